@@ -1,5 +1,7 @@
 package goex
 
+import "context"
+
 // api interface
 
 type API interface {
@@ -21,3 +23,69 @@ type API interface {
 
 	GetExchangeName() string
 }
+
+// context-aware api interface , 每个调用都受ctx控制(取消/超时)
+
+type APIWithContext interface {
+	LimitBuy(ctx context.Context, amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error)
+	LimitSell(ctx context.Context, amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error)
+	MarketBuy(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error)
+	MarketSell(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error)
+	CancelOrder(ctx context.Context, orderId string, currency CurrencyPair) (bool, error)
+	GetOneOrder(ctx context.Context, orderId string, currency CurrencyPair) (*Order, error)
+	GetUnfinishOrders(ctx context.Context, currency CurrencyPair) ([]Order, error)
+	GetOrderHistorys(ctx context.Context, currency CurrencyPair, opt ...OptionalParameter) ([]Order, error)
+	GetAccount(ctx context.Context) (*Account, error)
+
+	GetTicker(ctx context.Context, currency CurrencyPair) (*Ticker, error)
+	GetDepth(ctx context.Context, size int, currency CurrencyPair) (*Depth, error)
+	GetKlineRecords(ctx context.Context, currency CurrencyPair, period KlinePeriod, size int, optional ...OptionalParameter) ([]Kline, error)
+	GetTrades(ctx context.Context, currencyPair CurrencyPair, since int64) ([]Trade, error)
+
+	GetExchangeName() string
+}
+
+// 支持ctx的交易所实现该接口，返回一个绑定了ctx的API副本，http请求会随ctx取消
+type ContextBinder interface {
+	WithContext(ctx context.Context) API
+}
+
+// 装饰器(NewRetryAPI、NewMarketRoundingAPI等)实现该接口，返回被包装的api
+// 装饰器只实现API，ContextBinder、BatchOrderAPI、ClientOrderIdAPI等可选接口需要先用UnwrapAPI取到交易所实现再做类型断言
+type APIUnwrapper interface {
+	Unwrap() API
+}
+
+// 逐层解开装饰器，返回最内层的交易所实现
+func UnwrapAPI(api API) API {
+	for {
+		u, ok := api.(APIUnwrapper)
+		if !ok {
+			return api
+		}
+		api = u.Unwrap()
+	}
+}
+
+// 批量下单/撤单，交易所有批量接口时原生实现；没有的可以用NewBatchOrderAPI并发逐个调用。
+// 分批请求时某一批失败，不再发送之后的批次，该批及之后订单的Err为该错误，同时返回已完成批次的结果和错误
+type BatchOrderAPI interface {
+	//批量限价单，使用Order的Currency/Side(BUY,SELL)/Price/Amount/Cid/OrderType字段，结果与orders一一对应
+	BatchLimitOrders(orders []Order) ([]BatchOrderResult, error)
+	//批量撤单，结果与orderIds一一对应
+	BatchCancelOrders(currency CurrencyPair, orderIds []string) ([]BatchOrderResult, error)
+	//撤销该交易对的所有未完成订单
+	CancelAllOrders(currency CurrencyPair) (bool, error)
+}
+
+// 修改未完成的限价单，交易所不支持时可以用NewAmendOrderAPI撤单后重新下单
+type AmendOrderAPI interface {
+	//newPrice , newAmount 为空表示不修改，newAmount为修改后的委托总数量(包含已成交部分)
+	AmendOrder(orderId string, currency CurrencyPair, newPrice, newAmount string) (*Order, error)
+}
+
+// 按客户端订单ID(下单时通过ClientOrderId传入)查询和撤销订单，下单请求超时等结果未知时用来确认订单状态
+type ClientOrderIdAPI interface {
+	GetOrderByClientId(cid string, currency CurrencyPair) (*Order, error)
+	CancelOrderByClientId(cid string, currency CurrencyPair) (bool, error)
+}
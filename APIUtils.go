@@ -1,6 +1,7 @@
 package goex
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/mrwill84/goex/internal/logger"
@@ -15,6 +16,7 @@ import (
   @method 调用的函数，比如: api.GetTicker ,注意：不是api.GetTicker(...)
   @params 参数,顺序一定要按照实际调用函数入参顺序一样
   @return 返回
+  Deprecated: 会重试所有错误(包括下单)，请使用RetryPolicy或NewRetryAPI
 */
 func RE(retry int, delay time.Duration, method interface{}, params ...interface{}) interface{} {
 
@@ -75,21 +77,36 @@ func CancelAllUnfinishedOrders(api API, currencyPair CurrencyPair) int {
 
 	c := 0
 
+	policy := &RetryPolicy{MaxRetries: 1, InitialInterval: 200 * time.Millisecond, Multiplier: 1}
+
 	for {
-		ret := RE(2, 200*time.Millisecond, api.GetUnfinishOrders, currencyPair)
+		var orders []Order
+		err := policy.Do(context.Background(), func() (err error) {
+			orders, err = api.GetUnfinishOrders(currencyPair)
+			return err
+		})
 
-		if err, isok := ret.(error); isok {
+		if err != nil {
 			logger.Log.Error("[api error]", err)
 			break
 		}
 
-		if ret == nil {
+		if len(orders) == 0 {
 			break
 		}
 
-		orders, isok := ret.([]Order)
-		if !isok || len(orders) == 0 {
-			break
+		if batch, ok := api.(BatchOrderAPI); ok {
+			orderIds := make([]string, 0, len(orders))
+			for _, ord := range orders {
+				orderIds = append(orderIds, ord.OrderID2)
+			}
+			n, err := countBatchSuccess(batch.BatchCancelOrders(currencyPair, orderIds))
+			if err != nil {
+				logger.Log.Error(err)
+				break
+			}
+			c += n
+			continue
 		}
 
 		for _, ord := range orders {
@@ -118,20 +135,35 @@ func CancelAllUnfinishedFutureOrders(api FutureRestAPI, contractType string, cur
 
 	c := 0
 
+	policy := &RetryPolicy{MaxRetries: 9, InitialInterval: 200 * time.Millisecond, Multiplier: 1}
+
 	for {
-		ret := RE(10, 200*time.Millisecond, api.GetUnfinishFutureOrders, currencyPair, contractType)
-		if err, isOk := ret.(error); isOk {
+		var orders []FutureOrder
+		err := policy.Do(context.Background(), func() (err error) {
+			orders, err = api.GetUnfinishFutureOrders(currencyPair, contractType)
+			return err
+		})
+		if err != nil {
 			logger.Log.Error("[api error]", err)
 			break
 		}
 
-		if ret == nil {
+		if len(orders) == 0 {
 			break
 		}
 
-		orders, isOk := ret.([]FutureOrder)
-		if !isOk || len(orders) == 0 {
-			break
+		if batch, ok := api.(BatchFutureOrderAPI); ok {
+			orderIds := make([]string, 0, len(orders))
+			for _, ord := range orders {
+				orderIds = append(orderIds, ord.OrderID2)
+			}
+			n, err := countBatchSuccess(batch.BatchFutureCancelOrders(currencyPair, contractType, orderIds))
+			if err != nil {
+				logger.Log.Error(err)
+				break
+			}
+			c += n
+			continue
 		}
 
 		for _, ord := range orders {
@@ -147,3 +179,18 @@ func CancelAllUnfinishedFutureOrders(api FutureRestAPI, contractType string, cur
 
 	return c
 }
+
+func countBatchSuccess(results []BatchOrderResult, err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+	c := 0
+	for _, r := range results {
+		if r.Err != nil {
+			logger.Log.Error(r.Err)
+		} else {
+			c++
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,133 @@
+package goex
+
+import (
+	"errors"
+	"strconv"
+)
+
+// 返回api的改单接口: api实现了AmendOrderAPI时直接返回，否则撤单后按剩余数量重新下单。
+// 模拟改单时返回新订单，Amount为修改后的总数量，DealAmount为原订单撤单前已成交的数量，
+// 已成交数量不小于newAmount时不再下单，返回撤单后的原订单(原订单已完全成交时Status为ORDER_FINISH，否则为ORDER_CANCEL)；
+// 撤单后无法查询原订单或重新下单失败时，返回已撤销的原订单(Status为ORDER_CANCEL)和错误
+func NewAmendOrderAPI(api API) AmendOrderAPI {
+	if amend, ok := api.(AmendOrderAPI); ok {
+		return amend
+	}
+	return &amendOrderAPI{api: api}
+}
+
+// 同NewAmendOrderAPI，作用于FutureRestAPI
+func NewAmendFutureOrderAPI(api FutureRestAPI) AmendFutureOrderAPI {
+	if amend, ok := api.(AmendFutureOrderAPI); ok {
+		return amend
+	}
+	return &amendFutureOrderAPI{api: api}
+}
+
+//计算改单后的价格和剩余需要重新下单的数量 , 剩余数量按十进制相减 , 精度与输入的数量一致
+func amendParams(price, amount, dealAmount float64, newPrice, newAmount string) (string, float64, Decimal) {
+	if newPrice == "" {
+		newPrice = strconv.FormatFloat(price, 'f', -1, 64)
+	}
+	total := NewDecimalFromFloat(amount)
+	if newAmount != "" {
+		total = ToDecimal(newAmount)
+	}
+	return newPrice, total.Float64(), total.Sub(NewDecimalFromFloat(dealAmount))
+}
+
+type amendOrderAPI struct {
+	api API
+}
+
+func (a *amendOrderAPI) AmendOrder(orderId string, currency CurrencyPair, newPrice, newAmount string) (*Order, error) {
+	ord, err := a.api.GetOneOrder(orderId, currency)
+	if err != nil {
+		return nil, err
+	}
+	if ord.Side != BUY && ord.Side != SELL {
+		return nil, errors.New("only limit order can be amended")
+	}
+
+	if _, err = a.api.CancelOrder(orderId, currency); err != nil {
+		return nil, err
+	}
+
+	//撤单前可能有新的成交，以撤单后的成交数量为准；查询失败时不再下单，返回已撤销的原订单
+	canceled, err := a.api.GetOneOrder(orderId, currency)
+	if err != nil {
+		ord.Status = ORDER_CANCEL
+		return ord, err
+	}
+	ord = canceled
+
+	price, total, remain := amendParams(ord.Price, ord.Amount, ord.DealAmount, newPrice, newAmount)
+	if remain.Sign() <= 0 {
+		ord.Amount = total
+		if ord.Status != ORDER_FINISH {
+			ord.Status = ORDER_CANCEL
+		}
+		return ord, nil
+	}
+
+	opt := orderTypeOptions(ord.OrderType, "")
+	amount := remain.String()
+
+	var newOrd *Order
+	if ord.Side == BUY {
+		newOrd, err = a.api.LimitBuy(amount, price, currency, opt...)
+	} else {
+		newOrd, err = a.api.LimitSell(amount, price, currency, opt...)
+	}
+	if err != nil {
+		ord.Status = ORDER_CANCEL
+		return ord, err
+	}
+
+	newOrd.Amount = total
+	newOrd.DealAmount = ord.DealAmount
+	return newOrd, nil
+}
+
+type amendFutureOrderAPI struct {
+	api FutureRestAPI
+}
+
+func (a *amendFutureOrderAPI) AmendFutureOrder(currencyPair CurrencyPair, contractType, orderId, newPrice, newAmount string) (*FutureOrder, error) {
+	ord, err := a.api.GetFutureOrder(orderId, currencyPair, contractType)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = a.api.FutureCancelOrder(currencyPair, contractType, orderId); err != nil {
+		return nil, err
+	}
+
+	//撤单前可能有新的成交，以撤单后的成交数量为准；查询失败时不再下单，返回已撤销的原订单
+	canceled, err := a.api.GetFutureOrder(orderId, currencyPair, contractType)
+	if err != nil {
+		ord.Status = ORDER_CANCEL
+		return ord, err
+	}
+	ord = canceled
+
+	price, total, remain := amendParams(ord.Price, ord.Amount, ord.DealAmount, newPrice, newAmount)
+	if remain.Sign() <= 0 {
+		ord.Amount = total
+		if ord.Status != ORDER_FINISH {
+			ord.Status = ORDER_CANCEL
+		}
+		return ord, nil
+	}
+
+	newOrd, err := a.api.LimitFuturesOrder(currencyPair, contractType, price, remain.String(),
+		ord.OType, orderTypeOptions(ord.OrderType, "")...)
+	if err != nil {
+		ord.Status = ORDER_CANCEL
+		return ord, err
+	}
+
+	newOrd.Amount = total
+	newOrd.DealAmount = ord.DealAmount
+	return newOrd, nil
+}
@@ -0,0 +1,106 @@
+package goex
+
+import (
+	"errors"
+	"testing"
+)
+
+type mockAmendAPI struct {
+	API
+	ord      Order
+	canceled bool
+	queryErr error //撤单后查询订单返回的错误
+	placeErr error //重新下单返回的错误
+	placed   []string
+}
+
+func (m *mockAmendAPI) GetOneOrder(orderId string, currency CurrencyPair) (*Order, error) {
+	ord := m.ord
+	if m.canceled && m.queryErr != nil {
+		return nil, m.queryErr
+	}
+	if m.canceled {
+		//撤单前又成交了1个
+		ord.DealAmount++
+		ord.Status = ORDER_CANCEL
+	}
+	return &ord, nil
+}
+
+func (m *mockAmendAPI) CancelOrder(orderId string, currency CurrencyPair) (bool, error) {
+	m.canceled = true
+	return true, nil
+}
+
+func (m *mockAmendAPI) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	m.placed = append(m.placed, amount+"@"+price)
+	if m.placeErr != nil {
+		return nil, m.placeErr
+	}
+	return &Order{OrderID2: "2", Price: ToFloat64(price), Amount: ToFloat64(amount), Side: BUY, Status: ORDER_UNFINISH}, nil
+}
+
+func TestNewAmendOrderAPI_CancelReplace(t *testing.T) {
+	api := &mockAmendAPI{ord: Order{OrderID2: "1", Price: 100, Amount: 10, DealAmount: 2, Side: BUY}}
+
+	ord, err := NewAmendOrderAPI(api).AmendOrder("1", BTC_USDT, "101", "8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(api.placed) != 1 || api.placed[0] != "5@101" {
+		t.Fatal(api.placed)
+	}
+	if ord.OrderID2 != "2" || ord.Amount != 8 || ord.DealAmount != 3 {
+		t.Fatal(ord)
+	}
+
+	//已成交数量不小于修改后的数量，不再下单，原订单部分成交后已撤销
+	api = &mockAmendAPI{ord: Order{OrderID2: "1", Price: 100, Amount: 10, DealAmount: 2, Side: BUY}}
+	ord, err = NewAmendOrderAPI(api).AmendOrder("1", BTC_USDT, "", "3")
+	if err != nil || len(api.placed) != 0 || ord.OrderID2 != "1" || ord.Status != ORDER_CANCEL || ord.DealAmount != 3 {
+		t.Fatal(ord, err, api.placed)
+	}
+}
+
+func TestNewAmendOrderAPI_RemainPrecision(t *testing.T) {
+	api := &mockAmendAPI{ord: Order{OrderID2: "1", Price: 100, Amount: 1, DealAmount: 0.2, Side: BUY}}
+
+	//1.3 - 1.2 按float64相减为0.10000000000000009
+	_, err := NewAmendOrderAPI(api).AmendOrder("1", BTC_USDT, "", "1.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(api.placed) != 1 || api.placed[0] != "0.1@100" {
+		t.Fatal(api.placed)
+	}
+}
+
+func TestNewAmendOrderAPI_QueryAfterCancelFailed(t *testing.T) {
+	queryErr := errors.New("network error")
+	api := &mockAmendAPI{ord: Order{OrderID2: "1", Price: 100, Amount: 10, DealAmount: 2, Side: BUY}, queryErr: queryErr}
+
+	ord, err := NewAmendOrderAPI(api).AmendOrder("1", BTC_USDT, "101", "8")
+	if err != queryErr {
+		t.Fatal(err)
+	}
+	if len(api.placed) != 0 {
+		t.Fatal(api.placed)
+	}
+	if ord == nil || ord.OrderID2 != "1" || ord.Status != ORDER_CANCEL {
+		t.Fatal(ord)
+	}
+}
+
+func TestNewAmendOrderAPI_PlaceFailed(t *testing.T) {
+	placeErr := errors.New("insufficient balance")
+	api := &mockAmendAPI{ord: Order{OrderID2: "1", Price: 100, Amount: 10, DealAmount: 2, Side: BUY}, placeErr: placeErr}
+
+	ord, err := NewAmendOrderAPI(api).AmendOrder("1", BTC_USDT, "101", "8")
+	if err != placeErr {
+		t.Fatal(err)
+	}
+	//返回撤单后重新查询的原订单，包含撤单前新增的成交
+	if ord == nil || ord.OrderID2 != "1" || ord.Status != ORDER_CANCEL || ord.DealAmount != 3 {
+		t.Fatal(ord)
+	}
+}
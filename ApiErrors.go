@@ -1,13 +1,35 @@
 package goex
 
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
 type ApiError struct {
 	ErrCode,
 	ErrMsg,
 	OriginErrMsg string
+	OriginErrCode string        //交易所原始错误码
+	RetryAfter    time.Duration //限频时交易所要求的等待时间，0表示未知
 }
 
 func (e ApiError) Error() string {
-	return e.ErrMsg
+	if e.OriginErrCode == "" && e.OriginErrMsg == "" {
+		return e.ErrMsg
+	}
+	return fmt.Sprintf("%s , code=%s , msg=%s", e.ErrMsg, e.OriginErrCode, e.OriginErrMsg)
+}
+
+//错误码相同即视为同一类错误 , errors.Is(err, EX_ERR_API_LIMIT)
+func (e ApiError) Is(target error) bool {
+	switch t := target.(type) {
+	case ApiError:
+		return e.ErrCode == t.ErrCode
+	case *ApiError:
+		return t != nil && e.ErrCode == t.ErrCode
+	}
+	return false
 }
 
 func (e ApiError) OriginErr(err string) ApiError {
@@ -15,6 +37,18 @@ func (e ApiError) OriginErr(err string) ApiError {
 	return e
 }
 
+//保留交易所原始错误码和错误信息
+func (e ApiError) Wrap(originCode, originMsg string) ApiError {
+	e.OriginErrCode = originCode
+	e.OriginErrMsg = originMsg
+	return e
+}
+
+func (e ApiError) WithRetryAfter(d time.Duration) ApiError {
+	e.RetryAfter = d
+	return e
+}
+
 var (
 	API_ERR                      = ApiError{ErrCode: "EX_ERR_0000", ErrMsg: "unknown error"}
 	HTTP_ERR_CODE                = ApiError{ErrCode: "HTTP_ERR_0001", ErrMsg: "http request error"}
@@ -28,4 +62,53 @@ var (
 	EX_ERR_INVALID_CURRENCY_PAIR = ApiError{ErrCode: "EX_ERR_0007", ErrMsg: "invalid currency pair"}
 	EX_ERR_NOT_FIND_ORDER        = ApiError{ErrCode: "EX_ERR_0008", ErrMsg: "not find order"}
 	EX_ERR_SYMBOL_ERR            = ApiError{ErrCode: "EX_ERR_0009", ErrMsg: "symbol error"}
+	EX_ERR_MARKET_NOT_TRADING    = ApiError{ErrCode: "EX_ERR_0010", ErrMsg: "market not trading"}
+	EX_ERR_INVALID_ORDER_PARAM   = ApiError{ErrCode: "EX_ERR_0011", ErrMsg: "invalid order parameter"}
+	EX_ERR_INVALID_TIMESTAMP     = ApiError{ErrCode: "EX_ERR_0012", ErrMsg: "invalid nonce or timestamp"}
+	EX_ERR_AUTH_FAIL             = ApiError{ErrCode: "EX_ERR_0013", ErrMsg: "authentication failure"}
+	EX_ERR_MAINTENANCE           = ApiError{ErrCode: "EX_ERR_0014", ErrMsg: "exchange under maintenance"}
+	EX_ERR_NOT_SUPPORTED         = ApiError{ErrCode: "EX_ERR_0015", ErrMsg: "not supported by exchange"}
+	EX_ERR_DUPLICATE_CLIENT_OID  = ApiError{ErrCode: "EX_ERR_0016", ErrMsg: "duplicate client order id"}
 )
+
+//交易所错误码 -> 标准错误
+type ErrorCodeTable map[string]ApiError
+
+//未知的错误码返回API_ERR , 均保留原始错误码和错误信息
+func (t ErrorCodeTable) Wrap(originCode, originMsg string) ApiError {
+	e, ok := t[originCode]
+	if !ok {
+		e = API_ERR
+	}
+	return e.Wrap(originCode, originMsg)
+}
+
+//错误码未知时按http状态码分类
+func (t ErrorCodeTable) WrapHttpError(httpErr *HttpError, originCode, originMsg string) ApiError {
+	e, ok := t[originCode]
+	if !ok {
+		e = API_ERR
+		for _, std := range []ApiError{EX_ERR_API_LIMIT, EX_ERR_AUTH_FAIL, EX_ERR_MAINTENANCE} {
+			if httpErr.Is(std) {
+				e = std
+				break
+			}
+		}
+	}
+	return e.Wrap(originCode, originMsg).WithRetryAfter(httpErr.RetryAfter)
+}
+
+//限频错误中交易所要求的等待时间
+func GetRetryAfter(err error) (time.Duration, bool) {
+	var apiErr ApiError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return apiErr.RetryAfter, true
+	}
+
+	var httpErr *HttpError
+	if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+		return httpErr.RetryAfter, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,49 @@
+package goex
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestApiError_Is(t *testing.T) {
+	err := error(EX_ERR_NOT_FIND_ORDER.OriginErr("Unknown order sent."))
+	if !errors.Is(err, EX_ERR_NOT_FIND_ORDER) || errors.Is(err, EX_ERR_API_LIMIT) {
+		t.Fatal(err)
+	}
+
+	table := ErrorCodeTable{"-1003": EX_ERR_API_LIMIT}
+	err = table.Wrap("-1003", "Too many requests")
+	if !errors.Is(err, EX_ERR_API_LIMIT) || err.Error() != "api limited , code=-1003 , msg=Too many requests" {
+		t.Fatal(err)
+	}
+	if err = table.Wrap("-9999", "unknown"); !errors.Is(err, API_ERR) {
+		t.Fatal(err)
+	}
+}
+
+func TestHttpError_Is(t *testing.T) {
+	httpErr := newHttpError(429, []byte(`{"code":-1,"msg":"limited"}`), "3")
+	if !errors.Is(httpErr, EX_ERR_API_LIMIT) || errors.Is(httpErr, EX_ERR_AUTH_FAIL) {
+		t.Fatal(httpErr)
+	}
+	if httpErr.Error() != `HttpStatusCode:429 ,Desc:{"code":-1,"msg":"limited"}` {
+		t.Fatal(httpErr.Error())
+	}
+
+	if d, ok := GetRetryAfter(httpErr); !ok || d != 3*time.Second {
+		t.Fatal(d, ok)
+	}
+
+	err := ErrorCodeTable{}.WrapHttpError(httpErr, "-1", "limited")
+	if !errors.Is(err, EX_ERR_API_LIMIT) {
+		t.Fatal(err)
+	}
+	if d, ok := GetRetryAfter(err); !ok || d != 3*time.Second {
+		t.Fatal(d, ok)
+	}
+
+	if _, ok := GetRetryAfter(errors.New("other")); ok {
+		t.Fatal("no retry after")
+	}
+}
@@ -0,0 +1,177 @@
+package goex
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+)
+
+// 返回api的批量下单接口: api实现了BatchOrderAPI时直接返回，否则并发地逐个调用LimitBuy/LimitSell/CancelOrder
+func NewBatchOrderAPI(api API) BatchOrderAPI {
+	if batch, ok := api.(BatchOrderAPI); ok {
+		return batch
+	}
+	return &batchOrderAPI{api: api}
+}
+
+// 同NewBatchOrderAPI，没有批量接口时并发地逐个调用LimitFuturesOrder/FutureCancelOrder
+func NewBatchFutureOrderAPI(api FutureRestAPI) BatchFutureOrderAPI {
+	if batch, ok := api.(BatchFutureOrderAPI); ok {
+		return batch
+	}
+	return &batchFutureOrderAPI{api: api}
+}
+
+func orderTypeOptions(orderType int, cid string) []LimitOrderOptionalParameter {
+	var opt []LimitOrderOptionalParameter
+	switch orderType {
+	case ORDER_FEATURE_POST_ONLY:
+		opt = append(opt, PostOnly)
+	case ORDER_FEATURE_FOK:
+		opt = append(opt, Fok)
+	case ORDER_FEATURE_IOC:
+		opt = append(opt, Ioc)
+	}
+	if cid != "" {
+		opt = append(opt, ClientOrderId(cid))
+	}
+	return opt
+}
+
+func fanOut(n int, call func(i int) BatchOrderResult) []BatchOrderResult {
+	results := make([]BatchOrderResult, n)
+	wg := sync.WaitGroup{}
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			results[i] = call(i)
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// 并发逐个下限价单，供没有批量下单接口的交易所实现BatchLimitOrders
+func LimitOrdersConcurrently(api API, orders []Order) []BatchOrderResult {
+	return fanOut(len(orders), func(i int) BatchOrderResult {
+		o := orders[i]
+		price, amount := strconv.FormatFloat(o.Price, 'f', -1, 64), strconv.FormatFloat(o.Amount, 'f', -1, 64)
+		opt := orderTypeOptions(o.OrderType, o.Cid)
+
+		var (
+			ord *Order
+			err error
+		)
+		switch o.Side {
+		case BUY:
+			ord, err = api.LimitBuy(amount, price, o.Currency, opt...)
+		case SELL:
+			ord, err = api.LimitSell(amount, price, o.Currency, opt...)
+		default:
+			err = errors.New("batch limit order only support BUY or SELL side")
+		}
+
+		if err != nil {
+			return BatchOrderResult{Cid: o.Cid, Err: err}
+		}
+		return BatchOrderResult{OrderId: ord.OrderID2, Cid: o.Cid}
+	})
+}
+
+// 并发逐个撤单，供没有批量撤单接口的交易所实现BatchCancelOrders
+func CancelOrdersConcurrently(api API, currency CurrencyPair, orderIds []string) []BatchOrderResult {
+	return fanOut(len(orderIds), func(i int) BatchOrderResult {
+		_, err := api.CancelOrder(orderIds[i], currency)
+		return BatchOrderResult{OrderId: orderIds[i], Err: err}
+	})
+}
+
+// 并发逐个下期货限价单
+func LimitFuturesOrdersConcurrently(api FutureRestAPI, orders []FutureOrder) []BatchOrderResult {
+	return fanOut(len(orders), func(i int) BatchOrderResult {
+		o := orders[i]
+		price, amount := strconv.FormatFloat(o.Price, 'f', -1, 64), strconv.FormatFloat(o.Amount, 'f', -1, 64)
+		ord, err := api.LimitFuturesOrder(o.Currency, o.ContractName, price, amount, o.OType, orderTypeOptions(o.OrderType, o.ClientOid)...)
+		if err != nil {
+			return BatchOrderResult{Cid: o.ClientOid, Err: err}
+		}
+		return BatchOrderResult{OrderId: ord.OrderID2, Cid: o.ClientOid}
+	})
+}
+
+// 并发逐个撤销期货订单
+func FutureCancelOrdersConcurrently(api FutureRestAPI, currencyPair CurrencyPair, contractType string, orderIds []string) []BatchOrderResult {
+	return fanOut(len(orderIds), func(i int) BatchOrderResult {
+		_, err := api.FutureCancelOrder(currencyPair, contractType, orderIds[i])
+		return BatchOrderResult{OrderId: orderIds[i], Err: err}
+	})
+}
+
+// 返回第一个失败订单的错误
+func firstBatchError(results []BatchOrderResult) error {
+	for _, r := range results {
+		if r.Err != nil {
+			return r.Err
+		}
+	}
+	return nil
+}
+
+type batchOrderAPI struct {
+	api API
+}
+
+func (b *batchOrderAPI) BatchLimitOrders(orders []Order) ([]BatchOrderResult, error) {
+	return LimitOrdersConcurrently(b.api, orders), nil
+}
+
+func (b *batchOrderAPI) BatchCancelOrders(currency CurrencyPair, orderIds []string) ([]BatchOrderResult, error) {
+	return CancelOrdersConcurrently(b.api, currency, orderIds), nil
+}
+
+func (b *batchOrderAPI) CancelAllOrders(currency CurrencyPair) (bool, error) {
+	orders, err := b.api.GetUnfinishOrders(currency)
+	if err != nil {
+		return false, err
+	}
+
+	orderIds := make([]string, 0, len(orders))
+	for _, ord := range orders {
+		orderIds = append(orderIds, ord.OrderID2)
+	}
+
+	if err = firstBatchError(CancelOrdersConcurrently(b.api, currency, orderIds)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+type batchFutureOrderAPI struct {
+	api FutureRestAPI
+}
+
+func (b *batchFutureOrderAPI) BatchLimitFuturesOrders(orders []FutureOrder) ([]BatchOrderResult, error) {
+	return LimitFuturesOrdersConcurrently(b.api, orders), nil
+}
+
+func (b *batchFutureOrderAPI) BatchFutureCancelOrders(currencyPair CurrencyPair, contractType string, orderIds []string) ([]BatchOrderResult, error) {
+	return FutureCancelOrdersConcurrently(b.api, currencyPair, contractType, orderIds), nil
+}
+
+func (b *batchFutureOrderAPI) FutureCancelAllOrders(currencyPair CurrencyPair, contractType string) (bool, error) {
+	orders, err := b.api.GetUnfinishFutureOrders(currencyPair, contractType)
+	if err != nil {
+		return false, err
+	}
+
+	orderIds := make([]string, 0, len(orders))
+	for _, ord := range orders {
+		orderIds = append(orderIds, ord.OrderID2)
+	}
+
+	if err = firstBatchError(FutureCancelOrdersConcurrently(b.api, currencyPair, contractType, orderIds)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,108 @@
+package goex
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+type mockBatchAPI struct {
+	API
+	lock     sync.Mutex
+	opts     map[string][]LimitOrderOptionalParameter
+	canceled []string
+	unfinish []Order
+}
+
+func (m *mockBatchAPI) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	cid := GetClientOrderId(opt...)
+	m.opts[cid] = opt
+	return &Order{OrderID2: "id-" + cid, Cid: cid}, nil
+}
+
+func (m *mockBatchAPI) LimitSell(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	return nil, EX_ERR_INSUFFICIENT_BALANCE
+}
+
+func (m *mockBatchAPI) CancelOrder(orderId string, currency CurrencyPair) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.canceled = append(m.canceled, orderId)
+	m.unfinish = nil
+	return true, nil
+}
+
+func (m *mockBatchAPI) GetUnfinishOrders(currency CurrencyPair) ([]Order, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.unfinish, nil
+}
+
+//实现了BatchOrderAPI的交易所
+type mockNativeBatchAPI struct {
+	*mockBatchAPI
+	batchCancels int
+}
+
+func (m *mockNativeBatchAPI) BatchLimitOrders(orders []Order) ([]BatchOrderResult, error) {
+	return nil, errors.New("not implement")
+}
+
+func (m *mockNativeBatchAPI) BatchCancelOrders(currency CurrencyPair, orderIds []string) ([]BatchOrderResult, error) {
+	m.batchCancels++
+	m.unfinish = nil
+	results := make([]BatchOrderResult, 0, len(orderIds))
+	for _, id := range orderIds {
+		results = append(results, BatchOrderResult{OrderId: id})
+	}
+	return results, nil
+}
+
+func (m *mockNativeBatchAPI) CancelAllOrders(currency CurrencyPair) (bool, error) {
+	return true, nil
+}
+
+func TestNewBatchOrderAPI_FanOut(t *testing.T) {
+	api := &mockBatchAPI{opts: make(map[string][]LimitOrderOptionalParameter)}
+	batch := NewBatchOrderAPI(api)
+
+	results, err := batch.BatchLimitOrders([]Order{
+		{Currency: BTC_USDT, Side: BUY, Price: 100, Amount: 1, Cid: "a", OrderType: ORDER_FEATURE_POST_ONLY},
+		{Currency: BTC_USDT, Side: SELL, Price: 101, Amount: 1, Cid: "b"},
+		{Currency: BTC_USDT, Side: BUY, Price: 99, Amount: 1, Cid: "c"},
+	})
+	if err != nil || len(results) != 3 {
+		t.Fatal(results, err)
+	}
+
+	if results[0].OrderId != "id-a" || results[0].Err != nil || results[2].OrderId != "id-c" {
+		t.Fatal(results)
+	}
+	if results[1].Cid != "b" || !errors.Is(results[1].Err, EX_ERR_INSUFFICIENT_BALANCE) {
+		t.Fatal(results[1])
+	}
+	if opt := api.opts["a"]; len(opt) != 2 || opt[0] != PostOnly {
+		t.Fatal(opt)
+	}
+
+	results, _ = batch.BatchCancelOrders(BTC_USDT, []string{"1", "2"})
+	if len(results) != 2 || results[1].OrderId != "2" || len(api.canceled) != 2 {
+		t.Fatal(results, api.canceled)
+	}
+}
+
+func TestCancelAllUnfinishedOrders_Batch(t *testing.T) {
+	api := &mockNativeBatchAPI{mockBatchAPI: &mockBatchAPI{unfinish: []Order{{OrderID2: "1"}, {OrderID2: "2"}}}}
+	if batch := NewBatchOrderAPI(api); batch != api {
+		t.Fatal("should use native batch api")
+	}
+
+	if c := CancelAllUnfinishedOrders(api, BTC_USDT); c != 2 {
+		t.Fatal(c)
+	}
+	if api.batchCancels != 1 || len(api.canceled) != 0 {
+		t.Fatal(api.batchCancels, api.canceled)
+	}
+}
@@ -54,6 +54,28 @@ const (
 	CLOSE_SELL            //平空
 )
 
+var openTypeNames = map[int]string{
+	OPEN_BUY:   "openlong",
+	OPEN_SELL:  "openshort",
+	CLOSE_BUY:  "closelong",
+	CLOSE_SELL: "closeshort",
+}
+
+//OPEN_BUY/OPEN_SELL/CLOSE_BUY/CLOSE_SELL转换为openlong/openshort/closelong/closeshort，未知类型返回空串
+func OpenTypeName(openType int) string {
+	return openTypeNames[openType]
+}
+
+//openlong/openshort/closelong/closeshort转换为OPEN_BUY/OPEN_SELL/CLOSE_BUY/CLOSE_SELL，未知类型返回0
+func ParseOpenType(name string) int {
+	for openType, n := range openTypeNames {
+		if n == name {
+			return openType
+		}
+	}
+	return 0
+}
+
 type KlinePeriod int
 
 //k线周期
@@ -123,6 +145,34 @@ var (
 	SWAP_USDT_CONTRACT  = "swap-usdt"
 )
 
+//条件单类型
+type TriggerOrderType int
+
+const (
+	TRIGGER_STOP_MARKET        TriggerOrderType = 1 + iota //止损市价
+	TRIGGER_STOP_LIMIT                                     //止损限价
+	TRIGGER_TAKE_PROFIT_MARKET                             //止盈市价
+	TRIGGER_TAKE_PROFIT_LIMIT                              //止盈限价
+	TRIGGER_TRAILING_STOP                                  //跟踪止损(市价)
+)
+
+func (t TriggerOrderType) String() string {
+	if t > 0 && int(t) <= len(triggerOrderTypeSymbol) {
+		return triggerOrderTypeSymbol[t-1]
+	}
+	return fmt.Sprintf("UNKNOWN_TRIGGER_ORDER_TYPE(%d)", t)
+}
+
+var triggerOrderTypeSymbol = [...]string{"STOP_MARKET", "STOP_LIMIT", "TAKE_PROFIT_MARKET", "TAKE_PROFIT_LIMIT", "TRAILING_STOP"}
+
+//合约保证金模式
+type MarginMode string
+
+const (
+	CROSS_MARGIN    MarginMode = "cross"    //全仓
+	ISOLATED_MARGIN MarginMode = "isolated" //逐仓
+)
+
 //exchanges const
 const (
 	KUCOIN          = "kucoin.com"
@@ -175,7 +225,11 @@ const (
 	SWAP_USDT //usdt本位永续合约
 )
 
-type LimitOrderOptionalParameter int
+//限价单可选参数: PostOnly/Ioc/Fok 或 ClientOrderId(cid)
+type LimitOrderOptionalParameter struct {
+	timeInForce int
+	cid         string
+}
 
 func (opt LimitOrderOptionalParameter) String() string {
 	switch opt {
@@ -190,8 +244,33 @@ func (opt LimitOrderOptionalParameter) String() string {
 	}
 }
 
-const (
-	PostOnly LimitOrderOptionalParameter = iota + 1
-	Ioc
-	Fok
+var (
+	PostOnly = LimitOrderOptionalParameter{timeInForce: 1}
+	Ioc      = LimitOrderOptionalParameter{timeInForce: 2}
+	Fok      = LimitOrderOptionalParameter{timeInForce: 3}
 )
+
+//客户端订单ID，带有cid的下单请求重复提交时会被交易所拒绝，可以安全重试
+func ClientOrderId(cid string) LimitOrderOptionalParameter {
+	return LimitOrderOptionalParameter{cid: cid}
+}
+
+//返回opts中的客户端订单ID，没有时返回空
+func GetClientOrderId(opts ...LimitOrderOptionalParameter) string {
+	for _, opt := range opts {
+		if opt.cid != "" {
+			return opt.cid
+		}
+	}
+	return ""
+}
+
+//返回opts中的PostOnly/Ioc/Fok，没有时ok为false
+func GetTimeInForce(opts ...LimitOrderOptionalParameter) (tif LimitOrderOptionalParameter, ok bool) {
+	for _, opt := range opts {
+		if opt.timeInForce != 0 {
+			return opt, true
+		}
+	}
+	return tif, false
+}
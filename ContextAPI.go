@@ -0,0 +1,448 @@
+package goex
+
+import "context"
+
+// 将普通的API适配成APIWithContext:
+// 如果api实现了ContextBinder，请求会绑定ctx，取消时http请求立即中断；
+// 否则在goroutine中调用，ctx取消时立即返回ctx.Err()(底层请求仍会执行完成)；
+// 下单/撤单等改变状态的请求开始后不会因ctx取消而提前返回，等待请求结果，避免订单已生效却返回取消错误
+func NewAPIWithContext(api API) APIWithContext {
+	return &apiWithContext{api: api}
+}
+
+// 将普通的FutureRestAPI适配成FutureRestAPIWithContext，规则同NewAPIWithContext
+func NewFutureRestAPIWithContext(api FutureRestAPI) FutureRestAPIWithContext {
+	return &futureRestAPIWithContext{api: api}
+}
+
+func callWithContext(ctx context.Context, call func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- call()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//改变状态的请求：只在开始前检查ctx，开始后等待结果(实现了ContextBinder时由http请求自身响应ctx取消)
+func callUntilDone(ctx context.Context, call func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return call()
+}
+
+type apiWithContext struct {
+	api API
+}
+
+func (w *apiWithContext) bind(ctx context.Context) API {
+	if binder, ok := w.api.(ContextBinder); ok {
+		return binder.WithContext(ctx)
+	}
+	return w.api
+}
+
+func (w *apiWithContext) LimitBuy(ctx context.Context, amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	var ord *Order
+	err := callUntilDone(ctx, func() (err error) {
+		ord, err = w.bind(ctx).LimitBuy(amount, price, currency, opt...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ord, nil
+}
+
+func (w *apiWithContext) LimitSell(ctx context.Context, amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	var ord *Order
+	err := callUntilDone(ctx, func() (err error) {
+		ord, err = w.bind(ctx).LimitSell(amount, price, currency, opt...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ord, nil
+}
+
+func (w *apiWithContext) MarketBuy(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
+	var ord *Order
+	err := callUntilDone(ctx, func() (err error) {
+		ord, err = w.bind(ctx).MarketBuy(amount, price, currency)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ord, nil
+}
+
+func (w *apiWithContext) MarketSell(ctx context.Context, amount, price string, currency CurrencyPair) (*Order, error) {
+	var ord *Order
+	err := callUntilDone(ctx, func() (err error) {
+		ord, err = w.bind(ctx).MarketSell(amount, price, currency)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ord, nil
+}
+
+func (w *apiWithContext) CancelOrder(ctx context.Context, orderId string, currency CurrencyPair) (bool, error) {
+	var ok bool
+	err := callUntilDone(ctx, func() (err error) {
+		ok, err = w.bind(ctx).CancelOrder(orderId, currency)
+		return
+	})
+	if err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+func (w *apiWithContext) GetOneOrder(ctx context.Context, orderId string, currency CurrencyPair) (*Order, error) {
+	var ord *Order
+	err := callWithContext(ctx, func() (err error) {
+		ord, err = w.bind(ctx).GetOneOrder(orderId, currency)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ord, nil
+}
+
+func (w *apiWithContext) GetUnfinishOrders(ctx context.Context, currency CurrencyPair) ([]Order, error) {
+	var orders []Order
+	err := callWithContext(ctx, func() (err error) {
+		orders, err = w.bind(ctx).GetUnfinishOrders(currency)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (w *apiWithContext) GetOrderHistorys(ctx context.Context, currency CurrencyPair, opt ...OptionalParameter) ([]Order, error) {
+	var orders []Order
+	err := callWithContext(ctx, func() (err error) {
+		orders, err = w.bind(ctx).GetOrderHistorys(currency, opt...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (w *apiWithContext) GetAccount(ctx context.Context) (*Account, error) {
+	var acc *Account
+	err := callWithContext(ctx, func() (err error) {
+		acc, err = w.bind(ctx).GetAccount()
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+func (w *apiWithContext) GetTicker(ctx context.Context, currency CurrencyPair) (*Ticker, error) {
+	var ticker *Ticker
+	err := callWithContext(ctx, func() (err error) {
+		ticker, err = w.bind(ctx).GetTicker(currency)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ticker, nil
+}
+
+func (w *apiWithContext) GetDepth(ctx context.Context, size int, currency CurrencyPair) (*Depth, error) {
+	var dep *Depth
+	err := callWithContext(ctx, func() (err error) {
+		dep, err = w.bind(ctx).GetDepth(size, currency)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dep, nil
+}
+
+func (w *apiWithContext) GetKlineRecords(ctx context.Context, currency CurrencyPair, period KlinePeriod, size int, optional ...OptionalParameter) ([]Kline, error) {
+	var klines []Kline
+	err := callWithContext(ctx, func() (err error) {
+		klines, err = w.bind(ctx).GetKlineRecords(currency, period, size, optional...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return klines, nil
+}
+
+func (w *apiWithContext) GetTrades(ctx context.Context, currencyPair CurrencyPair, since int64) ([]Trade, error) {
+	var trades []Trade
+	err := callWithContext(ctx, func() (err error) {
+		trades, err = w.bind(ctx).GetTrades(currencyPair, since)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
+func (w *apiWithContext) GetExchangeName() string {
+	return w.api.GetExchangeName()
+}
+
+type futureRestAPIWithContext struct {
+	api FutureRestAPI
+}
+
+func (w *futureRestAPIWithContext) bind(ctx context.Context) FutureRestAPI {
+	if binder, ok := w.api.(FutureContextBinder); ok {
+		return binder.WithContext(ctx)
+	}
+	return w.api
+}
+
+func (w *futureRestAPIWithContext) GetExchangeName() string {
+	return w.api.GetExchangeName()
+}
+
+func (w *futureRestAPIWithContext) GetFutureEstimatedPrice(ctx context.Context, currencyPair CurrencyPair) (float64, error) {
+	var price float64
+	err := callWithContext(ctx, func() (err error) {
+		price, err = w.bind(ctx).GetFutureEstimatedPrice(currencyPair)
+		return
+	})
+	if err != nil {
+		return 0, err
+	}
+	return price, nil
+}
+
+func (w *futureRestAPIWithContext) GetFutureTicker(ctx context.Context, currencyPair CurrencyPair, contractType string) (*Ticker, error) {
+	var ticker *Ticker
+	err := callWithContext(ctx, func() (err error) {
+		ticker, err = w.bind(ctx).GetFutureTicker(currencyPair, contractType)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ticker, nil
+}
+
+func (w *futureRestAPIWithContext) GetFutureDepth(ctx context.Context, currencyPair CurrencyPair, contractType string, size int) (*Depth, error) {
+	var dep *Depth
+	err := callWithContext(ctx, func() (err error) {
+		dep, err = w.bind(ctx).GetFutureDepth(currencyPair, contractType, size)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dep, nil
+}
+
+func (w *futureRestAPIWithContext) GetFutureIndex(ctx context.Context, currencyPair CurrencyPair) (float64, error) {
+	var index float64
+	err := callWithContext(ctx, func() (err error) {
+		index, err = w.bind(ctx).GetFutureIndex(currencyPair)
+		return
+	})
+	if err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+func (w *futureRestAPIWithContext) GetFutureUserinfo(ctx context.Context, currencyPair ...CurrencyPair) (*FutureAccount, error) {
+	var acc *FutureAccount
+	err := callWithContext(ctx, func() (err error) {
+		acc, err = w.bind(ctx).GetFutureUserinfo(currencyPair...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+func (w *futureRestAPIWithContext) PlaceFutureOrder(ctx context.Context, currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice int, leverRate float64) (string, error) {
+	var orderId string
+	err := callUntilDone(ctx, func() (err error) {
+		orderId, err = w.bind(ctx).PlaceFutureOrder(currencyPair, contractType, price, amount, openType, matchPrice, leverRate)
+		return
+	})
+	if err != nil {
+		return "", err
+	}
+	return orderId, nil
+}
+
+func (w *futureRestAPIWithContext) LimitFuturesOrder(ctx context.Context, currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
+	var ord *FutureOrder
+	err := callUntilDone(ctx, func() (err error) {
+		ord, err = w.bind(ctx).LimitFuturesOrder(currencyPair, contractType, price, amount, openType, opt...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ord, nil
+}
+
+func (w *futureRestAPIWithContext) MarketFuturesOrder(ctx context.Context, currencyPair CurrencyPair, contractType, amount string, openType int) (*FutureOrder, error) {
+	var ord *FutureOrder
+	err := callUntilDone(ctx, func() (err error) {
+		ord, err = w.bind(ctx).MarketFuturesOrder(currencyPair, contractType, amount, openType)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ord, nil
+}
+
+func (w *futureRestAPIWithContext) FutureCancelOrder(ctx context.Context, currencyPair CurrencyPair, contractType, orderId string) (bool, error) {
+	var ok bool
+	err := callUntilDone(ctx, func() (err error) {
+		ok, err = w.bind(ctx).FutureCancelOrder(currencyPair, contractType, orderId)
+		return
+	})
+	if err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+func (w *futureRestAPIWithContext) GetFuturePosition(ctx context.Context, currencyPair CurrencyPair, contractType string) ([]FuturePosition, error) {
+	var positions []FuturePosition
+	err := callWithContext(ctx, func() (err error) {
+		positions, err = w.bind(ctx).GetFuturePosition(currencyPair, contractType)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+func (w *futureRestAPIWithContext) GetFutureOrders(ctx context.Context, orderIds []string, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
+	var orders []FutureOrder
+	err := callWithContext(ctx, func() (err error) {
+		orders, err = w.bind(ctx).GetFutureOrders(orderIds, currencyPair, contractType)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (w *futureRestAPIWithContext) GetFutureOrder(ctx context.Context, orderId string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
+	var ord *FutureOrder
+	err := callWithContext(ctx, func() (err error) {
+		ord, err = w.bind(ctx).GetFutureOrder(orderId, currencyPair, contractType)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ord, nil
+}
+
+func (w *futureRestAPIWithContext) GetUnfinishFutureOrders(ctx context.Context, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
+	var orders []FutureOrder
+	err := callWithContext(ctx, func() (err error) {
+		orders, err = w.bind(ctx).GetUnfinishFutureOrders(currencyPair, contractType)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (w *futureRestAPIWithContext) GetFutureOrderHistory(ctx context.Context, pair CurrencyPair, contractType string, optional ...OptionalParameter) ([]FutureOrder, error) {
+	var orders []FutureOrder
+	err := callWithContext(ctx, func() (err error) {
+		orders, err = w.bind(ctx).GetFutureOrderHistory(pair, contractType, optional...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (w *futureRestAPIWithContext) GetFee(ctx context.Context) (float64, error) {
+	var fee float64
+	err := callWithContext(ctx, func() (err error) {
+		fee, err = w.bind(ctx).GetFee()
+		return
+	})
+	if err != nil {
+		return 0, err
+	}
+	return fee, nil
+}
+
+func (w *futureRestAPIWithContext) GetContractValue(ctx context.Context, currencyPair CurrencyPair) (float64, error) {
+	var val float64
+	err := callWithContext(ctx, func() (err error) {
+		val, err = w.bind(ctx).GetContractValue(currencyPair)
+		return
+	})
+	if err != nil {
+		return 0, err
+	}
+	return val, nil
+}
+
+func (w *futureRestAPIWithContext) GetDeliveryTime() (int, int, int, int) {
+	return w.api.GetDeliveryTime()
+}
+
+func (w *futureRestAPIWithContext) GetKlineRecords(ctx context.Context, contractType string, currency CurrencyPair, period KlinePeriod, size int, optional ...OptionalParameter) ([]FutureKline, error) {
+	var klines []FutureKline
+	err := callWithContext(ctx, func() (err error) {
+		klines, err = w.bind(ctx).GetKlineRecords(contractType, currency, period, size, optional...)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return klines, nil
+}
+
+func (w *futureRestAPIWithContext) GetTrades(ctx context.Context, contractType string, currencyPair CurrencyPair, since int64) ([]Trade, error) {
+	var trades []Trade
+	err := callWithContext(ctx, func() (err error) {
+		trades, err = w.bind(ctx).GetTrades(contractType, currencyPair, since)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
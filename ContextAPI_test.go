@@ -0,0 +1,69 @@
+package goex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type slowTickerAPI struct {
+	API
+	delay time.Duration
+}
+
+func (api *slowTickerAPI) GetTicker(currency CurrencyPair) (*Ticker, error) {
+	time.Sleep(api.delay)
+	return &Ticker{Pair: currency, Last: 1}, nil
+}
+
+func (api *slowTickerAPI) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	time.Sleep(api.delay)
+	return &Order{OrderID2: "1", Currency: currency}, nil
+}
+
+func TestNewAPIWithContext(t *testing.T) {
+	api := NewAPIWithContext(&slowTickerAPI{delay: 200 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := api.GetTicker(ctx, BTC_USDT)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	ticker, err := api.GetTicker(context.Background(), BTC_USDT)
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, ticker.Last)
+
+	//下单开始后ctx超时，仍然等待并返回下单结果
+	ord, err := api.LimitBuy(ctx, "1", "1", BTC_USDT)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded)) //ctx已超时，不会开始下单
+	assert.Nil(t, ord)
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel2()
+	ord, err = api.LimitBuy(ctx2, "1", "1", BTC_USDT)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", ord.OrderID2)
+}
+
+func TestHttpClientWithContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := HttpGet(HttpClientWithContext(ctx, http.DefaultClient), srv.URL)
+	assert.NotNil(t, err)
+	assert.True(t, time.Since(start) < time.Second)
+}
@@ -243,6 +243,40 @@ func (pair *CurrencyPair) SetPriceTickSize(tickSize int) CurrencyPair {
 	return *pair
 }
 
+//按PriceTickSize四舍五入价格，未设置精度时原样返回
+func (pair CurrencyPair) RoundPrice(price Decimal) Decimal {
+	if pair.PriceTickSize <= 0 {
+		return price
+	}
+	return price.Round(int32(pair.PriceTickSize))
+}
+
+//按AmountTickSize向下截断数量，避免超出可用余额，未设置精度时原样返回
+func (pair CurrencyPair) RoundAmount(amount Decimal) Decimal {
+	if pair.AmountTickSize <= 0 {
+		return amount
+	}
+	return amount.Truncate(int32(pair.AmountTickSize))
+}
+
+//按pair上设置的精度格式化价格，适配器下单时不会自动调用，交易所精度见MarketRegistry；无法解析(如市价单的空价格)时原样返回
+func (pair CurrencyPair) FormatPrice(price string) string {
+	d, err := NewDecimalFromString(price)
+	if err != nil {
+		return price
+	}
+	return pair.RoundPrice(d).String()
+}
+
+//按pair上设置的精度格式化数量，适配器下单时不会自动调用；无法解析时原样返回
+func (pair CurrencyPair) FormatAmount(amount string) string {
+	d, err := NewDecimalFromString(amount)
+	if err != nil {
+		return amount
+	}
+	return pair.RoundAmount(d).String()
+}
+
 func (pair CurrencyPair) ToSymbol(joinChar string) string {
 	return strings.Join([]string{pair.CurrencyA.Symbol, pair.CurrencyB.Symbol}, joinChar)
 }
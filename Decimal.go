@@ -0,0 +1,302 @@
+package goex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	bigTen  = big.NewInt(10)
+	bigZero = big.NewInt(0)
+
+	DecimalZero = Decimal{}
+)
+
+//十进制定点数 , 值为 value * 10^exp , 用于价格/数量的精确计算
+type Decimal struct {
+	value *big.Int
+	exp   int32
+}
+
+func NewDecimal(value int64, exp int32) Decimal {
+	return Decimal{value: big.NewInt(value), exp: exp}
+}
+
+//支持 "123.45" , "-0.001" , "1e-8" 等格式
+func NewDecimalFromString(s string) (Decimal, error) {
+	str := strings.TrimSpace(s)
+	if str == "" {
+		return DecimalZero, errors.New("decimal: empty string")
+	}
+
+	var exp int64
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(str[i+1:], 10, 32)
+		if err != nil {
+			return DecimalZero, fmt.Errorf("decimal: can't parse %q", s)
+		}
+		exp = e
+		str = str[:i]
+	}
+
+	intPart, fracPart := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		intPart, fracPart = str[:i], str[i+1:]
+	}
+	exp -= int64(len(fracPart))
+
+	digits := intPart + fracPart
+	if digits == "" || digits == "+" || digits == "-" || strings.ContainsAny(digits[1:], "+-") {
+		return DecimalZero, fmt.Errorf("decimal: can't parse %q", s)
+	}
+
+	value, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return DecimalZero, fmt.Errorf("decimal: can't parse %q", s)
+	}
+
+	if exp < math.MinInt32 || exp > math.MaxInt32 {
+		return DecimalZero, fmt.Errorf("decimal: exponent out of range %q", s)
+	}
+
+	return Decimal{value: value, exp: int32(exp)}, nil
+}
+
+//使用float64的最短十进制表示 , 0.1 -> "0.1"
+func NewDecimalFromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return DecimalZero
+	}
+	d, _ := NewDecimalFromString(strconv.FormatFloat(f, 'f', -1, 64))
+	return d
+}
+
+//下单参数中的价格/数量字符串 , 统一输出为不使用科学计数法的十进制 , "1e-3" -> "0.001" ; 无法解析(如市价单的空价格)时原样返回
+func DecimalString(s string) string {
+	d, err := NewDecimalFromString(s)
+	if err != nil {
+		return s
+	}
+	return d.String()
+}
+
+//string/float64/int/int64/json.Number转换为Decimal , 无法解析时返回0
+func ToDecimal(v interface{}) Decimal {
+	if v == nil {
+		return DecimalZero
+	}
+
+	switch vv := v.(type) {
+	case Decimal:
+		return vv
+	case float64:
+		return NewDecimalFromFloat(vv)
+	case float32:
+		return NewDecimalFromFloat(float64(vv))
+	case int:
+		return NewDecimal(int64(vv), 0)
+	case int64:
+		return NewDecimal(vv, 0)
+	case string:
+		d, _ := NewDecimalFromString(vv)
+		return d
+	case json.Number:
+		d, _ := NewDecimalFromString(vv.String())
+		return d
+	default:
+		d, _ := NewDecimalFromString(fmt.Sprint(v))
+		return d
+	}
+}
+
+func (d Decimal) val() *big.Int {
+	if d.value == nil {
+		return bigZero
+	}
+	return d.value
+}
+
+//调整为指定的exp , 仅支持exp <= d.exp
+func (d Decimal) rescale(exp int32) Decimal {
+	if exp >= d.exp {
+		return d
+	}
+	m := new(big.Int).Exp(bigTen, big.NewInt(int64(d.exp-exp)), nil)
+	return Decimal{value: m.Mul(m, d.val()), exp: exp}
+}
+
+func align(d1, d2 Decimal) (Decimal, Decimal) {
+	if d1.exp < d2.exp {
+		return d1, d2.rescale(d1.exp)
+	}
+	return d1.rescale(d2.exp), d2
+}
+
+func (d Decimal) Add(d2 Decimal) Decimal {
+	a, b := align(d, d2)
+	return Decimal{value: new(big.Int).Add(a.val(), b.val()), exp: a.exp}
+}
+
+func (d Decimal) Sub(d2 Decimal) Decimal {
+	a, b := align(d, d2)
+	return Decimal{value: new(big.Int).Sub(a.val(), b.val()), exp: a.exp}
+}
+
+func (d Decimal) Mul(d2 Decimal) Decimal {
+	return Decimal{value: new(big.Int).Mul(d.val(), d2.val()), exp: d.exp + d2.exp}
+}
+
+//除法结果保留places位小数(四舍五入) , 除数为0时panic
+func (d Decimal) Div(d2 Decimal, places int32) Decimal {
+	return d.quo(d2, places, false)
+}
+
+func (d Decimal) quo(d2 Decimal, places int32, truncate bool) Decimal {
+	if d2.Sign() == 0 {
+		panic("decimal: division by zero")
+	}
+
+	num := new(big.Int).Set(d.val())
+	den := new(big.Int).Set(d2.val())
+	shift := int64(d.exp) - int64(d2.exp) + int64(places)
+	if shift >= 0 {
+		num.Mul(num, new(big.Int).Exp(bigTen, big.NewInt(shift), nil))
+	} else {
+		den.Mul(den, new(big.Int).Exp(bigTen, big.NewInt(-shift), nil))
+	}
+
+	return Decimal{value: quoRound(num, den, truncate), exp: -places}
+}
+
+//整数除法 , truncate为false时四舍五入(远离0)
+func quoRound(num, den *big.Int, truncate bool) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if truncate || r.Sign() == 0 {
+		return q
+	}
+
+	r2 := new(big.Int).Abs(r)
+	r2.Lsh(r2, 1)
+	if r2.Cmp(new(big.Int).Abs(den)) >= 0 {
+		if num.Sign()*den.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{value: new(big.Int).Neg(d.val()), exp: d.exp}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{value: new(big.Int).Abs(d.val()), exp: d.exp}
+}
+
+func (d Decimal) Sign() int {
+	return d.val().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// -1: d < d2 , 0: d == d2 , 1: d > d2
+func (d Decimal) Cmp(d2 Decimal) int {
+	a, b := align(d, d2)
+	return a.val().Cmp(b.val())
+}
+
+func (d Decimal) Equal(d2 Decimal) bool {
+	return d.Cmp(d2) == 0
+}
+
+func (d Decimal) round(places int32, truncate bool) Decimal {
+	if d.exp >= -places {
+		return d.rescale(-places)
+	}
+	den := new(big.Int).Exp(bigTen, big.NewInt(int64(-places-d.exp)), nil)
+	return Decimal{value: quoRound(d.val(), den, truncate), exp: -places}
+}
+
+//四舍五入保留places位小数
+func (d Decimal) Round(places int32) Decimal {
+	return d.round(places, false)
+}
+
+//向0截断保留places位小数
+func (d Decimal) Truncate(places int32) Decimal {
+	return d.round(places, true)
+}
+
+//四舍五入到tick的整数倍 , tick<=0时原样返回
+func (d Decimal) RoundToTick(tick Decimal) Decimal {
+	if tick.Sign() <= 0 {
+		return d
+	}
+	return d.quo(tick, 0, false).Mul(tick)
+}
+
+//向0截断到tick的整数倍 , tick<=0时原样返回
+func (d Decimal) TruncateToTick(tick Decimal) Decimal {
+	if tick.Sign() <= 0 {
+		return d
+	}
+	return d.quo(tick, 0, true).Mul(tick)
+}
+
+//不使用科学计数法 , 保留小数位
+func (d Decimal) String() string {
+	if d.exp >= 0 {
+		return d.rescale(0).val().String()
+	}
+
+	abs := new(big.Int).Abs(d.val()).String()
+	places := int(-d.exp)
+	if len(abs) <= places {
+		abs = strings.Repeat("0", places-len(abs)+1) + abs
+	}
+
+	str := abs[:len(abs)-places] + "." + abs[len(abs)-places:]
+	if d.Sign() < 0 {
+		str = "-" + str
+	}
+	return str
+}
+
+//四舍五入保留places位小数后的字符串
+func (d Decimal) StringFixed(places int32) string {
+	return d.Round(places).String()
+}
+
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+//支持 "0.1" 和 0.1 两种格式
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	str := strings.Trim(string(data), `"`)
+	if str == "null" || str == "" {
+		*d = DecimalZero
+		return nil
+	}
+
+	v, err := NewDecimalFromString(str)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
@@ -0,0 +1,124 @@
+package goex
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecimal_String(t *testing.T) {
+	cases := map[string]string{
+		"0.1":      "0.1",
+		"-0.001":   "-0.001",
+		"123":      "123",
+		"1e-8":     "0.00000001",
+		"1.5E3":    "1500",
+		".5":       "0.5",
+		"+12.3400": "12.3400",
+	}
+	for in, out := range cases {
+		d, err := NewDecimalFromString(in)
+		if err != nil {
+			t.Fatal(in, err)
+		}
+		if d.String() != out {
+			t.Fatalf("%s: expect %s , got %s", in, out, d.String())
+		}
+	}
+
+	for _, in := range []string{"", "abc", "1.2.3", "--1", "1e"} {
+		if _, err := NewDecimalFromString(in); err == nil {
+			t.Fatalf("%q should be invalid", in)
+		}
+	}
+}
+
+func TestDecimalString(t *testing.T) {
+	cases := map[string]string{
+		"0.1":    "0.1",
+		"1e-3":   "0.001",
+		"2.50":   "2.50",
+		"":       "",
+		"market": "market",
+	}
+	for in, out := range cases {
+		if DecimalString(in) != out {
+			t.Fatalf("%q: expect %s , got %s", in, out, DecimalString(in))
+		}
+	}
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	a, b := ToDecimal(0.1), ToDecimal("0.2")
+	if a.Add(b).String() != "0.3" {
+		t.Fatal(a.Add(b))
+	}
+	if a.Sub(b).String() != "-0.1" {
+		t.Fatal(a.Sub(b))
+	}
+	if a.Mul(b).String() != "0.02" {
+		t.Fatal(a.Mul(b))
+	}
+	if ToDecimal(1).Div(ToDecimal(3), 4).String() != "0.3333" {
+		t.Fatal(ToDecimal(1).Div(ToDecimal(3), 4))
+	}
+	if ToDecimal(2).Div(ToDecimal(-3), 2).String() != "-0.67" {
+		t.Fatal(ToDecimal(2).Div(ToDecimal(-3), 2))
+	}
+	if a.Cmp(b) != -1 || !ToDecimal("0.30").Equal(ToDecimal(0.3)) {
+		t.Fatal("cmp error")
+	}
+}
+
+func TestDecimal_Round(t *testing.T) {
+	d := ToDecimal("1.2355")
+	if d.Round(3).String() != "1.236" || d.Truncate(3).String() != "1.235" {
+		t.Fatal(d.Round(3), d.Truncate(3))
+	}
+	if ToDecimal("-1.2355").Round(3).String() != "-1.236" {
+		t.Fatal(ToDecimal("-1.2355").Round(3))
+	}
+	if ToDecimal("1.2").Round(3).String() != "1.200" {
+		t.Fatal(ToDecimal("1.2").Round(3))
+	}
+	if ToDecimal("10.26").RoundToTick(ToDecimal("0.5")).String() != "10.5" {
+		t.Fatal(ToDecimal("10.26").RoundToTick(ToDecimal("0.5")))
+	}
+	if ToDecimal("10.99").TruncateToTick(ToDecimal("0.25")).String() != "10.75" {
+		t.Fatal(ToDecimal("10.99").TruncateToTick(ToDecimal("0.25")))
+	}
+}
+
+func TestDecimal_JSON(t *testing.T) {
+	var v struct {
+		Price  Decimal `json:"price"`
+		Amount Decimal `json:"amount"`
+	}
+	err := json.Unmarshal([]byte(`{"price":"9876.54","amount":0.30000000000000004}`), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Price.String() != "9876.54" || v.Amount.Round(8).String() != "0.30000000" {
+		t.Fatal(v.Price, v.Amount)
+	}
+
+	data, _ := json.Marshal(v)
+	if string(data) != `{"price":"9876.54","amount":"0.30000000000000004"}` {
+		t.Fatal(string(data))
+	}
+}
+
+func TestCurrencyPair_FormatPrice(t *testing.T) {
+	pair := NewCurrencyPair2("BTC_USDT")
+	if pair.FormatPrice("9876.543") != "9876.543" {
+		t.Fatal(pair.FormatPrice("9876.543"))
+	}
+
+	pair.SetPriceTickSize(2)
+	pair.SetAmountTickSize(3)
+	if pair.FormatPrice("9876.545") != "9876.55" || pair.FormatAmount("0.30000000000000004") != "0.300" {
+		t.Fatal(pair.FormatPrice("9876.545"), pair.FormatAmount("0.30000000000000004"))
+	}
+	if pair.FormatPrice("") != "" {
+		t.Fatal(pair.FormatPrice(""))
+	}
+}
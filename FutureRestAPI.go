@@ -1,5 +1,7 @@
 package goex
 
+import "context"
+
 type FutureRestAPI interface {
 	/**
 	 *获取交易所名字
@@ -123,3 +125,111 @@ type FutureRestAPI interface {
 	 */
 	GetTrades(contractType string, currencyPair CurrencyPair, since int64) ([]Trade, error)
 }
+
+// context-aware futures api interface , 每个网络调用都受ctx控制(取消/超时)
+type FutureRestAPIWithContext interface {
+	GetExchangeName() string
+	GetFutureEstimatedPrice(ctx context.Context, currencyPair CurrencyPair) (float64, error)
+	GetFutureTicker(ctx context.Context, currencyPair CurrencyPair, contractType string) (*Ticker, error)
+	GetFutureDepth(ctx context.Context, currencyPair CurrencyPair, contractType string, size int) (*Depth, error)
+	GetFutureIndex(ctx context.Context, currencyPair CurrencyPair) (float64, error)
+	GetFutureUserinfo(ctx context.Context, currencyPair ...CurrencyPair) (*FutureAccount, error)
+	PlaceFutureOrder(ctx context.Context, currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice int, leverRate float64) (string, error)
+	LimitFuturesOrder(ctx context.Context, currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (*FutureOrder, error)
+	MarketFuturesOrder(ctx context.Context, currencyPair CurrencyPair, contractType, amount string, openType int) (*FutureOrder, error)
+	FutureCancelOrder(ctx context.Context, currencyPair CurrencyPair, contractType, orderId string) (bool, error)
+	GetFuturePosition(ctx context.Context, currencyPair CurrencyPair, contractType string) ([]FuturePosition, error)
+	GetFutureOrders(ctx context.Context, orderIds []string, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error)
+	GetFutureOrder(ctx context.Context, orderId string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error)
+	GetUnfinishFutureOrders(ctx context.Context, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error)
+	GetFutureOrderHistory(ctx context.Context, pair CurrencyPair, contractType string, optional ...OptionalParameter) ([]FutureOrder, error)
+	GetFee(ctx context.Context) (float64, error)
+	GetContractValue(ctx context.Context, currencyPair CurrencyPair) (float64, error)
+	GetDeliveryTime() (int, int, int, int)
+	GetKlineRecords(ctx context.Context, contractType string, currency CurrencyPair, period KlinePeriod, size int, optional ...OptionalParameter) ([]FutureKline, error)
+	GetTrades(ctx context.Context, contractType string, currencyPair CurrencyPair, since int64) ([]Trade, error)
+}
+
+// 支持ctx的期货交易所实现该接口，返回一个绑定了ctx的FutureRestAPI副本
+type FutureContextBinder interface {
+	WithContext(ctx context.Context) FutureRestAPI
+}
+
+// 同APIUnwrapper，期货装饰器实现该接口
+type FutureRestAPIUnwrapper interface {
+	Unwrap() FutureRestAPI
+}
+
+// 逐层解开期货装饰器，返回最内层的交易所实现
+func UnwrapFutureRestAPI(api FutureRestAPI) FutureRestAPI {
+	for {
+		u, ok := api.(FutureRestAPIUnwrapper)
+		if !ok {
+			return api
+		}
+		api = u.Unwrap()
+	}
+}
+
+// 期货批量下单/撤单，没有批量接口的交易所可以用NewBatchFutureOrderAPI并发逐个调用，分批请求失败时同BatchOrderAPI
+type BatchFutureOrderAPI interface {
+	//批量限价单，使用FutureOrder的Currency/ContractName/OType/Price/Amount/ClientOid/OrderType字段，结果与orders一一对应
+	BatchLimitFuturesOrders(orders []FutureOrder) ([]BatchOrderResult, error)
+	//批量撤单，结果与orderIds一一对应
+	BatchFutureCancelOrders(currencyPair CurrencyPair, contractType string, orderIds []string) ([]BatchOrderResult, error)
+	//撤销该合约的所有未完成订单
+	FutureCancelAllOrders(currencyPair CurrencyPair, contractType string) (bool, error)
+}
+
+// 修改未完成的期货限价单，交易所不支持时可以用NewAmendFutureOrderAPI撤单后重新下单
+type AmendFutureOrderAPI interface {
+	//newPrice , newAmount 为空表示不修改，newAmount为修改后的委托总数量(包含已成交部分)
+	AmendFutureOrder(currencyPair CurrencyPair, contractType, orderId, newPrice, newAmount string) (*FutureOrder, error)
+}
+
+// 按客户端订单ID查询和撤销期货订单
+type FutureClientOrderIdAPI interface {
+	GetFutureOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error)
+	FutureCancelOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (bool, error)
+}
+
+// 指定客户端订单ID下单，openType为openlong/openshort/closelong/closeshort(见OpenTypeName)
+type FutureCidOrderAPI interface {
+	PlaceFutureOrderWithCid(cid string, currencyPair CurrencyPair, contractType, price, amount, openType string, matchPrice int, leverRate float64) (string, error)
+	LimitFuturesOrderWithCid(cid string, currencyPair CurrencyPair, contractType, price, amount, openType string, opt ...LimitOrderOptionalParameter) (*FutureOrder, error)
+	MarketFuturesOrderWithCid(cid string, currencyPair CurrencyPair, contractType, amount, openType string) (*FutureOrder, error)
+	GetFutureOrderByCid(cid string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error)
+}
+
+// 永续合约的资金费率、标记价格、指数价格和持仓量，时间均为毫秒时间戳
+type PerpetualDataAPI interface {
+	//当前周期资金费率及结算时间，交易所提供时包含预测的下一周期费率
+	GetFundingRate(currencyPair CurrencyPair, contractType string) (*FundingRate, error)
+	//已结算的资金费率，按结算时间降序；start/end为0表示不限制，size<=0使用交易所默认条数
+	GetFundingRateHistory(currencyPair CurrencyPair, contractType string, start, end int64, size int) ([]FundingRate, error)
+	//标记价格和指数价格
+	GetMarkPrice(currencyPair CurrencyPair, contractType string) (*MarkPrice, error)
+	GetOpenInterest(currencyPair CurrencyPair, contractType string) (*OpenInterest, error)
+}
+
+// 杠杆倍数、保证金模式、持仓模式和逐仓保证金管理，设置后对之后的下单生效
+// 交易所不支持的设置返回EX_ERR_NOT_SUPPORTED
+type FutureLeverageAPI interface {
+	GetLeverage(currencyPair CurrencyPair, contractType string) (float64, error)
+	SetLeverage(currencyPair CurrencyPair, contractType string, lever int) error
+	//有持仓或挂单时交易所通常会拒绝修改
+	SetMarginMode(currencyPair CurrencyPair, contractType string, mode MarginMode) error
+	//账户级设置，hedge=true为双向持仓，false为单向持仓
+	SetPositionMode(hedge bool) error
+	//调整逐仓保证金，amount>0增加，amount<0减少，单位为保证金币种；positionSide为OPEN_BUY(多仓)或OPEN_SELL(空仓)，单向持仓时忽略
+	ModifyIsolatedMargin(currencyPair CurrencyPair, contractType string, positionSide int, amount float64) error
+}
+
+// 条件单：止损、止盈和跟踪止损，交易所不支持的类型返回EX_ERR_NOT_SUPPORTED
+type FutureTriggerOrderAPI interface {
+	//使用TriggerOrder的Currency/ContractType/Type/OType/Amount/TriggerPrice/Price/CallbackRate/ReduceOnly/ClosePosition/ClientOid字段，返回的OrderId用于撤单
+	PlaceTriggerOrder(ord *TriggerOrder) (*TriggerOrder, error)
+	CancelTriggerOrder(currencyPair CurrencyPair, contractType, orderId string) (bool, error)
+	//未触发的条件单
+	GetUnfinishTriggerOrders(currencyPair CurrencyPair, contractType string) ([]TriggerOrder, error)
+}
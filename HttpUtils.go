@@ -2,14 +2,15 @@ package goex
 
 //http request 工具函数
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,12 +30,64 @@ var (
 	socksDialer fasthttp.DialFunc
 )
 
+//http状态码非200时返回的错误
+type HttpError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration //Retry-After响应头
+}
+
+func newHttpError(statusCode int, body []byte, retryAfter string) *HttpError {
+	e := &HttpError{StatusCode: statusCode, Body: string(body)}
+	if sec, err := strconv.Atoi(strings.TrimSpace(retryAfter)); err == nil && sec > 0 {
+		e.RetryAfter = time.Duration(sec) * time.Second
+	}
+	return e
+}
+
+func (e *HttpError) Error() string {
+	return fmt.Sprintf("HttpStatusCode:%d ,Desc:%s", e.StatusCode, e.Body)
+}
+
+//429/418: EX_ERR_API_LIMIT , 401: EX_ERR_AUTH_FAIL , 503: EX_ERR_MAINTENANCE
+func (e *HttpError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusTeapot:
+		return EX_ERR_API_LIMIT.Is(target)
+	case http.StatusUnauthorized:
+		return EX_ERR_AUTH_FAIL.Is(target)
+	case http.StatusServiceUnavailable:
+		return EX_ERR_MAINTENANCE.Is(target)
+	}
+	return false
+}
+
 func NewHttpRequestWithFasthttp(client *http.Client, reqMethod, reqUrl, postData string, headers map[string]string) ([]byte, error) {
+	return newHttpRequestWithFasthttp(context.Background(), client, reqMethod, reqUrl, postData, headers)
+}
+
+func newHttpRequestWithFasthttp(ctx context.Context, client *http.Client, reqMethod, reqUrl, postData string, headers map[string]string) ([]byte, error) {
 	logger.Log.Debug("use fasthttp client")
 	transport := client.Transport
 
-	if transport != nil {
-		if proxy, err := transport.(*http.Transport).Proxy(nil); err == nil && proxy != nil {
+	//fasthttp不经过http.Transport，需要单独调用限频器
+	var (
+		limiter    RateLimiter
+		limiterReq *http.Request
+	)
+	if t, ok := transport.(*RateLimitTransport); ok {
+		limiter, transport = t.Limiter, t.Base
+		var err error
+		if limiterReq, err = http.NewRequestWithContext(ctx, reqMethod, reqUrl, nil); err != nil {
+			return nil, err
+		}
+		if err = limiter.Wait(ctx, limiterReq); err != nil {
+			return nil, err
+		}
+	}
+
+	if transport, ok := transport.(*http.Transport); ok && transport.Proxy != nil {
+		if proxy, err := transport.Proxy(nil); err == nil && proxy != nil {
 			proxyUrl := proxy.String()
 			logger.Log.Debug("proxy url: ", proxyUrl)
 			if proxy.Scheme != "socks5" {
@@ -60,25 +113,89 @@ func NewHttpRequestWithFasthttp(client *http.Client, reqMethod, reqUrl, postData
 	req.SetRequestURI(reqUrl)
 	req.SetBodyString(postData)
 
-	err := fastHttpClient.Do(req, resp)
+	var err error
+	if deadline, ok := ctx.Deadline(); ok {
+		err = fastHttpClient.DoDeadline(req, resp, deadline)
+	} else {
+		err = fastHttpClient.Do(req, resp)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if limiter != nil {
+		header := http.Header{}
+		resp.Header.VisitAll(func(k, v []byte) {
+			header.Add(string(k), string(v))
+		})
+		limiter.Update(limiterReq, &http.Response{StatusCode: resp.StatusCode(), Header: header})
+	}
+
 	if resp.StatusCode() != 200 {
-		return nil, errors.New(fmt.Sprintf("HttpStatusCode:%d ,Desc:%s", resp.StatusCode(), string(resp.Body())))
+		return nil, newHttpError(resp.StatusCode(), resp.Body(), string(resp.Header.Peek("Retry-After")))
 	}
 	return resp.Body(), nil
 }
 
+//http client bound to a context , see HttpClientWithContext
+type contextTransport struct {
+	ctx    context.Context
+	client *http.Client
+}
+
+func (t *contextTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return transport.RoundTrip(req.WithContext(t.ctx))
+}
+
+/**
+ * 返回一个绑定了ctx的http client副本，通过该client发出的请求(HttpGet/HttpPostForm...)都受ctx控制(取消/超时)
+ */
+func HttpClientWithContext(ctx context.Context, client *http.Client) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if t, ok := client.Transport.(*contextTransport); ok {
+		client = t.client
+	}
+	return &http.Client{
+		Transport:     &contextTransport{ctx: ctx, client: client},
+		CheckRedirect: client.CheckRedirect,
+		Jar:           client.Jar,
+		Timeout:       client.Timeout,
+	}
+}
+
 func NewHttpRequest(client *http.Client, reqType string, reqUrl string, postData string, requstHeaders map[string]string) ([]byte, error) {
+	ctx := context.Background()
+	if t, ok := client.Transport.(*contextTransport); ok {
+		ctx, client = t.ctx, t.client
+	}
+	return NewHttpRequestWithContext(ctx, client, reqType, reqUrl, postData, requstHeaders)
+}
+
+func NewHttpRequestWithContext(ctx context.Context, client *http.Client, reqType string, reqUrl string, postData string, requstHeaders map[string]string) ([]byte, error) {
 	logger.Log.Debugf("[%s] request url: %s", reqType, reqUrl)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	lib := os.Getenv("HTTP_LIB")
 	if lib == "fasthttp" {
-		return NewHttpRequestWithFasthttp(client, reqType, reqUrl, postData, requstHeaders)
+		return newHttpRequestWithFasthttp(ctx, client, reqType, reqUrl, postData, requstHeaders)
 	}
 
-	req, _ := http.NewRequest(reqType, reqUrl, strings.NewReader(postData))
+	req, err := http.NewRequestWithContext(ctx, reqType, reqUrl, strings.NewReader(postData))
+	if err != nil {
+		return nil, err
+	}
 	if req.Header.Get("User-Agent") == "" {
 		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 5.1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/31.0.1650.63 Safari/537.36")
 	}
@@ -101,7 +218,7 @@ func NewHttpRequest(client *http.Client, reqType string, reqUrl string, postData
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, errors.New(fmt.Sprintf("HttpStatusCode:%d ,Desc:%s", resp.StatusCode, string(bodyData)))
+		return nil, newHttpError(resp.StatusCode, bodyData, resp.Header.Get("Retry-After"))
 	}
 
 	return bodyData, nil
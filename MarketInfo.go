@@ -0,0 +1,278 @@
+package goex
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrwill84/goex/internal/logger"
+)
+
+type MarketStatus int
+
+const (
+	MARKET_TRADING MarketStatus = iota //可交易
+	MARKET_SUSPEND                     //暂停交易
+	MARKET_OFFLINE                     //已下线/已交割
+)
+
+func (s MarketStatus) String() string {
+	switch s {
+	case MARKET_TRADING:
+		return "TRADING"
+	case MARKET_SUSPEND:
+		return "SUSPEND"
+	case MARKET_OFFLINE:
+		return "OFFLINE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+//交易所提供的交易对/合约交易规则，值为0表示交易所没有该限制
+type Market struct {
+	Pair         CurrencyPair
+	ContractType string   //现货为空，永续合约为SWAP_CONTRACT
+	Symbol       string   //交易所的symbol , 如 BTCUSDT , BTC-USD-SWAP
+	Aliases      []string //交易所的其它id , 如kraken的XBTUSD , XBT/USD , 火币交割合约的BTC_CQ
+	TickSize     Decimal  //价格步长
+	LotSize      Decimal  //数量步长(合约为张数步长)
+	MinAmount    Decimal  //最小下单数量
+	MinNotional  Decimal  //最小下单金额(计价币)
+	ContractVal  Decimal  //合约面值，U本位合约以币计，币本位合约(Inverse)以计价币计
+	Inverse      bool     //币本位反向合约
+	Status       MarketStatus
+}
+
+//按TickSize四舍五入价格
+func (m *Market) RoundPrice(price Decimal) Decimal {
+	return price.RoundToTick(m.TickSize)
+}
+
+//按LotSize向下截断数量，避免超出可用余额
+func (m *Market) RoundAmount(amount Decimal) Decimal {
+	return amount.TruncateToTick(m.LotSize)
+}
+
+//下单金额(计价币)，合约数量为张数时乘以合约面值
+func (m *Market) Notional(price, amount Decimal) Decimal {
+	switch {
+	case m.ContractVal.Sign() <= 0:
+		return price.Mul(amount)
+	case m.Inverse:
+		return amount.Mul(m.ContractVal)
+	default:
+		return price.Mul(amount).Mul(m.ContractVal)
+	}
+}
+
+//校验(已修正的)价格和数量是否满足交易规则
+func (m *Market) Validate(price, amount Decimal) error {
+	if m.Status != MARKET_TRADING {
+		return EX_ERR_MARKET_NOT_TRADING.OriginErr(fmt.Sprintf("%s status %s", m.Symbol, m.Status))
+	}
+
+	if price.Sign() <= 0 {
+		return EX_ERR_INVALID_ORDER_PARAM.OriginErr(fmt.Sprintf("invalid price %s", price))
+	}
+
+	if amount.Sign() <= 0 || amount.Cmp(m.MinAmount) < 0 {
+		return EX_ERR_INVALID_ORDER_PARAM.OriginErr(fmt.Sprintf("amount %s less than min amount %s", amount, m.MinAmount))
+	}
+
+	if notional := m.Notional(price, amount); notional.Cmp(m.MinNotional) < 0 {
+		return EX_ERR_INVALID_ORDER_PARAM.OriginErr(fmt.Sprintf("notional %s less than min notional %s", notional, m.MinNotional))
+	}
+
+	return nil
+}
+
+//各交易所实现，获取全部交易对/合约的交易规则
+type MarketInfo interface {
+	GetMarkets() ([]Market, error)
+}
+
+//交易规则缓存，第一次查询时拉取，之后每隔expire在后台goroutine中刷新，下单路径不会等待刷新
+type MarketRegistry struct {
+	info   MarketInfo
+	expire time.Duration
+
+	lock      sync.RWMutex
+	markets   map[string]*Market
+	symbols   map[string][]*Market //交易所symbol及别名(大写) -> Market , 合并多个接口时不同类型的市场可能同名(binance现货和U本位合约的BTCUSDT)
+	updatedAt time.Time
+
+	refreshLock sync.Mutex
+	startOnce   sync.Once
+	closeOnce   sync.Once
+	closed      chan struct{}
+}
+
+//expire<=0时只在第一次查询时拉取
+func NewMarketRegistry(info MarketInfo, expire time.Duration) *MarketRegistry {
+	return &MarketRegistry{
+		info:    info,
+		expire:  expire,
+		markets: make(map[string]*Market),
+		symbols: make(map[string][]*Market),
+		closed:  make(chan struct{}),
+	}
+}
+
+func marketKey(pair CurrencyPair, contractType string) string {
+	if contractType == SWAP_USDT_CONTRACT {
+		contractType = SWAP_CONTRACT //永续合约以计价币区分
+	}
+	return strings.ToUpper(pair.ToSymbol("_")) + "@" + contractType
+}
+
+//立即重新拉取交易规则
+func (r *MarketRegistry) Refresh() error {
+	r.refreshLock.Lock()
+	defer r.refreshLock.Unlock()
+
+	markets, err := r.info.GetMarkets()
+	if err != nil {
+		return err
+	}
+
+	m := make(map[string]*Market, len(markets))
+	symbols := make(map[string][]*Market, len(markets))
+	for i := range markets {
+		m[marketKey(markets[i].Pair, markets[i].ContractType)] = &markets[i]
+		for _, symbol := range append([]string{markets[i].Symbol}, markets[i].Aliases...) {
+			symbol = strings.ToUpper(symbol)
+			if findMarket(symbols[symbol], markets[i].ContractType) != nil {
+				logger.Warnf("[market registry] duplicate market %s %s , ignored", symbol, markets[i].ContractType)
+				continue
+			}
+			symbols[symbol] = append(symbols[symbol], &markets[i])
+		}
+	}
+
+	r.lock.Lock()
+	r.markets = m
+	r.symbols = symbols
+	r.updatedAt = time.Now()
+	r.lock.Unlock()
+
+	return nil
+}
+
+//停止后台刷新
+func (r *MarketRegistry) Close() {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+	})
+}
+
+func (r *MarketRegistry) UpdatedAt() time.Time {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.updatedAt
+}
+
+//还没有缓存时同步拉取，并启动后台刷新
+func (r *MarketRegistry) load() error {
+	if !r.UpdatedAt().IsZero() {
+		return nil
+	}
+
+	r.refreshLock.Lock()
+	loaded := !r.updatedAt.IsZero() //等待锁期间其它goroutine已经拉取
+	r.refreshLock.Unlock()
+	if !loaded {
+		if err := r.Refresh(); err != nil {
+			return err
+		}
+	}
+
+	if r.expire > 0 {
+		r.startOnce.Do(func() {
+			go r.refreshLoop()
+		})
+	}
+	return nil
+}
+
+//定时刷新，失败时继续使用旧的缓存
+func (r *MarketRegistry) refreshLoop() {
+	tick := time.NewTicker(r.expire)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-r.closed:
+			return
+		case <-tick.C:
+			if err := r.Refresh(); err != nil {
+				logger.Warnf("[market registry] refresh error: %s , use the cached markets", err.Error())
+			}
+		}
+	}
+}
+
+//获取交易规则，现货contractType传空
+func (r *MarketRegistry) GetMarket(pair CurrencyPair, contractType string) (*Market, error) {
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	m, ok := r.markets[marketKey(pair, contractType)]
+	if !ok {
+		return nil, EX_ERR_SYMBOL_ERR.OriginErr(fmt.Sprintf("market %s %s not found", pair, contractType))
+	}
+
+	return m, nil
+}
+
+//同一symbol下按合约类型查找，SWAP_USDT_CONTRACT和SWAP_CONTRACT等价
+func findMarket(markets []*Market, contractType string) *Market {
+	for _, m := range markets {
+		if marketKey(m.Pair, m.ContractType) == marketKey(m.Pair, contractType) {
+			return m
+		}
+	}
+	return nil
+}
+
+//按交易所的symbol或别名获取交易规则，不区分大小写；
+//contractTypes为候选的类型(现货为空字符串)，不传时匹配所有类型，匹配到多个市场时返回错误
+func (r *MarketRegistry) GetMarketBySymbol(symbol string, contractTypes ...string) (*Market, error) {
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	candidates := r.symbols[strings.ToUpper(symbol)]
+	if len(contractTypes) > 0 {
+		var matched []*Market
+		for _, contractType := range contractTypes {
+			if m := findMarket(candidates, contractType); m != nil {
+				matched = append(matched, m)
+			}
+		}
+		candidates = matched
+	}
+
+	switch len(candidates) {
+	case 0:
+		return nil, EX_ERR_SYMBOL_ERR.OriginErr(fmt.Sprintf("market %s %v not found", symbol, contractTypes))
+	case 1:
+		return candidates[0], nil
+	default:
+		return nil, EX_ERR_SYMBOL_ERR.OriginErr(fmt.Sprintf("market %s is ambiguous , specify the contract type", symbol))
+	}
+}
+
+func (r *MarketRegistry) Len() int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return len(r.markets)
+}
@@ -0,0 +1,177 @@
+package goex
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockMarketInfo struct {
+	lock  sync.Mutex
+	calls int
+	err   error
+}
+
+func (m *mockMarketInfo) setErr(err error) {
+	m.lock.Lock()
+	m.err = err
+	m.lock.Unlock()
+}
+
+func (m *mockMarketInfo) getCalls() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.calls
+}
+
+func (m *mockMarketInfo) GetMarkets() ([]Market, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []Market{
+		{
+			Pair:        BTC_USDT,
+			Symbol:      "BTCUSDT",
+			TickSize:    ToDecimal("0.5"),
+			LotSize:     ToDecimal("0.001"),
+			MinAmount:   ToDecimal("0.001"),
+			MinNotional: ToDecimal("10"),
+		},
+		{
+			Pair:         BTC_USD,
+			ContractType: SWAP_CONTRACT,
+			Symbol:       "BTC-USD-SWAP",
+			TickSize:     ToDecimal("0.1"),
+			LotSize:      ToDecimal("1"),
+			ContractVal:  ToDecimal("100"),
+			MinNotional:  ToDecimal("100"),
+			Inverse:      true,
+		},
+		{
+			Pair:         ETH_USDT,
+			ContractType: SWAP_CONTRACT,
+			Symbol:       "ETH-USDT-SWAP",
+			TickSize:     ToDecimal("0.01"),
+			LotSize:      ToDecimal("1"),
+			ContractVal:  ToDecimal("0.1"),
+			MinNotional:  ToDecimal("5"),
+		},
+		{Pair: ETH_USDT, Symbol: "ETHUSDT", Status: MARKET_SUSPEND},
+	}, nil
+}
+
+type mockOrderAPI struct {
+	API
+	amount, price string
+}
+
+func (m *mockOrderAPI) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	m.amount, m.price = amount, price
+	return &Order{Amount: ToFloat64(amount), Price: ToFloat64(price), Currency: currency}, nil
+}
+
+func TestMarketRegistry_GetMarket(t *testing.T) {
+	info := &mockMarketInfo{}
+	registry := NewMarketRegistry(info, 0)
+
+	m, err := registry.GetMarket(BTC_USD, SWAP_USDT_CONTRACT)
+	if err != nil || m.Symbol != "BTC-USD-SWAP" {
+		t.Fatal(m, err)
+	}
+
+	if _, err = registry.GetMarket(NewCurrencyPair2("btc_usdt"), ""); err != nil || info.getCalls() != 1 {
+		t.Fatal(err, info.getCalls())
+	}
+
+	if _, err = registry.GetMarket(LTC_USDT, ""); err == nil {
+		t.Fatal("market should be not found")
+	}
+}
+
+func TestMarketRegistry_BackgroundRefresh(t *testing.T) {
+	info := &mockMarketInfo{}
+	registry := NewMarketRegistry(info, 20*time.Millisecond)
+	defer registry.Close()
+
+	if _, err := registry.GetMarket(BTC_USDT, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for info.getCalls() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("registry not refreshed in background")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	//刷新失败时使用旧的缓存
+	info.setErr(errors.New("network error"))
+	calls := info.getCalls()
+	for info.getCalls() < calls+2 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := registry.GetMarket(BTC_USDT, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	registry.Close()
+	time.Sleep(50 * time.Millisecond)
+	calls = info.getCalls()
+	time.Sleep(50 * time.Millisecond)
+	if info.getCalls() != calls {
+		t.Fatal("refresh loop not stopped")
+	}
+}
+
+func TestMarket_ValidateContract(t *testing.T) {
+	registry := NewMarketRegistry(&mockMarketInfo{}, 0)
+
+	//U本位：张数 * 面值(0.1ETH) * 价格
+	linear, _ := registry.GetMarket(ETH_USDT, SWAP_USDT_CONTRACT)
+	if n := linear.Notional(ToDecimal("3000"), ToDecimal("10")); n.Cmp(ToDecimal("3000")) != 0 {
+		t.Fatal(n)
+	}
+	if err := linear.Validate(ToDecimal("40"), ToDecimal("1")); err == nil {
+		t.Fatal("notional 4 less than min notional 5")
+	}
+	if err := linear.Validate(ToDecimal("3000"), ToDecimal("1")); err != nil {
+		t.Fatal(err)
+	}
+
+	//币本位：张数 * 面值(100USD)，与价格无关
+	inverse, _ := registry.GetMarket(BTC_USD, SWAP_CONTRACT)
+	if n := inverse.Notional(ToDecimal("30000"), ToDecimal("3")); n.Cmp(ToDecimal("300")) != 0 {
+		t.Fatal(n)
+	}
+	if err := inverse.Validate(ToDecimal("30000"), ToDecimal("1")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMarketRoundingAPI_LimitBuy(t *testing.T) {
+	mock := &mockOrderAPI{}
+	api := NewMarketRoundingAPI(mock, NewMarketRegistry(&mockMarketInfo{}, 0))
+
+	_, err := api.LimitBuy("0.12345", "9876.3", BTC_USDT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mock.amount != "0.123" || mock.price != "9876.5" {
+		t.Fatal(mock.amount, mock.price)
+	}
+
+	if _, err = api.LimitBuy("0.0009", "9876", BTC_USDT); err == nil {
+		t.Fatal("amount less than min amount")
+	}
+	if _, err = api.LimitBuy("0.001", "9876", BTC_USDT); err == nil {
+		t.Fatal("notional less than min notional")
+	}
+	if _, err = api.LimitBuy("1", "100", ETH_USDT); err == nil {
+		t.Fatal("market not trading")
+	}
+}
@@ -0,0 +1,90 @@
+package goex
+
+// 下单前按交易所的交易规则修正限价单的价格(四舍五入到TickSize)和数量(向下截断到LotSize)，
+// 并校验最小数量/最小金额/交易状态，不满足时不发送请求直接返回错误；其它方法直接调用被包装的api
+// 返回值只实现API，可选接口(ContextBinder、ClientOrderIdAPI等)需要UnwrapAPI后断言
+func NewMarketRoundingAPI(api API, registry *MarketRegistry) API {
+	return &marketRoundingAPI{API: api, registry: registry}
+}
+
+// 同NewMarketRoundingAPI，作用于LimitFuturesOrder和限价的PlaceFutureOrder，可选接口需要UnwrapFutureRestAPI后断言
+func NewMarketRoundingFutureRestAPI(api FutureRestAPI, registry *MarketRegistry) FutureRestAPI {
+	return &marketRoundingFutureRestAPI{FutureRestAPI: api, registry: registry}
+}
+
+func roundOrder(registry *MarketRegistry, pair CurrencyPair, contractType, price, amount string) (string, string, error) {
+	m, err := registry.GetMarket(pair, contractType)
+	if err != nil {
+		return "", "", err
+	}
+
+	p, err := NewDecimalFromString(price)
+	if err != nil {
+		return "", "", EX_ERR_INVALID_ORDER_PARAM.OriginErr("invalid price " + price)
+	}
+
+	a, err := NewDecimalFromString(amount)
+	if err != nil {
+		return "", "", EX_ERR_INVALID_ORDER_PARAM.OriginErr("invalid amount " + amount)
+	}
+
+	p, a = m.RoundPrice(p), m.RoundAmount(a)
+	if err = m.Validate(p, a); err != nil {
+		return "", "", err
+	}
+
+	return p.String(), a.String(), nil
+}
+
+type marketRoundingAPI struct {
+	API
+	registry *MarketRegistry
+}
+
+func (r *marketRoundingAPI) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	price, amount, err := roundOrder(r.registry, currency, "", price, amount)
+	if err != nil {
+		return nil, err
+	}
+	return r.API.LimitBuy(amount, price, currency, opt...)
+}
+
+func (r *marketRoundingAPI) LimitSell(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	price, amount, err := roundOrder(r.registry, currency, "", price, amount)
+	if err != nil {
+		return nil, err
+	}
+	return r.API.LimitSell(amount, price, currency, opt...)
+}
+
+func (r *marketRoundingAPI) Unwrap() API {
+	return r.API
+}
+
+type marketRoundingFutureRestAPI struct {
+	FutureRestAPI
+	registry *MarketRegistry
+}
+
+func (r *marketRoundingFutureRestAPI) PlaceFutureOrder(currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice int, leverRate float64) (string, error) {
+	if matchPrice == 0 {
+		var err error
+		price, amount, err = roundOrder(r.registry, currencyPair, contractType, price, amount)
+		if err != nil {
+			return "", err
+		}
+	}
+	return r.FutureRestAPI.PlaceFutureOrder(currencyPair, contractType, price, amount, openType, matchPrice, leverRate)
+}
+
+func (r *marketRoundingFutureRestAPI) LimitFuturesOrder(currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
+	price, amount, err := roundOrder(r.registry, currencyPair, contractType, price, amount)
+	if err != nil {
+		return nil, err
+	}
+	return r.FutureRestAPI.LimitFuturesOrder(currencyPair, contractType, price, amount, openType, opt...)
+}
+
+func (r *marketRoundingFutureRestAPI) Unwrap() FutureRestAPI {
+	return r.FutureRestAPI
+}
@@ -22,6 +22,22 @@ type Order struct {
 	OrderType    int    //0:default,1:maker,2:fok,3:ioc
 	OrderTime    int    // create  timestamp
 	FinishedTime int64  //finished timestamp
+	Decimals     OrderDecimals
+}
+
+//交易所返回的价格/数量的十进制值，适配器直接从响应的字符串字段解析，不经过float64；没有解析的适配器为零值
+type OrderDecimals struct {
+	Price      Decimal
+	Amount     Decimal
+	AvgPrice   Decimal
+	DealAmount Decimal
+}
+
+//批量下单/撤单中单个订单的结果，Err为nil表示成功
+type BatchOrderResult struct {
+	OrderId string
+	Cid     string
+	Err     error
 }
 
 type Trade struct {
@@ -178,12 +194,32 @@ type FutureOrder struct {
 	Profit       float64
 	ContractName string
 	FinishedTime int64 // finished timestamp
+	Decimals     OrderDecimals
 
 	//策略委托单
 	TriggerPrice float64
 	AlgoType     int //1:限价 2:市场价；触发价格类型，默认是限价；为市场价时，委托价格不必填；
 }
 
+//条件单，触发前不冻结保证金，触发后按Type下市价或限价单
+type TriggerOrder struct {
+	OrderId       string
+	ClientOid     string
+	Currency      CurrencyPair
+	ContractType  string
+	Type          TriggerOrderType
+	OType         int     //1：开多 2：开空 3：平多 4： 平空
+	Amount        float64 //ClosePosition为true时忽略
+	TriggerPrice  float64 //跟踪止损为激活价格，0表示立即激活
+	Price         float64 //限价条件单的委托价格
+	CallbackRate  float64 //跟踪止损的回调比例，0.01表示1%
+	ReduceOnly    bool    //平仓单(CLOSE_BUY/CLOSE_SELL)总是只减仓
+	ClosePosition bool    //触发后平掉该方向的全部仓位
+	Status        TradeStatus
+	OrderTime     int64
+	TriggeredId   string //触发后生成的普通订单ID，交易所提供时才有
+}
+
 type FuturePosition struct {
 	BuyAmount      float64
 	BuyAvailable   float64
@@ -213,6 +249,34 @@ type HistoricalFunding struct {
 	FundingTime  time.Time `json:"funding_time"`
 }
 
+//永续合约资金费率，时间单位ms
+type FundingRate struct {
+	Pair            CurrencyPair
+	ContractType    string
+	Rate            float64 //当前周期资金费率
+	NextRate        float64 //预测的下一周期资金费率，交易所不提供时为0
+	FundingTime     int64   //当前周期的收取时间
+	NextFundingTime int64
+}
+
+//标记价格，时间单位ms
+type MarkPrice struct {
+	Pair         CurrencyPair
+	ContractType string
+	MarkPrice    float64
+	IndexPrice   float64 //交易所不提供时为0
+	Timestamp    int64
+}
+
+//持仓量，时间单位ms
+type OpenInterest struct {
+	Pair         CurrencyPair
+	ContractType string
+	Amount       float64 //持仓量(张)
+	Value        float64 //持仓量折合的币数量，交易所不提供时为0
+	Timestamp    int64
+}
+
 type TickSize struct {
 	InstrumentID    string
 	UnderlyingIndex string
@@ -0,0 +1,245 @@
+package goex
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrwill84/goex/internal/logger"
+)
+
+//请求限频器，所有经过同一个http client的请求共享限额
+type RateLimiter interface {
+	//阻塞直到请求可以发出，ctx取消时返回ctx.Err()
+	Wait(ctx context.Context, req *http.Request) error
+	//根据响应(已用权重/429/418)调整限额
+	Update(req *http.Request, resp *http.Response)
+}
+
+//一组接口的限频规则，匹配的规则共享一个令牌桶
+type RateLimitRule struct {
+	Host             string //为空时匹配所有host
+	Method           string //为空时匹配所有method
+	Path             string //精确匹配，为空时按PathPrefix匹配
+	PathPrefix       string
+	Limit            int //Interval内允许的权重
+	Interval         time.Duration
+	Weight           func(req *http.Request) int //请求消耗的权重，nil时为1
+	UsedWeightHeader string                      //交易所返回的已用权重响应头，如binance X-MBX-USED-WEIGHT-1M
+}
+
+func (r *RateLimitRule) match(req *http.Request) bool {
+	if r.Host != "" && r.Host != req.URL.Host {
+		return false
+	}
+	if r.Method != "" && r.Method != req.Method {
+		return false
+	}
+	if r.Path != "" {
+		return req.URL.Path == r.Path
+	}
+	return strings.HasPrefix(req.URL.Path, r.PathPrefix)
+}
+
+func (r *RateLimitRule) weight(req *http.Request) int {
+	if r.Weight == nil {
+		return 1
+	}
+	return r.Weight(req)
+}
+
+type tokenBucket struct {
+	lock     sync.Mutex
+	capacity float64
+	tokens   float64
+	rate     float64 //每秒恢复的令牌数
+	last     time.Time
+}
+
+func newTokenBucket(limit int, interval time.Duration) *tokenBucket {
+	return &tokenBucket{
+		capacity: float64(limit),
+		tokens:   float64(limit),
+		rate:     float64(limit) / interval.Seconds(),
+		last:     time.Now(),
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+}
+
+//预扣n个令牌，返回需要等待的时间
+func (b *tokenBucket) reserve(n float64) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if n > b.capacity {
+		n = b.capacity
+	}
+
+	b.refill(time.Now())
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) cancel(n float64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if n > b.capacity {
+		n = b.capacity
+	}
+	b.tokens += n
+}
+
+//以交易所返回的已用权重为准
+func (b *tokenBucket) setUsed(used float64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.refill(time.Now())
+	if remain := b.capacity - used; remain < b.tokens {
+		b.tokens = remain
+	}
+}
+
+type rateLimitGroup struct {
+	rule   RateLimitRule
+	bucket *tokenBucket
+}
+
+//按接口分组的令牌桶限频器，收到429/418时暂停该host的所有请求(Retry-After或指数退避)
+type EndpointRateLimiter struct {
+	groups []*rateLimitGroup
+
+	lock         sync.Mutex
+	blockedUntil map[string]time.Time
+	backoffs     map[string]int
+}
+
+func NewRateLimiter(rules ...RateLimitRule) *EndpointRateLimiter {
+	limiter := &EndpointRateLimiter{
+		blockedUntil: make(map[string]time.Time),
+		backoffs:     make(map[string]int),
+	}
+	for _, r := range rules {
+		limiter.groups = append(limiter.groups, &rateLimitGroup{rule: r, bucket: newTokenBucket(r.Limit, r.Interval)})
+	}
+	return limiter
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *EndpointRateLimiter) Wait(ctx context.Context, req *http.Request) error {
+	l.lock.Lock()
+	until := l.blockedUntil[req.URL.Host]
+	l.lock.Unlock()
+
+	if err := sleepWithContext(ctx, time.Until(until)); err != nil {
+		return err
+	}
+
+	var reserved []*rateLimitGroup
+	for _, g := range l.groups {
+		if !g.rule.match(req) {
+			continue
+		}
+		n := float64(g.rule.weight(req))
+		reserved = append(reserved, g)
+		if err := sleepWithContext(ctx, g.bucket.reserve(n)); err != nil {
+			//请求不会发出，退还所有已预扣的令牌
+			for _, r := range reserved {
+				r.bucket.cancel(float64(r.rule.weight(req)))
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *EndpointRateLimiter) Update(req *http.Request, resp *http.Response) {
+	for _, g := range l.groups {
+		if g.rule.UsedWeightHeader == "" || !g.rule.match(req) {
+			continue
+		}
+		if used, err := strconv.ParseFloat(resp.Header.Get(g.rule.UsedWeightHeader), 64); err == nil {
+			g.bucket.setUsed(used)
+		}
+	}
+
+	host := req.URL.Host
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusTeapot {
+		delete(l.backoffs, host)
+		return
+	}
+
+	//未返回Retry-After时按1s,2s,4s...60s退避
+	backoff := time.Second << uint(l.backoffs[host])
+	if backoff > time.Minute {
+		backoff = time.Minute
+	} else {
+		l.backoffs[host]++
+	}
+	if sec, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && sec > 0 {
+		backoff = time.Duration(sec) * time.Second
+	}
+
+	l.blockedUntil[host] = time.Now().Add(backoff)
+	logger.Warnf("[rate limiter] %s response status %d , pause requests for %s", host, resp.StatusCode, backoff)
+}
+
+//限频的http.RoundTripper，用于http.Client.Transport
+type RateLimitTransport struct {
+	Base    http.RoundTripper //为nil时使用http.DefaultTransport
+	Limiter RateLimiter
+}
+
+func NewRateLimitTransport(base http.RoundTripper, limiter RateLimiter) *RateLimitTransport {
+	return &RateLimitTransport{Base: base, Limiter: limiter}
+}
+
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.Limiter.Wait(req.Context(), req); err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Limiter.Update(req, resp)
+
+	return resp, nil
+}
@@ -0,0 +1,98 @@
+package goex
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEndpointRateLimiter_Wait(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitRule{PathPrefix: "/api/", Limit: 10, Interval: time.Second, Weight: func(req *http.Request) int {
+		return 5
+	}})
+	req, _ := http.NewRequest(http.MethodGet, "https://api.binance.com/api/v3/depth", nil)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Fatalf("the third request should wait about 500ms , elapsed %s", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	limiter.Wait(context.Background(), req)
+	if err := limiter.Wait(ctx, req); err != context.DeadlineExceeded {
+		t.Fatal(err)
+	}
+
+	other, _ := http.NewRequest(http.MethodGet, "https://api.binance.com/sapi/v1/asset", nil)
+	if err := limiter.Wait(ctx, other); err != nil {
+		t.Fatal("unmatched request should not be limited", err)
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-MBX-USED-WEIGHT-1M", "100")
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	limiter := NewRateLimiter(RateLimitRule{PathPrefix: "/api/", Limit: 100, Interval: time.Minute, UsedWeightHeader: "X-MBX-USED-WEIGHT-1M"})
+	client := &http.Client{Transport: NewRateLimitTransport(nil, limiter)}
+
+	if _, err := NewHttpRequest(client, http.MethodGet, server.URL+"/api/v3/time", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if d := limiter.groups[0].bucket.reserve(1); d < time.Second/2 {
+		t.Fatal("used weight header should exhaust the bucket", d)
+	}
+
+	status = http.StatusTooManyRequests
+	NewHttpRequest(client, http.MethodGet, server.URL+"/other", "", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := NewHttpRequest(HttpClientWithContext(ctx, client), http.MethodGet, server.URL+"/other", "", nil); err == nil {
+		t.Fatal("requests should be paused after 429")
+	}
+}
+
+//后面的规则等待超时时，前面规则已预扣的令牌要退还
+func TestEndpointRateLimiter_WaitRefund(t *testing.T) {
+	limiter := NewRateLimiter(
+		RateLimitRule{PathPrefix: "/api/", Limit: 10, Interval: time.Minute},
+		RateLimitRule{Path: "/api/v3/order", Limit: 1, Interval: time.Minute},
+	)
+	order, _ := http.NewRequest(http.MethodPost, "https://api.binance.com/api/v3/order", nil)
+
+	if err := limiter.Wait(context.Background(), order); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		if err := limiter.Wait(ctx, order); err != context.DeadlineExceeded {
+			t.Fatal(err)
+		}
+		cancel()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	other, _ := http.NewRequest(http.MethodGet, "https://api.binance.com/api/v3/time", nil)
+	for i := 0; i < 9; i++ {
+		if err := limiter.Wait(ctx, other); err != nil {
+			t.Fatal("tokens of cancelled requests should be refunded", i, err)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package goex
+
+import (
+	"context"
+	"errors"
+)
+
+//按policy重试API的查询类方法；限价下单只有带ClientOrderId时才重试，重试前先按cid查询订单，
+//交易所返回cid重复时也按cid查询，不会重复下单(被包装的api需要实现ClientOrderIdAPI)；市价单和撤单不重试
+//WithContext绑定的ctx同时控制重试等待；其它可选接口需要UnwrapAPI后断言
+func NewRetryAPI(api API, policy *RetryPolicy) API {
+	return &retryAPI{API: api, policy: policy, ctx: context.Background()}
+}
+
+//同NewRetryAPI，作用于FutureRestAPI，被包装的api需要实现FutureClientOrderIdAPI；PlaceFutureOrder/MarketFuturesOrder/FutureCancelOrder不重试
+func NewRetryFutureRestAPI(api FutureRestAPI, policy *RetryPolicy) FutureRestAPI {
+	return &retryFutureRestAPI{FutureRestAPI: api, policy: policy, ctx: context.Background()}
+}
+
+var (
+	_ ContextBinder       = (*retryAPI)(nil)
+	_ FutureContextBinder = (*retryFutureRestAPI)(nil)
+)
+
+type retryAPI struct {
+	API
+	policy *RetryPolicy
+	ctx    context.Context
+}
+
+func (r *retryAPI) do(call func() error) error {
+	return r.policy.Do(r.ctx, call)
+}
+
+func (r *retryAPI) Unwrap() API {
+	return r.API
+}
+
+//被包装的api实现了ContextBinder时一并绑定
+func (r *retryAPI) WithContext(ctx context.Context) API {
+	api := r.API
+	if binder, ok := api.(ContextBinder); ok {
+		api = binder.WithContext(ctx)
+	}
+	return &retryAPI{API: api, policy: r.policy, ctx: ctx}
+}
+
+//带cid的限价单，重试前和cid重复时按cid查询订单，查到则直接返回
+func (r *retryAPI) limitOrder(cid string, currency CurrencyPair, place func() (*Order, error)) (ord *Order, err error) {
+	cidApi, _ := UnwrapAPI(r.API).(ClientOrderIdAPI)
+	if cidApi == nil {
+		err = r.do(func() error {
+			ord, err = place()
+			return err
+		})
+		return
+	}
+
+	placed := false //之前的请求结果未知，可能已经下单
+	err = r.do(func() error {
+		if placed {
+			ord, err = cidApi.GetOrderByClientId(cid, currency)
+			if err == nil || !errors.Is(err, EX_ERR_NOT_FIND_ORDER) {
+				return err
+			}
+		}
+
+		placed = true
+		ord, err = place()
+		if errors.Is(err, EX_ERR_DUPLICATE_CLIENT_OID) {
+			ord, err = cidApi.GetOrderByClientId(cid, currency)
+		}
+		return err
+	})
+	return
+}
+
+func (r *retryAPI) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	cid := GetClientOrderId(opt...)
+	if cid == "" {
+		return r.API.LimitBuy(amount, price, currency, opt...)
+	}
+	return r.limitOrder(cid, currency, func() (*Order, error) {
+		return r.API.LimitBuy(amount, price, currency, opt...)
+	})
+}
+
+func (r *retryAPI) LimitSell(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	cid := GetClientOrderId(opt...)
+	if cid == "" {
+		return r.API.LimitSell(amount, price, currency, opt...)
+	}
+	return r.limitOrder(cid, currency, func() (*Order, error) {
+		return r.API.LimitSell(amount, price, currency, opt...)
+	})
+}
+
+func (r *retryAPI) GetOneOrder(orderId string, currency CurrencyPair) (ord *Order, err error) {
+	err = r.do(func() error {
+		ord, err = r.API.GetOneOrder(orderId, currency)
+		return err
+	})
+	return
+}
+
+func (r *retryAPI) GetUnfinishOrders(currency CurrencyPair) (orders []Order, err error) {
+	err = r.do(func() error {
+		orders, err = r.API.GetUnfinishOrders(currency)
+		return err
+	})
+	return
+}
+
+func (r *retryAPI) GetOrderHistorys(currency CurrencyPair, opt ...OptionalParameter) (orders []Order, err error) {
+	err = r.do(func() error {
+		orders, err = r.API.GetOrderHistorys(currency, opt...)
+		return err
+	})
+	return
+}
+
+func (r *retryAPI) GetAccount() (acc *Account, err error) {
+	err = r.do(func() error {
+		acc, err = r.API.GetAccount()
+		return err
+	})
+	return
+}
+
+func (r *retryAPI) GetTicker(currency CurrencyPair) (ticker *Ticker, err error) {
+	err = r.do(func() error {
+		ticker, err = r.API.GetTicker(currency)
+		return err
+	})
+	return
+}
+
+func (r *retryAPI) GetDepth(size int, currency CurrencyPair) (depth *Depth, err error) {
+	err = r.do(func() error {
+		depth, err = r.API.GetDepth(size, currency)
+		return err
+	})
+	return
+}
+
+func (r *retryAPI) GetKlineRecords(currency CurrencyPair, period KlinePeriod, size int, optional ...OptionalParameter) (klines []Kline, err error) {
+	err = r.do(func() error {
+		klines, err = r.API.GetKlineRecords(currency, period, size, optional...)
+		return err
+	})
+	return
+}
+
+func (r *retryAPI) GetTrades(currencyPair CurrencyPair, since int64) (trades []Trade, err error) {
+	err = r.do(func() error {
+		trades, err = r.API.GetTrades(currencyPair, since)
+		return err
+	})
+	return
+}
+
+type retryFutureRestAPI struct {
+	FutureRestAPI
+	policy *RetryPolicy
+	ctx    context.Context
+}
+
+func (r *retryFutureRestAPI) do(call func() error) error {
+	return r.policy.Do(r.ctx, call)
+}
+
+func (r *retryFutureRestAPI) Unwrap() FutureRestAPI {
+	return r.FutureRestAPI
+}
+
+func (r *retryFutureRestAPI) WithContext(ctx context.Context) FutureRestAPI {
+	api := r.FutureRestAPI
+	if binder, ok := api.(FutureContextBinder); ok {
+		api = binder.WithContext(ctx)
+	}
+	return &retryFutureRestAPI{FutureRestAPI: api, policy: r.policy, ctx: ctx}
+}
+
+func (r *retryFutureRestAPI) LimitFuturesOrder(currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (ord *FutureOrder, err error) {
+	cid := GetClientOrderId(opt...)
+	if cid == "" {
+		return r.FutureRestAPI.LimitFuturesOrder(currencyPair, contractType, price, amount, openType, opt...)
+	}
+
+	cidApi, _ := UnwrapFutureRestAPI(r.FutureRestAPI).(FutureClientOrderIdAPI)
+	placed := false
+	err = r.do(func() error {
+		if placed && cidApi != nil {
+			ord, err = cidApi.GetFutureOrderByClientId(cid, currencyPair, contractType)
+			if err == nil || !errors.Is(err, EX_ERR_NOT_FIND_ORDER) {
+				return err
+			}
+		}
+
+		placed = true
+		ord, err = r.FutureRestAPI.LimitFuturesOrder(currencyPair, contractType, price, amount, openType, opt...)
+		if cidApi != nil && errors.Is(err, EX_ERR_DUPLICATE_CLIENT_OID) {
+			ord, err = cidApi.GetFutureOrderByClientId(cid, currencyPair, contractType)
+		}
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetFutureEstimatedPrice(currencyPair CurrencyPair) (price float64, err error) {
+	err = r.do(func() error {
+		price, err = r.FutureRestAPI.GetFutureEstimatedPrice(currencyPair)
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetFutureTicker(currencyPair CurrencyPair, contractType string) (ticker *Ticker, err error) {
+	err = r.do(func() error {
+		ticker, err = r.FutureRestAPI.GetFutureTicker(currencyPair, contractType)
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetFutureDepth(currencyPair CurrencyPair, contractType string, size int) (depth *Depth, err error) {
+	err = r.do(func() error {
+		depth, err = r.FutureRestAPI.GetFutureDepth(currencyPair, contractType, size)
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetFutureIndex(currencyPair CurrencyPair) (index float64, err error) {
+	err = r.do(func() error {
+		index, err = r.FutureRestAPI.GetFutureIndex(currencyPair)
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetFutureUserinfo(currencyPair ...CurrencyPair) (acc *FutureAccount, err error) {
+	err = r.do(func() error {
+		acc, err = r.FutureRestAPI.GetFutureUserinfo(currencyPair...)
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetFuturePosition(currencyPair CurrencyPair, contractType string) (positions []FuturePosition, err error) {
+	err = r.do(func() error {
+		positions, err = r.FutureRestAPI.GetFuturePosition(currencyPair, contractType)
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetFutureOrders(orderIds []string, currencyPair CurrencyPair, contractType string) (orders []FutureOrder, err error) {
+	err = r.do(func() error {
+		orders, err = r.FutureRestAPI.GetFutureOrders(orderIds, currencyPair, contractType)
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetFutureOrder(orderId string, currencyPair CurrencyPair, contractType string) (ord *FutureOrder, err error) {
+	err = r.do(func() error {
+		ord, err = r.FutureRestAPI.GetFutureOrder(orderId, currencyPair, contractType)
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetUnfinishFutureOrders(currencyPair CurrencyPair, contractType string) (orders []FutureOrder, err error) {
+	err = r.do(func() error {
+		orders, err = r.FutureRestAPI.GetUnfinishFutureOrders(currencyPair, contractType)
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetFutureOrderHistory(pair CurrencyPair, contractType string, optional ...OptionalParameter) (orders []FutureOrder, err error) {
+	err = r.do(func() error {
+		orders, err = r.FutureRestAPI.GetFutureOrderHistory(pair, contractType, optional...)
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetFee() (fee float64, err error) {
+	err = r.do(func() error {
+		fee, err = r.FutureRestAPI.GetFee()
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetContractValue(currencyPair CurrencyPair) (val float64, err error) {
+	err = r.do(func() error {
+		val, err = r.FutureRestAPI.GetContractValue(currencyPair)
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetKlineRecords(contractType string, currency CurrencyPair, period KlinePeriod, size int, optional ...OptionalParameter) (klines []FutureKline, err error) {
+	err = r.do(func() error {
+		klines, err = r.FutureRestAPI.GetKlineRecords(contractType, currency, period, size, optional...)
+		return err
+	})
+	return
+}
+
+func (r *retryFutureRestAPI) GetTrades(contractType string, currencyPair CurrencyPair, since int64) (trades []Trade, err error) {
+	err = r.do(func() error {
+		trades, err = r.FutureRestAPI.GetTrades(contractType, currencyPair, since)
+		return err
+	})
+	return
+}
@@ -0,0 +1,111 @@
+package goex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/mrwill84/goex/internal/logger"
+	"github.com/valyala/fasthttp"
+)
+
+//重试策略: 指数退避+随机抖动，只重试可以确认是暂时性的错误
+type RetryPolicy struct {
+	MaxRetries      int                  //最大重试次数，不含第一次调用
+	InitialInterval time.Duration        //第一次重试前的等待时间
+	MaxInterval     time.Duration        //单次等待的上限
+	Multiplier      float64              //每次重试等待时间的倍数
+	Jitter          float64              //随机抖动比例[0,1] , 0.2表示等待时间在±20%内随机
+	MaxElapsedTime  time.Duration        //从第一次调用开始的总时长上限，0表示不限制
+	Retryable       func(err error) bool //判断错误是否可以重试，nil时使用IsTransientError
+}
+
+//默认最多重试3次，等待200ms,400ms,800ms(±20%)，总时长不超过30s
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries:      3,
+		InitialInterval: 200 * time.Millisecond,
+		MaxInterval:     5 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+		MaxElapsedTime:  30 * time.Second,
+	}
+}
+
+//暂时性错误: 限频、交易所维护、时间戳超出窗口、5xx、网络错误
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	for _, e := range []ApiError{EX_ERR_API_LIMIT, EX_ERR_MAINTENANCE, EX_ERR_INVALID_TIMESTAMP, HTTP_ERR_CODE} {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+
+	var httpErr *HttpError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError || httpErr.StatusCode == http.StatusRequestTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, fasthttp.ErrConnectionClosed)
+}
+
+func (p *RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsTransientError(err)
+}
+
+//第attempt次(从1开始)重试前的等待时间
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	interval := float64(p.InitialInterval)
+	for i := 1; i < attempt && p.Multiplier > 1; i++ {
+		interval *= p.Multiplier
+		if p.MaxInterval > 0 && interval >= float64(p.MaxInterval) {
+			interval = float64(p.MaxInterval)
+			break
+		}
+	}
+	if p.Jitter > 0 {
+		interval += interval * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(interval)
+}
+
+//调用call直到成功、错误不可重试、超过重试次数或总时长，返回最后一次的错误
+//交易所返回了Retry-After时至少等待该时间
+func (p *RetryPolicy) Do(ctx context.Context, call func() error) error {
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil || attempt > p.MaxRetries || !p.retryable(err) {
+			return err
+		}
+
+		wait := p.Backoff(attempt)
+		if retryAfter, ok := GetRetryAfter(err); ok && retryAfter > wait {
+			wait = retryAfter
+		}
+		if p.MaxElapsedTime > 0 && time.Since(start)+wait > p.MaxElapsedTime {
+			return err
+		}
+
+		logger.Warnf("[retry] %s , retry %d/%d after %s", err.Error(), attempt, p.MaxRetries, wait)
+		if e := sleepWithContext(ctx, wait); e != nil {
+			return err
+		}
+	}
+}
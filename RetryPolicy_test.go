@@ -0,0 +1,141 @@
+package goex
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockRetryAPI struct {
+	API
+	calls int
+	err   error
+}
+
+func (m *mockRetryAPI) GetTicker(currency CurrencyPair) (*Ticker, error) {
+	m.calls++
+	if m.calls < 3 {
+		return nil, m.err
+	}
+	return &Ticker{Pair: currency}, nil
+}
+
+func (m *mockRetryAPI) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	m.calls++
+	return nil, m.err
+}
+
+func TestIsTransientError(t *testing.T) {
+	for _, err := range []error{EX_ERR_API_LIMIT.Wrap("-1003", "too many requests"), newHttpError(502, nil, ""), newHttpError(429, nil, "1")} {
+		if !IsTransientError(err) {
+			t.Fatal("should be transient", err)
+		}
+	}
+	for _, err := range []error{EX_ERR_INSUFFICIENT_BALANCE, newHttpError(400, nil, ""), errors.New("unknown")} {
+		if IsTransientError(err) {
+			t.Fatal("should not be transient", err)
+		}
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := &RetryPolicy{InitialInterval: 100 * time.Millisecond, MaxInterval: time.Second, Multiplier: 2}
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second} {
+		if d := p.Backoff(attempt); d != want {
+			t.Fatal(attempt, d)
+		}
+	}
+}
+
+func TestRetryAPI(t *testing.T) {
+	policy := &RetryPolicy{MaxRetries: 3, InitialInterval: time.Millisecond, Multiplier: 2}
+
+	mock := &mockRetryAPI{err: HTTP_ERR_CODE}
+	if _, err := NewRetryAPI(mock, policy).GetTicker(BTC_USDT); err != nil || mock.calls != 3 {
+		t.Fatal(err, mock.calls)
+	}
+
+	mock = &mockRetryAPI{err: EX_ERR_SIGN}
+	if _, err := NewRetryAPI(mock, policy).GetTicker(BTC_USDT); !errors.Is(err, EX_ERR_SIGN) || mock.calls != 1 {
+		t.Fatal(err, mock.calls)
+	}
+
+	//没有ClientOrderId的下单不重试
+	mock = &mockRetryAPI{err: HTTP_ERR_CODE}
+	NewRetryAPI(mock, policy).LimitBuy("1", "1", BTC_USDT, PostOnly)
+	if mock.calls != 1 {
+		t.Fatal(mock.calls)
+	}
+
+	mock = &mockRetryAPI{err: HTTP_ERR_CODE}
+	NewRetryAPI(mock, policy).LimitBuy("1", "1", BTC_USDT, PostOnly, ClientOrderId("goex1"))
+	if mock.calls != 4 {
+		t.Fatal(mock.calls)
+	}
+}
+
+//第一次下单超时但交易所已经下单
+type mockCidRetryAPI struct {
+	mockRetryAPI
+	placed  *Order
+	lookups int
+}
+
+func (m *mockCidRetryAPI) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	m.calls++
+	if m.placed != nil {
+		return nil, EX_ERR_DUPLICATE_CLIENT_OID
+	}
+	m.placed = &Order{Cid: GetClientOrderId(opt...), OrderID2: "1", Currency: currency}
+	return nil, m.err
+}
+
+func (m *mockCidRetryAPI) GetOrderByClientId(cid string, currency CurrencyPair) (*Order, error) {
+	m.lookups++
+	if m.placed == nil || m.placed.Cid != cid {
+		return nil, EX_ERR_NOT_FIND_ORDER
+	}
+	return m.placed, nil
+}
+
+func (m *mockCidRetryAPI) CancelOrderByClientId(cid string, currency CurrencyPair) (bool, error) {
+	return false, EX_ERR_NOT_SUPPORTED
+}
+
+func TestRetryAPI_ResolveByClientId(t *testing.T) {
+	policy := &RetryPolicy{MaxRetries: 3, InitialInterval: time.Millisecond, Multiplier: 2}
+
+	mock := &mockCidRetryAPI{mockRetryAPI: mockRetryAPI{err: HTTP_ERR_CODE}}
+	ord, err := NewRetryAPI(mock, policy).LimitBuy("1", "1", BTC_USDT, ClientOrderId("goex1"))
+	if err != nil || ord.OrderID2 != "1" || mock.calls != 1 || mock.lookups != 1 {
+		t.Fatal(err, ord, mock.calls, mock.lookups)
+	}
+
+	//交易所返回cid重复时按cid查询
+	ord, err = NewRetryAPI(mock, policy).LimitBuy("1", "1", BTC_USDT, ClientOrderId("goex1"))
+	if err != nil || ord.OrderID2 != "1" || mock.calls != 2 || mock.lookups != 2 {
+		t.Fatal(err, ord, mock.calls, mock.lookups)
+	}
+
+	//经过装饰器包装也能找到ClientOrderIdAPI
+	mock = &mockCidRetryAPI{mockRetryAPI: mockRetryAPI{err: HTTP_ERR_CODE}}
+	api := NewRetryAPI(NewMarketRoundingAPI(mock, nil), policy)
+	if UnwrapAPI(api) != mock {
+		t.Fatal("unwrap should return the innermost api")
+	}
+}
+
+func TestRetryAPI_WithContext(t *testing.T) {
+	policy := &RetryPolicy{MaxRetries: 10, InitialInterval: time.Second, Multiplier: 1}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	mock := &mockRetryAPI{err: HTTP_ERR_CODE}
+	start := time.Now()
+	NewRetryAPI(mock, policy).(ContextBinder).WithContext(ctx).GetTicker(BTC_USDT)
+	if time.Since(start) > 500*time.Millisecond || mock.calls != 1 {
+		t.Fatal("retry wait should stop when ctx done", time.Since(start), mock.calls)
+	}
+}
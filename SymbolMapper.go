@@ -0,0 +1,87 @@
+package goex
+
+import (
+	"time"
+
+	"github.com/mrwill84/goex/internal/logger"
+)
+
+//CurrencyPair+合约类型与交易所原生symbol(instrument id)的双向映射，数据来自交易所的交易规则接口(MarketInfo)，
+//交割合约的symbol包含交割日期，缓存过期后重新拉取即可跟随合约滚动；ws适配器可用ToCurrencyPair把推送消息映射回交易对
+type SymbolMapper struct {
+	registry *MarketRegistry
+}
+
+//expire<=0时只在第一次查询时拉取，有交割合约时应设置为小于一周
+func NewSymbolMapper(info MarketInfo, expire time.Duration) *SymbolMapper {
+	return NewSymbolMapperWithRegistry(NewMarketRegistry(info, expire))
+}
+
+//和MarketRoundingAPI共用同一份交易规则缓存
+func NewSymbolMapperWithRegistry(registry *MarketRegistry) *SymbolMapper {
+	return &SymbolMapper{registry: registry}
+}
+
+//现货contractType传空，永续合约SWAP_CONTRACT和SWAP_USDT_CONTRACT等价(以计价币区分币本位和U本位)
+func (m *SymbolMapper) ToSymbol(pair CurrencyPair, contractType string) (string, error) {
+	market, err := m.registry.GetMarket(pair, contractType)
+	if err != nil {
+		return "", err
+	}
+	return market.Symbol, nil
+}
+
+//symbol不区分大小写，也可以是交易所的别名；U本位永续合约返回SWAP_USDT_CONTRACT。
+//合并了多个接口(JoinMarketInfo)时symbol可能同名，用contractTypes限定候选类型，如现货传""，U本位永续传SWAP_USDT_CONTRACT
+func (m *SymbolMapper) ToCurrencyPair(symbol string, contractTypes ...string) (CurrencyPair, string, error) {
+	market, err := m.registry.GetMarketBySymbol(symbol, contractTypes...)
+	if err != nil {
+		return UNKNOWN_PAIR, "", err
+	}
+
+	contractType := market.ContractType
+	if contractType == SWAP_CONTRACT && market.Pair.CurrencyB.Symbol == USDT.Symbol {
+		contractType = SWAP_USDT_CONTRACT
+	}
+	return market.Pair, contractType, nil
+}
+
+//ws适配器把推送中的symbol映射回交易对，m为nil或映射失败时使用适配器自己的解析fallback
+func (m *SymbolMapper) PairOf(symbol string, fallback func(symbol string) CurrencyPair, contractTypes ...string) CurrencyPair {
+	if m != nil {
+		pair, _, err := m.ToCurrencyPair(symbol, contractTypes...)
+		if err == nil {
+			return pair
+		}
+		logger.Warnf("[symbol mapper] map symbol %s error: %s", symbol, err.Error())
+	}
+	return fallback(symbol)
+}
+
+func (m *SymbolMapper) Registry() *MarketRegistry {
+	return m.registry
+}
+
+//ws适配器实现该接口，设置后推送消息中的symbol通过SymbolMapper映射回交易对，未设置时使用适配器自己的解析
+type SymbolMapperAware interface {
+	SetSymbolMapper(mapper *SymbolMapper)
+}
+
+type joinedMarketInfo []MarketInfo
+
+//合并多个MarketInfo的交易规则，如同一交易所的现货/U本位/币本位接口，任一个拉取失败则返回错误
+func JoinMarketInfo(infos ...MarketInfo) MarketInfo {
+	return joinedMarketInfo(infos)
+}
+
+func (infos joinedMarketInfo) GetMarkets() ([]Market, error) {
+	var markets []Market
+	for _, info := range infos {
+		m, err := info.GetMarkets()
+		if err != nil {
+			return nil, err
+		}
+		markets = append(markets, m...)
+	}
+	return markets, nil
+}
@@ -0,0 +1,112 @@
+package goex
+
+import (
+	"testing"
+)
+
+type mockSymbolMarketInfo struct {
+	quarter string
+}
+
+func (m *mockSymbolMarketInfo) GetMarkets() ([]Market, error) {
+	return []Market{
+		{Pair: BTC_USDT, Symbol: "BTCUSDT"},
+		{Pair: BTC_USDT, ContractType: SWAP_CONTRACT, Symbol: "BTC-USDT-SWAP"},
+		{Pair: BTC_USD, ContractType: SWAP_CONTRACT, Symbol: "BTC-USD-SWAP"},
+		{Pair: BTC_USD, ContractType: QUARTER_CONTRACT, Symbol: m.quarter, Aliases: []string{"BTC_CQ"}},
+		{Pair: BTC_USD, Symbol: "XXBTZUSD", Aliases: []string{"XBTUSD", "XBT/USD"}},
+	}, nil
+}
+
+func TestSymbolMapper(t *testing.T) {
+	info := &mockSymbolMarketInfo{quarter: "BTC-USD-211231"}
+	mapper := NewSymbolMapper(info, 0)
+
+	symbol, err := mapper.ToSymbol(BTC_USDT, SWAP_USDT_CONTRACT)
+	if err != nil || symbol != "BTC-USDT-SWAP" {
+		t.Fatal(symbol, err)
+	}
+
+	if symbol, _ = mapper.ToSymbol(BTC_USD, QUARTER_CONTRACT); symbol != "BTC-USD-211231" {
+		t.Fatal(symbol)
+	}
+
+	if _, err = mapper.ToSymbol(ETH_USDT, ""); err == nil {
+		t.Fatal("symbol should be not found")
+	}
+
+	tests := []struct {
+		symbol       string
+		pair         CurrencyPair
+		contractType string
+	}{
+		{"btcusdt", BTC_USDT, ""},
+		{"BTC-USDT-SWAP", BTC_USDT, SWAP_USDT_CONTRACT},
+		{"BTC-USD-SWAP", BTC_USD, SWAP_CONTRACT},
+		{"BTC-USD-211231", BTC_USD, QUARTER_CONTRACT},
+		{"BTC_CQ", BTC_USD, QUARTER_CONTRACT},
+		{"XBT/USD", BTC_USD, ""},
+	}
+	for _, tt := range tests {
+		pair, contractType, err := mapper.ToCurrencyPair(tt.symbol)
+		if err != nil || pair != tt.pair || contractType != tt.contractType {
+			t.Fatal(tt.symbol, pair, contractType, err)
+		}
+	}
+
+	//交割后重新拉取，季度合约映射到新的symbol
+	info.quarter = "BTC-USD-220325"
+	if err = mapper.Registry().Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if symbol, _ = mapper.ToSymbol(BTC_USD, QUARTER_CONTRACT); symbol != "BTC-USD-220325" {
+		t.Fatal(symbol)
+	}
+	if _, _, err = mapper.ToCurrencyPair("BTC-USD-211231"); err == nil {
+		t.Fatal("expired symbol should be not found")
+	}
+
+	joined := NewSymbolMapper(JoinMarketInfo(&mockMarketInfo{}, info), 0)
+	if symbol, _ = joined.ToSymbol(ETH_USDT, ""); symbol != "ETHUSDT" {
+		t.Fatal(symbol)
+	}
+	if symbol, _ = joined.ToSymbol(BTC_USD, QUARTER_CONTRACT); symbol != "BTC-USD-220325" {
+		t.Fatal(symbol)
+	}
+}
+
+type marketsInfo []Market
+
+func (m marketsInfo) GetMarkets() ([]Market, error) {
+	return m, nil
+}
+
+//binance现货和U本位合约的symbol同名
+func TestSymbolMapper_JoinSameSymbol(t *testing.T) {
+	spot := marketsInfo{{Pair: BTC_USDT, Symbol: "BTCUSDT"}}
+	usdtSwap := marketsInfo{{Pair: BTC_USDT, ContractType: SWAP_CONTRACT, Symbol: "BTCUSDT"}}
+	coinSwap := marketsInfo{{Pair: BTC_USD, ContractType: SWAP_CONTRACT, Symbol: "BTCUSD_PERP"}}
+
+	for _, mapper := range []*SymbolMapper{
+		NewSymbolMapper(JoinMarketInfo(spot, usdtSwap, coinSwap), 0),
+		NewSymbolMapper(JoinMarketInfo(coinSwap, usdtSwap, spot), 0),
+	} {
+		if _, _, err := mapper.ToCurrencyPair("BTCUSDT"); err == nil {
+			t.Fatal("ambiguous symbol should return error")
+		}
+		if pair, contractType, err := mapper.ToCurrencyPair("btcusdt", ""); err != nil || pair != BTC_USDT || contractType != "" {
+			t.Fatal(pair, contractType, err)
+		}
+		if pair, contractType, err := mapper.ToCurrencyPair("BTCUSDT", SWAP_USDT_CONTRACT); err != nil || pair != BTC_USDT || contractType != SWAP_USDT_CONTRACT {
+			t.Fatal(pair, contractType, err)
+		}
+		if pair, contractType, err := mapper.ToCurrencyPair("BTCUSD_PERP"); err != nil || pair != BTC_USD || contractType != SWAP_CONTRACT {
+			t.Fatal(pair, contractType, err)
+		}
+	}
+
+	var nilMapper *SymbolMapper
+	if pair := nilMapper.PairOf("BTCUSDT", func(string) CurrencyPair { return ETH_USDT }); pair != ETH_USDT {
+		t.Fatal(pair)
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -107,6 +109,20 @@ func ToInt64(v interface{}) int64 {
 	}
 }
 
+//非字符串的值按fmt格式化，nil返回空字符串，不会panic
+func ToString(v interface{}) string {
+	switch vv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return vv
+	case float64:
+		return strconv.FormatFloat(vv, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
 func FloatToString(v float64, precision int) string {
 	return fmt.Sprint(FloatToFixed(v, precision))
 }
@@ -149,7 +165,24 @@ func FlateDecompress(data []byte) ([]byte, error) {
 	return ioutil.ReadAll(flate.NewReader(bytes.NewReader(data)))
 }
 
+//Deprecated: 生成的ID不一定符合交易所的格式要求，请使用NewClientOrderId
 func GenerateOrderClientId(size int) string {
 	uuidStr := strings.Replace(uuid.New().String(), "-", "", 32)
 	return "goex" + uuidStr[0:size-5]
 }
+
+//数字类型的客户端订单ID，从启动时的纳秒时间戳开始递增，保证进程内唯一且重启后不重复
+var numericClientOrderId = time.Now().UnixNano()
+
+//生成符合交易所格式要求的客户端订单ID，通过ClientOrderId(cid)传给下单接口:
+//hbdm为正整数 , kraken为uuid , 其它交易所为"goex"开头的32位字母数字(binance/okex/huobi/bitmex等均接受)
+func NewClientOrderId(exchange string) string {
+	switch exchange {
+	case HBDM, HBDM_SWAP:
+		return strconv.FormatInt(atomic.AddInt64(&numericClientOrderId, 1), 10)
+	case KRAKEN:
+		return uuid.New().String()
+	default:
+		return "goex" + strings.Replace(uuid.New().String(), "-", "", -1)[0:28]
+	}
+}
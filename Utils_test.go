@@ -16,3 +16,29 @@ func TestFloatToString(t *testing.T) {
 func TestGenerateOrderClientId(t *testing.T) {
 	t.Log(len(GenerateOrderClientId(32)), GenerateOrderClientId(32))
 }
+
+func TestNewClientOrderId(t *testing.T) {
+	cid := NewClientOrderId(BINANCE)
+	assert.Len(t, cid, 32)
+	assert.Regexp(t, "^goex[0-9a-f]{28}$", cid)
+	assert.NotEqual(t, cid, NewClientOrderId(BINANCE))
+
+	//hbdm只接受正整数
+	id1, id2 := ToInt64(NewClientOrderId(HBDM)), ToInt64(NewClientOrderId(HBDM_SWAP))
+	assert.True(t, id1 > 0 && id2 > id1)
+
+	tif, ok := GetTimeInForce(ClientOrderId(cid), Fok)
+	assert.True(t, ok)
+	assert.Equal(t, Fok, tif)
+	_, ok = GetTimeInForce(ClientOrderId(cid))
+	assert.False(t, ok)
+}
+
+func TestOpenTypeName(t *testing.T) {
+	for _, openType := range []int{OPEN_BUY, OPEN_SELL, CLOSE_BUY, CLOSE_SELL} {
+		assert.Equal(t, openType, ParseOpenType(OpenTypeName(openType)))
+	}
+	assert.Equal(t, "closeshort", OpenTypeName(CLOSE_SELL))
+	assert.Equal(t, "", OpenTypeName(0))
+	assert.Equal(t, 0, ParseOpenType("long"))
+}
@@ -4,32 +4,50 @@ type FuturesWsApi interface {
 	DepthCallback(func(depth *Depth))
 	TickerCallback(func(ticker *FutureTicker))
 	TradeCallback(func(trade *Trade, contract string))
-	//OrderCallback(func(order *FutureOrder))
-	//PositionCallback(func(position *FuturePosition))
-	//AccountCallback(func(account *FutureAccount))
 
 	SubscribeDepth(pair CurrencyPair, contractType string) error
 	SubscribeTicker(pair CurrencyPair, contractType string) error
 	SubscribeTrade(pair CurrencyPair, contractType string) error
 
-	//Login() error
-	//SubscribeOrder(pair CurrencyPair, contractType string) error
-	//SubscribePosition(pair CurrencyPair, contractType string) error
-	//SubscribeAccount(pair CurrencyPair) error
+	// 取消订阅，没有订阅过时返回nil
+	UnsubscribeDepth(pair CurrencyPair, contractType string) error
+	UnsubscribeTicker(pair CurrencyPair, contractType string) error
+	UnsubscribeTrade(pair CurrencyPair, contractType string) error
 }
 
 type SpotWsApi interface {
 	DepthCallback(func(depth *Depth))
 	TickerCallback(func(ticker *Ticker))
 	TradeCallback(func(trade *Trade))
-	//OrderCallback(func(order *Order))
-	//AccountCallback(func(account *Account))
 
 	SubscribeDepth(pair CurrencyPair) error
 	SubscribeTicker(pair CurrencyPair) error
 	SubscribeTrade(pair CurrencyPair) error
 
-	//Login() error
-	//SubscribeOrder(pair CurrencyPair) error
-	//SubscribeAccount(pair CurrencyPair) error
+	// 取消订阅，没有订阅过时返回nil
+	UnsubscribeDepth(pair CurrencyPair) error
+	UnsubscribeTicker(pair CurrencyPair) error
+	UnsubscribeTrade(pair CurrencyPair) error
+}
+
+// 合约私有数据推送(订单/持仓/账户)，需要api key , 订阅前需先Login
+type FuturesPrivateWsApi interface {
+	OrderCallback(func(order *FutureOrder))
+	PositionCallback(func(position *FuturePosition))
+	AccountCallback(func(account *FutureAccount))
+
+	Login() error
+	SubscribeOrder(pair CurrencyPair, contractType string) error
+	SubscribePosition(pair CurrencyPair, contractType string) error
+	SubscribeAccount(pair CurrencyPair) error
+}
+
+// 现货私有数据推送(订单/账户)，需要api key , 订阅前需先Login
+type SpotPrivateWsApi interface {
+	OrderCallback(func(order *Order))
+	AccountCallback(func(account *Account))
+
+	Login() error
+	SubscribeOrder(pair CurrencyPair) error
+	SubscribeAccount(pair CurrencyPair) error
 }
@@ -0,0 +1,162 @@
+package goex
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+//按交易所的单连接订阅数限制把订阅分散到多个WsConn，连接在需要时用同一个WsBuilder建立，
+//推送都进入builder的ProtoHandleFunc(或ConnProtoHandleFunc)，对适配器表现为一个连接；
+//订阅消息的限频由builder的SubscribeRateLimit按连接控制
+type WsPool struct {
+	builder *WsBuilder
+	maxSubs int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	lock   sync.Mutex
+	conns  []*WsConn
+	owner  map[string]*WsConn //订阅Key -> 所在连接
+	counts map[*WsConn]int
+}
+
+//maxSubsPerConn为每个连接最多的订阅数，<=0时只使用一个连接
+func NewWsPool(builder *WsBuilder, maxSubsPerConn int) *WsPool {
+	return NewWsPoolWithContext(context.Background(), builder, maxSubsPerConn)
+}
+
+//ctx取消后关闭所有连接
+func NewWsPoolWithContext(ctx context.Context, builder *WsBuilder, maxSubsPerConn int) *WsPool {
+	pool := &WsPool{
+		builder: builder,
+		maxSubs: maxSubsPerConn,
+		owner:   make(map[string]*WsConn),
+		counts:  make(map[*WsConn]int),
+	}
+	pool.ctx, pool.cancel = context.WithCancel(ctx)
+	return pool
+}
+
+//选择订阅数未满的连接，都已满时建立新连接；已关闭的连接不再使用
+func (p *WsPool) pick() (*WsConn, error) {
+	for _, c := range p.conns {
+		select {
+		case <-c.Done():
+			continue
+		default:
+		}
+		if p.maxSubs <= 0 || p.counts[c] < p.maxSubs {
+			return c, nil
+		}
+	}
+
+	if p.ctx.Err() != nil {
+		return nil, ErrWsClosed
+	}
+	c, err := p.builder.BuildWithContext(p.ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.conns = append(p.conns, c)
+	return c, nil
+}
+
+//以订阅消息本身作为Key，同WsConn.Subscribe
+func (p *WsPool) Subscribe(subEvent interface{}) error {
+	data, err := json.Marshal(subEvent)
+	if err != nil {
+		return err
+	}
+	return p.SubscribeWithId(string(data), "", subEvent)
+}
+
+//key相同的订阅只发送一次(被交易所拒绝的在原连接上重新发送)，建立新连接失败时返回错误
+func (p *WsPool) SubscribeWithId(key, id string, subEvent interface{}) error {
+	p.lock.Lock()
+	c, ok := p.owner[key]
+	if !ok {
+		var err error
+		c, err = p.pick()
+		if err != nil {
+			p.lock.Unlock()
+			return err
+		}
+		p.owner[key] = c
+		p.counts[c]++
+	}
+	p.lock.Unlock()
+
+	//限频时会阻塞，不持有锁
+	err := c.SubscribeWithId(key, id, subEvent)
+	if err != nil {
+		p.release(key)
+	}
+	return err
+}
+
+func (p *WsPool) release(key string) *WsConn {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	c, ok := p.owner[key]
+	if !ok {
+		return nil
+	}
+	delete(p.owner, key)
+	p.counts[c]--
+	return c
+}
+
+//从订阅所在的连接取消订阅，没有订阅过时返回nil；连接没有订阅后保留，供之后的订阅使用
+func (p *WsPool) Unsubscribe(key string, unsubEvent interface{}) error {
+	c := p.release(key)
+	if c == nil {
+		return nil
+	}
+	return c.Unsubscribe(key, unsubEvent)
+}
+
+//订阅所在的连接，没有订阅时返回nil；用于向该连接发送和订阅相关的消息(如重新订阅获取快照)
+func (p *WsPool) Conn(key string) *WsConn {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.owner[key]
+}
+
+func (p *WsPool) Conns() []*WsConn {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]*WsConn{}, p.conns...)
+}
+
+//所有连接的订阅，按连接和订阅顺序
+func (p *WsPool) Subscriptions() []WsSubscription {
+	var subs []WsSubscription
+	for _, c := range p.Conns() {
+		subs = append(subs, c.Subscriptions()...)
+	}
+	return subs
+}
+
+func (p *WsPool) AckSubscription(id string, err error) bool {
+	for _, c := range p.Conns() {
+		if c.AckSubscription(id, err) {
+			return true
+		}
+	}
+	return false
+}
+
+//关闭所有连接，返回第一个关闭错误，可以重复调用
+func (p *WsPool) Close() error {
+	p.cancel()
+
+	var err error
+	for _, c := range p.Conns() {
+		if e := c.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,117 @@
+package goex
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWsPool(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for { //原样返回订阅消息
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(websocket.TextMessage, msg)
+		}
+	}))
+	defer server.Close()
+
+	var (
+		lock     sync.Mutex
+		received = make(map[string]*WsConn)
+		done     = make(chan struct{}, 10)
+	)
+	builder := NewWsBuilder().WsUrl("ws"+strings.TrimPrefix(server.URL, "http")).
+		SubscribeRateLimit(1, 50*time.Millisecond).
+		ConnProtoHandleFunc(func(c *WsConn, data []byte) error {
+			lock.Lock()
+			received[string(data)] = c
+			lock.Unlock()
+			done <- struct{}{}
+			return nil
+		})
+	pool := NewWsPool(builder, 3)
+	defer pool.Close()
+
+	start := time.Now()
+	for i := 0; i < 7; i++ {
+		key := fmt.Sprint(i)
+		if err := pool.SubscribeWithId(key, key, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.SubscribeWithId("0", "0", "0")
+
+	//每个连接的订阅消息限频
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatal(elapsed)
+	}
+	if conns := pool.Conns(); len(conns) != 3 {
+		t.Fatal(len(conns))
+	}
+
+	for i := 0; i < 7; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("message timeout")
+		}
+	}
+
+	//所有连接的推送进入同一个回调，并能区分连接
+	lock.Lock()
+	for i := 0; i < 7; i++ {
+		key := fmt.Sprint(i)
+		if c := received[fmt.Sprintf("%q", key)]; c == nil || c != pool.Conn(key) {
+			t.Fatal(key, c)
+		}
+	}
+	lock.Unlock()
+
+	if pool.Conn("0") != pool.Conn("2") || pool.Conn("2") == pool.Conn("3") || pool.Conn("6") != pool.Conns()[2] {
+		t.Fatal("subscriptions should fill connections in order")
+	}
+
+	//取消订阅后空出的位置给之后的订阅使用
+	first := pool.Conn("1")
+	if err := pool.Unsubscribe("1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if pool.Conn("1") != nil {
+		t.Fatal("unsubscribed")
+	}
+	if err := pool.SubscribeWithId("7", "7", "7"); err != nil {
+		t.Fatal(err)
+	}
+	if pool.Conn("7") != first || len(pool.Conns()) != 3 {
+		t.Fatal("should reuse the first connection")
+	}
+
+	if !pool.AckSubscription("5", nil) || pool.AckSubscription("1", nil) {
+		t.Fatal("ack")
+	}
+	if subs := pool.Subscriptions(); len(subs) != 7 || subs[5].Key != "5" || !subs[5].Acked {
+		t.Fatalf("%+v", subs)
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.SubscribeWithId("8", "8", "8"); err != ErrWsClosed {
+		t.Fatal(err)
+	}
+}
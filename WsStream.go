@@ -0,0 +1,453 @@
+package goex
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+//推送速度超过消费速度时的处理方式
+type OverflowPolicy int
+
+const (
+	OVERFLOW_DEFAULT         OverflowPolicy = iota //深度和行情为OVERFLOW_COALESCE_LATEST , 成交为OVERFLOW_BLOCK
+	OVERFLOW_BLOCK                                 //缓冲满时阻塞ws的读goroutine , 不丢数据
+	OVERFLOW_DROP_OLDEST                           //缓冲满时丢弃最早的一条
+	OVERFLOW_COALESCE_LATEST                       //只保留最新的一条，BufferSize无效
+)
+
+const defaultStreamBufferSize = 64
+
+type StreamConfig struct {
+	BufferSize int //消费者没有取走的推送最多缓冲的条数 , <=0时为64
+	Overflow   OverflowPolicy
+}
+
+//一个订阅的推送缓冲，回调中入队，由单独的goroutine投递到channel
+type streamQueue struct {
+	lock    sync.Mutex
+	cond    *sync.Cond
+	items   []interface{}
+	size    int
+	policy  OverflowPolicy
+	closed  bool
+	dropped int64
+}
+
+func newStreamQueue(config StreamConfig, defaultPolicy OverflowPolicy) *streamQueue {
+	q := &streamQueue{size: config.BufferSize, policy: config.Overflow}
+	if q.size <= 0 {
+		q.size = defaultStreamBufferSize
+	}
+	if q.policy == OVERFLOW_DEFAULT {
+		q.policy = defaultPolicy
+	}
+	if q.policy == OVERFLOW_COALESCE_LATEST {
+		q.size = 1
+	}
+	q.cond = sync.NewCond(&q.lock)
+	return q
+}
+
+func (q *streamQueue) push(v interface{}) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for q.policy == OVERFLOW_BLOCK && len(q.items) >= q.size && !q.closed {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return
+	}
+
+	if len(q.items) >= q.size {
+		q.items = q.items[1:]
+		atomic.AddInt64(&q.dropped, 1)
+	}
+	q.items = append(q.items, v)
+	q.cond.Broadcast()
+}
+
+//队列关闭后返回false
+func (q *streamQueue) pop() (interface{}, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return nil, false
+	}
+
+	v := q.items[0]
+	q.items[0] = nil
+	q.items = q.items[1:]
+	q.cond.Broadcast()
+	return v, true
+}
+
+func (q *streamQueue) close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.closed = true
+	q.items = nil
+	q.cond.Broadcast()
+}
+
+//各类型订阅的公共部分
+type stream struct {
+	queue *streamQueue
+	tag   string //合约类型，现货为空
+	done  chan struct{}
+
+	closeOnce sync.Once
+	closeFn   func() error
+	closeErr  error
+}
+
+func newStream(config StreamConfig, defaultPolicy OverflowPolicy, tag string) *stream {
+	return &stream{
+		queue: newStreamQueue(config, defaultPolicy),
+		tag:   tag,
+		done:  make(chan struct{}),
+	}
+}
+
+//send在Close后返回false；退出时调用closeCh关闭channel
+func (s *stream) run(send func(v interface{}) bool, closeCh func()) {
+	defer closeCh()
+	for {
+		v, ok := s.queue.pop()
+		if !ok || !send(v) {
+			return
+		}
+	}
+}
+
+//取消订阅并关闭channel，同一交易对的最后一个订阅关闭时才调用ws的Unsubscribe，可以重复调用
+func (s *stream) Close() error {
+	s.closeOnce.Do(func() {
+		s.queue.close()
+		close(s.done)
+		if s.closeFn != nil {
+			s.closeErr = s.closeFn()
+		}
+	})
+	return s.closeErr
+}
+
+//缓冲满被丢弃或被合并的推送数
+func (s *stream) Dropped() int64 {
+	return atomic.LoadInt64(&s.queue.dropped)
+}
+
+//交易对 -> 订阅 , 同一交易对和合约类型的第一个订阅时调用ws的Subscribe
+type streamHub struct {
+	subLock sync.Mutex //串行化ws的订阅/取消订阅
+	lock    sync.RWMutex
+	streams map[string][]*stream
+}
+
+func newStreamHub() *streamHub {
+	return &streamHub{streams: make(map[string][]*stream)}
+}
+
+func (h *streamHub) count(key, tag string) int {
+	n := 0
+	for _, s := range h.streams[key] {
+		if s.tag == tag {
+			n++
+		}
+	}
+	return n
+}
+
+func (h *streamHub) add(key string, s *stream, subscribe func() error) error {
+	h.subLock.Lock()
+	defer h.subLock.Unlock()
+
+	h.lock.Lock()
+	h.streams[key] = append(h.streams[key], s)
+	first := h.count(key, s.tag) == 1
+	h.lock.Unlock()
+
+	if !first {
+		return nil
+	}
+	if err := subscribe(); err != nil {
+		h.detach(key, s)
+		return err
+	}
+	return nil
+}
+
+func (h *streamHub) detach(key string, s *stream) (last bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	streams := h.streams[key]
+	for i, ss := range streams {
+		if ss == s {
+			streams = append(streams[:i:i], streams[i+1:]...)
+			break
+		}
+	}
+	if len(streams) == 0 {
+		delete(h.streams, key)
+	} else {
+		h.streams[key] = streams
+	}
+	return h.count(key, s.tag) == 0
+}
+
+func (h *streamHub) remove(key string, s *stream, unsubscribe func() error) error {
+	h.subLock.Lock()
+	defer h.subLock.Unlock()
+
+	if h.detach(key, s) {
+		return unsubscribe()
+	}
+	return nil
+}
+
+//推送给交易对的订阅；有合约类型相同的订阅时只推送给它们(推送中的合约类型可能和订阅时不一致，如U本位永续)
+func (h *streamHub) publish(key, tag string, v interface{}) {
+	h.lock.RLock()
+	streams := h.streams[key]
+	matched := 0
+	for _, s := range streams {
+		if s.tag == tag {
+			matched++
+		}
+	}
+	h.lock.RUnlock()
+
+	for _, s := range streams {
+		if matched == 0 || s.tag == tag {
+			s.queue.push(v)
+		}
+	}
+}
+
+func streamKey(pair CurrencyPair) string {
+	return pair.ToSymbol("_")
+}
+
+type DepthStream struct {
+	C <-chan *Depth
+	*stream
+}
+
+func newDepthStream(config StreamConfig, tag string) *DepthStream {
+	ch := make(chan *Depth)
+	s := &DepthStream{C: ch, stream: newStream(config, OVERFLOW_COALESCE_LATEST, tag)}
+	go s.run(func(v interface{}) bool {
+		select {
+		case ch <- v.(*Depth):
+			return true
+		case <-s.done:
+			return false
+		}
+	}, func() { close(ch) })
+	return s
+}
+
+type TickerStream struct {
+	C <-chan *Ticker
+	*stream
+}
+
+func newTickerStream(config StreamConfig) *TickerStream {
+	ch := make(chan *Ticker)
+	s := &TickerStream{C: ch, stream: newStream(config, OVERFLOW_COALESCE_LATEST, "")}
+	go s.run(func(v interface{}) bool {
+		select {
+		case ch <- v.(*Ticker):
+			return true
+		case <-s.done:
+			return false
+		}
+	}, func() { close(ch) })
+	return s
+}
+
+type FutureTickerStream struct {
+	C <-chan *FutureTicker
+	*stream
+}
+
+func newFutureTickerStream(config StreamConfig, tag string) *FutureTickerStream {
+	ch := make(chan *FutureTicker)
+	s := &FutureTickerStream{C: ch, stream: newStream(config, OVERFLOW_COALESCE_LATEST, tag)}
+	go s.run(func(v interface{}) bool {
+		select {
+		case ch <- v.(*FutureTicker):
+			return true
+		case <-s.done:
+			return false
+		}
+	}, func() { close(ch) })
+	return s
+}
+
+//合约成交的合约类型在Trade.ContractType
+type TradeStream struct {
+	C <-chan *Trade
+	*stream
+}
+
+func newTradeStream(config StreamConfig, tag string) *TradeStream {
+	ch := make(chan *Trade)
+	s := &TradeStream{C: ch, stream: newStream(config, OVERFLOW_BLOCK, tag)}
+	go s.run(func(v interface{}) bool {
+		select {
+		case ch <- v.(*Trade):
+			return true
+		case <-s.done:
+			return false
+		}
+	}, func() { close(ch) })
+	return s
+}
+
+//在SpotWsApi的回调之上按交易对分发到多个channel，每个订阅有独立的缓冲和溢出策略，
+//回调中只入队，慢的消费者不会阻塞连接(OVERFLOW_BLOCK除外)；创建后不要再设置ws的回调
+type SpotWsStream struct {
+	ws      SpotWsApi
+	depths  *streamHub
+	tickers *streamHub
+	trades  *streamHub
+}
+
+func NewSpotWsStream(ws SpotWsApi) *SpotWsStream {
+	s := &SpotWsStream{ws: ws, depths: newStreamHub(), tickers: newStreamHub(), trades: newStreamHub()}
+	ws.DepthCallback(func(depth *Depth) {
+		s.depths.publish(streamKey(depth.Pair), "", depth)
+	})
+	ws.TickerCallback(func(ticker *Ticker) {
+		s.tickers.publish(streamKey(ticker.Pair), "", ticker)
+	})
+	ws.TradeCallback(func(trade *Trade) {
+		s.trades.publish(streamKey(trade.Pair), "", trade)
+	})
+	return s
+}
+
+func (s *SpotWsStream) SubscribeDepth(pair CurrencyPair, config StreamConfig) (*DepthStream, error) {
+	ds := newDepthStream(config, "")
+	key := streamKey(pair)
+	ds.closeFn = func() error {
+		return s.depths.remove(key, ds.stream, func() error { return s.ws.UnsubscribeDepth(pair) })
+	}
+	err := s.depths.add(key, ds.stream, func() error { return s.ws.SubscribeDepth(pair) })
+	if err != nil {
+		ds.closeFn = nil
+		ds.Close()
+		return nil, err
+	}
+	return ds, nil
+}
+
+func (s *SpotWsStream) SubscribeTicker(pair CurrencyPair, config StreamConfig) (*TickerStream, error) {
+	ts := newTickerStream(config)
+	key := streamKey(pair)
+	ts.closeFn = func() error {
+		return s.tickers.remove(key, ts.stream, func() error { return s.ws.UnsubscribeTicker(pair) })
+	}
+	err := s.tickers.add(key, ts.stream, func() error { return s.ws.SubscribeTicker(pair) })
+	if err != nil {
+		ts.closeFn = nil
+		ts.Close()
+		return nil, err
+	}
+	return ts, nil
+}
+
+func (s *SpotWsStream) SubscribeTrade(pair CurrencyPair, config StreamConfig) (*TradeStream, error) {
+	ts := newTradeStream(config, "")
+	key := streamKey(pair)
+	ts.closeFn = func() error {
+		return s.trades.remove(key, ts.stream, func() error { return s.ws.UnsubscribeTrade(pair) })
+	}
+	err := s.trades.add(key, ts.stream, func() error { return s.ws.SubscribeTrade(pair) })
+	if err != nil {
+		ts.closeFn = nil
+		ts.Close()
+		return nil, err
+	}
+	return ts, nil
+}
+
+//同SpotWsStream；同一交易对订阅了多个合约类型时，按推送中的ContractType区分，无法区分时推送给该交易对的所有订阅
+type FuturesWsStream struct {
+	ws      FuturesWsApi
+	depths  *streamHub
+	tickers *streamHub
+	trades  *streamHub
+}
+
+func NewFuturesWsStream(ws FuturesWsApi) *FuturesWsStream {
+	s := &FuturesWsStream{ws: ws, depths: newStreamHub(), tickers: newStreamHub(), trades: newStreamHub()}
+	ws.DepthCallback(func(depth *Depth) {
+		s.depths.publish(streamKey(depth.Pair), depth.ContractType, depth)
+	})
+	ws.TickerCallback(func(ticker *FutureTicker) {
+		var pair CurrencyPair
+		if ticker.Ticker != nil {
+			pair = ticker.Pair
+		}
+		s.tickers.publish(streamKey(pair), ticker.ContractType, ticker)
+	})
+	ws.TradeCallback(func(trade *Trade, contract string) {
+		if trade.ContractType == "" {
+			trade.ContractType = contract
+		}
+		s.trades.publish(streamKey(trade.Pair), contract, trade)
+	})
+	return s
+}
+
+func (s *FuturesWsStream) SubscribeDepth(pair CurrencyPair, contractType string, config StreamConfig) (*DepthStream, error) {
+	ds := newDepthStream(config, contractType)
+	key := streamKey(pair)
+	ds.closeFn = func() error {
+		return s.depths.remove(key, ds.stream, func() error { return s.ws.UnsubscribeDepth(pair, contractType) })
+	}
+	err := s.depths.add(key, ds.stream, func() error { return s.ws.SubscribeDepth(pair, contractType) })
+	if err != nil {
+		ds.closeFn = nil
+		ds.Close()
+		return nil, err
+	}
+	return ds, nil
+}
+
+func (s *FuturesWsStream) SubscribeTicker(pair CurrencyPair, contractType string, config StreamConfig) (*FutureTickerStream, error) {
+	ts := newFutureTickerStream(config, contractType)
+	key := streamKey(pair)
+	ts.closeFn = func() error {
+		return s.tickers.remove(key, ts.stream, func() error { return s.ws.UnsubscribeTicker(pair, contractType) })
+	}
+	err := s.tickers.add(key, ts.stream, func() error { return s.ws.SubscribeTicker(pair, contractType) })
+	if err != nil {
+		ts.closeFn = nil
+		ts.Close()
+		return nil, err
+	}
+	return ts, nil
+}
+
+func (s *FuturesWsStream) SubscribeTrade(pair CurrencyPair, contractType string, config StreamConfig) (*TradeStream, error) {
+	ts := newTradeStream(config, contractType)
+	key := streamKey(pair)
+	ts.closeFn = func() error {
+		return s.trades.remove(key, ts.stream, func() error { return s.ws.UnsubscribeTrade(pair, contractType) })
+	}
+	err := s.trades.add(key, ts.stream, func() error { return s.ws.SubscribeTrade(pair, contractType) })
+	if err != nil {
+		ts.closeFn = nil
+		ts.Close()
+		return nil, err
+	}
+	return ts, nil
+}
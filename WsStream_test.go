@@ -0,0 +1,201 @@
+package goex
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockSpotWs struct {
+	lock         sync.Mutex
+	depthFn      func(*Depth)
+	tickerFn     func(*Ticker)
+	tradeFn      func(*Trade)
+	subscribed   map[string]int
+	unsubscribed map[string]int
+	err          error
+}
+
+func newMockSpotWs() *mockSpotWs {
+	return &mockSpotWs{subscribed: make(map[string]int), unsubscribed: make(map[string]int)}
+}
+
+func (m *mockSpotWs) DepthCallback(f func(*Depth))   { m.depthFn = f }
+func (m *mockSpotWs) TickerCallback(f func(*Ticker)) { m.tickerFn = f }
+func (m *mockSpotWs) TradeCallback(f func(*Trade))   { m.tradeFn = f }
+
+func (m *mockSpotWs) sub(topic string, pair CurrencyPair) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.subscribed[topic+":"+pair.String()]++
+	return nil
+}
+
+func (m *mockSpotWs) unsub(topic string, pair CurrencyPair) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.unsubscribed[topic+":"+pair.String()]++
+	return nil
+}
+
+func (m *mockSpotWs) count(counts map[string]int, key string) int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return counts[key]
+}
+
+func (m *mockSpotWs) SubscribeDepth(pair CurrencyPair) error    { return m.sub("depth", pair) }
+func (m *mockSpotWs) SubscribeTicker(pair CurrencyPair) error   { return m.sub("ticker", pair) }
+func (m *mockSpotWs) SubscribeTrade(pair CurrencyPair) error    { return m.sub("trade", pair) }
+func (m *mockSpotWs) UnsubscribeDepth(pair CurrencyPair) error  { return m.unsub("depth", pair) }
+func (m *mockSpotWs) UnsubscribeTicker(pair CurrencyPair) error { return m.unsub("ticker", pair) }
+func (m *mockSpotWs) UnsubscribeTrade(pair CurrencyPair) error  { return m.unsub("trade", pair) }
+
+type mockFuturesWs struct {
+	depthFn  func(*Depth)
+	tickerFn func(*FutureTicker)
+	tradeFn  func(*Trade, string)
+}
+
+func (m *mockFuturesWs) DepthCallback(f func(*Depth))                 { m.depthFn = f }
+func (m *mockFuturesWs) TickerCallback(f func(*FutureTicker))         { m.tickerFn = f }
+func (m *mockFuturesWs) TradeCallback(f func(*Trade, string))         { m.tradeFn = f }
+func (m *mockFuturesWs) SubscribeDepth(CurrencyPair, string) error    { return nil }
+func (m *mockFuturesWs) SubscribeTicker(CurrencyPair, string) error   { return nil }
+func (m *mockFuturesWs) SubscribeTrade(CurrencyPair, string) error    { return nil }
+func (m *mockFuturesWs) UnsubscribeDepth(CurrencyPair, string) error  { return nil }
+func (m *mockFuturesWs) UnsubscribeTicker(CurrencyPair, string) error { return nil }
+func (m *mockFuturesWs) UnsubscribeTrade(CurrencyPair, string) error  { return nil }
+
+func TestSpotWsStream(t *testing.T) {
+	ws := newMockSpotWs()
+	s := NewSpotWsStream(ws)
+
+	//同一交易对的多个订阅只订阅一次
+	latest, err := s.SubscribeDepth(BTC_USDT, StreamConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, _ := s.SubscribeDepth(BTC_USDT, StreamConfig{BufferSize: 3, Overflow: OVERFLOW_DROP_OLDEST})
+	eth, _ := s.SubscribeDepth(ETH_USDT, StreamConfig{})
+	if n := ws.count(ws.subscribed, "depth:BTC_USDT"); n != 1 {
+		t.Fatal(n)
+	}
+
+	//消费者没有读取时回调不阻塞
+	for i := 1; i <= 5; i++ {
+		ws.depthFn(&Depth{Pair: BTC_USDT, Timestamp: int64(i)})
+	}
+
+	if d := <-latest.C; d.Timestamp != 1 && d.Timestamp != 5 {
+		t.Fatal(d.Timestamp)
+	}
+	select {
+	case d := <-eth.C:
+		t.Fatal("unexpected depth", d.Pair)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	//DROP_OLDEST保留最新的3条，投递goroutine可能已取出1条
+	var got []int64
+	for len(got) == 0 || got[len(got)-1] != 5 {
+		got = append(got, (<-all.C).Timestamp)
+	}
+	if len(got) < 3 || len(got) > 4 || all.Dropped() == 0 {
+		t.Fatal(got, all.Dropped())
+	}
+
+	//最后一个订阅关闭后才取消订阅，channel被关闭
+	latest.Close()
+	if n := ws.count(ws.unsubscribed, "depth:BTC_USDT"); n != 0 {
+		t.Fatal(n)
+	}
+	all.Close()
+	all.Close()
+	if n := ws.count(ws.unsubscribed, "depth:BTC_USDT"); n != 1 {
+		t.Fatal(n)
+	}
+	if _, ok := <-all.C; ok {
+		t.Fatal("channel should be closed")
+	}
+	ws.depthFn(&Depth{Pair: BTC_USDT})
+
+	//成交默认阻塞，不丢数据
+	trades, _ := s.SubscribeTrade(BTC_USDT, StreamConfig{BufferSize: 1})
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			ws.tradeFn(&Trade{Tid: int64(i), Pair: BTC_USDT})
+		}
+		close(done)
+	}()
+	for i := 0; i < 100; i++ {
+		if trade := <-trades.C; trade.Tid != int64(i) {
+			t.Fatal(i, trade.Tid)
+		}
+	}
+	<-done
+
+	//关闭后阻塞中的回调返回
+	go ws.tradeFn(&Trade{Pair: BTC_USDT})
+	go ws.tradeFn(&Trade{Pair: BTC_USDT})
+	go ws.tradeFn(&Trade{Pair: BTC_USDT})
+	time.Sleep(20 * time.Millisecond)
+	trades.Close()
+	ws.tradeFn(&Trade{Pair: BTC_USDT})
+
+	ws.err = errors.New("subscribe fail")
+	if _, err = s.SubscribeTicker(BTC_USDT, StreamConfig{}); err == nil {
+		t.Fatal("subscribe should be fail")
+	}
+	ws.err = nil
+	ticker, err := s.SubscribeTicker(BTC_USDT, StreamConfig{})
+	if err != nil || ws.count(ws.subscribed, "ticker:BTC_USDT") != 1 {
+		t.Fatal(err)
+	}
+	ws.tickerFn(&Ticker{Pair: BTC_USDT, Last: 1})
+	if tk := <-ticker.C; tk.Last != 1 {
+		t.Fatal(tk.Last)
+	}
+	ticker.Close()
+	eth.Close()
+}
+
+func TestFuturesWsStream(t *testing.T) {
+	ws := &mockFuturesWs{}
+	s := NewFuturesWsStream(ws)
+
+	swap, _ := s.SubscribeTrade(BTC_USD, SWAP_CONTRACT, StreamConfig{})
+	quarter, _ := s.SubscribeTrade(BTC_USD, QUARTER_CONTRACT, StreamConfig{})
+
+	ws.tradeFn(&Trade{Pair: BTC_USD, Tid: 1}, QUARTER_CONTRACT)
+	if trade := <-quarter.C; trade.Tid != 1 || trade.ContractType != QUARTER_CONTRACT {
+		t.Fatal(trade)
+	}
+	select {
+	case <-swap.C:
+		t.Fatal("quarter trade pushed to swap")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	//推送中的合约类型无法区分时推送给所有订阅
+	depth, _ := s.SubscribeDepth(BTC_USDT, SWAP_USDT_CONTRACT, StreamConfig{})
+	ws.depthFn(&Depth{Pair: BTC_USDT, ContractType: SWAP_CONTRACT})
+	if d := <-depth.C; d.Pair != BTC_USDT {
+		t.Fatal(d.Pair)
+	}
+
+	ticker, _ := s.SubscribeTicker(BTC_USD, SWAP_CONTRACT, StreamConfig{})
+	ws.tickerFn(&FutureTicker{Ticker: &Ticker{Pair: BTC_USD, Last: 2}, ContractType: SWAP_CONTRACT})
+	if tk := <-ticker.C; tk.Last != 2 {
+		t.Fatal(tk.Last)
+	}
+
+	for _, c := range []interface{ Close() error }{swap, quarter, depth, ticker} {
+		c.Close()
+	}
+}
@@ -0,0 +1,171 @@
+package goex
+
+import (
+	"encoding/json"
+	"sync"
+
+	. "github.com/mrwill84/goex/internal/logger"
+)
+
+//WsConn上的一个订阅
+type WsSubscription struct {
+	Key   string //订阅的唯一标识，如交易所的频道名，用于去重和取消订阅
+	Id    string //交易所回应订阅请求时携带的标识(请求id或频道名)，用于关联ack/错误，没有时为空
+	Sub   []byte //订阅消息，重连后重新发送
+	Acked bool   //收到交易所的订阅成功回应，重连后重置
+	Err   error  //交易所返回的订阅错误，订阅失败的不再重新订阅
+}
+
+//按订阅顺序保存，重连后按原顺序重新订阅
+type wsSubscriptions struct {
+	lock sync.Mutex
+	keys []string
+	subs map[string]*WsSubscription
+}
+
+func newWsSubscriptions() *wsSubscriptions {
+	return &wsSubscriptions{subs: make(map[string]*WsSubscription, 4)}
+}
+
+//已存在时返回false；被交易所拒绝(Err不为nil)的订阅视为不存在，替换后保留原来的顺序
+func (s *wsSubscriptions) add(sub *WsSubscription) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if old, ok := s.subs[sub.Key]; ok {
+		if old.Err == nil {
+			return false
+		}
+		s.subs[sub.Key] = sub
+		return true
+	}
+	s.keys = append(s.keys, sub.Key)
+	s.subs[sub.Key] = sub
+	return true
+}
+
+func (s *wsSubscriptions) remove(key string) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.subs[key]; !ok {
+		return false
+	}
+	delete(s.subs, key)
+	for i, k := range s.keys {
+		if k == key {
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (s *wsSubscriptions) list() []WsSubscription {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	subs := make([]WsSubscription, 0, len(s.keys))
+	for _, key := range s.keys {
+		subs = append(subs, *s.subs[key])
+	}
+	return subs
+}
+
+func (s *wsSubscriptions) ack(id string, err error) (WsSubscription, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if id == "" {
+		return WsSubscription{}, false
+	}
+	for _, key := range s.keys {
+		sub := s.subs[key]
+		if sub.Id == id {
+			sub.Acked = err == nil
+			sub.Err = err
+			return *sub, true
+		}
+	}
+	return WsSubscription{}, false
+}
+
+//重连后需要重新发送的订阅消息，同时重置ack状态
+func (s *wsSubscriptions) active() [][]byte {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var msgs [][]byte
+	for _, key := range s.keys {
+		sub := s.subs[key]
+		if sub.Err != nil {
+			continue
+		}
+		sub.Acked = false
+		msgs = append(msgs, sub.Sub)
+	}
+	return msgs
+}
+
+//以订阅消息本身作为Key去重，不关联ack
+func (ws *WsConn) Subscribe(subEvent interface{}) error {
+	data, err := json.Marshal(subEvent)
+	if err != nil {
+		Log.Errorf("[ws][%s] json encode error , %s", ws.WsUrl, err)
+		return err
+	}
+	return ws.subscribe(&WsSubscription{Key: string(data), Sub: data})
+}
+
+//key相同的订阅只发送一次，之前被交易所拒绝的会重新发送；id为交易所回应中用于关联该订阅的标识，收到回应后调用AckSubscription
+func (ws *WsConn) SubscribeWithId(key, id string, subEvent interface{}) error {
+	data, err := json.Marshal(subEvent)
+	if err != nil {
+		Log.Errorf("[ws][%s] json encode error , %s", ws.WsUrl, err)
+		return err
+	}
+	return ws.subscribe(&WsSubscription{Key: key, Id: id, Sub: data})
+}
+
+func (ws *WsConn) subscribe(sub *WsSubscription) error {
+	if !ws.subscriptions.add(sub) {
+		Log.Debugf("[ws][%s] already subscribed %s", ws.WsUrl, sub.Key)
+		return nil
+	}
+	Log.Debug(string(sub.Sub))
+	err := ws.waitSubscribe()
+	if err == nil {
+		err = ws.send(ws.writeBufferChan, sub.Sub)
+	}
+	if err != nil {
+		ws.subscriptions.remove(sub.Key)
+		return err
+	}
+	return nil
+}
+
+//从订阅列表中移除并发送取消订阅消息(unsubEvent为nil时不发送)，没有订阅过时直接返回nil
+func (ws *WsConn) Unsubscribe(key string, unsubEvent interface{}) error {
+	if !ws.subscriptions.remove(key) || unsubEvent == nil {
+		return nil
+	}
+	if err := ws.waitSubscribe(); err != nil {
+		return err
+	}
+	return ws.SendJsonMessage(unsubEvent)
+}
+
+//当前的订阅，按订阅顺序
+func (ws *WsConn) Subscriptions() []WsSubscription {
+	return ws.subscriptions.list()
+}
+
+//适配器收到交易所的订阅回应时调用，err不为nil表示订阅失败；返回false表示没有对应id的订阅
+func (ws *WsConn) AckSubscription(id string, err error) bool {
+	sub, ok := ws.subscriptions.ack(id, err)
+	if !ok {
+		return false
+	}
+	if err != nil {
+		Log.Errorf("[ws][%s] subscribe %s fail , %s", ws.WsUrl, sub.Key, err.Error())
+		ws.emit(WsEvent{Type: WS_EVENT_SUBSCRIBE_FAILED, Subscription: sub.Key, Err: err})
+	} else {
+		ws.emit(WsEvent{Type: WS_EVENT_SUBSCRIBED, Subscription: sub.Key})
+	}
+	return true
+}
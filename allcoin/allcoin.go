@@ -32,6 +32,10 @@ type Allcoin struct {
 	httpClient *http.Client
 }
 
+var (
+	_ API = (*Allcoin)(nil)
+)
+
 func (ac *Allcoin) buildParamsSigned(postForm *url.Values) error {
 	//postForm.Set("api_key", ac.accessKey)
 	//postForm.Set("secret_key", ac.secretKey)
@@ -445,7 +449,7 @@ func (ac *Allcoin) GetUnfinishOrders(currencyPair CurrencyPair) ([]Order, error)
 	return orders, nil
 }
 
-func (ac *Allcoin) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
+func (ac *Allcoin) GetKlineRecords(currency CurrencyPair, period KlinePeriod, size int, optional ...OptionalParameter) ([]Kline, error) {
 	panic("not implements")
 }
 
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var ac = New(http.DefaultClient, "", "")
@@ -29,5 +30,6 @@ func TestAllcoin_GetDepth(t *testing.T) {
 }
 
 func TestAllcoin_LimitBuy(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(ac.LimitBuy("1", "0.07", goex.ETH_BTC))
 }
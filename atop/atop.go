@@ -138,6 +138,10 @@ type Atop struct {
 	httpClient *http.Client
 }
 
+var (
+	_ API = (*Atop)(nil)
+)
+
 //hao
 func (at *Atop) buildPostForm(postForm *url.Values) error {
 	postForm.Set("accesskey", at.accessKey)
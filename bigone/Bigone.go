@@ -30,6 +30,10 @@ type Bigone struct {
 	timeOffset int64
 }
 
+var (
+	_ goex.API = (*Bigone)(nil)
+)
+
 func New(client *http.Client, api_key, secret_key string) *Bigone {
 	return &Bigone{accessKey: api_key, secretKey: secret_key, httpClient: client, uid: uuid.New().String(), baseUri: V2}
 }
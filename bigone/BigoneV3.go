@@ -17,6 +17,10 @@ type BigoneV3 struct {
 	Bigone
 }
 
+var (
+	_ goex.API = (*BigoneV3)(nil)
+)
+
 //accessKey,
 //secretKey string
 //httpClient *http.Client
@@ -156,11 +160,11 @@ func (bo *BigoneV3) placeOrder(amount, price string, pair goex.CurrencyPair, ord
 		OrderTime:  int(time.Now().Unix())}, nil
 }
 
-func (bo *BigoneV3) LimitBuy(amount, price string, currency goex.CurrencyPair) (*goex.Order, error) {
+func (bo *BigoneV3) LimitBuy(amount, price string, currency goex.CurrencyPair, opt ...goex.LimitOrderOptionalParameter) (*goex.Order, error) {
 	return bo.placeOrder(amount, price, currency, "LIMIT", "BID")
 }
 
-func (bo *BigoneV3) LimitSell(amount, price string, currency goex.CurrencyPair) (*goex.Order, error) {
+func (bo *BigoneV3) LimitSell(amount, price string, currency goex.CurrencyPair, opt ...goex.LimitOrderOptionalParameter) (*goex.Order, error) {
 	return bo.placeOrder(amount, price, currency, "LIMIT", "ASK")
 }
 
@@ -355,7 +359,7 @@ func (bo *BigoneV3) GetOneOrder(orderId string, currencyPair goex.CurrencyPair)
 func (bo *BigoneV3) GetUnfinishOrders(currencyPair goex.CurrencyPair) ([]goex.Order, error) {
 	return bo.getOrdersList(currencyPair, 200, goex.ORDER_UNFINISH)
 }
-func (bo *BigoneV3) GetOrderHistorys(currencyPair goex.CurrencyPair, opt goex.OptionalParameter) ([]goex.Order, error) {
+func (bo *BigoneV3) GetOrderHistorys(currencyPair goex.CurrencyPair, opt ...goex.OptionalParameter) ([]goex.Order, error) {
 	return bo.getOrdersList(currencyPair, 200, goex.ORDER_FINISH)
 }
 
@@ -452,11 +456,11 @@ type CandleResp struct {
 	} `json:"data"`
 }
 
-func (bo *BigoneV3) GetKlineRecords(currency goex.CurrencyPair, period, size, since int) ([]goex.Kline, error) {
+func (bo *BigoneV3) GetKlineRecords(currency goex.CurrencyPair, period goex.KlinePeriod, size int, optional ...goex.OptionalParameter) ([]goex.Kline, error) {
 	apiUrl := fmt.Sprintf("%s/asset_pairs/%s/candles", bo.baseUri, currency.ToSymbol("-"))
 	params := url.Values{}
 	params.Set("asset_pair_name", currency.ToSymbol("-"))
-	params.Set("period", _INERNAL_KLINE_PERIOD_CONVERTER[period])
+	params.Set("period", _INERNAL_KLINE_PERIOD_CONVERTER[int(period)])
 	params.Set("limit", fmt.Sprint(size))
 	//params["period"] = _INERNAL_KLINE_PERIOD_CONVERTER[period]
 	//params["time"] =
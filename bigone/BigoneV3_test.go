@@ -52,7 +52,7 @@ func TestBigoneV3_GetUnfinishOrders(t *testing.T) {
 }
 func TestBigoneV3_GetOrderHistorys(t *testing.T) {
 	return
-	t.Log(b1.GetOrderHistorys(BTC_USDT, 1, 1))
+	t.Log(b1.GetOrderHistorys(BTC_USDT))
 }
 func TestBigoneV3_LimitSell(t *testing.T) {
 	return
@@ -72,6 +72,6 @@ func TestBigoneV3_GetOneOrder(t *testing.T) {
 }
 func TestBigoneV3_GetKlineRecords(t *testing.T) {
 	return
-	t.Log(b1.GetKlineRecords(ETH_BTC, KLINE_PERIOD_1MIN, 1, 1))
+	t.Log(b1.GetKlineRecords(ETH_BTC, KLINE_PERIOD_1MIN, 1))
 
 }
@@ -3,6 +3,7 @@ package binance
 import (
 	"fmt"
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/orderbook"
 	"strings"
 )
 
@@ -46,6 +47,22 @@ func adaptSymbolToCurrencyPair(symbol string) goex.CurrencyPair {
 	return goex.UNKNOWN_PAIR
 }
 
+//合约推送的symbol映射回交易对时的候选类型：U本位永续合约(BTCUSDT)与现货同名，需要限定类型；币本位合约带后缀，不会重名
+func futuresSymbolContractTypes(symbol string) []string {
+	if strings.Contains(symbol, "_") {
+		return nil
+	}
+	return []string{goex.SWAP_USDT_CONTRACT}
+}
+
+//成交均价 = 成交金额 / 成交数量 , 保留8位小数 ; 没有成交时为0
+func adaptAvgPrice(quoteQty, qty goex.Decimal) goex.Decimal {
+	if qty.Sign() <= 0 {
+		return goex.DecimalZero
+	}
+	return quoteQty.Div(qty, 8)
+}
+
 func adaptOrderStatus(status string) goex.TradeStatus {
 	var tradeStatus goex.TradeStatus
 	switch status {
@@ -64,3 +81,91 @@ func adaptOrderStatus(status string) goex.TradeStatus {
 	}
 	return tradeStatus
 }
+
+func adaptFuturesOType(side string, positionSide string) int {
+	if positionSide == "BOTH" && side == "SELL" {
+		return goex.OPEN_SELL
+	}
+
+	if positionSide == "BOTH" && side == "BUY" {
+		return goex.OPEN_BUY
+	}
+
+	if positionSide == "LONG" {
+		switch side {
+		case "BUY":
+			return goex.OPEN_BUY
+		default:
+			return goex.CLOSE_BUY
+		}
+	}
+
+	if positionSide == "SHORT" {
+		switch side {
+		case "SELL":
+			return goex.OPEN_SELL
+		default:
+			return goex.CLOSE_SELL
+		}
+	}
+
+	return 0
+}
+
+//[["price","qty"]] -> []orderbook.Level
+func adaptDepthLevels(items [][]interface{}) []orderbook.Level {
+	levels := make([]orderbook.Level, 0, len(items))
+	for _, item := range items {
+		if len(item) < 2 {
+			continue
+		}
+		levels = append(levels, orderbook.Level{
+			Price:  fmt.Sprint(item[0]),
+			Amount: fmt.Sprint(item[1]),
+		})
+	}
+	return levels
+}
+
+//PERPETUAL , CURRENT_QUARTER , NEXT_QUARTER , 其它类型返回空
+func adaptContractType(contractType string) string {
+	switch contractType {
+	case "PERPETUAL":
+		return goex.SWAP_CONTRACT
+	case "CURRENT_QUARTER":
+		return goex.QUARTER_CONTRACT
+	case "NEXT_QUARTER":
+		return goex.BI_QUARTER_CONTRACT
+	}
+	return ""
+}
+
+func adaptMarket(ts TradeSymbol, contractType string) goex.Market {
+	m := goex.Market{
+		Pair:         goex.NewCurrencyPair(goex.NewCurrency(ts.BaseAsset, ""), goex.NewCurrency(ts.QuoteAsset, "")),
+		ContractType: contractType,
+		Symbol:       ts.Symbol,
+		Status:       goex.MARKET_SUSPEND,
+	}
+
+	switch ts.Status {
+	case "TRADING":
+		m.Status = goex.MARKET_TRADING
+	case "SETTLING", "DELIVERING", "DELIVERED", "CLOSE":
+		m.Status = goex.MARKET_OFFLINE
+	}
+
+	for _, f := range ts.Filters {
+		switch f.FilterType {
+		case "PRICE_FILTER":
+			m.TickSize = goex.NewDecimalFromFloat(f.TickSize)
+		case "LOT_SIZE":
+			m.LotSize = goex.NewDecimalFromFloat(f.StepSize)
+			m.MinAmount = goex.NewDecimalFromFloat(f.MinQty)
+		case "MIN_NOTIONAL", "NOTIONAL":
+			m.MinNotional = goex.NewDecimalFromFloat(f.MinNotional + f.Notional)
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,200 @@
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	. "github.com/mrwill84/goex"
+)
+
+const (
+	swapBatchPlaceSize  = 5  //fapi batchOrders 每次最多5个订单
+	swapBatchCancelSize = 10 //每次最多撤销10个订单
+)
+
+//现货没有批量下单接口，并发逐个下单
+func (bn *Binance) BatchLimitOrders(orders []Order) ([]BatchOrderResult, error) {
+	return LimitOrdersConcurrently(bn, orders), nil
+}
+
+func (bn *Binance) BatchCancelOrders(currency CurrencyPair, orderIds []string) ([]BatchOrderResult, error) {
+	return CancelOrdersConcurrently(bn, currency, orderIds), nil
+}
+
+func (bn *Binance) CancelAllOrders(currency CurrencyPair) (bool, error) {
+	params := url.Values{}
+	params.Set("symbol", currency.ToSymbol(""))
+
+	bn.buildParamsSigned(&params)
+
+	_, err := HttpDeleteForm(bn.httpClient, bn.apiV3+"openOrders", params, map[string]string{"X-MBX-APIKEY": bn.accessKey})
+	if err != nil {
+		err = adaptError(err)
+		//没有未完成订单时返回 -2011 Unknown order sent
+		if errors.Is(err, EX_ERR_NOT_FIND_ORDER) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+//批量接口中单个订单的结果: 成功时为订单，失败时为 {"code":-2010,"msg":"..."}
+type batchOrderItem struct {
+	Code          int64  `json:"code"`
+	Msg           string `json:"msg"`
+	OrderId       int64  `json:"orderId"`
+	ClientOrderId string `json:"clientOrderId"`
+}
+
+func (item *batchOrderItem) result() BatchOrderResult {
+	if item.Code != 0 && item.OrderId == 0 {
+		return BatchOrderResult{Cid: item.ClientOrderId, Err: adaptErrorCode(nil, item.Code, item.Msg)}
+	}
+	return BatchOrderResult{OrderId: strconv.FormatInt(item.OrderId, 10), Cid: item.ClientOrderId}
+}
+
+func swapOrderSide(oType int) string {
+	switch oType {
+	case OPEN_BUY, CLOSE_SELL:
+		return "BUY"
+	default:
+		return "SELL"
+	}
+}
+
+func swapTimeInForce(orderType int) string {
+	switch orderType {
+	case ORDER_FEATURE_POST_ONLY:
+		return "GTX"
+	case ORDER_FEATURE_FOK:
+		return "FOK"
+	case ORDER_FEATURE_IOC:
+		return "IOC"
+	default:
+		return "GTC"
+	}
+}
+
+//USDT本位合约批量下限价单，按每批5个订单分批请求
+func (bs *BinanceSwap) BatchLimitFuturesOrders(orders []FutureOrder) ([]BatchOrderResult, error) {
+	results := make([]BatchOrderResult, 0, len(orders))
+
+	for start := 0; start < len(orders); start += swapBatchPlaceSize {
+		end := start + swapBatchPlaceSize
+		if end > len(orders) {
+			end = len(orders)
+		}
+
+		batch := make([]map[string]string, 0, end-start)
+		for _, ord := range orders[start:end] {
+			pair := bs.adaptCurrencyPair(ord.Currency)
+			o := map[string]string{
+				"symbol":      pair.ToSymbol(""),
+				"side":        swapOrderSide(ord.OType),
+				"type":        "LIMIT",
+				"timeInForce": swapTimeInForce(ord.OrderType),
+				"quantity":    NewDecimalFromFloat(ord.Amount).String(),
+				"price":       NewDecimalFromFloat(ord.Price).String(),
+			}
+			if bs.hedgeMode {
+				//双向持仓不能使用reduceOnly
+				o["positionSide"] = adaptPositionSide(true, ord.OType)
+			} else if ord.OType == CLOSE_BUY || ord.OType == CLOSE_SELL {
+				o["reduceOnly"] = "true"
+			}
+			if ord.ClientOid != "" {
+				o["newClientOrderId"] = ord.ClientOid
+			}
+			batch = append(batch, o)
+		}
+
+		data, _ := json.Marshal(batch)
+		params := url.Values{}
+		params.Set("batchOrders", string(data))
+
+		items, err := bs.batchOrdersRequest(HttpPostForm2, params)
+		if err == nil && len(items) != end-start {
+			err = errors.New("batchOrders response size mismatch")
+		}
+		if err != nil {
+			for _, ord := range orders[start:] {
+				results = append(results, BatchOrderResult{Cid: ord.ClientOid, Err: err})
+			}
+			return results, err
+		}
+
+		for i := range items {
+			r := items[i].result()
+			if r.Cid == "" {
+				r.Cid = orders[start+i].ClientOid
+			}
+			results = append(results, r)
+		}
+	}
+
+	return results, nil
+}
+
+//批量撤单，按每批10个订单分批请求
+func (bs *BinanceSwap) BatchFutureCancelOrders(currencyPair CurrencyPair, contractType string, orderIds []string) ([]BatchOrderResult, error) {
+	results := make([]BatchOrderResult, 0, len(orderIds))
+
+	for start := 0; start < len(orderIds); start += swapBatchCancelSize {
+		end := start + swapBatchCancelSize
+		if end > len(orderIds) {
+			end = len(orderIds)
+		}
+
+		ids := make([]int64, 0, end-start)
+		for _, id := range orderIds[start:end] {
+			ids = append(ids, ToInt64(id))
+		}
+
+		list, _ := json.Marshal(ids)
+		params := url.Values{}
+		params.Set("symbol", bs.adaptCurrencyPair(currencyPair).ToSymbol(""))
+		params.Set("orderIdList", string(list))
+
+		items, err := bs.batchOrdersRequest(HttpDeleteForm, params)
+		if err == nil && len(items) != end-start {
+			err = errors.New("batchOrders response size mismatch")
+		}
+		if err != nil {
+			for _, id := range orderIds[start:] {
+				results = append(results, BatchOrderResult{OrderId: id, Err: err})
+			}
+			return results, err
+		}
+
+		for i := range items {
+			r := items[i].result()
+			if r.OrderId == "" || r.OrderId == "0" {
+				r.OrderId = orderIds[start+i]
+			}
+			results = append(results, r)
+		}
+	}
+
+	return results, nil
+}
+
+func (bs *BinanceSwap) batchOrdersRequest(method func(*http.Client, string, url.Values, map[string]string) ([]byte, error), params url.Values) ([]batchOrderItem, error) {
+	bs.buildParamsSigned(&params)
+
+	resp, err := method(bs.httpClient, bs.apiV1+"batchOrders", params, map[string]string{"X-MBX-APIKEY": bs.accessKey})
+	if err != nil {
+		return nil, adaptError(err)
+	}
+
+	var items []batchOrderItem
+	if err = json.Unmarshal(resp, &items); err != nil {
+		return nil, errors.New(string(resp))
+	}
+
+	return items, nil
+}
@@ -0,0 +1,114 @@
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mrwill84/goex"
+)
+
+func TestBinanceSwap_BatchLimitFuturesOrders(t *testing.T) {
+	var batches [][]map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		//NewBinanceSwap会请求服务器时间和dapi exchangeInfo
+		if r.Method != http.MethodPost || r.URL.Path != "/fapi/v1/batchOrders" {
+			http.NotFound(w, r)
+			return
+		}
+		r.ParseForm()
+		var batch []map[string]string
+		json.Unmarshal([]byte(r.Form.Get("batchOrders")), &batch)
+		batches = append(batches, batch)
+
+		var resp []interface{}
+		for _, o := range batch {
+			if o["newClientOrderId"] == "c6" {
+				resp = append(resp, map[string]interface{}{"code": -2019, "msg": "Margin is insufficient."})
+				continue
+			}
+			resp = append(resp, map[string]interface{}{"orderId": 1000 + len(resp), "clientOrderId": o["newClientOrderId"]})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	swap := NewBinanceSwap(&goex.APIConfig{Endpoint: server.URL, HttpClient: http.DefaultClient})
+
+	var orders []goex.FutureOrder
+	for i := 0; i < 7; i++ {
+		orders = append(orders, goex.FutureOrder{
+			Currency:  goex.BTC_USDT,
+			ClientOid: fmt.Sprintf("c%d", i),
+			Price:     100,
+			Amount:    1,
+			OType:     goex.CLOSE_BUY,
+			OrderType: goex.ORDER_FEATURE_POST_ONLY,
+		})
+	}
+
+	results, err := swap.BatchLimitFuturesOrders(orders)
+	if err != nil || len(results) != 7 {
+		t.Fatal(results, err)
+	}
+	if len(batches) != 2 || len(batches[0]) != 5 || len(batches[1]) != 2 {
+		t.Fatal(batches)
+	}
+	if o := batches[0][0]; o["side"] != "SELL" || o["timeInForce"] != "GTX" || o["reduceOnly"] != "true" || o["symbol"] != "BTCUSDT" {
+		t.Fatal(o)
+	}
+
+	if results[5].OrderId != "1000" || results[5].Cid != "c5" {
+		t.Fatal(results[5])
+	}
+	if results[6].Cid != "c6" || !errors.Is(results[6].Err, goex.EX_ERR_INSUFFICIENT_BALANCE) {
+		t.Fatal(results[6])
+	}
+}
+
+//第二批请求失败时返回第一批的结果，失败批次的订单带错误
+func TestBinanceSwap_BatchLimitFuturesOrdersChunkFailed(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/fapi/v1/batchOrders" {
+			http.NotFound(w, r)
+			return
+		}
+		calls++
+		if calls > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"code":-1001,"msg":"Internal error; unable to process your request. Please try again."}`))
+			return
+		}
+		r.ParseForm()
+		var batch []map[string]string
+		json.Unmarshal([]byte(r.Form.Get("batchOrders")), &batch)
+		var resp []interface{}
+		for _, o := range batch {
+			resp = append(resp, map[string]interface{}{"orderId": 1000 + len(resp), "clientOrderId": o["newClientOrderId"]})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	swap := NewBinanceSwap(&goex.APIConfig{Endpoint: server.URL, HttpClient: http.DefaultClient})
+
+	var orders []goex.FutureOrder
+	for i := 0; i < 7; i++ {
+		orders = append(orders, goex.FutureOrder{Currency: goex.BTC_USDT, ClientOid: fmt.Sprintf("c%d", i), Price: 100, Amount: 1, OType: goex.OPEN_BUY})
+	}
+
+	results, err := swap.BatchLimitFuturesOrders(orders)
+	if err == nil || len(results) != 7 {
+		t.Fatal(results, err)
+	}
+	if results[4].OrderId != "1004" || results[4].Err != nil {
+		t.Fatal(results[4])
+	}
+	if results[5].Cid != "c5" || results[5].Err == nil || results[6].Err == nil {
+		t.Fatal(results[5:])
+	}
+}
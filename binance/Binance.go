@@ -1,6 +1,7 @@
 package binance
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,9 +10,11 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	. "github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/orderbook"
 )
 
 const (
@@ -62,6 +65,7 @@ type Filter struct {
 	MaxQty              float64 `json:"maxQty,string"`
 	StepSize            float64 `json:"stepSize,string"`
 	MinNotional         float64 `json:"minNotional,string"`
+	Notional            float64 `json:"notional,string"` //合约MIN_NOTIONAL
 	ApplyToMarket       bool    `json:"applyToMarket"`
 	Limit               int     `json:"limit"`
 	MaxNumAlgoOrders    int     `json:"maxNumAlgoOrders"`
@@ -166,8 +170,17 @@ type Binance struct {
 	httpClient *http.Client
 	timeOffset int64 //nanosecond
 	*ExchangeInfo
+	exchangeInfoLock sync.RWMutex //保护ExchangeInfo，GetMarkets可能在后台刷新
 }
 
+var (
+	_ API              = (*Binance)(nil)
+	_ ContextBinder    = (*Binance)(nil)
+	_ BatchOrderAPI    = (*Binance)(nil)
+	_ ClientOrderIdAPI = (*Binance)(nil)
+	_ MarketInfo       = (*Binance)(nil)
+)
+
 func (bn *Binance) buildParamsSigned(postForm *url.Values) error {
 	postForm.Set("recvWindow", "60000")
 	tonce := strconv.FormatInt(time.Now().UnixMilli(), 10)
@@ -206,6 +219,24 @@ func (bn *Binance) GetExchangeName() string {
 	return BINANCE
 }
 
+// 返回绑定了ctx的副本，所有http请求都受ctx控制
+func (bn *Binance) WithContext(ctx context.Context) API {
+	bn.exchangeInfoLock.RLock()
+	info := bn.ExchangeInfo
+	bn.exchangeInfoLock.RUnlock()
+
+	return &Binance{
+		accessKey:    bn.accessKey,
+		secretKey:    bn.secretKey,
+		baseUrl:      bn.baseUrl,
+		apiV1:        bn.apiV1,
+		apiV3:        bn.apiV3,
+		httpClient:   HttpClientWithContext(ctx, bn.httpClient),
+		timeOffset:   bn.timeOffset,
+		ExchangeInfo: info,
+	}
+}
+
 func (bn *Binance) Ping() bool {
 	_, err := HttpGet(bn.httpClient, bn.apiV3+"ping")
 	if err != nil {
@@ -313,19 +344,55 @@ func (bn *Binance) GetDepth(size int, currencyPair CurrencyPair) (*Depth, error)
 	return depth, nil
 }
 
-func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType, orderSide string) (*Order, error) {
+//获取带lastUpdateId的深度快照，用于同步本地订单簿 , apiBase: apiV3(现货) , fapi/dapi apiV1(合约)
+func (bn *Binance) getDepthSnapshot(apiBase, symbol string, limit int) (*orderbook.Snapshot, error) {
+	var resp struct {
+		LastUpdateId int64           `json:"lastUpdateId"`
+		Bids         [][]interface{} `json:"bids"`
+		Asks         [][]interface{} `json:"asks"`
+	}
+
+	err := HttpGet4(bn.httpClient, fmt.Sprintf(apiBase+DEPTH_URI, symbol, limit), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &orderbook.Snapshot{
+		Asks:     adaptDepthLevels(resp.Asks),
+		Bids:     adaptDepthLevels(resp.Bids),
+		UpdateId: resp.LastUpdateId,
+	}, nil
+}
+
+func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType, orderSide string, opt ...LimitOrderOptionalParameter) (*Order, error) {
 	path := bn.apiV3 + ORDER_URI
 	params := url.Values{}
 	params.Set("symbol", pair.ToSymbol(""))
 	params.Set("side", orderSide)
 	params.Set("type", orderType)
 	params.Set("newOrderRespType", "ACK")
-	params.Set("quantity", amount)
+	params.Set("quantity", DecimalString(amount))
+
+	cid := GetClientOrderId(opt...)
+	if cid != "" {
+		params.Set("newClientOrderId", cid)
+	}
 
 	switch orderType {
 	case "LIMIT":
 		params.Set("timeInForce", "GTC")
-		params.Set("price", price)
+		params.Set("price", DecimalString(price))
+		if tif, ok := GetTimeInForce(opt...); ok {
+			switch tif {
+			case PostOnly:
+				params.Set("type", "LIMIT_MAKER")
+				params.Del("timeInForce")
+			case Ioc:
+				params.Set("timeInForce", "IOC")
+			case Fok:
+				params.Set("timeInForce", "FOK")
+			}
+		}
 	case "MARKET":
 		params.Set("newOrderRespType", "RESULT")
 	}
@@ -335,7 +402,7 @@ func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType
 	resp, err := HttpPostForm2(bn.httpClient, path, params,
 		map[string]string{"X-MBX-APIKEY": bn.accessKey})
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 
 	respmap := make(map[string]interface{})
@@ -354,24 +421,30 @@ func (bn *Binance) placeOrder(amount, price string, pair CurrencyPair, orderType
 		side = SELL
 	}
 
-	dealAmount := ToFloat64(respmap["executedQty"])
-	cummulativeQuoteQty := ToFloat64(respmap["cummulativeQuoteQty"])
-	avgPrice := 0.0
-	if cummulativeQuoteQty > 0 && dealAmount > 0 {
-		avgPrice = cummulativeQuoteQty / dealAmount
+	decimals := OrderDecimals{
+		Price:      ToDecimal(price),
+		Amount:     ToDecimal(amount),
+		DealAmount: ToDecimal(respmap["executedQty"]),
+	}
+	decimals.AvgPrice = adaptAvgPrice(ToDecimal(respmap["cummulativeQuoteQty"]), decimals.DealAmount)
+
+	if cid == "" {
+		cid, _ = respmap["clientOrderId"].(string)
 	}
 
 	return &Order{
 		Currency:   pair,
 		OrderID:    orderId,
 		OrderID2:   strconv.Itoa(orderId),
-		Price:      ToFloat64(price),
-		Amount:     ToFloat64(amount),
-		DealAmount: dealAmount,
-		AvgPrice:   avgPrice,
+		Cid:        cid,
+		Price:      decimals.Price.Float64(),
+		Amount:     decimals.Amount.Float64(),
+		DealAmount: decimals.DealAmount.Float64(),
+		AvgPrice:   decimals.AvgPrice.Float64(),
 		Side:       TradeSide(side),
 		Status:     ORDER_UNFINISH,
-		OrderTime:  ToInt(respmap["transactTime"])}, nil
+		OrderTime:  ToInt(respmap["transactTime"]),
+		Decimals:   decimals}, nil
 }
 
 func (bn *Binance) GetAccount() (*Account, error) {
@@ -380,10 +453,10 @@ func (bn *Binance) GetAccount() (*Account, error) {
 	path := bn.apiV3 + ACCOUNT_URI + params.Encode()
 	respmap, err := HttpGet2(bn.httpClient, path, map[string]string{"X-MBX-APIKEY": bn.accessKey})
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 	if _, isok := respmap["code"]; isok == true {
-		return nil, errors.New(respmap["msg"].(string))
+		return nil, adaptErrorCode(nil, ToInt64(respmap["code"]), fmt.Sprint(respmap["msg"]))
 	}
 	acc := Account{}
 	acc.Exchange = bn.GetExchangeName()
@@ -404,11 +477,11 @@ func (bn *Binance) GetAccount() (*Account, error) {
 }
 
 func (bn *Binance) LimitBuy(amount, price string, currencyPair CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
-	return bn.placeOrder(amount, price, currencyPair, "LIMIT", "BUY")
+	return bn.placeOrder(amount, price, currencyPair, "LIMIT", "BUY", opt...)
 }
 
 func (bn *Binance) LimitSell(amount, price string, currencyPair CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
-	return bn.placeOrder(amount, price, currencyPair, "LIMIT", "SELL")
+	return bn.placeOrder(amount, price, currencyPair, "LIMIT", "SELL", opt...)
 }
 
 func (bn *Binance) MarketBuy(amount, price string, currencyPair CurrencyPair) (*Order, error) {
@@ -420,17 +493,26 @@ func (bn *Binance) MarketSell(amount, price string, currencyPair CurrencyPair) (
 }
 
 func (bn *Binance) CancelOrder(orderId string, currencyPair CurrencyPair) (bool, error) {
+	return bn.cancelOrder("orderId", orderId, currencyPair)
+}
+
+func (bn *Binance) CancelOrderByClientId(cid string, currencyPair CurrencyPair) (bool, error) {
+	return bn.cancelOrder("origClientOrderId", cid, currencyPair)
+}
+
+//idKey: orderId 或 origClientOrderId
+func (bn *Binance) cancelOrder(idKey, id string, currencyPair CurrencyPair) (bool, error) {
 	path := bn.apiV3 + ORDER_URI
 	params := url.Values{}
 	params.Set("symbol", currencyPair.ToSymbol(""))
-	params.Set("orderId", orderId)
+	params.Set(idKey, id)
 
 	bn.buildParamsSigned(&params)
 
 	resp, err := HttpDeleteForm(bn.httpClient, path, params, map[string]string{"X-MBX-APIKEY": bn.accessKey})
 
 	if err != nil {
-		return false, bn.adaptError(err)
+		return false, adaptError(err)
 	}
 
 	respmap := make(map[string]interface{})
@@ -448,19 +530,25 @@ func (bn *Binance) CancelOrder(orderId string, currencyPair CurrencyPair) (bool,
 }
 
 func (bn *Binance) GetOneOrder(orderId string, currencyPair CurrencyPair) (*Order, error) {
+	return bn.getOrder("orderId", orderId, currencyPair)
+}
+
+func (bn *Binance) GetOrderByClientId(cid string, currencyPair CurrencyPair) (*Order, error) {
+	return bn.getOrder("origClientOrderId", cid, currencyPair)
+}
+
+//idKey: orderId 或 origClientOrderId
+func (bn *Binance) getOrder(idKey, id string, currencyPair CurrencyPair) (*Order, error) {
 	params := url.Values{}
 	params.Set("symbol", currencyPair.ToSymbol(""))
-	if orderId != "" {
-		params.Set("orderId", orderId)
-	}
-	params.Set("orderId", orderId)
+	params.Set(idKey, id)
 
 	bn.buildParamsSigned(&params)
 	path := bn.apiV3 + ORDER_URI + "?" + params.Encode()
 
 	respmap, err := HttpGet2(bn.httpClient, path, map[string]string{"X-MBX-APIKEY": bn.accessKey})
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 
 	order := bn.adaptOrder(currencyPair, respmap)
@@ -477,7 +565,7 @@ func (bn *Binance) GetUnfinishOrders(currencyPair CurrencyPair) ([]Order, error)
 
 	respmap, err := HttpGet3(bn.httpClient, path, map[string]string{"X-MBX-APIKEY": bn.accessKey})
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 
 	orders := make([]Order, 0)
@@ -566,7 +654,7 @@ func (bn *Binance) GetOrderHistorys(currency CurrencyPair, optional ...OptionalP
 
 	respmap, err := HttpGet3(bn.httpClient, path, map[string]string{"X-MBX-APIKEY": bn.accessKey})
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 
 	orders := make([]Order, 0)
@@ -580,14 +668,11 @@ func (bn *Binance) GetOrderHistorys(currency CurrencyPair, optional ...OptionalP
 }
 
 func (bn *Binance) toCurrencyPair(symbol string) CurrencyPair {
-	if bn.ExchangeInfo == nil {
-		var err error
-		bn.ExchangeInfo, err = bn.GetExchangeInfo()
-		if err != nil {
-			return CurrencyPair{}
-		}
+	info, err := bn.cachedExchangeInfo()
+	if err != nil {
+		return CurrencyPair{}
 	}
-	for _, v := range bn.ExchangeInfo.Symbols {
+	for _, v := range info.Symbols {
 		if v.Symbol == symbol {
 			return NewCurrencyPair2(v.BaseAsset + "_" + v.QuoteAsset)
 		}
@@ -609,39 +694,88 @@ func (bn *Binance) GetExchangeInfo() (*ExchangeInfo, error) {
 	return info, nil
 }
 
+//缓存的ExchangeInfo，没有时拉取
+func (bn *Binance) cachedExchangeInfo() (*ExchangeInfo, error) {
+	bn.exchangeInfoLock.RLock()
+	info := bn.ExchangeInfo
+	bn.exchangeInfoLock.RUnlock()
+	if info != nil {
+		return info, nil
+	}
+
+	info, err := bn.GetExchangeInfo()
+	if err != nil {
+		return nil, err
+	}
+	bn.setExchangeInfo(info)
+	return info, nil
+}
+
+func (bn *Binance) setExchangeInfo(info *ExchangeInfo) {
+	bn.exchangeInfoLock.Lock()
+	bn.ExchangeInfo = info
+	bn.exchangeInfoLock.Unlock()
+}
+
+func (bn *Binance) GetMarkets() ([]Market, error) {
+	info, err := bn.GetExchangeInfo()
+	if err != nil {
+		return nil, err
+	}
+	bn.setExchangeInfo(info)
+
+	markets := make([]Market, 0, len(info.Symbols))
+	for _, v := range info.Symbols {
+		markets = append(markets, adaptMarket(v, ""))
+	}
+
+	return markets, nil
+}
+
 func (bn *Binance) GetTradeSymbol(currencyPair CurrencyPair) (*TradeSymbol, error) {
-	if bn.ExchangeInfo == nil {
-		var err error
-		bn.ExchangeInfo, err = bn.GetExchangeInfo()
-		if err != nil {
-			return nil, err
-		}
+	info, err := bn.cachedExchangeInfo()
+	if err != nil {
+		return nil, err
 	}
-	for k, v := range bn.ExchangeInfo.Symbols {
+	for k, v := range info.Symbols {
 		if v.Symbol == currencyPair.ToSymbol("") {
-			return &bn.ExchangeInfo.Symbols[k], nil
+			return &info.Symbols[k], nil
 		}
 	}
 	return nil, errors.New("symbol not found")
 }
 
-func (bn *Binance) adaptError(err error) error {
-	errStr := err.Error()
-
-	if strings.Contains(errStr, "Order does not exist") ||
-		strings.Contains(errStr, "Unknown order sent") {
-		return EX_ERR_NOT_FIND_ORDER.OriginErr(errStr)
+//user data stream listenKey , uri: api/v3/userDataStream (spot) , fapi/v1/listenKey , dapi/v1/listenKey
+func (bn *Binance) newListenKey(uri string) (string, error) {
+	resp, err := HttpPostForm2(bn.httpClient, uri, url.Values{}, map[string]string{"X-MBX-APIKEY": bn.accessKey})
+	if err != nil {
+		return "", adaptError(err)
 	}
 
-	if strings.Contains(errStr, "Too much request") {
-		return EX_ERR_API_LIMIT.OriginErr(errStr)
+	var ret struct {
+		ListenKey string `json:"listenKey"`
+	}
+	err = json.Unmarshal(resp, &ret)
+	if err != nil {
+		return "", err
 	}
 
-	if strings.Contains(errStr, "insufficient") {
-		return EX_ERR_INSUFFICIENT_BALANCE.OriginErr(errStr)
+	if ret.ListenKey == "" {
+		return "", errors.New(string(resp))
 	}
 
-	return err
+	return ret.ListenKey, nil
+}
+
+//listenKey 有效期60分钟，需要定时延长
+func (bn *Binance) keepaliveListenKey(uri, listenKey string) error {
+	params := url.Values{}
+	params.Set("listenKey", listenKey)
+	_, err := HttpPut(bn.httpClient, uri, params, map[string]string{"X-MBX-APIKEY": bn.accessKey})
+	if err != nil {
+		return adaptError(err)
+	}
+	return nil
 }
 
 func (bn *Binance) adaptOrder(currencyPair CurrencyPair, orderMap map[string]interface{}) Order {
@@ -652,25 +786,26 @@ func (bn *Binance) adaptOrder(currencyPair CurrencyPair, orderMap map[string]int
 		orderSide = BUY
 	}
 
-	quoteQty := ToFloat64(orderMap["cummulativeQuoteQty"])
-	qty := ToFloat64(orderMap["executedQty"])
-	avgPrice := 0.0
-	if qty > 0 {
-		avgPrice = FloatToFixed(quoteQty/qty, 8)
+	decimals := OrderDecimals{
+		Price:      ToDecimal(orderMap["price"]),
+		Amount:     ToDecimal(orderMap["origQty"]),
+		DealAmount: ToDecimal(orderMap["executedQty"]),
 	}
+	decimals.AvgPrice = adaptAvgPrice(ToDecimal(orderMap["cummulativeQuoteQty"]), decimals.DealAmount)
 
 	return Order{
 		OrderID:      ToInt(orderMap["orderId"]),
 		OrderID2:     fmt.Sprintf("%.0f", orderMap["orderId"]),
 		Cid:          orderMap["clientOrderId"].(string),
 		Currency:     currencyPair,
-		Price:        ToFloat64(orderMap["price"]),
-		Amount:       ToFloat64(orderMap["origQty"]),
-		DealAmount:   ToFloat64(orderMap["executedQty"]),
-		AvgPrice:     avgPrice,
+		Price:        decimals.Price.Float64(),
+		Amount:       decimals.Amount.Float64(),
+		DealAmount:   decimals.DealAmount.Float64(),
+		AvgPrice:     decimals.AvgPrice.Float64(),
 		Side:         TradeSide(orderSide),
 		Status:       adaptOrderStatus(orderMap["status"].(string)),
 		OrderTime:    ToInt(orderMap["time"]),
 		FinishedTime: ToInt64(orderMap["updateTime"]),
+		Decimals:     decimals,
 	}
 }
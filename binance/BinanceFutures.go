@@ -39,10 +39,10 @@ type OrderInfoResponse struct {
 	Pair          string  `json:"pair"`
 	ClientOrderId string  `json:"clientOrderId"`
 	OrderId       int64   `json:"orderId"`
-	AvgPrice      float64 `json:"avgPrice,string"`
-	ExecutedQty   float64 `json:"executedQty,string"`
-	OrigQty       float64 `json:"origQty,string"`
-	Price         float64 `json:"price,string"`
+	AvgPrice      Decimal `json:"avgPrice"`
+	ExecutedQty   Decimal `json:"executedQty"`
+	OrigQty       Decimal `json:"origQty"`
+	Price         Decimal `json:"price"`
 	Side          string  `json:"side"`
 	PositionSide  string  `json:"positionSide"`
 	Status        string  `json:"status"`
@@ -51,6 +51,10 @@ type OrderInfoResponse struct {
 	UpdateTime    int64   `json:"updateTime"`
 }
 
+func (r OrderInfoResponse) decimals() OrderDecimals {
+	return OrderDecimals{Price: r.Price, Amount: r.OrigQty, AvgPrice: r.AvgPrice, DealAmount: r.ExecutedQty}
+}
+
 type PositionRiskResponse struct {
 	Symbol           string  `json:"symbol"`
 	PositionAmt      float64 `json:"positionAmt,string"`
@@ -75,11 +79,17 @@ type SymbolInfo struct {
 type BinanceFutures struct {
 	base         *Binance
 	apikey       string
+	hedgeMode    bool //双向持仓
 	exchangeInfo *struct {
 		Symbols []SymbolInfo `json:"symbols"`
 	}
 }
 
+var (
+	_ FutureRestAPI = (*BinanceFutures)(nil)
+	_ MarketInfo    = (*BinanceFutures)(nil)
+)
+
 func NewBinanceFutures(config *APIConfig) *BinanceFutures {
 	if config.Endpoint == "" {
 		config.Endpoint = "https://dapi.binance.com"
@@ -243,7 +253,7 @@ func (bs *BinanceFutures) GetFutureUserinfo(currencyPair ...CurrencyPair) (*Futu
 		"X-MBX-APIKEY": bs.apikey})
 
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 
 	logger.Debug(string(respData))
@@ -274,9 +284,13 @@ func (bs *BinanceFutures) GetFutureUserinfo(currencyPair ...CurrencyPair) (*Futu
 	return &futureAccounts, nil
 }
 
-func (bs *BinanceFutures) PlaceFutureOrder(cid string, currencyPair CurrencyPair,
+func (bs *BinanceFutures) PlaceFutureOrder(currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice int, leverRate float64) (string, error) {
+	return bs.placeFutureOrder(NewClientOrderId(BINANCE), currencyPair, contractType, price, amount, openType, matchPrice)
+}
+
+func (bs *BinanceFutures) placeFutureOrder(cid string, currencyPair CurrencyPair,
 	contractType, price, amount string,
-	openType, matchPrice int, leverRate float64) (string, error) {
+	openType, matchPrice int, opt ...LimitOrderOptionalParameter) (string, error) {
 	apiPath := "order"
 	symbol, err := bs.adaptToSymbol(currencyPair, contractType)
 	if err != nil {
@@ -286,13 +300,23 @@ func (bs *BinanceFutures) PlaceFutureOrder(cid string, currencyPair CurrencyPair
 	param := url.Values{}
 	param.Set("symbol", symbol)
 	param.Set("newClientOrderId", cid)
-	param.Set("quantity", amount)
+	param.Set("quantity", DecimalString(amount))
 	param.Set("newOrderRespType", "ACK")
 
 	if matchPrice == 0 {
 		param.Set("type", "LIMIT")
 		param.Set("timeInForce", "GTC")
-		param.Set("price", price)
+		param.Set("price", DecimalString(price))
+		if tif, ok := GetTimeInForce(opt...); ok {
+			switch tif {
+			case PostOnly:
+				param.Set("timeInForce", "GTX")
+			case Ioc:
+				param.Set("timeInForce", "IOC")
+			case Fok:
+				param.Set("timeInForce", "FOK")
+			}
+		}
 	} else {
 		param.Set("type", "MARKET")
 	}
@@ -303,6 +327,9 @@ func (bs *BinanceFutures) PlaceFutureOrder(cid string, currencyPair CurrencyPair
 	case OPEN_SELL, CLOSE_BUY:
 		param.Set("side", "SELL")
 	}
+	if bs.hedgeMode {
+		param.Set("positionSide", adaptPositionSide(true, openType))
+	}
 
 	bs.base.buildParamsSigned(&param)
 
@@ -310,7 +337,7 @@ func (bs *BinanceFutures) PlaceFutureOrder(cid string, currencyPair CurrencyPair
 		map[string]string{"X-MBX-APIKEY": bs.apikey})
 
 	if err != nil {
-		return "", err
+		return "", adaptError(err)
 	}
 
 	logger.Debug(string(resp))
@@ -332,9 +359,14 @@ func (bs *BinanceFutures) PlaceFutureOrder(cid string, currencyPair CurrencyPair
 	return "", errors.New(response.Msg)
 }
 
-func (bs *BinanceFutures) LimitFuturesOrder(cid string, currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
-	orderId, err := bs.PlaceFutureOrder(cid, currencyPair, contractType, price, amount, openType, 0, 10)
+func (bs *BinanceFutures) LimitFuturesOrder(currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
+	cid := GetClientOrderId(opt...)
+	if cid == "" {
+		cid = NewClientOrderId(BINANCE)
+	}
+	orderId, err := bs.placeFutureOrder(cid, currencyPair, contractType, price, amount, openType, 0, opt...)
 	return &FutureOrder{
+		ClientOid:    cid,
 		OrderID2:     orderId,
 		Currency:     currencyPair,
 		ContractName: contractType,
@@ -344,9 +376,11 @@ func (bs *BinanceFutures) LimitFuturesOrder(cid string, currencyPair CurrencyPai
 	}, err
 }
 
-func (bs *BinanceFutures) MarketFuturesOrder(cid string, currencyPair CurrencyPair, contractType, amount string, openType int) (*FutureOrder, error) {
-	orderId, err := bs.PlaceFutureOrder(cid, currencyPair, contractType, "", amount, openType, 1, 10)
+func (bs *BinanceFutures) MarketFuturesOrder(currencyPair CurrencyPair, contractType, amount string, openType int) (*FutureOrder, error) {
+	cid := NewClientOrderId(BINANCE)
+	orderId, err := bs.placeFutureOrder(cid, currencyPair, contractType, "", amount, openType, 1)
 	return &FutureOrder{
+		ClientOid:    cid,
 		OrderID2:     orderId,
 		Currency:     currencyPair,
 		ContractName: contractType,
@@ -376,7 +410,7 @@ func (bs *BinanceFutures) FutureCancelOrder(currencyPair CurrencyPair, contractT
 	resp, err := HttpDeleteForm(bs.base.httpClient, reqUrl, url.Values{}, map[string]string{"X-MBX-APIKEY": bs.apikey})
 	if err != nil {
 		logger.Errorf("request url: %s", reqUrl)
-		return false, err
+		return false, adaptError(err)
 	}
 
 	logger.Debug(string(resp))
@@ -396,7 +430,7 @@ func (bs *BinanceFutures) GetFuturePosition(currencyPair CurrencyPair, contractT
 
 	respBody, err := HttpGet5(bs.base.httpClient, path, map[string]string{"X-MBX-APIKEY": bs.apikey})
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 	logger.Debug(string(respBody))
 
@@ -465,7 +499,7 @@ func (bs *BinanceFutures) GetFutureOrder(orderId string, currencyPair CurrencyPa
 	resp, err := HttpGet5(bs.base.httpClient, reqUrl, map[string]string{"X-MBX-APIKEY": bs.apikey})
 	if err != nil {
 		logger.Errorf("request url: %s", reqUrl)
-		return nil, err
+		return nil, adaptError(err)
 	}
 
 	logger.Debug(string(resp))
@@ -481,15 +515,16 @@ func (bs *BinanceFutures) GetFutureOrder(orderId string, currencyPair CurrencyPa
 		Currency:     currencyPair,
 		ClientOid:    getOrderInfoResponse.ClientOrderId,
 		OrderID2:     fmt.Sprint(getOrderInfoResponse.OrderId),
-		Price:        getOrderInfoResponse.Price,
-		Amount:       getOrderInfoResponse.OrigQty,
-		AvgPrice:     getOrderInfoResponse.AvgPrice,
-		DealAmount:   getOrderInfoResponse.ExecutedQty,
+		Price:        getOrderInfoResponse.Price.Float64(),
+		Amount:       getOrderInfoResponse.OrigQty.Float64(),
+		AvgPrice:     getOrderInfoResponse.AvgPrice.Float64(),
+		DealAmount:   getOrderInfoResponse.ExecutedQty.Float64(),
 		OrderTime:    getOrderInfoResponse.Time / 1000,
 		Status:       bs.adaptStatus(getOrderInfoResponse.Status),
 		OType:        bs.adaptOType(getOrderInfoResponse.Side, getOrderInfoResponse.PositionSide),
 		ContractName: contractType,
 		FinishedTime: getOrderInfoResponse.UpdateTime / 1000,
+		Decimals:     getOrderInfoResponse.decimals(),
 	}, nil
 }
 
@@ -510,7 +545,7 @@ func (bs *BinanceFutures) GetUnfinishFutureOrders(currencyPair CurrencyPair, con
 			"X-MBX-APIKEY": bs.apikey,
 		})
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 	logger.Debug(string(respbody))
 
@@ -530,15 +565,16 @@ func (bs *BinanceFutures) GetUnfinishFutureOrders(currencyPair CurrencyPair, con
 			ClientOid:    ord.ClientOrderId,
 			OrderID:      ord.OrderId,
 			OrderID2:     fmt.Sprint(ord.OrderId),
-			Price:        ord.Price,
-			Amount:       ord.OrigQty,
-			AvgPrice:     ord.AvgPrice,
-			DealAmount:   ord.ExecutedQty,
+			Price:        ord.Price.Float64(),
+			Amount:       ord.OrigQty.Float64(),
+			AvgPrice:     ord.AvgPrice.Float64(),
+			DealAmount:   ord.ExecutedQty.Float64(),
 			Status:       bs.adaptStatus(ord.Status),
 			OType:        bs.adaptOType(ord.Side, ord.PositionSide),
 			ContractName: contractType,
 			FinishedTime: ord.UpdateTime / 1000,
 			OrderTime:    ord.Time / 1000,
+			Decimals:     ord.decimals(),
 		})
 	}
 
@@ -638,6 +674,42 @@ func (bs *BinanceFutures) adaptToSymbol(pair CurrencyPair, contractType string)
 	return "", errors.New("binance not support " + pair.ToSymbol("") + " " + contractType)
 }
 
+//币本位永续和交割合约，交割合约按当季/次季映射为QUARTER_CONTRACT/BI_QUARTER_CONTRACT
+func (bs *BinanceFutures) GetMarkets() ([]Market, error) {
+	resp, err := HttpGet5(bs.base.httpClient, bs.base.apiV1+"exchangeInfo", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		Symbols []struct {
+			TradeSymbol
+			ContractType   string  `json:"contractType"`
+			ContractStatus string  `json:"contractStatus"`
+			ContractSize   float64 `json:"contractSize"`
+		} `json:"symbols"`
+	}
+	err = json.Unmarshal(resp, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, 0, len(info.Symbols))
+	for _, v := range info.Symbols {
+		contractType := adaptContractType(v.ContractType)
+		if contractType == "" {
+			continue
+		}
+		v.Status = v.ContractStatus
+		m := adaptMarket(v.TradeSymbol, contractType)
+		m.ContractVal = NewDecimalFromFloat(v.ContractSize)
+		m.Inverse = true //币本位合约
+		markets = append(markets, m)
+	}
+
+	return markets, nil
+}
+
 func (bs *BinanceFutures) adaptStatus(status string) TradeStatus {
 	switch status {
 	case "NEW":
@@ -658,31 +730,5 @@ func (bs *BinanceFutures) adaptStatus(status string) TradeStatus {
 }
 
 func (bs *BinanceFutures) adaptOType(side string, positionSide string) int {
-	if positionSide == "BOTH" && side == "SELL" {
-		return OPEN_SELL
-	}
-
-	if positionSide == "BOTH" && side == "BUY" {
-		return OPEN_BUY
-	}
-
-	if positionSide == "LONG" {
-		switch side {
-		case "BUY":
-			return OPEN_BUY
-		default:
-			return CLOSE_BUY
-		}
-	}
-
-	if positionSide == "SHORT" {
-		switch side {
-		case "SELL":
-			return OPEN_SELL
-		default:
-			return CLOSE_SELL
-		}
-	}
-
-	return 0
+	return adaptFuturesOType(side, positionSide)
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var baDapi = NewBinanceFutures(&goex.APIConfig{
@@ -19,48 +20,59 @@ func init() {
 }
 
 func TestBinanceFutures_GetFutureDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(baDapi.GetFutureDepth(goex.ETH_USD, goex.QUARTER_CONTRACT, 10))
 }
 
 func TestBinanceSwap_GetFutureTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ticker, err := baDapi.GetFutureTicker(goex.LTC_USD, goex.SWAP_CONTRACT)
 	t.Log(err)
 	t.Logf("%+v", ticker)
 }
 
 func TestBinance_GetExchangeInfo(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	baDapi.GetExchangeInfo()
 }
 
 func TestBinanceFutures_GetFutureUserinfo(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(baDapi.GetFutureUserinfo())
 }
 
 func TestBinanceFutures_PlaceFutureOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	//1044675677
-	t.Log(baDapi.PlaceFutureOrder("wahtthefuck", goex.BTC_USD, goex.QUARTER_CONTRACT, "19990", "2", goex.OPEN_SELL, 0, 10))
+	t.Log(baDapi.PlaceFutureOrder(goex.BTC_USD, goex.QUARTER_CONTRACT, "19990", "2", goex.OPEN_SELL, 0, 10))
 }
 
 func TestBinanceFutures_LimitFuturesOrder(t *testing.T) {
-	t.Log(baDapi.LimitFuturesOrder("wahtthefuck", goex.BTC_USD, goex.QUARTER_CONTRACT, "20001", "2", goex.OPEN_SELL))
+	replay.SkipUnlessLive(t)
+	t.Log(baDapi.LimitFuturesOrder(goex.BTC_USD, goex.QUARTER_CONTRACT, "20001", "2", goex.OPEN_SELL, goex.ClientOrderId("wahtthefuck")))
 }
 
 func TestBinanceFutures_MarketFuturesOrder(t *testing.T) {
-	t.Log(baDapi.MarketFuturesOrder("wahtthefuck", goex.BTC_USD, goex.QUARTER_CONTRACT, "2", goex.OPEN_SELL))
+	replay.SkipUnlessLive(t)
+	t.Log(baDapi.MarketFuturesOrder(goex.BTC_USD, goex.QUARTER_CONTRACT, "2", goex.OPEN_SELL))
 }
 
 func TestBinanceFutures_GetFutureOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(baDapi.GetFutureOrder("1045208666", goex.BTC_USD, goex.QUARTER_CONTRACT))
 }
 
 func TestBinanceFutures_FutureCancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(baDapi.FutureCancelOrder(goex.BTC_USD, goex.QUARTER_CONTRACT, "1045328328"))
 }
 
 func TestBinanceFutures_GetFuturePosition(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(baDapi.GetFuturePosition(goex.BTC_USD, goex.QUARTER_CONTRACT))
 }
 
 func TestBinanceFutures_GetUnfinishFutureOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(baDapi.GetUnfinishFutureOrders(goex.BTC_USD, goex.QUARTER_CONTRACT))
 }
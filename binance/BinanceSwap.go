@@ -19,9 +19,18 @@ const (
 
 type BinanceSwap struct {
 	Binance
-	f *BinanceFutures
+	f         *BinanceFutures
+	hedgeMode bool //双向持仓
 }
 
+var (
+	_ FutureRestAPI          = (*BinanceSwap)(nil)
+	_ BatchFutureOrderAPI    = (*BinanceSwap)(nil)
+	_ AmendFutureOrderAPI    = (*BinanceSwap)(nil)
+	_ FutureClientOrderIdAPI = (*BinanceSwap)(nil)
+	_ FutureCidOrderAPI      = (*BinanceSwap)(nil)
+)
+
 func NewBinanceSwap(config *APIConfig) *BinanceSwap {
 	if config.Endpoint == "" {
 		config.Endpoint = baseUrl
@@ -77,8 +86,9 @@ func (bs *BinanceSwap) setTimeOffset() error {
 	return nil
 }
 
+//永续合约没有预估交割价格，返回标记价格(同GetFutureIndex)
 func (bs *BinanceSwap) GetFutureEstimatedPrice(currencyPair CurrencyPair) (float64, error) {
-	panic("not supported.")
+	return bs.GetFutureIndex(currencyPair)
 }
 
 func (bs *BinanceSwap) GetFutureTicker(currency CurrencyPair, contractType string) (*Ticker, error) {
@@ -181,8 +191,33 @@ func (bs *BinanceSwap) GetFutureDepth(currency CurrencyPair, contractType string
 	return depth, nil
 }
 
+//U本位合约的历史订单(包括未完成订单)，optional支持orderId/startTime/endTime/limit
 func (bs *BinanceSwap) GetFutureOrderHistory(pair CurrencyPair, contractType string, optional ...OptionalParameter) ([]FutureOrder, error) {
-	panic("implement me")
+	if contractType != SWAP_USDT_CONTRACT {
+		return nil, EX_ERR_NOT_SUPPORTED
+	}
+
+	params := url.Values{}
+	params.Set("symbol", bs.adaptCurrencyPair(pair).ToSymbol(""))
+	MergeOptionalParameter(&params, optional...)
+	bs.buildParamsSigned(&params)
+
+	result, err := HttpGet3(bs.httpClient, bs.apiV1+"allOrders?"+params.Encode(), map[string]string{"X-MBX-APIKEY": bs.accessKey})
+	if err != nil {
+		return nil, adaptError(err)
+	}
+
+	orders := make([]FutureOrder, 0, len(result))
+	for _, info := range result {
+		ord, ok := info.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		order := bs.parseOrder(ord)
+		order.Currency = pair
+		orders = append(orders, *order)
+	}
+	return orders, nil
 }
 
 func (bs *BinanceSwap) GetTrades(contractType string, currencyPair CurrencyPair, since int64) ([]Trade, error) {
@@ -197,7 +232,7 @@ func (bs *BinanceSwap) GetTrades(contractType string, currencyPair CurrencyPair,
 	resp, err := HttpGet3(bs.httpClient, apiUrl, map[string]string{
 		"X-MBX-APIKEY": bs.accessKey})
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 
 	var trades []Trade
@@ -241,7 +276,7 @@ func (bs *BinanceSwap) GetFutureUserinfo(currencyPair ...CurrencyPair) (*FutureA
 	path := bs.apiV1 + ACCOUNT_URI + params.Encode()
 	respmap, err := HttpGet2(bs.httpClient, path, map[string]string{"X-MBX-APIKEY": bs.accessKey})
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 
 	if _, isok := respmap["code"]; isok == true {
@@ -276,7 +311,7 @@ func (bs *BinanceSwap) Transfer(currency Currency, transferType int, amount floa
 	resp, err := HttpPostForm2(bs.httpClient, uri, params,
 		map[string]string{"X-MBX-APIKEY": bs.accessKey})
 	if err != nil {
-		return 0, err
+		return 0, adaptError(err)
 	}
 
 	respmap := make(map[string]interface{})
@@ -288,12 +323,19 @@ func (bs *BinanceSwap) Transfer(currency Currency, transferType int, amount floa
 	return ToInt64(respmap["tranId"]), nil
 }
 
-func (bs *BinanceSwap) PlaceFutureOrder(cid string, currencyPair CurrencyPair, contractType, price, amount string, openType string, matchPrice int, leverRate float64) (string, error) {
+func (bs *BinanceSwap) PlaceFutureOrder(currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice int, leverRate float64) (string, error) {
+	return bs.PlaceFutureOrderWithCid(NewClientOrderId(BINANCE_SWAP), currencyPair, contractType, price, amount, OpenTypeName(openType), matchPrice, leverRate)
+}
+
+func (bs *BinanceSwap) PlaceFutureOrderWithCid(cid string, currencyPair CurrencyPair, contractType, price, amount string, openType string, matchPrice int, leverRate float64) (string, error) {
 	fOrder, err := bs.PlaceFutureOrder2(cid, currencyPair, contractType, price, amount, openType, matchPrice, leverRate)
-	return fOrder.OrderID2, err
+	if err != nil {
+		return "", err
+	}
+	return fOrder.OrderID2, nil
 }
 
-func (bs *BinanceSwap) PlaceFutureOrder2(cid string, currencyPair CurrencyPair, contractType, price, amount string, openType string, matchPrice int, leverRate float64) (*FutureOrder, error) {
+func (bs *BinanceSwap) PlaceFutureOrder2(cid string, currencyPair CurrencyPair, contractType, price, amount string, openType string, matchPrice int, leverRate float64, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
 	/*if contractType == SWAP_CONTRACT {
 		orderId, err := bs.f.PlaceFutureOrder(cid, currencyPair.AdaptUsdtToUsd(), contractType, price, amount, openType, matchPrice, leverRate)
 		return &FutureOrder{
@@ -325,29 +367,50 @@ func (bs *BinanceSwap) PlaceFutureOrder2(cid string, currencyPair CurrencyPair,
 			"buy", "short",
 		},
 	}
+	if _, ok := mapping[openType]; !ok {
+		return nil, fmt.Errorf("unknown open type %s", openType)
+	}
 	fOrder := &FutureOrder{
-		Currency:  currencyPair,
-		ClientOid: cid, //GenerateOrderClientId(32),
-		Price:     ToFloat64(price),
-		Amount:    ToFloat64(amount),
-		// OrderType:    openType,
+		Currency:     currencyPair,
+		ClientOid:    cid,
+		Price:        ToFloat64(price),
+		Amount:       ToFloat64(amount),
+		OType:        ParseOpenType(openType),
 		LeverRate:    leverRate,
 		ContractName: contractType,
+		Status:       ORDER_UNFINISH,
+		Decimals:     OrderDecimals{Price: ToDecimal(price), Amount: ToDecimal(amount)},
 	}
 
 	pair := bs.adaptCurrencyPair(currencyPair)
 	path := bs.apiV1 + ORDER_URI
 	params := url.Values{}
 	params.Set("symbol", pair.ToSymbol(""))
-	params.Set("quantity", amount)
+	params.Set("quantity", DecimalString(amount))
 	params.Set("newClientOrderId", fOrder.ClientOid)
 	ot := strings.ToUpper(mapping[openType][0])
 	params.Set("side", ot)
+	if bs.hedgeMode {
+		params.Set("positionSide", adaptPositionSide(true, fOrder.OType))
+	}
 
 	if matchPrice == 0 {
 		params.Set("type", "LIMIT")
-		params.Set("price", price)
+		params.Set("price", DecimalString(price))
 		params.Set("timeInForce", "GTC")
+		if tif, ok := GetTimeInForce(opt...); ok {
+			switch tif {
+			case PostOnly:
+				params.Set("timeInForce", "GTX")
+				fOrder.OrderType = ORDER_FEATURE_POST_ONLY
+			case Ioc:
+				params.Set("timeInForce", "IOC")
+				fOrder.OrderType = ORDER_FEATURE_IOC
+			case Fok:
+				params.Set("timeInForce", "FOK")
+				fOrder.OrderType = ORDER_FEATURE_FOK
+			}
+		}
 	} else {
 		params.Set("type", "MARKET")
 	}
@@ -356,7 +419,7 @@ func (bs *BinanceSwap) PlaceFutureOrder2(cid string, currencyPair CurrencyPair,
 	resp, err := HttpPostForm2(bs.httpClient, path, params,
 		map[string]string{"X-MBX-APIKEY": bs.accessKey})
 	if err != nil {
-		return fOrder, err
+		return fOrder, adaptError(err)
 	}
 
 	respmap := make(map[string]interface{})
@@ -374,11 +437,23 @@ func (bs *BinanceSwap) PlaceFutureOrder2(cid string, currencyPair CurrencyPair,
 	return fOrder, nil
 }
 
-func (bs *BinanceSwap) LimitFuturesOrder(cid string, currencyPair CurrencyPair, contractType, price, amount string, openType string, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
-	return bs.PlaceFutureOrder2(cid, currencyPair, contractType, price, amount, openType, 0, 10)
+func (bs *BinanceSwap) LimitFuturesOrder(currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
+	cid := GetClientOrderId(opt...)
+	if cid == "" {
+		cid = NewClientOrderId(BINANCE_SWAP)
+	}
+	return bs.PlaceFutureOrder2(cid, currencyPair, contractType, price, amount, OpenTypeName(openType), 0, 10, opt...)
+}
+
+func (bs *BinanceSwap) MarketFuturesOrder(currencyPair CurrencyPair, contractType, amount string, openType int) (*FutureOrder, error) {
+	return bs.PlaceFutureOrder2(NewClientOrderId(BINANCE_SWAP), currencyPair, contractType, "0", amount, OpenTypeName(openType), 1, 10)
 }
 
-func (bs *BinanceSwap) MarketFuturesOrder(cid string, currencyPair CurrencyPair, contractType, amount string, openType string) (*FutureOrder, error) {
+func (bs *BinanceSwap) LimitFuturesOrderWithCid(cid string, currencyPair CurrencyPair, contractType, price, amount string, openType string, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
+	return bs.PlaceFutureOrder2(cid, currencyPair, contractType, price, amount, openType, 0, 10, opt...)
+}
+
+func (bs *BinanceSwap) MarketFuturesOrderWithCid(cid string, currencyPair CurrencyPair, contractType, amount string, openType string) (*FutureOrder, error) {
 	return bs.PlaceFutureOrder2(cid, currencyPair, contractType, "0", amount, openType, 1, 10)
 }
 
@@ -402,7 +477,7 @@ func (bs *BinanceSwap) FutureCancelOrder(currencyPair CurrencyPair, contractType
 	resp, err := HttpDeleteForm(bs.httpClient, path, params, map[string]string{"X-MBX-APIKEY": bs.accessKey})
 
 	if err != nil {
-		return false, err
+		return false, adaptError(err)
 	}
 
 	respmap := make(map[string]interface{})
@@ -419,6 +494,31 @@ func (bs *BinanceSwap) FutureCancelOrder(currencyPair CurrencyPair, contractType
 	return true, nil
 }
 
+func (bs *BinanceSwap) FutureCancelOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (bool, error) {
+	params := url.Values{}
+	params.Set("symbol", bs.adaptCurrencyPair(currencyPair).ToSymbol(""))
+	params.Set("origClientOrderId", cid)
+
+	bs.buildParamsSigned(&params)
+
+	resp, err := HttpDeleteForm(bs.httpClient, bs.apiV1+ORDER_URI, params, map[string]string{"X-MBX-APIKEY": bs.accessKey})
+	if err != nil {
+		return false, adaptError(err)
+	}
+
+	respmap := make(map[string]interface{})
+	err = json.Unmarshal(resp, &respmap)
+	if err != nil {
+		return false, err
+	}
+
+	if ToInt(respmap["orderId"]) <= 0 {
+		return false, errors.New(string(resp))
+	}
+
+	return true, nil
+}
+
 func (bs *BinanceSwap) FutureCancelAllOrders(currencyPair CurrencyPair, contractType string) (bool, error) {
 
 	currencyPair = bs.adaptCurrencyPair(currencyPair)
@@ -431,7 +531,7 @@ func (bs *BinanceSwap) FutureCancelAllOrders(currencyPair CurrencyPair, contract
 	resp, err := HttpDeleteForm(bs.httpClient, path, params, map[string]string{"X-MBX-APIKEY": bs.accessKey})
 
 	if err != nil {
-		return false, err
+		return false, adaptError(err)
 	}
 
 	respmap := make(map[string]interface{})
@@ -466,7 +566,7 @@ func (bs *BinanceSwap) FutureCancelOrders(currencyPair CurrencyPair, contractTyp
 	resp, err := HttpDeleteForm(bs.httpClient, path, params, map[string]string{"X-MBX-APIKEY": bs.accessKey})
 
 	if err != nil {
-		return false, err
+		return false, adaptError(err)
 	}
 
 	respmap := make(map[string]interface{})
@@ -482,6 +582,51 @@ func (bs *BinanceSwap) FutureCancelOrders(currencyPair CurrencyPair, contractTyp
 	return true, nil
 }
 
+//修改限价单的价格和数量，side , quantity , price 都是必填参数，先查询原订单补全未修改的参数
+func (bs *BinanceSwap) AmendFutureOrder(currencyPair CurrencyPair, contractType, orderId, newPrice, newAmount string) (*FutureOrder, error) {
+	ord, err := bs.GetFutureOrder(orderId, currencyPair, contractType)
+	if err != nil {
+		return nil, err
+	}
+
+	if newPrice == "" {
+		newPrice = strconv.FormatFloat(ord.Price, 'f', -1, 64)
+	}
+	if newAmount == "" {
+		newAmount = strconv.FormatFloat(ord.Amount, 'f', -1, 64)
+	}
+
+	pair := bs.adaptCurrencyPair(currencyPair)
+	params := url.Values{}
+	params.Set("symbol", pair.ToSymbol(""))
+	params.Set("orderId", orderId)
+	params.Set("side", swapOrderSide(ord.OType))
+	params.Set("quantity", DecimalString(newAmount))
+	params.Set("price", DecimalString(newPrice))
+
+	bs.buildParamsSigned(&params)
+
+	resp, err := HttpPut(bs.httpClient, bs.apiV1+ORDER_URI, params, map[string]string{"X-MBX-APIKEY": bs.accessKey})
+	if err != nil {
+		return nil, adaptError(err)
+	}
+
+	respmap := make(map[string]interface{})
+	err = json.Unmarshal(resp, &respmap)
+	if err != nil {
+		return nil, err
+	}
+
+	if ToInt(respmap["orderId"]) <= 0 {
+		return nil, errors.New(string(resp))
+	}
+
+	order := bs.parseOrder(respmap)
+	order.Currency = currencyPair
+	order.ClientOid, _ = respmap["clientOrderId"].(string)
+	return order, nil
+}
+
 func (bs *BinanceSwap) GetFuturePosition(currencyPair CurrencyPair, contractType string) ([]FuturePosition, error) {
 
 	currencyPair1 := bs.adaptCurrencyPair(currencyPair)
@@ -493,7 +638,7 @@ func (bs *BinanceSwap) GetFuturePosition(currencyPair CurrencyPair, contractType
 	result, err := HttpGet3(bs.httpClient, path, map[string]string{"X-MBX-APIKEY": bs.accessKey})
 
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 
 	var positions []FuturePosition
@@ -553,7 +698,7 @@ func (bs *BinanceSwap) GetFutureOrderByCid(cid string, currencyPair CurrencyPair
 				Currency:   currencyPair,
 			}, nil
 		}
-		return nil, err
+		return nil, adaptError(err)
 	}
 	order := &FutureOrder{}
 	order = bs.parseOrder(_ord)
@@ -562,53 +707,61 @@ func (bs *BinanceSwap) GetFutureOrderByCid(cid string, currencyPair CurrencyPair
 	return order, nil
 
 }
-func (bs *BinanceSwap) GetFutureOrders(orderIds []string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
+func (bs *BinanceSwap) GetFutureOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
+	return bs.GetFutureOrderByCid(cid, currencyPair, contractType)
+}
 
-	if len(orderIds) == 0 {
-		return nil, errors.New("orderIds is empty")
+func (bs *BinanceSwap) GetFutureOrders(orderIds []string, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
+	orders := make([]FutureOrder, 0, len(orderIds))
+	for _, orderId := range orderIds {
+		ord, err := bs.GetFutureOrder(orderId, currencyPair, contractType)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *ord)
 	}
+	return orders, nil
+}
+
+func (bs *BinanceSwap) GetFutureOrder(orderId string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
 	currencyPair1 := bs.adaptCurrencyPair(currencyPair)
 
 	params := url.Values{}
 	params.Set("symbol", currencyPair1.ToSymbol(""))
-	params.Set("orderId", orderIds[0])
+	params.Set("orderId", orderId)
 	bs.buildParamsSigned(&params)
 
 	path := bs.apiV1 + "order?" + params.Encode()
 
 	_ord, err := HttpGet2(bs.httpClient, path, map[string]string{"X-MBX-APIKEY": bs.accessKey})
-	fmt.Println("_ord", _ord)
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
-	order := &FutureOrder{}
-	order = bs.parseOrder(_ord)
+	order := bs.parseOrder(_ord)
 	order.Currency = currencyPair
 	order.ContractName = currencyPair.ToSymbol("-") + "-SWAP"
 	return order, nil
-
-}
-
-//origClientOrderId
-
-func (bs *BinanceSwap) GetFutureOrder(orderId string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
-
-	return bs.GetFutureOrders([]string{orderId}, currencyPair, contractType)
-
 }
 
 func (bs *BinanceSwap) parseOrder(rsp map[string]interface{}) *FutureOrder {
 	order := &FutureOrder{}
-	order.Price = ToFloat64(rsp["price"])
-	order.Amount = ToFloat64(rsp["origQty"])
-	order.DealAmount = ToFloat64(rsp["executedQty"])
-	order.AvgPrice = ToFloat64(rsp["avgPrice"])
+	order.Decimals = OrderDecimals{
+		Price:      ToDecimal(rsp["price"]),
+		Amount:     ToDecimal(rsp["origQty"]),
+		AvgPrice:   ToDecimal(rsp["avgPrice"]),
+		DealAmount: ToDecimal(rsp["executedQty"]),
+	}
+	order.Price = order.Decimals.Price.Float64()
+	order.Amount = order.Decimals.Amount.Float64()
+	order.DealAmount = order.Decimals.DealAmount.Float64()
+	order.AvgPrice = order.Decimals.AvgPrice.Float64()
 	order.OrderTime = ToInt64(rsp["time"])
 	order.ContractName = rsp["symbol"].(string)
 	status := rsp["status"].(string)
 	order.Status = bs.parseOrderStatus(status)
 	order.OrderID = ToInt64(rsp["orderId"])
 	order.OrderID2 = strconv.Itoa(int(order.OrderID))
+	order.ClientOid, _ = rsp["clientOrderId"].(string)
 	order.OType = OPEN_BUY
 	if rsp["side"].(string) == "SELL" {
 		order.OType = OPEN_SELL
@@ -667,7 +820,7 @@ func (bs *BinanceSwap) GetUnfinishFutureOrders(currencyPair CurrencyPair, contra
 	result, err := HttpGet3(bs.httpClient, path, map[string]string{"X-MBX-APIKEY": bs.accessKey})
 
 	if err != nil {
-		return nil, err
+		return nil, adaptError(err)
 	}
 
 	orders := make([]FutureOrder, 0)
@@ -685,16 +838,19 @@ func (bs *BinanceSwap) GetUnfinishFutureOrders(currencyPair CurrencyPair, contra
 	return orders, nil
 }
 
+//手续费按交易对查询(commissionRate)，这里不支持
 func (bs *BinanceSwap) GetFee() (float64, error) {
-	panic("not supported.")
+	return 0, EX_ERR_NOT_SUPPORTED
 }
 
+//U本位合约按币数量下单，没有合约面值
 func (bs *BinanceSwap) GetContractValue(currencyPair CurrencyPair) (float64, error) {
-	panic("not supported.")
+	return 0, EX_ERR_NOT_SUPPORTED
 }
 
+//永续合约没有交割时间
 func (bs *BinanceSwap) GetDeliveryTime() (int, int, int, int) {
-	panic("not supported.")
+	return 0, 0, 0, 0
 }
 
 func (bs *BinanceSwap) GetKlineRecords(contractType string, currency CurrencyPair, period KlinePeriod, size int, opt ...OptionalParameter) ([]FutureKline, error) {
@@ -748,6 +904,35 @@ func (bs *BinanceSwap) GetServerTime() (int64, error) {
 	return stime, nil
 }
 
+//只返回永续合约
+func (bs *BinanceSwap) GetMarkets() ([]Market, error) {
+	resp, err := HttpGet5(bs.httpClient, bs.apiV1+"exchangeInfo", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		Symbols []struct {
+			TradeSymbol
+			ContractType string `json:"contractType"`
+		} `json:"symbols"`
+	}
+	err = json.Unmarshal(resp, &info)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, 0, len(info.Symbols))
+	for _, v := range info.Symbols {
+		if v.ContractType != "PERPETUAL" {
+			continue
+		}
+		markets = append(markets, adaptMarket(v.TradeSymbol, SWAP_CONTRACT))
+	}
+
+	return markets, nil
+}
+
 func (bs *BinanceSwap) adaptCurrencyPair(pair CurrencyPair) CurrencyPair {
 	return pair.AdaptUsdToUsdt()
 }
@@ -1,13 +1,17 @@
 package binance
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	goex "github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var bs = NewBinanceSwap(&goex.APIConfig{
@@ -24,14 +28,17 @@ var bs = NewBinanceSwap(&goex.APIConfig{
 })
 
 func TestBinanceSwap_Ping(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	bs.Ping()
 }
 
 func TestBinanceSwap_GetFutureDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bs.GetFutureDepth(goex.BTC_USDT, "", 1))
 }
 
 func TestBinanceSwap_GetFutureIndex(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bs.GetFutureIndex(goex.BTC_USDT))
 }
 
@@ -41,19 +48,23 @@ func TestBinanceSwap_GetKlineRecords(t *testing.T) {
 }
 
 func TestBinanceSwap_GetTrades(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bs.GetTrades("", goex.BTC_USDT, 0))
 }
 
 func TestBinanceSwap_GetFutureUserinfo(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bs.GetFutureUserinfo())
 }
 
 func TestBinanceSwap_PlaceFutureOrder(t *testing.T) {
-	t.Log(bs.PlaceFutureOrder("waht", goex.BTC_USDT, "", "8322", "0.01", "openlong", 0, 0))
+	replay.SkipUnlessLive(t)
+	t.Log(bs.PlaceFutureOrderWithCid("waht", goex.BTC_USDT, "", "8322", "0.01", "openlong", 0, 0))
 }
 
 func TestBinanceSwap_PlaceFutureOrder2(t *testing.T) {
-	t.Log(bs.PlaceFutureOrder("wahtthefuck", goex.BTC_USDT,
+	replay.SkipUnlessLive(t)
+	t.Log(bs.PlaceFutureOrderWithCid("wahtthefuck", goex.BTC_USDT,
 		goex.SWAP_USDT_CONTRACT,
 		"25999",
 		"0.01",
@@ -61,29 +72,34 @@ func TestBinanceSwap_PlaceFutureOrder2(t *testing.T) {
 }
 
 func TestBinanceSwap_GetFutureOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bs.GetFutureOrder("197306870655", goex.BTC_USDT, goex.SWAP_USDT_CONTRACT))
 }
 
 func TestBinanceSwap_GetFutureOrderByCid(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bs.GetFutureOrderByCid("wahtthefuck", goex.BTC_USDT, goex.SWAP_USDT_CONTRACT))
 }
 
 func TestBinanceSwap_FutureCancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bs.FutureCancelOrder(goex.BTC_USDT,
 		goex.SWAP_USDT_CONTRACT,
 		"197306870655"))
 }
 
 func TestBinanceSwap_GetFuturePosition(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bs.GetFuturePosition(goex.BTC_USDT, ""))
 }
 
 func TestBinanceIntegation(t *testing.T) {
-	order, err := bs.PlaceFutureOrder("wahtthefuck", goex.BTC_USDT,
+	replay.SkipUnlessLive(t)
+	order, err := bs.PlaceFutureOrder(goex.BTC_USDT,
 		goex.SWAP_USDT_CONTRACT,
 		"25999",
 		"0.01",
-		"", 0, 1)
+		goex.OPEN_BUY, 0, 1)
 	fmt.Println(err)
 	bs.FutureCancelOrder(goex.BTC_USDT,
 		goex.SWAP_USDT_CONTRACT,
@@ -91,7 +107,8 @@ func TestBinanceIntegation(t *testing.T) {
 }
 
 func TestBinanceIntegationCid(t *testing.T) {
-	order, err := bs.PlaceFutureOrder("wahtthefuck34", goex.BTC_USDT,
+	replay.SkipUnlessLive(t)
+	order, err := bs.PlaceFutureOrderWithCid("wahtthefuck34", goex.BTC_USDT,
 		goex.SWAP_USDT_CONTRACT,
 		"25999",
 		"0.01",
@@ -105,3 +122,33 @@ func TestBinanceIntegationCid(t *testing.T) {
 	fmt.Println(bs.GetFutureOrderByCid("wahtthefuck34", goex.BTC_USDT, goex.SWAP_USDT_CONTRACT))
 
 }
+
+func TestBinanceSwap_GetFutureOrderHistory(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/allOrders" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.Query()
+		w.Write([]byte(`[{"orderId":1001,"symbol":"BTCUSDT","status":"FILLED","clientOrderId":"c1","price":"100","avgPrice":"100","origQty":"2","executedQty":"2","side":"BUY","positionSide":"BOTH","type":"LIMIT","timeInForce":"GTC","time":1600000000000,"updateTime":1600000000000}]`))
+	}))
+	defer server.Close()
+
+	swap := NewBinanceSwap(&goex.APIConfig{Endpoint: server.URL, HttpClient: http.DefaultClient})
+
+	orders, err := swap.GetFutureOrderHistory(goex.BTC_USDT, goex.SWAP_USDT_CONTRACT, goex.OptionalParameter{}.Optional("limit", "10"))
+	if err != nil || len(orders) != 1 {
+		t.Fatal(orders, err)
+	}
+	if query.Get("symbol") != "BTCUSDT" || query.Get("limit") != "10" {
+		t.Fatal(query)
+	}
+	if orders[0].OrderID2 != "1001" || orders[0].Amount != 2 || orders[0].Status != goex.ORDER_FINISH {
+		t.Fatal(orders[0])
+	}
+
+	if _, err = swap.GetFee(); !errors.Is(err, goex.EX_ERR_NOT_SUPPORTED) {
+		t.Fatal(err)
+	}
+}
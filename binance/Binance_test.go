@@ -1,12 +1,15 @@
 package binance
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var ba = NewWithConfig(
@@ -15,22 +18,109 @@ var ba = NewWithConfig(
 		Endpoint:   "https://api.binancezh.pro",
 	})
 
+//回放testdata中的fixture , GOEX_RECORD=1时请求真实的交易所并更新fixture
+func newReplayBinance(t *testing.T, fixture string) *Binance {
+	srv := replay.NewServer(t, "testdata/"+fixture, GLOBAL_API_BASE_URL)
+	return NewWithConfig(&goex.APIConfig{
+		HttpClient: srv.Client(),
+		Endpoint:   srv.URL,
+	})
+}
+
 func TestBinance_GetTicker(t *testing.T) {
-	ticker, err := ba.GetTicker(goex.NewCurrencyPair2("USDT_USD"))
-	t.Log(ticker, err)
+	ticker, err := newReplayBinance(t, "spot_market.json").GetTicker(goex.BTC_USDT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/spot_ticker.golden.json", ticker)
 }
 
 func TestBinance_LimitBuy(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	order, err := ba.LimitBuy("3", "68.5", goex.LTC_USDT)
 	t.Log(order, err)
 }
 
+func TestBinance_LimitBuyClientOrderId(t *testing.T) {
+	var form map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v3/order" {
+			http.NotFound(w, r)
+			return
+		}
+		r.ParseForm()
+		form = map[string]string{}
+		for k := range r.PostForm {
+			form[k] = r.PostForm.Get(k)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"orderId": 28, "clientOrderId": form["newClientOrderId"]})
+	}))
+	defer server.Close()
+
+	bn := NewWithConfig(&goex.APIConfig{Endpoint: server.URL, HttpClient: http.DefaultClient})
+	ord, err := bn.LimitBuy("1", "100", goex.BTC_USDT, goex.PostOnly, goex.ClientOrderId("goexabc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ord.Cid != "goexabc" || ord.OrderID2 != "28" {
+		t.Fatal(ord)
+	}
+	if form["newClientOrderId"] != "goexabc" || form["type"] != "LIMIT_MAKER" || form["timeInForce"] != "" {
+		t.Fatal(form)
+	}
+
+	//下单参数原样发送，不按CurrencyPair上的默认精度截断
+	if _, err = bn.LimitBuy("0.001", "0.5123", goex.BTC_USDT); err != nil {
+		t.Fatal(err)
+	}
+	if form["quantity"] != "0.001" || form["price"] != "0.5123" {
+		t.Fatal(form)
+	}
+
+	//科学计数法的参数按十进制发送
+	ord, err = bn.LimitBuy("1e-3", "0.5123", goex.BTC_USDT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form["quantity"] != "0.001" || ord.Decimals.Amount.String() != "0.001" || ord.Decimals.Price.String() != "0.5123" {
+		t.Fatal(form, ord.Decimals)
+	}
+}
+
+func TestBinance_GetOneOrderDecimals(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v3/order" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"symbol":"BTCUSDT","orderId":28,"clientOrderId":"goexabc","price":"0.10000000","origQty":"0.30000000",
+"executedQty":"0.20000000","cummulativeQuoteQty":"0.02000000","status":"PARTIALLY_FILLED","side":"BUY","time":1499827319559,"updateTime":1499827319559}`))
+	}))
+	defer server.Close()
+
+	bn := NewWithConfig(&goex.APIConfig{Endpoint: server.URL, HttpClient: http.DefaultClient})
+	ord, err := bn.GetOneOrder("28", goex.BTC_USDT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//十进制值直接从响应的字符串解析
+	d := ord.Decimals
+	if d.Price.String() != "0.10000000" || d.Amount.String() != "0.30000000" || d.DealAmount.String() != "0.20000000" || d.AvgPrice.String() != "0.10000000" {
+		t.Fatal(d)
+	}
+	if d.Amount.Sub(d.DealAmount).String() != "0.10000000" || ord.Amount != 0.3 || ord.AvgPrice != 0.1 {
+		t.Fatal(ord)
+	}
+}
+
 func TestBinance_LimitSell(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	order, err := ba.LimitSell("1", "90", goex.LTC_USDT)
 	t.Log(order, err)
 }
 
 func TestBinance_CancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	r, er := ba.CancelOrder("3848718241", goex.BTC_USDT)
 	if !r {
 		t.Log((er.(goex.ApiError)).ErrCode)
@@ -38,31 +128,33 @@ func TestBinance_CancelOrder(t *testing.T) {
 }
 
 func TestBinance_GetOneOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	odr, err := ba.GetOneOrder("3874087228", goex.BTC_USDT)
 	t.Log(err, odr)
 }
 
 func TestBinance_GetDepth(t *testing.T) {
-	//return
-	dep, err := ba.GetDepth(5, goex.NewCurrencyPair2("BTC_USDT"))
-	t.Log(err)
-	if err == nil {
-		t.Log(dep.AskList)
-		t.Log(dep.BidList)
+	dep, err := newReplayBinance(t, "spot_market.json").GetDepth(5, goex.BTC_USDT)
+	if err != nil {
+		t.Fatal(err)
 	}
+	replay.AssertGolden(t, "testdata/spot_depth.golden.json", dep, "Timestamp")
 }
 
 func TestBinance_GetAccount(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	account, err := ba.GetAccount()
 	t.Log(err, account)
 }
 
 func TestBinance_GetUnfinishOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	orders, err := ba.GetUnfinishOrders(goex.NewCurrencyPair2("BTC_USDT"))
 	t.Log(orders, err)
 }
 
 func TestBinance_GetKlineRecords(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	startTime := time.Now().Add(-24*time.Hour).Unix() * 1000
 	endTime := time.Now().Add(-5*time.Hour).Unix() * 1000
 
@@ -76,19 +168,23 @@ func TestBinance_GetKlineRecords(t *testing.T) {
 }
 
 func TestBinance_GetTrades(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(ba.GetTrades(goex.BTC_USDT, 0))
 }
 
 func TestBinance_GetTradeSymbols(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(ba.GetTradeSymbol(goex.BTC_USDT))
 }
 
 func TestBinance_SetTimeOffset(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(ba.setTimeOffset())
 	t.Log(ba.timeOffset)
 }
 
 func TestBinance_GetOrderHistorys(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(ba.GetOrderHistorys(goex.BTC_USDT,
 		goex.OptionalParameter{}.
 			Optional("startTime", "1607656034333").
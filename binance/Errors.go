@@ -0,0 +1,66 @@
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	. "github.com/mrwill84/goex"
+)
+
+//https://binance-docs.github.io/apidocs/spot/en/#error-codes
+var errorCodeTable = ErrorCodeTable{
+	"-1003": EX_ERR_API_LIMIT,            //TOO_MANY_REQUESTS
+	"-1015": EX_ERR_API_LIMIT,            //TOO_MANY_ORDERS
+	"-1016": EX_ERR_MAINTENANCE,          //SERVICE_SHUTTING_DOWN
+	"-1021": EX_ERR_INVALID_TIMESTAMP,    //INVALID_TIMESTAMP
+	"-1022": EX_ERR_SIGN,                 //INVALID_SIGNATURE
+	"-1013": EX_ERR_INVALID_ORDER_PARAM,  //filter failure
+	"-1111": EX_ERR_INVALID_ORDER_PARAM,  //BAD_PRECISION
+	"-4164": EX_ERR_INVALID_ORDER_PARAM,  //MIN_NOTIONAL
+	"-1121": EX_ERR_SYMBOL_ERR,           //BAD_SYMBOL
+	"-2010": EX_ERR_PLACE_ORDER_FAIL,     //NEW_ORDER_REJECTED
+	"-2011": EX_ERR_CANCEL_ORDER_FAIL,    //CANCEL_REJECTED
+	"-2013": EX_ERR_NOT_FIND_ORDER,       //NO_SUCH_ORDER
+	"-2014": EX_ERR_AUTH_FAIL,            //BAD_API_KEY_FMT
+	"-2015": EX_ERR_AUTH_FAIL,            //REJECTED_MBX_KEY
+	"-2018": EX_ERR_INSUFFICIENT_BALANCE, //BALANCE_NOT_SUFFICIENT
+	"-2019": EX_ERR_INSUFFICIENT_BALANCE, //MARGIN_NOT_SUFFICIEN
+	"-4116": EX_ERR_DUPLICATE_CLIENT_OID, //DUPLICATED_CLIENT_TRAN_ID(合约)
+}
+
+//将http错误响应 {"code":-2010,"msg":"..."} 转换为ApiError , 其它错误原样返回
+func adaptError(err error) error {
+	var httpErr *HttpError
+	if !errors.As(err, &httpErr) {
+		return err
+	}
+
+	var resp struct {
+		Code int64  `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if e := json.Unmarshal([]byte(httpErr.Body), &resp); e != nil || resp.Code == 0 {
+		return err
+	}
+
+	return adaptErrorCode(httpErr, resp.Code, resp.Msg)
+}
+
+func adaptErrorCode(httpErr *HttpError, code int64, msg string) ApiError {
+	//-2010 , -2011 的具体原因只在msg中
+	switch {
+	case strings.Contains(msg, "insufficient balance"):
+		return EX_ERR_INSUFFICIENT_BALANCE.Wrap(strconv.FormatInt(code, 10), msg)
+	case strings.Contains(msg, "Unknown order sent") || strings.Contains(msg, "Order does not exist"):
+		return EX_ERR_NOT_FIND_ORDER.Wrap(strconv.FormatInt(code, 10), msg)
+	case strings.Contains(msg, "Duplicate order sent"):
+		return EX_ERR_DUPLICATE_CLIENT_OID.Wrap(strconv.FormatInt(code, 10), msg)
+	}
+
+	if httpErr == nil {
+		return errorCodeTable.Wrap(strconv.FormatInt(code, 10), msg)
+	}
+	return errorCodeTable.WrapHttpError(httpErr, strconv.FormatInt(code, 10), msg)
+}
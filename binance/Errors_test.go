@@ -0,0 +1,30 @@
+package binance
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mrwill84/goex"
+)
+
+func TestAdaptError(t *testing.T) {
+	err := adaptError(&goex.HttpError{StatusCode: 400, Body: `{"code":-2010,"msg":"Account has insufficient balance for requested action."}`})
+	if !errors.Is(err, goex.EX_ERR_INSUFFICIENT_BALANCE) {
+		t.Fatal(err)
+	}
+
+	err = adaptError(&goex.HttpError{StatusCode: 400, Body: `{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`})
+	if !errors.Is(err, goex.EX_ERR_INVALID_TIMESTAMP) {
+		t.Fatal(err)
+	}
+
+	err = adaptError(&goex.HttpError{StatusCode: 418, Body: `{"code":-1099,"msg":"banned"}`})
+	if !errors.Is(err, goex.EX_ERR_API_LIMIT) {
+		t.Fatal(err)
+	}
+
+	other := errors.New("EOF")
+	if adaptError(other) != other {
+		t.Fatal("non http error should be returned as it is")
+	}
+}
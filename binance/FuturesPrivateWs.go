@@ -0,0 +1,313 @@
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/logger"
+)
+
+//合约私有数据推送(订单/持仓/账户)，usdt本位走fapi用户数据流，币本位走dapi用户数据流
+type FuturesPrivateWs struct {
+	f *userDataStream //usdt swap
+	d *userDataStream //coin futures
+
+	lock          sync.RWMutex
+	orderPairs    map[string]bool
+	positionPairs map[string]bool
+	accountPairs  map[string]bool
+
+	symbolMapper *goex.SymbolMapper
+
+	orderCallFn    func(order *goex.FutureOrder)
+	positionCallFn func(position *goex.FuturePosition)
+	accountCallFn  func(account *goex.FutureAccount)
+}
+
+var (
+	_ goex.FuturesPrivateWsApi = (*FuturesPrivateWs)(nil)
+	_ goex.SymbolMapperAware   = (*FuturesPrivateWs)(nil)
+)
+
+func NewFuturesPrivateWs(config *goex.APIConfig) *FuturesPrivateWs {
+	if config.Endpoint == "" {
+		config.Endpoint = baseUrl
+	}
+
+	fapi := &Binance{
+		baseUrl:    config.Endpoint,
+		apiV1:      config.Endpoint + "/fapi/v1/",
+		accessKey:  config.ApiKey,
+		secretKey:  config.ApiSecretKey,
+		httpClient: config.HttpClient,
+	}
+
+	dEndpoint := strings.ReplaceAll(config.Endpoint, "fapi", "dapi")
+	dapi := &Binance{
+		baseUrl:    dEndpoint,
+		apiV1:      dEndpoint + "/dapi/v1/",
+		accessKey:  config.ApiKey,
+		secretKey:  config.ApiSecretKey,
+		httpClient: config.HttpClient,
+	}
+
+	s := &FuturesPrivateWs{
+		orderPairs:    make(map[string]bool, 2),
+		positionPairs: make(map[string]bool, 2),
+		accountPairs:  make(map[string]bool, 2),
+	}
+	s.f = newUserDataStream(fapi, fapi.apiV1+"listenKey", "wss://fstream.binance.com/ws/", s.handle)
+	s.d = newUserDataStream(dapi, dapi.apiV1+"listenKey", "wss://dstream.binance.com/ws/", s.handle)
+
+	return s
+}
+
+//设置后使用交易规则把推送的symbol映射回交易对，交割合约映射为QUARTER_CONTRACT/BI_QUARTER_CONTRACT，
+//mapper应包含BinanceSwap和BinanceFutures的交易规则，见goex.JoinMarketInfo
+func (s *FuturesPrivateWs) SetSymbolMapper(mapper *goex.SymbolMapper) {
+	s.symbolMapper = mapper
+}
+
+func (s *FuturesPrivateWs) OrderCallback(f func(order *goex.FutureOrder)) {
+	s.orderCallFn = f
+}
+
+func (s *FuturesPrivateWs) PositionCallback(f func(position *goex.FuturePosition)) {
+	s.positionCallFn = f
+}
+
+func (s *FuturesPrivateWs) AccountCallback(f func(account *goex.FutureAccount)) {
+	s.accountCallFn = f
+}
+
+//创建usdt本位合约的用户数据流，币本位合约的用户数据流在订阅时创建
+func (s *FuturesPrivateWs) Login() error {
+	return s.f.start()
+}
+
+func (s *FuturesPrivateWs) SubscribeOrder(pair goex.CurrencyPair, contractType string) error {
+	if s.orderCallFn == nil {
+		return errors.New("please set order callback func")
+	}
+	return s.subscribe(s.orderPairs, pair, contractType)
+}
+
+func (s *FuturesPrivateWs) SubscribePosition(pair goex.CurrencyPair, contractType string) error {
+	if s.positionCallFn == nil {
+		return errors.New("please set position callback func")
+	}
+	return s.subscribe(s.positionPairs, pair, contractType)
+}
+
+//pair的计价币为usdt时订阅usdt本位合约账户，否则订阅币本位合约账户
+func (s *FuturesPrivateWs) SubscribeAccount(pair goex.CurrencyPair) error {
+	if s.accountCallFn == nil {
+		return errors.New("please set account callback func")
+	}
+
+	contractType := goex.SWAP_CONTRACT
+	if pair.CurrencyB == goex.USDT {
+		contractType = goex.SWAP_USDT_CONTRACT
+	}
+
+	return s.subscribe(s.accountPairs, pair, contractType)
+}
+
+func (s *FuturesPrivateWs) subscribe(pairs map[string]bool, pair goex.CurrencyPair, contractType string) error {
+	stream := s.d
+	pair = pair.AdaptUsdtToUsd()
+	if contractType == goex.SWAP_USDT_CONTRACT {
+		stream = s.f
+		pair = pair.AdaptUsdToUsdt()
+	}
+
+	s.lock.Lock()
+	pairs[pair.ToSymbol("_")] = true
+	s.lock.Unlock()
+
+	return stream.start()
+}
+
+//BTCUSDT -> BTC_USDT , BTCUSD_PERP -> BTC_USD
+func (s *FuturesPrivateWs) adaptSymbol(symbol string) (goex.CurrencyPair, string) {
+	if s.symbolMapper != nil {
+		pair, contractType, err := s.symbolMapper.ToCurrencyPair(symbol, futuresSymbolContractTypes(symbol)...)
+		if err == nil {
+			return pair, contractType
+		}
+		logger.Warnf("[binance] map symbol %s error: %s", symbol, err.Error())
+	}
+
+	meta := strings.Split(symbol, "_")
+	pair := adaptSymbolToCurrencyPair(meta[0])
+	if len(meta) == 1 {
+		return pair, goex.SWAP_USDT_CONTRACT
+	}
+	if meta[1] == "PERP" {
+		return pair, goex.SWAP_CONTRACT
+	}
+	return pair, meta[1]
+}
+
+func (s *FuturesPrivateWs) handle(event string, data []byte) error {
+	var m = make(map[string]interface{}, 8)
+	err := json.Unmarshal(data, &m)
+	if err != nil {
+		return err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	switch event {
+	case "ORDER_TRADE_UPDATE":
+		o, _ := m["o"].(map[string]interface{})
+		if o == nil {
+			return nil
+		}
+		pair, _ := s.adaptSymbol(goex.ToString(o["s"]))
+		if s.orderPairs[pair.ToSymbol("_")] {
+			s.orderCallFn(s.adaptOrder(o))
+		}
+	case "ACCOUNT_UPDATE":
+		a, _ := m["a"].(map[string]interface{})
+		if a == nil {
+			return nil
+		}
+		s.handleAccountUpdate(a)
+	default:
+		logger.Debugf("[binance] ignore user data event: %s", string(data))
+	}
+
+	return nil
+}
+
+func (s *FuturesPrivateWs) handleAccountUpdate(a map[string]interface{}) {
+	positions, _ := a["P"].([]interface{})
+	for _, v := range positions {
+		p, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		pair, _ := s.adaptSymbol(goex.ToString(p["s"]))
+		if s.positionPairs[pair.ToSymbol("_")] {
+			s.positionCallFn(s.adaptPosition(p))
+		}
+	}
+
+	balances, _ := a["B"].([]interface{})
+	if len(balances) == 0 {
+		return
+	}
+
+	acc := &goex.FutureAccount{FutureSubAccounts: make(map[goex.Currency]goex.FutureSubAccount, 2)}
+	subscribed := false
+	for _, v := range balances {
+		vv, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		currency := goex.NewCurrency(goex.ToString(vv["a"]), "")
+		acc.FutureSubAccounts[currency] = goex.FutureSubAccount{
+			Currency:      currency,
+			AccountRights: goex.ToFloat64(vv["wb"]),
+		}
+		for pair := range s.accountPairs {
+			if strings.HasPrefix(pair, currency.Symbol+"_") || strings.HasSuffix(pair, "_"+currency.Symbol) {
+				subscribed = true
+			}
+		}
+	}
+
+	if subscribed {
+		s.accountCallFn(acc)
+	}
+}
+
+func (s *FuturesPrivateWs) adaptOrder(o map[string]interface{}) *goex.FutureOrder {
+	pair, _ := s.adaptSymbol(goex.ToString(o["s"]))
+	ord := &goex.FutureOrder{
+		ClientOid:    goex.ToString(o["c"]),
+		OrderID:      goex.ToInt64(o["i"]),
+		OrderID2:     fmt.Sprint(goex.ToInt64(o["i"])),
+		Fee:          goex.ToFloat64(o["n"]),
+		Profit:       goex.ToFloat64(o["rp"]),
+		OrderTime:    goex.ToInt64(o["T"]),
+		Currency:     pair,
+		ContractName: goex.ToString(o["s"]),
+		OType:        adaptFuturesOType(goex.ToString(o["S"]), goex.ToString(o["ps"])),
+		Status:       adaptOrderStatus(goex.ToString(o["X"])),
+		Decimals: goex.OrderDecimals{
+			Price:      goex.ToDecimal(o["p"]),
+			Amount:     goex.ToDecimal(o["q"]),
+			AvgPrice:   goex.ToDecimal(o["ap"]),
+			DealAmount: goex.ToDecimal(o["z"]),
+		},
+	}
+	ord.Price = ord.Decimals.Price.Float64()
+	ord.Amount = ord.Decimals.Amount.Float64()
+	ord.AvgPrice = ord.Decimals.AvgPrice.Float64()
+	ord.DealAmount = ord.Decimals.DealAmount.Float64()
+
+	if o["X"] == "EXPIRED" {
+		ord.Status = goex.ORDER_CANCEL
+	}
+
+	switch o["f"] {
+	case "IOC":
+		ord.OrderType = goex.ORDER_FEATURE_IOC
+	case "FOK":
+		ord.OrderType = goex.ORDER_FEATURE_FOK
+	case "GTX":
+		ord.OrderType = goex.ORDER_FEATURE_POST_ONLY
+	}
+
+	if o["ps"] == "BOTH" && o["R"] == true {
+		//单向持仓的只减仓单
+		if ord.OType == goex.OPEN_BUY {
+			ord.OType = goex.CLOSE_SELL
+		} else {
+			ord.OType = goex.CLOSE_BUY
+		}
+	}
+
+	if ord.Status == goex.ORDER_FINISH || ord.Status == goex.ORDER_CANCEL || ord.Status == goex.ORDER_REJECT {
+		ord.FinishedTime = goex.ToInt64(o["T"])
+	}
+
+	return ord
+}
+
+func (s *FuturesPrivateWs) adaptPosition(p map[string]interface{}) *goex.FuturePosition {
+	pair, contractType := s.adaptSymbol(goex.ToString(p["s"]))
+	pos := &goex.FuturePosition{
+		Symbol:       pair,
+		ContractType: contractType,
+	}
+
+	amount := goex.ToFloat64(p["pa"])
+	price := goex.ToFloat64(p["ep"])
+	upnl := goex.ToFloat64(p["up"])
+	realized := goex.ToFloat64(p["cr"])
+
+	if p["ps"] == "LONG" || (p["ps"] == "BOTH" && amount > 0) {
+		pos.BuyAmount = amount
+		pos.BuyAvailable = amount
+		pos.BuyPriceAvg = price
+		pos.BuyProfit = upnl
+		pos.BuyProfitReal = realized
+	} else {
+		pos.SellAmount = math.Abs(amount)
+		pos.SellAvailable = math.Abs(amount)
+		pos.SellPriceAvg = price
+		pos.SellProfit = upnl
+		pos.SellProfitReal = realized
+	}
+
+	return pos
+}
@@ -0,0 +1,72 @@
+package binance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mrwill84/goex"
+)
+
+func TestFuturesPrivateWs_handle(t *testing.T) {
+	ws := NewFuturesPrivateWs(&goex.APIConfig{})
+	ws.orderPairs["BTC_USDT"] = true
+
+	var ord *goex.FutureOrder
+	ws.OrderCallback(func(order *goex.FutureOrder) {
+		ord = order
+	})
+
+	err := ws.handle("ORDER_TRADE_UPDATE", []byte(`{"e":"ORDER_TRADE_UPDATE","E":1568879465651,"T":1568879465650,
+		"o":{"s":"BTCUSDT","c":"TEST","S":"SELL","o":"LIMIT","f":"GTC","q":"0.001","p":"9910","ap":"9910","X":"FILLED",
+		"i":8886774,"z":"0.001","n":"0.0396","T":1568879465651,"R":true,"ps":"BOTH","rp":"1.2"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ord == nil {
+		t.Fatal("order callback not called")
+	}
+	if ord.OrderID2 != "8886774" || ord.Status != goex.ORDER_FINISH || ord.OType != goex.CLOSE_BUY {
+		t.Fatalf("unexpected order: %+v", ord)
+	}
+	if ord.Currency.ToSymbol("_") != "BTC_USDT" || ord.DealAmount != 0.001 {
+		t.Fatalf("unexpected order: %+v", ord)
+	}
+}
+
+func TestFuturesPrivateWs_SymbolMapper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"symbols":[
+			{"symbol":"BTCUSD_PERP","pair":"BTCUSD","contractType":"PERPETUAL","contractStatus":"TRADING","baseAsset":"BTC","quoteAsset":"USD","contractSize":100},
+			{"symbol":"BTCUSD_211231","pair":"BTCUSD","contractType":"CURRENT_QUARTER","contractStatus":"TRADING","baseAsset":"BTC","quoteAsset":"USD","contractSize":100,
+				"filters":[{"filterType":"PRICE_FILTER","tickSize":"0.1"}]},
+			{"symbol":"BTCUSD_220325","pair":"BTCUSD","contractType":"NEXT_QUARTER","contractStatus":"TRADING","baseAsset":"BTC","quoteAsset":"USD","contractSize":100}]}`))
+	}))
+	defer server.Close()
+
+	futures := NewBinanceFutures(&goex.APIConfig{Endpoint: server.URL, HttpClient: http.DefaultClient})
+	mapper := goex.NewSymbolMapper(futures, time.Hour)
+
+	symbol, err := mapper.ToSymbol(goex.BTC_USD, goex.QUARTER_CONTRACT)
+	if err != nil || symbol != "BTCUSD_211231" {
+		t.Fatal(symbol, err)
+	}
+
+	ws := NewFuturesPrivateWs(&goex.APIConfig{})
+	ws.SetSymbolMapper(mapper)
+
+	tests := map[string]string{
+		"BTCUSD_PERP":   goex.SWAP_CONTRACT,
+		"BTCUSD_211231": goex.QUARTER_CONTRACT,
+		"BTCUSD_220325": goex.BI_QUARTER_CONTRACT,
+		"BTCUSDT":       goex.SWAP_USDT_CONTRACT, //不在mapper中，按symbol解析
+	}
+	for symbol, want := range tests {
+		pair, contractType := ws.adaptSymbol(symbol)
+		if pair.ToSymbol("_") != "BTC_USD" && pair.ToSymbol("_") != "BTC_USDT" || contractType != want {
+			t.Fatal(symbol, pair, contractType)
+		}
+	}
+}
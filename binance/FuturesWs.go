@@ -2,38 +2,55 @@ package binance
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"os"
-	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/orderbook"
+)
+
+//合约单个连接最多200个stream，每秒最多10条消息(包括pong)
+const (
+	futuresMaxStreams     = 200
+	futuresSubscribeLimit = 8
 )
 
 type FuturesWs struct {
-	base  *BinanceFutures
-	fOnce sync.Once
-	dOnce sync.Once
+	base *BinanceFutures
+
+	f *goex.WsPool //U本位
+	d *goex.WsPool //币本位
+
+	reqLock sync.Mutex
+	reqId   int //两个连接共用，订阅回应按id关联
+
+	booksLock sync.RWMutex
+	books     map[string]*orderbook.OrderBook
+	depthLock sync.Mutex //订单簿就绪回调在后台goroutine中，和两个连接的读循环的推送串行
 
-	wsBuilder *goex.WsBuilder
-	f         *goex.WsConn
-	d         *goex.WsConn
+	symbolMapper *goex.SymbolMapper
 
 	depthCallFn  func(depth *goex.Depth)
 	tickerCallFn func(ticker *goex.FutureTicker)
-	tradeCalFn   func(trade *goex.Trade)
+	tradeCalFn   func(trade *goex.Trade, contract string)
 }
 
+var (
+	_ goex.FuturesWsApi      = (*FuturesWs)(nil)
+	_ goex.SymbolMapperAware = (*FuturesWs)(nil)
+)
+
 func NewFuturesWs() *FuturesWs {
-	futuresWs := new(FuturesWs)
+	futuresWs := &FuturesWs{books: make(map[string]*orderbook.OrderBook, 2)}
 
-	futuresWs.wsBuilder = goex.NewWsBuilder().
-		ProtoHandleFunc(futuresWs.handle).AutoReconnect()
+	futuresWs.f = goex.NewWsPool(futuresWs.newWsBuilder("wss://fstream.binance.com/ws"), futuresMaxStreams)
+	futuresWs.d = goex.NewWsPool(futuresWs.newWsBuilder("wss://dstream.binance.com/ws"), futuresMaxStreams)
 
 	httpCli := &http.Client{
 		Timeout: 10 * time.Second,
@@ -57,16 +74,19 @@ func NewFuturesWs() *FuturesWs {
 	return futuresWs
 }
 
-func (s *FuturesWs) connectUsdtFutures() {
-	s.fOnce.Do(func() {
-		s.f = s.wsBuilder.WsUrl("wss://fstream.binance.com/ws").Build()
-	})
+func (s *FuturesWs) newWsBuilder(wsUrl string) *goex.WsBuilder {
+	return goex.NewWsBuilder().WsUrl(wsUrl).
+		ProtoHandleFunc(s.handle).AutoReconnect().
+		SubscribeRateLimit(futuresSubscribeLimit, time.Second)
 }
 
-func (s *FuturesWs) connectFutures() {
-	s.dOnce.Do(func() {
-		s.d = s.wsBuilder.WsUrl("wss://dstream.binance.com/ws").Build()
-	})
+//设置后使用交易规则把推送的symbol映射回交易对，mapper应包含BinanceSwap和BinanceFutures的交易规则
+func (s *FuturesWs) SetSymbolMapper(mapper *goex.SymbolMapper) {
+	s.symbolMapper = mapper
+}
+
+func (s *FuturesWs) symbolPair(symbol string) goex.CurrencyPair {
+	return s.symbolMapper.PairOf(symbol, adaptSymbolToCurrencyPair, futuresSymbolContractTypes(symbol)...)
 }
 
 func (s *FuturesWs) DepthCallback(f func(depth *goex.Depth)) {
@@ -77,62 +97,118 @@ func (s *FuturesWs) TickerCallback(f func(ticker *goex.FutureTicker)) {
 	s.tickerCallFn = f
 }
 
-func (s *FuturesWs) TradeCallback(f func(trade *goex.Trade)) {
+func (s *FuturesWs) TradeCallback(f func(trade *goex.Trade, contract string)) {
 	s.tradeCalFn = f
 }
 
+func (s *FuturesWs) nextReqId() int {
+	s.reqLock.Lock()
+	defer s.reqLock.Unlock()
+	s.reqId++
+	return s.reqId
+}
+
+//stream同时作为订阅的Key
+func (s *FuturesWs) subscribe(c *goex.WsPool, stream string) error {
+	id := s.nextReqId()
+	return c.SubscribeWithId(stream, fmt.Sprint(id), req{
+		Method: "SUBSCRIBE",
+		Params: []string{stream},
+		Id:     id,
+	})
+}
+
+func (s *FuturesWs) unsubscribe(c *goex.WsPool, stream string) error {
+	return c.Unsubscribe(stream, req{
+		Method: "UNSUBSCRIBE",
+		Params: []string{stream},
+		Id:     s.nextReqId(),
+	})
+}
+
+//U本位永续合约的symbol
+func (s *FuturesWs) usdtSymbol(pair goex.CurrencyPair) string {
+	return pair.AdaptUsdToUsdt().ToSymbol("")
+}
+
+//币本位合约的symbol
+func (s *FuturesWs) coinSymbol(pair goex.CurrencyPair, contractType string) string {
+	sym, _ := s.base.adaptToSymbol(pair.AdaptUsdtToUsd(), contractType)
+	return sym
+}
+
 func (s *FuturesWs) SubscribeDepth(pair goex.CurrencyPair, contractType string) error {
 	switch contractType {
 	case goex.SWAP_USDT_CONTRACT:
-		s.connectUsdtFutures()
-		return s.f.Subscribe(req{
-			Method: "SUBSCRIBE",
-			Params: []string{pair.AdaptUsdToUsdt().ToLower().ToSymbol("") + "@depth10@100ms"},
-			Id:     1,
-		})
+		sym := s.usdtSymbol(pair)
+		s.addOrderBook(baseUrl+"/fapi/v1/", sym)
+		return s.subscribe(s.f, strings.ToLower(sym)+"@depth@100ms")
 	default:
-		s.connectFutures()
-		sym, _ := s.base.adaptToSymbol(pair.AdaptUsdtToUsd(), contractType)
-		return s.d.Subscribe(req{
-			Method: "SUBSCRIBE",
-			Params: []string{strings.ToLower(sym) + "@depth20@100ms"},
-			Id:     2,
-		})
+		sym := s.coinSymbol(pair, contractType)
+		s.addOrderBook(s.base.base.apiV1, sym)
+		return s.subscribe(s.d, strings.ToLower(sym)+"@depth@100ms")
 	}
-	return errors.New("contract is error")
+}
+
+func (s *FuturesWs) addOrderBook(apiBase, symbol string) {
+	s.booksLock.Lock()
+	defer s.booksLock.Unlock()
+	if _, ok := s.books[symbol]; ok {
+		return
+	}
+	ob := orderbook.NewOrderBook(func() (*orderbook.Snapshot, error) {
+		return s.base.base.getDepthSnapshot(apiBase, symbol, 1000)
+	})
+	ob.ReadyCallback(func() {
+		s.emitDepth(ob, symbol, time.Now().UnixNano()/int64(time.Millisecond))
+	})
+	s.books[symbol] = ob
 }
 
 func (s *FuturesWs) SubscribeTicker(pair goex.CurrencyPair, contractType string) error {
 	switch contractType {
 	case goex.SWAP_USDT_CONTRACT:
-		s.connectUsdtFutures()
-		return s.f.Subscribe(req{
-			Method: "SUBSCRIBE",
-			Params: []string{pair.AdaptUsdToUsdt().ToLower().ToSymbol("") + "@miniTicker"},
-			Id:     1,
-		})
+		return s.subscribe(s.f, strings.ToLower(s.usdtSymbol(pair))+"@miniTicker")
 	default:
-		s.connectFutures()
-		sym, _ := s.base.adaptToSymbol(pair.AdaptUsdtToUsd(), contractType)
-		return s.d.Subscribe(req{
-			Method: "SUBSCRIBE",
-			Params: []string{strings.ToLower(sym) + "@ticker"},
-			Id:     2,
-		})
+		return s.subscribe(s.d, strings.ToLower(s.coinSymbol(pair, contractType))+"@ticker")
 	}
-	return errors.New("contract is error")
 }
 
 func (s *FuturesWs) SubscribeTrade(pair goex.CurrencyPair, contractType string) error {
 	///panic("implement me")
 	switch contractType {
 	case goex.SWAP_USDT_CONTRACT:
-		s.connectUsdtFutures()
-		return s.f.Subscribe(req{
-			Method: "SUBSCRIBE",
-			Params: []string{pair.AdaptUsdToUsdt().ToLower().ToSymbol("") + "@aggTrade"},
-			Id:     1,
-		})
+		return s.subscribe(s.f, strings.ToLower(s.usdtSymbol(pair))+"@aggTrade")
+	}
+	return nil
+}
+
+func (s *FuturesWs) UnsubscribeDepth(pair goex.CurrencyPair, contractType string) error {
+	c, sym := s.d, s.coinSymbol(pair, contractType)
+	if contractType == goex.SWAP_USDT_CONTRACT {
+		c, sym = s.f, s.usdtSymbol(pair)
+	}
+
+	s.booksLock.Lock()
+	if ob, ok := s.books[sym]; ok {
+		ob.Close()
+		delete(s.books, sym)
+	}
+	s.booksLock.Unlock()
+
+	return s.unsubscribe(c, strings.ToLower(sym)+"@depth@100ms")
+}
+
+func (s *FuturesWs) UnsubscribeTicker(pair goex.CurrencyPair, contractType string) error {
+	if contractType == goex.SWAP_USDT_CONTRACT {
+		return s.unsubscribe(s.f, strings.ToLower(s.usdtSymbol(pair))+"@miniTicker")
+	}
+	return s.unsubscribe(s.d, strings.ToLower(s.coinSymbol(pair, contractType))+"@ticker")
+}
+
+func (s *FuturesWs) UnsubscribeTrade(pair goex.CurrencyPair, contractType string) error {
+	if contractType == goex.SWAP_USDT_CONTRACT {
+		return s.unsubscribe(s.f, strings.ToLower(s.usdtSymbol(pair))+"@aggTrade")
 	}
 	return nil
 }
@@ -144,32 +220,22 @@ func (s *FuturesWs) handle(data []byte) error {
 		return err
 	}
 
-	if e, ok := m["e"].(string); ok && e == "depthUpdate" {
-		dep := s.depthHandle(m["b"].([]interface{}), m["a"].([]interface{}))
-		dep.ContractType = "SWAP" // m["s"].(string)
-
-		symbol, ok := m["s"].(string)
-
-		if ok {
-			dep.Pair = adaptSymbolToCurrencyPair(symbol)
-		} else {
-			dep.Pair = adaptSymbolToCurrencyPair(dep.ContractType) //usdt swap
-		}
-		dep.ContractId = symbol[:len(symbol)-4] + "-USDT-SWAP"
-		dep.Timestamp = goex.ToInt64(m["T"])
-		dep.Exchange = "BINANCE"
-		s.depthCallFn(dep)
-
+	if _, ok := m["id"]; ok && ackSubscription(data, s.f, s.d) {
 		return nil
 	}
 
+	if e, ok := m["e"].(string); ok && e == "depthUpdate" {
+		return s.depthHandle(data, goex.ToInt64(m["T"]))
+	}
+
 	if e, ok := m["e"].(string); ok && e == "24hrMiniTicker" {
 		s.tickerCallFn(s.tickerHandle(m))
 		return nil
 	}
 	//fmt.Println("m", m)
 	if e, ok := m["e"].(string); ok && e == "aggTrade" {
-		s.tradeCalFn(s.aggTradeHandle(m))
+		trade := s.aggTradeHandle(m)
+		s.tradeCalFn(trade, trade.ContractType)
 		return nil
 	}
 
@@ -178,42 +244,66 @@ func (s *FuturesWs) handle(data []byte) error {
 	return nil
 }
 
-func (s *FuturesWs) depthHandle(bids []interface{}, asks []interface{}) *goex.Depth {
-	var dep goex.Depth
+//增量深度更新本地订单簿，订单簿就绪后推送
+func (s *FuturesWs) depthHandle(data []byte, ts int64) error {
+	var depthR depthUpdateResp
+	err := json.Unmarshal(data, &depthR)
+	if err != nil {
+		return err
+	}
 
-	for _, item := range bids {
-		bid := item.([]interface{})
-		dep.BidList = append(dep.BidList,
-			goex.DepthRecord{
-				Price:  goex.ToFloat64(bid[0]),
-				Amount: goex.ToFloat64(bid[1]),
-			})
+	s.booksLock.RLock()
+	ob := s.books[depthR.Symbol]
+	s.booksLock.RUnlock()
+	if ob == nil {
+		return nil
 	}
 
-	for _, item := range asks {
-		ask := item.([]interface{})
-		dep.AskList = append(dep.AskList, goex.DepthRecord{
-			Price:  goex.ToFloat64(ask[0]),
-			Amount: goex.ToFloat64(ask[1]),
-		})
+	err = ob.Apply(&orderbook.Diff{
+		Asks:    adaptDepthLevels(depthR.Asks),
+		Bids:    adaptDepthLevels(depthR.Bids),
+		FirstId: depthR.FirstUpdateId,
+		LastId:  depthR.LastUpdateId,
+		PrevId:  depthR.PrevUpdateId,
+	})
+	if err != nil || !ob.IsReady() {
+		return err
 	}
 
-	sort.Sort(sort.Reverse(dep.AskList))
+	s.emitDepth(ob, depthR.Symbol, ts)
 
-	return &dep
+	return nil
 }
 
-func (s *FuturesWs) aggTradeHandle(m map[string]interface{}) *goex.Trade {
-	var trade goex.Trade
+//同SpotWs.emitDepth，在锁内读取深度并回调
+func (s *FuturesWs) emitDepth(ob *orderbook.OrderBook, symbol string, ts int64) {
+	s.depthLock.Lock()
+	defer s.depthLock.Unlock()
 
-	symbol, ok := m["s"].(string)
+	s.depthCallFn(s.adaptDepth(ob, symbol, ts))
+}
 
-	if ok {
-		trade.Pair = adaptSymbolToCurrencyPair(symbol)
-	} else {
-		trade.Pair = adaptSymbolToCurrencyPair(m["s"].(string)) //usdt swap
+func (s *FuturesWs) adaptDepth(ob *orderbook.OrderBook, symbol string, ts int64) *goex.Depth {
+	size := depthSize
+	if strings.Contains(symbol, "_") {
+		size = 20 //币本位合约
 	}
 
+	dep := ob.Depth(size)
+	dep.ContractType = "SWAP"
+	dep.Pair = s.symbolPair(symbol)
+	dep.ContractId = symbol[:len(symbol)-4] + "-USDT-SWAP"
+	dep.Timestamp = ts
+	dep.Exchange = "BINANCE"
+
+	return dep
+}
+
+func (s *FuturesWs) aggTradeHandle(m map[string]interface{}) *goex.Trade {
+	var trade goex.Trade
+
+	trade.Pair = s.symbolPair(goex.ToString(m["s"]))
+
 	trade.ContractType = "SWAP"
 	sym := m["s"].(string)
 	trade.Date = goex.ToInt64(m["T"])
@@ -235,12 +325,7 @@ func (s *FuturesWs) tickerHandle(m map[string]interface{}) *goex.FutureTicker {
 	var ticker goex.FutureTicker
 	ticker.Ticker = new(goex.Ticker)
 
-	symbol, ok := m["s"].(string)
-	if ok {
-		ticker.Pair = adaptSymbolToCurrencyPair(symbol)
-	} else {
-		ticker.Pair = adaptSymbolToCurrencyPair(m["s"].(string)) //usdt swap
-	}
+	ticker.Pair = s.symbolPair(goex.ToString(m["s"]))
 
 	ticker.ContractType = "SWAP"
 	sym := m["s"].(string)
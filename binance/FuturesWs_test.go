@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var futuresWs *FuturesWs
@@ -23,13 +24,14 @@ func createFuturesWs() {
 		fmt.Println(ticker.Ticker, ticker.ContractType)
 	})
 
-	futuresWs.TradeCallback(func(trade *goex.Trade) {
+	futuresWs.TradeCallback(func(trade *goex.Trade, contract string) {
 		fmt.Println("slots", trade.Slots)
 	})
 
 }
 
 func TestFuturesWs_DepthCallback(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	createFuturesWs()
 
 	//futuresWs.SubscribeDepth(goex.LTC_USDT, goex.SWAP_USDT_CONTRACT)
@@ -40,6 +42,7 @@ func TestFuturesWs_DepthCallback(t *testing.T) {
 }
 
 func TestFuturesWs_SubscribeTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	createFuturesWs()
 
 	//futuresWs.SubscribeTicker(goex.BTC_USDT, goex.SWAP_USDT_CONTRACT)
@@ -50,6 +53,7 @@ func TestFuturesWs_SubscribeTicker(t *testing.T) {
 }
 
 func TestFuturesWs_TradeCallback(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	createFuturesWs()
 
 	//futuresWs.SubscribeDepth(goex.LTC_USDT, goex.SWAP_USDT_CONTRACT)
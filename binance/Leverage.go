@@ -0,0 +1,173 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	. "github.com/mrwill84/goex"
+)
+
+var (
+	_ FutureLeverageAPI = (*BinanceSwap)(nil)
+	_ FutureLeverageAPI = (*BinanceFutures)(nil)
+)
+
+const (
+	errCodeNoNeedChangeMarginType   = "-4046"
+	errCodeNoNeedChangePositionSide = "-4059"
+)
+
+//签名的POST请求，重复设置相同的模式时交易所返回的错误码视为成功
+func doSignedPost(bn *Binance, apiKey, apiPath string, params url.Values, ignoreCode string) error {
+	bn.buildParamsSigned(&params)
+	_, err := HttpPostForm2(bn.httpClient, bn.apiV1+apiPath, params, map[string]string{"X-MBX-APIKEY": apiKey})
+	if err == nil {
+		return nil
+	}
+
+	err = adaptError(err)
+	var apiErr ApiError
+	if ignoreCode != "" && errors.As(err, &apiErr) && apiErr.OriginErrCode == ignoreCode {
+		return nil
+	}
+	return err
+}
+
+func setLeverage(bn *Binance, apiKey, symbol string, lever int) error {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("leverage", fmt.Sprint(lever))
+	return doSignedPost(bn, apiKey, "leverage", params, "")
+}
+
+func getLeverage(bn *Binance, apiKey, symbol string) (float64, error) {
+	params := url.Values{}
+	bn.buildParamsSigned(&params)
+
+	var positions []PositionRiskResponse
+	err := HttpGet4(bn.httpClient, bn.apiV1+"positionRisk?"+params.Encode(), map[string]string{"X-MBX-APIKEY": apiKey}, &positions)
+	if err != nil {
+		return 0, adaptError(err)
+	}
+
+	for _, p := range positions {
+		if p.Symbol == symbol {
+			return p.Leverage, nil
+		}
+	}
+	return 0, EX_ERR_SYMBOL_ERR
+}
+
+func setMarginType(bn *Binance, apiKey, symbol string, mode MarginMode) error {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	switch mode {
+	case CROSS_MARGIN:
+		params.Set("marginType", "CROSSED")
+	case ISOLATED_MARGIN:
+		params.Set("marginType", "ISOLATED")
+	default:
+		return fmt.Errorf("unknown margin mode %s", mode)
+	}
+	return doSignedPost(bn, apiKey, "marginType", params, errCodeNoNeedChangeMarginType)
+}
+
+func setDualSidePosition(bn *Binance, apiKey string, hedge bool) error {
+	params := url.Values{}
+	params.Set("dualSidePosition", fmt.Sprint(hedge))
+	return doSignedPost(bn, apiKey, "positionSide/dual", params, errCodeNoNeedChangePositionSide)
+}
+
+//type: 1 增加 , 2 减少
+func modifyPositionMargin(bn *Binance, apiKey, symbol string, hedge bool, positionSide int, amount float64) error {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("positionSide", adaptPositionSide(hedge, positionSide))
+	params.Set("type", "1")
+	if amount < 0 {
+		params.Set("type", "2")
+		amount = -amount
+	}
+	params.Set("amount", FloatToString(amount, 8))
+	return doSignedPost(bn, apiKey, "positionMargin", params, "")
+}
+
+//单向持仓为BOTH，双向持仓时开多/平多为LONG，开空/平空为SHORT
+func adaptPositionSide(hedge bool, openType int) string {
+	if !hedge {
+		return "BOTH"
+	}
+	switch openType {
+	case OPEN_SELL, CLOSE_SELL:
+		return "SHORT"
+	default:
+		return "LONG"
+	}
+}
+
+func (bs *BinanceSwap) GetLeverage(currencyPair CurrencyPair, contractType string) (float64, error) {
+	return getLeverage(&bs.Binance, bs.accessKey, bs.adaptCurrencyPair(currencyPair).ToSymbol(""))
+}
+
+func (bs *BinanceSwap) SetLeverage(currencyPair CurrencyPair, contractType string, lever int) error {
+	return setLeverage(&bs.Binance, bs.accessKey, bs.adaptCurrencyPair(currencyPair).ToSymbol(""), lever)
+}
+
+func (bs *BinanceSwap) SetMarginMode(currencyPair CurrencyPair, contractType string, mode MarginMode) error {
+	return setMarginType(&bs.Binance, bs.accessKey, bs.adaptCurrencyPair(currencyPair).ToSymbol(""), mode)
+}
+
+//双向持仓模式下单时会带上positionSide
+func (bs *BinanceSwap) SetPositionMode(hedge bool) error {
+	if err := setDualSidePosition(&bs.Binance, bs.accessKey, hedge); err != nil {
+		return err
+	}
+	bs.hedgeMode = hedge
+	return nil
+}
+
+func (bs *BinanceSwap) ModifyIsolatedMargin(currencyPair CurrencyPair, contractType string, positionSide int, amount float64) error {
+	return modifyPositionMargin(&bs.Binance, bs.accessKey, bs.adaptCurrencyPair(currencyPair).ToSymbol(""), bs.hedgeMode, positionSide, amount)
+}
+
+func (bs *BinanceFutures) GetLeverage(currencyPair CurrencyPair, contractType string) (float64, error) {
+	symbol, err := bs.adaptToSymbol(currencyPair, contractType)
+	if err != nil {
+		return 0, err
+	}
+	return getLeverage(bs.base, bs.apikey, symbol)
+}
+
+func (bs *BinanceFutures) SetLeverage(currencyPair CurrencyPair, contractType string, lever int) error {
+	symbol, err := bs.adaptToSymbol(currencyPair, contractType)
+	if err != nil {
+		return err
+	}
+	return setLeverage(bs.base, bs.apikey, symbol, lever)
+}
+
+func (bs *BinanceFutures) SetMarginMode(currencyPair CurrencyPair, contractType string, mode MarginMode) error {
+	symbol, err := bs.adaptToSymbol(currencyPair, contractType)
+	if err != nil {
+		return err
+	}
+	return setMarginType(bs.base, bs.apikey, symbol, mode)
+}
+
+//双向持仓模式下单时会带上positionSide
+func (bs *BinanceFutures) SetPositionMode(hedge bool) error {
+	if err := setDualSidePosition(bs.base, bs.apikey, hedge); err != nil {
+		return err
+	}
+	bs.hedgeMode = hedge
+	return nil
+}
+
+func (bs *BinanceFutures) ModifyIsolatedMargin(currencyPair CurrencyPair, contractType string, positionSide int, amount float64) error {
+	symbol, err := bs.adaptToSymbol(currencyPair, contractType)
+	if err != nil {
+		return err
+	}
+	return modifyPositionMargin(bs.base, bs.apikey, symbol, bs.hedgeMode, positionSide, amount)
+}
@@ -0,0 +1,68 @@
+package binance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mrwill84/goex"
+)
+
+func TestBinanceSwap_Leverage(t *testing.T) {
+	forms := map[string]url.Values{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/fapi/v1/marginType":
+			forms[r.URL.Path] = r.PostForm
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-4046,"msg":"No need to change margin type."}`))
+		case "/fapi/v1/positionSide/dual", "/fapi/v1/leverage", "/fapi/v1/order":
+			forms[r.URL.Path] = r.PostForm
+			json.NewEncoder(w).Encode(map[string]interface{}{"code": 200, "msg": "success", "orderId": 1001})
+		case "/fapi/v1/positionRisk":
+			w.Write([]byte(`[{"symbol":"ETHUSDT","leverage":"5"},{"symbol":"BTCUSDT","leverage":"20","positionSide":"BOTH"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	swap := NewBinanceSwap(&goex.APIConfig{Endpoint: server.URL, HttpClient: http.DefaultClient})
+
+	if err := swap.SetMarginMode(goex.BTC_USDT, goex.SWAP_USDT_CONTRACT, goex.ISOLATED_MARGIN); err != nil {
+		t.Fatal(err)
+	}
+	if f := forms["/fapi/v1/marginType"]; f.Get("symbol") != "BTCUSDT" || f.Get("marginType") != "ISOLATED" {
+		t.Fatal(f)
+	}
+
+	if err := swap.SetLeverage(goex.BTC_USDT, goex.SWAP_USDT_CONTRACT, 20); err != nil {
+		t.Fatal(err)
+	}
+	if f := forms["/fapi/v1/leverage"]; f.Get("leverage") != "20" {
+		t.Fatal(f)
+	}
+
+	lever, err := swap.GetLeverage(goex.BTC_USDT, goex.SWAP_USDT_CONTRACT)
+	if err != nil || lever != 20 {
+		t.Fatal(lever, err)
+	}
+
+	if err := swap.SetPositionMode(true); err != nil {
+		t.Fatal(err)
+	}
+	if f := forms["/fapi/v1/positionSide/dual"]; f.Get("dualSidePosition") != "true" {
+		t.Fatal(f)
+	}
+
+	//双向持仓模式下单带positionSide
+	if _, err := swap.LimitFuturesOrder(goex.BTC_USDT, goex.SWAP_USDT_CONTRACT, "100", "1", goex.CLOSE_SELL); err != nil {
+		t.Fatal(err)
+	}
+	if f := forms["/fapi/v1/order"]; f.Get("side") != "BUY" || f.Get("positionSide") != "SHORT" {
+		t.Fatal(f)
+	}
+}
@@ -0,0 +1,216 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	. "github.com/mrwill84/goex"
+)
+
+var (
+	_ PerpetualDataAPI = (*BinanceSwap)(nil)
+	_ PerpetualDataAPI = (*BinanceFutures)(nil)
+)
+
+type premiumIndexResponse struct {
+	Symbol          string  `json:"symbol"`
+	MarkPrice       float64 `json:"markPrice,string"`
+	IndexPrice      float64 `json:"indexPrice,string"`
+	LastFundingRate string  `json:"lastFundingRate"` //交割合约为空
+	NextFundingTime int64   `json:"nextFundingTime"`
+	Time            int64   `json:"time"`
+}
+
+//fapi返回对象，dapi返回数组
+func getPremiumIndex(client *http.Client, apiV1, symbol string) (*premiumIndexResponse, error) {
+	resp, err := HttpGet5(client, apiV1+"premiumIndex?symbol="+symbol, nil)
+	if err != nil {
+		return nil, adaptError(err)
+	}
+
+	if len(resp) > 0 && resp[0] == '[' {
+		var indexes []premiumIndexResponse
+		if err = json.Unmarshal(resp, &indexes); err != nil {
+			return nil, err
+		}
+		if len(indexes) == 0 {
+			return nil, EX_ERR_SYMBOL_ERR
+		}
+		return &indexes[0], nil
+	}
+
+	var index premiumIndexResponse
+	if err = json.Unmarshal(resp, &index); err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+//lastFundingRate是当前周期(下次结算时收取)的资金费率
+func getFundingRate(client *http.Client, apiV1, symbol string) (*FundingRate, error) {
+	index, err := getPremiumIndex(client, apiV1, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &FundingRate{
+		Rate:        ToFloat64(index.LastFundingRate),
+		FundingTime: index.NextFundingTime,
+	}, nil
+}
+
+func getFundingRateHistory(client *http.Client, apiV1, symbol string, start, end int64, size int) ([]FundingRate, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	if start > 0 {
+		params.Set("startTime", fmt.Sprint(start))
+	}
+	if end > 0 {
+		params.Set("endTime", fmt.Sprint(end))
+	}
+	if size > 0 {
+		params.Set("limit", fmt.Sprint(size))
+	}
+
+	var resp []struct {
+		FundingRate float64 `json:"fundingRate,string"`
+		FundingTime int64   `json:"fundingTime"`
+	}
+	err := HttpGet4(client, apiV1+"fundingRate?"+params.Encode(), nil, &resp)
+	if err != nil {
+		return nil, adaptError(err)
+	}
+
+	//binance按时间升序返回
+	rates := make([]FundingRate, 0, len(resp))
+	for i := len(resp) - 1; i >= 0; i-- {
+		rates = append(rates, FundingRate{Rate: resp[i].FundingRate, FundingTime: resp[i].FundingTime})
+	}
+	return rates, nil
+}
+
+func getMarkPrice(client *http.Client, apiV1, symbol string) (*MarkPrice, error) {
+	index, err := getPremiumIndex(client, apiV1, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return &MarkPrice{
+		MarkPrice:  index.MarkPrice,
+		IndexPrice: index.IndexPrice,
+		Timestamp:  index.Time,
+	}, nil
+}
+
+func getOpenInterest(client *http.Client, apiV1, symbol string) (*OpenInterest, error) {
+	var resp struct {
+		OpenInterest float64 `json:"openInterest,string"`
+		Time         int64   `json:"time"`
+	}
+	err := HttpGet4(client, apiV1+"openInterest?symbol="+symbol, nil, &resp)
+	if err != nil {
+		return nil, adaptError(err)
+	}
+	return &OpenInterest{Amount: resp.OpenInterest, Timestamp: resp.Time}, nil
+}
+
+func (bs *BinanceSwap) GetFundingRate(currencyPair CurrencyPair, contractType string) (*FundingRate, error) {
+	rate, err := getFundingRate(bs.httpClient, bs.apiV1, bs.adaptCurrencyPair(currencyPair).ToSymbol(""))
+	if err != nil {
+		return nil, err
+	}
+	rate.Pair, rate.ContractType = currencyPair, contractType
+	return rate, nil
+}
+
+func (bs *BinanceSwap) GetFundingRateHistory(currencyPair CurrencyPair, contractType string, start, end int64, size int) ([]FundingRate, error) {
+	rates, err := getFundingRateHistory(bs.httpClient, bs.apiV1, bs.adaptCurrencyPair(currencyPair).ToSymbol(""), start, end, size)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rates {
+		rates[i].Pair, rates[i].ContractType = currencyPair, contractType
+	}
+	return rates, nil
+}
+
+func (bs *BinanceSwap) GetMarkPrice(currencyPair CurrencyPair, contractType string) (*MarkPrice, error) {
+	price, err := getMarkPrice(bs.httpClient, bs.apiV1, bs.adaptCurrencyPair(currencyPair).ToSymbol(""))
+	if err != nil {
+		return nil, err
+	}
+	price.Pair, price.ContractType = currencyPair, contractType
+	return price, nil
+}
+
+//U本位合约的持仓量单位为币
+func (bs *BinanceSwap) GetOpenInterest(currencyPair CurrencyPair, contractType string) (*OpenInterest, error) {
+	oi, err := getOpenInterest(bs.httpClient, bs.apiV1, bs.adaptCurrencyPair(currencyPair).ToSymbol(""))
+	if err != nil {
+		return nil, err
+	}
+	oi.Pair, oi.ContractType = currencyPair, contractType
+	oi.Value = oi.Amount
+	return oi, nil
+}
+
+//币本位合约，资金费率只适用于永续合约(SWAP_CONTRACT)，标记价格和持仓量也支持交割合约
+func (bs *BinanceFutures) GetFundingRate(currencyPair CurrencyPair, contractType string) (*FundingRate, error) {
+	symbol, err := bs.adaptToSymbol(currencyPair, contractType)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := getFundingRate(bs.base.httpClient, bs.base.apiV1, symbol)
+	if err != nil {
+		return nil, err
+	}
+	rate.Pair, rate.ContractType = currencyPair, contractType
+	return rate, nil
+}
+
+func (bs *BinanceFutures) GetFundingRateHistory(currencyPair CurrencyPair, contractType string, start, end int64, size int) ([]FundingRate, error) {
+	symbol, err := bs.adaptToSymbol(currencyPair, contractType)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := getFundingRateHistory(bs.base.httpClient, bs.base.apiV1, symbol, start, end, size)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rates {
+		rates[i].Pair, rates[i].ContractType = currencyPair, contractType
+	}
+	return rates, nil
+}
+
+func (bs *BinanceFutures) GetMarkPrice(currencyPair CurrencyPair, contractType string) (*MarkPrice, error) {
+	symbol, err := bs.adaptToSymbol(currencyPair, contractType)
+	if err != nil {
+		return nil, err
+	}
+	price, err := getMarkPrice(bs.base.httpClient, bs.base.apiV1, symbol)
+	if err != nil {
+		return nil, err
+	}
+	price.Pair, price.ContractType = currencyPair, contractType
+	return price, nil
+}
+
+//币本位合约的持仓量单位为张，Value按标记价格折算为币
+func (bs *BinanceFutures) GetOpenInterest(currencyPair CurrencyPair, contractType string) (*OpenInterest, error) {
+	symbol, err := bs.adaptToSymbol(currencyPair, contractType)
+	if err != nil {
+		return nil, err
+	}
+	oi, err := getOpenInterest(bs.base.httpClient, bs.base.apiV1, symbol)
+	if err != nil {
+		return nil, err
+	}
+	oi.Pair, oi.ContractType = currencyPair, contractType
+
+	contractVal, _ := bs.GetContractValue(currencyPair)
+	if price, err := getMarkPrice(bs.base.httpClient, bs.base.apiV1, symbol); err == nil && price.MarkPrice > 0 {
+		oi.Value = oi.Amount * contractVal / price.MarkPrice
+	}
+	return oi, nil
+}
@@ -0,0 +1,84 @@
+package binance
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/mrwill84/goex"
+)
+
+const usedWeightHeader = "X-MBX-USED-WEIGHT-1M"
+
+const (
+	spotHost     = "api.binance.com"
+	usdtSwapHost = "fapi.binance.com"
+	coinSwapHost = "dapi.binance.com"
+)
+
+//binance按ip的权重限频，现货/U本位/币本位分别计算，已用权重以响应头为准
+//规则按Host匹配，使用其它Endpoint(如binance.us)时需要复制规则并修改Host
+var RateLimitRules = []RateLimitRule{
+	{Host: spotHost, PathPrefix: "/api/", Limit: 6000, Interval: time.Minute, Weight: spotRequestWeight, UsedWeightHeader: usedWeightHeader},
+	{Host: spotHost, Path: "/api/v3/order", Method: http.MethodPost, Limit: 50, Interval: 10 * time.Second},
+	{Host: usdtSwapHost, PathPrefix: "/fapi/", Limit: 2400, Interval: time.Minute, Weight: futuresRequestWeight, UsedWeightHeader: usedWeightHeader},
+	{Host: usdtSwapHost, Path: "/fapi/v1/order", Method: http.MethodPost, Limit: 300, Interval: 10 * time.Second},
+	{Host: coinSwapHost, PathPrefix: "/dapi/", Limit: 2400, Interval: time.Minute, Weight: futuresRequestWeight, UsedWeightHeader: usedWeightHeader},
+	{Host: coinSwapHost, Path: "/dapi/v1/order", Method: http.MethodPost, Limit: 300, Interval: 10 * time.Second},
+}
+
+//常用接口的权重 , https://binance-docs.github.io/apidocs/spot/en/#limits
+func spotRequestWeight(req *http.Request) int {
+	query := req.URL.Query()
+	switch path := req.URL.Path; {
+	case strings.HasSuffix(path, "/depth"):
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		switch {
+		case limit <= 100:
+			return 5
+		case limit <= 500:
+			return 25
+		case limit <= 1000:
+			return 50
+		default:
+			return 250
+		}
+	case strings.HasSuffix(path, "/exchangeInfo"), strings.HasSuffix(path, "/account"), strings.HasSuffix(path, "/allOrders"):
+		return 20
+	case strings.HasSuffix(path, "/openOrders"), strings.HasSuffix(path, "/ticker/24hr"):
+		if query.Get("symbol") == "" {
+			return 80
+		}
+		return 6
+	case strings.HasSuffix(path, "/order") && req.Method == http.MethodGet:
+		return 4
+	}
+	return 2
+}
+
+func futuresRequestWeight(req *http.Request) int {
+	query := req.URL.Query()
+	switch path := req.URL.Path; {
+	case strings.HasSuffix(path, "/depth"):
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		switch {
+		case limit <= 50:
+			return 2
+		case limit <= 100:
+			return 5
+		case limit <= 500:
+			return 10
+		default:
+			return 20
+		}
+	case strings.HasSuffix(path, "/account"), strings.HasSuffix(path, "/allOrders"), strings.HasSuffix(path, "/positionRisk"):
+		return 5
+	case strings.HasSuffix(path, "/openOrders"), strings.HasSuffix(path, "/ticker/24hr"):
+		if query.Get("symbol") == "" {
+			return 40
+		}
+		return 1
+	}
+	return 1
+}
@@ -0,0 +1,196 @@
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/logger"
+)
+
+//现货私有数据推送(订单/账户)，基于用户数据流(listenKey)
+type SpotPrivateWs struct {
+	base   *Binance
+	stream *userDataStream
+
+	lock             sync.RWMutex
+	orderPairs       map[string]bool
+	subscribeAccount bool
+
+	symbolMapper *goex.SymbolMapper
+
+	orderCallFn   func(order *goex.Order)
+	accountCallFn func(account *goex.Account)
+}
+
+var (
+	_ goex.SpotPrivateWsApi  = (*SpotPrivateWs)(nil)
+	_ goex.SymbolMapperAware = (*SpotPrivateWs)(nil)
+)
+
+func NewSpotPrivateWs(config *goex.APIConfig) *SpotPrivateWs {
+	if config.Endpoint == "" {
+		config.Endpoint = GLOBAL_API_BASE_URL
+	}
+
+	s := &SpotPrivateWs{
+		base: &Binance{
+			baseUrl:    config.Endpoint,
+			apiV3:      config.Endpoint + "/api/v3/",
+			accessKey:  config.ApiKey,
+			secretKey:  config.ApiSecretKey,
+			httpClient: config.HttpClient,
+		},
+		orderPairs: make(map[string]bool, 2),
+	}
+	s.stream = newUserDataStream(s.base, s.base.apiV3+"userDataStream", "wss://stream.binance.com:9443/ws/", s.handle)
+
+	return s
+}
+
+//设置后使用交易规则把推送的symbol映射回交易对
+func (s *SpotPrivateWs) SetSymbolMapper(mapper *goex.SymbolMapper) {
+	s.symbolMapper = mapper
+}
+
+func (s *SpotPrivateWs) OrderCallback(f func(order *goex.Order)) {
+	s.orderCallFn = f
+}
+
+func (s *SpotPrivateWs) AccountCallback(f func(account *goex.Account)) {
+	s.accountCallFn = f
+}
+
+//创建listenKey并建立用户数据流连接
+func (s *SpotPrivateWs) Login() error {
+	return s.stream.start()
+}
+
+func (s *SpotPrivateWs) SubscribeOrder(pair goex.CurrencyPair) error {
+	if s.orderCallFn == nil {
+		return errors.New("please set order callback func")
+	}
+
+	s.lock.Lock()
+	s.orderPairs[pair.ToSymbol("")] = true
+	s.lock.Unlock()
+
+	return s.stream.start()
+}
+
+//用户数据流推送全部资产余额，pair无效
+func (s *SpotPrivateWs) SubscribeAccount(pair goex.CurrencyPair) error {
+	if s.accountCallFn == nil {
+		return errors.New("please set account callback func")
+	}
+
+	s.lock.Lock()
+	s.subscribeAccount = true
+	s.lock.Unlock()
+
+	return s.stream.start()
+}
+
+func (s *SpotPrivateWs) handle(event string, data []byte) error {
+	var m = make(map[string]interface{}, 16)
+	err := json.Unmarshal(data, &m)
+	if err != nil {
+		return err
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	switch event {
+	case "executionReport":
+		if !s.orderPairs[strings.ToUpper(goex.ToString(m["s"]))] {
+			return nil
+		}
+		s.orderCallFn(s.adaptExecutionReport(m))
+	case "outboundAccountPosition":
+		if !s.subscribeAccount {
+			return nil
+		}
+		s.accountCallFn(s.adaptAccountPosition(m))
+	default:
+		logger.Debugf("[binance] ignore user data event: %s", string(data))
+	}
+
+	return nil
+}
+
+func (s *SpotPrivateWs) adaptExecutionReport(m map[string]interface{}) *goex.Order {
+	side := goex.SELL
+	if m["S"] == "BUY" {
+		side = goex.BUY
+	}
+
+	ord := &goex.Order{
+		Cid:       goex.ToString(m["c"]),
+		OrderID:   goex.ToInt(m["i"]),
+		OrderID2:  fmt.Sprint(goex.ToInt64(m["i"])),
+		Currency:  s.symbolMapper.PairOf(goex.ToString(m["s"]), adaptSymbolToCurrencyPair, ""),
+		Side:      side,
+		Type:      strings.ToLower(goex.ToString(m["o"])),
+		Fee:       goex.ToFloat64(m["n"]),
+		Status:    adaptOrderStatus(goex.ToString(m["X"])),
+		OrderTime: goex.ToInt(m["O"]),
+		Decimals: goex.OrderDecimals{
+			Price:      goex.ToDecimal(m["p"]),
+			Amount:     goex.ToDecimal(m["q"]),
+			DealAmount: goex.ToDecimal(m["z"]),
+		},
+	}
+	ord.Decimals.AvgPrice = adaptAvgPrice(goex.ToDecimal(m["Z"]), ord.Decimals.DealAmount)
+	ord.Price = ord.Decimals.Price.Float64()
+	ord.Amount = ord.Decimals.Amount.Float64()
+	ord.DealAmount = ord.Decimals.DealAmount.Float64()
+	ord.AvgPrice = ord.Decimals.AvgPrice.Float64()
+
+	if m["X"] == "EXPIRED" {
+		ord.Status = goex.ORDER_CANCEL
+	}
+
+	switch m["f"] {
+	case "IOC":
+		ord.OrderType = goex.ORDER_FEATURE_IOC
+	case "FOK":
+		ord.OrderType = goex.ORDER_FEATURE_FOK
+	}
+
+	if m["o"] == "LIMIT_MAKER" {
+		ord.OrderType = goex.ORDER_FEATURE_POST_ONLY
+	}
+
+	if ord.Status == goex.ORDER_FINISH || ord.Status == goex.ORDER_CANCEL || ord.Status == goex.ORDER_REJECT {
+		ord.FinishedTime = goex.ToInt64(m["T"])
+	}
+
+	return ord
+}
+
+func (s *SpotPrivateWs) adaptAccountPosition(m map[string]interface{}) *goex.Account {
+	acc := &goex.Account{
+		Exchange:    goex.BINANCE,
+		SubAccounts: make(map[goex.Currency]goex.SubAccount, 4),
+	}
+
+	balances, _ := m["B"].([]interface{})
+	for _, v := range balances {
+		vv, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		currency := goex.NewCurrency(goex.ToString(vv["a"]), "").AdaptBccToBch()
+		acc.SubAccounts[currency] = goex.SubAccount{
+			Currency:     currency,
+			Amount:       goex.ToFloat64(vv["f"]),
+			ForzenAmount: goex.ToFloat64(vv["l"]),
+		}
+	}
+
+	return acc
+}
@@ -0,0 +1,36 @@
+package binance
+
+import (
+	"testing"
+
+	"github.com/mrwill84/goex"
+)
+
+func TestSpotPrivateWs_handle(t *testing.T) {
+	ws := NewSpotPrivateWs(&goex.APIConfig{})
+	ws.orderPairs["BTCUSDT"] = true
+
+	var ord *goex.Order
+	ws.OrderCallback(func(order *goex.Order) {
+		ord = order
+	})
+
+	err := ws.handle("executionReport", []byte(`{"e":"executionReport","s":"BTCUSDT","c":"TEST","S":"BUY","o":"LIMIT",
+		"f":"GTC","q":"0.001","p":"9910","X":"PARTIALLY_FILLED","i":8886774,"z":"0.0005","Z":"4.955","n":"0","O":1568879465650}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ord == nil || ord.OrderID2 != "8886774" || ord.Cid != "TEST" || ord.Status != goex.ORDER_PART_FINISH || ord.AvgPrice != 9910 {
+		t.Fatalf("unexpected order: %+v", ord)
+	}
+
+	//字段类型不符或缺失时不应panic
+	ord = nil
+	err = ws.handle("executionReport", []byte(`{"e":"executionReport","s":"BTCUSDT","c":null,"X":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ord == nil || ord.Cid != "" {
+		t.Fatalf("unexpected order: %+v", ord)
+	}
+}
@@ -3,14 +3,17 @@ package binance
 import (
 	json2 "encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
-	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/orderbook"
 )
 
 type req struct {
@@ -24,42 +27,95 @@ type resp struct {
 	Data   json2.RawMessage `json:"data"`
 }
 
-type depthResp struct {
-	LastUpdateId int             `json:"lastUpdateId"`
-	Bids         [][]interface{} `json:"bids"`
-	Asks         [][]interface{} `json:"asks"`
+//订阅/取消订阅请求的回应 , 成功时result为null
+type subscribeResp struct {
+	Id    *int `json:"id"`
+	Error *struct {
+		Code int64  `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"error"`
 }
 
+//增量深度 , pu仅合约
+type depthUpdateResp struct {
+	Event         string          `json:"e"`
+	EventTime     int64           `json:"E"`
+	Symbol        string          `json:"s"`
+	FirstUpdateId int64           `json:"U"`
+	LastUpdateId  int64           `json:"u"`
+	PrevUpdateId  int64           `json:"pu"`
+	Bids          [][]interface{} `json:"b"`
+	Asks          [][]interface{} `json:"a"`
+}
+
+//本地订单簿推送的深度档数
+const depthSize = 10
+
+//现货单个连接最多1024个stream，每秒最多5条消息(包括pong)
+const (
+	spotMaxStreams     = 1024
+	spotSubscribeLimit = 4
+)
+
 type SpotWs struct {
-	c         *goex.WsConn
-	once      sync.Once
+	c         *goex.WsPool
 	wsBuilder *goex.WsBuilder
+	base      *Binance
 
-	reqId int
+	reqId int64 //订阅/取消订阅并发调用，使用atomic递增
+
+	symbolMapper *goex.SymbolMapper
+
+	booksLock sync.RWMutex
+	books     map[string]*orderbook.OrderBook
+	depthLock sync.Mutex //订单簿就绪回调在后台goroutine中，和读循环的推送串行
 
 	depthCallFn  func(depth *goex.Depth)
 	tickerCallFn func(ticker *goex.Ticker)
 	tradeCallFn  func(trade *goex.Trade)
 }
 
+var (
+	_ goex.SpotWsApi         = (*SpotWs)(nil)
+	_ goex.SymbolMapperAware = (*SpotWs)(nil)
+)
+
 func NewSpotWs() *SpotWs {
-	spotWs := &SpotWs{}
+	spotWs := &SpotWs{books: make(map[string]*orderbook.OrderBook, 2)}
 	logger.Debugf("proxy url: %s", os.Getenv("HTTPS_PROXY"))
 
+	httpCli := &http.Client{Timeout: 10 * time.Second}
+	if os.Getenv("HTTPS_PROXY") != "" {
+		httpCli.Transport = &http.Transport{
+			Proxy: func(r *http.Request) (*url.URL, error) {
+				return url.Parse(os.Getenv("HTTPS_PROXY"))
+			},
+		}
+	}
+	spotWs.base = &Binance{
+		baseUrl:    GLOBAL_API_BASE_URL,
+		apiV3:      GLOBAL_API_BASE_URL + "/api/v3/",
+		httpClient: httpCli,
+	}
+
 	spotWs.wsBuilder = goex.NewWsBuilder().
 		WsUrl("wss://stream.binance.com:9443/stream?streams=depth/miniTicker/ticker/trade").
 		ProxyUrl(os.Getenv("HTTPS_PROXY")).
-		ProtoHandleFunc(spotWs.handle).AutoReconnect()
-
-	spotWs.reqId = 1
+		ProtoHandleFunc(spotWs.handle).AutoReconnect().
+		SubscribeRateLimit(spotSubscribeLimit, time.Second)
+	spotWs.c = goex.NewWsPool(spotWs.wsBuilder, spotMaxStreams)
 
 	return spotWs
 }
 
-func (s *SpotWs) connect() {
-	s.once.Do(func() {
-		s.c = s.wsBuilder.Build()
-	})
+//设置后使用交易规则把推送的stream映射回交易对，mapper可以包含合约的交易规则(只匹配现货)
+func (s *SpotWs) SetSymbolMapper(mapper *goex.SymbolMapper) {
+	s.symbolMapper = mapper
+}
+
+//btcusdt@depth@100ms -> BTC_USDT
+func (s *SpotWs) streamPair(stream string) goex.CurrencyPair {
+	return s.symbolMapper.PairOf(strings.Split(stream, "@")[0], adaptStreamToCurrencyPair, "")
 }
 
 func (s *SpotWs) DepthCallback(f func(depth *goex.Depth)) {
@@ -74,38 +130,71 @@ func (s *SpotWs) TradeCallback(f func(trade *goex.Trade)) {
 	s.tradeCallFn = f
 }
 
-func (s *SpotWs) SubscribeDepth(pair goex.CurrencyPair) error {
-	defer func() {
-		s.reqId++
-	}()
-
-	s.connect()
+func (s *SpotWs) nextReqId() int {
+	return int(atomic.AddInt64(&s.reqId, 1))
+}
 
-	return s.c.Subscribe(req{
+//stream同时作为订阅的Key，请求id用于关联订阅回应
+func (s *SpotWs) subscribe(stream string) error {
+	id := s.nextReqId()
+	return s.c.SubscribeWithId(stream, fmt.Sprint(id), req{
 		Method: "SUBSCRIBE",
-		Params: []string{
-			fmt.Sprintf("%s@depth10@100ms", pair.ToLower().ToSymbol("")),
-		},
-		Id: s.reqId,
+		Params: []string{stream},
+		Id:     id,
 	})
 }
 
-func (s *SpotWs) SubscribeTicker(pair goex.CurrencyPair) error {
-	defer func() {
-		s.reqId++
-	}()
+func (s *SpotWs) unsubscribe(stream string) error {
+	return s.c.Unsubscribe(stream, req{
+		Method: "UNSUBSCRIBE",
+		Params: []string{stream},
+		Id:     s.nextReqId(),
+	})
+}
 
-	s.connect()
+func (s *SpotWs) SubscribeDepth(pair goex.CurrencyPair) error {
+	symbol := pair.ToSymbol("")
+	stream := fmt.Sprintf("%s@depth@100ms", pair.ToLower().ToSymbol(""))
+	s.booksLock.Lock()
+	if _, ok := s.books[symbol]; !ok {
+		ob := orderbook.NewOrderBook(func() (*orderbook.Snapshot, error) {
+			return s.base.getDepthSnapshot(s.base.apiV3, symbol, 1000)
+		})
+		ob.ReadyCallback(func() {
+			s.emitDepth(ob, s.streamPair(stream))
+		})
+		s.books[symbol] = ob
+	}
+	s.booksLock.Unlock()
 
-	return s.c.Subscribe(req{
-		Method: "SUBSCRIBE",
-		Params: []string{pair.ToLower().ToSymbol("") + "@ticker"},
-		Id:     s.reqId,
-	})
+	return s.subscribe(stream)
+}
+
+func (s *SpotWs) SubscribeTicker(pair goex.CurrencyPair) error {
+	return s.subscribe(pair.ToLower().ToSymbol("") + "@ticker")
 }
 
 func (s *SpotWs) SubscribeTrade(pair goex.CurrencyPair) error {
-	panic("implement me")
+	return s.subscribe(pair.ToLower().ToSymbol("") + "@trade")
+}
+
+func (s *SpotWs) UnsubscribeDepth(pair goex.CurrencyPair) error {
+	s.booksLock.Lock()
+	if ob, ok := s.books[pair.ToSymbol("")]; ok {
+		ob.Close()
+		delete(s.books, pair.ToSymbol(""))
+	}
+	s.booksLock.Unlock()
+
+	return s.unsubscribe(fmt.Sprintf("%s@depth@100ms", pair.ToLower().ToSymbol("")))
+}
+
+func (s *SpotWs) UnsubscribeTicker(pair goex.CurrencyPair) error {
+	return s.unsubscribe(pair.ToLower().ToSymbol("") + "@ticker")
+}
+
+func (s *SpotWs) UnsubscribeTrade(pair goex.CurrencyPair) error {
+	return s.unsubscribe(pair.ToLower().ToSymbol("") + "@trade")
 }
 
 func (s *SpotWs) handle(data []byte) error {
@@ -116,12 +205,20 @@ func (s *SpotWs) handle(data []byte) error {
 		return err
 	}
 
-	if strings.HasSuffix(r.Stream, "@depth10@100ms") {
-		return s.depthHandle(r.Data, adaptStreamToCurrencyPair(r.Stream))
+	if strings.HasSuffix(r.Stream, "@depth@100ms") {
+		return s.depthHandle(r.Data, s.streamPair(r.Stream))
 	}
 
 	if strings.HasSuffix(r.Stream, "@ticker") {
-		return s.tickerHandle(r.Data, adaptStreamToCurrencyPair(r.Stream))
+		return s.tickerHandle(r.Data, s.streamPair(r.Stream))
+	}
+
+	if strings.HasSuffix(r.Stream, "@trade") {
+		return s.tradeHandle(r.Data, s.streamPair(r.Stream))
+	}
+
+	if r.Stream == "" && ackSubscription(data, s.c) {
+		return nil
 	}
 
 	logger.Warn("unknown ws response:", string(data))
@@ -129,43 +226,73 @@ func (s *SpotWs) handle(data []byte) error {
 	return nil
 }
 
+//订阅回应按请求id关联到订阅，取消订阅的回应没有对应的订阅，忽略；不是订阅回应时返回false
+func ackSubscription(data []byte, pools ...*goex.WsPool) bool {
+	var r subscribeResp
+	if json2.Unmarshal(data, &r) != nil || r.Id == nil {
+		return false
+	}
+
+	var err error
+	if r.Error != nil {
+		err = adaptErrorCode(nil, r.Error.Code, r.Error.Msg)
+	}
+	for _, p := range pools {
+		if p.AckSubscription(fmt.Sprint(*r.Id), err) {
+			break
+		}
+	}
+	return true
+}
+
+//增量深度更新本地订单簿，推送前depthSize档
 func (s *SpotWs) depthHandle(data json2.RawMessage, pair goex.CurrencyPair) error {
-	var (
-		depthR depthResp
-		dep    goex.Depth
-		err    error
-	)
+	var depthR depthUpdateResp
 
-	err = json2.Unmarshal(data, &depthR)
+	err := json2.Unmarshal(data, &depthR)
 	if err != nil {
 		logger.Errorf("unmarshal depth response error %s[] , response data = %s", err, string(data))
 		return err
 	}
 
-	dep.Timestamp = time.Now().Unix()
-	dep.Pair = pair
-
-	for _, bid := range depthR.Bids {
-		dep.BidList = append(dep.BidList, goex.DepthRecord{
-			Price:  goex.ToFloat64(bid[0]),
-			Amount: goex.ToFloat64(bid[1]),
-		})
+	s.booksLock.RLock()
+	ob := s.books[depthR.Symbol]
+	s.booksLock.RUnlock()
+	if ob == nil {
+		return nil
 	}
 
-	for _, ask := range depthR.Asks {
-		dep.AskList = append(dep.AskList, goex.DepthRecord{
-			Price:  goex.ToFloat64(ask[0]),
-			Amount: goex.ToFloat64(ask[1]),
-		})
+	err = ob.Apply(&orderbook.Diff{
+		Asks:    adaptDepthLevels(depthR.Asks),
+		Bids:    adaptDepthLevels(depthR.Bids),
+		FirstId: depthR.FirstUpdateId,
+		LastId:  depthR.LastUpdateId,
+	})
+	if err != nil {
+		return err
 	}
 
-	sort.Sort(sort.Reverse(dep.AskList))
+	if !ob.IsReady() {
+		return nil
+	}
 
-	s.depthCallFn(&dep)
+	s.emitDepth(ob, pair)
 
 	return nil
 }
 
+//在锁内读取深度并回调，回调不会并发，也不会先推送较旧的深度
+func (s *SpotWs) emitDepth(ob *orderbook.OrderBook, pair goex.CurrencyPair) {
+	s.depthLock.Lock()
+	defer s.depthLock.Unlock()
+
+	dep := ob.Depth(depthSize)
+	dep.Timestamp = time.Now().Unix()
+	dep.Pair = pair
+
+	s.depthCallFn(dep)
+}
+
 func (s *SpotWs) tickerHandle(data json2.RawMessage, pair goex.CurrencyPair) error {
 	var (
 		tickerData = make(map[string]interface{}, 4)
@@ -191,3 +318,31 @@ func (s *SpotWs) tickerHandle(data json2.RawMessage, pair goex.CurrencyPair) err
 
 	return nil
 }
+
+//逐笔成交 , m为true时买方是maker，即主动卖出
+func (s *SpotWs) tradeHandle(data json2.RawMessage, pair goex.CurrencyPair) error {
+	var tradeData = make(map[string]interface{}, 8)
+
+	err := json2.Unmarshal(data, &tradeData)
+	if err != nil {
+		logger.Errorf("unmarshal trade response data error [%s] , data = %s", err, string(data))
+		return err
+	}
+
+	trade := goex.Trade{
+		Tid:      goex.ToInt64(tradeData["t"]),
+		Type:     goex.BUY,
+		Amount:   goex.ToFloat64(tradeData["q"]),
+		Price:    goex.ToFloat64(tradeData["p"]),
+		Date:     goex.ToInt64(tradeData["T"]),
+		Pair:     pair,
+		Exchange: "BINANCE",
+	}
+	if goex.ToBool(tradeData["m"]) {
+		trade.Type = goex.SELL
+	}
+
+	s.tradeCallFn(&trade)
+
+	return nil
+}
@@ -2,10 +2,14 @@ package binance
 
 import (
 	"log"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
+	"github.com/mrwill84/goex/orderbook"
 )
 
 var spotWs *SpotWs
@@ -21,17 +25,148 @@ func createSpotWs() {
 	})
 }
 
+//回放testdata/spot_ws.json中的推送，深度快照从testdata/spot_market.json获取
 func TestSpotWs_DepthCallback(t *testing.T) {
-	createSpotWs()
+	srv := replay.NewServer(t, "testdata/spot_market.json", GLOBAL_API_BASE_URL)
+	wsSrv := replay.NewWsServer(t, "testdata/spot_ws.json", "wss://stream.binance.com:9443")
+	wsSrv.SendAfter = 2
 
-	spotWs.SubscribeDepth(goex.BTC_USDT)
-	spotWs.SubscribeTicker(goex.LTC_USDT)
-	time.Sleep(11 * time.Minute)
+	ws := NewSpotWs()
+	ws.wsBuilder.WsUrl(wsSrv.URL + "/stream?streams=depth/miniTicker/ticker/trade")
+	ws.base.apiV3 = srv.URL + "/api/v3/"
+	ws.base.httpClient = srv.Client()
+
+	depths := make(chan *goex.Depth, 2)
+	tickers := make(chan *goex.Ticker, 1)
+	ws.DepthCallback(func(depth *goex.Depth) {
+		depths <- depth
+	})
+	ws.TickerCallback(func(ticker *goex.Ticker) {
+		tickers <- ticker
+	})
+
+	if err := ws.SubscribeDepth(goex.BTC_USDT); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SubscribeTicker(goex.BTC_USDT); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ticker := <-tickers:
+		replay.AssertGolden(t, "testdata/spot_ws_ticker.golden.json", ticker)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ticker timeout")
+	}
+
+	//快照在后台获取，期间的增量缓存后一起应用，取最后一次推送的深度
+	var depth *goex.Depth
+	select {
+	case depth = <-depths:
+	case <-time.After(5 * time.Second):
+		t.Fatal("depth timeout")
+	}
+	for more := true; more; {
+		select {
+		case depth = <-depths:
+		case <-time.After(500 * time.Millisecond):
+			more = false
+		}
+	}
+	replay.AssertGolden(t, "testdata/spot_ws_depth.golden.json", depth, "Timestamp")
+}
+
+func TestSpotWs_TradeCallback(t *testing.T) {
+	wsSrv := replay.NewWsServer(t, "testdata/spot_ws_trade.json", "wss://stream.binance.com:9443")
+	wsSrv.SendAfter = 1
+
+	ws := NewSpotWs()
+	ws.wsBuilder.WsUrl(wsSrv.URL + "/stream?streams=depth/miniTicker/ticker/trade")
+
+	trades := make(chan *goex.Trade, 1)
+	ws.TradeCallback(func(trade *goex.Trade) {
+		trades <- trade
+	})
+
+	if err := ws.SubscribeTrade(goex.BTC_USDT); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case trade := <-trades:
+		replay.AssertGolden(t, "testdata/spot_ws_trade.golden.json", trade)
+	case <-time.After(5 * time.Second):
+		t.Fatal("trade timeout")
+	}
 }
 
 func TestSpotWs_SubscribeTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	createSpotWs()
 
 	spotWs.SubscribeTicker(goex.LTC_USDT)
 	time.Sleep(30 * time.Minute)
 }
+
+//并发订阅时请求id不重复
+func TestSpotWs_NextReqId(t *testing.T) {
+	ws := &SpotWs{}
+	ids := make(chan int, 100)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids <- ws.nextReqId()
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int]bool, 100)
+	for id := range ids {
+		if seen[id] {
+			t.Fatal("duplicate req id", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != 100 {
+		t.Fatal(len(seen))
+	}
+}
+
+//订单簿就绪回调和读循环并发推送深度时，深度回调串行执行
+func TestSpotWs_EmitDepthSerialized(t *testing.T) {
+	ob := orderbook.NewOrderBook(nil)
+	if err := ob.Snapshot(&orderbook.Snapshot{
+		Asks:     []orderbook.Level{{Price: "100.1", Amount: "1"}},
+		Bids:     []orderbook.Level{{Price: "100", Amount: "2"}},
+		UpdateId: 1,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var inflight, overlapped int32
+	ws := &SpotWs{}
+	ws.DepthCallback(func(depth *goex.Depth) {
+		if atomic.AddInt32(&inflight, 1) > 1 {
+			atomic.StoreInt32(&overlapped, 1)
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&inflight, -1)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ws.emitDepth(ob, goex.BTC_USDT)
+		}()
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&overlapped) != 0 {
+		t.Fatal("depth callback called concurrently")
+	}
+}
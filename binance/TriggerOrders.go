@@ -0,0 +1,213 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	. "github.com/mrwill84/goex"
+)
+
+var (
+	_ FutureTriggerOrderAPI = (*BinanceSwap)(nil)
+	_ FutureTriggerOrderAPI = (*BinanceFutures)(nil)
+)
+
+var triggerOrderTypes = map[TriggerOrderType]string{
+	TRIGGER_STOP_MARKET:        "STOP_MARKET",
+	TRIGGER_STOP_LIMIT:         "STOP",
+	TRIGGER_TAKE_PROFIT_MARKET: "TAKE_PROFIT_MARKET",
+	TRIGGER_TAKE_PROFIT_LIMIT:  "TAKE_PROFIT",
+	TRIGGER_TRAILING_STOP:      "TRAILING_STOP_MARKET",
+}
+
+type triggerOrderResponse struct {
+	OrderId       int64   `json:"orderId"`
+	ClientOrderId string  `json:"clientOrderId"`
+	Symbol        string  `json:"symbol"`
+	Side          string  `json:"side"`
+	PositionSide  string  `json:"positionSide"`
+	Type          string  `json:"type"`
+	OrigQty       float64 `json:"origQty,string"`
+	Price         float64 `json:"price,string"`
+	StopPrice     float64 `json:"stopPrice,string"`
+	ActivatePrice float64 `json:"activatePrice,string"`
+	PriceRate     float64 `json:"priceRate,string"` //回调比例，1表示1%
+	ReduceOnly    bool    `json:"reduceOnly"`
+	ClosePosition bool    `json:"closePosition"`
+	Time          int64   `json:"time"`
+	UpdateTime    int64   `json:"updateTime"`
+}
+
+//平仓单在单向持仓模式下使用reduceOnly，双向持仓模式下使用positionSide
+func placeTriggerOrder(bn *Binance, apiKey, symbol string, hedge bool, ord *TriggerOrder) (*TriggerOrder, error) {
+	orderType, ok := triggerOrderTypes[ord.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown trigger order type %s", ord.Type)
+	}
+
+	if ord.ClientOid == "" {
+		ord.ClientOid = NewClientOrderId(BINANCE)
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("type", orderType)
+	params.Set("newClientOrderId", ord.ClientOid)
+	params.Set("side", "SELL")
+	if ord.OType == OPEN_BUY || ord.OType == CLOSE_SELL {
+		params.Set("side", "BUY")
+	}
+
+	if ord.Type == TRIGGER_TRAILING_STOP {
+		params.Set("callbackRate", FloatToString(ord.CallbackRate*100, 1))
+		if ord.TriggerPrice > 0 {
+			params.Set("activationPrice", NewDecimalFromFloat(ord.TriggerPrice).String())
+		}
+	} else {
+		params.Set("stopPrice", NewDecimalFromFloat(ord.TriggerPrice).String())
+	}
+
+	if ord.Type == TRIGGER_STOP_LIMIT || ord.Type == TRIGGER_TAKE_PROFIT_LIMIT {
+		params.Set("price", NewDecimalFromFloat(ord.Price).String())
+		params.Set("timeInForce", "GTC")
+	}
+
+	if ord.ClosePosition {
+		params.Set("closePosition", "true")
+	} else {
+		params.Set("quantity", NewDecimalFromFloat(ord.Amount).String())
+	}
+
+	if hedge {
+		params.Set("positionSide", adaptPositionSide(true, ord.OType))
+	} else if !ord.ClosePosition && (ord.ReduceOnly || ord.OType == CLOSE_BUY || ord.OType == CLOSE_SELL) {
+		params.Set("reduceOnly", "true")
+	}
+
+	bn.buildParamsSigned(&params)
+	resp, err := HttpPostForm2(bn.httpClient, bn.apiV1+"order", params, map[string]string{"X-MBX-APIKEY": apiKey})
+	if err != nil {
+		return nil, adaptError(err)
+	}
+
+	var response triggerOrderResponse
+	if err = json.Unmarshal(resp, &response); err != nil {
+		return nil, err
+	}
+
+	placed := *ord
+	placed.OrderId = strconv.FormatInt(response.OrderId, 10)
+	placed.Status = ORDER_UNFINISH
+	placed.OrderTime = response.UpdateTime
+	return &placed, nil
+}
+
+func getUnfinishTriggerOrders(bn *Binance, apiKey, symbol string) ([]triggerOrderResponse, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	bn.buildParamsSigned(&params)
+
+	var response []triggerOrderResponse
+	err := HttpGet4(bn.httpClient, bn.apiV1+"openOrders?"+params.Encode(), map[string]string{"X-MBX-APIKEY": apiKey}, &response)
+	if err != nil {
+		return nil, adaptError(err)
+	}
+
+	orders := make([]triggerOrderResponse, 0, len(response))
+	for _, o := range response {
+		if o.Symbol == symbol && o.Type != "LIMIT" && o.Type != "MARKET" {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+func adaptTriggerOrder(o triggerOrderResponse, currencyPair CurrencyPair, contractType string) TriggerOrder {
+	ord := TriggerOrder{
+		OrderId:       strconv.FormatInt(o.OrderId, 10),
+		ClientOid:     o.ClientOrderId,
+		Currency:      currencyPair,
+		ContractType:  contractType,
+		OType:         adaptFuturesOType(o.Side, o.PositionSide),
+		Amount:        o.OrigQty,
+		TriggerPrice:  o.StopPrice,
+		Price:         o.Price,
+		CallbackRate:  o.PriceRate / 100,
+		ReduceOnly:    o.ReduceOnly,
+		ClosePosition: o.ClosePosition,
+		Status:        ORDER_UNFINISH,
+		OrderTime:     o.Time,
+	}
+
+	for t, name := range triggerOrderTypes {
+		if name == o.Type {
+			ord.Type = t
+		}
+	}
+	if ord.Type == TRIGGER_TRAILING_STOP {
+		ord.TriggerPrice = o.ActivatePrice
+	}
+
+	//单向持仓的只减仓单
+	if o.PositionSide == "BOTH" && (o.ReduceOnly || o.ClosePosition) {
+		ord.OType = CLOSE_BUY
+		if o.Side == "BUY" {
+			ord.OType = CLOSE_SELL
+		}
+	}
+	return ord
+}
+
+func (bs *BinanceSwap) PlaceTriggerOrder(ord *TriggerOrder) (*TriggerOrder, error) {
+	symbol := bs.adaptCurrencyPair(ord.Currency).ToSymbol("")
+	return placeTriggerOrder(&bs.Binance, bs.accessKey, symbol, bs.hedgeMode, ord)
+}
+
+func (bs *BinanceSwap) CancelTriggerOrder(currencyPair CurrencyPair, contractType, orderId string) (bool, error) {
+	return bs.FutureCancelOrder(currencyPair, contractType, orderId)
+}
+
+func (bs *BinanceSwap) GetUnfinishTriggerOrders(currencyPair CurrencyPair, contractType string) ([]TriggerOrder, error) {
+	response, err := getUnfinishTriggerOrders(&bs.Binance, bs.accessKey, bs.adaptCurrencyPair(currencyPair).ToSymbol(""))
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]TriggerOrder, 0, len(response))
+	for _, o := range response {
+		orders = append(orders, adaptTriggerOrder(o, currencyPair, contractType))
+	}
+	return orders, nil
+}
+
+func (bs *BinanceFutures) PlaceTriggerOrder(ord *TriggerOrder) (*TriggerOrder, error) {
+	symbol, err := bs.adaptToSymbol(ord.Currency, ord.ContractType)
+	if err != nil {
+		return nil, err
+	}
+	return placeTriggerOrder(bs.base, bs.apikey, symbol, bs.hedgeMode, ord)
+}
+
+func (bs *BinanceFutures) CancelTriggerOrder(currencyPair CurrencyPair, contractType, orderId string) (bool, error) {
+	return bs.FutureCancelOrder(currencyPair, contractType, orderId)
+}
+
+func (bs *BinanceFutures) GetUnfinishTriggerOrders(currencyPair CurrencyPair, contractType string) ([]TriggerOrder, error) {
+	symbol, err := bs.adaptToSymbol(currencyPair, contractType)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := getUnfinishTriggerOrders(bs.base, bs.apikey, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]TriggerOrder, 0, len(response))
+	for _, o := range response {
+		orders = append(orders, adaptTriggerOrder(o, currencyPair, contractType))
+	}
+	return orders, nil
+}
@@ -0,0 +1,77 @@
+package binance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mrwill84/goex"
+)
+
+func TestBinanceSwap_TriggerOrders(t *testing.T) {
+	var form url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/fapi/v1/order":
+			form = r.PostForm
+			w.Write([]byte(`{"orderId":2001,"clientOrderId":"` + r.PostForm.Get("newClientOrderId") + `","updateTime":1633017346000}`))
+		case "/fapi/v1/openOrders":
+			w.Write([]byte(`[
+				{"orderId":1,"symbol":"BTCUSDT","type":"LIMIT","side":"BUY","positionSide":"BOTH","origQty":"1","price":"100"},
+				{"orderId":2001,"clientOrderId":"stop1","symbol":"BTCUSDT","type":"STOP_MARKET","side":"SELL","positionSide":"BOTH","origQty":"0.01","price":"0","stopPrice":"58000","reduceOnly":true,"time":1633017346000},
+				{"orderId":2002,"clientOrderId":"trail1","symbol":"BTCUSDT","type":"TRAILING_STOP_MARKET","side":"SELL","positionSide":"BOTH","origQty":"0.01","price":"0","stopPrice":"0","activatePrice":"62000","priceRate":"1.5","reduceOnly":true,"time":1633017347000}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	swap := NewBinanceSwap(&goex.APIConfig{Endpoint: server.URL, HttpClient: http.DefaultClient})
+
+	ord, err := swap.PlaceTriggerOrder(&goex.TriggerOrder{
+		ClientOid:    "stop1",
+		Currency:     goex.BTC_USDT,
+		ContractType: goex.SWAP_USDT_CONTRACT,
+		Type:         goex.TRIGGER_STOP_MARKET,
+		OType:        goex.CLOSE_BUY,
+		Amount:       0.01,
+		TriggerPrice: 58000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ord.OrderId != "2001" || form.Get("type") != "STOP_MARKET" || form.Get("side") != "SELL" ||
+		form.Get("stopPrice") != "58000" || form.Get("reduceOnly") != "true" || form.Get("price") != "" {
+		t.Fatal(ord, form)
+	}
+
+	_, err = swap.PlaceTriggerOrder(&goex.TriggerOrder{
+		Currency:      goex.BTC_USDT,
+		ContractType:  goex.SWAP_USDT_CONTRACT,
+		Type:          goex.TRIGGER_TRAILING_STOP,
+		OType:         goex.CLOSE_SELL,
+		TriggerPrice:  55000,
+		CallbackRate:  0.015,
+		ClosePosition: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.Get("type") != "TRAILING_STOP_MARKET" || form.Get("side") != "BUY" || form.Get("callbackRate") != "1.5" ||
+		form.Get("activationPrice") != "55000" || form.Get("closePosition") != "true" || form.Get("quantity") != "" || form.Get("reduceOnly") != "" {
+		t.Fatal(form)
+	}
+
+	orders, err := swap.GetUnfinishTriggerOrders(goex.BTC_USDT, goex.SWAP_USDT_CONTRACT)
+	if err != nil || len(orders) != 2 {
+		t.Fatal(orders, err)
+	}
+	if o := orders[0]; o.Type != goex.TRIGGER_STOP_MARKET || o.OType != goex.CLOSE_BUY || o.TriggerPrice != 58000 || !o.ReduceOnly {
+		t.Fatal(o)
+	}
+	if o := orders[1]; o.Type != goex.TRIGGER_TRAILING_STOP || o.TriggerPrice != 62000 || o.CallbackRate != 0.015 {
+		t.Fatal(o)
+	}
+}
@@ -0,0 +1,124 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/logger"
+)
+
+const listenKeyKeepaliveInterval = 30 * time.Minute
+
+//binance 用户数据流: 通过listenKey建立私有ws连接，并定时keepalive，listenKey过期后自动重建连接
+type userDataStream struct {
+	base      *Binance
+	restUri   string //listenKey rest uri
+	wsBaseUrl string
+	handle    func(event string, data []byte) error
+
+	lock      sync.Mutex
+	listenKey string
+	c         *goex.WsConn
+	stop      chan struct{}
+}
+
+func newUserDataStream(base *Binance, restUri, wsBaseUrl string, handle func(event string, data []byte) error) *userDataStream {
+	return &userDataStream{
+		base:      base,
+		restUri:   restUri,
+		wsBaseUrl: wsBaseUrl,
+		handle:    handle,
+	}
+}
+
+func (u *userDataStream) start() error {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	if u.c != nil {
+		return nil
+	}
+
+	listenKey, err := u.base.newListenKey(u.restUri)
+	if err != nil {
+		logger.Errorf("[binance] create listen key error: %s", err.Error())
+		return err
+	}
+
+	c, err := goex.NewWsBuilder().
+		WsUrl(u.wsBaseUrl + listenKey).
+		ProxyUrl(os.Getenv("HTTPS_PROXY")).
+		ProtoHandleFunc(u.handleMessage).
+		AutoReconnect().
+		BuildWithContext(context.Background())
+	if err != nil {
+		logger.Errorf("[binance] connect user data stream error: %s", err.Error())
+		return err
+	}
+
+	u.listenKey = listenKey
+	u.c = c
+	u.stop = make(chan struct{})
+	go u.keepalive(listenKey, u.stop)
+
+	return nil
+}
+
+func (u *userDataStream) restart() {
+	u.lock.Lock()
+	if u.c != nil {
+		close(u.stop)
+		u.c.CloseWs()
+		u.c = nil
+	}
+	u.lock.Unlock()
+
+	if err := u.start(); err != nil {
+		logger.Errorf("[binance] restart user data stream error: %s", err.Error())
+	}
+}
+
+func (u *userDataStream) keepalive(listenKey string, stop chan struct{}) {
+	tick := time.NewTicker(listenKeyKeepaliveInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-tick.C:
+			err := u.base.keepaliveListenKey(u.restUri, listenKey)
+			if err != nil {
+				logger.Errorf("[binance] keepalive listen key error: %s , recreate user data stream", err.Error())
+				go u.restart()
+				return
+			}
+			logger.Debugf("[binance] keepalive listen key %s", listenKey)
+		}
+	}
+}
+
+func (u *userDataStream) handleMessage(data []byte) error {
+	var event struct {
+		Event     string `json:"e"`
+		EventTime int64  `json:"E"`
+	}
+
+	err := json.Unmarshal(data, &event)
+	if err != nil {
+		logger.Errorf("json unmarshal ws response error [%s] , response data = %s", err, string(data))
+		return err
+	}
+
+	if event.Event == "listenKeyExpired" {
+		logger.Warn("[binance] listen key expired , recreate user data stream")
+		go u.restart()
+		return nil
+	}
+
+	return u.handle(event.Event, data)
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var wallet *Wallet
@@ -18,6 +19,7 @@ func init() {
 }
 
 func TestWallet_Transfer(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(wallet.Transfer(goex.TransferParameter{
 		Currency: "USDT",
 		From:     goex.SPOT,
@@ -18,6 +18,10 @@ type Bitfinex struct {
 	secretKey string
 }
 
+var (
+	_ API = (*Bitfinex)(nil)
+)
+
 const (
 	baseURL  string = "https://api.bitfinex.com"
 	apiURLV1 string = baseURL + "/v1"
@@ -415,8 +419,3 @@ func convertPairToBitfinexSymbol(prefix string, pair CurrencyPair) string {
 	symbol := pair.ToSymbol("")
 	return prefix + symbol
 }
-
-func convertKeyToPair(key string) CurrencyPair {
-	split := strings.Split(key, ":")
-	return symbolToCurrencyPair(split[2][1:])
-}
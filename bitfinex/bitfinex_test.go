@@ -5,22 +5,26 @@ import (
 	"testing"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var bfx = New(http.DefaultClient, "", "")
 
 func TestBitfinex_GetTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ticker, _ := bfx.GetTicker(goex.ETH_BTC)
 	t.Log(ticker)
 }
 
 func TestBitfinex_GetDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	dep, _ := bfx.GetDepth(2, goex.ETH_BTC)
 	t.Log(dep.AskList)
 	t.Log(dep.BidList)
 }
 
 func TestBitfinex_GetKline(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	kline, _ := bfx.GetKlineRecords(goex.BTC_USD, goex.KLINE_PERIOD_1MONTH, 10)
 	for _, k := range kline {
 		t.Log(k)
@@ -1,10 +1,12 @@
 package bitfinex
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,22 +15,31 @@ import (
 
 const subscribe = "subscribe"
 const subscribed = "subscribed"
+const unsubscribe = "unsubscribe"
 const ticker = "ticker"
 const trades = "trades"
 const candles = "candles"
 
 type BitfinexWs struct {
 	*WsBuilder
-	sync.Once
+	connLock sync.Mutex
 	wsConn   *WsConn
+	lock     sync.Mutex
 	eventMap map[int64]SubscribeEvent
 
 	tickerCallback func(*Ticker)
 	depthCallback  func(*Depth)
 	tradeCallback  func(*Trade)
 	candleCallback func(*Kline)
+
+	symbolMapper *SymbolMapper
 }
 
+var (
+	_ SpotWsApi         = (*BitfinexWs)(nil)
+	_ SymbolMapperAware = (*BitfinexWs)(nil)
+)
+
 type SubscribeEvent struct {
 	Event     string `json:"event"`
 	SubID     string `json:"subId"`
@@ -40,6 +51,8 @@ type SubscribeEvent struct {
 	Key       string `json:"key,omitempty"`
 	Len       string `json:"len,omitempty"`
 	Pair      string `json:"pair"`
+	Code      int64  `json:"code"`
+	Msg       string `json:"msg"`
 }
 
 type EventMap map[int64]SubscribeEvent
@@ -75,7 +88,8 @@ func (bws *BitfinexWs) SubscribeTicker(pair CurrencyPair) error {
 	return bws.subscribe(map[string]interface{}{
 		"event":   subscribe,
 		"channel": ticker,
-		"symbol":  convertPairToBitfinexSymbol("t", pair)})
+		"symbol":  convertPairToBitfinexSymbol("t", pair),
+		"subId":   ticker + ":" + convertPairToBitfinexSymbol("t", pair)})
 }
 
 func (bws *BitfinexWs) SubscribeDepth(pair CurrencyPair) error {
@@ -89,7 +103,20 @@ func (bws *BitfinexWs) SubscribeTrade(pair CurrencyPair) error {
 	return bws.subscribe(map[string]interface{}{
 		"event":   subscribe,
 		"channel": trades,
-		"symbol":  convertPairToBitfinexSymbol("t", pair)})
+		"symbol":  convertPairToBitfinexSymbol("t", pair),
+		"subId":   trades + ":" + convertPairToBitfinexSymbol("t", pair)})
+}
+
+func (bws *BitfinexWs) UnsubscribeTicker(pair CurrencyPair) error {
+	return bws.unsubscribe(ticker + ":" + convertPairToBitfinexSymbol("t", pair))
+}
+
+func (bws *BitfinexWs) UnsubscribeDepth(pair CurrencyPair) error {
+	return bws.unsubscribe("book:" + convertPairToBitfinexSymbol("t", pair))
+}
+
+func (bws *BitfinexWs) UnsubscribeTrade(pair CurrencyPair) error {
+	return bws.unsubscribe(trades + ":" + convertPairToBitfinexSymbol("t", pair))
 }
 
 func (bws *BitfinexWs) SubscribeCandle(pair CurrencyPair, klinePeriod KlinePeriod) error {
@@ -103,29 +130,74 @@ func (bws *BitfinexWs) SubscribeCandle(pair CurrencyPair, klinePeriod KlinePerio
 		return fmt.Errorf("invalid period")
 	}
 
+	key := fmt.Sprintf("trade:%s:%s", period, symbol)
 	return bws.subscribe(map[string]interface{}{
 		"event":   subscribe,
 		"channel": candles,
-		"key":     fmt.Sprintf("trade:%s:%s", period, symbol),
+		"key":     key,
+		"subId":   candles + ":" + key,
 	})
 }
 
+//subId同时作为订阅的Key，交易所在subscribed和error事件中原样返回
 func (bws *BitfinexWs) subscribe(sub map[string]interface{}) error {
-	bws.connectWs()
-	return bws.wsConn.Subscribe(sub)
+	if err := bws.connectWs(); err != nil {
+		return err
+	}
+	subId := sub["subId"].(string)
+	return bws.wsConn.SubscribeWithId(subId, subId, sub)
 }
 
-func (bws *BitfinexWs) connectWs() {
-	bws.Do(func() {
-		bws.wsConn = bws.WsBuilder.Build()
-	})
+//取消订阅需要subscribed事件中的chanId，还没有收到时只从订阅列表中移除，收到后再取消
+func (bws *BitfinexWs) unsubscribe(subId string) error {
+	if bws.wsConn == nil {
+		return nil
+	}
+
+	bws.lock.Lock()
+	var unsub interface{}
+	for chanId, event := range bws.eventMap {
+		if event.SubID == subId {
+			unsub = map[string]interface{}{"event": unsubscribe, "chanId": chanId}
+			delete(bws.eventMap, chanId)
+			break
+		}
+	}
+	bws.lock.Unlock()
+
+	return bws.wsConn.Unsubscribe(subId, unsub)
+}
+
+//首次订阅时建立连接，连接失败时返回错误，下次订阅时重试
+func (bws *BitfinexWs) connectWs() error {
+	bws.connLock.Lock()
+	defer bws.connLock.Unlock()
+	if bws.wsConn != nil {
+		return nil
+	}
+	c, err := bws.WsBuilder.BuildWithContext(context.Background())
+	if err != nil {
+		return err
+	}
+	bws.wsConn = c
+	return nil
 }
 
 func (bws *BitfinexWs) handle(msg []byte) error {
 	var event SubscribeEvent
 	if err := json.Unmarshal(msg, &event); err == nil {
-		if event.Event == subscribed {
+		switch event.Event {
+		case subscribed:
+			if !bws.wsConn.AckSubscription(event.SubID, nil) {
+				//订阅回应之前已经取消订阅
+				return bws.wsConn.SendJsonMessage(map[string]interface{}{"event": unsubscribe, "chanId": event.ChanID})
+			}
+			bws.lock.Lock()
 			bws.eventMap[event.ChanID] = event
+			bws.lock.Unlock()
+			return nil
+		case "error":
+			bws.wsConn.AckSubscription(event.SubID, API_ERR.Wrap(fmt.Sprint(event.Code), event.Msg))
 			return nil
 		}
 	}
@@ -133,7 +205,9 @@ func (bws *BitfinexWs) handle(msg []byte) error {
 	var resp []interface{}
 	if err := json.Unmarshal(msg, &resp); err == nil {
 		channelID := ToInt64(resp[0])
+		bws.lock.Lock()
 		event, ok := bws.eventMap[channelID]
+		bws.lock.Unlock()
 		if !ok {
 			return nil
 		}
@@ -141,7 +215,7 @@ func (bws *BitfinexWs) handle(msg []byte) error {
 		switch event.Channel {
 		case ticker:
 			if raw, ok := resp[1].([]interface{}); ok {
-				pair := symbolToCurrencyPair(event.Pair)
+				pair := bws.symbolPair("t" + event.Pair)
 				t := bws.tickerFromRaw(pair, raw)
 				bws.tickerCallback(t)
 				return nil
@@ -152,7 +226,7 @@ func (bws *BitfinexWs) handle(msg []byte) error {
 			}
 
 			if raw, ok := resp[2].([]interface{}); ok {
-				pair := symbolToCurrencyPair(event.Pair)
+				pair := bws.symbolPair("t" + event.Pair)
 				trade := bws.tradeFromRaw(pair, raw)
 				bws.tradeCallback(trade)
 				return nil
@@ -163,7 +237,7 @@ func (bws *BitfinexWs) handle(msg []byte) error {
 					return nil
 				}
 
-				kline := klineFromRaw(convertKeyToPair(event.Key), raw)
+				kline := klineFromRaw(bws.symbolPair(strings.Split(event.Key, ":")[2]), raw)
 				bws.candleCallback(kline)
 				return nil
 			}
@@ -174,6 +248,17 @@ func (bws *BitfinexWs) handle(msg []byte) error {
 	return nil
 }
 
+//设置后使用交易规则把推送的symbol(tBTCUSD)映射回交易对
+func (bws *BitfinexWs) SetSymbolMapper(mapper *SymbolMapper) {
+	bws.symbolMapper = mapper
+}
+
+func (bws *BitfinexWs) symbolPair(symbol string) CurrencyPair {
+	return bws.symbolMapper.PairOf(symbol, func(symbol string) CurrencyPair {
+		return symbolToCurrencyPair(symbol[1:])
+	}, "")
+}
+
 func (bws *BitfinexWs) tickerFromRaw(pair CurrencyPair, raw []interface{}) *Ticker {
 	return &Ticker{
 		Pair: pair,
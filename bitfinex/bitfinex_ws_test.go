@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 func TestNewBitfinexWs(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	bitfinexWs := NewWs()
 
 	handleTicker := func(ticker *goex.Ticker) {
@@ -24,6 +24,13 @@ type BitgetSwap struct {
 	timeOffset int64
 }
 
+var (
+	_ FutureRestAPI     = (*BitgetSwap)(nil)
+	_ MarketInfo        = (*BitgetSwap)(nil)
+	_ PerpetualDataAPI  = (*BitgetSwap)(nil)
+	_ FutureLeverageAPI = (*BitgetSwap)(nil)
+)
+
 func NewSwap(config *APIConfig) *BitgetSwap {
 	if config.Endpoint == "" {
 		config.Endpoint = baseUrl
@@ -240,16 +247,19 @@ func (bs *BitgetSwap) PlaceFutureOrder(currencyPair CurrencyPair, contractType,
 * @param openType   1:开多   2:开空   3:平多   4:平空
 * @param matchPrice  是否为对手价 0:不是    1:是   ,当取值为1时,price无效
  */
-func (bs *BitgetSwap) PlaceFutureOrder2(currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice int, leverRate float64) (*FutureOrder, error) {
+func (bs *BitgetSwap) PlaceFutureOrder2(currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice int, leverRate float64, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
 	fOrder := &FutureOrder{
 		Currency:     currencyPair,
-		ClientOid:    GenerateOrderClientId(32),
+		ClientOid:    GetClientOrderId(opt...),
 		Price:        ToFloat64(price),
 		Amount:       ToFloat64(amount),
 		OrderType:    openType,
 		LeverRate:    leverRate,
 		ContractName: contractType,
 	}
+	if fOrder.ClientOid == "" {
+		fOrder.ClientOid = NewClientOrderId(BITGET_SWAP)
+	}
 
 	symbol := bs.adaptSymbol(currencyPair)
 	uri := "/api/swap/v3/order/placeOrder"
@@ -262,6 +272,17 @@ func (bs *BitgetSwap) PlaceFutureOrder2(currencyPair CurrencyPair, contractType,
 	params["order_type"] = "0"
 	if matchPrice == 0 {
 		params["price"] = price
+		//1:只做maker 2:全部成交或立即取消 3:立即成交并取消剩余
+		if tif, ok := GetTimeInForce(opt...); ok {
+			switch tif {
+			case PostOnly:
+				params["order_type"] = "1"
+			case Fok:
+				params["order_type"] = "2"
+			case Ioc:
+				params["order_type"] = "3"
+			}
+		}
 	}
 	resp, err := bs.doAuthRequest(http.MethodPost, uri, params)
 	if err != nil {
@@ -284,7 +305,7 @@ func (bs *BitgetSwap) PlaceFutureOrder2(currencyPair CurrencyPair, contractType,
 }
 
 func (bs *BitgetSwap) LimitFuturesOrder(currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
-	return bs.PlaceFutureOrder2(currencyPair, contractType, price, amount, openType, 0, 10)
+	return bs.PlaceFutureOrder2(currencyPair, contractType, price, amount, openType, 0, 10, opt...)
 }
 
 func (bs *BitgetSwap) MarketFuturesOrder(currencyPair CurrencyPair, contractType, amount string, openType int) (*FutureOrder, error) {
@@ -394,7 +415,15 @@ func (bs *BitgetSwap) GetFuturePosition(currencyPair CurrencyPair, contractType
 *获取订单信息
  */
 func (bs *BitgetSwap) GetFutureOrders(orderIds []string, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
-	panic("not implement")
+	orders := make([]FutureOrder, 0, len(orderIds))
+	for _, orderId := range orderIds {
+		ord, err := bs.GetFutureOrder(orderId, currencyPair, contractType)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *ord)
+	}
+	return orders, nil
 }
 
 /**
@@ -496,6 +525,10 @@ func (bs *BitgetSwap) GetUnfinishFutureOrders(currencyPair CurrencyPair, contrac
 	return orders, nil
 }
 
+func (bs *BitgetSwap) GetFutureOrderHistory(pair CurrencyPair, contractType string, optional ...OptionalParameter) ([]FutureOrder, error) {
+	panic("not supported.")
+}
+
 /**
 *获取交易费
  */
@@ -520,7 +553,7 @@ func (bs *BitgetSwap) GetDeliveryTime() (int, int, int, int) {
 /**
 * 获取K线数据
  */
-func (bs *BitgetSwap) GetKlineRecords(contractType string, currency CurrencyPair, period, size, since int) ([]FutureKline, error) {
+func (bs *BitgetSwap) GetKlineRecords(contractType string, currency CurrencyPair, period KlinePeriod, size int, optional ...OptionalParameter) ([]FutureKline, error) {
 	panic("not supported.")
 }
 
@@ -592,6 +625,76 @@ func (bs *BitgetSwap) GetMarginLevel(currencyPair CurrencyPair) (*MarginLeverage
 	return &margin, nil
 }
 
+//多仓杠杆倍数，全仓模式下多空杠杆相同
+func (bs *BitgetSwap) GetLeverage(currencyPair CurrencyPair, contractType string) (float64, error) {
+	margin, err := bs.GetMarginLevel(currencyPair)
+	if err != nil {
+		return 0, err
+	}
+	return margin.LongLeverage, nil
+}
+
+//多空两个方向设置相同的杠杆倍数
+func (bs *BitgetSwap) SetLeverage(currencyPair CurrencyPair, contractType string, lever int) error {
+	for _, side := range []int{1, 2} {
+		if _, err := bs.SetMarginLevel(currencyPair, lever, side); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// marginMode
+//fixed:逐仓
+//crossed:全仓
+func (bs *BitgetSwap) SetMarginMode(currencyPair CurrencyPair, contractType string, mode MarginMode) error {
+	reqBody := make(map[string]interface{})
+	reqBody["symbol"] = bs.adaptSymbol(currencyPair)
+	switch mode {
+	case CROSS_MARGIN:
+		reqBody["marginMode"] = "crossed"
+	case ISOLATED_MARGIN:
+		reqBody["marginMode"] = "fixed"
+	default:
+		return fmt.Errorf("unknown margin mode %s", mode)
+	}
+
+	_, err := bs.doAuthRequest(http.MethodPost, "/api/swap/v3/account/setMarginMode", reqBody)
+	return err
+}
+
+//bitget永续合约只有双向持仓模式
+func (bs *BitgetSwap) SetPositionMode(hedge bool) error {
+	if !hedge {
+		return EX_ERR_NOT_SUPPORTED.Wrap("", "one-way position mode")
+	}
+	return nil
+}
+
+// positionType
+//1:多仓
+//2:空仓
+// type
+//1:增加
+//2:减少
+func (bs *BitgetSwap) ModifyIsolatedMargin(currencyPair CurrencyPair, contractType string, positionSide int, amount float64) error {
+	reqBody := make(map[string]interface{})
+	reqBody["symbol"] = bs.adaptSymbol(currencyPair)
+	reqBody["positionType"] = 1
+	if positionSide == OPEN_SELL {
+		reqBody["positionType"] = 2
+	}
+	reqBody["type"] = 1
+	if amount < 0 {
+		reqBody["type"] = 2
+		amount = -amount
+	}
+	reqBody["amount"] = FloatToString(amount, 8)
+
+	_, err := bs.doAuthRequest(http.MethodPost, "/api/swap/v3/account/adjustMargin", reqBody)
+	return err
+}
+
 type Instrument struct {
 	Coin                string        `json:"coin"`
 	ContractVal         string        `json:"contract_val"`
@@ -657,6 +760,35 @@ func (bs *BitgetSwap) GetInstruments() ([]Instrument, error) {
 	return ins, nil
 }
 
+//tick_size , size_increment为小数位数 , priceEndStep为价格最后一位的步长
+func (bs *BitgetSwap) GetMarkets() ([]Market, error) {
+	ins, err := bs.GetInstruments()
+	if err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, 0, len(ins))
+	for _, v := range ins {
+		step := int64(v.PriceEndStep)
+		if step <= 0 {
+			step = 1
+		}
+		lot := NewDecimal(1, -int32(v.SizeIncrement))
+		pair := NewCurrencyPair(NewCurrency(v.UnderlyingIndex, ""), NewCurrency(v.QuoteCurrency, ""))
+		markets = append(markets, Market{
+			Pair:         pair,
+			ContractType: SWAP_CONTRACT,
+			Symbol:       bs.adaptSymbol(pair),
+			TickSize:     NewDecimal(step, -int32(v.TickSize)),
+			LotSize:      lot,
+			MinAmount:    lot,
+			ContractVal:  ToDecimal(v.ContractVal),
+			Status:       MARKET_TRADING,
+		})
+	}
+	return markets, nil
+}
+
 // side
 //1:多仓
 //2:空仓
@@ -686,3 +818,117 @@ func (bs *BitgetSwap) ModifyAutoAppendMargin(currencyPair CurrencyPair, side int
 	return true, nil
 
 }
+
+func (bs *BitgetSwap) GetFundingRate(currencyPair CurrencyPair, contractType string) (*FundingRate, error) {
+	symbol := bs.adaptSymbol(currencyPair)
+
+	var rate struct {
+		FundingRate float64 `json:"funding_rate,string"`
+	}
+	err := HttpGet4(bs.httpClient, fmt.Sprintf("%s/api/swap/v3/market/current_fundRate?symbol=%s", bs.baseUrl, symbol), nil, &rate)
+	if err != nil {
+		return nil, err
+	}
+
+	var fundingTime struct {
+		FundingTime int64 `json:"funding_time,string"`
+	}
+	err = HttpGet4(bs.httpClient, fmt.Sprintf("%s/api/swap/v3/market/funding_time?symbol=%s", bs.baseUrl, symbol), nil, &fundingTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FundingRate{
+		Pair:         currencyPair,
+		ContractType: contractType,
+		Rate:         rate.FundingRate,
+		FundingTime:  fundingTime.FundingTime,
+	}, nil
+}
+
+//bitget只支持分页查询(每页最多100条)，按时间降序翻页直到超出start或凑够size条
+func (bs *BitgetSwap) GetFundingRateHistory(currencyPair CurrencyPair, contractType string, start, end int64, size int) ([]FundingRate, error) {
+	const pageSize = 100
+	var rates []FundingRate
+	for page := 1; ; page++ {
+		var resp []struct {
+			RealizedRate float64 `json:"realized_rate,string"`
+			FundingTime  int64   `json:"funding_time,string"`
+		}
+		url := fmt.Sprintf("%s/api/swap/v3/market/historical_funding_rate?symbol=%s&pageIndex=%d&pageSize=%d",
+			bs.baseUrl, bs.adaptSymbol(currencyPair), page, pageSize)
+		if err := HttpGet4(bs.httpClient, url, nil, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, r := range resp {
+			if end > 0 && r.FundingTime > end {
+				continue
+			}
+			if start > 0 && r.FundingTime < start {
+				return rates, nil
+			}
+			rates = append(rates, FundingRate{
+				Pair:         currencyPair,
+				ContractType: contractType,
+				Rate:         r.RealizedRate,
+				FundingTime:  r.FundingTime,
+			})
+			if size > 0 && len(rates) >= size {
+				return rates, nil
+			}
+		}
+
+		if len(resp) < pageSize {
+			return rates, nil
+		}
+	}
+}
+
+func (bs *BitgetSwap) GetMarkPrice(currencyPair CurrencyPair, contractType string) (*MarkPrice, error) {
+	symbol := bs.adaptSymbol(currencyPair)
+
+	var markPrice struct {
+		MarkPrice float64 `json:"mark_price,string"`
+		Timestamp int64   `json:"timestamp,string"`
+	}
+	err := HttpGet4(bs.httpClient, fmt.Sprintf("%s/api/swap/v3/market/mark_price?symbol=%s", bs.baseUrl, symbol), nil, &markPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	var index struct {
+		Index float64 `json:"index,string"`
+	}
+	err = HttpGet4(bs.httpClient, fmt.Sprintf("%s/api/swap/v3/market/index?symbol=%s", bs.baseUrl, symbol), nil, &index)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MarkPrice{
+		Pair:         currencyPair,
+		ContractType: contractType,
+		MarkPrice:    markPrice.MarkPrice,
+		IndexPrice:   index.Index,
+		Timestamp:    markPrice.Timestamp,
+	}, nil
+}
+
+//amount单位为张
+func (bs *BitgetSwap) GetOpenInterest(currencyPair CurrencyPair, contractType string) (*OpenInterest, error) {
+	var resp struct {
+		Amount    float64 `json:"amount,string"`
+		Timestamp int64   `json:"timestamp,string"`
+	}
+	err := HttpGet4(bs.httpClient, fmt.Sprintf("%s/api/swap/v3/market/open_interest?symbol=%s", bs.baseUrl, bs.adaptSymbol(currencyPair)), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OpenInterest{
+		Pair:         currencyPair,
+		ContractType: contractType,
+		Amount:       resp.Amount,
+		Timestamp:    resp.Timestamp,
+	}, nil
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var bg = NewSwap(&goex.APIConfig{
@@ -23,50 +24,76 @@ var bg = NewSwap(&goex.APIConfig{
 	Lever:    0,
 })
 
+//回放testdata中的fixture , GOEX_RECORD=1时请求真实的交易所并更新fixture
+func newReplaySwap(t *testing.T, fixture string) *BitgetSwap {
+	srv := replay.NewServer(t, "testdata/"+fixture, baseUrl)
+	return NewSwap(&goex.APIConfig{
+		HttpClient: srv.Client(),
+		Endpoint:   srv.URL,
+	})
+}
+
 func TestBitgetSwap_GetFutureTicker(t *testing.T) {
-	t.Log(bg.GetFutureTicker(goex.ETH_USDT, ""))
+	ticker, err := newReplaySwap(t, "swap_market.json").GetFutureTicker(goex.ETH_USDT, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_ticker.golden.json", ticker)
 }
 
 func TestBitgetSwap_GetServerTime(t *testing.T) {
-	t.Log(bg.GetServerTime())
+	stime, err := newReplaySwap(t, "swap_market.json").GetServerTime()
+	if err != nil || stime != 1640000000000 {
+		t.Fatal(stime, err)
+	}
 }
 
 func TestBitgetSwap_GetFutureUserinfo(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bg.GetFutureUserinfo(goex.ETH_USDT))
 }
 
 func TestBitgetSwap_LimitFuturesOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bg.LimitFuturesOrder(goex.ETH_USDT, "", "350", "1", goex.CLOSE_BUY))
 }
 
 func TestBitgetSwap_GetFuturePosition(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bg.GetFuturePosition(goex.ETH_USDT, ""))
 }
 
 func TestBitgetSwap_GetUnfinishFutureOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bg.GetUnfinishFutureOrders(goex.ETH_USDT, ""))
 }
 
 func TestBitgetSwap_SetMarginLevel(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bg.SetMarginLevel(goex.ETH_USDT, 10, 2))
 }
 
 func TestBitgetSwap_GetMarginLevel(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bg.GetMarginLevel(goex.ETH_USDT))
 }
 
 func TestBitgetSwap_GetContractInfo(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bg.GetContractInfo(goex.ETH_USDT))
 }
 
 func TestBitgetSwap_GetFutureOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bg.GetFutureOrder("671529783552638913", goex.ETH_USDT, ""))
 }
 
 func TestBitgetSwap_FutureCancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bg.FutureCancelOrder(goex.ETH_USDT, "", "671529783552638913"))
 }
 
 func TestBitgetSwap_ModifyAutoAppendMargin(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(bg.ModifyAutoAppendMargin(goex.ETH_USDT, 1, 1))
 }
@@ -21,6 +21,10 @@ type Bithumb struct {
 	secretkey string
 }
 
+var (
+	_ API = (*Bithumb)(nil)
+)
+
 var (
 	baseUrl = "https://api.bithumb.com"
 )
@@ -236,7 +240,7 @@ func (bit *Bithumb) doAuthenticatedRequest(uri, params string, ret interface{})
 	params += "&endpoint=" + e_endpoint
 
 	// Api-Sign information generation.
-	hmac_data := uri + string(0) + params + string(0) + api_nonce
+	hmac_data := uri + "\x00" + params + "\x00" + api_nonce
 	hash_hmac_str := GetParamHmacSHA512Base64Sign(bit.secretkey, hmac_data)
 	api_sign := hash_hmac_str
 	content_length_str := strconv.Itoa(len(params))
@@ -304,12 +308,12 @@ func (bit *Bithumb) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
 
 	for _, v := range bids {
 		bid := v.(map[string]interface{})
-		dep.BidList = append(dep.BidList, DepthRecord{ToFloat64(bid["price"]), ToFloat64(bid["quantity"])})
+		dep.BidList = append(dep.BidList, DepthRecord{Price: ToFloat64(bid["price"]), Amount: ToFloat64(bid["quantity"])})
 	}
 
 	for _, v := range asks {
 		ask := v.(map[string]interface{})
-		dep.AskList = append(dep.AskList, DepthRecord{ToFloat64(ask["price"]), ToFloat64(ask["quantity"])})
+		dep.AskList = append(dep.AskList, DepthRecord{Price: ToFloat64(ask["price"]), Amount: ToFloat64(ask["quantity"])})
 	}
 
 	sort.Sort(sort.Reverse(dep.AskList))
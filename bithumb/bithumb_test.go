@@ -5,17 +5,20 @@ import (
 	"testing"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var bh = New(http.DefaultClient, "", "")
 
 func TestBithumb_GetTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ticker, err := bh.GetTicker(goex.NewCurrencyPair2("ALL_KAW"))
 	t.Log("err=>", err)
 	t.Log("ticker=>", ticker)
 }
 
 func TestBithumb_GetDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	dep, err := bh.GetDepth(1, goex.BTC_KRW)
 	t.Log("err=>", err)
 	t.Log("asks=>", dep.AskList)
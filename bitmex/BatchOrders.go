@@ -0,0 +1,135 @@
+package bitmex
+
+import (
+	"strings"
+
+	. "github.com/mrwill84/goex"
+)
+
+func (bm *bitmex) adaptBatchOrder(ord FutureOrder) BitmexOrder {
+	o := BitmexOrder{
+		Text:        "github.com/mrwill84/goex/tree/master/bitmex",
+		Symbol:      bm.adaptCurrencyPairToSymbol(ord.Currency, ord.ContractName),
+		OrdType:     "Limit",
+		TimeInForce: "GoodTillCancel",
+		ClOrdID:     ord.ClientOid,
+		OrderQty:    int(ord.Amount),
+		Price:       ord.Price,
+	}
+	if o.ClOrdID == "" {
+		o.ClOrdID = NewClientOrderId(BITMEX)
+	}
+
+	switch ord.OType {
+	case OPEN_BUY, CLOSE_SELL:
+		o.Side = "Buy"
+	case OPEN_SELL, CLOSE_BUY:
+		o.Side = "Sell"
+	}
+
+	var execInst []string
+	switch ord.OrderType {
+	case ORDER_FEATURE_POST_ONLY:
+		execInst = append(execInst, "ParticipateDoNotInitiate")
+	case ORDER_FEATURE_FOK:
+		o.TimeInForce = "FillOrKill"
+	case ORDER_FEATURE_IOC:
+		o.TimeInForce = "ImmediateOrCancel"
+	}
+	if ord.OType == CLOSE_BUY || ord.OType == CLOSE_SELL {
+		execInst = append(execInst, "ReduceOnly")
+	}
+	o.ExecInst = strings.Join(execInst, ",")
+
+	return o
+}
+
+//bulk接口是原子的，任意订单参数错误时整批失败
+func (bm *bitmex) BatchLimitFuturesOrders(orders []FutureOrder) ([]BatchOrderResult, error) {
+	var param struct {
+		Orders []BitmexOrder `json:"orders"`
+	}
+	for _, ord := range orders {
+		param.Orders = append(param.Orders, bm.adaptBatchOrder(ord))
+	}
+
+	var response []struct {
+		OrderID string `json:"orderID"`
+		ClOrdID string `json:"clOrdID"`
+	}
+	err := bm.doAuthRequest("POST", "/api/v1/order/bulk", bm.toJson(param), &response)
+	if err != nil {
+		return nil, err
+	}
+
+	orderIds := make(map[string]string, len(response))
+	for _, o := range response {
+		orderIds[o.ClOrdID] = o.OrderID
+	}
+
+	results := make([]BatchOrderResult, 0, len(orders))
+	for _, o := range param.Orders {
+		r := BatchOrderResult{OrderId: orderIds[o.ClOrdID], Cid: o.ClOrdID}
+		if r.OrderId == "" {
+			r.Err = EX_ERR_PLACE_ORDER_FAIL.OriginErr("no result for clOrdID " + o.ClOrdID)
+		}
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+//orderIds可以是订单ID或者goex生成的clOrdID
+func (bm *bitmex) BatchFutureCancelOrders(currencyPair CurrencyPair, contractType string, orderIds []string) ([]BatchOrderResult, error) {
+	var param struct {
+		OrderID []string `json:"orderID,omitempty"`
+		ClOrdID []string `json:"clOrdID,omitempty"`
+	}
+	for _, id := range orderIds {
+		if strings.HasPrefix(id, "goex") {
+			param.ClOrdID = append(param.ClOrdID, id)
+		} else {
+			param.OrderID = append(param.OrderID, id)
+		}
+	}
+
+	var response []struct {
+		OrderID string `json:"orderID"`
+		ClOrdID string `json:"clOrdID"`
+		Error   string `json:"error"`
+	}
+	err := bm.doAuthRequest("DELETE", "/api/v1/order", bm.toJson(param), &response)
+	if err != nil {
+		return nil, err
+	}
+
+	failed := make(map[string]error, len(response))
+	for _, o := range response {
+		if o.Error == "" {
+			continue
+		}
+		e := EX_ERR_CANCEL_ORDER_FAIL.OriginErr(o.Error)
+		failed[o.OrderID], failed[o.ClOrdID] = e, e
+	}
+
+	results := make([]BatchOrderResult, 0, len(orderIds))
+	for _, id := range orderIds {
+		results = append(results, BatchOrderResult{OrderId: id, Err: failed[id]})
+	}
+
+	return results, nil
+}
+
+func (bm *bitmex) FutureCancelAllOrders(currencyPair CurrencyPair, contractType string) (bool, error) {
+	var param struct {
+		Symbol string `json:"symbol"`
+	}
+	param.Symbol = bm.adaptCurrencyPairToSymbol(currencyPair, contractType)
+
+	var response []interface{}
+	err := bm.doAuthRequest("DELETE", "/api/v1/order/all", bm.toJson(param), &response)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
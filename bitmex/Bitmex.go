@@ -1,6 +1,7 @@
 package bitmex
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -21,6 +22,13 @@ type bitmex struct {
 	*APIConfig
 }
 
+var (
+	_ FutureRestAPI          = (*bitmex)(nil)
+	_ BatchFutureOrderAPI    = (*bitmex)(nil)
+	_ AmendFutureOrderAPI    = (*bitmex)(nil)
+	_ FutureClientOrderIdAPI = (*bitmex)(nil)
+)
+
 func (bm *bitmex) GetFutureOrderHistory(pair CurrencyPair, contractType string, optional ...OptionalParameter) ([]FutureOrder, error) {
 	panic("implement me")
 }
@@ -37,6 +45,13 @@ func New(config *APIConfig) *bitmex {
 	return bm
 }
 
+// 返回绑定了ctx的副本，所有http请求都受ctx控制
+func (bm *bitmex) WithContext(ctx context.Context) FutureRestAPI {
+	config := *bm.APIConfig
+	config.HttpClient = HttpClientWithContext(ctx, bm.HttpClient)
+	return &bitmex{&config}
+}
+
 func (bm *bitmex) generateSignature(httpMethod, uri, data, nonce string) string {
 	payload := strings.ToUpper(httpMethod) + uri + nonce + data
 	//println(payload)
@@ -59,7 +74,7 @@ func (bm *bitmex) doAuthRequest(m, uri, param string, r interface{}) error {
 		"api-signature": sign})
 	Log.Debug("response:", string(resp))
 	if err != nil {
-		return err
+		return adaptError(err)
 	} else {
 		//println(string(resp))
 		return json.Unmarshal(resp, &r)
@@ -118,6 +133,7 @@ type BitmexOrder struct {
 	OrdType     string    `json:"ordType"`
 	Text        string    `json:"text"`
 	TimeInForce string    `json:"timeInForce,omitempty"`
+	ExecInst    string    `json:"execInst,omitempty"`
 	Side        string    `json:"side"`
 	OrdStatus   string    `json:"ordStatus"`
 	Timestamp   time.Time `json:"timestamp"`
@@ -128,7 +144,7 @@ func (bm *bitmex) PlaceFutureOrder(currencyPair CurrencyPair, contractType, pric
 	return fOrder.OrderID2, err
 }
 
-func (bm *bitmex) PlaceFutureOrder2(currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice int, leverRate float64) (*FutureOrder, error) {
+func (bm *bitmex) PlaceFutureOrder2(currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice int, leverRate float64, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
 	var createOrderParameter BitmexOrder
 
 	var resp struct {
@@ -139,11 +155,25 @@ func (bm *bitmex) PlaceFutureOrder2(currencyPair CurrencyPair, contractType, pri
 	createOrderParameter.Symbol = bm.adaptCurrencyPairToSymbol(currencyPair, contractType)
 	createOrderParameter.OrdType = "Limit"
 	createOrderParameter.TimeInForce = "GoodTillCancel"
-	createOrderParameter.ClOrdID = GenerateOrderClientId(32)
+	createOrderParameter.ClOrdID = GetClientOrderId(opt...)
 	createOrderParameter.OrderQty = ToInt(amount)
 
+	if createOrderParameter.ClOrdID == "" {
+		createOrderParameter.ClOrdID = NewClientOrderId(BITMEX)
+	}
+
 	if matchPrice == 0 {
 		createOrderParameter.Price = ToFloat64(price)
+		if tif, ok := GetTimeInForce(opt...); ok {
+			switch tif {
+			case PostOnly:
+				createOrderParameter.ExecInst = "ParticipateDoNotInitiate"
+			case Fok:
+				createOrderParameter.TimeInForce = "FillOrKill"
+			case Ioc:
+				createOrderParameter.TimeInForce = "ImmediateOrCancel"
+			}
+		}
 	} else {
 		createOrderParameter.OrdType = "Market"
 	}
@@ -181,7 +211,7 @@ func (bm *bitmex) PlaceFutureOrder2(currencyPair CurrencyPair, contractType, pri
 }
 
 func (bm *bitmex) LimitFuturesOrder(currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
-	return bm.PlaceFutureOrder2(currencyPair, contractType, price, amount, openType, 0, 10)
+	return bm.PlaceFutureOrder2(currencyPair, contractType, price, amount, openType, 0, 10, opt...)
 }
 
 func (bm *bitmex) MarketFuturesOrder(currencyPair CurrencyPair, contractType, amount string, openType int) (*FutureOrder, error) {
@@ -206,6 +236,47 @@ func (bm *bitmex) FutureCancelOrder(currencyPair CurrencyPair, contractType, ord
 	return true, nil
 }
 
+func (bm *bitmex) FutureCancelOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (bool, error) {
+	var param struct {
+		ClOrdID string `json:"clOrdID"`
+	}
+	param.ClOrdID = cid
+	var response []interface{}
+	err := bm.doAuthRequest("DELETE", "/api/v1/order", bm.toJson(param), &response)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+//orderId可以是订单ID或者goex生成的clOrdID , newAmount为修改后的总数量
+func (bm *bitmex) AmendFutureOrder(currencyPair CurrencyPair, contractType, orderId, newPrice, newAmount string) (*FutureOrder, error) {
+	var param struct {
+		OrderID     string  `json:"orderID,omitempty"`
+		OrigClOrdID string  `json:"origClOrdID,omitempty"`
+		Price       float64 `json:"price,omitempty"`
+		OrderQty    int     `json:"orderQty,omitempty"`
+	}
+	if strings.HasPrefix(orderId, "goex") {
+		param.OrigClOrdID = orderId
+	} else {
+		param.OrderID = orderId
+	}
+	param.Price = ToFloat64(newPrice)
+	param.OrderQty = ToInt(newAmount)
+
+	var response BitmexOrder
+	err := bm.doAuthRequest("PUT", "/api/v1/order", bm.toJson(param), &response)
+	if err != nil {
+		return nil, err
+	}
+
+	ord := bm.adaptOrder(response)
+	ord.Currency = currencyPair
+	ord.ContractName = contractType
+	return &ord, nil
+}
+
 func (bm *bitmex) GetFuturePosition(currencyPair CurrencyPair, contractType string) ([]FuturePosition, error) {
 	var (
 		response []struct {
@@ -264,8 +335,16 @@ func (bm *bitmex) GetFutureOrders(orderIds []string, currencyPair CurrencyPair,
 }
 
 func (bm *bitmex) GetFutureOrder(orderId string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
+	return bm.getFutureOrder("orderID", orderId, currencyPair, contractType)
+}
+
+func (bm *bitmex) GetFutureOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
+	return bm.getFutureOrder("clOrdID", cid, currencyPair, contractType)
+}
+
+func (bm *bitmex) getFutureOrder(idKey, id string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
 	var response []BitmexOrder
-	filters := fmt.Sprintf(`{"orderID":"%s"}`, orderId)
+	filters := fmt.Sprintf(`{"%s":"%s"}`, idKey, id)
 	param := url.Values{}
 	param.Set("symbol", bm.adaptCurrencyPairToSymbol(currencyPair, contractType))
 	param.Set("filter", filters)
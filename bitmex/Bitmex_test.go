@@ -3,6 +3,7 @@ package bitmex
 import (
 	"github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/internal/replay"
 	"github.com/stretchr/testify/assert"
 	"net"
 	"net/http"
@@ -35,18 +36,37 @@ func init() {
 
 var mex *bitmex
 
+//回放testdata中的fixture , GOEX_RECORD=1时请求真实的交易所并更新fixture
+func newReplayBitmex(t *testing.T, fixture string) *bitmex {
+	srv := replay.NewServer(t, "testdata/"+fixture, baseUrl)
+	return New(&goex.APIConfig{
+		HttpClient: srv.Client(),
+		Endpoint:   srv.URL,
+	})
+}
+
 func TestBitmex_GetFutureDepth(t *testing.T) {
-	dep, err := mex.GetFutureDepth(goex.ETH_USDT, goex.SWAP_CONTRACT, 5)
-	assert.Nil(t, err)
-	t.Log(dep.AskList)
-	t.Log(dep.BidList)
+	dep, err := newReplayBitmex(t, "swap_market.json").GetFutureDepth(goex.BTC_USD, goex.SWAP_CONTRACT, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_depth.golden.json", dep, "Timestamp")
 }
 
 func TestBitmex_GetFutureTicker(t *testing.T) {
-	tk, er := mex.GetFutureTicker(goex.BTC_USD, "")
-	if assert.Nil(t, er) {
-		t.Logf("buy:%.8f ,sell: %.8f ,Last:%.8f , vol:%.8f", tk.Buy, tk.Sell, tk.Last, tk.Vol)
+	ticker, err := newReplayBitmex(t, "swap_market.json").GetFutureTicker(goex.BTC_USD, goex.SWAP_CONTRACT)
+	if err != nil {
+		t.Fatal(err)
 	}
+	replay.AssertGolden(t, "testdata/swap_ticker.golden.json", ticker)
+}
+
+func TestBitmex_GetFutureOrder(t *testing.T) {
+	ord, err := newReplayBitmex(t, "swap_market.json").GetFutureOrder("4b3fd1c4-2c5b-4d7f-9d9e-1a2b3c4d5e6f", goex.BTC_USD, goex.SWAP_CONTRACT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_order.golden.json", ord)
 }
 
 func TestBitmex_GetIndicativeFundingRate(t *testing.T) {
@@ -58,6 +78,7 @@ func TestBitmex_GetIndicativeFundingRate(t *testing.T) {
 }
 
 func TestBitmex_GetFutureUserinfo(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	userinfo, err := mex.GetFutureUserinfo()
 	if assert.Nil(t, err) {
 		t.Logf("%.8f", userinfo.FutureSubAccounts[goex.BTC].AccountRights)
@@ -68,22 +89,22 @@ func TestBitmex_GetFutureUserinfo(t *testing.T) {
 }
 
 func TestBitmex_GetFuturePosition(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(mex.GetFuturePosition(goex.BTC_USD, ""))
 }
 
 func TestBitmex_PlaceFutureOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	//{"orderID":"ae0436f4-9229-0be1-e9ea-45073a2a404a","clOrdID":"goexba0c770d9cea445eafb12b95fe220a0f"
 	t.Log(mex.PlaceFutureOrder(goex.BTC_USD, goex.SWAP_CONTRACT, "9999", "2", goex.CLOSE_SELL, 0, 10))
 }
 
 func TestBitmex_GetUnfinishFutureOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(mex.GetUnfinishFutureOrders(goex.BTC_USD, goex.SWAP_CONTRACT))
 }
 
-func TestBitmex_GetFutureOrder(t *testing.T) {
-	t.Log(mex.GetFutureOrder("ae0436f4-9229-0be1-e9ea-45073a2a404a", goex.BTC_USD, goex.SWAP_CONTRACT))
-}
-
 func TestBitmex_FutureCancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(mex.FutureCancelOrder(goex.BTC_USD, goex.SWAP_CONTRACT, "goexfd6fd7694877448e8ae81a9cd7ecd89a"))
 }
@@ -0,0 +1,60 @@
+package bitmex
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	. "github.com/mrwill84/goex"
+)
+
+//bitmex没有错误码，按错误信息前缀匹配；按顺序匹配第一个，较长(更具体)的前缀在前
+var errorMsgPrefixes = []struct {
+	prefix string
+	err    ApiError
+}{
+	{"Order price is below the liquidation", EX_ERR_INVALID_ORDER_PARAM},
+	{"The system is currently overloaded", EX_ERR_API_LIMIT},
+	{"Account has insufficient Available", EX_ERR_INSUFFICIENT_BALANCE},
+	{"This request has expired", EX_ERR_INVALID_TIMESTAMP},
+	{"Invalid price tickSize", EX_ERR_INVALID_ORDER_PARAM},
+	{"Unable to cancel order", EX_ERR_CANCEL_ORDER_FAIL},
+	{"This key is disabled", EX_ERR_AUTH_FAIL},
+	{"Signature not valid", EX_ERR_SIGN},
+	{"Rate limit exceeded", EX_ERR_API_LIMIT},
+	{"Duplicate clOrdID", EX_ERR_DUPLICATE_CLIENT_OID},
+	{"Invalid API Key", EX_ERR_AUTH_FAIL},
+	{"Invalid orderID", EX_ERR_NOT_FIND_ORDER},
+	{"Invalid symbol", EX_ERR_SYMBOL_ERR},
+	{"Maintenance", EX_ERR_MAINTENANCE},
+	{"Not Found", EX_ERR_NOT_FIND_ORDER},
+}
+
+//http错误响应 {"error":{"message":"...","name":"HTTPError"}} 转换为ApiError , 原始错误码为http状态码
+func adaptError(err error) error {
+	var httpErr *HttpError
+	if !errors.As(err, &httpErr) {
+		return err
+	}
+
+	var resp struct {
+		Error struct {
+			Message string `json:"message"`
+			Name    string `json:"name"`
+		} `json:"error"`
+	}
+	if e := json.Unmarshal([]byte(httpErr.Body), &resp); e != nil || resp.Error.Message == "" {
+		return err
+	}
+
+	code := strconv.Itoa(httpErr.StatusCode)
+	for _, p := range errorMsgPrefixes {
+		if strings.HasPrefix(resp.Error.Message, p.prefix) {
+			return p.err.Wrap(code, resp.Error.Message).WithRetryAfter(httpErr.RetryAfter)
+		}
+	}
+
+	//没有匹配的前缀时按http状态码分类
+	return ErrorCodeTable{}.WrapHttpError(httpErr, code, resp.Error.Message)
+}
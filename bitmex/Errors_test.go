@@ -0,0 +1,39 @@
+package bitmex
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mrwill84/goex"
+)
+
+func TestAdaptError(t *testing.T) {
+	err := adaptError(&goex.HttpError{StatusCode: 400, Body: `{"error":{"message":"Account has insufficient Available Balance, 0 XBt, need 1000 XBt","name":"ValidationError"}}`})
+	if !errors.Is(err, goex.EX_ERR_INSUFFICIENT_BALANCE) {
+		t.Fatal(err)
+	}
+
+	err = adaptError(&goex.HttpError{StatusCode: 404, Body: `{"error":{"message":"Not Found","name":"HTTPError"}}`})
+	if !errors.Is(err, goex.EX_ERR_NOT_FIND_ORDER) {
+		t.Fatal(err)
+	}
+
+	err = adaptError(&goex.HttpError{StatusCode: 429, Body: `{"error":{"message":"Too many requests","name":"HTTPError"}}`})
+	if !errors.Is(err, goex.EX_ERR_API_LIMIT) {
+		t.Fatal(err)
+	}
+
+	other := errors.New("EOF")
+	if adaptError(other) != other {
+		t.Fatal("non http error should be returned as it is")
+	}
+}
+
+//前缀按长度降序，包含关系的前缀总是匹配较长的
+func TestErrorMsgPrefixesOrder(t *testing.T) {
+	for i := 1; i < len(errorMsgPrefixes); i++ {
+		if len(errorMsgPrefixes[i].prefix) > len(errorMsgPrefixes[i-1].prefix) {
+			t.Fatal(errorMsgPrefixes[i-1].prefix, errorMsgPrefixes[i].prefix)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package bitmex
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+
+	. "github.com/mrwill84/goex"
+)
+
+var (
+	_ FutureLeverageAPI = (*bitmex)(nil)
+)
+
+type positionMargin struct {
+	Symbol      string  `json:"symbol"`
+	Leverage    float64 `json:"leverage"`
+	CrossMargin bool    `json:"crossMargin"`
+}
+
+func (bm *bitmex) getPositionMargin(symbol string) (*positionMargin, error) {
+	param := url.Values{}
+	param.Set("filter", fmt.Sprintf(`{"symbol":"%s"}`, symbol))
+
+	var response []positionMargin
+	err := bm.doAuthRequest("GET", "/api/v1/position?"+param.Encode(), "", &response)
+	if err != nil {
+		return nil, err
+	}
+	if len(response) == 0 {
+		//没有交易过的合约没有仓位记录，bitmex默认全仓
+		return &positionMargin{Symbol: symbol, CrossMargin: true}, nil
+	}
+	return &response[0], nil
+}
+
+func (bm *bitmex) GetLeverage(currencyPair CurrencyPair, contractType string) (float64, error) {
+	pos, err := bm.getPositionMargin(bm.adaptCurrencyPairToSymbol(currencyPair, contractType))
+	if err != nil {
+		return 0, err
+	}
+	return pos.Leverage, nil
+}
+
+//保持当前的保证金模式，全仓时设置的是全仓杠杆
+func (bm *bitmex) SetLeverage(currencyPair CurrencyPair, contractType string, lever int) error {
+	symbol := bm.adaptCurrencyPairToSymbol(currencyPair, contractType)
+	pos, err := bm.getPositionMargin(symbol)
+	if err != nil {
+		return err
+	}
+
+	uri := "/api/v1/position/leverage"
+	if pos.CrossMargin {
+		uri = "/api/v1/position/crossLeverage"
+	}
+
+	var param struct {
+		Symbol   string `json:"symbol"`
+		Leverage int    `json:"leverage"`
+	}
+	param.Symbol = symbol
+	param.Leverage = lever
+
+	var response positionMargin
+	return bm.doAuthRequest("POST", uri, bm.toJson(param), &response)
+}
+
+func (bm *bitmex) SetMarginMode(currencyPair CurrencyPair, contractType string, mode MarginMode) error {
+	var param struct {
+		Symbol  string `json:"symbol"`
+		Enabled bool   `json:"enabled"` //true 逐仓 , false 全仓
+	}
+	param.Symbol = bm.adaptCurrencyPairToSymbol(currencyPair, contractType)
+	switch mode {
+	case CROSS_MARGIN:
+		param.Enabled = false
+	case ISOLATED_MARGIN:
+		param.Enabled = true
+	default:
+		return fmt.Errorf("unknown margin mode %s", mode)
+	}
+
+	var response positionMargin
+	return bm.doAuthRequest("POST", "/api/v1/position/isolate", bm.toJson(param), &response)
+}
+
+//bitmex只有单向持仓模式
+func (bm *bitmex) SetPositionMode(hedge bool) error {
+	if hedge {
+		return EX_ERR_NOT_SUPPORTED.Wrap("", "hedge position mode")
+	}
+	return nil
+}
+
+//amount单位为结算币，USDT结算的合约按USDt(1e-6)折算，其余按XBt(聪)折算
+func (bm *bitmex) ModifyIsolatedMargin(currencyPair CurrencyPair, contractType string, positionSide int, amount float64) error {
+	unit := 1e8
+	if currencyPair.CurrencyB.Eq(USDT) {
+		unit = 1e6
+	}
+
+	var param struct {
+		Symbol string `json:"symbol"`
+		Amount int64  `json:"amount"`
+	}
+	param.Symbol = bm.adaptCurrencyPairToSymbol(currencyPair, contractType)
+	param.Amount = int64(math.Round(amount * unit))
+
+	var response positionMargin
+	return bm.doAuthRequest("POST", "/api/v1/position/transferMargin", bm.toJson(param), &response)
+}
@@ -0,0 +1,129 @@
+package bitmex
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	. "github.com/mrwill84/goex"
+)
+
+var (
+	_ PerpetualDataAPI = (*bitmex)(nil)
+)
+
+type instrument struct {
+	Symbol                string    `json:"symbol"`
+	FundingRate           float64   `json:"fundingRate"`
+	IndicativeFundingRate float64   `json:"indicativeFundingRate"`
+	FundingTimestamp      time.Time `json:"fundingTimestamp"`
+	FundingInterval       time.Time `json:"fundingInterval"`
+	MarkPrice             float64   `json:"markPrice"`
+	IndicativeSettlePrice float64   `json:"indicativeSettlePrice"` //永续合约为指数价格
+	OpenInterest          float64   `json:"openInterest"`
+	OpenValue             float64   `json:"openValue"`
+	SettlCurrency         string    `json:"settlCurrency"`
+	Timestamp             time.Time `json:"timestamp"`
+}
+
+func (bm *bitmex) getInstrument(symbol string) (*instrument, error) {
+	var instruments []instrument
+	err := HttpGet4(bm.HttpClient, fmt.Sprintf("%s/api/v1/instrument?symbol=%s", bm.Endpoint, symbol), nil, &instruments)
+	if err != nil {
+		return nil, adaptError(err)
+	}
+	if len(instruments) == 0 {
+		return nil, errors.New(" response is null")
+	}
+	return &instruments[0], nil
+}
+
+//fundingRate在fundingTimestamp收取，indicativeFundingRate为下一周期的预测费率
+func (bm *bitmex) GetFundingRate(currencyPair CurrencyPair, contractType string) (*FundingRate, error) {
+	ins, err := bm.getInstrument(bm.adaptCurrencyPairToSymbol(currencyPair, contractType))
+	if err != nil {
+		return nil, err
+	}
+
+	fundingTime := ins.FundingTimestamp.UnixNano() / int64(time.Millisecond)
+	//fundingInterval形如2000-01-01T08:00:00.000Z，相对2000-01-01的偏移即结算间隔
+	interval := ins.FundingInterval.Sub(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	return &FundingRate{
+		Pair:            currencyPair,
+		ContractType:    contractType,
+		Rate:            ins.FundingRate,
+		NextRate:        ins.IndicativeFundingRate,
+		FundingTime:     fundingTime,
+		NextFundingTime: fundingTime + int64(interval/time.Millisecond),
+	}, nil
+}
+
+//单次最多返回500条
+func (bm *bitmex) GetFundingRateHistory(currencyPair CurrencyPair, contractType string, start, end int64, size int) ([]FundingRate, error) {
+	params := url.Values{}
+	params.Set("symbol", bm.adaptCurrencyPairToSymbol(currencyPair, contractType))
+	params.Set("reverse", "true")
+	if start > 0 {
+		params.Set("startTime", time.Unix(0, start*int64(time.Millisecond)).UTC().Format(time.RFC3339Nano))
+	}
+	if end > 0 {
+		params.Set("endTime", time.Unix(0, end*int64(time.Millisecond)).UTC().Format(time.RFC3339Nano))
+	}
+	if size > 0 {
+		params.Set("count", fmt.Sprint(size))
+	}
+
+	var resp []struct {
+		Timestamp   time.Time `json:"timestamp"`
+		FundingRate float64   `json:"fundingRate"`
+	}
+	err := HttpGet4(bm.HttpClient, bm.Endpoint+"/api/v1/funding?"+params.Encode(), nil, &resp)
+	if err != nil {
+		return nil, adaptError(err)
+	}
+
+	rates := make([]FundingRate, 0, len(resp))
+	for _, r := range resp {
+		rates = append(rates, FundingRate{
+			Pair:         currencyPair,
+			ContractType: contractType,
+			Rate:         r.FundingRate,
+			FundingTime:  r.Timestamp.UnixNano() / int64(time.Millisecond),
+		})
+	}
+	return rates, nil
+}
+
+func (bm *bitmex) GetMarkPrice(currencyPair CurrencyPair, contractType string) (*MarkPrice, error) {
+	ins, err := bm.getInstrument(bm.adaptCurrencyPairToSymbol(currencyPair, contractType))
+	if err != nil {
+		return nil, err
+	}
+	return &MarkPrice{
+		Pair:         currencyPair,
+		ContractType: contractType,
+		MarkPrice:    ins.MarkPrice,
+		IndexPrice:   ins.IndicativeSettlePrice,
+		Timestamp:    ins.Timestamp.UnixNano() / int64(time.Millisecond),
+	}, nil
+}
+
+//openInterest单位为张，只有XBt结算的合约能折算为币数量(openValue单位为聪)
+func (bm *bitmex) GetOpenInterest(currencyPair CurrencyPair, contractType string) (*OpenInterest, error) {
+	ins, err := bm.getInstrument(bm.adaptCurrencyPairToSymbol(currencyPair, contractType))
+	if err != nil {
+		return nil, err
+	}
+
+	oi := &OpenInterest{
+		Pair:         currencyPair,
+		ContractType: contractType,
+		Amount:       ins.OpenInterest,
+		Timestamp:    ins.Timestamp.UnixNano() / int64(time.Millisecond),
+	}
+	if ins.SettlCurrency == "XBt" {
+		oi.Value = ins.OpenValue / 1e8
+	}
+	return oi, nil
+}
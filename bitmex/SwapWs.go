@@ -1,14 +1,16 @@
 package bitmex
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	. "github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/orderbook"
 )
 
 type SubscribeOp struct {
@@ -22,6 +24,16 @@ type wsMessage struct {
 	Data   json.RawMessage
 }
 
+//订阅成功: {"success":true,"subscribe":"orderBook10:XBTUSD"}
+//订阅失败: {"status":400,"error":"...","request":{"op":"subscribe","args":["..."]}}
+type subscribeResp struct {
+	Success   bool        `json:"success"`
+	Subscribe string      `json:"subscribe"`
+	Status    int         `json:"status"`
+	Error     string      `json:"error"`
+	Request   SubscribeOp `json:"request"`
+}
+
 type tickerData struct {
 	Symbol          string  `json:"symbol"`
 	MakerFee        float64 `json:"makerFee"`
@@ -45,15 +57,22 @@ type depthData struct {
 
 type SwapWs struct {
 	c         *WsConn
-	once      sync.Once
+	connLock  sync.Mutex
 	wsBuilder *WsBuilder
 
 	depthCall  func(depth *Depth)
 	tickerCall func(ticker *FutureTicker)
 
 	tickerCacheMap map[string]FutureTicker
+
+	symbolMapper *SymbolMapper
 }
 
+var (
+	_ FuturesWsApi      = (*SwapWs)(nil)
+	_ SymbolMapperAware = (*SwapWs)(nil)
+)
+
 func NewSwapWs() *SwapWs {
 	s := new(SwapWs)
 	s.wsBuilder = NewWsBuilder().DisableEnableCompression().WsUrl("wss://www.bitmex.com/realtime")
@@ -64,10 +83,29 @@ func NewSwapWs() *SwapWs {
 	return s
 }
 
-func (s *SwapWs) connect() {
-	s.once.Do(func() {
-		s.c = s.wsBuilder.Build()
-	})
+//首次订阅时建立连接，连接失败时返回错误，下次订阅时重试
+func (s *SwapWs) connect() error {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+	if s.c != nil {
+		return nil
+	}
+	c, err := s.wsBuilder.BuildWithContext(context.Background())
+	if err != nil {
+		return err
+	}
+	s.c = c
+	return nil
+}
+
+//设置后使用交易规则把推送的symbol映射回交易对，合约类型仍由symbol解析
+func (s *SwapWs) SetSymbolMapper(mapper *SymbolMapper) {
+	s.symbolMapper = mapper
+}
+
+func (s *SwapWs) adaptSymbol(symbol string) (CurrencyPair, string) {
+	pair, contractType := AdaptWsSymbol(symbol)
+	return s.symbolMapper.PairOf(symbol, func(string) CurrencyPair { return pair }), contractType
 }
 
 func (s *SwapWs) DepthCallback(f func(depth *Depth)) {
@@ -82,39 +120,75 @@ func (s *SwapWs) TradeCallback(f func(trade *Trade, contract string)) {
 	panic("implement me")
 }
 
-func (s *SwapWs) SubscribeDepth(pair CurrencyPair, contractType string) error {
-	//{"op": "subscribe", "args": ["orderBook10:XBTUSD"]}
-	s.connect()
+//topic同时作为订阅的Key，订阅回应中原样返回
+func (s *SwapWs) subscribe(topic string) error {
+	if err := s.connect(); err != nil {
+		return err
+	}
+	return s.c.SubscribeWithId(topic, topic, SubscribeOp{Op: "subscribe", Args: []string{topic}})
+}
 
-	op := SubscribeOp{
-		Op: "subscribe",
-		Args: []string{
-			fmt.Sprintf("orderBook10:%s", AdaptCurrencyPairToSymbol(pair, contractType)),
-		},
+func (s *SwapWs) unsubscribe(topic string) error {
+	if s.c == nil {
+		return nil
 	}
-	return s.c.Subscribe(op)
+	return s.c.Unsubscribe(topic, SubscribeOp{Op: "unsubscribe", Args: []string{topic}})
 }
 
-func (s *SwapWs) SubscribeTicker(pair CurrencyPair, contractType string) error {
-	s.connect()
+func (s *SwapWs) SubscribeDepth(pair CurrencyPair, contractType string) error {
+	//{"op": "subscribe", "args": ["orderBook10:XBTUSD"]}
+	return s.subscribe(fmt.Sprintf("orderBook10:%s", AdaptCurrencyPairToSymbol(pair, contractType)))
+}
 
-	return s.c.Subscribe(SubscribeOp{
-		Op: "subscribe",
-		Args: []string{
-			"instrument:" + AdaptCurrencyPairToSymbol(pair, contractType),
-		},
-	})
+func (s *SwapWs) SubscribeTicker(pair CurrencyPair, contractType string) error {
+	return s.subscribe("instrument:" + AdaptCurrencyPairToSymbol(pair, contractType))
 }
 
 func (s *SwapWs) SubscribeTrade(pair CurrencyPair, contractType string) error {
 	panic("implement me")
 }
 
+func (s *SwapWs) UnsubscribeDepth(pair CurrencyPair, contractType string) error {
+	return s.unsubscribe(fmt.Sprintf("orderBook10:%s", AdaptCurrencyPairToSymbol(pair, contractType)))
+}
+
+func (s *SwapWs) UnsubscribeTicker(pair CurrencyPair, contractType string) error {
+	return s.unsubscribe("instrument:" + AdaptCurrencyPairToSymbol(pair, contractType))
+}
+
+func (s *SwapWs) UnsubscribeTrade(pair CurrencyPair, contractType string) error {
+	return s.unsubscribe("trade:" + AdaptCurrencyPairToSymbol(pair, contractType))
+}
+
+//不是订阅回应时返回false
+func (s *SwapWs) ackSubscription(data []byte) bool {
+	var r subscribeResp
+	if json.Unmarshal(data, &r) != nil {
+		return false
+	}
+
+	switch {
+	case r.Success && r.Subscribe != "":
+		s.c.AckSubscription(r.Subscribe, nil)
+	case r.Error != "" && r.Request.Op == "subscribe":
+		for _, topic := range r.Request.Args {
+			s.c.AckSubscription(topic, API_ERR.Wrap(strconv.Itoa(r.Status), r.Error))
+		}
+	default:
+		return r.Success || r.Error != ""
+	}
+	return true
+}
+
 func (s *SwapWs) handle(data []byte) error {
 	if string(data) == "pong" {
 		return nil
 	}
 
+	if s.ackSubscription(data) {
+		return nil
+	}
+
 	var msg wsMessage
 	err := json.Unmarshal(data, &msg)
 	if err != nil {
@@ -143,25 +217,19 @@ func (s *SwapWs) handle(data []byte) error {
 			logger.Warn("depth data len==0 ??")
 			return nil
 		}
+		//orderBook10每次推送前10档全量
+		ob := orderbook.NewOrderBook(nil)
+		ob.Snapshot(&orderbook.Snapshot{
+			Asks: adaptDepthLevels(depthData[0].Asks),
+			Bids: adaptDepthLevels(depthData[0].Bids),
+		})
+		book := ob.Depth(10)
+
 		dep.Timestamp = time.Now().Unix()
 		//dep.UTime, _ = time.Parse(time.RFC3339, depthData[0].Timestamp)
-		dep.Pair, dep.ContractType = AdaptWsSymbol(depthData[0].Symbol)
-
-		for _, item := range depthData[0].Bids {
-			dep.BidList = append(dep.BidList, DepthRecord{
-				Price:  ToFloat64(item[0]),
-				Amount: ToFloat64(item[1]),
-			})
-		}
-
-		for _, item := range depthData[0].Asks {
-			dep.AskList = append(dep.AskList, DepthRecord{
-				Price:  ToFloat64(item[0]),
-				Amount: ToFloat64(item[1]),
-			})
-		}
-
-		sort.Sort(sort.Reverse(dep.AskList))
+		dep.Pair, dep.ContractType = s.adaptSymbol(depthData[0].Symbol)
+		dep.AskList = book.AskList
+		dep.BidList = book.BidList
 
 		s.depthCall(&dep)
 	case "instrument":
@@ -176,7 +244,7 @@ func (s *SwapWs) handle(data []byte) error {
 		if msg.Action == "partial" {
 			ticker := s.tickerCacheMap[tickerData[0].Symbol]
 			ticker.Ticker = new(Ticker)
-			ticker.Pair, ticker.ContractType = AdaptWsSymbol(tickerData[0].Symbol)
+			ticker.Pair, ticker.ContractType = s.adaptSymbol(tickerData[0].Symbol)
 			ticker.Vol = tickerData[0].HomeNotional24h
 			ticker.Last = tickerData[0].LastPrice
 			ticker.Sell = tickerData[0].AskPrice
@@ -229,3 +297,17 @@ func (s *SwapWs) handle(data []byte) error {
 
 	return nil
 }
+
+func adaptDepthLevels(items [][]interface{}) []orderbook.Level {
+	levels := make([]orderbook.Level, 0, len(items))
+	for _, item := range items {
+		if len(item) < 2 {
+			continue
+		}
+		levels = append(levels, orderbook.Level{
+			Price:  strconv.FormatFloat(ToFloat64(item[0]), 'f', -1, 64),
+			Amount: strconv.FormatFloat(ToFloat64(item[1]), 'f', -1, 64),
+		})
+	}
+	return levels
+}
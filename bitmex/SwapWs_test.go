@@ -2,12 +2,14 @@ package bitmex
 
 import (
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 	"os"
 	"testing"
 	"time"
 )
 
 func TestNewSwapWs(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	os.Setenv("HTTPS_PROXY", "socks5://127.0.0.1:1080")
 	ws := NewSwapWs()
 	ws.DepthCallback(func(depth *goex.Depth) {
@@ -21,3 +23,16 @@ func TestNewSwapWs(t *testing.T) {
 
 	time.Sleep(5 * time.Minute)
 }
+
+//连接失败时订阅返回错误，不panic
+func TestSwapWs_SubscribeConnectError(t *testing.T) {
+	ws := NewSwapWs()
+	ws.wsBuilder.WsUrl("ws://127.0.0.1:1/realtime")
+
+	if err := ws.SubscribeTicker(goex.BTC_USD, goex.SWAP_CONTRACT); err == nil {
+		t.Fatal("expect connect error")
+	}
+	if ws.c != nil {
+		t.Fatal("conn should be nil")
+	}
+}
@@ -0,0 +1,181 @@
+package bitmex
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/mrwill84/goex"
+)
+
+var (
+	_ FutureTriggerOrderAPI = (*bitmex)(nil)
+)
+
+var triggerOrdTypes = map[TriggerOrderType]string{
+	TRIGGER_STOP_MARKET:        "Stop",
+	TRIGGER_STOP_LIMIT:         "StopLimit",
+	TRIGGER_TAKE_PROFIT_MARKET: "MarketIfTouched",
+	TRIGGER_TAKE_PROFIT_LIMIT:  "LimitIfTouched",
+	TRIGGER_TRAILING_STOP:      "Stop",
+}
+
+type bitmexTriggerOrder struct {
+	OrderID        string    `json:"orderID"`
+	ClOrdID        string    `json:"clOrdID"`
+	Symbol         string    `json:"symbol"`
+	Side           string    `json:"side"`
+	OrderQty       float64   `json:"orderQty"`
+	Price          float64   `json:"price"`
+	StopPx         float64   `json:"stopPx"`
+	PegPriceType   string    `json:"pegPriceType"`
+	PegOffsetValue float64   `json:"pegOffsetValue"`
+	OrdType        string    `json:"ordType"`
+	ExecInst       string    `json:"execInst"`
+	OrdStatus      string    `json:"ordStatus"`
+	Triggered      string    `json:"triggered"` //已触发为StopOrderTriggered
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+//bitmex只有单向持仓，平仓单使用ReduceOnly；跟踪止损按当前最新价把回调比例换算为价格偏移，不支持激活价格
+func (bm *bitmex) PlaceTriggerOrder(ord *TriggerOrder) (*TriggerOrder, error) {
+	ordType, ok := triggerOrdTypes[ord.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown trigger order type %s", ord.Type)
+	}
+
+	if ord.ClientOid == "" {
+		ord.ClientOid = NewClientOrderId(BITMEX)
+	}
+
+	param := map[string]interface{}{
+		"symbol":  bm.adaptCurrencyPairToSymbol(ord.Currency, ord.ContractType),
+		"clOrdID": ord.ClientOid,
+		"ordType": ordType,
+		"text":    "github.com/mrwill84/goex/tree/master/bitmex",
+	}
+
+	side := "Sell"
+	if ord.OType == OPEN_BUY || ord.OType == CLOSE_SELL {
+		side = "Buy"
+	}
+	param["side"] = side
+
+	if ord.Type == TRIGGER_TRAILING_STOP {
+		if ord.TriggerPrice > 0 {
+			return nil, EX_ERR_NOT_SUPPORTED.Wrap("", "trailing stop activation price")
+		}
+		ticker, err := bm.GetFutureTicker(ord.Currency, ord.ContractType)
+		if err != nil {
+			return nil, err
+		}
+		offset := ord.Currency.RoundPrice(NewDecimalFromFloat(ticker.Last * ord.CallbackRate)).Float64()
+		if side == "Sell" {
+			offset = -offset
+		}
+		param["pegPriceType"] = "TrailingStopPeg"
+		param["pegOffsetValue"] = offset
+	} else {
+		param["stopPx"] = ord.TriggerPrice
+	}
+
+	if ord.Type == TRIGGER_STOP_LIMIT || ord.Type == TRIGGER_TAKE_PROFIT_LIMIT {
+		param["price"] = ord.Price
+	}
+
+	if ord.ClosePosition {
+		param["execInst"] = "Close"
+	} else {
+		param["orderQty"] = ord.Amount
+		if ord.ReduceOnly || ord.OType == CLOSE_BUY || ord.OType == CLOSE_SELL {
+			param["execInst"] = "ReduceOnly"
+		}
+	}
+
+	var response bitmexTriggerOrder
+	err := bm.doAuthRequest("POST", "/api/v1/order", bm.toJson(param), &response)
+	if err != nil {
+		return nil, err
+	}
+
+	placed := *ord
+	placed.OrderId = response.OrderID
+	placed.Status = ORDER_UNFINISH
+	placed.OrderTime = response.Timestamp.UnixNano() / int64(time.Millisecond)
+	return &placed, nil
+}
+
+func (bm *bitmex) CancelTriggerOrder(currencyPair CurrencyPair, contractType, orderId string) (bool, error) {
+	return bm.FutureCancelOrder(currencyPair, contractType, orderId)
+}
+
+func (bm *bitmex) GetUnfinishTriggerOrders(currencyPair CurrencyPair, contractType string) ([]TriggerOrder, error) {
+	query := url.Values{}
+	query.Set("symbol", bm.adaptCurrencyPairToSymbol(currencyPair, contractType))
+	query.Set("filter", `{"open":true}`)
+
+	var response []bitmexTriggerOrder
+	err := bm.doAuthRequest("GET", "/api/v1/order?"+query.Encode(), "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var orders []TriggerOrder
+	for _, o := range response {
+		if o.Triggered != "" || o.StopPx == 0 && o.PegPriceType != "TrailingStopPeg" {
+			continue
+		}
+		orders = append(orders, bm.adaptTriggerOrder(o, currencyPair, contractType))
+	}
+	return orders, nil
+}
+
+func (bm *bitmex) adaptTriggerOrder(o bitmexTriggerOrder, currencyPair CurrencyPair, contractType string) TriggerOrder {
+	ord := TriggerOrder{
+		OrderId:       o.OrderID,
+		ClientOid:     o.ClOrdID,
+		Currency:      currencyPair,
+		ContractType:  contractType,
+		Amount:        o.OrderQty,
+		TriggerPrice:  o.StopPx,
+		Price:         o.Price,
+		ReduceOnly:    strings.Contains(o.ExecInst, "ReduceOnly"),
+		ClosePosition: strings.Contains(o.ExecInst, "Close"),
+		Status:        ORDER_UNFINISH,
+		OrderTime:     o.Timestamp.UnixNano() / int64(time.Millisecond),
+	}
+
+	switch o.OrdType {
+	case "StopLimit":
+		ord.Type = TRIGGER_STOP_LIMIT
+	case "MarketIfTouched":
+		ord.Type = TRIGGER_TAKE_PROFIT_MARKET
+	case "LimitIfTouched":
+		ord.Type = TRIGGER_TAKE_PROFIT_LIMIT
+	default:
+		ord.Type = TRIGGER_STOP_MARKET
+	}
+	if o.PegPriceType == "TrailingStopPeg" {
+		ord.Type = TRIGGER_TRAILING_STOP
+		ord.TriggerPrice = 0
+		if o.StopPx > 0 {
+			//stopPx为当前的触发价，偏移量换算回调比例
+			ord.CallbackRate = math.Abs(o.PegOffsetValue) / (o.StopPx - o.PegOffsetValue)
+		}
+	}
+
+	reduce := ord.ReduceOnly || ord.ClosePosition
+	switch {
+	case o.Side == "Buy" && reduce:
+		ord.OType = CLOSE_SELL
+	case o.Side == "Sell" && reduce:
+		ord.OType = CLOSE_BUY
+	case o.Side == "Sell":
+		ord.OType = OPEN_SELL
+	default:
+		ord.OType = OPEN_BUY
+	}
+	return ord
+}
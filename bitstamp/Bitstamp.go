@@ -44,6 +44,10 @@ type Bitstamp struct {
 	secretkey string
 }
 
+var (
+	_ API = (*Bitstamp)(nil)
+)
+
 func NewBitstamp(client *http.Client, accessKey, secertkey, clientId string) *Bitstamp {
 	return &Bitstamp{client: client, accessKey: accessKey, secretkey: secertkey, clientId: clientId}
 }
@@ -135,12 +139,26 @@ func (bitstamp *Bitstamp) GetAccount() (*Account, error) {
 	return &acc, nil
 }
 
-func (bitstamp *Bitstamp) placeOrder(side string, pair CurrencyPair, amount, price, urlStr string) (*Order, error) {
+func (bitstamp *Bitstamp) placeOrder(side string, pair CurrencyPair, amount, price, urlStr string, opt ...LimitOrderOptionalParameter) (*Order, error) {
 	params := url.Values{}
 	params.Set("amount", amount)
 	if price != "" {
 		params.Set("price", price)
 	}
+	cid := GetClientOrderId(opt...)
+	if cid != "" {
+		params.Set("client_order_id", cid)
+	}
+	if tif, ok := GetTimeInForce(opt...); ok {
+		switch tif {
+		case PostOnly:
+			params.Set("moc_order", "True")
+		case Ioc:
+			params.Set("ioc_order", "True")
+		case Fok:
+			params.Set("fok_order", "True")
+		}
+	}
 	bitstamp.buildPostForm(&params)
 
 	resp, err := HttpPostForm(bitstamp.client, urlStr, params)
@@ -171,6 +189,7 @@ func (bitstamp *Bitstamp) placeOrder(side string, pair CurrencyPair, amount, pri
 
 	return &Order{
 		Currency:   pair,
+		Cid:        cid,
 		OrderID:    ToInt(orderId),
 		OrderID2:   orderId,
 		Price:      ToFloat64(orderprice),
@@ -182,10 +201,10 @@ func (bitstamp *Bitstamp) placeOrder(side string, pair CurrencyPair, amount, pri
 		OrderTime:  1}, nil
 }
 
-func (bitstamp *Bitstamp) placeLimitOrder(side string, pair CurrencyPair, amount, price string) (*Order, error) {
+func (bitstamp *Bitstamp) placeLimitOrder(side string, pair CurrencyPair, amount, price string, opt ...LimitOrderOptionalParameter) (*Order, error) {
 	urlStr := fmt.Sprintf("%sv2/%s/%s/", BASE_URL, side, strings.ToLower(pair.ToSymbol("")))
 	//println(urlStr)
-	return bitstamp.placeOrder(side, pair, amount, price, urlStr)
+	return bitstamp.placeOrder(side, pair, amount, price, urlStr, opt...)
 }
 
 func (bitstamp *Bitstamp) placeMarketOrder(side string, pair CurrencyPair, amount string) (*Order, error) {
@@ -195,11 +214,11 @@ func (bitstamp *Bitstamp) placeMarketOrder(side string, pair CurrencyPair, amoun
 }
 
 func (bitstamp *Bitstamp) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
-	return bitstamp.placeLimitOrder("buy", currency, amount, price)
+	return bitstamp.placeLimitOrder("buy", currency, amount, price, opt...)
 }
 
 func (bitstamp *Bitstamp) LimitSell(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
-	return bitstamp.placeLimitOrder("sell", currency, amount, price)
+	return bitstamp.placeLimitOrder("sell", currency, amount, price, opt...)
 }
 
 func (bitstamp *Bitstamp) MarketBuy(amount, price string, currency CurrencyPair) (*Order, error) {
@@ -387,7 +406,7 @@ func (bitstamp *Bitstamp) GetDepth(size int, currency CurrencyPair) (*Depth, err
 	dep.Pair = currency
 	for _, v := range bids {
 		bid := v.([]interface{})
-		dep.BidList = append(dep.BidList, DepthRecord{ToFloat64(bid[0]), ToFloat64(bid[1])})
+		dep.BidList = append(dep.BidList, DepthRecord{Price: ToFloat64(bid[0]), Amount: ToFloat64(bid[1])})
 		i++
 		if i == size {
 			break
@@ -397,7 +416,7 @@ func (bitstamp *Bitstamp) GetDepth(size int, currency CurrencyPair) (*Depth, err
 	i = 0
 	for _, v := range asks {
 		ask := v.([]interface{})
-		dep.AskList = append(dep.AskList, DepthRecord{ToFloat64(ask[0]), ToFloat64(ask[1])})
+		dep.AskList = append(dep.AskList, DepthRecord{Price: ToFloat64(ask[0]), Amount: ToFloat64(ask[1])})
 		i++
 		if i == size {
 			break
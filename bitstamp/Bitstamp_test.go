@@ -2,6 +2,7 @@ package bitstamp
 
 import (
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 	"github.com/stretchr/testify/assert"
 	"log"
 	"net/http"
@@ -17,18 +18,21 @@ var client = http.Client{
 var btmp = NewBitstamp(&client, "", "", "")
 
 func TestBitstamp_GetAccount(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	acc, err := btmp.GetAccount()
 	assert.Nil(t, err)
 	t.Log(acc)
 }
 
 func TestBitstamp_GetTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ticker, err := btmp.GetTicker(goex.BTC_USD)
 	assert.Nil(t, err)
 	t.Log(ticker)
 }
 
 func TestBitstamp_GetDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	dep, err := btmp.GetDepth(5, goex.BTC_USD)
 	assert.Nil(t, err)
 	t.Log(dep.BidList)
@@ -36,42 +40,49 @@ func TestBitstamp_GetDepth(t *testing.T) {
 }
 
 func TestBitstamp_LimitBuy(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ord, err := btmp.LimitBuy("55", "0.12", goex.XRP_USD)
 	assert.Nil(t, err)
 	t.Log(ord)
 }
 
 func TestBitstamp_LimitSell(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ord, err := btmp.LimitSell("40", "0.22", goex.XRP_USD)
 	assert.Nil(t, err)
 	t.Log(ord)
 }
 
 func TestBitstamp_MarketBuy(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ord, err := btmp.MarketBuy("1", "", goex.XRP_USD)
 	assert.Nil(t, err)
 	t.Log(ord)
 }
 
 func TestBitstamp_MarketSell(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ord, err := btmp.MarketSell("2", "", goex.XRP_USD)
 	assert.Nil(t, err)
 	t.Log(ord)
 }
 
 func TestBitstamp_CancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	r, err := btmp.CancelOrder("311242779", goex.XRP_USD)
 	assert.Nil(t, err)
 	t.Log(r)
 }
 
 func TestBitstamp_GetUnfinishOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ords, err := btmp.GetUnfinishOrders(goex.XRP_USD)
 	assert.Nil(t, err)
 	t.Log(ords)
 }
 
 func TestBitstamp_GetOneOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ord, err := btmp.GetOneOrder("311752078", goex.XRP_USD)
 	assert.Nil(t, err)
 	t.Log(ord)
@@ -15,6 +15,10 @@ type Bittrex struct {
 	secretkey string
 }
 
+var (
+	_ API = (*Bittrex)(nil)
+)
+
 func New(client *http.Client, accesskey, secretkey string) *Bittrex {
 	return &Bittrex{client: client, accesskey: accesskey, secretkey: secretkey, baseUrl: "https://bittrex.com/api/v1.1"}
 }
@@ -92,12 +96,12 @@ func (bx *Bittrex) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
 
 	for _, v := range bids {
 		r := v.(map[string]interface{})
-		dep.BidList = append(dep.BidList, DepthRecord{ToFloat64(r["Rate"]), ToFloat64(r["Quantity"])})
+		dep.BidList = append(dep.BidList, DepthRecord{Price: ToFloat64(r["Rate"]), Amount: ToFloat64(r["Quantity"])})
 	}
 
 	for _, v := range asks {
 		r := v.(map[string]interface{})
-		dep.AskList = append(dep.AskList, DepthRecord{ToFloat64(r["Rate"]), ToFloat64(r["Quantity"])})
+		dep.AskList = append(dep.AskList, DepthRecord{Price: ToFloat64(r["Rate"]), Amount: ToFloat64(r["Quantity"])})
 	}
 
 	sort.Sort(sort.Reverse(dep.AskList))
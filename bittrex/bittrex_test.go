@@ -2,6 +2,7 @@ package bittrex
 
 import (
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 	"net/http"
 	"testing"
 )
@@ -9,12 +10,14 @@ import (
 var b = New(http.DefaultClient, "", "")
 
 func TestBittrex_GetTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ticker, err := b.GetTicker(goex.BTC_USDT)
 	t.Log("err=>", err)
 	t.Log("ticker=>", ticker)
 }
 
 func TestBittrex_GetDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	dep, err := b.GetDepth(1, goex.BTC_USDT)
 	t.Log("err=>", err)
 	t.Log("ask=>", dep.AskList)
@@ -138,8 +138,9 @@ func (builder *APIBuilder) HttpProxy(proxyUrl string) (_builder *APIBuilder) {
 		return builder
 	}
 	builder.HttpClientConfig.Proxy = proxy
-	transport := builder.client.Transport.(*http.Transport)
-	transport.Proxy = http.ProxyURL(proxy)
+	if transport := builder.httpTransport(); transport != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
 	return builder
 }
 
@@ -147,7 +148,7 @@ func (builder *APIBuilder) HttpTimeout(timeout time.Duration) (_builder *APIBuil
 	builder.HttpClientConfig.HttpTimeout = timeout
 	builder.httpTimeout = timeout
 	builder.client.Timeout = timeout
-	transport := builder.client.Transport.(*http.Transport)
+	transport := builder.httpTransport()
 	if transport != nil {
 		//transport.ResponseHeaderTimeout = timeout
 		//transport.TLSHandshakeTimeout = timeout
@@ -159,6 +160,28 @@ func (builder *APIBuilder) HttpTimeout(timeout time.Duration) (_builder *APIBuil
 	return builder
 }
 
+//所有由该builder构建的api共享同一个限频器，如:
+//builder.RateLimiter(NewRateLimiter(append(binance.RateLimitRules, okexV5.RateLimitRules...)...))
+func (builder *APIBuilder) RateLimiter(limiter RateLimiter) (_builder *APIBuilder) {
+	transport := builder.client.Transport
+	if t, ok := transport.(*RateLimitTransport); ok {
+		transport = t.Base
+	}
+	builder.client.Transport = NewRateLimitTransport(transport, limiter)
+	return builder
+}
+
+func (builder *APIBuilder) httpTransport() *http.Transport {
+	transport := builder.client.Transport
+	if t, ok := transport.(*RateLimitTransport); ok {
+		transport = t.Base
+	}
+	if t, ok := transport.(*http.Transport); ok {
+		return t
+	}
+	return nil
+}
+
 func (builder *APIBuilder) APIKey(key string) (_builder *APIBuilder) {
 	builder.apiKey = key
 	return builder
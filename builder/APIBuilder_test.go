@@ -1,10 +1,15 @@
 package builder
 
 import (
+	"context"
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/binance"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/internal/replay"
+	okexV5 "github.com/mrwill84/goex/okex/v5"
 	"github.com/stretchr/testify/assert"
 	"log"
+	"net/http"
 	"testing"
 	"time"
 )
@@ -16,6 +21,7 @@ func init() {
 }
 
 func TestAPIBuilder_Build(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	assert.Equal(t, builder.APIKey("").APISecretkey("").Build(goex.OKCOIN_COM).GetExchangeName(), goex.OKCOIN_COM)
 	assert.Equal(t, builder.APIKey("").APISecretkey("").Build(goex.HUOBI_PRO).GetExchangeName(), goex.HUOBI_PRO)
 	assert.Equal(t, builder.APIKey("").APISecretkey("").Build(goex.ZB).GetExchangeName(), goex.ZB)
@@ -28,6 +34,7 @@ func TestAPIBuilder_Build(t *testing.T) {
 }
 
 func TestAPIBuilder_BuildSpotWs(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	//os.Setenv("HTTPS_PROXY" , "socks5://127.0.0.1:1080")
 	wsApi, _ := builder.BuildSpotWs(goex.OKEX_V3)
 	wsApi.DepthCallback(func(depth *goex.Depth) {
@@ -38,6 +45,7 @@ func TestAPIBuilder_BuildSpotWs(t *testing.T) {
 }
 
 func TestAPIBuilder_BuildFuturesWs(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	//os.Setenv("HTTPS_PROXY" , "socks5://127.0.0.1:1080")
 	wsApi, _ := builder.BuildFuturesWs(goex.OKEX_V3)
 	wsApi.DepthCallback(func(depth *goex.Depth) {
@@ -46,3 +54,38 @@ func TestAPIBuilder_BuildFuturesWs(t *testing.T) {
 	wsApi.SubscribeDepth(goex.BTC_USD, goex.QUARTER_CONTRACT)
 	time.Sleep(time.Minute)
 }
+
+//binance和okex的规则合并使用时，按Host互不影响
+func TestAPIBuilder_RateLimiterMixedRules(t *testing.T) {
+	limiter := goex.NewRateLimiter(append(binance.RateLimitRules, okexV5.RateLimitRules...)...)
+
+	wait := func(method, url string, timeout time.Duration) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		req, _ := http.NewRequest(method, url, nil)
+		return limiter.Wait(ctx, req)
+	}
+
+	//okex /api/v5 的请求不消耗binance现货/api/的权重
+	for i := 0; i < 3000; i++ {
+		if err := wait(http.MethodGet, "https://www.okex.com/api/v5/market/history-trades", time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wait(http.MethodGet, "https://api.binance.com/api/v3/time", 10*time.Millisecond); err != nil {
+		t.Fatal("binance spot limited by okex requests", err)
+	}
+
+	//okex自己的规则仍然生效
+	for i := 0; i < 10; i++ {
+		if err := wait(http.MethodGet, "https://www.okex.com/api/v5/account/balance", time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wait(http.MethodGet, "https://www.okex.com/api/v5/account/balance", 10*time.Millisecond); err != context.DeadlineExceeded {
+		t.Fatal("okex account balance should be limited", err)
+	}
+	if err := wait(http.MethodGet, "https://fapi.binance.com/fapi/v1/account", 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+}
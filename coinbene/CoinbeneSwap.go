@@ -38,6 +38,10 @@ type CoinbeneSwap struct {
 	config APIConfig
 }
 
+var (
+	_ FutureRestAPI = (*CoinbeneSwap)(nil)
+)
+
 func (swap *CoinbeneSwap) GetFutureOrderHistory(pair CurrencyPair, contractType string, optional ...OptionalParameter) ([]FutureOrder, error) {
 	panic("implement me")
 }
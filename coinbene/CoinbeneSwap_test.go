@@ -8,6 +8,7 @@ import (
 	"time"
 
 	goex "github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var (
@@ -33,33 +34,41 @@ var (
 )
 
 func TestCoinbeneSwap_GetFutureTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(coinbeneSwap.GetFutureTicker(goex.BTC_USD, goex.SWAP_CONTRACT))
 }
 
 func TestCoinbeneSwap_GetFutureDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(coinbeneSwap.GetFutureDepth(goex.BTC_USDT, goex.SWAP_CONTRACT, 2))
 }
 
 func TestCoinbeneSwap_GetFutureUserinfo(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(coinbeneSwap.GetFutureUserinfo())
 }
 
 func TestCoinbeneSwap_GetFuturePosition(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(coinbeneSwap.GetFuturePosition(goex.BTC_USDT, goex.SWAP_CONTRACT))
 }
 
 func TestCoinbeneSwap_PlaceFutureOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(coinbeneSwap.PlaceFutureOrder(goex.BTC_USDT, goex.SWAP_CONTRACT, "10000", "1", goex.OPEN_BUY, 0, 10))
 }
 
 func TestCoinbeneSwap_FutureCancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(coinbeneSwap.FutureCancelOrder(goex.BTC_USDT, goex.SWAP_CONTRACT, "580719990266232832"))
 }
 
 func TestCoinbeneSwap_GetUnfinishFutureOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(coinbeneSwap.GetUnfinishFutureOrders(goex.BTC_USDT, goex.SWAP_CONTRACT))
 }
 
 func TestCoinbeneSwap_GetFutureOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(coinbeneSwap.GetFutureOrder("123", goex.BTC_USDT, goex.SWAP_CONTRACT))
 }
@@ -27,6 +27,10 @@ type CoinBig struct {
 	timeoffset int64
 }
 
+var (
+	_ API = (*CoinBig)(nil)
+)
+
 func New(client *http.Client, api_key, secret_key string) *CoinBig {
 	return &CoinBig{accessKey: api_key, secretKey: secret_key, httpClient: client}
 }
@@ -444,7 +448,7 @@ func (cb *CoinBig) GetServerSync() error {
 
 }
 
-func (cb *CoinBig) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
+func (cb *CoinBig) GetKlineRecords(currency CurrencyPair, period KlinePeriod, size int, optional ...OptionalParameter) ([]Kline, error) {
 	panic("not implement")
 }
 
@@ -2,6 +2,7 @@ package coinbig
 
 import (
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 	"net/http"
 	"testing"
 )
@@ -33,10 +34,12 @@ func TestCoinBig_BuildSigned(t *testing.T) {
 }
 
 func TestCoinBig_GetAccount(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	//return
 	t.Log(cb.GetAccount())
 }
 func TestCoinBig_GetUnfinishOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	//return
 	t.Log(cb.GetUnfinishOrders(goex.BTC_USDT))
 }
@@ -21,6 +21,10 @@ type CoinEx struct {
 	secretKey string
 }
 
+var (
+	_ API = (*CoinEx)(nil)
+)
+
 var (
 	baseurl = "https://api.coinex.com/v1/"
 )
@@ -73,12 +77,12 @@ func (coinex *CoinEx) GetDepth(size int, currency CurrencyPair) (*Depth, error)
 
 	for _, v := range asks {
 		r := v.([]interface{})
-		dep.AskList = append(dep.AskList, DepthRecord{ToFloat64(r[0]), ToFloat64(r[1])})
+		dep.AskList = append(dep.AskList, DepthRecord{Price: ToFloat64(r[0]), Amount: ToFloat64(r[1])})
 	}
 
 	for _, v := range bids {
 		r := v.([]interface{})
-		dep.BidList = append(dep.BidList, DepthRecord{ToFloat64(r[0]), ToFloat64(r[1])})
+		dep.BidList = append(dep.BidList, DepthRecord{Price: ToFloat64(r[0]), Amount: ToFloat64(r[1])})
 	}
 
 	sort.Sort(sort.Reverse(dep.AskList))
@@ -86,12 +90,25 @@ func (coinex *CoinEx) GetDepth(size int, currency CurrencyPair) (*Depth, error)
 	return &dep, nil
 }
 
-func (coinex *CoinEx) placeLimitOrder(side, amount, price string, pair CurrencyPair) (*Order, error) {
+func (coinex *CoinEx) placeLimitOrder(side, amount, price string, pair CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
 	params := url.Values{}
 	params.Set("market", pair.ToSymbol(""))
 	params.Set("type", side)
 	params.Set("amount", amount)
 	params.Set("price", price)
+	if cid := GetClientOrderId(opt...); cid != "" {
+		params.Set("client_id", cid)
+	}
+	if tif, ok := GetTimeInForce(opt...); ok {
+		switch tif {
+		case PostOnly:
+			params.Set("option", "MAKER_ONLY")
+		case Ioc:
+			params.Set("option", "IOC")
+		case Fok:
+			params.Set("option", "FOK")
+		}
+	}
 
 	retmap, err := coinex.doRequest("POST", "order/limit", &params)
 	if err != nil {
@@ -104,11 +121,11 @@ func (coinex *CoinEx) placeLimitOrder(side, amount, price string, pair CurrencyP
 }
 
 func (coinex *CoinEx) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
-	return coinex.placeLimitOrder("buy", amount, price, currency)
+	return coinex.placeLimitOrder("buy", amount, price, currency, opt...)
 }
 
 func (coinex *CoinEx) LimitSell(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
-	return coinex.placeLimitOrder("sell", amount, price, currency)
+	return coinex.placeLimitOrder("sell", amount, price, currency, opt...)
 }
 
 func (coinex *CoinEx) MarketBuy(amount, price string, currency CurrencyPair) (*Order, error) {
@@ -330,8 +347,10 @@ func (coinex *CoinEx) adaptTradeStatus(status string) TradeStatus {
 }
 
 func (coinex *CoinEx) adaptOrder(ordermap map[string]interface{}, pair CurrencyPair) Order {
+	cid, _ := ordermap["client_id"].(string)
 	return Order{
 		Currency:   pair,
+		Cid:        cid,
 		OrderID:    ToInt(ordermap["id"]),
 		OrderID2:   fmt.Sprint(ToInt(ordermap["id"])),
 		Amount:     ToFloat64(ordermap["amount"]),
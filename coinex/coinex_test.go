@@ -6,17 +6,20 @@ import (
 	"testing"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var coinex = New(http.DefaultClient, "", "")
 
 func TestCoinEx_GetTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ticker, err := coinex.GetTicker(goex.LTC_BTC)
 	t.Log(err)
 	t.Log(ticker)
 }
 
 func TestCoinEx_GetDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	dep, err := coinex.GetDepth(5, goex.LTC_BTC)
 	t.Log(err)
 	t.Log(dep.AskList)
@@ -24,6 +27,7 @@ func TestCoinEx_GetDepth(t *testing.T) {
 }
 
 func TestCoinEx_GetAccount(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	//os.Setenv("https_proxy", "http://120.27.230.57:30000")
 	acc, err := coinex.GetAccount()
 	t.Log(err)
@@ -35,23 +39,27 @@ func TestCoinEx_LimitBuy(t *testing.T) {
 }
 
 func TestCoinEx_LimitSell(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ord, err := coinex.LimitSell("100", "0.0000601", goex.NewCurrencyPair2("CET_BCH"))
 	t.Log(err)
 	t.Log(ord)
 }
 
 func TestCoinEx_GetUnfinishOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ords, err := coinex.GetUnfinishOrders(goex.NewCurrencyPair2("CET_BCH"))
 	t.Log(err)
 	t.Log(fmt.Sprint(ords[0].OrderID))
 }
 
 func TestCoinEx_CancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	r, err := coinex.CancelOrder("37504128", goex.NewCurrencyPair2("CET_BCH"))
 	t.Log(r, err)
 }
 
 func TestCoinEx_GetOneOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ord, err := coinex.GetOneOrder("37504128", goex.NewCurrencyPair2("CET_BCH"))
 	t.Log(err)
 	t.Log(ord)
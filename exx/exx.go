@@ -37,6 +37,10 @@ type Exx struct {
 	secretKey string
 }
 
+var (
+	_ API = (*Exx)(nil)
+)
+
 func New(httpClient *http.Client, accessKey, secretKey string) *Exx {
 	return &Exx{httpClient, accessKey, secretKey}
 }
@@ -383,7 +387,7 @@ func (exx *Exx) GetOrderHistorys(currency CurrencyPair, optional ...OptionalPara
 	return nil, nil
 }
 
-func (exx *Exx) GetKlineRecords(currency CurrencyPair, period, size, since int) ([]Kline, error) {
+func (exx *Exx) GetKlineRecords(currency CurrencyPair, period KlinePeriod, size int, optional ...OptionalParameter) ([]Kline, error) {
 	return nil, nil
 }
 
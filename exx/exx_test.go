@@ -2,6 +2,7 @@ package exx
 
 import (
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 	"net/http"
 	"net/url"
 	"testing"
@@ -23,6 +24,7 @@ func TestExx_Signed(t *testing.T) {
 }
 
 func TestExx_GetAccount(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	//return
 	acc, err := exx.GetAccount()
 	t.Log(acc, err)
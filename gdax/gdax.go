@@ -20,6 +20,10 @@ type Gdax struct {
 	secretKey string
 }
 
+var (
+	_ API = (*Gdax)(nil)
+)
+
 func New(client *http.Client, accesskey, secretkey string) *Gdax {
 	return &Gdax{client, "https://api.gdax.com", accesskey, secretkey}
 }
@@ -103,12 +107,12 @@ func (g *Gdax) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
 
 	for _, v := range bids {
 		r := v.([]interface{})
-		dep.BidList = append(dep.BidList, DepthRecord{ToFloat64(r[0]), ToFloat64(r[1])})
+		dep.BidList = append(dep.BidList, DepthRecord{Price: ToFloat64(r[0]), Amount: ToFloat64(r[1])})
 	}
 
 	for _, v := range asks {
 		r := v.([]interface{})
-		dep.AskList = append(dep.AskList, DepthRecord{ToFloat64(r[0]), ToFloat64(r[1])})
+		dep.AskList = append(dep.AskList, DepthRecord{Price: ToFloat64(r[0]), Amount: ToFloat64(r[1])})
 	}
 
 	sort.Sort(sort.Reverse(dep.AskList))
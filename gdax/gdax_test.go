@@ -6,23 +6,27 @@ import (
 
 	"github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var gdax = New(http.DefaultClient, "", "")
 
 func TestGdax_GetTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ticker, err := gdax.GetTicker(goex.BTC_USD)
 	t.Log("err=>", err)
 	t.Log("ticker=>", ticker)
 }
 
 func TestGdax_Get24HStats(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	stats, err := gdax.Get24HStats(goex.BTC_USD)
 	t.Log("err=>", err)
 	t.Log("stats=>", stats)
 }
 
 func TestGdax_GetDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	dep, err := gdax.GetDepth(2, goex.BTC_USD)
 	t.Log("err=>", err)
 	t.Log("bids=>", dep.BidList)
@@ -30,6 +34,7 @@ func TestGdax_GetDepth(t *testing.T) {
 }
 
 func TestGdax_GetKlineRecords(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	logger.SetLevel(logger.DEBUG)
-	t.Log(gdax.GetKlineRecords(goex.BTC_USD, goex.KLINE_PERIOD_1DAY, 0, 0))
+	t.Log(gdax.GetKlineRecords(goex.BTC_USD, goex.KLINE_PERIOD_1DAY, 0))
 }
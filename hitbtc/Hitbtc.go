@@ -42,6 +42,10 @@ type Hitbtc struct {
 	httpClient *http.Client
 }
 
+var (
+	_ goex.API = (*Hitbtc)(nil)
+)
+
 func New(client *http.Client, accessKey, secretKey string) *Hitbtc {
 	return &Hitbtc{accessKey, secretKey, client}
 }
@@ -153,9 +157,12 @@ curl -X PUT -u "ff20f250a7b3a414781d1abe11cd8cee:fb453577d11294359058a9ae13c9471
         "updatedAt": "2017-05-15T17:01:05.092Z"
     }
 */
-func (hitbtc *Hitbtc) placeOrder(ty goex.TradeSide, amount, price string, currency goex.CurrencyPair) (*goex.Order, error) {
+func (hitbtc *Hitbtc) placeOrder(ty goex.TradeSide, amount, price string, currency goex.CurrencyPair, opt ...goex.LimitOrderOptionalParameter) (*goex.Order, error) {
 	postData := url.Values{}
 	postData.Set("symbol", currency.ToSymbol(""))
+	if cid := goex.GetClientOrderId(opt...); cid != "" {
+		postData.Set("clientOrderId", cid)
+	}
 	var side string
 	var orderType string
 	switch ty {
@@ -179,6 +186,16 @@ func (hitbtc *Hitbtc) placeOrder(ty goex.TradeSide, amount, price string, curren
 	postData.Set("quantity", amount)
 	if orderType == "limit" {
 		postData.Set("price", price)
+		if tif, ok := goex.GetTimeInForce(opt...); ok {
+			switch tif {
+			case goex.PostOnly:
+				postData.Set("postOnly", "true")
+			case goex.Ioc:
+				postData.Set("timeInForce", "IOC")
+			case goex.Fok:
+				postData.Set("timeInForce", "FOK")
+			}
+		}
 	}
 
 	reqUrl := API_BASE_URL + API_V2 + ORDER_URI
@@ -215,11 +232,11 @@ func (hitbtc *Hitbtc) placeOrder(ty goex.TradeSide, amount, price string, curren
 }
 
 func (hitbtc *Hitbtc) LimitBuy(amount, price string, currency goex.CurrencyPair, opt ...goex.LimitOrderOptionalParameter) (*goex.Order, error) {
-	return hitbtc.placeOrder(goex.BUY, amount, price, currency)
+	return hitbtc.placeOrder(goex.BUY, amount, price, currency, opt...)
 }
 
 func (hitbtc *Hitbtc) LimitSell(amount, price string, currency goex.CurrencyPair, opt ...goex.LimitOrderOptionalParameter) (*goex.Order, error) {
-	return hitbtc.placeOrder(goex.SELL, amount, price, currency)
+	return hitbtc.placeOrder(goex.SELL, amount, price, currency, opt...)
 }
 
 func (hitbtc *Hitbtc) MarketBuy(amount, price string, currency goex.CurrencyPair) (*goex.Order, error) {
@@ -559,6 +576,7 @@ func (hitbtc *Hitbtc) toOrder(resp map[string]interface{}) *goex.Order {
 		Amount:     goex.ToFloat64(resp["quantity"]),
 		DealAmount: goex.ToFloat64(resp["cumQuantity"]),
 		OrderID2:   resp["clientOrderId"].(string),
+		Cid:        resp["clientOrderId"].(string),
 		OrderID:    goex.ToInt(resp["id"]),
 		OrderTime:  int(parseTime(resp["createdAt"].(string))),
 		Status:     parseStatus(resp["status"].(string)),
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 	"github.com/stretchr/testify/require"
 )
 
@@ -20,6 +21,7 @@ func init() {
 }
 
 func TestHitbtc_GetSymbols(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(htb.GetSymbols())
 }
 
@@ -32,6 +34,7 @@ func TestHitbtc_adaptSymbolToCurrencyPair(t *testing.T) {
 }
 
 func TestGetTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	res, err := htb.GetTicker(goex.BCH_USD)
 	require := require.New(t)
 	require.Nil(err)
@@ -39,6 +42,7 @@ func TestGetTicker(t *testing.T) {
 }
 
 func TestGetAccount(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	res, err := htb.GetAccount()
 	require := require.New(t)
 	require.Nil(err)
@@ -46,6 +50,7 @@ func TestGetAccount(t *testing.T) {
 }
 
 func TestDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	res, err := htb.GetDepth(10, YCC_BTC)
 	require := require.New(t)
 	require.Nil(err)
@@ -53,6 +58,7 @@ func TestDepth(t *testing.T) {
 }
 
 func TestKline(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	res, err := htb.GetKline(YCC_BTC, "1M", 10, 0)
 	require := require.New(t)
 	require.Nil(err)
@@ -60,6 +66,7 @@ func TestKline(t *testing.T) {
 }
 
 func TestTrades(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	res, err := htb.GetTrades(YCC_BTC, 1519862400)
 	require := require.New(t)
 	require.Nil(err)
@@ -67,6 +74,7 @@ func TestTrades(t *testing.T) {
 }
 
 func TestPlaceOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	res, err := htb.LimitBuy("15", "0.000008", YCC_BTC)
 	require := require.New(t)
 	require.Nil(err)
@@ -74,6 +82,7 @@ func TestPlaceOrder(t *testing.T) {
 }
 
 func TestCancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	res, err := htb.CancelOrder("a605f2abbcc750da9138687bb27a2835", YCC_BTC)
 	require := require.New(t)
 	require.Nil(err)
@@ -81,6 +90,7 @@ func TestCancelOrder(t *testing.T) {
 }
 
 func TestGetOneOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	res, err := htb.GetOneOrder("177836e71c8d57a14648d465e893efce", YCC_BTC)
 	require := require.New(t)
 	require.Nil(err)
@@ -88,6 +98,7 @@ func TestGetOneOrder(t *testing.T) {
 }
 
 func TestGetOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	res, err := htb.GetOrderHistorys(YCC_BTC)
 	require := require.New(t)
 	require.Nil(err)
@@ -95,6 +106,7 @@ func TestGetOrders(t *testing.T) {
 }
 
 func TestGetUnfinishOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	res, err := htb.GetUnfinishOrders(YCC_BTC)
 	require := require.New(t)
 	require.Nil(err)
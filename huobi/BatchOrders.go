@@ -0,0 +1,361 @@
+package huobi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	. "github.com/mrwill84/goex"
+)
+
+const (
+	spotBatchPlaceSize  = 10 //现货批量下单每次最多10个订单
+	spotBatchCancelSize = 50 //现货批量撤单每次最多50个订单
+	hbdmBatchSize       = 10 //合约批量下单/撤单每次最多10个订单
+)
+
+//签名参数放在url上，body为json
+func (hbpro *HuoBiPro) doJsonRequest(path string, body interface{}, data interface{}) error {
+	params := url.Values{}
+	hbpro.buildPostForm("POST", path, &params)
+
+	reqBody, _ := json.Marshal(body)
+	resp, err := HttpPostForm3(hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode(), string(reqBody),
+		map[string]string{"Content-Type": "application/json", "Accept-Language": "zh-cn"})
+	if err != nil {
+		return err
+	}
+
+	respmap := make(map[string]interface{})
+	if err = json.Unmarshal(resp, &respmap); err != nil {
+		return err
+	}
+	if status, _ := respmap["status"].(string); status != "ok" {
+		return adaptSpotError(respmap)
+	}
+
+	var ret struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err = json.Unmarshal(resp, &ret); err != nil {
+		return err
+	}
+	return json.Unmarshal(ret.Data, data)
+}
+
+func spotOrderType(side TradeSide, orderType int) string {
+	prefix := "buy"
+	if side == SELL {
+		prefix = "sell"
+	}
+	switch orderType {
+	case ORDER_FEATURE_POST_ONLY:
+		return prefix + "-limit-maker"
+	case ORDER_FEATURE_FOK:
+		return prefix + "-limit-fok"
+	case ORDER_FEATURE_IOC:
+		return prefix + "-ioc"
+	default:
+		return prefix + "-limit"
+	}
+}
+
+//批量限价单，超过10个订单时分批请求
+func (hbpro *HuoBiPro) BatchLimitOrders(orders []Order) ([]BatchOrderResult, error) {
+	results := make([]BatchOrderResult, 0, len(orders))
+
+	for start := 0; start < len(orders); start += spotBatchPlaceSize {
+		end := start + spotBatchPlaceSize
+		if end > len(orders) {
+			end = len(orders)
+		}
+
+		//client-order-id用于匹配每个订单的结果
+		cids := make([]string, 0, end-start)
+		batch := make([]map[string]string, 0, end-start)
+		for _, ord := range orders[start:end] {
+			symbol := hbpro.Symbols[ord.Currency.ToLower().ToSymbol("")]
+			cid := ord.Cid
+			if cid == "" {
+				cid = NewClientOrderId(HUOBI_PRO)
+			}
+			cids = append(cids, cid)
+			batch = append(batch, map[string]string{
+				"account-id":      hbpro.accountId,
+				"client-order-id": cid,
+				"symbol":          ord.Currency.AdaptUsdToUsdt().ToLower().ToSymbol(""),
+				"type":            spotOrderType(ord.Side, ord.OrderType),
+				"amount":          ToDecimal(ord.Amount).Truncate(int32(symbol.AmountPrecision)).String(),
+				"price":           ToDecimal(ord.Price).Round(int32(symbol.PricePrecision)).String(),
+			})
+		}
+
+		var data []struct {
+			OrderId int64  `json:"order-id"`
+			Cid     string `json:"client-order-id"`
+			ErrCode string `json:"err-code"`
+			ErrMsg  string `json:"err-msg"`
+		}
+		if err := hbpro.doJsonRequest("/v1/order/batch-orders", batch, &data); err != nil {
+			//本批生成的client-order-id也要返回，用于查询订单是否已经生效
+			for i, ord := range orders[start:] {
+				cid := ord.Cid
+				if i < len(cids) {
+					cid = cids[i]
+				}
+				results = append(results, BatchOrderResult{Cid: cid, Err: err})
+			}
+			return results, err
+		}
+
+		byCid := make(map[string]BatchOrderResult, len(data))
+		for _, d := range data {
+			r := BatchOrderResult{Cid: d.Cid}
+			if d.ErrCode != "" {
+				r.Err = spotErrorCodeTable.Wrap(d.ErrCode, d.ErrMsg)
+			} else {
+				r.OrderId = strconv.FormatInt(d.OrderId, 10)
+			}
+			byCid[d.Cid] = r
+		}
+
+		for _, cid := range cids {
+			r, ok := byCid[cid]
+			if !ok {
+				r = BatchOrderResult{Cid: cid, Err: EX_ERR_PLACE_ORDER_FAIL.OriginErr("no result for client-order-id " + cid)}
+			}
+			results = append(results, r)
+		}
+	}
+
+	return results, nil
+}
+
+func (hbpro *HuoBiPro) BatchCancelOrders(currency CurrencyPair, orderIds []string) ([]BatchOrderResult, error) {
+	results := make([]BatchOrderResult, 0, len(orderIds))
+
+	for start := 0; start < len(orderIds); start += spotBatchCancelSize {
+		end := start + spotBatchCancelSize
+		if end > len(orderIds) {
+			end = len(orderIds)
+		}
+
+		var data struct {
+			Success []string `json:"success"`
+			Failed  []struct {
+				OrderId string `json:"order-id"`
+				ErrCode string `json:"err-code"`
+				ErrMsg  string `json:"err-msg"`
+			} `json:"failed"`
+		}
+		err := hbpro.doJsonRequest("/v1/order/orders/batchcancel", map[string]interface{}{"order-ids": orderIds[start:end]}, &data)
+		if err != nil {
+			for _, id := range orderIds[start:] {
+				results = append(results, BatchOrderResult{OrderId: id, Err: err})
+			}
+			return results, err
+		}
+
+		failed := make(map[string]error, len(data.Failed))
+		for _, f := range data.Failed {
+			failed[f.OrderId] = spotErrorCodeTable.Wrap(f.ErrCode, f.ErrMsg)
+		}
+		for _, id := range orderIds[start:end] {
+			results = append(results, BatchOrderResult{OrderId: id, Err: failed[id]})
+		}
+	}
+
+	return results, nil
+}
+
+//每次最多撤销100个订单，next-id不为-1时继续撤销
+func (hbpro *HuoBiPro) CancelAllOrders(currency CurrencyPair) (bool, error) {
+	for {
+		var data struct {
+			SuccessCount int   `json:"success-count"`
+			FailedCount  int   `json:"failed-count"`
+			NextId       int64 `json:"next-id"`
+		}
+		err := hbpro.doJsonRequest("/v1/order/orders/batchCancelOpenOrders", map[string]interface{}{
+			"account-id": hbpro.accountId,
+			"symbol":     currency.AdaptUsdToUsdt().ToLower().ToSymbol(""),
+			"size":       100,
+		}, &data)
+		if err != nil {
+			return false, err
+		}
+
+		if data.FailedCount > 0 {
+			return false, EX_ERR_CANCEL_ORDER_FAIL.OriginErr(fmt.Sprintf("%d orders cancel failed", data.FailedCount))
+		}
+		if data.NextId == -1 || data.SuccessCount == 0 {
+			return true, nil
+		}
+	}
+}
+
+//签名参数放在url上，body为json
+func (dm *Hbdm) doJsonRequest(path string, body interface{}, data interface{}) error {
+	params := &url.Values{}
+	dm.buildPostForm("POST", path, params)
+
+	reqBody, _ := json.Marshal(body)
+	resp, err := HttpPostForm3(dm.config.HttpClient, dm.config.Endpoint+path+"?"+params.Encode(), string(reqBody),
+		map[string]string{"Content-Type": "application/json", "Accept-Language": "zh-cn"})
+	if err != nil {
+		return err
+	}
+
+	var ret BaseResponse
+	if err = json.Unmarshal(resp, &ret); err != nil {
+		return err
+	}
+	if ret.Status != "ok" {
+		return adaptHbdmError(ret.ErrCode, ret.ErrMsg)
+	}
+
+	return json.Unmarshal(ret.Data, data)
+}
+
+func hbdmOrderPriceType(orderType int) string {
+	switch orderType {
+	case ORDER_FEATURE_POST_ONLY:
+		return "post_only"
+	case ORDER_FEATURE_FOK:
+		return "fok"
+	case ORDER_FEATURE_IOC:
+		return "ioc"
+	default:
+		return "limit"
+	}
+}
+
+//合约批量撤单/全部撤单的响应
+type hbdmCancelResult struct {
+	Successes string `json:"successes"`
+	Errors    []struct {
+		OrderID string `json:"order_id"`
+		ErrCode int    `json:"err_code"`
+		ErrMsg  string `json:"err_msg"`
+	} `json:"errors"`
+}
+
+//批量限价单，超过10个订单时分批请求；ClientOid需为数字，否则由交易所生成
+func (dm *Hbdm) BatchLimitFuturesOrders(orders []FutureOrder) ([]BatchOrderResult, error) {
+	results := make([]BatchOrderResult, 0, len(orders))
+
+	for start := 0; start < len(orders); start += hbdmBatchSize {
+		end := start + hbdmBatchSize
+		if end > len(orders) {
+			end = len(orders)
+		}
+
+		batch := make([]map[string]interface{}, 0, end-start)
+		for _, ord := range orders[start:end] {
+			direction, offset := dm.adaptOpenType(ord.OType)
+			o := map[string]interface{}{
+				"symbol":           ord.Currency.CurrencyA.Symbol,
+				"contract_type":    ord.ContractName,
+				"price":            dm.formatPriceSize(ord.ContractName, ord.Currency.CurrencyA, NewDecimalFromFloat(ord.Price).String()),
+				"volume":           ord.Amount,
+				"direction":        direction,
+				"offset":           offset,
+				"lever_rate":       dm.config.Lever,
+				"order_price_type": hbdmOrderPriceType(ord.OrderType),
+			}
+			if cid, err := strconv.ParseInt(ord.ClientOid, 10, 64); err == nil {
+				o["client_order_id"] = cid
+			}
+			batch = append(batch, o)
+		}
+
+		var data struct {
+			Errors []struct {
+				Index   int    `json:"index"`
+				ErrCode int    `json:"err_code"`
+				ErrMsg  string `json:"err_msg"`
+			} `json:"errors"`
+			Success []struct {
+				Index    int   `json:"index"`
+				OrderId  int64 `json:"order_id"`
+				COrderId int64 `json:"client_order_id"`
+			} `json:"success"`
+		}
+		if err := dm.doJsonRequest("/api/v1/contract_batchorder", map[string]interface{}{"orders_data": batch}, &data); err != nil {
+			for _, ord := range orders[start:] {
+				results = append(results, BatchOrderResult{Cid: ord.ClientOid, Err: err})
+			}
+			return results, err
+		}
+
+		//index从1开始
+		chunk := make([]BatchOrderResult, end-start)
+		for i := range chunk {
+			chunk[i] = BatchOrderResult{Cid: orders[start+i].ClientOid, Err: EX_ERR_PLACE_ORDER_FAIL.OriginErr("no result")}
+		}
+		for _, e := range data.Errors {
+			if e.Index >= 1 && e.Index <= len(chunk) {
+				chunk[e.Index-1].Err = adaptHbdmError(e.ErrCode, e.ErrMsg)
+			}
+		}
+		for _, s := range data.Success {
+			if s.Index >= 1 && s.Index <= len(chunk) {
+				chunk[s.Index-1] = BatchOrderResult{OrderId: fmt.Sprint(s.OrderId), Cid: fmt.Sprint(s.COrderId)}
+			}
+		}
+		results = append(results, chunk...)
+	}
+
+	return results, nil
+}
+
+func (dm *Hbdm) BatchFutureCancelOrders(currencyPair CurrencyPair, contractType string, orderIds []string) ([]BatchOrderResult, error) {
+	results := make([]BatchOrderResult, 0, len(orderIds))
+
+	for start := 0; start < len(orderIds); start += hbdmBatchSize {
+		end := start + hbdmBatchSize
+		if end > len(orderIds) {
+			end = len(orderIds)
+		}
+
+		var data hbdmCancelResult
+		err := dm.doJsonRequest("/api/v1/contract_cancel", map[string]string{
+			"order_id": strings.Join(orderIds[start:end], ","),
+			"symbol":   currencyPair.CurrencyA.Symbol,
+		}, &data)
+		if err != nil {
+			for _, id := range orderIds[start:] {
+				results = append(results, BatchOrderResult{OrderId: id, Err: err})
+			}
+			return results, err
+		}
+
+		failed := make(map[string]error, len(data.Errors))
+		for _, e := range data.Errors {
+			failed[e.OrderID] = adaptHbdmError(e.ErrCode, e.ErrMsg)
+		}
+		for _, id := range orderIds[start:end] {
+			results = append(results, BatchOrderResult{OrderId: id, Err: failed[id]})
+		}
+	}
+
+	return results, nil
+}
+
+func (dm *Hbdm) FutureCancelAllOrders(currencyPair CurrencyPair, contractType string) (bool, error) {
+	var data hbdmCancelResult
+	err := dm.doJsonRequest("/api/v1/contract_cancelall", map[string]string{
+		"symbol":        currencyPair.CurrencyA.Symbol,
+		"contract_type": contractType,
+	}, &data)
+	if err != nil {
+		return false, err
+	}
+
+	if len(data.Errors) > 0 {
+		return false, adaptHbdmError(data.Errors[0].ErrCode, data.Errors[0].ErrMsg)
+	}
+	return true, nil
+}
@@ -0,0 +1,45 @@
+package huobi
+
+import (
+	"fmt"
+
+	. "github.com/mrwill84/goex"
+)
+
+//现货 err-code
+var spotErrorCodeTable = ErrorCodeTable{
+	"api-signature-not-valid":                   EX_ERR_SIGN,
+	"api-signature-check-failed":                EX_ERR_SIGN,
+	"login-required":                            EX_ERR_AUTH_FAIL,
+	"account-frozen-balance-insufficient-error": EX_ERR_INSUFFICIENT_BALANCE,
+	"insufficient-balance":                      EX_ERR_INSUFFICIENT_BALANCE,
+	"order-orderstate-error":                    EX_ERR_CANCEL_ORDER_FAIL,
+	"base-record-invalid":                       EX_ERR_NOT_FIND_ORDER,
+	"order-value-min-error":                     EX_ERR_INVALID_ORDER_PARAM,
+	"order-limitorder-amount-min-error":         EX_ERR_INVALID_ORDER_PARAM,
+	"order-orderprice-precision-error":          EX_ERR_INVALID_ORDER_PARAM,
+	"order-orderamount-precision-error":         EX_ERR_INVALID_ORDER_PARAM,
+	"base-symbol-error":                         EX_ERR_SYMBOL_ERR,
+	"base-system-error":                         EX_ERR_MAINTENANCE,
+}
+
+//合约 err_code
+var hbdmErrorCodeTable = ErrorCodeTable{
+	"1004": EX_ERR_MAINTENANCE,          //系统繁忙
+	"1032": EX_ERR_API_LIMIT,            //访问次数超出限制
+	"1047": EX_ERR_INSUFFICIENT_BALANCE, //可用保证金不足
+	"1017": EX_ERR_NOT_FIND_ORDER,       //查询订单不存在
+	"1061": EX_ERR_NOT_FIND_ORDER,       //订单不存在，无法撤单
+	"1071": EX_ERR_CANCEL_ORDER_FAIL,    //订单已撤，无法撤单
+}
+
+//现货接口 status=error 时的响应
+func adaptSpotError(respmap map[string]interface{}) error {
+	code, _ := respmap["err-code"].(string)
+	msg, _ := respmap["err-msg"].(string)
+	return spotErrorCodeTable.Wrap(code, msg)
+}
+
+func adaptHbdmError(code int, msg string) error {
+	return hbdmErrorCodeTable.Wrap(fmt.Sprint(code), msg)
+}
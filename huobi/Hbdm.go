@@ -1,6 +1,7 @@
 package huobi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -19,12 +20,19 @@ type Hbdm struct {
 	config *APIConfig
 }
 
+var (
+	_ FutureRestAPI          = (*Hbdm)(nil)
+	_ FutureContextBinder    = (*Hbdm)(nil)
+	_ BatchFutureOrderAPI    = (*Hbdm)(nil)
+	_ FutureClientOrderIdAPI = (*Hbdm)(nil)
+)
+
 type OrderInfo struct {
 	Symbol         string  `json:"symbol"`
 	ContractType   string  `json:"contract_type"`
 	ContractCode   string  `json:"contract_code"`
-	Volume         float64 `json:"volume"`
-	Price          float64 `json:"price"`
+	Volume         Decimal `json:"volume"`
+	Price          Decimal `json:"price"`
 	OrderPriceType string  `json:"order_price_type"`
 	Direction      string  `json:"direction"`
 	Offset         string  `json:"offset"`
@@ -34,14 +42,18 @@ type OrderInfo struct {
 	OrderSource    string  `json:"order_source"`
 	CreatedAt      int64   `json:"created_at"`
 	CreateDate     int64   `json:"create_date"` //for swap contract
-	TradeVolume    float64 `json:"trade_volume"`
+	TradeVolume    Decimal `json:"trade_volume"`
 	TradeTurnover  float64 `json:"trade_turnover"`
 	Fee            float64 `json:"fee"`
-	TradeAvgPrice  float64 `json:"trade_avg_price"`
+	TradeAvgPrice  Decimal `json:"trade_avg_price"`
 	MarginFrozen   float64 `json:"margin_frozen"`
 	Status         int     `json:"status"`
 }
 
+func (ord OrderInfo) decimals() OrderDecimals {
+	return OrderDecimals{Price: ord.Price, Amount: ord.Volume, AvgPrice: ord.TradeAvgPrice, DealAmount: ord.TradeVolume}
+}
+
 type BaseResponse struct {
 	Status  string          `json:"status"`
 	Ch      string          `json:"ch"`
@@ -134,6 +146,17 @@ func (dm *Hbdm) GetExchangeName() string {
 	return HBDM
 }
 
+// 返回绑定了ctx的副本，所有http请求都受ctx控制
+func (dm *Hbdm) WithContext(ctx context.Context) FutureRestAPI {
+	return dm.withContext(ctx)
+}
+
+func (dm *Hbdm) withContext(ctx context.Context) *Hbdm {
+	config := *dm.config
+	config.HttpClient = HttpClientWithContext(ctx, dm.config.HttpClient)
+	return &Hbdm{&config}
+}
+
 func (dm *Hbdm) GetFutureUserinfo(currencyPair ...CurrencyPair) (*FutureAccount, error) {
 	path := "/api/v1/contract_account_info"
 	var data []struct {
@@ -287,10 +310,14 @@ func (dm *Hbdm) PlaceFutureOrder2(currencyPair CurrencyPair, contractType, price
 	params := &url.Values{}
 	path := "/api/v1/contract_order"
 
-	params.Add("client_order_id", fmt.Sprint(time.Now().UnixNano()))
+	cid := GetClientOrderId(opt...)
+	if cid == "" {
+		cid = NewClientOrderId(HBDM)
+	}
+	params.Add("client_order_id", cid)
 	params.Add("contract_type", contractType)
 	params.Add("symbol", currencyPair.CurrencyA.Symbol)
-	params.Add("volume", amount)
+	params.Add("volume", DecimalString(amount))
 	params.Add("lever_rate", fmt.Sprint(leverRate))
 	params.Add("contract_code", "")
 
@@ -298,8 +325,8 @@ func (dm *Hbdm) PlaceFutureOrder2(currencyPair CurrencyPair, contractType, price
 		params.Set("order_price_type", "opponent") //对手价下单
 	} else {
 		orderPriceType := "limit"
-		if len(opt) > 0 {
-			switch opt[0] {
+		if tif, ok := GetTimeInForce(opt...); ok {
+			switch tif {
 			case Fok:
 				orderPriceType = "fok"
 			case Ioc:
@@ -337,7 +364,7 @@ func (dm *Hbdm) PlaceFutureOrder2(currencyPair CurrencyPair, contractType, price
 }
 
 func (dm *Hbdm) LimitFuturesOrder(currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
-	return dm.PlaceFutureOrder2(currencyPair, contractType, price, amount, openType, 0, dm.config.Lever)
+	return dm.PlaceFutureOrder2(currencyPair, contractType, price, amount, openType, 0, dm.config.Lever, opt...)
 }
 
 func (dm *Hbdm) MarketFuturesOrder(currencyPair CurrencyPair, contractType, amount string, openType int) (*FutureOrder, error) {
@@ -345,6 +372,14 @@ func (dm *Hbdm) MarketFuturesOrder(currencyPair CurrencyPair, contractType, amou
 }
 
 func (dm *Hbdm) FutureCancelOrder(currencyPair CurrencyPair, contractType, orderId string) (bool, error) {
+	return dm.cancelOrder("order_id", orderId, currencyPair)
+}
+
+func (dm *Hbdm) FutureCancelOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (bool, error) {
+	return dm.cancelOrder("client_order_id", cid, currencyPair)
+}
+
+func (dm *Hbdm) cancelOrder(idKey, id string, currencyPair CurrencyPair) (bool, error) {
 	var data struct {
 		Successes string `json:"successes"`
 		Errors    []struct {
@@ -356,7 +391,7 @@ func (dm *Hbdm) FutureCancelOrder(currencyPair CurrencyPair, contractType, order
 	path := "/api/v1/contract_cancel"
 	params := &url.Values{}
 
-	params.Add("order_id", orderId)
+	params.Add(idKey, id)
 	params.Add("symbol", currencyPair.CurrencyA.Symbol)
 
 	err := dm.doRequest(path, params, &data)
@@ -365,7 +400,7 @@ func (dm *Hbdm) FutureCancelOrder(currencyPair CurrencyPair, contractType, order
 	}
 
 	if len(data.Errors) > 0 {
-		return false, errors.New(fmt.Sprintf("%d:[%s]", data.Errors[0].ErrCode, data.Errors[0].ErrMsg))
+		return false, adaptHbdmError(data.Errors[0].ErrCode, data.Errors[0].ErrMsg)
 	} else {
 		return true, nil
 	}
@@ -398,10 +433,12 @@ func (dm *Hbdm) GetUnfinishFutureOrders(currencyPair CurrencyPair, contractType
 			OType:        dm.adaptOffsetDirectionToOpenType(ord.Offset, ord.Direction),
 			OrderID2:     fmt.Sprint(ord.OrderId),
 			OrderID:      ord.OrderId,
-			Amount:       ord.Volume,
-			Price:        ord.Price,
-			AvgPrice:     ord.TradeAvgPrice,
-			DealAmount:   ord.TradeVolume,
+			ClientOid:    fmt.Sprint(ord.ClientOrderId),
+			Amount:       ord.Volume.Float64(),
+			Price:        ord.Price.Float64(),
+			AvgPrice:     ord.TradeAvgPrice.Float64(),
+			DealAmount:   ord.TradeVolume.Float64(),
+			Decimals:     ord.decimals(),
 			Status:       dm.adaptOrderStatus(ord.Status),
 			Fee:          ord.Fee,
 			LeverRate:    ord.LeverRate,
@@ -424,11 +461,28 @@ func (dm *Hbdm) GetFutureOrder(orderId string, currencyPair CurrencyPair, contra
 }
 
 func (dm *Hbdm) GetFutureOrders(orderIds []string, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
+	return dm.getFutureOrders("order_id", orderIds, currencyPair, contractType)
+}
+
+func (dm *Hbdm) GetFutureOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
+	ords, err := dm.getFutureOrders("client_order_id", []string{cid}, currencyPair, contractType)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ords) == 1 {
+		return &ords[0], nil
+	}
+	return nil, errors.New("not found order")
+}
+
+//idKey为order_id或client_order_id
+func (dm *Hbdm) getFutureOrders(idKey string, ids []string, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
 	var data []OrderInfo
 	path := "/api/v1/contract_order_info"
 	params := &url.Values{}
 
-	params.Add("order_id", strings.Join(orderIds, ","))
+	params.Add(idKey, strings.Join(ids, ","))
 	params.Add("symbol", currencyPair.CurrencyA.Symbol)
 
 	err := dm.doRequest(path, params, &data)
@@ -445,10 +499,12 @@ func (dm *Hbdm) GetFutureOrders(orderIds []string, currencyPair CurrencyPair, co
 			OType:        dm.adaptOffsetDirectionToOpenType(ord.Offset, ord.Direction),
 			OrderID2:     fmt.Sprint(ord.OrderId),
 			OrderID:      ord.OrderId,
-			Amount:       ord.Volume,
-			Price:        ord.Price,
-			AvgPrice:     ord.TradeAvgPrice,
-			DealAmount:   ord.TradeVolume,
+			ClientOid:    fmt.Sprint(ord.ClientOrderId),
+			Amount:       ord.Volume.Float64(),
+			Price:        ord.Price.Float64(),
+			AvgPrice:     ord.TradeAvgPrice.Float64(),
+			DealAmount:   ord.TradeVolume.Float64(),
+			Decimals:     ord.decimals(),
 			Status:       dm.adaptOrderStatus(ord.Status),
 			Fee:          ord.Fee,
 			LeverRate:    ord.LeverRate,
@@ -490,10 +546,12 @@ func (dm *Hbdm) GetFutureOrderHistory(pair CurrencyPair, contractType string, op
 			OType:        dm.adaptOffsetDirectionToOpenType(ord.Offset, ord.Direction),
 			OrderID2:     fmt.Sprint(ord.OrderId),
 			OrderID:      ord.OrderId,
-			Amount:       ord.Volume,
-			Price:        ord.Price,
-			AvgPrice:     ord.TradeAvgPrice,
-			DealAmount:   ord.TradeVolume,
+			ClientOid:    fmt.Sprint(ord.ClientOrderId),
+			Amount:       ord.Volume.Float64(),
+			Price:        ord.Price.Float64(),
+			AvgPrice:     ord.TradeAvgPrice.Float64(),
+			DealAmount:   ord.TradeVolume.Float64(),
+			Decimals:     ord.decimals(),
 			Status:       dm.adaptOrderStatus(ord.Status),
 			Fee:          ord.Fee,
 			LeverRate:    ord.LeverRate,
@@ -585,12 +643,12 @@ func (dm *Hbdm) GetFutureDepth(currencyPair CurrencyPair, contractType string, s
 
 	for _, item := range asks {
 		askItem := item.([]interface{})
-		dep.AskList = append(dep.AskList, DepthRecord{ToFloat64(askItem[0]), ToFloat64(askItem[1])})
+		dep.AskList = append(dep.AskList, DepthRecord{Price: ToFloat64(askItem[0]), Amount: ToFloat64(askItem[1])})
 	}
 
 	for _, item := range bids {
 		bidItem := item.([]interface{})
-		dep.BidList = append(dep.BidList, DepthRecord{ToFloat64(bidItem[0]), ToFloat64(bidItem[1])})
+		dep.BidList = append(dep.BidList, DepthRecord{Price: ToFloat64(bidItem[0]), Amount: ToFloat64(bidItem[1])})
 	}
 
 	sort.Sort(sort.Reverse(dep.AskList))
@@ -799,27 +857,40 @@ func (dm *Hbdm) doRequest(path string, params *url.Values, data interface{}) err
 	}
 
 	if ret.Status != "ok" {
-		return errors.New(fmt.Sprintf("%d:[%s]", ret.ErrCode, ret.ErrMsg))
+		return adaptHbdmError(ret.ErrCode, ret.ErrMsg)
 	}
 
 	return json.Unmarshal(ret.Data, data)
 }
 
+//公共接口，status不为ok时返回交易所错误，ts为响应时间
+func (dm *Hbdm) doPublicRequest(path string, params url.Values, data interface{}) (ts int64, err error) {
+	respBody, err := HttpGet5(dm.config.HttpClient, dm.config.Endpoint+path+"?"+params.Encode(), map[string]string{})
+	if err != nil {
+		return 0, err
+	}
+	logger.Debugf("response body: %s", string(respBody))
+
+	var ret BaseResponse
+	if err = json.Unmarshal(respBody, &ret); err != nil {
+		return 0, err
+	}
+
+	if ret.Status != "ok" {
+		return 0, adaptHbdmError(ret.ErrCode, ret.ErrMsg)
+	}
+
+	return ret.Ts, json.Unmarshal(ret.Data, data)
+}
+
 func (dm *Hbdm) formatPriceSize(contract string, currency Currency, price string) string {
-	var tickSize = 2 //default set 2
 	for _, v := range FuturesContractInfos {
 		if (v.ContractType == contract || v.InstrumentID == contract) && v.UnderlyingIndex == currency.Symbol {
 			if v.PriceTickSize == 0 {
 				break
 			}
-			tickSize = 0
-			priceSize := v.PriceTickSize
-			for priceSize < 1 {
-				tickSize++
-				priceSize *= 10
-			}
-			break
+			return ToDecimal(price).RoundToTick(NewDecimalFromFloat(v.PriceTickSize)).String()
 		}
 	}
-	return FloatToString(ToFloat64(price), tickSize)
+	return ToDecimal(price).Round(2).String() //default set 2
 }
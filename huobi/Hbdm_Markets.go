@@ -0,0 +1,91 @@
+package huobi
+
+import (
+	"net/url"
+	"strings"
+
+	. "github.com/mrwill84/goex"
+)
+
+var (
+	_ MarketInfo = (*Hbdm)(nil)
+	_ MarketInfo = (*HbdmSwap)(nil)
+)
+
+const (
+	contractInfoApiPath = "/api/v1/contract_contract_info"
+)
+
+//contract_type -> 合约类型及行情接口使用的别名后缀，如BTC_CQ
+var hbdmContractTypes = map[string][2]string{
+	"this_week":    {THIS_WEEK_CONTRACT, "CW"},
+	"next_week":    {NEXT_WEEK_CONTRACT, "NW"},
+	"quarter":      {QUARTER_CONTRACT, "CQ"},
+	"next_quarter": {BI_QUARTER_CONTRACT, "NQ"},
+}
+
+type hbdmContractInfo struct {
+	Symbol         string  `json:"symbol"`
+	ContractCode   string  `json:"contract_code"`
+	ContractType   string  `json:"contract_type"`
+	ContractSize   float64 `json:"contract_size"`
+	PriceTick      float64 `json:"price_tick"`
+	DeliveryDate   string  `json:"delivery_date"`
+	ContractStatus int     `json:"contract_status"` //0:已下市 1:上市 2:待上市 3:停牌 4:暂停上市中 5:结算中 6:交割中 7:结算完成 8:交割完成
+}
+
+func (info hbdmContractInfo) market(pair CurrencyPair, contractType string) Market {
+	m := Market{
+		Pair:         pair,
+		ContractType: contractType,
+		Symbol:       info.ContractCode,
+		TickSize:     NewDecimalFromFloat(info.PriceTick),
+		LotSize:      NewDecimal(1, 0),
+		MinAmount:    NewDecimal(1, 0),
+		ContractVal:  NewDecimalFromFloat(info.ContractSize),
+		Inverse:      true, //币本位合约，面值以美元计
+		Status:       MARKET_SUSPEND,
+	}
+
+	switch info.ContractStatus {
+	case 1:
+		m.Status = MARKET_TRADING
+	case 0, 8:
+		m.Status = MARKET_OFFLINE
+	}
+	return m
+}
+
+//交割合约，symbol为带交割日期的contract_code(如BTC211231)，别名为行情接口的BTC_CQ
+func (dm *Hbdm) GetMarkets() ([]Market, error) {
+	var infos []hbdmContractInfo
+	if _, err := dm.doPublicRequest(contractInfoApiPath, url.Values{}, &infos); err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, 0, len(infos))
+	for _, info := range infos {
+		ct, ok := hbdmContractTypes[info.ContractType]
+		if !ok {
+			continue
+		}
+		m := info.market(NewCurrencyPair(NewCurrency(info.Symbol, ""), USD), ct[0])
+		m.Aliases = []string{strings.ToUpper(info.Symbol) + "_" + ct[1]}
+		markets = append(markets, m)
+	}
+	return markets, nil
+}
+
+//symbol为contract_code , 如BTC-USD
+func (swap *HbdmSwap) GetMarkets() ([]Market, error) {
+	var infos []hbdmContractInfo
+	if _, err := swap.doPublicRequest(getSwapContractInfoApiPath, url.Values{}, &infos); err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, 0, len(infos))
+	for _, info := range infos {
+		markets = append(markets, info.market(NewCurrencyPair3(info.ContractCode, "-"), SWAP_CONTRACT))
+	}
+	return markets, nil
+}
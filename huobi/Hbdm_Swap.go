@@ -1,11 +1,13 @@
 package huobi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
 	"sort"
+	"sync"
 	"time"
 
 	. "github.com/mrwill84/goex"
@@ -13,10 +15,17 @@ import (
 )
 
 type HbdmSwap struct {
-	base *Hbdm
-	c    *APIConfig
+	base       *Hbdm
+	c          *APIConfig
+	leverRates *sync.Map //contract_code -> SetLeverage设置的杠杆倍数，未设置的合约使用c.Lever
 }
 
+var (
+	_ FutureRestAPI          = (*HbdmSwap)(nil)
+	_ FutureContextBinder    = (*HbdmSwap)(nil)
+	_ FutureClientOrderIdAPI = (*HbdmSwap)(nil)
+)
+
 const (
 	getSwapContractInfoApiPath = "/swap-ex/v1/swap_contract_info"
 	tickerApiPath              = "/swap-ex/market/detail/merged"
@@ -37,8 +46,9 @@ func NewHbdmSwap(c *APIConfig) *HbdmSwap {
 	}
 
 	return &HbdmSwap{
-		base: NewHbdm(c),
-		c:    c,
+		base:       NewHbdm(c),
+		c:          c,
+		leverRates: new(sync.Map),
 	}
 }
 
@@ -46,6 +56,12 @@ func (swap *HbdmSwap) GetExchangeName() string {
 	return HBDM_SWAP
 }
 
+// 返回绑定了ctx的副本，所有http请求都受ctx控制
+func (swap *HbdmSwap) WithContext(ctx context.Context) FutureRestAPI {
+	base := swap.base.withContext(ctx)
+	return &HbdmSwap{base: base, c: base.config, leverRates: swap.leverRates}
+}
+
 func (swap *HbdmSwap) GetFutureTicker(currencyPair CurrencyPair, contractType string) (*Ticker, error) {
 	tickerUrl := fmt.Sprintf("%s%s?contract_code=%s", swap.base.config.Endpoint, tickerApiPath, currencyPair.ToSymbol("-"))
 	responseBody, err := HttpGet5(swap.base.config.HttpClient, tickerUrl, map[string]string{})
@@ -199,11 +215,22 @@ func (swap *HbdmSwap) GetFutureUserinfo(currencyPair ...CurrencyPair) (*FutureAc
 }
 
 func (swap *HbdmSwap) PlaceFutureOrder(currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice int, leverRate float64) (string, error) {
+	orderId, _, err := swap.placeFutureOrder(currencyPair, price, amount, openType, matchPrice, leverRate)
+	return orderId, err
+}
+
+//返回订单ID和客户端订单ID
+func (swap *HbdmSwap) placeFutureOrder(currencyPair CurrencyPair, price, amount string, openType, matchPrice int, leverRate float64, opt ...LimitOrderOptionalParameter) (string, string, error) {
+	cid := GetClientOrderId(opt...)
+	if cid == "" {
+		cid = NewClientOrderId(HBDM_SWAP)
+	}
+
 	param := url.Values{}
 	param.Set("contract_code", currencyPair.ToSymbol("-"))
-	param.Set("client_order_id", fmt.Sprint(time.Now().UnixNano()))
-	param.Set("price", price)
-	param.Set("volume", amount)
+	param.Set("client_order_id", cid)
+	param.Set("price", DecimalString(price))
+	param.Set("volume", DecimalString(amount))
 	param.Set("lever_rate", fmt.Sprintf("%.0f", leverRate))
 
 	direction, offset := swap.base.adaptOpenType(openType)
@@ -214,7 +241,18 @@ func (swap *HbdmSwap) PlaceFutureOrder(currencyPair CurrencyPair, contractType,
 	if matchPrice == 1 {
 		param.Set("order_price_type", "opponent")
 	} else {
-		param.Set("order_price_type", "limit")
+		orderPriceType := "limit"
+		if tif, ok := GetTimeInForce(opt...); ok {
+			switch tif {
+			case Fok:
+				orderPriceType = "fok"
+			case Ioc:
+				orderPriceType = "ioc"
+			case PostOnly:
+				orderPriceType = "post_only"
+			}
+		}
+		param.Set("order_price_type", orderPriceType)
 	}
 
 	var orderResponse struct {
@@ -224,16 +262,17 @@ func (swap *HbdmSwap) PlaceFutureOrder(currencyPair CurrencyPair, contractType,
 
 	err := swap.base.doRequest(placeOrderApiPath, &param, &orderResponse)
 	if err != nil {
-		return "", err
+		return "", cid, err
 	}
 
-	return orderResponse.OrderId, nil
+	return orderResponse.OrderId, cid, nil
 }
 
 func (swap *HbdmSwap) LimitFuturesOrder(currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
-	orderId, err := swap.PlaceFutureOrder(currencyPair, contractType, price, amount, openType, 0, swap.c.Lever)
+	orderId, cid, err := swap.placeFutureOrder(currencyPair, price, amount, openType, 0, swap.leverRate(currencyPair), opt...)
 	return &FutureOrder{
 		Currency:     currencyPair,
+		ClientOid:    cid,
 		OrderID2:     orderId,
 		Amount:       ToFloat64(amount),
 		Price:        ToFloat64(price),
@@ -243,7 +282,7 @@ func (swap *HbdmSwap) LimitFuturesOrder(currencyPair CurrencyPair, contractType,
 }
 
 func (swap *HbdmSwap) MarketFuturesOrder(currencyPair CurrencyPair, contractType, amount string, openType int) (*FutureOrder, error) {
-	orderId, err := swap.PlaceFutureOrder(currencyPair, contractType, "", amount, openType, 1, 10)
+	orderId, err := swap.PlaceFutureOrder(currencyPair, contractType, "", amount, openType, 1, swap.leverRate(currencyPair))
 	return &FutureOrder{
 		Currency:     currencyPair,
 		OrderID2:     orderId,
@@ -254,12 +293,21 @@ func (swap *HbdmSwap) MarketFuturesOrder(currencyPair CurrencyPair, contractType
 }
 
 func (swap *HbdmSwap) FutureCancelOrder(currencyPair CurrencyPair, contractType, orderId string) (bool, error) {
+	return swap.cancelOrder("order_id", orderId, currencyPair)
+}
+
+func (swap *HbdmSwap) FutureCancelOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (bool, error) {
+	return swap.cancelOrder("client_order_id", cid, currencyPair)
+}
+
+func (swap *HbdmSwap) cancelOrder(idKey, id string, currencyPair CurrencyPair) (bool, error) {
 	param := url.Values{}
-	param.Set("order_id", orderId)
+	param.Set(idKey, id)
 	param.Set("contract_code", currencyPair.ToSymbol("-"))
 
 	var cancelResponse struct {
 		Errors []struct {
+			ErrCode   int    `json:"err_code"`
 			ErrMsg    string `json:"err_msg"`
 			Successes string `json:"successes,omitempty"`
 		} `json:"errors"`
@@ -271,7 +319,7 @@ func (swap *HbdmSwap) FutureCancelOrder(currencyPair CurrencyPair, contractType,
 	}
 
 	if len(cancelResponse.Errors) > 0 {
-		return false, errors.New(cancelResponse.Errors[0].ErrMsg)
+		return false, adaptHbdmError(cancelResponse.Errors[0].ErrCode, cancelResponse.Errors[0].ErrMsg)
 	}
 
 	return true, nil
@@ -346,13 +394,21 @@ func (swap *HbdmSwap) GetFutureOrders(orderIds []string, currencyPair CurrencyPa
 }
 
 func (swap *HbdmSwap) GetFutureOrder(orderId string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
+	return swap.getFutureOrder("order_id", orderId, currencyPair)
+}
+
+func (swap *HbdmSwap) GetFutureOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
+	return swap.getFutureOrder("client_order_id", cid, currencyPair)
+}
+
+func (swap *HbdmSwap) getFutureOrder(idKey, id string, currencyPair CurrencyPair) (*FutureOrder, error) {
 	var (
 		orderInfoResponse []OrderInfo
 		param             = url.Values{}
 	)
 
 	param.Set("contract_code", currencyPair.ToSymbol("-"))
-	param.Set("order_id", orderId)
+	param.Set(idKey, id)
 
 	err := swap.base.doRequest(getOrderInfoApiPath, &param, &orderInfoResponse)
 	if err != nil {
@@ -369,10 +425,11 @@ func (swap *HbdmSwap) GetFutureOrder(orderId string, currencyPair CurrencyPair,
 		Currency:     currencyPair,
 		ClientOid:    fmt.Sprint(orderInfo.ClientOrderId),
 		OrderID2:     fmt.Sprint(orderInfo.OrderId),
-		Price:        orderInfo.Price,
-		Amount:       orderInfo.Volume,
-		AvgPrice:     orderInfo.TradeAvgPrice,
-		DealAmount:   orderInfo.TradeVolume,
+		Price:        orderInfo.Price.Float64(),
+		Amount:       orderInfo.Volume.Float64(),
+		AvgPrice:     orderInfo.TradeAvgPrice.Float64(),
+		DealAmount:   orderInfo.TradeVolume.Float64(),
+		Decimals:     orderInfo.decimals(),
 		OrderID:      orderInfo.OrderId,
 		Status:       swap.base.adaptOrderStatus(orderInfo.Status),
 		OType:        swap.base.adaptOffsetDirectionToOpenType(orderInfo.Offset, orderInfo.Direction),
@@ -414,10 +471,11 @@ func (swap *HbdmSwap) GetFutureOrderHistory(pair CurrencyPair, contractType stri
 		historyOrders = append(historyOrders, FutureOrder{
 			OrderID:      ord.OrderId,
 			OrderID2:     fmt.Sprintf("%d", ord.OrderId),
-			Price:        ord.Price,
-			Amount:       ord.Volume,
-			AvgPrice:     ord.TradeAvgPrice,
-			DealAmount:   ord.TradeVolume,
+			Price:        ord.Price.Float64(),
+			Amount:       ord.Volume.Float64(),
+			AvgPrice:     ord.TradeAvgPrice.Float64(),
+			DealAmount:   ord.TradeVolume.Float64(),
+			Decimals:     ord.decimals(),
 			OrderTime:    ord.CreateDate,
 			Status:       swap.base.adaptOrderStatus(ord.Status),
 			Currency:     pair,
@@ -451,10 +509,11 @@ func (swap *HbdmSwap) GetUnfinishFutureOrders(currencyPair CurrencyPair, contrac
 			Currency:   currencyPair,
 			ClientOid:  fmt.Sprint(ord.ClientOrderId),
 			OrderID2:   fmt.Sprint(ord.OrderId),
-			Price:      ord.Price,
-			Amount:     ord.Volume,
-			AvgPrice:   ord.TradeAvgPrice,
-			DealAmount: ord.TradeVolume,
+			Price:      ord.Price.Float64(),
+			Amount:     ord.Volume.Float64(),
+			AvgPrice:   ord.TradeAvgPrice.Float64(),
+			DealAmount: ord.TradeVolume.Float64(),
+			Decimals:   ord.decimals(),
 			OrderID:    ord.OrderId,
 			Status:     swap.base.adaptOrderStatus(ord.Status),
 			OType:      swap.base.adaptOffsetDirectionToOpenType(ord.Offset, ord.Direction),
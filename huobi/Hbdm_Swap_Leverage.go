@@ -0,0 +1,84 @@
+package huobi
+
+import (
+	"fmt"
+	"net/url"
+
+	. "github.com/mrwill84/goex"
+)
+
+var (
+	_ FutureLeverageAPI = (*HbdmSwap)(nil)
+)
+
+const (
+	switchLeverRateApiPath = "/swap-api/v1/swap_switch_lever_rate"
+)
+
+//下单时使用的杠杆倍数，需要与持仓的杠杆倍数一致
+func (swap *HbdmSwap) leverRate(currencyPair CurrencyPair) float64 {
+	if lever, ok := swap.leverRates.Load(currencyPair.ToSymbol("-")); ok {
+		return lever.(float64)
+	}
+	return swap.c.Lever
+}
+
+func (swap *HbdmSwap) GetLeverage(currencyPair CurrencyPair, contractType string) (float64, error) {
+	param := url.Values{}
+	param.Set("contract_code", currencyPair.ToSymbol("-"))
+
+	var accounts []struct {
+		ContractCode string  `json:"contract_code"`
+		LeverRate    float64 `json:"lever_rate"`
+	}
+	err := swap.base.doRequest(accountApiPath, &param, &accounts)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, acc := range accounts {
+		if acc.ContractCode == currencyPair.ToSymbol("-") {
+			return acc.LeverRate, nil
+		}
+	}
+	return 0, EX_ERR_SYMBOL_ERR
+}
+
+//切换成功后该合约之后的下单都使用新的杠杆倍数
+func (swap *HbdmSwap) SetLeverage(currencyPair CurrencyPair, contractType string, lever int) error {
+	param := url.Values{}
+	param.Set("contract_code", currencyPair.ToSymbol("-"))
+	param.Set("lever_rate", fmt.Sprint(lever))
+
+	var resp struct {
+		LeverRate float64 `json:"lever_rate"`
+	}
+	err := swap.base.doRequest(switchLeverRateApiPath, &param, &resp)
+	if err != nil {
+		return err
+	}
+
+	swap.leverRates.Store(currencyPair.ToSymbol("-"), float64(lever))
+	return nil
+}
+
+//币本位永续合约只有逐仓模式
+func (swap *HbdmSwap) SetMarginMode(currencyPair CurrencyPair, contractType string, mode MarginMode) error {
+	if mode != ISOLATED_MARGIN {
+		return EX_ERR_NOT_SUPPORTED.Wrap("", fmt.Sprintf("margin mode %s", mode))
+	}
+	return nil
+}
+
+//币本位永续合约只有双向持仓模式
+func (swap *HbdmSwap) SetPositionMode(hedge bool) error {
+	if !hedge {
+		return EX_ERR_NOT_SUPPORTED.Wrap("", "one-way position mode")
+	}
+	return nil
+}
+
+//保证金随开平仓自动调整，不支持手动追加或减少
+func (swap *HbdmSwap) ModifyIsolatedMargin(currencyPair CurrencyPair, contractType string, positionSide int, amount float64) error {
+	return EX_ERR_NOT_SUPPORTED.Wrap("", "modify isolated margin")
+}
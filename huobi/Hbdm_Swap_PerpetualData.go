@@ -0,0 +1,163 @@
+package huobi
+
+import (
+	"fmt"
+	"net/url"
+
+	. "github.com/mrwill84/goex"
+)
+
+var (
+	_ PerpetualDataAPI = (*HbdmSwap)(nil)
+)
+
+const (
+	fundingRateApiPath        = "/swap-api/v1/swap_funding_rate"
+	historicalFundingApiPath  = "/swap-api/v1/swap_historical_funding_rate"
+	swapIndexApiPath          = "/swap-api/v1/swap_index"
+	swapOpenInterestApiPath   = "/swap-api/v1/swap_open_interest"
+	swapMarkPriceKlineApiPath = "/index/market/history/swap_mark_price_kline"
+
+	historicalFundingMaxPageSize = 50
+)
+
+//公共接口，status不为ok时返回交易所错误，ts为响应时间
+func (swap *HbdmSwap) doPublicRequest(path string, params url.Values, data interface{}) (ts int64, err error) {
+	return swap.base.doPublicRequest(path, params, data)
+}
+
+func (swap *HbdmSwap) GetFundingRate(currencyPair CurrencyPair, contractType string) (*FundingRate, error) {
+	params := url.Values{}
+	params.Set("contract_code", currencyPair.ToSymbol("-"))
+
+	var resp struct {
+		FundingRate     float64 `json:"funding_rate,string"`
+		EstimatedRate   string  `json:"estimated_rate"` //可能为null
+		FundingTime     int64   `json:"funding_time,string"`
+		NextFundingTime string  `json:"next_funding_time"`
+	}
+	if _, err := swap.doPublicRequest(fundingRateApiPath, params, &resp); err != nil {
+		return nil, err
+	}
+
+	return &FundingRate{
+		Pair:            currencyPair,
+		ContractType:    contractType,
+		Rate:            resp.FundingRate,
+		NextRate:        ToFloat64(resp.EstimatedRate),
+		FundingTime:     resp.FundingTime,
+		NextFundingTime: ToInt64(resp.NextFundingTime),
+	}, nil
+}
+
+//火币只支持分页查询，按时间降序翻页直到超出start或凑够size条
+func (swap *HbdmSwap) GetFundingRateHistory(currencyPair CurrencyPair, contractType string, start, end int64, size int) ([]FundingRate, error) {
+	params := url.Values{}
+	params.Set("contract_code", currencyPair.ToSymbol("-"))
+	params.Set("page_size", fmt.Sprint(historicalFundingMaxPageSize))
+
+	var rates []FundingRate
+	for page := 1; ; page++ {
+		params.Set("page_index", fmt.Sprint(page))
+
+		var resp struct {
+			TotalPage int `json:"total_page"`
+			Data      []struct {
+				FundingRate  float64 `json:"funding_rate,string"`
+				RealizedRate float64 `json:"realized_rate,string"`
+				FundingTime  int64   `json:"funding_time,string"`
+			} `json:"data"`
+		}
+		if _, err := swap.doPublicRequest(historicalFundingApiPath, params, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, r := range resp.Data {
+			if end > 0 && r.FundingTime > end {
+				continue
+			}
+			if start > 0 && r.FundingTime < start {
+				return rates, nil
+			}
+			rates = append(rates, FundingRate{
+				Pair:         currencyPair,
+				ContractType: contractType,
+				Rate:         r.RealizedRate,
+				FundingTime:  r.FundingTime,
+			})
+			if size > 0 && len(rates) >= size {
+				return rates, nil
+			}
+		}
+
+		if page >= resp.TotalPage || len(resp.Data) == 0 {
+			return rates, nil
+		}
+	}
+}
+
+//标记价格取最近一根1分钟标记价格K线的收盘价
+func (swap *HbdmSwap) GetMarkPrice(currencyPair CurrencyPair, contractType string) (*MarkPrice, error) {
+	params := url.Values{}
+	params.Set("contract_code", currencyPair.ToSymbol("-"))
+	params.Set("period", "1min")
+	params.Set("size", "1")
+
+	var klines []struct {
+		Id    int64   `json:"id"` //秒
+		Close float64 `json:"close,string"`
+	}
+	ts, err := swap.doPublicRequest(swapMarkPriceKlineApiPath, params, &klines)
+	if err != nil {
+		return nil, err
+	}
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("no mark price for %s", currencyPair.ToSymbol("-"))
+	}
+
+	params = url.Values{}
+	params.Set("contract_code", currencyPair.ToSymbol("-"))
+	var indexes []struct {
+		IndexPrice float64 `json:"index_price"`
+	}
+	if _, err = swap.doPublicRequest(swapIndexApiPath, params, &indexes); err != nil {
+		return nil, err
+	}
+
+	markPrice := &MarkPrice{
+		Pair:         currencyPair,
+		ContractType: contractType,
+		MarkPrice:    klines[0].Close,
+		Timestamp:    ts,
+	}
+	if len(indexes) > 0 {
+		markPrice.IndexPrice = indexes[0].IndexPrice
+	}
+	return markPrice, nil
+}
+
+//volume单位为张，amount单位为币
+func (swap *HbdmSwap) GetOpenInterest(currencyPair CurrencyPair, contractType string) (*OpenInterest, error) {
+	params := url.Values{}
+	params.Set("contract_code", currencyPair.ToSymbol("-"))
+
+	var resp []struct {
+		Volume float64 `json:"volume"`
+		Amount float64 `json:"amount"`
+	}
+	ts, err := swap.doPublicRequest(swapOpenInterestApiPath, params, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) == 0 {
+		return nil, fmt.Errorf("no open interest for %s", currencyPair.ToSymbol("-"))
+	}
+
+	return &OpenInterest{
+		Pair:         currencyPair,
+		ContractType: contractType,
+		Amount:       resp[0].Volume,
+		Value:        resp[0].Amount,
+		Timestamp:    ts,
+	}, nil
+}
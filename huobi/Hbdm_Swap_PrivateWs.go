@@ -0,0 +1,368 @@
+package huobi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/logger"
+)
+
+const hbdmPrivateWsHost = "api.hbdm.com"
+
+type hbdmNotifyResponse struct {
+	Op      string          `json:"op"`
+	Topic   string          `json:"topic"`
+	Ts      json.RawMessage `json:"ts"`
+	ErrCode int             `json:"err-code"`
+	ErrMsg  string          `json:"err-msg"`
+	Data    json.RawMessage `json:"data"`
+}
+
+//永续合约私有数据推送(订单/持仓/账户)
+type HbdmSwapPrivateWs struct {
+	*WsBuilder
+	connLock     sync.Mutex
+	wsConn       *WsConn
+	base         *Hbdm
+	path         string
+	contractType string
+	authCh       chan error
+	isAuth       bool
+
+	orderCallback    func(*FutureOrder)
+	positionCallback func(*FuturePosition)
+	accountCallback  func(*FutureAccount)
+
+	symbolMapper *SymbolMapper
+}
+
+var (
+	_ FuturesPrivateWsApi = (*HbdmSwapPrivateWs)(nil)
+	_ SymbolMapperAware   = (*HbdmSwapPrivateWs)(nil)
+)
+
+//构建币本位永续合约私有ws
+func NewHbdmSwapPrivateWs(config *APIConfig) *HbdmSwapPrivateWs {
+	return newHbdmSwapPrivateWs(config, "/swap-notification", SWAP_CONTRACT)
+}
+
+//构建usdt本位永续合约私有ws
+func NewHbdmLinearSwapPrivateWs(config *APIConfig) *HbdmSwapPrivateWs {
+	return newHbdmSwapPrivateWs(config, "/linear-swap-notification", SWAP_USDT_CONTRACT)
+}
+
+//设置后使用交易规则把推送的合约代码映射回交易对
+func (ws *HbdmSwapPrivateWs) SetSymbolMapper(mapper *SymbolMapper) {
+	ws.symbolMapper = mapper
+}
+
+func (ws *HbdmSwapPrivateWs) contractPair(contractCode string) CurrencyPair {
+	return ws.symbolMapper.PairOf(contractCode, adaptSwapContractCode)
+}
+
+func newHbdmSwapPrivateWs(config *APIConfig, path, contractType string) *HbdmSwapPrivateWs {
+	ws := &HbdmSwapPrivateWs{
+		WsBuilder:    NewWsBuilder(),
+		base:         NewHbdm(config),
+		path:         path,
+		contractType: contractType,
+		authCh:       make(chan error, 1),
+	}
+	ws.WsBuilder = ws.WsBuilder.
+		WsUrl(fmt.Sprintf("wss://%s%s", hbdmPrivateWsHost, path)).
+		AutoReconnect().
+		DecompressFunc(GzipDecompress).
+		ConnectSuccessAfterSendMessage(ws.authMessage).
+		ProtoHandleFunc(ws.handle)
+	return ws
+}
+
+func (ws *HbdmSwapPrivateWs) OrderCallback(call func(order *FutureOrder)) {
+	ws.orderCallback = call
+}
+
+func (ws *HbdmSwapPrivateWs) PositionCallback(call func(position *FuturePosition)) {
+	ws.positionCallback = call
+}
+
+func (ws *HbdmSwapPrivateWs) AccountCallback(call func(account *FutureAccount)) {
+	ws.accountCallback = call
+}
+
+func (ws *HbdmSwapPrivateWs) authMessage() []byte {
+	params := url.Values{}
+	params.Set("AccessKeyId", ws.base.config.ApiKey)
+	params.Set("SignatureMethod", "HmacSHA256")
+	params.Set("SignatureVersion", "2")
+	params.Set("Timestamp", time.Now().UTC().Format("2006-01-02T15:04:05"))
+	payload := fmt.Sprintf("%s\n%s\n%s\n%s", "GET", hbdmPrivateWsHost, ws.path, params.Encode())
+	sign, _ := GetParamHmacSHA256Base64Sign(ws.base.config.ApiSecretKey, payload)
+
+	data, _ := json.Marshal(map[string]string{
+		"op":               "auth",
+		"type":             "api",
+		"AccessKeyId":      params.Get("AccessKeyId"),
+		"SignatureMethod":  params.Get("SignatureMethod"),
+		"SignatureVersion": params.Get("SignatureVersion"),
+		"Timestamp":        params.Get("Timestamp"),
+		"Signature":        sign,
+	})
+	return data
+}
+
+//建立连接，连接成功后发送鉴权消息；连接失败时返回错误，下次Login时重试
+func (ws *HbdmSwapPrivateWs) connect() error {
+	ws.connLock.Lock()
+	defer ws.connLock.Unlock()
+	if ws.wsConn != nil {
+		return nil
+	}
+	c, err := ws.WsBuilder.BuildWithContext(context.Background())
+	if err != nil {
+		return err
+	}
+	ws.wsConn = c
+	return nil
+}
+
+func (ws *HbdmSwapPrivateWs) Login() error {
+	if ws.base.config.ApiKey == "" || ws.base.config.ApiSecretKey == "" {
+		return EX_ERR_NOT_FIND_APIKEY
+	}
+
+	if ws.isAuth {
+		return nil
+	}
+
+	if err := ws.connect(); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-ws.authCh:
+		ws.isAuth = err == nil
+		return err
+	case <-time.After(wsAuthTimeout):
+		return errors.New("[hbdm] ws auth timeout")
+	}
+}
+
+func (ws *HbdmSwapPrivateWs) subscribe(topic string) error {
+	if ws.wsConn == nil {
+		return errors.New("[hbdm] please login first")
+	}
+	return ws.wsConn.Subscribe(map[string]interface{}{
+		"op":    "sub",
+		"cid":   topic,
+		"topic": topic,
+	})
+}
+
+func (ws *HbdmSwapPrivateWs) adaptContractCode(pair CurrencyPair) string {
+	if ws.contractType == SWAP_USDT_CONTRACT {
+		return pair.AdaptUsdToUsdt().ToSymbol("-")
+	}
+	return pair.AdaptUsdtToUsd().ToSymbol("-")
+}
+
+func (ws *HbdmSwapPrivateWs) SubscribeOrder(pair CurrencyPair, contractType string) error {
+	if ws.orderCallback == nil {
+		return errors.New("please set order callback func")
+	}
+	return ws.subscribe("orders." + ws.adaptContractCode(pair))
+}
+
+func (ws *HbdmSwapPrivateWs) SubscribePosition(pair CurrencyPair, contractType string) error {
+	if ws.positionCallback == nil {
+		return errors.New("please set position callback func")
+	}
+	return ws.subscribe("positions." + ws.adaptContractCode(pair))
+}
+
+func (ws *HbdmSwapPrivateWs) SubscribeAccount(pair CurrencyPair) error {
+	if ws.accountCallback == nil {
+		return errors.New("please set account callback func")
+	}
+	return ws.subscribe("accounts." + ws.adaptContractCode(pair))
+}
+
+func (ws *HbdmSwapPrivateWs) handle(msg []byte) error {
+	var resp hbdmNotifyResponse
+	err := json.Unmarshal(msg, &resp)
+	if err != nil {
+		logger.Errorf("[hbdm] json unmarshal ws response error [%s] , response data = %s", err, string(msg))
+		return err
+	}
+
+	switch resp.Op {
+	case "ping":
+		ws.wsConn.SendJsonMessage(map[string]interface{}{
+			"op": "pong",
+			"ts": resp.Ts,
+		})
+		return nil
+	case "auth":
+		var err error
+		if resp.ErrCode != 0 {
+			err = fmt.Errorf("[hbdm] ws auth error , err-code=%d , err-msg=%s", resp.ErrCode, resp.ErrMsg)
+		}
+		select {
+		case ws.authCh <- err:
+		default:
+		}
+		return nil
+	case "sub":
+		if resp.ErrCode != 0 {
+			logger.Errorf("[hbdm] subscribe %s error , err-code=%d , err-msg=%s", resp.Topic, resp.ErrCode, resp.ErrMsg)
+		}
+		return nil
+	case "notify":
+		topic := strings.ToLower(resp.Topic)
+		if strings.HasPrefix(topic, "orders.") {
+			return ws.handleOrder(msg)
+		}
+		if strings.HasPrefix(topic, "positions.") {
+			return ws.handlePosition(resp.Data)
+		}
+		if strings.HasPrefix(topic, "accounts.") {
+			return ws.handleAccount(resp.Data)
+		}
+	}
+
+	logger.Debugf("[hbdm] ignore ws message: %s", string(msg))
+
+	return nil
+}
+
+func (ws *HbdmSwapPrivateWs) handleOrder(msg []byte) error {
+	var ord struct {
+		OrderInfo
+		Profit float64 `json:"profit"`
+	}
+	err := json.Unmarshal(msg, &ord)
+	if err != nil {
+		return err
+	}
+
+	futureOrder := &FutureOrder{
+		ClientOid:    fmt.Sprint(ord.ClientOrderId),
+		OrderID:      ord.OrderId,
+		OrderID2:     fmt.Sprint(ord.OrderId),
+		Price:        ord.Price.Float64(),
+		Amount:       ord.Volume.Float64(),
+		AvgPrice:     ord.TradeAvgPrice.Float64(),
+		DealAmount:   ord.TradeVolume.Float64(),
+		Decimals:     ord.decimals(),
+		OrderTime:    ord.CreatedAt,
+		Status:       ws.base.adaptOrderStatus(ord.Status),
+		Currency:     ws.contractPair(ord.ContractCode),
+		OType:        ws.base.adaptOffsetDirectionToOpenType(ord.Offset, ord.Direction),
+		LeverRate:    ord.LeverRate,
+		Fee:          ord.Fee,
+		Profit:       ord.Profit,
+		ContractName: ord.ContractCode,
+	}
+
+	switch ord.OrderPriceType {
+	case "post_only":
+		futureOrder.OrderType = ORDER_FEATURE_POST_ONLY
+	case "fok":
+		futureOrder.OrderType = ORDER_FEATURE_FOK
+	case "ioc":
+		futureOrder.OrderType = ORDER_FEATURE_IOC
+	}
+
+	ws.orderCallback(futureOrder)
+
+	return nil
+}
+
+func (ws *HbdmSwapPrivateWs) handlePosition(data json.RawMessage) error {
+	var positions []struct {
+		ContractCode string  `json:"contract_code"`
+		Volume       float64 `json:"volume"`
+		Available    float64 `json:"available"`
+		CostOpen     float64 `json:"cost_open"`
+		CostHold     float64 `json:"cost_hold"`
+		ProfitUnreal float64 `json:"profit_unreal"`
+		ProfitRate   float64 `json:"profit_rate"`
+		Profit       float64 `json:"profit"`
+		LeverRate    float64 `json:"lever_rate"`
+		Direction    string  `json:"direction"`
+	}
+	err := json.Unmarshal(data, &positions)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range positions {
+		pos := &FuturePosition{
+			Symbol:       ws.contractPair(p.ContractCode),
+			ContractType: ws.contractType,
+			LeverRate:    p.LeverRate,
+		}
+		if p.Direction == "buy" {
+			pos.BuyAmount = p.Volume
+			pos.BuyAvailable = p.Available
+			pos.BuyPriceAvg = p.CostOpen
+			pos.BuyPriceCost = p.CostHold
+			pos.BuyProfit = p.ProfitUnreal
+			pos.BuyProfitReal = p.Profit
+			pos.LongPnlRatio = p.ProfitRate
+		} else {
+			pos.SellAmount = p.Volume
+			pos.SellAvailable = p.Available
+			pos.SellPriceAvg = p.CostOpen
+			pos.SellPriceCost = p.CostHold
+			pos.SellProfit = p.ProfitUnreal
+			pos.SellProfitReal = p.Profit
+			pos.ShortPnlRatio = p.ProfitRate
+		}
+		ws.positionCallback(pos)
+	}
+
+	return nil
+}
+
+func (ws *HbdmSwapPrivateWs) handleAccount(data json.RawMessage) error {
+	var accounts []struct {
+		Symbol        string  `json:"symbol"`
+		MarginAsset   string  `json:"margin_asset"` //usdt本位合约
+		MarginBalance float64 `json:"margin_balance"`
+		MarginFrozen  float64 `json:"margin_frozen"`
+		ProfitReal    float64 `json:"profit_real"`
+		ProfitUnreal  float64 `json:"profit_unreal"`
+		RiskRate      float64 `json:"risk_rate"`
+	}
+	err := json.Unmarshal(data, &accounts)
+	if err != nil {
+		return err
+	}
+
+	acc := &FutureAccount{FutureSubAccounts: make(map[Currency]FutureSubAccount, len(accounts))}
+	for _, a := range accounts {
+		currency := NewCurrency(a.Symbol, "")
+		if a.MarginAsset != "" {
+			currency = NewCurrency(a.MarginAsset, "")
+		}
+		acc.FutureSubAccounts[currency] = FutureSubAccount{
+			Currency:      currency,
+			AccountRights: a.MarginBalance,
+			KeepDeposit:   a.MarginFrozen,
+			ProfitReal:    a.ProfitReal,
+			ProfitUnreal:  a.ProfitUnreal,
+			RiskRate:      a.RiskRate,
+		}
+	}
+
+	ws.accountCallback(acc)
+
+	return nil
+}
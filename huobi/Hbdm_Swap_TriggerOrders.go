@@ -0,0 +1,172 @@
+package huobi
+
+import (
+	"fmt"
+	"net/url"
+
+	. "github.com/mrwill84/goex"
+)
+
+var (
+	_ FutureTriggerOrderAPI = (*HbdmSwap)(nil)
+)
+
+const (
+	triggerOrderApiPath      = "/swap-api/v1/swap_trigger_order"
+	triggerCancelApiPath     = "/swap-api/v1/swap_trigger_cancel"
+	triggerOpenOrdersApiPath = "/swap-api/v1/swap_trigger_openorders"
+
+	triggerOpenOrdersPageSize = 50
+)
+
+type triggerOrderResponse struct {
+	OrderId        string  `json:"order_id_str"`
+	ContractCode   string  `json:"contract_code"`
+	TriggerType    string  `json:"trigger_type"` //ge: 大于等于 , le: 小于等于
+	Volume         float64 `json:"volume"`
+	Direction      string  `json:"direction"`
+	Offset         string  `json:"offset"`
+	OrderPrice     float64 `json:"order_price"`
+	TriggerPrice   float64 `json:"trigger_price"`
+	OrderPriceType string  `json:"order_price_type"`
+	CreatedAt      int64   `json:"created_at"`
+}
+
+//止损单在价格向不利方向突破时触发，止盈单相反
+func adaptTriggerType(orderType TriggerOrderType, direction string) string {
+	stop := orderType == TRIGGER_STOP_MARKET || orderType == TRIGGER_STOP_LIMIT
+	if stop == (direction == "buy") {
+		return "ge"
+	}
+	return "le"
+}
+
+//火币计划委托触发后按开平方向下单，平仓单天然只减仓；不支持跟踪止损和ClosePosition
+func (swap *HbdmSwap) PlaceTriggerOrder(ord *TriggerOrder) (*TriggerOrder, error) {
+	if ord.Type == TRIGGER_TRAILING_STOP {
+		return nil, EX_ERR_NOT_SUPPORTED.Wrap("", "trailing stop")
+	}
+	if ord.ClosePosition {
+		return nil, EX_ERR_NOT_SUPPORTED.Wrap("", "close position trigger order")
+	}
+
+	direction, offset := swap.base.adaptOpenType(ord.OType)
+	if direction == "" {
+		return nil, EX_ERR_INVALID_ORDER_PARAM
+	}
+
+	param := url.Values{}
+	param.Set("contract_code", ord.Currency.ToSymbol("-"))
+	param.Set("trigger_type", adaptTriggerType(ord.Type, direction))
+	param.Set("trigger_price", FloatToString(ord.TriggerPrice, 8))
+	param.Set("volume", FloatToString(ord.Amount, 0))
+	param.Set("direction", direction)
+	param.Set("offset", offset)
+	param.Set("lever_rate", fmt.Sprintf("%.0f", swap.leverRate(ord.Currency)))
+
+	switch ord.Type {
+	case TRIGGER_STOP_LIMIT, TRIGGER_TAKE_PROFIT_LIMIT:
+		param.Set("order_price_type", "limit")
+		param.Set("order_price", FloatToString(ord.Price, 8))
+	case TRIGGER_STOP_MARKET, TRIGGER_TAKE_PROFIT_MARKET:
+		param.Set("order_price_type", "optimal_20")
+	default:
+		return nil, fmt.Errorf("unknown trigger order type %s", ord.Type)
+	}
+
+	var response struct {
+		OrderId string `json:"order_id_str"`
+	}
+	err := swap.base.doRequest(triggerOrderApiPath, &param, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	placed := *ord
+	placed.OrderId = response.OrderId
+	placed.Status = ORDER_UNFINISH
+	return &placed, nil
+}
+
+func (swap *HbdmSwap) CancelTriggerOrder(currencyPair CurrencyPair, contractType, orderId string) (bool, error) {
+	param := url.Values{}
+	param.Set("contract_code", currencyPair.ToSymbol("-"))
+	param.Set("order_id", orderId)
+
+	var cancelResponse struct {
+		Errors []struct {
+			ErrCode int    `json:"err_code"`
+			ErrMsg  string `json:"err_msg"`
+		} `json:"errors"`
+	}
+
+	err := swap.base.doRequest(triggerCancelApiPath, &param, &cancelResponse)
+	if err != nil {
+		return false, err
+	}
+
+	if len(cancelResponse.Errors) > 0 {
+		return false, adaptHbdmError(cancelResponse.Errors[0].ErrCode, cancelResponse.Errors[0].ErrMsg)
+	}
+
+	return true, nil
+}
+
+func (swap *HbdmSwap) GetUnfinishTriggerOrders(currencyPair CurrencyPair, contractType string) ([]TriggerOrder, error) {
+	param := url.Values{}
+	param.Set("contract_code", currencyPair.ToSymbol("-"))
+	param.Set("page_size", fmt.Sprint(triggerOpenOrdersPageSize))
+
+	var orders []TriggerOrder
+	for page := 1; ; page++ {
+		param.Set("page_index", fmt.Sprint(page))
+
+		var response struct {
+			Orders    []triggerOrderResponse `json:"orders"`
+			TotalPage int                    `json:"total_page"`
+		}
+		err := swap.base.doRequest(triggerOpenOrdersApiPath, &param, &response)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, o := range response.Orders {
+			orders = append(orders, swap.adaptTriggerOrder(o, currencyPair, contractType))
+		}
+
+		if page >= response.TotalPage {
+			return orders, nil
+		}
+	}
+}
+
+func (swap *HbdmSwap) adaptTriggerOrder(o triggerOrderResponse, currencyPair CurrencyPair, contractType string) TriggerOrder {
+	ord := TriggerOrder{
+		OrderId:      o.OrderId,
+		Currency:     currencyPair,
+		ContractType: contractType,
+		OType:        swap.base.adaptOffsetDirectionToOpenType(o.Offset, o.Direction),
+		Amount:       o.Volume,
+		TriggerPrice: o.TriggerPrice,
+		ReduceOnly:   o.Offset == "close",
+		Status:       ORDER_UNFINISH,
+		OrderTime:    o.CreatedAt,
+	}
+
+	stop := (o.Direction == "buy") == (o.TriggerType == "ge")
+	limit := o.OrderPriceType == "limit"
+	switch {
+	case stop && limit:
+		ord.Type = TRIGGER_STOP_LIMIT
+	case stop:
+		ord.Type = TRIGGER_STOP_MARKET
+	case limit:
+		ord.Type = TRIGGER_TAKE_PROFIT_LIMIT
+	default:
+		ord.Type = TRIGGER_TAKE_PROFIT_MARKET
+	}
+	if limit {
+		ord.Price = o.OrderPrice
+	}
+	return ord
+}
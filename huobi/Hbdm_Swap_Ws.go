@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	. "github.com/mrwill84/goex"
@@ -15,14 +14,20 @@ import (
 
 type HbdmSwapWs struct {
 	*WsBuilder
-	sync.Once
-	wsConn *WsConn
+	wsPool *WsPool
 
 	tickerCallback func(*FutureTicker)
 	depthCallback  func(*Depth)
 	tradeCallback  func(*Trade, string)
+
+	symbolMapper *SymbolMapper
 }
 
+var (
+	_ FuturesWsApi      = (*HbdmSwapWs)(nil)
+	_ SymbolMapperAware = (*HbdmSwapWs)(nil)
+)
+
 func NewHbdmSwapWs() *HbdmSwapWs {
 	ws := &HbdmSwapWs{WsBuilder: NewWsBuilder()}
 	ws.WsBuilder = ws.WsBuilder.
@@ -30,7 +35,8 @@ func NewHbdmSwapWs() *HbdmSwapWs {
 		//ProxyUrl("socks5://127.0.0.1:1080").
 		AutoReconnect().
 		DecompressFunc(GzipDecompress).
-		ProtoHandleFunc(ws.handle)
+		ConnProtoHandleFunc(ws.handle)
+	ws.wsPool = NewWsPool(ws.WsBuilder, wsMaxSubsPerConn)
 	return ws
 }
 
@@ -42,10 +48,16 @@ func NewHbdmLinearSwapWs() *HbdmSwapWs {
 		//ProxyUrl("socks5://127.0.0.1:1080").
 		AutoReconnect().
 		DecompressFunc(GzipDecompress).
-		ProtoHandleFunc(ws.handle)
+		ConnProtoHandleFunc(ws.handle)
+	ws.wsPool = NewWsPool(ws.WsBuilder, wsMaxSubsPerConn)
 	return ws
 }
 
+//设置后使用交易规则把推送的合约代码(BTC-USD)映射回交易对
+func (ws *HbdmSwapWs) SetSymbolMapper(mapper *SymbolMapper) {
+	ws.symbolMapper = mapper
+}
+
 func (ws *HbdmSwapWs) SetCallbacks(tickerCallback func(*FutureTicker),
 	depthCallback func(*Depth),
 	tradeCallback func(*Trade, string)) {
@@ -71,9 +83,7 @@ func (ws *HbdmSwapWs) SubscribeTicker(pair CurrencyPair, contract string) error
 	}
 
 	if contract == SWAP_CONTRACT || contract == SWAP_USDT_CONTRACT {
-		return ws.subscribe(map[string]interface{}{
-			"id":  "ticker_1",
-			"sub": fmt.Sprintf("market.%s.detail", pair.ToSymbol("-"))})
+		return ws.subscribe(ws.topic(pair, "detail"))
 	}
 
 	return errors.New("not implement")
@@ -85,9 +95,7 @@ func (ws *HbdmSwapWs) SubscribeDepth(pair CurrencyPair, contract string) error {
 	}
 
 	if contract == SWAP_CONTRACT || contract == SWAP_USDT_CONTRACT {
-		return ws.subscribe(map[string]interface{}{
-			"id":  "swap.depth",
-			"sub": fmt.Sprintf("market.%s.depth.step6", pair.ToSymbol("-"))})
+		return ws.subscribe(ws.topic(pair, "depth.step6"))
 	}
 
 	return errors.New("not implement")
@@ -99,32 +107,43 @@ func (ws *HbdmSwapWs) SubscribeTrade(pair CurrencyPair, contract string) error {
 	}
 
 	if contract == SWAP_CONTRACT || contract == SWAP_USDT_CONTRACT {
-		return ws.subscribe(map[string]interface{}{
-			"id":  "swap_trade_3",
-			"sub": fmt.Sprintf("market.%s.trade.detail", pair.ToSymbol("-"))})
+		return ws.subscribe(ws.topic(pair, "trade.detail"))
 	}
 
 	return errors.New("not implement")
 }
 
-func (ws *HbdmSwapWs) subscribe(sub map[string]interface{}) error {
-	//	log.Println(sub)
-	ws.connectWs()
-	return ws.wsConn.Subscribe(sub)
+func (ws *HbdmSwapWs) UnsubscribeTicker(pair CurrencyPair, contract string) error {
+	return wsUnsubscribe(ws.wsPool, ws.topic(pair, "detail"))
+}
+
+func (ws *HbdmSwapWs) UnsubscribeDepth(pair CurrencyPair, contract string) error {
+	return wsUnsubscribe(ws.wsPool, ws.topic(pair, "depth.step6"))
+}
+
+func (ws *HbdmSwapWs) UnsubscribeTrade(pair CurrencyPair, contract string) error {
+	return wsUnsubscribe(ws.wsPool, ws.topic(pair, "trade.detail"))
+}
+
+//market.BTC-USD.detail
+func (ws *HbdmSwapWs) topic(pair CurrencyPair, channel string) string {
+	return fmt.Sprintf("market.%s.%s", pair.ToSymbol("-"), channel)
 }
 
-func (ws *HbdmSwapWs) connectWs() {
-	ws.Do(func() {
-		ws.wsConn = ws.WsBuilder.Build()
-	})
+func (ws *HbdmSwapWs) subscribe(topic string) error {
+	return wsSubscribe(ws.wsPool, topic)
 }
 
-func (ws *HbdmSwapWs) handle(msg []byte) error {
+func (ws *HbdmSwapWs) handle(c *WsConn, msg []byte) error {
 	logger.Debug("ws message data:", string(msg))
 	//心跳
 	if bytes.Contains(msg, []byte("ping")) {
 		pong := bytes.ReplaceAll(msg, []byte("ping"), []byte("pong"))
-		ws.wsConn.SendMessage(pong)
+		c.SendMessage(pong)
+		return nil
+	}
+
+	if wsAckSubscription(c, msg) {
 		return nil
 	}
 
@@ -135,20 +154,16 @@ func (ws *HbdmSwapWs) handle(msg []byte) error {
 	}
 
 	if resp.Ch == "" {
-		logger.Warnf("[%s] ch == \"\" , msg=%s", ws.wsConn.WsUrl, string(msg))
+		logger.Warnf("[%s] ch == \"\" , msg=%s", c.WsUrl, string(msg))
 		return nil
 	}
 
-	ts := time.Now()
-	if resp.Ts > 0 {
-		ts = time.Unix(0, resp.Ts*int64(time.Millisecond))
-	}
-
 	pair, contract, err := ws.parseCurrencyAndContract(resp.Ch)
 	if err != nil {
-		logger.Errorf("[%s] parse currency and contract err=%s", ws.wsConn.WsUrl, err)
+		logger.Errorf("[%s] parse currency and contract err=%s", c.WsUrl, err)
 		return err
 	}
+	pair = ws.symbolMapper.PairOf(wsChSymbol(resp.Ch), adaptSwapContractCode)
 
 	if strings.Contains(resp.Ch, ".depth.") {
 		var depResp DepthResponse
@@ -200,7 +215,7 @@ func (ws *HbdmSwapWs) handle(msg []byte) error {
 		return nil
 	}
 
-	logger.Errorf("[%s] unknown message, msg=%s", ws.wsConn.WsUrl, string(msg))
+	logger.Errorf("[%s] unknown message, msg=%s", c.WsUrl, string(msg))
 
 	return nil
 }
@@ -3,11 +3,13 @@ package huobi
 import (
 	"github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/internal/replay"
 	"testing"
 	"time"
 )
 
 func TestNewHbdmSwapWs(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	logger.SetLevel(logger.DEBUG)
 
 	ws := NewHbdmSwapWs()
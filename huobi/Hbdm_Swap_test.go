@@ -2,6 +2,7 @@ package huobi
 
 import (
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 	"net/http"
 	"testing"
 	"time"
@@ -19,43 +20,67 @@ func init() {
 	})
 }
 
+//回放testdata中的fixture , GOEX_RECORD=1时请求真实的交易所并更新fixture
+func newReplayHbdmSwap(t *testing.T, fixture string) *HbdmSwap {
+	srv := replay.NewServer(t, "testdata/"+fixture, defaultBaseUrl)
+	return NewHbdmSwap(&goex.APIConfig{
+		HttpClient: srv.Client(),
+		Endpoint:   srv.URL,
+	})
+}
+
 func TestHbdmSwap_GetFutureTicker(t *testing.T) {
-	t.Log(swap.GetFutureTicker(goex.BTC_USD, goex.SWAP_CONTRACT))
+	ticker, err := newReplayHbdmSwap(t, "swap_market.json").GetFutureTicker(goex.BTC_USD, goex.SWAP_CONTRACT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_ticker.golden.json", ticker)
 }
 
 func TestHbdmSwap_GetFutureDepth(t *testing.T) {
-	dep, err := swap.GetFutureDepth(goex.BTC_USD, goex.SWAP_CONTRACT, 5)
-	t.Log(err)
-	t.Log(dep.AskList)
-	t.Log(dep.BidList)
+	dep, err := newReplayHbdmSwap(t, "swap_market.json").GetFutureDepth(goex.BTC_USD, goex.SWAP_CONTRACT, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_depth.golden.json", dep, "Timestamp")
 }
 
 func TestHbdmSwap_GetFutureUserinfo(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(swap.GetFutureUserinfo(goex.NewCurrencyPair2("DOT_USD")))
 }
 
 func TestHbdmSwap_GetFuturePosition(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(swap.GetFuturePosition(goex.NewCurrencyPair2("DOT_USD"), goex.SWAP_CONTRACT))
 }
 
 func TestHbdmSwap_LimitFuturesOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	//784115347040780289
 	t.Log(swap.LimitFuturesOrder(goex.NewCurrencyPair2("DOT_USD"), goex.SWAP_CONTRACT, "6.5", "1", goex.OPEN_SELL))
 }
 
 func TestHbdmSwap_FutureCancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(swap.FutureCancelOrder(goex.NewCurrencyPair2("DOT_USD"), goex.SWAP_CONTRACT, "784118017750929408"))
 }
 
 func TestHbdmSwap_GetUnfinishFutureOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(swap.GetUnfinishFutureOrders(goex.NewCurrencyPair2("DOT_USD"), goex.SWAP_CONTRACT))
 }
 
 func TestHbdmSwap_GetFutureOrder(t *testing.T) {
-	t.Log(swap.GetFutureOrder("784118017750929408", goex.NewCurrencyPair2("DOT_USD"), goex.SWAP_CONTRACT))
+	ord, err := newReplayHbdmSwap(t, "swap_market.json").GetFutureOrder("918800256249405440", goex.BTC_USD, goex.SWAP_CONTRACT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_order.golden.json", ord)
 }
 
 func TestHbdmSwap_GetFutureOrderHistory(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(swap.GetFutureOrderHistory(goex.NewCurrencyPair2("KSM_USD"), goex.SWAP_CONTRACT,
 		goex.OptionalParameter{}.Optional("start_time", time.Now().Add(-5*24*time.Hour).Unix()*1000),
 		goex.OptionalParameter{}.Optional("end_time", time.Now().Unix()*1000)))
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	. "github.com/mrwill84/goex"
@@ -19,6 +18,50 @@ type WsResponse struct {
 	Tick json.RawMessage
 }
 
+//订阅/取消订阅的回应，id为请求中的id
+type wsSubResponse struct {
+	Id      string `json:"id"`
+	Status  string `json:"status"`
+	ErrCode string `json:"err-code"`
+	ErrMsg  string `json:"err-msg"`
+}
+
+//火币没有公开单个连接的订阅数限制，按每个连接100个订阅分散推送压力
+const wsMaxSubsPerConn = 100
+
+//topic同时作为订阅的Key和请求id
+func wsSubscribe(pool *WsPool, topic string) error {
+	return pool.SubscribeWithId(topic, topic, map[string]interface{}{"id": topic, "sub": topic})
+}
+
+func wsUnsubscribe(pool *WsPool, topic string) error {
+	return pool.Unsubscribe(topic, map[string]interface{}{"id": topic, "unsub": topic})
+}
+
+//按id关联订阅回应，ws的err-code和现货接口相同；不是订阅回应时返回false
+func wsAckSubscription(c *WsConn, msg []byte) bool {
+	var r wsSubResponse
+	if json.Unmarshal(msg, &r) != nil || r.Status == "" {
+		return false
+	}
+
+	var err error
+	if r.Status != "ok" {
+		err = spotErrorCodeTable.Wrap(r.ErrCode, r.ErrMsg)
+	}
+	c.AckSubscription(r.Id, err)
+	return true
+}
+
+//ws推送ch中的symbol , 如market.BTC_CQ.detail中的BTC_CQ
+func wsChSymbol(ch string) string {
+	el := strings.Split(ch, ".")
+	if len(el) < 2 {
+		return ""
+	}
+	return el[1]
+}
+
 type TradeResponse struct {
 	Id   int64
 	Ts   int64
@@ -60,14 +103,20 @@ type DepthResponse struct {
 
 type HbdmWs struct {
 	*WsBuilder
-	sync.Once
-	wsConn *WsConn
+	wsPool *WsPool
 
 	tickerCallback func(*FutureTicker)
 	depthCallback  func(*Depth)
 	tradeCallback  func(*Trade, string)
+
+	symbolMapper *SymbolMapper
 }
 
+var (
+	_ FuturesWsApi      = (*HbdmWs)(nil)
+	_ SymbolMapperAware = (*HbdmWs)(nil)
+)
+
 func NewHbdmWs() *HbdmWs {
 	hbdmWs := &HbdmWs{WsBuilder: NewWsBuilder()}
 	hbdmWs.WsBuilder = hbdmWs.WsBuilder.
@@ -76,7 +125,8 @@ func NewHbdmWs() *HbdmWs {
 		//Heartbeat([]byte("{\"event\": \"ping\"} "), 30*time.Second).
 		//Heartbeat(func() []byte { return []byte("{\"op\":\"ping\"}") }(), 5*time.Second).
 		DecompressFunc(GzipDecompress).
-		ProtoHandleFunc(hbdmWs.handle)
+		ConnProtoHandleFunc(hbdmWs.handle)
+	hbdmWs.wsPool = NewWsPool(hbdmWs.WsBuilder, wsMaxSubsPerConn)
 	go hbdmInit()
 	return hbdmWs
 }
@@ -89,6 +139,11 @@ func (hbdmWs *HbdmWs) SetCallbacks(tickerCallback func(*FutureTicker),
 	hbdmWs.tradeCallback = tradeCallback
 }
 
+//设置后使用交易规则把推送的合约代码(BTC_CQ)映射回交易对
+func (hbdmWs *HbdmWs) SetSymbolMapper(mapper *SymbolMapper) {
+	hbdmWs.symbolMapper = mapper
+}
+
 func (hbdmWs *HbdmWs) TickerCallback(call func(ticker *FutureTicker)) {
 	hbdmWs.tickerCallback = call
 }
@@ -104,46 +159,53 @@ func (hbdmWs *HbdmWs) SubscribeTicker(pair CurrencyPair, contract string) error
 	if hbdmWs.tickerCallback == nil {
 		return errors.New("please set ticker callback func")
 	}
-	return hbdmWs.subscribe(map[string]interface{}{
-		"id":  "ticker_1",
-		"sub": fmt.Sprintf("market.%s_%s.detail", pair.CurrencyA.Symbol, hbdmWs.adaptContractSymbol(contract))})
+	return hbdmWs.subscribe(hbdmWs.topic(pair, contract, "detail"))
 }
 
 func (hbdmWs *HbdmWs) SubscribeDepth(pair CurrencyPair, contract string) error {
 	if hbdmWs.depthCallback == nil {
 		return errors.New("please set depth callback func")
 	}
-	return hbdmWs.subscribe(map[string]interface{}{
-		"id":  "futures.depth",
-		"sub": fmt.Sprintf("market.%s_%s.depth.size_20.high_freq", pair.CurrencyA.Symbol, hbdmWs.adaptContractSymbol(contract))})
+	return hbdmWs.subscribe(hbdmWs.topic(pair, contract, "depth.size_20.high_freq"))
 }
 
 func (hbdmWs *HbdmWs) SubscribeTrade(pair CurrencyPair, contract string) error {
 	if hbdmWs.tradeCallback == nil {
 		return errors.New("please set trade callback func")
 	}
-	return hbdmWs.subscribe(map[string]interface{}{
-		"id":  "trade_3",
-		"sub": fmt.Sprintf("market.%s_%s.trade.detail", pair.CurrencyA.Symbol, hbdmWs.adaptContractSymbol(contract))})
+	return hbdmWs.subscribe(hbdmWs.topic(pair, contract, "trade.detail"))
+}
+
+func (hbdmWs *HbdmWs) UnsubscribeTicker(pair CurrencyPair, contract string) error {
+	return wsUnsubscribe(hbdmWs.wsPool, hbdmWs.topic(pair, contract, "detail"))
 }
 
-func (hbdmWs *HbdmWs) subscribe(sub map[string]interface{}) error {
-	//	log.Println(sub)
-	hbdmWs.connectWs()
-	return hbdmWs.wsConn.Subscribe(sub)
+func (hbdmWs *HbdmWs) UnsubscribeDepth(pair CurrencyPair, contract string) error {
+	return wsUnsubscribe(hbdmWs.wsPool, hbdmWs.topic(pair, contract, "depth.size_20.high_freq"))
 }
 
-func (hbdmWs *HbdmWs) connectWs() {
-	hbdmWs.Do(func() {
-		hbdmWs.wsConn = hbdmWs.WsBuilder.Build()
-	})
+func (hbdmWs *HbdmWs) UnsubscribeTrade(pair CurrencyPair, contract string) error {
+	return wsUnsubscribe(hbdmWs.wsPool, hbdmWs.topic(pair, contract, "trade.detail"))
 }
 
-func (hbdmWs *HbdmWs) handle(msg []byte) error {
+//market.BTC_CQ.detail
+func (hbdmWs *HbdmWs) topic(pair CurrencyPair, contract, channel string) string {
+	return fmt.Sprintf("market.%s_%s.%s", pair.CurrencyA.Symbol, hbdmWs.adaptContractSymbol(contract), channel)
+}
+
+func (hbdmWs *HbdmWs) subscribe(topic string) error {
+	return wsSubscribe(hbdmWs.wsPool, topic)
+}
+
+func (hbdmWs *HbdmWs) handle(c *WsConn, msg []byte) error {
 	//心跳
 	if bytes.Contains(msg, []byte("ping")) {
 		pong := bytes.ReplaceAll(msg, []byte("ping"), []byte("pong"))
-		hbdmWs.wsConn.SendMessage(pong)
+		c.SendMessage(pong)
+		return nil
+	}
+
+	if wsAckSubscription(c, msg) {
 		return nil
 	}
 
@@ -154,15 +216,16 @@ func (hbdmWs *HbdmWs) handle(msg []byte) error {
 	}
 
 	if resp.Ch == "" {
-		logger.Warnf("[%s] ch == \"\" , msg=%s", hbdmWs.wsConn.WsUrl, string(msg))
+		logger.Warnf("[%s] ch == \"\" , msg=%s", c.WsUrl, string(msg))
 		return nil
 	}
 
 	pair, contract, err := hbdmWs.parseCurrencyAndContract(resp.Ch)
 	if err != nil {
-		logger.Errorf("[%s] parse currency and contract err=%s", hbdmWs.wsConn.WsUrl, err)
+		logger.Errorf("[%s] parse currency and contract err=%s", c.WsUrl, err)
 		return err
 	}
+	pair = hbdmWs.symbolMapper.PairOf(wsChSymbol(resp.Ch), func(string) CurrencyPair { return pair })
 
 	if strings.Contains(resp.Ch, ".depth.") {
 		var depResp DepthResponse
@@ -208,7 +271,7 @@ func (hbdmWs *HbdmWs) handle(msg []byte) error {
 		return nil
 	}
 
-	logger.Errorf("[%s] unknown message, msg=%s", hbdmWs.wsConn.WsUrl, string(msg))
+	logger.Errorf("[%s] unknown message, msg=%s", c.WsUrl, string(msg))
 
 	return nil
 }
@@ -2,12 +2,14 @@ package huobi
 
 import (
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 	"log"
 	"testing"
 	"time"
 )
 
 func TestNewHbdmWs(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ws := NewHbdmWs()
 	ws.ProxyUrl("socks5://127.0.0.1:1080")
 
@@ -24,7 +26,7 @@ func TestNewHbdmWs(t *testing.T) {
 	})
 
 	t.Log(ws.SubscribeTicker(goex.BTC_USD, goex.QUARTER_CONTRACT))
-	t.Log(ws.SubscribeDepth(goex.BTC_USD, goex.NEXT_WEEK_CONTRACT, 0))
+	t.Log(ws.SubscribeDepth(goex.BTC_USD, goex.NEXT_WEEK_CONTRACT))
 	t.Log(ws.SubscribeTrade(goex.LTC_USD, goex.THIS_WEEK_CONTRACT))
 	time.Sleep(time.Minute)
 }
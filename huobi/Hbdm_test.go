@@ -2,6 +2,7 @@ package huobi
 
 import (
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 	"testing"
 	"time"
 )
@@ -13,52 +14,80 @@ var dm = NewHbdm(&goex.APIConfig{
 	ApiSecretKey: ""})
 
 func TestHbdm_GetFutureUserinfo(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(dm.GetFutureUserinfo())
 }
 
 func TestHbdm_GetFuturePosition(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(dm.GetFuturePosition(goex.BTC_USD, goex.QUARTER_CONTRACT))
 }
 
 func TestHbdm_PlaceFutureOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(dm.PlaceFutureOrder(goex.BTC_USD, goex.QUARTER_CONTRACT, "3800", "1", goex.OPEN_BUY, 0, 20))
 }
 
 func TestHbdm_FutureCancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(dm.FutureCancelOrder(goex.BTC_USD, goex.QUARTER_CONTRACT, "6"))
 }
 
 func TestHbdm_GetUnfinishFutureOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(dm.GetUnfinishFutureOrders(goex.BTC_USD, goex.QUARTER_CONTRACT))
 }
 
 func TestHbdm_GetFutureOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(dm.GetFutureOrders([]string{"6", "5"}, goex.BTC_USD, goex.QUARTER_CONTRACT))
 }
 
+//回放testdata中的fixture , GOEX_RECORD=1时请求真实的交易所并更新fixture
+func newReplayHbdm(t *testing.T, fixture string) *Hbdm {
+	srv := replay.NewServer(t, "testdata/"+fixture, defaultBaseUrl)
+	return NewHbdm(&goex.APIConfig{
+		HttpClient: srv.Client(),
+		Endpoint:   srv.URL,
+	})
+}
+
 func TestHbdm_GetFutureOrder(t *testing.T) {
-	t.Log(dm.GetFutureOrder("6", goex.BTC_USD, goex.QUARTER_CONTRACT))
+	ord, err := newReplayHbdm(t, "hbdm_market.json").GetFutureOrder("773131315209248768", goex.BTC_USD, goex.QUARTER_CONTRACT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/hbdm_order.golden.json", ord)
 }
 
 func TestHbdm_GetFutureTicker(t *testing.T) {
-	t.Log(dm.GetFutureTicker(goex.EOS_USD, goex.QUARTER_CONTRACT))
+	ticker, err := newReplayHbdm(t, "hbdm_market.json").GetFutureTicker(goex.BTC_USD, goex.QUARTER_CONTRACT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/hbdm_ticker.golden.json", ticker)
 }
 
 func TestHbdm_GetFutureDepth(t *testing.T) {
-	dep, err := dm.GetFutureDepth(goex.BTC_USD, goex.QUARTER_CONTRACT, 0)
-	t.Log(err)
-	t.Logf("%+v\n%+v", dep.AskList, dep.BidList)
+	dep, err := newReplayHbdm(t, "hbdm_market.json").GetFutureDepth(goex.BTC_USD, goex.QUARTER_CONTRACT, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/hbdm_depth.golden.json", dep, "Timestamp")
 }
 func TestHbdm_GetFutureIndex(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(dm.GetFutureIndex(goex.BTC_USD))
 }
 
 func TestHbdm_GetFutureEstimatedPrice(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(dm.GetFutureEstimatedPrice(goex.BTC_USD))
 }
 
 func TestHbdm_GetKlineRecords(t *testing.T) {
-	klines, _ := dm.GetKlineRecords(goex.QUARTER_CONTRACT, goex.EOS_USD, goex.KLINE_PERIOD_1MIN, 20, 0)
+	replay.SkipUnlessLive(t)
+	klines, _ := dm.GetKlineRecords(goex.QUARTER_CONTRACT, goex.EOS_USD, goex.KLINE_PERIOD_1MIN, 20)
 	for _, k := range klines {
 		tt := time.Unix(k.Timestamp, 0)
 		t.Log(k.Pair, tt, k.Open, k.Close, k.High, k.Low, k.Vol, k.Vol2)
@@ -1,6 +1,7 @@
 package huobi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -50,6 +51,14 @@ type HuoBiPro struct {
 	//ECDSAPrivateKey string
 }
 
+var (
+	_ API              = (*HuoBiPro)(nil)
+	_ ContextBinder    = (*HuoBiPro)(nil)
+	_ BatchOrderAPI    = (*HuoBiPro)(nil)
+	_ ClientOrderIdAPI = (*HuoBiPro)(nil)
+	_ MarketInfo       = (*HuoBiPro)(nil)
+)
+
 type HuoBiProSymbol struct {
 	BaseCurrency    string
 	QuoteCurrency   string
@@ -59,6 +68,7 @@ type HuoBiProSymbol struct {
 	MinValue        float64
 	SymbolPartition string
 	Symbol          string
+	State           string //online , offline , suspend , pre-online
 }
 
 func NewHuobiWithConfig(config *APIConfig) *HuoBiPro {
@@ -151,7 +161,7 @@ func (hbpro *HuoBiPro) GetAccountInfo(acc string) (AccountInfo, error) {
 	}
 
 	if respmap["status"].(string) != "ok" {
-		return AccountInfo{}, errors.New(respmap["err-code"].(string))
+		return AccountInfo{}, adaptSpotError(respmap)
 	}
 
 	var info AccountInfo
@@ -187,7 +197,7 @@ func (hbpro *HuoBiPro) GetAccount() (*Account, error) {
 	//log.Println(respmap)
 
 	if respmap["status"].(string) != "ok" {
-		return nil, errors.New(respmap["err-code"].(string))
+		return nil, adaptSpotError(respmap)
 	}
 
 	datamap := respmap["data"].(map[string]interface{})
@@ -227,20 +237,26 @@ func (hbpro *HuoBiPro) GetAccount() (*Account, error) {
 	return acc, nil
 }
 
-func (hbpro *HuoBiPro) placeOrder(amount, price string, pair CurrencyPair, orderType string) (string, error) {
+//cid为空时自动生成
+func (hbpro *HuoBiPro) placeOrder(amount, price string, pair CurrencyPair, orderType, cid string) (string, error) {
 	symbol := hbpro.Symbols[pair.ToLower().ToSymbol("")]
 
+	if cid == "" {
+		cid = NewClientOrderId(HUOBI_PRO)
+	}
+
 	path := "/v1/order/orders/place"
 	params := url.Values{}
 	params.Set("account-id", hbpro.accountId)
-	params.Set("client-order-id", GenerateOrderClientId(32))
-	params.Set("amount", FloatToString(ToFloat64(amount), int(symbol.AmountPrecision)))
+	params.Set("client-order-id", cid)
+	params.Set("amount", ToDecimal(amount).Truncate(int32(symbol.AmountPrecision)).String())
 	params.Set("symbol", pair.AdaptUsdToUsdt().ToLower().ToSymbol(""))
 	params.Set("type", orderType)
 
 	switch orderType {
-	case "buy-limit", "sell-limit":
-		params.Set("price", FloatToString(ToFloat64(price), int(symbol.PricePrecision)))
+	case "buy-market", "sell-market":
+	default:
+		params.Set("price", ToDecimal(price).Round(int32(symbol.PricePrecision)).String())
 	}
 
 	hbpro.buildPostForm("POST", path, &params)
@@ -258,7 +274,7 @@ func (hbpro *HuoBiPro) placeOrder(amount, price string, pair CurrencyPair, order
 	}
 
 	if respmap["status"].(string) != "ok" {
-		return "", errors.New(respmap["err-code"].(string))
+		return "", adaptSpotError(respmap)
 	}
 
 	return respmap["data"].(string), nil
@@ -266,24 +282,27 @@ func (hbpro *HuoBiPro) placeOrder(amount, price string, pair CurrencyPair, order
 
 func (hbpro *HuoBiPro) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
 	orderTy := "buy-limit"
-	if len(opt) > 0 {
-		switch opt[0] {
+	if tif, ok := GetTimeInForce(opt...); ok {
+		switch tif {
 		case PostOnly:
 			orderTy = "buy-limit-maker"
 		case Ioc:
 			orderTy = "buy-ioc"
 		case Fok:
 			orderTy = "buy-limit-fok"
-		default:
-			Log.Error("limit order optional parameter error ,opt= ", opt[0])
 		}
 	}
-	orderId, err := hbpro.placeOrder(amount, price, currency, orderTy)
+	cid := GetClientOrderId(opt...)
+	if cid == "" {
+		cid = NewClientOrderId(HUOBI_PRO)
+	}
+	orderId, err := hbpro.placeOrder(amount, price, currency, orderTy, cid)
 	if err != nil {
 		return nil, err
 	}
 	return &Order{
 		Currency: currency,
+		Cid:      cid,
 		OrderID:  ToInt(orderId),
 		OrderID2: orderId,
 		Amount:   ToFloat64(amount),
@@ -293,24 +312,27 @@ func (hbpro *HuoBiPro) LimitBuy(amount, price string, currency CurrencyPair, opt
 
 func (hbpro *HuoBiPro) LimitSell(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
 	orderTy := "sell-limit"
-	if len(opt) > 0 {
-		switch opt[0] {
+	if tif, ok := GetTimeInForce(opt...); ok {
+		switch tif {
 		case PostOnly:
 			orderTy = "sell-limit-maker"
 		case Ioc:
 			orderTy = "sell-ioc"
 		case Fok:
 			orderTy = "sell-limit-fok"
-		default:
-			Log.Error("limit order optional parameter error ,opt= ", opt[0])
 		}
 	}
-	orderId, err := hbpro.placeOrder(amount, price, currency, orderTy)
+	cid := GetClientOrderId(opt...)
+	if cid == "" {
+		cid = NewClientOrderId(HUOBI_PRO)
+	}
+	orderId, err := hbpro.placeOrder(amount, price, currency, orderTy, cid)
 	if err != nil {
 		return nil, err
 	}
 	return &Order{
 		Currency: currency,
+		Cid:      cid,
 		OrderID:  ToInt(orderId),
 		OrderID2: orderId,
 		Amount:   ToFloat64(amount),
@@ -319,7 +341,7 @@ func (hbpro *HuoBiPro) LimitSell(amount, price string, currency CurrencyPair, op
 }
 
 func (hbpro *HuoBiPro) MarketBuy(amount, price string, currency CurrencyPair) (*Order, error) {
-	orderId, err := hbpro.placeOrder(amount, price, currency, "buy-market")
+	orderId, err := hbpro.placeOrder(amount, price, currency, "buy-market", "")
 	if err != nil {
 		return nil, err
 	}
@@ -333,7 +355,7 @@ func (hbpro *HuoBiPro) MarketBuy(amount, price string, currency CurrencyPair) (*
 }
 
 func (hbpro *HuoBiPro) MarketSell(amount, price string, currency CurrencyPair) (*Order, error) {
-	orderId, err := hbpro.placeOrder(amount, price, currency, "sell-market")
+	orderId, err := hbpro.placeOrder(amount, price, currency, "sell-market", "")
 	if err != nil {
 		return nil, err
 	}
@@ -348,15 +370,20 @@ func (hbpro *HuoBiPro) MarketSell(amount, price string, currency CurrencyPair) (
 
 func (hbpro *HuoBiPro) parseOrder(ordmap map[string]interface{}) Order {
 	ord := Order{
-		Cid:        fmt.Sprint(ordmap["client-order-id"]),
-		OrderID:    ToInt(ordmap["id"]),
-		OrderID2:   fmt.Sprint(ToInt(ordmap["id"])),
-		Amount:     ToFloat64(ordmap["amount"]),
-		Price:      ToFloat64(ordmap["price"]),
-		DealAmount: ToFloat64(ordmap["field-amount"]),
-		Fee:        ToFloat64(ordmap["field-fees"]),
-		OrderTime:  ToInt(ordmap["created-at"]),
-	}
+		Cid:       fmt.Sprint(ordmap["client-order-id"]),
+		OrderID:   ToInt(ordmap["id"]),
+		OrderID2:  fmt.Sprint(ToInt(ordmap["id"])),
+		Fee:       ToFloat64(ordmap["field-fees"]),
+		OrderTime: ToInt(ordmap["created-at"]),
+		Decimals: OrderDecimals{
+			Price:      ToDecimal(ordmap["price"]),
+			Amount:     ToDecimal(ordmap["amount"]),
+			DealAmount: ToDecimal(ordmap["field-amount"]),
+		},
+	}
+	ord.Price = ord.Decimals.Price.Float64()
+	ord.Amount = ord.Decimals.Amount.Float64()
+	ord.DealAmount = ord.Decimals.DealAmount.Float64()
 
 	state := ordmap["state"].(string)
 	switch state {
@@ -372,17 +399,19 @@ func (hbpro *HuoBiPro) parseOrder(ordmap map[string]interface{}) Order {
 		ord.Status = ORDER_UNFINISH
 	}
 
-	if ord.DealAmount > 0.0 {
-		ord.AvgPrice = ToFloat64(ordmap["field-cash-amount"]) / ord.DealAmount
+	if ord.Decimals.DealAmount.Sign() > 0 {
+		//成交均价 = 成交金额 / 成交数量 , 保留8位小数
+		ord.Decimals.AvgPrice = ToDecimal(ordmap["field-cash-amount"]).Div(ord.Decimals.DealAmount, 8)
+		ord.AvgPrice = ord.Decimals.AvgPrice.Float64()
 	}
 
 	typeS := ordmap["type"].(string)
 	switch typeS {
-	case "buy-limit":
+	case "buy-limit", "buy-limit-maker", "buy-ioc", "buy-limit-fok":
 		ord.Side = BUY
 	case "buy-market":
 		ord.Side = BUY_MARKET
-	case "sell-limit":
+	case "sell-limit", "sell-limit-maker", "sell-ioc", "sell-limit-fok":
 		ord.Side = SELL
 	case "sell-market":
 		ord.Side = SELL_MARKET
@@ -400,7 +429,7 @@ func (hbpro *HuoBiPro) GetOneOrder(orderId string, currency CurrencyPair) (*Orde
 	}
 
 	if respmap["status"].(string) != "ok" {
-		return nil, errors.New(respmap["err-code"].(string))
+		return nil, adaptSpotError(respmap)
 	}
 
 	datamap := respmap["data"].(map[string]interface{})
@@ -433,12 +462,42 @@ func (hbpro *HuoBiPro) CancelOrder(orderId string, currency CurrencyPair) (bool,
 	}
 
 	if respmap["status"].(string) != "ok" {
-		return false, errors.New(string(resp))
+		return false, adaptSpotError(respmap)
 	}
 
 	return true, nil
 }
 
+func (hbpro *HuoBiPro) GetOrderByClientId(cid string, currency CurrencyPair) (*Order, error) {
+	path := "/v1/order/orders/getClientOrder"
+	params := url.Values{}
+	params.Set("clientOrderId", cid)
+	hbpro.buildPostForm("GET", path, &params)
+	respmap, err := HttpGet(hbpro.httpClient, hbpro.baseUrl+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	if respmap["status"].(string) != "ok" {
+		return nil, adaptSpotError(respmap)
+	}
+
+	datamap := respmap["data"].(map[string]interface{})
+	order := hbpro.parseOrder(datamap)
+	order.Currency = currency
+
+	return &order, nil
+}
+
+func (hbpro *HuoBiPro) CancelOrderByClientId(cid string, currency CurrencyPair) (bool, error) {
+	var status int
+	err := hbpro.doJsonRequest("/v1/order/orders/submitCancelClientOrder", map[string]string{"client-order-id": cid}, &status)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 func (hbpro *HuoBiPro) GetOrderHistorys(currency CurrencyPair, optional ...OptionalParameter) ([]Order, error) {
 	var optionals []OptionalParameter
 	optionals = append(optionals, OptionalParameter{}.
@@ -473,7 +532,7 @@ func (hbpro *HuoBiPro) getOrders(pair CurrencyPair, optional ...OptionalParamete
 	}
 
 	if respmap["status"].(string) != "ok" {
-		return nil, errors.New(respmap["err-code"].(string))
+		return nil, adaptSpotError(respmap)
 	}
 
 	datamap := respmap["data"].([]interface{})
@@ -722,6 +781,13 @@ func (hbpro *HuoBiPro) GetExchangeName() string {
 	return HUOBI_PRO
 }
 
+// 返回绑定了ctx的副本，所有http请求都受ctx控制
+func (hbpro *HuoBiPro) WithContext(ctx context.Context) API {
+	c := *hbpro
+	c.httpClient = HttpClientWithContext(ctx, hbpro.httpClient)
+	return &c
+}
+
 func (hbpro *HuoBiPro) GetCurrenciesList() ([]string, error) {
 	url := hbpro.baseUrl + "/v1/common/currencys"
 
@@ -763,9 +829,39 @@ func (hbpro *HuoBiPro) GetCurrenciesPrecision() ([]HuoBiProSymbol, error) {
 		sym.MinValue = _sym["min-order-value"].(float64)
 		sym.SymbolPartition = _sym["symbol-partition"].(string)
 		sym.Symbol = _sym["symbol"].(string)
+		sym.State, _ = _sym["state"].(string)
 		Symbols = append(Symbols, sym)
 		hbpro.Symbols[sym.Symbol] = sym
 	}
 	//fmt.Println(Symbols)
 	return Symbols, nil
 }
+
+func (hbpro *HuoBiPro) GetMarkets() ([]Market, error) {
+	symbols, err := hbpro.GetCurrenciesPrecision()
+	if err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, 0, len(symbols))
+	for _, v := range symbols {
+		status := MARKET_SUSPEND
+		switch v.State {
+		case "online":
+			status = MARKET_TRADING
+		case "offline":
+			status = MARKET_OFFLINE
+		}
+
+		markets = append(markets, Market{
+			Pair:        NewCurrencyPair(NewCurrency(v.BaseCurrency, ""), NewCurrency(v.QuoteCurrency, "")),
+			Symbol:      v.Symbol,
+			TickSize:    NewDecimal(1, -int32(v.PricePrecision)),
+			LotSize:     NewDecimal(1, -int32(v.AmountPrecision)),
+			MinAmount:   NewDecimalFromFloat(v.MinAmount),
+			MinNotional: NewDecimalFromFloat(v.MinValue),
+			Status:      status,
+		})
+	}
+	return markets, nil
+}
@@ -3,6 +3,7 @@ package huobi
 import (
 	"github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/internal/replay"
 	"github.com/stretchr/testify/assert"
 	"net"
 	"net/http"
@@ -35,20 +36,42 @@ var hbpro *HuoBiPro
 
 func init() {
 	logger.Log.SetLevel(logger.DEBUG)
-	hbpro = NewHuoBiProSpot(httpProxyClient, apikey, secretkey)
+	if replay.Live() { //构造时会请求账户信息
+		hbpro = NewHuoBiProSpot(httpProxyClient, apikey, secretkey)
+	}
+}
+
+//回放testdata中的fixture , GOEX_RECORD=1时请求真实的交易所并更新fixture
+func newReplayHuobiPro(t *testing.T, fixture string) *HuoBiPro {
+	srv := replay.NewServer(t, "testdata/"+fixture, "https://api.huobi.pro")
+	return NewHuobiWithConfig(&goex.APIConfig{
+		HttpClient: srv.Client(),
+		Endpoint:   srv.URL,
+	})
 }
 
 func TestHuobiPro_GetTicker(t *testing.T) {
-	ticker, err := hbpro.GetTicker(goex.XRP_BTC)
-	assert.Nil(t, err)
-	t.Log(ticker)
+	ticker, err := newReplayHuobiPro(t, "spot_market.json").GetTicker(goex.BTC_USDT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/spot_ticker.golden.json", ticker)
 }
 
 func TestHuobiPro_GetDepth(t *testing.T) {
-	dep, err := hbpro.GetDepth(2, goex.LTC_USDT)
-	assert.Nil(t, err)
-	t.Log(dep.AskList)
-	t.Log(dep.BidList)
+	dep, err := newReplayHuobiPro(t, "spot_market.json").GetDepth(5, goex.BTC_USDT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/spot_depth.golden.json", dep, "Timestamp")
+}
+
+func TestHuobiPro_GetCurrenciesPrecision(t *testing.T) {
+	symbols, err := newReplayHuobiPro(t, "spot_market.json").GetCurrenciesPrecision()
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/spot_symbols.golden.json", symbols)
 }
 
 func TestHuobiPro_GetAccountInfo(t *testing.T) {
@@ -118,12 +141,15 @@ func TestHuobiPro_CancelOrder(t *testing.T) {
 }
 
 func TestHuobiPro_GetOneOrder(t *testing.T) {
-	ord, err := hbpro.GetOneOrder("165062634284339", goex.BTC_USDT)
-	assert.Nil(t, err)
-	t.Log(ord)
+	ord, err := newReplayHuobiPro(t, "spot_market.json").GetOneOrder("59378", goex.BTC_USDT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/spot_order.golden.json", ord)
 }
 
 func TestHuobiPro_GetOrderHistorys(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ords, err := hbpro.GetOrderHistorys(
 		goex.NewCurrencyPair2("BTC_USDT"),
 		goex.OptionalParameter{}.Optional("start-date", "2020-11-30"))
@@ -132,10 +158,6 @@ func TestHuobiPro_GetOrderHistorys(t *testing.T) {
 }
 
 func TestHuobiPro_GetCurrenciesList(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	hbpro.GetCurrenciesList()
 }
-
-func TestHuobiPro_GetCurrenciesPrecision(t *testing.T) {
-	//return
-	t.Log(hbpro.GetCurrenciesPrecision())
-}
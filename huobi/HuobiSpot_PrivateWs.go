@@ -0,0 +1,313 @@
+package huobi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/logger"
+)
+
+const (
+	spotPrivateWsHost = "api.huobi.pro"
+	spotPrivateWsPath = "/ws/v2"
+	wsAuthTimeout     = 10 * time.Second
+)
+
+type spotWsV2Response struct {
+	Action  string          `json:"action"`
+	Code    int             `json:"code"`
+	Ch      string          `json:"ch"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+//现货私有数据推送(订单/账户) , 基于v2 websocket接口
+type SpotPrivateWs struct {
+	*WsBuilder
+	connLock sync.Mutex
+	wsConn   *WsConn
+	config   *APIConfig
+	authCh   chan error
+	isAuth   bool
+
+	lock             sync.RWMutex
+	accountCurrencys map[string]bool
+
+	orderCallback   func(*Order)
+	accountCallback func(*Account)
+
+	symbolMapper *SymbolMapper
+}
+
+var (
+	_ SpotPrivateWsApi  = (*SpotPrivateWs)(nil)
+	_ SymbolMapperAware = (*SpotPrivateWs)(nil)
+)
+
+func NewSpotPrivateWs(config *APIConfig) *SpotPrivateWs {
+	ws := &SpotPrivateWs{
+		WsBuilder:        NewWsBuilder(),
+		config:           config,
+		authCh:           make(chan error, 1),
+		accountCurrencys: make(map[string]bool, 4),
+	}
+	ws.WsBuilder = ws.WsBuilder.
+		WsUrl(fmt.Sprintf("wss://%s%s", spotPrivateWsHost, spotPrivateWsPath)).
+		AutoReconnect().
+		ConnectSuccessAfterSendMessage(ws.authMessage).
+		ProtoHandleFunc(ws.handle)
+	return ws
+}
+
+func (ws *SpotPrivateWs) OrderCallback(call func(order *Order)) {
+	ws.orderCallback = call
+}
+
+func (ws *SpotPrivateWs) AccountCallback(call func(account *Account)) {
+	ws.accountCallback = call
+}
+
+func (ws *SpotPrivateWs) authMessage() []byte {
+	params := url.Values{}
+	params.Set("accessKey", ws.config.ApiKey)
+	params.Set("signatureMethod", "HmacSHA256")
+	params.Set("signatureVersion", "2.1")
+	params.Set("timestamp", time.Now().UTC().Format("2006-01-02T15:04:05"))
+	payload := fmt.Sprintf("%s\n%s\n%s\n%s", "GET", spotPrivateWsHost, spotPrivateWsPath, params.Encode())
+	sign, _ := GetParamHmacSHA256Base64Sign(ws.config.ApiSecretKey, payload)
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"action": "req",
+		"ch":     "auth",
+		"params": map[string]string{
+			"authType":         "api",
+			"accessKey":        params.Get("accessKey"),
+			"signatureMethod":  params.Get("signatureMethod"),
+			"signatureVersion": params.Get("signatureVersion"),
+			"timestamp":        params.Get("timestamp"),
+			"signature":        sign,
+		},
+	})
+	return data
+}
+
+//建立连接，连接成功后发送鉴权消息；连接失败时返回错误，下次Login时重试
+func (ws *SpotPrivateWs) connect() error {
+	ws.connLock.Lock()
+	defer ws.connLock.Unlock()
+	if ws.wsConn != nil {
+		return nil
+	}
+	c, err := ws.WsBuilder.BuildWithContext(context.Background())
+	if err != nil {
+		return err
+	}
+	ws.wsConn = c
+	return nil
+}
+
+func (ws *SpotPrivateWs) Login() error {
+	if ws.config.ApiKey == "" || ws.config.ApiSecretKey == "" {
+		return EX_ERR_NOT_FIND_APIKEY
+	}
+
+	if ws.isAuth {
+		return nil
+	}
+
+	if err := ws.connect(); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-ws.authCh:
+		ws.isAuth = err == nil
+		return err
+	case <-time.After(wsAuthTimeout):
+		return errors.New("[huobi] ws auth timeout")
+	}
+}
+
+func (ws *SpotPrivateWs) subscribe(ch string) error {
+	if ws.wsConn == nil {
+		return errors.New("[huobi] please login first")
+	}
+	return ws.wsConn.Subscribe(map[string]interface{}{
+		"action": "sub",
+		"ch":     ch,
+	})
+}
+
+//设置后使用交易规则把推送的symbol映射回交易对
+func (ws *SpotPrivateWs) SetSymbolMapper(mapper *SymbolMapper) {
+	ws.symbolMapper = mapper
+}
+
+func (ws *SpotPrivateWs) SubscribeOrder(pair CurrencyPair) error {
+	if ws.orderCallback == nil {
+		return errors.New("please set order callback func")
+	}
+	return ws.subscribe(fmt.Sprintf("orders#%s", pair.ToLower().ToSymbol("")))
+}
+
+//订阅交易对两个币种的余额变动推送
+func (ws *SpotPrivateWs) SubscribeAccount(pair CurrencyPair) error {
+	if ws.accountCallback == nil {
+		return errors.New("please set account callback func")
+	}
+
+	ws.lock.Lock()
+	ws.accountCurrencys[pair.CurrencyA.Symbol] = true
+	ws.accountCurrencys[pair.CurrencyB.Symbol] = true
+	ws.lock.Unlock()
+
+	return ws.subscribe("accounts.update#1")
+}
+
+func (ws *SpotPrivateWs) handle(msg []byte) error {
+	var resp spotWsV2Response
+	err := json.Unmarshal(msg, &resp)
+	if err != nil {
+		logger.Errorf("[huobi] json unmarshal ws response error [%s] , response data = %s", err, string(msg))
+		return err
+	}
+
+	switch resp.Action {
+	case "ping":
+		ws.wsConn.SendJsonMessage(map[string]interface{}{
+			"action": "pong",
+			"data":   resp.Data,
+		})
+		return nil
+	case "req":
+		if resp.Ch == "auth" {
+			var err error
+			if resp.Code != 200 {
+				err = fmt.Errorf("[huobi] ws auth error , code=%d , message=%s", resp.Code, resp.Message)
+			}
+			select {
+			case ws.authCh <- err:
+			default:
+			}
+		}
+		return nil
+	case "sub":
+		if resp.Code != 200 {
+			logger.Errorf("[huobi] subscribe %s error , code=%d , message=%s", resp.Ch, resp.Code, resp.Message)
+		}
+		return nil
+	case "push":
+		if strings.HasPrefix(resp.Ch, "orders#") {
+			return ws.handleOrder(resp.Data)
+		}
+		if strings.HasPrefix(resp.Ch, "accounts.update") {
+			return ws.handleAccount(resp.Data)
+		}
+	}
+
+	logger.Warnf("[huobi] unknown ws message: %s", string(msg))
+
+	return nil
+}
+
+func (ws *SpotPrivateWs) handleOrder(data json.RawMessage) error {
+	var ordmap map[string]interface{}
+	err := json.Unmarshal(data, &ordmap)
+	if err != nil {
+		return err
+	}
+
+	ord := &Order{
+		Cid:        fmt.Sprint(ordmap["clientOrderId"]),
+		OrderID:    ToInt(ordmap["orderId"]),
+		OrderID2:   fmt.Sprintf("%.0f", ordmap["orderId"]),
+		Currency:   ws.symbolMapper.PairOf(fmt.Sprint(ordmap["symbol"]), adaptSpotSymbol, ""),
+		Amount:     ToFloat64(ordmap["orderSize"]),
+		Price:      ToFloat64(ordmap["orderPrice"]),
+		DealAmount: ToFloat64(ordmap["execAmt"]),
+		OrderTime:  ToInt(ordmap["orderCreateTime"]),
+	}
+
+	switch ordmap["orderStatus"] {
+	case "filled":
+		ord.Status = ORDER_FINISH
+	case "partial-filled":
+		ord.Status = ORDER_PART_FINISH
+	case "canceled", "partial-canceled":
+		ord.Status = ORDER_CANCEL
+	case "rejected":
+		ord.Status = ORDER_REJECT
+	default:
+		ord.Status = ORDER_UNFINISH
+	}
+
+	if ord.Status == ORDER_FINISH || ord.Status == ORDER_CANCEL {
+		ord.FinishedTime = ToInt64(ordmap["lastActTime"])
+		if ordmap["eventType"] == "trade" {
+			ord.FinishedTime = ToInt64(ordmap["tradeTime"])
+		}
+	}
+
+	typeS := fmt.Sprint(ordmap["type"])
+	switch typeS {
+	case "buy-limit":
+		ord.Side = BUY
+	case "buy-market":
+		ord.Side = BUY_MARKET
+	case "sell-limit":
+		ord.Side = SELL
+	case "sell-market":
+		ord.Side = SELL_MARKET
+	}
+	if strings.HasSuffix(typeS, "-market") {
+		ord.Type = "market"
+	} else {
+		ord.Type = "limit"
+	}
+
+	ws.orderCallback(ord)
+
+	return nil
+}
+
+func (ws *SpotPrivateWs) handleAccount(data json.RawMessage) error {
+	var balance struct {
+		Currency   string  `json:"currency"`
+		Balance    float64 `json:"balance,string"`
+		Available  float64 `json:"available,string"`
+		ChangeTime int64   `json:"changeTime"`
+	}
+	err := json.Unmarshal(data, &balance)
+	if err != nil {
+		return err
+	}
+
+	currency := NewCurrency(balance.Currency, "")
+
+	ws.lock.RLock()
+	subscribed := ws.accountCurrencys[currency.Symbol]
+	ws.lock.RUnlock()
+	if !subscribed {
+		return nil
+	}
+
+	ws.accountCallback(&Account{
+		Exchange: HUOBI_PRO,
+		SubAccounts: map[Currency]SubAccount{
+			currency: {
+				Currency:     currency,
+				Amount:       balance.Available,
+				ForzenAmount: balance.Balance - balance.Available,
+			},
+		},
+	})
+
+	return nil
+}
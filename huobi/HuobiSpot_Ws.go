@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-	"sync"
 	"time"
 
 	. "github.com/mrwill84/goex"
@@ -15,14 +14,20 @@ import (
 
 type SpotWs struct {
 	*WsBuilder
-	sync.Once
-	wsConn *WsConn
+	wsPool *WsPool
 
 	tickerCallback func(*Ticker)
 	depthCallback  func(*Depth)
 	tradeCallback  func(*Trade)
+
+	symbolMapper *SymbolMapper
 }
 
+var (
+	_ SpotWsApi         = (*SpotWs)(nil)
+	_ SymbolMapperAware = (*SpotWs)(nil)
+)
+
 func NewSpotWs() *SpotWs {
 	ws := &SpotWs{
 		WsBuilder: NewWsBuilder(),
@@ -31,10 +36,16 @@ func NewSpotWs() *SpotWs {
 		WsUrl("wss://api.huobi.pro/ws").
 		AutoReconnect().
 		DecompressFunc(GzipDecompress).
-		ProtoHandleFunc(ws.handle)
+		ConnProtoHandleFunc(ws.handle)
+	ws.wsPool = NewWsPool(ws.WsBuilder, wsMaxSubsPerConn)
 	return ws
 }
 
+//设置后使用交易规则把推送的symbol映射回交易对
+func (ws *SpotWs) SetSymbolMapper(mapper *SymbolMapper) {
+	ws.symbolMapper = mapper
+}
+
 func (ws *SpotWs) DepthCallback(call func(depth *Depth)) {
 	ws.depthCallback = call
 }
@@ -46,45 +57,53 @@ func (ws *SpotWs) TradeCallback(call func(trade *Trade)) {
 	ws.tradeCallback = call
 }
 
-func (ws *SpotWs) connectWs() {
-	ws.Do(func() {
-		ws.wsConn = ws.WsBuilder.Build()
-	})
+func (ws *SpotWs) subscribe(topic string) error {
+	return wsSubscribe(ws.wsPool, topic)
 }
 
-func (ws *SpotWs) subscribe(sub map[string]interface{}) error {
-	ws.connectWs()
-	return ws.wsConn.Subscribe(sub)
+//market.btcusdt.detail
+func (ws *SpotWs) topic(pair CurrencyPair, channel string) string {
+	return fmt.Sprintf("market.%s.%s", pair.ToLower().ToSymbol(""), channel)
 }
 
 func (ws *SpotWs) SubscribeDepth(pair CurrencyPair) error {
 	if ws.depthCallback == nil {
 		return errors.New("please set depth callback func")
 	}
-	return ws.subscribe(map[string]interface{}{
-		"id":  "spot.depth",
-		"sub": fmt.Sprintf("market.%s.mbp.refresh.20", pair.ToLower().ToSymbol(""))})
+	return ws.subscribe(ws.topic(pair, "mbp.refresh.20"))
 }
 
 func (ws *SpotWs) SubscribeTicker(pair CurrencyPair) error {
 	if ws.tickerCallback == nil {
 		return errors.New("please set ticker call back func")
 	}
-	return ws.subscribe(map[string]interface{}{
-		"id":  "spot.ticker",
-		"sub": fmt.Sprintf("market.%s.detail", pair.ToLower().ToSymbol("")),
-	})
-	return nil
+	return ws.subscribe(ws.topic(pair, "detail"))
 }
 
 func (ws *SpotWs) SubscribeTrade(pair CurrencyPair) error {
 	return nil
 }
 
-func (ws *SpotWs) handle(msg []byte) error {
+func (ws *SpotWs) UnsubscribeDepth(pair CurrencyPair) error {
+	return wsUnsubscribe(ws.wsPool, ws.topic(pair, "mbp.refresh.20"))
+}
+
+func (ws *SpotWs) UnsubscribeTicker(pair CurrencyPair) error {
+	return wsUnsubscribe(ws.wsPool, ws.topic(pair, "detail"))
+}
+
+func (ws *SpotWs) UnsubscribeTrade(pair CurrencyPair) error {
+	return nil
+}
+
+func (ws *SpotWs) handle(c *WsConn, msg []byte) error {
 	if bytes.Contains(msg, []byte("ping")) {
 		pong := bytes.ReplaceAll(msg, []byte("ping"), []byte("pong"))
-		ws.wsConn.SendMessage(pong)
+		c.SendMessage(pong)
+		return nil
+	}
+
+	if wsAckSubscription(c, msg) {
 		return nil
 	}
 
@@ -94,7 +113,7 @@ func (ws *SpotWs) handle(msg []byte) error {
 		return err
 	}
 
-	currencyPair := ParseCurrencyPairFromSpotWsCh(resp.Ch)
+	currencyPair := ws.symbolMapper.PairOf(wsChSymbol(resp.Ch), adaptSpotSymbol, "")
 	if strings.Contains(resp.Ch, "mbp.refresh") {
 		var (
 			depthResp DepthResponse
@@ -131,7 +150,7 @@ func (ws *SpotWs) handle(msg []byte) error {
 		return nil
 	}
 
-	logger.Errorf("[%s] unknown message ch , msg=%s", ws.wsConn.WsUrl, string(msg))
+	logger.Errorf("[%s] unknown message ch , msg=%s", c.WsUrl, string(msg))
 
 	return nil
 }
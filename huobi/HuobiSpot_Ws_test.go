@@ -6,9 +6,11 @@ import (
 	"time"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 func TestNewSpotWs(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	os.Setenv("HTTPS_PROXY", "socks5://127.0.0.1:1080")
 	spotWs := NewSpotWs()
 	spotWs.DepthCallback(func(depth *goex.Depth) {
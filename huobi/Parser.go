@@ -4,23 +4,43 @@ import (
 	"fmt"
 	"github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
-	"sort"
+	"github.com/mrwill84/goex/orderbook"
+	"strconv"
 	"strings"
 )
 
+//推送的是全量深度，通过本地订单簿排序后返回全部档位
 func ParseDepthFromResponse(r DepthResponse) goex.Depth {
-	var dep goex.Depth
-	for _, bid := range r.Bids {
-		dep.BidList = append(dep.BidList, goex.DepthRecord{Price: bid[0], Amount: bid[1]})
-	}
+	ob := orderbook.NewOrderBook(nil)
+	ob.Snapshot(&orderbook.Snapshot{
+		Asks: adaptDepthLevels(r.Asks),
+		Bids: adaptDepthLevels(r.Bids),
+	})
+	return *ob.Depth(0)
+}
 
-	for _, ask := range r.Asks {
-		dep.AskList = append(dep.AskList, goex.DepthRecord{Price: ask[0], Amount: ask[1]})
+func adaptDepthLevels(items [][]float64) []orderbook.Level {
+	levels := make([]orderbook.Level, 0, len(items))
+	for _, item := range items {
+		if len(item) < 2 {
+			continue
+		}
+		levels = append(levels, orderbook.Level{
+			Price:  strconv.FormatFloat(item[0], 'f', -1, 64),
+			Amount: strconv.FormatFloat(item[1], 'f', -1, 64),
+		})
 	}
+	return levels
+}
+
+//现货symbol(btcusdt)的fallback解析
+func adaptSpotSymbol(symbol string) goex.CurrencyPair {
+	return ParseCurrencyPairFromSpotWsCh("market." + symbol)
+}
 
-	sort.Sort(sort.Reverse(dep.BidList))
-	sort.Sort(sort.Reverse(dep.AskList))
-	return dep
+//永续合约代码(BTC-USD)的fallback解析
+func adaptSwapContractCode(contractCode string) goex.CurrencyPair {
+	return goex.NewCurrencyPair3(contractCode, "-")
 }
 
 func ParseCurrencyPairFromSpotWsCh(ch string) goex.CurrencyPair {
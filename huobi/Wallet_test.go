@@ -2,12 +2,16 @@ package huobi
 
 import (
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 	"testing"
 )
 
 var wallet *Wallet
 
 func init() {
+	if !replay.Live() {
+		return
+	}
 	wallet = NewWallet(&goex.APIConfig{
 		HttpClient:   httpProxyClient,
 		ApiKey:       "",
@@ -16,6 +20,7 @@ func init() {
 }
 
 func TestWallet_Transfer(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(wallet.Transfer(goex.TransferParameter{
 		Currency: "BTC",
 		From:     goex.SWAP_USDT,
@@ -0,0 +1,171 @@
+//Package replay 录制/回放交易所的http和websocket响应，使适配器的测试可以离线运行:
+//GOEX_RECORD=1 时请求转发到真实的交易所并把响应写入fixture文件，否则只从fixture文件返回响应
+//访问真实交易所、没有fixture的测试调用SkipUnlessLive，只在GOEX_LIVE=1时运行
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+//每次请求都会变化的参数，不参与匹配也不写入fixture
+var volatileParams = map[string]bool{
+	"timestamp":        true,
+	"signature":        true,
+	"sign":             true,
+	"recvwindow":       true,
+	"nonce":            true,
+	"signaturemethod":  true,
+	"signatureversion": true,
+	"accesskeyid":      true,
+	"api_key":          true,
+	"apikey":           true,
+}
+
+//是否录制模式
+func Recording() bool {
+	return os.Getenv("GOEX_RECORD") == "1"
+}
+
+//一次http请求和它的响应
+type Interaction struct {
+	Method   string            `json:"method"`
+	Path     string            `json:"path"`
+	Query    map[string]string `json:"query,omitempty"` //请求需要包含这些参数
+	Status   int               `json:"status"`
+	Header   map[string]string `json:"header,omitempty"`
+	Body     json.RawMessage   `json:"body,omitempty"`
+	BodyText string            `json:"bodyText,omitempty"` //非json的响应
+}
+
+func (i *Interaction) match(req *http.Request) bool {
+	if i.Method != req.Method || i.Path != req.URL.Path {
+		return false
+	}
+	query := req.URL.Query()
+	for k, v := range i.Query {
+		if query.Get(k) != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (i *Interaction) body() []byte {
+	if len(i.Body) > 0 {
+		return i.Body
+	}
+	return []byte(i.BodyText)
+}
+
+func stableQuery(query url.Values) map[string]string {
+	m := make(map[string]string)
+	for k := range query {
+		if !volatileParams[strings.ToLower(k)] {
+			m[k] = query.Get(k)
+		}
+	}
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+//fixture文件，按顺序匹配未使用过的记录，全部用过后重复返回最后一条匹配的记录
+type Cassette struct {
+	path         string
+	lock         sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+//录制模式下本进程已经清空过的fixture文件，同一个文件被多个测试共用时保留本次录制的记录
+var recorded = struct {
+	sync.Mutex
+	paths map[string]bool
+}{paths: make(map[string]bool)}
+
+//第一次加载时丢弃旧的记录，重新录制
+func truncateOnce(path string) bool {
+	recorded.Lock()
+	defer recorded.Unlock()
+	abs, _ := filepath.Abs(path)
+	if recorded.paths[abs] {
+		return false
+	}
+	recorded.paths[abs] = true
+	return true
+}
+
+//加载fixture文件，录制模式下文件可以不存在；录制时本进程第一次加载的文件会被重新录制，不追加到旧的记录后
+func LoadCassette(path string) (*Cassette, error) {
+	c := &Cassette{path: path}
+	if Recording() && truncateOnce(path) {
+		return c, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && Recording() {
+			return c, nil
+		}
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &c.interactions); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err.Error())
+	}
+	c.used = make([]bool, len(c.interactions))
+
+	return c, nil
+}
+
+func (c *Cassette) Match(req *http.Request) (*Interaction, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	last := -1
+	for idx, i := range c.interactions {
+		if !i.match(req) {
+			continue
+		}
+		if !c.used[idx] {
+			c.used[idx] = true
+			return i, true
+		}
+		last = idx
+	}
+
+	if last >= 0 {
+		return c.interactions[last], true
+	}
+	return nil, false
+}
+
+func (c *Cassette) Add(i *Interaction) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.interactions = append(c.interactions, i)
+	c.used = append(c.used, true)
+}
+
+func (c *Cassette) Save() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,85 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//GOEX_UPDATE_GOLDEN=1 时用当前结果覆盖golden文件
+func updateGolden() bool {
+	return os.Getenv("GOEX_UPDATE_GOLDEN") == "1" || Recording()
+}
+
+//将v按json格式与golden文件比较，ignore中的字段(如本地生成的时间戳)不参与比较
+func AssertGolden(t testing.TB, golden string, v interface{}, ignore ...string) {
+	t.Helper()
+
+	actual, err := normalize(v, ignore)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updateGolden() {
+		if err = os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(golden, actual, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(bytes.TrimSpace(expected), bytes.TrimSpace(actual)) {
+		t.Fatalf("%s mismatch\nexpected:\n%s\nactual:\n%s", golden, expected, actual)
+	}
+}
+
+func normalize(v interface{}, ignore []string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree interface{}
+	if err = json.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool, len(ignore))
+	for _, field := range ignore {
+		skip[field] = true
+	}
+	removeFields(tree, skip)
+
+	data, err = json.MarshalIndent(tree, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func removeFields(tree interface{}, skip map[string]bool) {
+	switch node := tree.(type) {
+	case map[string]interface{}:
+		for k, v := range node {
+			if skip[k] {
+				delete(node, k)
+				continue
+			}
+			removeFields(v, skip)
+		}
+	case []interface{}:
+		for _, v := range node {
+			removeFields(v, skip)
+		}
+	}
+}
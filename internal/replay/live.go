@@ -0,0 +1,18 @@
+package replay
+
+import (
+	"os"
+	"testing"
+)
+
+//GOEX_LIVE=1或录制模式下运行访问真实交易所的测试
+func Live() bool {
+	return os.Getenv("GOEX_LIVE") == "1" || Recording()
+}
+
+//访问真实交易所的测试默认跳过，使go test ./...可以离线运行
+func SkipUnlessLive(t testing.TB) {
+	if !Live() {
+		t.Skip("live test , set GOEX_LIVE=1 to run")
+	}
+}
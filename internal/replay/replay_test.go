@@ -0,0 +1,109 @@
+package replay
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func writeFixture(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTransport(t *testing.T) {
+	t.Setenv("GOEX_RECORD", "")
+
+	fixture := writeFixture(t, "fixture.json", `[
+  {"method":"GET","path":"/api/v3/depth","query":{"symbol":"BTCUSDT"},"status":200,"body":{"lastUpdateId":1}},
+  {"method":"GET","path":"/api/v3/depth","query":{"symbol":"BTCUSDT"},"status":200,"body":{"lastUpdateId":2}},
+  {"method":"GET","path":"/api/v3/time","status":429,"header":{"Retry-After":"3"},"bodyText":"too many requests"}
+]`)
+	client := &http.Client{Transport: NewTransport(t, fixture)}
+
+	for _, expected := range []string{`{"lastUpdateId":1}`, `{"lastUpdateId":2}`, `{"lastUpdateId":2}`} {
+		resp, err := client.Get("https://api.binance.com/api/v3/depth?symbol=BTCUSDT&limit=5&timestamp=123")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		if string(body) != expected {
+			t.Fatal(string(body))
+		}
+	}
+
+	resp, err := client.Get("https://api.binance.com/api/v3/time")
+	if err != nil || resp.StatusCode != 429 || resp.Header.Get("Retry-After") != "3" {
+		t.Fatal(resp, err)
+	}
+
+	if _, err = client.Get("https://api.binance.com/api/v3/depth?symbol=ETHUSDT"); err == nil {
+		t.Fatal("no fixture for ETHUSDT")
+	}
+}
+
+func TestWsServer(t *testing.T) {
+	fixture := writeFixture(t, "ws.json", `[{"stream":"btcusdt@ticker"},"pong"]`)
+	srv := NewWsServer(t, fixture, "")
+	srv.SendAfter = 1
+
+	conn, _, err := websocket.DefaultDialer.Dial(srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"op":"subscribe"}`))
+
+	for _, expected := range []string{`{"stream":"btcusdt@ticker"}`, "pong"} {
+		_, msg, err := conn.ReadMessage()
+		if err != nil || string(msg) != expected {
+			t.Fatal(string(msg), err)
+		}
+	}
+
+	if received := srv.Received(); len(received) != 1 || string(received[0]) != `{"op":"subscribe"}` {
+		t.Fatal(received)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	v := map[string]interface{}{"Price": 1.5, "Timestamp": 123, "List": []map[string]int{{"Timestamp": 1, "Amount": 2}}}
+	data, err := normalize(v, []string{"Timestamp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "{\n  \"List\": [\n    {\n      \"Amount\": 2\n    }\n  ],\n  \"Price\": 1.5\n}\n"
+	if string(data) != expected {
+		t.Fatal(string(data))
+	}
+}
+
+//录制模式下重新录制fixture，不在旧的记录后追加
+func TestLoadCassette_Recording(t *testing.T) {
+	t.Setenv("GOEX_RECORD", "1")
+
+	fixture := writeFixture(t, "fixture.json", `[{"method":"GET","path":"/api/v3/time","status":200,"body":{}}]`)
+	c, err := LoadCassette(fixture)
+	if err != nil || len(c.interactions) != 0 {
+		t.Fatal(err, c.interactions)
+	}
+	c.Add(&Interaction{Method: "GET", Path: "/api/v3/depth", Status: 200})
+	if err = c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	//同一进程中再次加载，保留本次录制的记录
+	c, err = LoadCassette(fixture)
+	if err != nil || len(c.interactions) != 1 || c.interactions[0].Path != "/api/v3/depth" {
+		t.Fatal(err, c.interactions)
+	}
+}
@@ -0,0 +1,147 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+//回放fixture的http.RoundTripper，录制模式下通过Base发出真实请求并记录响应
+type Transport struct {
+	Base     http.RoundTripper //为nil时使用http.DefaultTransport
+	cassette *Cassette
+}
+
+//加载fixture，录制模式下测试结束时保存
+func NewTransport(t testing.TB, fixture string) *Transport {
+	c, err := LoadCassette(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if Recording() {
+		t.Cleanup(func() {
+			if err := c.Save(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	return &Transport{cassette: c}
+}
+
+func (tr *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if Recording() {
+		return tr.record(req)
+	}
+
+	i, ok := tr.cassette.Match(req)
+	if !ok {
+		return nil, fmt.Errorf("replay: no fixture for %s %s", req.Method, req.URL.String())
+	}
+
+	resp := &http.Response{
+		StatusCode: i.Status,
+		Status:     fmt.Sprintf("%d %s", i.Status, http.StatusText(i.Status)),
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewReader(i.body())),
+		Request:    req,
+	}
+	for k, v := range i.Header {
+		resp.Header.Set(k, v)
+	}
+
+	return resp, nil
+}
+
+func (tr *Transport) record(req *http.Request) (*http.Response, error) {
+	base := tr.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	i := &Interaction{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Query:  stableQuery(req.URL.Query()),
+		Status: resp.StatusCode,
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		i.Header = map[string]string{"Retry-After": retryAfter}
+	}
+	if json.Valid(body) {
+		i.Body = body
+	} else {
+		i.BodyText = string(body)
+	}
+	tr.cassette.Add(i)
+
+	return resp, nil
+}
+
+//本地http服务，用于通过APIConfig.Endpoint替换交易所地址;
+//录制模式下请求转发到upstream(如 https://www.okex.com)
+func NewServer(t testing.TB, fixture, upstream string) *httptest.Server {
+	tr := NewTransport(t, fixture)
+
+	target, err := url.Parse(upstream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		u := *r.URL
+		u.Scheme, u.Host = target.Scheme, target.Host
+		req, err := http.NewRequest(r.Method, u.String(), bytes.NewReader(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for k, v := range r.Header {
+			if !strings.EqualFold(k, "Host") {
+				req.Header[k] = v
+			}
+		}
+
+		resp, err := tr.RoundTrip(req)
+		if err != nil {
+			t.Error(err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		defer resp.Body.Close()
+
+		for k, v := range resp.Header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(resp.StatusCode)
+		data, _ := ioutil.ReadAll(resp.Body)
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
@@ -0,0 +1,172 @@
+package replay
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+var upgrader = websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+
+//本地websocket服务，连接建立后按顺序推送fixture中的消息(只支持文本消息);
+//录制模式下连接转发到upstream(如 wss://stream.binance.com:9443)并记录服务端推送的消息
+type WsServer struct {
+	*httptest.Server
+	URL       string //ws://127.0.0.1:port
+	SendAfter int    //收到多少条客户端消息(如订阅请求)后开始推送，默认连接后立即推送
+
+	fixture  string
+	upstream string
+	messages []json.RawMessage //json字符串元素按文本发送(如pong)，其它按原始json发送
+
+	lock     sync.Mutex
+	received [][]byte
+	notify   chan struct{}
+}
+
+func NewWsServer(t testing.TB, fixture, upstream string) *WsServer {
+	s := &WsServer{fixture: fixture, upstream: upstream, notify: make(chan struct{}, 1)}
+
+	data, err := ioutil.ReadFile(fixture)
+	switch {
+	case err == nil:
+		if err = json.Unmarshal(data, &s.messages); err != nil {
+			t.Fatal(fixture, err)
+		}
+	case !(os.IsNotExist(err) && Recording()):
+		t.Fatal(err)
+	}
+
+	if Recording() {
+		s.messages = nil
+		t.Cleanup(func() {
+			if err := s.save(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.URL = "ws" + strings.TrimPrefix(s.Server.URL, "http")
+	t.Cleanup(s.Server.Close)
+
+	return s
+}
+
+//客户端发送的消息，如订阅请求
+func (s *WsServer) Received() [][]byte {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([][]byte{}, s.received...)
+}
+
+func (s *WsServer) handle(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	if Recording() {
+		s.proxy(conn, r)
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.readClient(conn, nil)
+	}()
+
+	for len(s.Received()) < s.SendAfter {
+		select {
+		case <-s.notify:
+		case <-done:
+			return
+		}
+	}
+
+	for _, msg := range s.messages {
+		var text string
+		if json.Unmarshal(msg, &text) != nil {
+			text = string(msg)
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(text)); err != nil {
+			return
+		}
+	}
+
+	<-done
+}
+
+//读取客户端消息直到连接关闭，upstream不为nil时转发
+func (s *WsServer) readClient(conn, upstream *websocket.Conn) {
+	for {
+		ty, msg, err := conn.ReadMessage()
+		if err != nil {
+			if upstream != nil {
+				upstream.Close()
+			}
+			return
+		}
+
+		s.lock.Lock()
+		s.received = append(s.received, msg)
+		s.lock.Unlock()
+
+		select {
+		case s.notify <- struct{}{}:
+		default:
+		}
+
+		if upstream != nil {
+			upstream.WriteMessage(ty, msg)
+		}
+	}
+}
+
+func (s *WsServer) proxy(conn *websocket.Conn, r *http.Request) {
+	upstream, _, err := websocket.DefaultDialer.Dial(s.upstream+r.URL.RequestURI(), nil)
+	if err != nil {
+		return
+	}
+	defer upstream.Close()
+
+	go s.readClient(conn, upstream)
+
+	for {
+		ty, msg, err := upstream.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err = conn.WriteMessage(ty, msg); err != nil {
+			return
+		}
+
+		raw := json.RawMessage(msg)
+		if ty != websocket.TextMessage || !json.Valid(msg) {
+			raw, _ = json.Marshal(string(msg))
+		}
+		s.lock.Lock()
+		s.messages = append(s.messages, raw)
+		s.lock.Unlock()
+	}
+}
+
+func (s *WsServer) save() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	data, err := json.MarshalIndent(s.messages, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.fixture, append(data, '\n'), 0644)
+}
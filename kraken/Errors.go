@@ -0,0 +1,35 @@
+package kraken
+
+import (
+	"strings"
+
+	. "github.com/mrwill84/goex"
+)
+
+//https://support.kraken.com/hc/en-us/articles/360001491786-API-error-messages
+var errorCodeTable = ErrorCodeTable{
+	"EAPI:Invalid key":                    EX_ERR_AUTH_FAIL,
+	"EAPI:Invalid signature":              EX_ERR_SIGN,
+	"EAPI:Invalid nonce":                  EX_ERR_INVALID_TIMESTAMP,
+	"EAPI:Rate limit exceeded":            EX_ERR_API_LIMIT,
+	"EOrder:Rate limit exceeded":          EX_ERR_API_LIMIT,
+	"EGeneral:Temporary lockout":          EX_ERR_API_LIMIT,
+	"EGeneral:Permission denied":          EX_ERR_AUTH_FAIL,
+	"EGeneral:Invalid arguments":          EX_ERR_INVALID_ORDER_PARAM,
+	"EOrder:Insufficient funds":           EX_ERR_INSUFFICIENT_BALANCE,
+	"EOrder:Order minimum not met":        EX_ERR_INVALID_ORDER_PARAM,
+	"EOrder:Unknown order":                EX_ERR_NOT_FIND_ORDER,
+	"EQuery:Unknown asset pair":           EX_ERR_SYMBOL_ERR,
+	"EService:Unavailable":                EX_ERR_MAINTENANCE,
+	"EService:Market in cancel_only mode": EX_ERR_MAINTENANCE,
+	"EService:Market in post_only mode":   EX_ERR_MAINTENANCE,
+}
+
+//错误格式为 <类别>:<信息>[:附加信息] , 如 EGeneral:Invalid arguments:volume
+func adaptError(errStr string) error {
+	code := errStr
+	if parts := strings.SplitN(errStr, ":", 3); len(parts) == 3 {
+		code = parts[0] + ":" + parts[1]
+	}
+	return errorCodeTable.Wrap(code, errStr)
+}
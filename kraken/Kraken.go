@@ -32,6 +32,11 @@ type Kraken struct {
 	secretKey string
 }
 
+var (
+	_ API           = (*Kraken)(nil)
+	_ AmendOrderAPI = (*Kraken)(nil)
+)
+
 var (
 	BASE_URL   = "https://api.kraken.com"
 	API_V0     = "/0/"
@@ -44,7 +49,8 @@ func New(client *http.Client, accesskey, secretkey string) *Kraken {
 	return &Kraken{client, accesskey, secretkey}
 }
 
-func (k *Kraken) placeOrder(orderType, side, amount, price string, pair CurrencyPair) (*Order, error) {
+//kraken不支持fok
+func (k *Kraken) placeOrder(orderType, side, amount, price string, pair CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
 	apiuri := "private/AddOrder"
 
 	params := url.Values{}
@@ -54,6 +60,19 @@ func (k *Kraken) placeOrder(orderType, side, amount, price string, pair Currency
 	params.Set("price", price)
 	params.Set("volume", amount)
 
+	cid := GetClientOrderId(opt...)
+	if cid != "" {
+		params.Set("cl_ord_id", cid)
+	}
+	if tif, ok := GetTimeInForce(opt...); ok {
+		switch tif {
+		case PostOnly:
+			params.Set("oflags", "post")
+		case Ioc:
+			params.Set("timeinforce", "IOC")
+		}
+	}
+
 	var resp NewOrderResponse
 	err := k.doAuthenticatedRequest("POST", apiuri, params, &resp)
 	//log.Println
@@ -68,6 +87,7 @@ func (k *Kraken) placeOrder(orderType, side, amount, price string, pair Currency
 
 	return &Order{
 		Currency: pair,
+		Cid:      cid,
 		OrderID2: resp.TxIds[0],
 		Amount:   ToFloat64(amount),
 		Price:    ToFloat64(price),
@@ -76,11 +96,11 @@ func (k *Kraken) placeOrder(orderType, side, amount, price string, pair Currency
 }
 
 func (k *Kraken) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
-	return k.placeOrder("limit", "buy", amount, price, currency)
+	return k.placeOrder("limit", "buy", amount, price, currency, opt...)
 }
 
 func (k *Kraken) LimitSell(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
-	return k.placeOrder("limit", "sell", amount, price, currency)
+	return k.placeOrder("limit", "sell", amount, price, currency, opt...)
 }
 
 func (k *Kraken) MarketBuy(amount, price string, currency CurrencyPair) (*Order, error) {
@@ -105,6 +125,54 @@ func (k *Kraken) CancelOrder(orderId string, currency CurrencyPair) (bool, error
 	return true, nil
 }
 
+func (k *Kraken) CancelOrderByClientId(cid string, currency CurrencyPair) (bool, error) {
+	params := url.Values{}
+	params.Set("cl_ord_id", cid)
+
+	var respmap map[string]interface{}
+	err := k.doAuthenticatedRequest("POST", "private/CancelOrder", params, &respmap)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+//EditOrder会撤销原订单并生成新的txid，newAmount为修改后的总数量
+func (k *Kraken) AmendOrder(orderId string, currency CurrencyPair, newPrice, newAmount string) (*Order, error) {
+	params := url.Values{}
+	params.Set("txid", orderId)
+	params.Set("pair", k.convertPair(currency).ToSymbol(""))
+	if newPrice != "" {
+		params.Set("price", newPrice)
+	}
+	if newAmount != "" {
+		params.Set("volume", newAmount)
+	}
+
+	var resp struct {
+		Status       string `json:"status"`
+		TxId         string `json:"txid"`
+		OriginalTxId string `json:"originaltxid"`
+		Volume       string `json:"volume"`
+		Price        string `json:"price"`
+		ErrorMessage string `json:"error_message"`
+	}
+	err := k.doAuthenticatedRequest("POST", "private/EditOrder", params, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status != "ok" {
+		return nil, errors.New(resp.ErrorMessage)
+	}
+
+	return &Order{
+		Currency: currency,
+		OrderID2: resp.TxId,
+		Amount:   ToFloat64(resp.Volume),
+		Price:    ToFloat64(resp.Price),
+		Status:   ORDER_UNFINISH}, nil
+}
+
 func (k *Kraken) toOrder(orderinfo interface{}) Order {
 	omap := orderinfo.(map[string]interface{})
 	descmap := omap["descr"].(map[string]interface{})
@@ -256,11 +324,11 @@ func (k *Kraken) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
 		bidsmap := depmap["bids"].([]interface{})
 		for _, v := range asksmap {
 			ask := v.([]interface{})
-			dep.AskList = append(dep.AskList, DepthRecord{ToFloat64(ask[0]), ToFloat64(ask[1])})
+			dep.AskList = append(dep.AskList, DepthRecord{Price: ToFloat64(ask[0]), Amount: ToFloat64(ask[1])})
 		}
 		for _, v := range bidsmap {
 			bid := v.([]interface{})
-			dep.BidList = append(dep.BidList, DepthRecord{ToFloat64(bid[0]), ToFloat64(bid[1])})
+			dep.BidList = append(dep.BidList, DepthRecord{Price: ToFloat64(bid[0]), Amount: ToFloat64(bid[1])})
 		}
 		break
 	}
@@ -332,7 +400,7 @@ func (k *Kraken) doAuthenticatedRequest(method, apiuri string, params url.Values
 	//println(string(resp))
 
 	if len(base.Error) > 0 {
-		return errors.New(base.Error[0])
+		return adaptError(base.Error[0])
 	}
 
 	return nil
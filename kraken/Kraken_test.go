@@ -2,9 +2,11 @@ package kraken
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -12,42 +14,49 @@ var k = New(http.DefaultClient, "", "")
 var BCH_XBT = goex.NewCurrencyPair(goex.BCH, goex.XBT)
 
 func TestKraken_GetDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	dep, err := k.GetDepth(2, goex.BTC_USD)
 	assert.Nil(t, err)
 	t.Log(dep)
 }
 
 func TestKraken_GetTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ticker, err := k.GetTicker(goex.ETC_BTC)
 	assert.Nil(t, err)
 	t.Log(ticker)
 }
 
 func TestKraken_GetAccount(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	acc, err := k.GetAccount()
 	assert.Nil(t, err)
 	t.Log(acc)
 }
 
 func TestKraken_LimitSell(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ord, err := k.LimitSell("0.01", "6900", goex.BTC_USD)
 	assert.Nil(t, err)
 	t.Log(ord)
 }
 
 func TestKraken_LimitBuy(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ord, err := k.LimitBuy("0.01", "6100", goex.NewCurrencyPair(goex.XBT, goex.USD))
 	assert.Nil(t, err)
 	t.Log(ord)
 }
 
 func TestKraken_GetUnfinishOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ords, err := k.GetUnfinishOrders(goex.NewCurrencyPair(goex.XBT, goex.USD))
 	assert.Nil(t, err)
 	t.Log(ords)
 }
 
 func TestKraken_CancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	r, err := k.CancelOrder("O6EAJC-YAC3C-XDEEXQ", goex.NewCurrencyPair(goex.XBT, goex.USD))
 	assert.Nil(t, err)
 	t.Log(r)
@@ -58,7 +67,40 @@ func TestKraken_GetTradeBalance(t *testing.T) {
 }
 
 func TestKraken_GetOneOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ord, err := k.GetOneOrder("ODCRMQ-RDEID-CY334C", goex.BTC_USD)
 	assert.Nil(t, err)
 	t.Log(ord)
 }
+
+func TestKraken_GetMarkets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":[],"result":{
+			"XXBTZUSD":{"altname":"XBTUSD","wsname":"XBT/USD","base":"XXBT","quote":"ZUSD","pair_decimals":1,"lot_decimals":8,"ordermin":"0.0001","costmin":"0.5","status":"online"},
+			"XETHXXBT.d":{"altname":"ETHXBT.d","base":"XETH","quote":"XXBT","pair_decimals":5,"lot_decimals":8}}}`))
+	}))
+	defer server.Close()
+
+	domain := API_DOMAIN
+	API_DOMAIN = server.URL + "/0/"
+	defer func() { API_DOMAIN = domain }()
+
+	mapper := goex.NewSymbolMapper(New(http.DefaultClient, "", ""), 0)
+
+	symbol, err := mapper.ToSymbol(goex.BTC_USD, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "XXBTZUSD", symbol)
+
+	for _, s := range []string{"XXBTZUSD", "XBTUSD", "XBT/USD"} {
+		pair, _, err := mapper.ToCurrencyPair(s)
+		assert.Nil(t, err)
+		assert.Equal(t, "BTC_USD", pair.ToSymbol("_"))
+	}
+
+	m, _ := mapper.Registry().GetMarket(goex.BTC_USD, "")
+	assert.Equal(t, "0.1", m.TickSize.String())
+	assert.Equal(t, "0.0001", m.MinAmount.String())
+
+	_, _, err = mapper.ToCurrencyPair("ETHXBT.d")
+	assert.NotNil(t, err)
+}
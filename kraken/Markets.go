@@ -0,0 +1,74 @@
+package kraken
+
+import (
+	"net/url"
+	"strings"
+
+	. "github.com/mrwill84/goex"
+)
+
+var (
+	_ MarketInfo = (*Kraken)(nil)
+)
+
+//kraken的币种代码 -> 通用代码
+var assetAliases = map[string]Currency{
+	"XBT": BTC,
+	"XDG": NewCurrency("DOGE", ""),
+}
+
+type assetPair struct {
+	Altname      string `json:"altname"` //XBTUSD
+	Wsname       string `json:"wsname"`  //XBT/USD
+	Base         string `json:"base"`    //XXBT
+	Quote        string `json:"quote"`   //ZUSD
+	PairDecimals int32  `json:"pair_decimals"`
+	LotDecimals  int32  `json:"lot_decimals"`
+	OrderMin     string `json:"ordermin"`
+	CostMin      string `json:"costmin"`
+	Status       string `json:"status"`
+}
+
+func adaptAsset(code string) Currency {
+	if c, ok := assetAliases[strings.ToUpper(code)]; ok {
+		return c
+	}
+	return NewCurrency(code, "")
+}
+
+//symbol为AssetPairs的key(如XXBTZUSD)，altname和wsname作为别名，交易对中XBT映射为BTC
+func (k *Kraken) GetMarkets() ([]Market, error) {
+	var pairs map[string]assetPair
+	err := k.doAuthenticatedRequest("GET", "public/AssetPairs", url.Values{}, &pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, 0, len(pairs))
+	for symbol, p := range pairs {
+		assets := strings.Split(p.Wsname, "/")
+		if len(assets) != 2 {
+			continue //暗池交易对没有wsname
+		}
+
+		m := Market{
+			Pair:        NewCurrencyPair(adaptAsset(assets[0]), adaptAsset(assets[1])),
+			Symbol:      symbol,
+			Aliases:     []string{p.Altname, p.Wsname},
+			TickSize:    NewDecimal(1, -p.PairDecimals),
+			LotSize:     NewDecimal(1, -p.LotDecimals),
+			MinAmount:   ToDecimal(p.OrderMin),
+			MinNotional: ToDecimal(p.CostMin),
+			Status:      MARKET_SUSPEND,
+		}
+
+		switch p.Status {
+		case "", "online":
+			m.Status = MARKET_TRADING
+		case "delisted":
+			m.Status = MARKET_OFFLINE
+		}
+		markets = append(markets, m)
+	}
+	return markets, nil
+}
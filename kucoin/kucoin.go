@@ -47,6 +47,10 @@ type KuCoin struct {
 	service       *kucoin.ApiService
 }
 
+var (
+	_ API = (*KuCoin)(nil)
+)
+
 var inernalKlinePeriodConverter = map[KlinePeriod]string{
 	KLINE_PERIOD_1MIN:  "1min",
 	KLINE_PERIOD_3MIN:  "3min",
@@ -92,8 +96,24 @@ func (kc *KuCoin) GetTicker(currency CurrencyPair) (*Ticker, error) {
 	return &ticker, nil
 }
 
+func adaptTimeInForce(in *kucoin.CreateOrderModel, opt ...LimitOrderOptionalParameter) {
+	if tif, ok := GetTimeInForce(opt...); ok {
+		switch tif {
+		case PostOnly:
+			in.PostOnly = true
+		case Ioc:
+			in.TimeInForce = "IOC"
+		case Fok:
+			in.TimeInForce = "FOK"
+		}
+	}
+}
+
 func (kc *KuCoin) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
-	clientID := GenerateOrderClientId(32)
+	clientID := GetClientOrderId(opt...)
+	if clientID == "" {
+		clientID = NewClientOrderId(KUCOIN)
+	}
 	in := kucoin.CreateOrderModel{
 		ClientOid: clientID,
 		Side:      "buy",
@@ -102,6 +122,7 @@ func (kc *KuCoin) LimitBuy(amount, price string, currency CurrencyPair, opt ...L
 		Price:     price,
 		Size:      amount,
 	}
+	adaptTimeInForce(&in, opt...)
 	resp, err := kc.service.CreateOrder(&in)
 	if err != nil {
 		log.Error("KuCoin LimitBuy error:", err)
@@ -123,7 +144,10 @@ func (kc *KuCoin) LimitBuy(amount, price string, currency CurrencyPair, opt ...L
 }
 
 func (kc *KuCoin) LimitSell(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
-	clientID := GenerateOrderClientId(32)
+	clientID := GetClientOrderId(opt...)
+	if clientID == "" {
+		clientID = NewClientOrderId(KUCOIN)
+	}
 	in := kucoin.CreateOrderModel{
 		ClientOid: clientID,
 		Side:      "sell",
@@ -132,6 +156,7 @@ func (kc *KuCoin) LimitSell(amount, price string, currency CurrencyPair, opt ...
 		Price:     price,
 		Size:      amount,
 	}
+	adaptTimeInForce(&in, opt...)
 	resp, err := kc.service.CreateOrder(&in)
 	if err != nil {
 		log.Error("KuCoin LimitSell error:", err)
@@ -153,7 +178,7 @@ func (kc *KuCoin) LimitSell(amount, price string, currency CurrencyPair, opt ...
 }
 
 func (kc *KuCoin) MarketBuy(amount, price string, currency CurrencyPair) (*Order, error) {
-	clientID := GenerateOrderClientId(32)
+	clientID := NewClientOrderId(KUCOIN)
 	in := kucoin.CreateOrderModel{
 		ClientOid: clientID,
 		Side:      "buy",
@@ -184,7 +209,7 @@ func (kc *KuCoin) MarketBuy(amount, price string, currency CurrencyPair) (*Order
 }
 
 func (kc *KuCoin) MarketSell(amount, price string, currency CurrencyPair) (*Order, error) {
-	clientID := GenerateOrderClientId(32)
+	clientID := NewClientOrderId(KUCOIN)
 	in := kucoin.CreateOrderModel{
 		ClientOid: clientID,
 		Side:      "sell",
@@ -602,7 +627,7 @@ func (kc *KuCoin) CreateAccount(typo, currency string) (*kucoin.AccountModel, er
 // The inner transfer interface is used for transferring assets between the accounts of a user and is free of charges.
 // For example, a user could transfer assets from their main account to their trading account on the platform.
 func (kc *KuCoin) InnerTransfer(currency, from, to, amount string) (string, error) {
-	resp, err := kc.service.InnerTransferV2(GenerateOrderClientId(32), currency, from, to, amount)
+	resp, err := kc.service.InnerTransferV2(NewClientOrderId(KUCOIN), currency, from, to, amount)
 	if err != nil {
 		log.Error("KuCoin InnerTransfer error:", err)
 		return "", err
@@ -621,7 +646,7 @@ func (kc *KuCoin) InnerTransfer(currency, from, to, amount string) (string, erro
 // SubTransfer transfers between master account and sub-account.
 func (kc *KuCoin) SubTransfer(currency, amount, direction, subUserId, accountType, subAccountType string) (string, error) {
 	params := map[string]string{
-		"clientOid":      GenerateOrderClientId(32),
+		"clientOid":      NewClientOrderId(KUCOIN),
 		"currency":       currency,
 		"amount":         amount,
 		"direction":      direction,      // IN or OUT
@@ -4,31 +4,37 @@ import (
 	"testing"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var kc = New("", "", "")
 
 func TestKuCoin_GetTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ticker, _ := kc.GetTicker(goex.BTC_USDT)
 	t.Log(ticker)
 }
 
 func TestKuCoin_GetDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	depth, _ := kc.GetDepth(10, goex.BTC_USDT)
 	t.Log(depth)
 }
 
 func TestKuCoin_GetKlineRecords(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	kLines, _ := kc.GetKlineRecords(goex.BTC_USDT, goex.KLINE_PERIOD_1MIN, 10)
 	t.Log(kLines)
 }
 
 func TestKuCoin_GetTrades(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	trades, _ := kc.GetTrades(goex.BTC_USDT, 0)
 	t.Log(trades)
 }
 
 func TestKuCoin_GetAccount(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	acc, _ := kc.GetAccount()
 	t.Log(acc)
 }
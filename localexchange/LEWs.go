@@ -1,6 +1,7 @@
 package localexchange
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -25,7 +26,7 @@ type wsResp struct {
 type LocalExchangeWs struct {
 	base *LE
 	*WsBuilder
-	once       *sync.Once
+	connLock   sync.Mutex
 	WsConn     *WsConn
 	respHandle func(channel string, instId string, data json.RawMessage) error
 }
@@ -44,7 +45,6 @@ type OKExLogin struct {
 
 func NewLocalExchangeWs(base *LE, handle func(channel string, instId string, data json.RawMessage) error) *LocalExchangeWs {
 	leWs := &LocalExchangeWs{
-		once:       new(sync.Once),
 		base:       base,
 		respHandle: handle,
 	}
@@ -96,10 +96,19 @@ func (okV3Ws *LocalExchangeWs) getTablePrefix(currencyPair CurrencyPair, contrac
 	return "futures"
 }
 
-func (okV3Ws *LocalExchangeWs) ConnectWs() {
-	okV3Ws.once.Do(func() {
-		okV3Ws.WsConn = okV3Ws.WsBuilder.Build()
-	})
+//建立连接，已连接时直接返回；连接失败时返回错误，下次调用时重试
+func (okV3Ws *LocalExchangeWs) ConnectWs() error {
+	okV3Ws.connLock.Lock()
+	defer okV3Ws.connLock.Unlock()
+	if okV3Ws.WsConn != nil {
+		return nil
+	}
+	c, err := okV3Ws.WsBuilder.BuildWithContext(context.Background())
+	if err != nil {
+		return err
+	}
+	okV3Ws.WsConn = c
+	return nil
 }
 
 func (okV3Ws *LocalExchangeWs) parseChannel(channel string) (string, error) {
@@ -152,7 +161,9 @@ func (okV3Ws *LocalExchangeWs) handle(msg []byte) error {
 }
 
 func (okV3Ws *LocalExchangeWs) Subscribe(sub map[string]interface{}) error {
-	okV3Ws.ConnectWs()
+	if err := okV3Ws.ConnectWs(); err != nil {
+		return err
+	}
 	logger.Info("[ws] [response] ", sub)
 	return okV3Ws.WsConn.Subscribe(sub)
 }
@@ -2,6 +2,7 @@ package localexchange
 
 import (
 	"encoding/json"
+	"github.com/mrwill84/goex/internal/replay"
 	"testing"
 )
 
@@ -9,11 +10,12 @@ var leconfig = LE{
 	wsAddress: "ws://127.0.0.1:3001/ws",
 }
 
-func HHH(channel string, data json.RawMessage) error {
+func HHH(channel string, instId string, data json.RawMessage) error {
 	return nil
 }
 
 func TestKuCoin_GetTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 
 	wx := NewLocalExchangeWs(&leconfig, HHH)
 	ps := []map[string]string{}
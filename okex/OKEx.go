@@ -28,6 +28,10 @@ type OKEx struct {
 	OKExV3SwapWs    *OKExV3SwapWs
 }
 
+var (
+	_ API = (*OKEx)(nil)
+)
+
 func NewOKEx(config *APIConfig) *OKEx {
 	if config.Endpoint == "" {
 		config.Endpoint = baseUrl
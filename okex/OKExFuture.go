@@ -36,6 +36,11 @@ type OKExFuture struct {
 	allContractInfo AllFutureContractInfo
 }
 
+var (
+	_ FutureRestAPI          = (*OKExFuture)(nil)
+	_ FutureClientOrderIdAPI = (*OKExFuture)(nil)
+)
+
 func (ok *OKExFuture) GetExchangeName() string {
 	return OKEX_FUTURE
 }
@@ -333,15 +338,10 @@ func (ok *OKExFuture) GetFutureUserinfo(currencyPair ...CurrencyPair) (*FutureAc
 func (ok *OKExFuture) normalizePrice(price float64, pair CurrencyPair) string {
 	for _, info := range ok.allContractInfo.contractInfos {
 		if info.UnderlyingIndex == pair.CurrencyA.Symbol && info.QuoteCurrency == pair.CurrencyB.Symbol {
-			var bit = 0
-			for info.TickSize < 1 {
-				bit++
-				info.TickSize *= 10
-			}
-			return FloatToString(price, bit)
+			return NewDecimalFromFloat(price).RoundToTick(NewDecimalFromFloat(info.TickSize)).String()
 		}
 	}
-	return FloatToString(price, 2)
+	return NewDecimalFromFloat(price).Round(2).String()
 }
 
 //matchPrice:是否以对手价下单(0:不是 1:是)，默认为0;当取值为1时,price字段无效，当以对手价下单，order_type只能选择0:普通委托
@@ -369,7 +369,10 @@ func (ok *OKExFuture) PlaceFutureOrder2(matchPrice int, ord *FutureOrder) (*Futu
 		return nil, errors.New("ord param is nil")
 	}
 	param.InstrumentId = ok.GetFutureContractId(ord.Currency, ord.ContractName)
-	param.ClientOid = GenerateOrderClientId(32)
+	param.ClientOid = ord.ClientOid
+	if param.ClientOid == "" {
+		param.ClientOid = NewClientOrderId(OKEX)
+	}
 	param.Type = ord.OType
 	param.OrderType = ord.OrderType
 	param.Price = ok.normalizePrice(ord.Price, ord.Currency)
@@ -415,10 +418,11 @@ func (ok *OKExFuture) LimitFuturesOrder(currencyPair CurrencyPair, contractType,
 		Amount:       ToFloat64(amount),
 		OType:        openType,
 		ContractName: contractType,
+		ClientOid:    GetClientOrderId(opt...),
 	}
 
-	if len(opt) > 0 {
-		switch opt[0] {
+	if tif, isOk := GetTimeInForce(opt...); isOk {
+		switch tif {
 		case PostOnly:
 			ord.OrderType = 1
 		case Fok:
@@ -612,6 +616,15 @@ func (ok *OKExFuture) GetFutureOrder(orderId string, currencyPair CurrencyPair,
 	return &ord, nil
 }
 
+//v3接口的orderId参数可以直接传client_oid
+func (ok *OKExFuture) GetFutureOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
+	return ok.GetFutureOrder(cid, currencyPair, contractType)
+}
+
+func (ok *OKExFuture) FutureCancelOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (bool, error) {
+	return ok.FutureCancelOrder(currencyPair, contractType, cid)
+}
+
 func (ok *OKExFuture) GetUnfinishFutureOrders(currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
 	urlPath := fmt.Sprintf("/api/futures/v3/orders/%s?state=6&limit=100", ok.GetFutureContractId(currencyPair, contractType))
 	var response struct {
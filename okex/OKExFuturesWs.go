@@ -17,8 +17,14 @@ type OKExV3FuturesWs struct {
 	depthCallback  func(*Depth)
 	tradeCallback  func(*Trade, string)
 	klineCallback  func(*FutureKline, int)
+	symbolMapper   *SymbolMapper
 }
 
+var (
+	_ FuturesWsApi      = (*OKExV3FuturesWs)(nil)
+	_ SymbolMapperAware = (*OKExV3FuturesWs)(nil)
+)
+
 func NewOKExV3FuturesWs(base *OKEx) *OKExV3FuturesWs {
 	okV3Ws := &OKExV3FuturesWs{
 		base: base,
@@ -27,6 +33,11 @@ func NewOKExV3FuturesWs(base *OKEx) *OKExV3FuturesWs {
 	return okV3Ws
 }
 
+//设置后使用交易规则把推送的永续合约instrument_id映射回交易对，交割合约通过合约信息接口解析
+func (okV3Ws *OKExV3FuturesWs) SetSymbolMapper(mapper *SymbolMapper) {
+	okV3Ws.symbolMapper = mapper
+}
+
 func (okV3Ws *OKExV3FuturesWs) TickerCallback(tickerCallback func(*FutureTicker)) {
 	okV3Ws.tickerCallback = tickerCallback
 }
@@ -123,6 +134,30 @@ func (okV3Ws *OKExV3FuturesWs) SubscribeTrade(currencyPair CurrencyPair, contrac
 		"args": []string{fmt.Sprintf(chName, "trade")}})
 }
 
+//channel为books , ticker , trade
+func (okV3Ws *OKExV3FuturesWs) unsubscribe(currencyPair CurrencyPair, contractType, channel string) error {
+	chName := okV3Ws.getChannelName(currencyPair, contractType)
+	if chName == "" {
+		return errors.New("unsubscribe error, get channel name fail")
+	}
+
+	return okV3Ws.v3Ws.Unsubscribe(map[string]interface{}{
+		"op":   "subscribe",
+		"args": []string{fmt.Sprintf(chName, channel)}})
+}
+
+func (okV3Ws *OKExV3FuturesWs) UnsubscribeDepth(currencyPair CurrencyPair, contractType string) error {
+	return okV3Ws.unsubscribe(currencyPair, contractType, "books")
+}
+
+func (okV3Ws *OKExV3FuturesWs) UnsubscribeTicker(currencyPair CurrencyPair, contractType string) error {
+	return okV3Ws.unsubscribe(currencyPair, contractType, "ticker")
+}
+
+func (okV3Ws *OKExV3FuturesWs) UnsubscribeTrade(currencyPair CurrencyPair, contractType string) error {
+	return okV3Ws.unsubscribe(currencyPair, contractType, "trade")
+}
+
 func (okV3Ws *OKExV3FuturesWs) SubscribeKline(currencyPair CurrencyPair, contractType string, period int) error {
 	if okV3Ws.klineCallback == nil {
 		return errors.New("place set kline callback func")
@@ -145,8 +180,7 @@ func (okV3Ws *OKExV3FuturesWs) SubscribeKline(currencyPair CurrencyPair, contrac
 
 func (okV3Ws *OKExV3FuturesWs) getContractAliasAndCurrencyPairFromInstrumentId(instrumentId string) (alias string, pair CurrencyPair) {
 	if strings.HasSuffix(instrumentId, "SWAP") {
-		ar := strings.Split(instrumentId, "-")
-		return instrumentId, NewCurrencyPair2(fmt.Sprintf("%s_%s", ar[0], ar[1]))
+		return instrumentId, okV3Ws.symbolMapper.PairOf(instrumentId, adaptSwapInstrumentId)
 	} else {
 		contractInfo, err := okV3Ws.base.OKExFuture.GetContractInfo(instrumentId)
 		if err != nil {
@@ -3,6 +3,7 @@ package okex
 import (
 	"github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/internal/replay"
 	"net/http"
 	"os"
 	"testing"
@@ -18,6 +19,7 @@ func init() {
 }
 
 func TestNewOKExV3FuturesWs(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	os.Setenv("HTTPS_PROXY", "socks5://127.0.0.1:1080")
 	ok := NewOKEx(&goex.APIConfig{
 		HttpClient: http.DefaultClient,
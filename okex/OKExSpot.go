@@ -16,6 +16,12 @@ type OKExSpot struct {
 	*OKEx
 }
 
+var (
+	_ API              = (*OKExSpot)(nil)
+	_ ClientOrderIdAPI = (*OKExSpot)(nil)
+	_ MarketInfo       = (*OKExSpot)(nil)
+)
+
 // [{
 //        "frozen":"0",
 //        "hold":"0",
@@ -113,9 +119,12 @@ func (ok *OKExSpot) BatchPlaceOrders(orders []Order) ([]PlaceOrderResponse, erro
 func (ok *OKExSpot) PlaceOrder(ty string, ord *Order) (*Order, error) {
 	urlPath := "/api/spot/v3/orders"
 	param := PlaceOrderParam{
-		ClientOid:    GenerateOrderClientId(32),
+		ClientOid:    ord.Cid,
 		InstrumentId: ord.Currency.AdaptUsdToUsdt().ToLower().ToSymbol("-"),
 	}
+	if param.ClientOid == "" {
+		param.ClientOid = NewClientOrderId(OKEX)
+	}
 
 	var response PlaceOrderResponse
 
@@ -166,27 +175,29 @@ func (ok *OKExSpot) PlaceOrder(ty string, ord *Order) (*Order, error) {
 
 func (ok *OKExSpot) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
 	ty := "limit"
-	if len(opt) > 0 {
-		ty = opt[0].String()
+	if tif, isOk := GetTimeInForce(opt...); isOk {
+		ty = tif.String()
 	}
 	return ok.PlaceOrder(ty, &Order{
 		Price:    ToFloat64(price),
 		Amount:   ToFloat64(amount),
 		Currency: currency,
 		Side:     BUY,
+		Cid:      GetClientOrderId(opt...),
 	})
 }
 
 func (ok *OKExSpot) LimitSell(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
 	ty := "limit"
-	if len(opt) > 0 {
-		ty = opt[0].String()
+	if tif, isOk := GetTimeInForce(opt...); isOk {
+		ty = tif.String()
 	}
 	return ok.PlaceOrder(ty, &Order{
 		Price:    ToFloat64(price),
 		Amount:   ToFloat64(amount),
 		Currency: currency,
 		Side:     SELL,
+		Cid:      GetClientOrderId(opt...),
 	})
 }
 
@@ -308,6 +319,14 @@ func (ok *OKExSpot) GetOneOrder(orderId string, currency CurrencyPair) (*Order,
 	return ordInfo, nil
 }
 
+func (ok *OKExSpot) GetOrderByClientId(cid string, currency CurrencyPair) (*Order, error) {
+	return ok.GetOneOrder(cid, currency)
+}
+
+func (ok *OKExSpot) CancelOrderByClientId(cid string, currency CurrencyPair) (bool, error) {
+	return ok.CancelOrder(cid, currency)
+}
+
 func (ok *OKExSpot) GetUnfinishOrders(currency CurrencyPair) ([]Order, error) {
 	urlPath := fmt.Sprintf("/api/spot/v3/orders_pending?instrument_id=%s", currency.AdaptUsdToUsdt().ToSymbol("-"))
 	var response []OrderResponse
@@ -564,3 +583,31 @@ func (ok *OKExSpot) GetCurrenciesPrecision() ([]OKExSpotSymbol, error) {
 	}
 	return Symbols, nil
 }
+
+func (ok *OKExSpot) GetMarkets() ([]Market, error) {
+	var response []struct {
+		InstrumentId  string `json:"instrument_id"`
+		BaseCurrency  string `json:"base_currency"`
+		QuoteCurrency string `json:"quote_currency"`
+		MinSize       string `json:"min_size"`
+		SizeIncrement string `json:"size_increment"`
+		TickSize      string `json:"tick_size"`
+	}
+	err := ok.DoRequest("GET", "/api/spot/v3/instruments", "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, 0, len(response))
+	for _, v := range response {
+		markets = append(markets, Market{
+			Pair:      NewCurrencyPair(NewCurrency(v.BaseCurrency, ""), NewCurrency(v.QuoteCurrency, "")),
+			Symbol:    v.InstrumentId,
+			TickSize:  ToDecimal(v.TickSize),
+			LotSize:   ToDecimal(v.SizeIncrement),
+			MinAmount: ToDecimal(v.MinSize),
+			Status:    MARKET_TRADING, //只返回可交易的币对
+		})
+	}
+	return markets, nil
+}
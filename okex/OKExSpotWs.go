@@ -16,8 +16,14 @@ type OKExV3SpotWs struct {
 	depthCallback  func(*Depth)
 	tradeCallback  func(*Trade)
 	klineCallback  func(*Kline, KlinePeriod)
+	symbolMapper   *SymbolMapper
 }
 
+var (
+	_ SpotWsApi         = (*OKExV3SpotWs)(nil)
+	_ SymbolMapperAware = (*OKExV3SpotWs)(nil)
+)
+
 func NewOKExSpotV3Ws(base *OKEx) *OKExV3SpotWs {
 	okV3Ws := &OKExV3SpotWs{
 		base: base,
@@ -26,6 +32,11 @@ func NewOKExSpotV3Ws(base *OKEx) *OKExV3SpotWs {
 	return okV3Ws
 }
 
+//设置后使用交易规则把推送的instrument_id映射回交易对
+func (okV3Ws *OKExV3SpotWs) SetSymbolMapper(mapper *SymbolMapper) {
+	okV3Ws.symbolMapper = mapper
+}
+
 func (okV3Ws *OKExV3SpotWs) TickerCallback(tickerCallback func(*Ticker)) {
 	okV3Ws.tickerCallback = tickerCallback
 }
@@ -80,6 +91,24 @@ func (okV3Ws *OKExV3SpotWs) SubscribeTrade(currencyPair CurrencyPair) error {
 		"args": []string{fmt.Sprintf("spot/trade:%s", currencyPair.ToSymbol("-"))}})
 }
 
+func (okV3Ws *OKExV3SpotWs) UnsubscribeDepth(currencyPair CurrencyPair) error {
+	return okV3Ws.v3Ws.Unsubscribe(map[string]interface{}{
+		"op":   "subscribe",
+		"args": []string{fmt.Sprintf("spot/depth5:%s", currencyPair.ToSymbol("-"))}})
+}
+
+func (okV3Ws *OKExV3SpotWs) UnsubscribeTicker(currencyPair CurrencyPair) error {
+	return okV3Ws.v3Ws.Unsubscribe(map[string]interface{}{
+		"op":   "subscribe",
+		"args": []string{fmt.Sprintf("spot/ticker:%s", currencyPair.ToSymbol("-"))}})
+}
+
+func (okV3Ws *OKExV3SpotWs) UnsubscribeTrade(currencyPair CurrencyPair) error {
+	return okV3Ws.v3Ws.Unsubscribe(map[string]interface{}{
+		"op":   "subscribe",
+		"args": []string{fmt.Sprintf("spot/trade:%s", currencyPair.ToSymbol("-"))}})
+}
+
 func (okV3Ws *OKExV3SpotWs) SubscribeKline(currencyPair CurrencyPair, period int) error {
 	if okV3Ws.klineCallback == nil {
 		return errors.New("place set kline callback func")
@@ -96,7 +125,9 @@ func (okV3Ws *OKExV3SpotWs) SubscribeKline(currencyPair CurrencyPair, period int
 }
 
 func (okV3Ws *OKExV3SpotWs) getCurrencyPair(instrumentId string) CurrencyPair {
-	return NewCurrencyPair3(instrumentId, "-")
+	return okV3Ws.symbolMapper.PairOf(instrumentId, func(instrumentId string) CurrencyPair {
+		return NewCurrencyPair3(instrumentId, "-")
+	}, "")
 }
 
 func (okV3Ws *OKExV3SpotWs) handle(*wsResp) error {
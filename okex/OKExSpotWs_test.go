@@ -3,6 +3,7 @@ package okex
 import (
 	"github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/internal/replay"
 	"os"
 	"testing"
 	"time"
@@ -13,6 +14,7 @@ func init() {
 }
 
 func TestNewOKExSpotV3Ws(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	os.Setenv("HTTPS_PROXY", "socks5://127.0.0.1:1080")
 	okexSpotV3Ws := okex.OKExV3SpotWs
 	okexSpotV3Ws.TickerCallback(func(ticker *goex.Ticker) {
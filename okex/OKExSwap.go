@@ -9,8 +9,6 @@ import (
 	"strings"
 	"time"
 
-	"funcs/libs/loging"
-
 	"github.com/mrwill84/goex"
 	. "github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
@@ -77,6 +75,7 @@ const (
 	CANCEL_ORDER                = "/api/v5/trade/cancel-order"
 	GET_ORDER                   = "/api/v5/trade/order?ordId=%s&instId=%s"
 	GET_ORDER_BY_CID            = "/api/v5/trade/order?clOrdId=%s&instId=%s"
+	GET_ORDERS_HISTORY          = "/api/v5/trade/orders-history?%s"
 	ORDER_HISTORY               = "/api/v5/trade/orders-history?instId=%s&instType=%s&before=%s&limit=1"
 	ORDER_HISTORY_WITHOUT_AFTER = "/api/v5/trade/orders-history?instId=%s&instType=%s&limit=2"
 	GET_INFO                    = ""
@@ -106,6 +105,12 @@ type OKExSwap struct {
 	config *APIConfig
 }
 
+var (
+	_ FutureRestAPI     = (*OKExSwap)(nil)
+	_ FutureCidOrderAPI = (*OKExSwap)(nil)
+	_ MarketInfo        = (*OKExSwap)(nil)
+)
+
 func NewOKExSwap(config *APIConfig) *OKExSwap {
 	return &OKExSwap{OKEx: &OKEx{config: config}, config: config}
 }
@@ -296,9 +301,16 @@ type PlaceOrdersInfo struct {
 	OrderData    []*BasePlaceOrderInfo `json:"order_data"`
 }
 
-func (ok *OKExSwap) PlaceFutureOrder(cid string, currencyPair CurrencyPair, contractType, price, amount, openType string, matchPrice int, leverRate float64) (string, error) {
+func (ok *OKExSwap) PlaceFutureOrder(currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice int, leverRate float64) (string, error) {
+	return ok.PlaceFutureOrderWithCid(NewClientOrderId(OKEX_SWAP), currencyPair, contractType, price, amount, OpenTypeName(openType), matchPrice, leverRate)
+}
+
+func (ok *OKExSwap) PlaceFutureOrderWithCid(cid string, currencyPair CurrencyPair, contractType, price, amount, openType string, matchPrice int, leverRate float64) (string, error) {
 	fOrder, err := ok.PlaceFutureOrder2(cid, currencyPair, contractType, price, amount, openType, matchPrice)
-	return fOrder.OrderID2, err
+	if err != nil {
+		return "", err
+	}
+	return fOrder.OrderID2, nil
 }
 func NowAsUnixMilli() int64 {
 	return time.Now().UnixNano() / 1e6
@@ -319,11 +331,15 @@ func (ok *OKExSwap) PlaceFutureOrder2(cid string, currencyPair CurrencyPair, con
 			"buy", "short",
 		},
 	}
+	if _, has := mapping[openType]; !has {
+		return nil, fmt.Errorf("unknown open type %s", openType)
+	}
 
-	//cid := //GenerateOrderClientId(32)
 	marketOrder := "limit"
 	if matchPrice == 1 {
 		marketOrder = "market"
+	} else if tif, has := GetTimeInForce(opt...); has {
+		marketOrder = tif.String()
 	}
 	param := PlaceOrderInfoV5{
 		BasePlaceOrderInfoV5{
@@ -339,17 +355,11 @@ func (ok *OKExSwap) PlaceFutureOrder2(cid string, currencyPair CurrencyPair, con
 	}
 
 	reqBody, _, _ := ok.OKEx.BuildRequestBody(param)
-	mapping2 := map[string]int{
-		"openlong":   1,
-		"openshort":  2,
-		"closelong":  3,
-		"closeshort": 4,
-	}
 	fOrder := &FutureOrder{
 		ClientOid:    cid,
 		Currency:     currencyPair,
 		ContractName: contractType,
-		OType:        mapping2[openType],
+		OType:        ParseOpenType(openType),
 		Price:        ToFloat64(price),
 		Amount:       ToFloat64(amount),
 		Status:       goex.ORDER_UNFINISH,
@@ -382,15 +392,33 @@ func (ok *OKExSwap) PlaceFutureOrder2(cid string, currencyPair CurrencyPair, con
 	return fOrder, nil
 }
 
-func (ok *OKExSwap) LimitFuturesOrder(cid string, currencyPair CurrencyPair, contractType, price, amount, openType string, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
+func (ok *OKExSwap) LimitFuturesOrder(currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
+	cid := GetClientOrderId(opt...)
+	if cid == "" {
+		cid = NewClientOrderId(OKEX_SWAP)
+	}
+	return ok.PlaceFutureOrder2(cid, currencyPair, contractType, price, amount, OpenTypeName(openType), 0, opt...)
+}
+
+func (ok *OKExSwap) MarketFuturesOrder(currencyPair CurrencyPair, contractType, amount string, openType int) (*FutureOrder, error) {
+	return ok.PlaceFutureOrder2(NewClientOrderId(OKEX_SWAP), currencyPair, contractType, "0", amount, OpenTypeName(openType), 1)
+}
+
+func (ok *OKExSwap) LimitFuturesOrderWithCid(cid string, currencyPair CurrencyPair, contractType, price, amount, openType string, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
 	return ok.PlaceFutureOrder2(cid, currencyPair, contractType, price, amount, openType, 0, opt...)
 }
 
-func (ok *OKExSwap) MarketFuturesOrder(cid string, currencyPair CurrencyPair, contractType, amount, openType string) (*FutureOrder, error) {
+func (ok *OKExSwap) MarketFuturesOrderWithCid(cid string, currencyPair CurrencyPair, contractType, amount, openType string) (*FutureOrder, error) {
 	return ok.PlaceFutureOrder2(cid, currencyPair, contractType, "0", amount, openType, 1)
 }
 
-func (ok *OKExSwap) FutureCancelOrder(currencyPair CurrencyPair, contractType, orderId string) (TradeStatus, error) {
+func (ok *OKExSwap) FutureCancelOrder(currencyPair CurrencyPair, contractType, orderId string) (bool, error) {
+	status, err := ok.FutureCancelOrderWithStatus(currencyPair, contractType, orderId)
+	return err == nil && status == ORDER_CANCEL, err
+}
+
+//撤单并返回订单状态，订单已撤销(51401)返回ORDER_CANCEL，已完成(51402)返回ORDER_FINISH
+func (ok *OKExSwap) FutureCancelOrderWithStatus(currencyPair CurrencyPair, contractType, orderId string) (TradeStatus, error) {
 	var cancelParam struct {
 		OrderId      string `json:"ordId"`
 		InstrumentId string `json:"instId"`
@@ -409,28 +437,62 @@ func (ok *OKExSwap) FutureCancelOrder(currencyPair CurrencyPair, contractType, o
 	cancelParam.InstrumentId = ok.adaptContractType(currencyPair)
 	cancelParam.OrderId = orderId
 
-	//fmt.Println("cancelParam.InstrumentId", cancelParam.InstrumentId, cancelParam.OrderId)
 	req, _, _ := ok.OKEx.BuildRequestBody(cancelParam)
 
 	err := ok.DoRequest("POST", CANCEL_ORDER, req, &resp)
-	//loging.Info("GetFutureOrder", "resp.Message ", resp.ErrorMessage)
 	if err != nil {
 		return SYSTEM_REQUEST_FAIL, err
 	}
 
 	if resp.Code == "0" {
 		return ORDER_CANCEL, nil
-	} else if resp.Code == "0" && len(resp.Data) > 0 {
-		if resp.Data[0].BaseResponseV5.ErrorCode == "51401" {
+	}
+
+	if len(resp.Data) > 0 {
+		switch resp.Data[0].ErrorCode {
+		case "51401":
 			return ORDER_CANCEL, nil
-		} else if resp.Data[0].BaseResponseV5.ErrorCode == "51402" {
+		case "51402":
 			return ORDER_FINISH, nil
 		}
+		return ORDER_FAIL, fmt.Errorf("%s:%s", resp.Data[0].ErrorCode, resp.Data[0].ErrorMessage)
 	}
-	return ORDER_CANCEL, nil
+
+	return ORDER_FAIL, fmt.Errorf("%s:%s", resp.Code, resp.Msg)
 }
 
-func (ok *OKExSwap) GetFutureOrderHistory(pair CurrencyPair, orderAfter string) ([]FutureOrder, error) {
+//最近三个月的历史订单，可选参数: limit , after , before , state , ordType
+func (ok *OKExSwap) GetFutureOrderHistory(pair CurrencyPair, contractType string, optional ...OptionalParameter) ([]FutureOrder, error) {
+	instId := ok.adaptContractType(pair)
+
+	param := url.Values{}
+	param.Set("instType", "SWAP")
+	param.Set("instId", instId)
+	MergeOptionalParameter(&param, optional...)
+
+	var response BizWarmTipsV5
+
+	err := ok.DoRequest("GET", fmt.Sprintf(GET_ORDERS_HISTORY, param.Encode()), "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Code != "0" {
+		return nil, fmt.Errorf("%s:%s", response.Code, response.Message)
+	}
+
+	orders := make([]FutureOrder, 0, len(response.OrderInfo))
+	for _, info := range response.OrderInfo {
+		ord := ok.parseOrder(info)
+		ord.Currency = pair
+		ord.ContractName = instId
+		orders = append(orders, ord)
+	}
+
+	return orders, nil
+}
+
+func (ok *OKExSwap) GetFutureOrderHistoryAfter(pair CurrencyPair, orderAfter string) ([]FutureOrder, error) {
 
 	urlPath := fmt.Sprintf(ORDER_HISTORY, ok.adaptContractType(pair), "SWAP", orderAfter)
 	if orderAfter == "" {
@@ -438,10 +500,6 @@ func (ok *OKExSwap) GetFutureOrderHistory(pair CurrencyPair, orderAfter string)
 	}
 	logger.Infof("GetFutureOrderHistory [urlPath] %s", urlPath)
 	contractType := ok.adaptContractType(pair)
-	//param := url.Values{}
-	//param.Set("limit", "100")
-	//param.Set("state", "7")
-	//MergeOptionalParameter(&param, optional...)
 
 	var response BizWarmTipsV5
 
@@ -502,7 +560,15 @@ func (ok *OKExSwap) GetUnfinishFutureOrders(currencyPair CurrencyPair, contractT
  *获取订单信息
  */
 func (ok *OKExSwap) GetFutureOrders(orderIds []string, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
-	panic("")
+	orders := make([]FutureOrder, 0, len(orderIds))
+	for _, orderId := range orderIds {
+		ord, err := ok.GetFutureOrder(orderId, currencyPair, contractType)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *ord)
+	}
+	return orders, nil
 }
 
 /**
@@ -534,140 +600,39 @@ type BizWarmTipsV5 struct {
 }
 
 func (ok *OKExSwap) GetFutureOrderByCid(cid string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
-	var getOrderParam struct {
-		OrderId      string `json:"clOrdId"`
-		InstrumentId string `json:"instId"`
-	}
-
-	var resp BizWarmTipsV5
-
-	contractType = ok.adaptContractType(currencyPair)
-
-	getOrderParam.OrderId = cid
-	getOrderParam.InstrumentId = contractType
-
-	//reqBody, _, _ := BuildRequestBody(getOrderParam)
-
-	err := ok.DoRequest("GET", fmt.Sprintf(GET_ORDER_BY_CID, cid, contractType), "", &resp)
-	if err != nil {
-		loging.Info("GetFutureOrderByCid", "err", err)
-		return nil, err
-	}
-
-	if resp.Message != "" {
-		loging.Info("GetFutureOrderByCid", "cid", cid, "resp.Message ", resp.Message)
-		return nil, errors.New(fmt.Sprintf("{\"ErrCode\":%d,\"ErrMessage\":\"%s\"}", resp.Code, resp.Message))
-	}
-
-	if len(resp.OrderInfo) == 0 {
-		return nil, fmt.Errorf("no order???")
-	}
-	order := resp.OrderInfo[0]
-	OType := order.Side + order.PositionSide
-	//mapping := map[string]string{
-	//	"buylong":   "openlong",
-	//	"sellshort": "openshort",
-	//	"selllong":  "closelong",
-	//	"buyshort":  "closeshort",
-	//}
-	mapping := map[string]int{
-		"buylong":   1,
-		"sellshort": 2,
-		"selllong":  3,
-		"buyshort":  4,
-	}
-	iTs, err := strconv.ParseInt(order.Timestamp, 10, 64)
-	orderPrice, _ := strconv.ParseFloat(order.Price, 64)
-	orderSize, _ := strconv.ParseFloat(order.Size, 64)
-	orderPriceAvg, _ := strconv.ParseFloat(order.PriceAvg, 64)
-	orderFilledQty, _ := strconv.ParseFloat(order.FilledQty, 64)
-	orderFee, _ := strconv.ParseFloat(order.Fee, 64)
-	orderProfit, _ := strconv.ParseFloat(order.Pnl, 64)
-	loging.Info("GetFutureOrder", "status", order.Status, "Status", ok.adaptOrderStateV5(order.Status))
-	return &FutureOrder{
-		ClientOid:    order.ClientOid,
-		Currency:     currencyPair,
-		ContractName: contractType,
-		OrderID2:     order.OrderId,
-		Amount:       orderSize,
-		Price:        orderPrice,
-		DealAmount:   orderFilledQty,
-		AvgPrice:     orderPriceAvg,
-		OType:        mapping[OType],
-		Fee:          orderFee,
-		Profit:       orderProfit,
-		Status:       ok.adaptOrderStateV5(order.Status),
-		OrderTime:    iTs,
-	}, nil
+	return ok.getFutureOrder(GET_ORDER_BY_CID, cid, currencyPair)
 }
 
 func (ok *OKExSwap) GetFutureOrder(orderId string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
-	var getOrderParam struct {
-		OrderId      string `json:"ordId"`
-		InstrumentId string `json:"instId"`
-	}
-	//fmt.Println(orderId)
+	return ok.getFutureOrder(GET_ORDER, orderId, currencyPair)
+}
 
+//uri为GET_ORDER或GET_ORDER_BY_CID
+func (ok *OKExSwap) getFutureOrder(uri, id string, currencyPair CurrencyPair) (*FutureOrder, error) {
 	var resp BizWarmTipsV5
 
-	contractType = ok.adaptContractType(currencyPair)
-
-	getOrderParam.OrderId = orderId
-	getOrderParam.InstrumentId = contractType
-
-	//reqBody, _, _ := BuildRequestBody(getOrderParam)
+	contractType := ok.adaptContractType(currencyPair)
 
-	err := ok.DoRequest("GET", fmt.Sprintf(GET_ORDER, orderId, contractType), "", &resp)
+	err := ok.DoRequest("GET", fmt.Sprintf(uri, id, contractType), "", &resp)
 	if err != nil {
-		loging.Info("GetFutureOrder", "err", err)
+		logger.Infof("[OKExSwap] get order %s err: %v", id, err)
 		return nil, err
 	}
 
 	if resp.Message != "" {
-		loging.Info("GetFutureOrder", "orderId", orderId, "resp.Message ", resp.Message)
-		return nil, errors.New(fmt.Sprintf("{\"ErrCode\":%d,\"ErrMessage\":\"%s\"}", resp.Code, resp.Message))
+		logger.Infof("[OKExSwap] get order %s: %s", id, resp.Message)
+		return nil, errors.New(fmt.Sprintf("{\"ErrCode\":%s,\"ErrMessage\":\"%s\"}", resp.Code, resp.Message))
 	}
 
 	if len(resp.OrderInfo) == 0 {
 		return nil, fmt.Errorf("no order???")
 	}
 	order := resp.OrderInfo[0]
-	OType := order.Side + order.PositionSide
-	//mapping := map[string]string{
-	//	"buylong":   "openlong",
-	//	"sellshort": "openshort",
-	//	"selllong":  "closelong",
-	//	"buyshort":  "closeshort",
-	//}
-	mapping := map[string]int{
-		"buylong":   1,
-		"sellshort": 2,
-		"selllong":  3,
-		"buyshort":  4,
-	}
-	iTs, err := strconv.ParseInt(order.Timestamp, 10, 64)
-	orderPrice, _ := strconv.ParseFloat(order.Price, 64)
-	orderSize, _ := strconv.ParseFloat(order.Size, 64)
-	orderPriceAvg, _ := strconv.ParseFloat(order.PriceAvg, 64)
-	orderFilledQty, _ := strconv.ParseFloat(order.FilledQty, 64)
-	orderFee, _ := strconv.ParseFloat(order.Fee, 64)
-	orderProfit, _ := strconv.ParseFloat(order.Pnl, 64)
-	loging.Info("GetFutureOrder", "status", order.Status, "Status", ok.adaptOrderStateV5(order.Status))
-	return &FutureOrder{
-		ClientOid:    order.ClientOid,
-		Currency:     currencyPair,
-		ContractName: contractType,
-		OrderID2:     order.OrderId,
-		Amount:       orderSize,
-		Price:        orderPrice,
-		DealAmount:   orderFilledQty,
-		AvgPrice:     orderPriceAvg,
-		OType:        mapping[OType],
-		Fee:          orderFee,
-		Profit:       orderProfit,
-		Status:       ok.adaptOrderStateV5(order.Status),
-		OrderTime:    iTs,
-	}, nil
+	fOrder := ok.parseOrder(order)
+	fOrder.Currency = currencyPair
+	fOrder.ContractName = contractType
+	fOrder.Profit, _ = strconv.ParseFloat(order.Pnl, 64)
+	return &fOrder, nil
 }
 
 func (ok *OKExSwap) GetFuturePosition(currencyPair CurrencyPair, contractType string) ([]FuturePosition, error) {
@@ -734,19 +699,29 @@ func (ok *OKExSwap) GetContractValue(currencyPair CurrencyPair) (float64, error)
 }
 
 func (ok *OKExSwap) GetFee() (float64, error) {
-	panic("not support")
+	return 0, EX_ERR_NOT_SUPPORTED
 }
 
+//永续合约没有预估交割价格，返回指数价格
 func (ok *OKExSwap) GetFutureEstimatedPrice(currencyPair CurrencyPair) (float64, error) {
-	panic("not support")
+	return ok.GetFutureIndex(currencyPair)
 }
 
 func (ok *OKExSwap) GetFutureIndex(currencyPair CurrencyPair) (float64, error) {
-	panic("not support")
+	var resp struct {
+		InstrumentId string  `json:"instrument_id"`
+		Index        float64 `json:"index,string"`
+	}
+	err := ok.DoRequest("GET", fmt.Sprintf("/api/swap/v3/instruments/%s/index", ok.adaptContractType(currencyPair)), "", &resp)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Index, nil
 }
 
+//永续合约没有交割时间
 func (ok *OKExSwap) GetDeliveryTime() (int, int, int, int) {
-	panic("not support")
+	return 0, 0, 0, 0
 }
 
 func (ok *OKExSwap) GetKlineRecords(contractType string, currency CurrencyPair, period KlinePeriod, size int, opt ...OptionalParameter) ([]FutureKline, error) {
@@ -837,11 +812,11 @@ func (ok *OKExSwap) GetKlineRecords2(contractType string, currency CurrencyPair,
 }
 
 func (ok *OKExSwap) GetTrades(contractType string, currencyPair CurrencyPair, since int64) ([]Trade, error) {
-	panic("not support")
+	return nil, EX_ERR_NOT_SUPPORTED
 }
 
 func (ok *OKExSwap) GetExchangeRate() (float64, error) {
-	panic("not support")
+	return 0, EX_ERR_NOT_SUPPORTED
 }
 
 func (ok *OKExSwap) GetHistoricalFunding(contractType string, currencyPair CurrencyPair, page int) ([]HistoricalFunding, error) {
@@ -899,6 +874,29 @@ func (ok *OKExSwap) GetInstruments() ([]Instrument, error) {
 	return resp, nil
 }
 
+func (ok *OKExSwap) GetMarkets() ([]Market, error) {
+	instruments, err := ok.GetInstruments()
+	if err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, 0, len(instruments))
+	for _, v := range instruments {
+		markets = append(markets, Market{
+			Pair:         NewCurrencyPair(NewCurrency(v.UnderlyingIndex, ""), NewCurrency(v.QuoteCurrency, "")),
+			ContractType: SWAP_CONTRACT,
+			Symbol:       v.InstrumentID,
+			TickSize:     NewDecimalFromFloat(v.TickSize),
+			LotSize:      NewDecimal(int64(v.SizeIncrement), 0),
+			MinAmount:    NewDecimal(int64(v.SizeIncrement), 0),
+			ContractVal:  NewDecimalFromFloat(v.ContractVal),
+			Inverse:      v.IsInverse,
+			Status:       MARKET_TRADING,
+		})
+	}
+	return markets, nil
+}
+
 type MarginLeverage struct {
 	LongLeverage  float64 `json:"long_leverage,string"`
 	MarginMode    string  `json:"margin_mode"`
@@ -8,10 +8,9 @@ import (
 	"strings"
 	"time"
 
-	"github.com/mrwill84/goex/okex/bst"
-
 	. "github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/orderbook"
 	cmap "github.com/orcaman/concurrent-map"
 )
 
@@ -22,12 +21,15 @@ type OKExV3SwapWs struct {
 	depthCallback  func(*Depth)
 	tradeCallback  func(*Trade)
 	klineCallback  func(*FutureKline, int)
+	symbolMapper   *SymbolMapper
 }
 
-type OkexSnapshot struct {
-	Asks bst.BSTree
-	Bids bst.BSTree
-}
+var (
+	_ SymbolMapperAware = (*OKExV3SwapWs)(nil)
+)
+
+//本地订单簿推送的深度档数
+const depthSize = 10
 
 var container cmap.ConcurrentMap = cmap.New()
 
@@ -39,6 +41,11 @@ func NewOKExV3SwapWs(base *OKEx) *OKExV3SwapWs {
 	return okV3Ws
 }
 
+//设置后使用交易规则把推送的instrument_id映射回交易对
+func (okV3Ws *OKExV3SwapWs) SetSymbolMapper(mapper *SymbolMapper) {
+	okV3Ws.symbolMapper = mapper
+}
+
 func (okV3Ws *OKExV3SwapWs) TickerCallback(tickerCallback func(*FutureTicker)) {
 	okV3Ws.tickerCallback = tickerCallback
 }
@@ -166,8 +173,7 @@ func (okV3Ws *OKExV3SwapWs) SubscribeKline(currencyPair CurrencyPair, contractTy
 
 func (okV3Ws *OKExV3SwapWs) getContractAliasAndCurrencyPairFromInstrumentId(instrumentId string) (alias string, pair CurrencyPair) {
 	if strings.HasSuffix(instrumentId, "SWAP") {
-		ar := strings.Split(instrumentId, "-")
-		return instrumentId, NewCurrencyPair2(fmt.Sprintf("%s_%s", ar[0], ar[1]))
+		return instrumentId, okV3Ws.symbolMapper.PairOf(instrumentId, adaptSwapInstrumentId)
 	} else {
 		contractInfo, err := okV3Ws.base.OKExFuture.GetContractInfo(instrumentId)
 		if err != nil {
@@ -180,30 +186,41 @@ func (okV3Ws *OKExV3SwapWs) getContractAliasAndCurrencyPairFromInstrumentId(inst
 	}
 }
 
-func AcceptSnap(snapshot *OkexSnapshot, depth *depthResponse) {
+//BTC-USD-SWAP -> BTC_USD
+func adaptSwapInstrumentId(instrumentId string) CurrencyPair {
+	ar := strings.Split(instrumentId, "-")
+	return NewCurrencyPair2(fmt.Sprintf("%s_%s", ar[0], ar[1]))
+}
 
-	for _, itm := range depth.Asks {
-		if itm[3] == "0" { //delete
-			snapshot.Asks.Delete(itm[0])
-		} else {
-			snapshot.Asks.Upsert(itm[0], &DepthRecord{
-				Price:  ToFloat64(itm[0]),
-				Amount: ToFloat64(itm[1]),
-				Slots:  ToInt64(itm[3]),
-			})
-		}
+func adaptDepthLevels(items [][4]string) []orderbook.Level {
+	levels := make([]orderbook.Level, 0, len(items))
+	for _, itm := range items {
+		levels = append(levels, orderbook.Level{
+			Price:  itm[0],
+			Amount: itm[1],
+			Slots:  ToInt64(itm[3]),
+		})
 	}
-	for _, itm := range depth.Bids {
-		if itm[3] == "0" { //delete
-			snapshot.Bids.Delete(itm[0])
-		} else {
-			snapshot.Bids.Upsert(itm[0], &DepthRecord{
-				Price:  ToFloat64(itm[0]),
-				Amount: ToFloat64(itm[1]),
-				Slots:  ToInt64(itm[3]),
-			})
-		}
+	return levels
+}
+
+//instId对应的本地订单簿，校验失败时重新订阅books频道获取全量快照
+func (okV3Ws *OKExV3SwapWs) getOrderBook(instId string) *orderbook.OrderBook {
+	if i, ok := container.Get(instId); ok {
+		return i.(*orderbook.OrderBook)
 	}
+
+	ob := orderbook.NewOrderBook(func() (*orderbook.Snapshot, error) {
+		args := []map[string]string{{"channel": "books", "instId": instId}}
+		err := okV3Ws.v3Ws.WsConn.SendJsonMessage(map[string]interface{}{"op": "unsubscribe", "args": args})
+		if err != nil {
+			return nil, err
+		}
+		return nil, okV3Ws.v3Ws.WsConn.SendJsonMessage(map[string]interface{}{"op": "subscribe", "args": args})
+	})
+	container.Set(instId, ob)
+
+	return ob
 }
 
 func (okV3Ws *OKExV3SwapWs) handle(resp *wsResp) error {
@@ -313,30 +330,38 @@ func (okV3Ws *OKExV3SwapWs) handle(resp *wsResp) error {
 		ts := ToInt64(depthResp[0].Timestamp)
 		dep.Timestamp = ts //time.Parse(time.RFC3339, depthResp[0].Timestamp)
 
-		i, ok := container.Get(instId)
-		var snapshot *OkexSnapshot
-		if ok {
-			if s, ok2 := i.(*OkexSnapshot); ok2 {
-				snapshot = s
+		ob := okV3Ws.getOrderBook(instId)
+		if resp.Action == "snapshot" {
+			err = ob.Snapshot(&orderbook.Snapshot{
+				Asks:     adaptDepthLevels(depthResp[0].Asks),
+				Bids:     adaptDepthLevels(depthResp[0].Bids),
+				UpdateId: depthResp[0].SeqId,
+				Checksum: depthResp[0].Checksum,
+			})
+			if err != nil {
+				err = ob.Resync()
 			}
 		} else {
-			snapshot = new(OkexSnapshot)
-			container.Set(instId, snapshot)
+			err = ob.Apply(&orderbook.Diff{
+				Asks:     adaptDepthLevels(depthResp[0].Asks),
+				Bids:     adaptDepthLevels(depthResp[0].Bids),
+				LastId:   depthResp[0].SeqId,
+				PrevId:   depthResp[0].PrevSeqId,
+				Checksum: depthResp[0].Checksum,
+			})
 		}
-
-		AcceptSnap(snapshot, &depthResp[0])
-
-		for i := range snapshot.Asks.Iter() {
-			r := i.Val.(*DepthRecord)
-			dep.AskList = append(dep.AskList, *r)
+		if err != nil {
+			logger.Errorf("[%s] sync order book error: %s", instId, err)
+			return err
 		}
 
-		for i := range snapshot.Bids.RIter() {
-			r := i.Val.(*DepthRecord)
-			dep.BidList = append(dep.BidList, *r)
+		if !ob.IsReady() {
+			return nil
 		}
-		dep.AskList = dep.AskList[:10]
-		dep.BidList = dep.BidList[:10]
+
+		book := ob.Depth(depthSize)
+		dep.AskList = book.AskList
+		dep.BidList = book.BidList
 		//call back func
 		okV3Ws.depthCallback(&dep)
 		return nil
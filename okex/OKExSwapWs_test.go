@@ -8,6 +8,7 @@ import (
 
 	"github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 func init() {
@@ -15,6 +16,7 @@ func init() {
 }
 
 func TestNewOKExV3SwapWs(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	//os.Setenv("HTTPS_PROXY", "socks5://127.0.0.1:1080")
 	ok := NewOKEx(&goex.APIConfig{
 		HttpClient: http.DefaultClient,
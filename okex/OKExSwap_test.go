@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 var config = &goex.APIConfig{
@@ -28,6 +29,7 @@ var config = &goex.APIConfig{
 var okExSwap = NewOKExSwap(config)
 
 func TestOKExSwap_GetFutureUserinfo(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okExSwap.GetFutureUserinfo())
 }
 
@@ -40,26 +42,32 @@ func TestOKExSwap_PlaceFutureOrder2(t *testing.T) {
 }
 
 func TestOKExSwap_FutureCancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okExSwap.FutureCancelOrder(goex.BTC_USDT, goex.SWAP_CONTRACT, "309935122485305344"))
 }
 
 func TestOKExSwap_GetFutureOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okExSwap.GetFutureOrder("581084124456583168", goex.BTC_USDT, goex.SWAP_CONTRACT))
 }
 
 func TestOKExSwap_GetFuturePosition(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okExSwap.GetFuturePosition(goex.BTC_USD, goex.SWAP_CONTRACT))
 }
 
 func TestOKExSwap_GetFutureDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okExSwap.GetFutureDepth(goex.LTC_USD, goex.SWAP_CONTRACT, 10))
 }
 
 func TestOKExSwap_GetFutureTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okExSwap.GetFutureTicker(goex.BTC_USD, goex.SWAP_CONTRACT))
 }
 
 func TestOKExSwap_GetUnfinishFutureOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ords, _ := okExSwap.GetUnfinishFutureOrders(goex.XRP_USD, goex.SWAP_CONTRACT)
 	for _, ord := range ords {
 		t.Log(ord.OrderID2, ord.ClientOid)
@@ -68,6 +76,7 @@ func TestOKExSwap_GetUnfinishFutureOrders(t *testing.T) {
 }
 
 func TestOKExSwap_GetHistoricalFunding(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	for i := 1; ; i++ {
 		funding, err := okExSwap.GetHistoricalFunding(goex.SWAP_CONTRACT, goex.BTC_USD, i)
 		t.Log(err, len(funding))
@@ -81,6 +90,7 @@ func TestOKExSwap_GetKlineRecords(t *testing.T) {
 }
 
 func TestOKExSwap_GetKlineRecords2(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	start := time.Now().Add(time.Minute * -30).UTC().Format(time.RFC3339)
 	t.Log(start)
 	kline, err := okExSwap.GetKlineRecords2(goex.SWAP_CONTRACT, goex.BTC_USDT, start, "", "900")
@@ -88,22 +98,27 @@ func TestOKExSwap_GetKlineRecords2(t *testing.T) {
 }
 
 func TestOKExSwap_GetInstruments(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okExSwap.GetInstruments())
 }
 
 func TestOKExSwap_SetMarginLevel(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okExSwap.SetMarginLevel(goex.EOS_USDT, 5, 3))
 }
 
 func TestOKExSwap_GetMarginLevel(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okExSwap.GetMarginLevel(goex.EOS_USDT))
 }
 
 func TestOKExSwap_GetFutureAccountInfo(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okExSwap.GetFutureAccountInfo(goex.BTC_USDT))
 }
 
 func TestOKExSwap_PlaceFutureAlgoOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	ord := &goex.FutureOrder{
 		ContractName: goex.SWAP_CONTRACT,
 		Currency:     goex.BTC_USD,
@@ -119,10 +134,12 @@ func TestOKExSwap_PlaceFutureAlgoOrder(t *testing.T) {
 }
 
 func TestOKExSwap_FutureCancelAlgoOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okExSwap.FutureCancelAlgoOrder(goex.BTC_USD, []string{"309935122485305344"}))
 
 }
 
 func TestOKExSwap_GetFutureAlgoOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okExSwap.GetFutureAlgoOrders("", "2", goex.BTC_USD))
 }
@@ -1,6 +1,7 @@
 package okex
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -26,7 +27,7 @@ type wsResp struct {
 type OKExV3Ws struct {
 	base *OKEx
 	*WsBuilder
-	once       *sync.Once
+	connLock   sync.Mutex
 	WsConn     *WsConn
 	respHandle func(*wsResp) error
 }
@@ -45,7 +46,6 @@ type OKExLogin struct {
 
 func NewOKExV3Ws(base *OKEx, handle func(*wsResp) error) *OKExV3Ws {
 	okV3Ws := &OKExV3Ws{
-		once:       new(sync.Once),
 		base:       base,
 		respHandle: handle,
 	}
@@ -97,10 +97,19 @@ func (okV3Ws *OKExV3Ws) getTablePrefix(currencyPair CurrencyPair, contractType s
 	return "futures"
 }
 
-func (okV3Ws *OKExV3Ws) ConnectWs() {
-	okV3Ws.once.Do(func() {
-		okV3Ws.WsConn = okV3Ws.WsBuilder.Build()
-	})
+//建立连接，已连接时直接返回；连接失败时返回错误，下次调用时重试
+func (okV3Ws *OKExV3Ws) ConnectWs() error {
+	okV3Ws.connLock.Lock()
+	defer okV3Ws.connLock.Unlock()
+	if okV3Ws.WsConn != nil {
+		return nil
+	}
+	c, err := okV3Ws.WsBuilder.BuildWithContext(context.Background())
+	if err != nil {
+		return err
+	}
+	okV3Ws.WsConn = c
+	return nil
 }
 
 func (okV3Ws *OKExV3Ws) parseChannel(channel string) (string, error) {
@@ -153,7 +162,23 @@ func (okV3Ws *OKExV3Ws) handle(msg []byte) error {
 }
 
 func (okV3Ws *OKExV3Ws) Subscribe(sub map[string]interface{}) error {
-	okV3Ws.ConnectWs()
+	if err := okV3Ws.ConnectWs(); err != nil {
+		return err
+	}
 	logger.Info("[ws] [response] ", sub)
 	return okV3Ws.WsConn.Subscribe(sub)
 }
+
+//sub为订阅时的消息，用于找到订阅，发送同样args的unsubscribe消息
+func (okV3Ws *OKExV3Ws) Unsubscribe(sub map[string]interface{}) error {
+	if okV3Ws.WsConn == nil {
+		return nil
+	}
+	key, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+	return okV3Ws.WsConn.Unsubscribe(string(key), map[string]interface{}{
+		"op":   "unsubscribe",
+		"args": sub["args"]})
+}
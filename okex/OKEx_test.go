@@ -6,6 +6,7 @@ import (
 
 	"github.com/mrwill84/goex"
 	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/internal/replay"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,10 +23,12 @@ var config2 = &goex.APIConfig{
 var okex = NewOKEx(config2) //线上请用APIBuilder构建
 
 func TestOKExSpot_GetAccount(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.GetAccount())
 }
 
 func TestOKExSpot_BatchPlaceOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExSpot.BatchPlaceOrders([]goex.Order{
 		goex.Order{
 			Cid:       okex.UUID(),
@@ -56,27 +59,33 @@ func TestOKExSpot_BatchPlaceOrders(t *testing.T) {
 }
 
 func TestOKExSpot_LimitBuy(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExSpot.LimitBuy("0.001", "9910", goex.BTC_USD))
 }
 
 func TestOKExSpot_CancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExSpot.CancelOrder("2a647e51435647708b1c840802bf70e5", goex.BTC_USD))
 
 }
 
 func TestOKExSpot_GetOneOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExSpot.GetOneOrder("5502594029936640", goex.BTC_USD))
 }
 
 func TestOKExSpot_GetUnfinishOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExSpot.GetUnfinishOrders(goex.EOS_BTC))
 }
 
 func TestOKExSpot_GetTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExSpot.GetTicker(goex.BTC_USD))
 }
 
 func TestOKExSpot_GetDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	dep, err := okex.OKExSpot.GetDepth(2, goex.EOS_BTC)
 	assert.Nil(t, err)
 	t.Log(dep.AskList)
@@ -84,6 +93,7 @@ func TestOKExSpot_GetDepth(t *testing.T) {
 }
 
 func TestOKExFuture_GetFutureTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExFuture.GetFutureTicker(goex.BTC_USD, "BTC-USD-190927"))
 	t.Log(okex.OKExFuture.GetFutureTicker(goex.BTC_USD, goex.QUARTER_CONTRACT))
 	t.Log(okex.OKExFuture.GetFutureDepth(goex.BTC_USD, goex.QUARTER_CONTRACT, 2))
@@ -93,18 +103,22 @@ func TestOKExFuture_GetFutureTicker(t *testing.T) {
 }
 
 func TestOKExFuture_GetFutureUserinfo(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExFuture.GetFutureUserinfo())
 }
 
 func TestOKExFuture_GetFuturePosition(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExFuture.GetFuturePosition(goex.EOS_USD, goex.QUARTER_CONTRACT))
 }
 
 func TestOKExFuture_PlaceFutureOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExFuture.PlaceFutureOrder(goex.EOS_USD, goex.THIS_WEEK_CONTRACT, "5.8", "1", goex.OPEN_BUY, 0, 10))
 }
 
 func TestOKExFuture_PlaceFutureOrder2(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExFuture.PlaceFutureOrder2(0, &goex.FutureOrder{
 		Currency:     goex.EOS_USD,
 		ContractName: goex.QUARTER_CONTRACT,
@@ -116,22 +130,27 @@ func TestOKExFuture_PlaceFutureOrder2(t *testing.T) {
 }
 
 func TestOKExFuture_FutureCancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExFuture.FutureCancelOrder(goex.EOS_USD, goex.QUARTER_CONTRACT, "e88bd3361de94512b8acaf9aa154f95a"))
 }
 
 func TestOKExFuture_GetFutureOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExFuture.GetFutureOrder("3145664744431616", goex.EOS_USD, goex.QUARTER_CONTRACT))
 }
 
 func TestOKExFuture_GetUnfinishFutureOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExFuture.GetUnfinishFutureOrders(goex.EOS_USD, goex.QUARTER_CONTRACT))
 }
 
 func TestOKExFuture_MarketCloseAllPosition(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExFuture.MarketCloseAllPosition(goex.BTC_USD, goex.THIS_WEEK_CONTRACT, goex.CLOSE_BUY))
 }
 
 func TestOKExFuture_GetRate(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExFuture.GetRate())
 }
 
@@ -145,6 +164,7 @@ func TestOKExFuture_GetKlineRecords(t *testing.T) {
 }
 
 func TestOKExWallet_GetAccount(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExWallet.GetAccount())
 }
 
@@ -169,14 +189,17 @@ func TestOKExWallet_GetAccount(t *testing.T) {
 //}
 
 func TestOKExWallet_GetDepositAddress(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExWallet.GetDepositAddress(goex.BTC))
 }
 
 func TestOKExWallet_GetWithDrawalFee(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExWallet.GetWithDrawalFee(nil))
 }
 
 func TestOKExWallet_GetDepositHistory(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExWallet.GetDepositHistory(&goex.BTC))
 }
 
@@ -185,10 +208,12 @@ func TestOKExWallet_GetWithDrawalHistory(t *testing.T) {
 }
 
 func TestOKExMargin_GetMarginAccount(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExMargin.GetMarginAccount(goex.EOS_USDT))
 }
 
 func TestOKExMargin_Borrow(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExMargin.Borrow(goex.BorrowParameter{
 		Currency:     goex.EOS,
 		CurrencyPair: goex.EOS_USDT,
@@ -197,6 +222,7 @@ func TestOKExMargin_Borrow(t *testing.T) {
 }
 
 func TestOKExMargin_Repayment(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExMargin.Repayment(goex.RepaymentParameter{
 		BorrowParameter: goex.BorrowParameter{
 			Currency:     goex.EOS,
@@ -206,6 +232,7 @@ func TestOKExMargin_Repayment(t *testing.T) {
 }
 
 func TestOKExMargin_PlaceOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExMargin.PlaceOrder(&goex.Order{
 		Currency:  goex.EOS_USDT,
 		Amount:    0.2,
@@ -217,22 +244,27 @@ func TestOKExMargin_PlaceOrder(t *testing.T) {
 }
 
 func TestOKExMargin_GetUnfinishOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExMargin.GetUnfinishOrders(goex.EOS_USDT))
 }
 
 func TestOKExMargin_CancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExMargin.CancelOrder("3174778420532224", goex.EOS_USDT))
 }
 
 func TestOKExMargin_GetOneOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExMargin.GetOneOrder("3174778420532224", goex.EOS_USDT))
 }
 
 func TestOKExSpot_GetCurrenciesPrecision(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	t.Log(okex.OKExSpot.GetCurrenciesPrecision())
 }
 
 func TestOKExSpot_GetOrderHistorys(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	orders, err := okex.OKExSpot.GetOrderHistorys(goex.NewCurrencyPair2("DASH_USDT"))
 	if err != nil {
 		t.Log(err)
@@ -1,3 +1,8 @@
+// Package bst provides a string keyed binary search tree.
+//
+// Deprecated: the okex ws adapters keep their order books in
+// github.com/mrwill84/goex/orderbook now; this package is kept for
+// existing importers and will not receive new features.
 package bst
 
 import (
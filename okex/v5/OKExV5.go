@@ -2,6 +2,7 @@ package okex
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -50,6 +51,12 @@ func (ok *OKExV5) SetCustomCID(f func() string) {
 	ok.customCIDFunc = f
 }
 
+func (ok *OKExV5) withContext(ctx context.Context) *OKExV5 {
+	config := *ok.config
+	config.HttpClient = HttpClientWithContext(ctx, ok.config.HttpClient)
+	return &OKExV5{config: &config, customCIDFunc: ok.customCIDFunc}
+}
+
 //获取所有产品行情信息
 //产品类型instType
 // SPOT：币币
@@ -144,7 +151,7 @@ func (ok *OKExV5) GetDepthV5(instId string, size int) (*DepthV5, error) {
 }
 
 func (ok *OKExV5) GetKlineRecordsV5(instId string, period KlinePeriod, params *url.Values) ([][]string, error) {
-	urlPath := fmt.Sprintf("%s/api/v5/market/candles?instId=%s&bar=%s", ok.config.Endpoint, instId, ok.adaptKLineBar(period))
+	urlPath := fmt.Sprintf("%s/api/v5/market/candles?instId=%s&bar=%s", ok.config.Endpoint, instId, adaptKLineBar(period))
 
 	if params.Encode() != "" {
 		urlPath = fmt.Sprintf("%s&%s", urlPath, params.Encode())
@@ -203,10 +210,15 @@ func (ok *OKExV5) BuildRequestBody(params interface{}) (string, *bytes.Reader, e
 
 func (ok *OKExV5) doParamSign(httpMethod, uri, requestBody string) (string, string) {
 	timestamp := IsoTime()
+	return ok.doParamSignWithTimestamp(timestamp, httpMethod, uri, requestBody), timestamp
+}
+
+//rest接口使用iso时间，ws登录使用unix秒时间戳
+func (ok *OKExV5) doParamSignWithTimestamp(timestamp, httpMethod, uri, requestBody string) string {
 	preText := fmt.Sprintf("%s%s%s%s", timestamp, strings.ToUpper(httpMethod), uri, requestBody)
 	//log.Println("preHash", preText)
 	sign, _ := GetParamHmacSHA256Base64Sign(ok.config.ApiSecretKey, preText)
-	return sign, timestamp
+	return sign
 }
 
 func (ok *OKExV5) DoAuthorRequest(httpMethod, uri, reqBody string, response interface{}) error {
@@ -223,7 +235,7 @@ func (ok *OKExV5) DoAuthorRequest(httpMethod, uri, reqBody string, response inte
 		OK_ACCESS_TIMESTAMP:  fmt.Sprint(timestamp)})
 	if err != nil {
 		//log.Println(err)
-		return err
+		return adaptError(err)
 	} else {
 		logger.Log.Debug(string(resp))
 		return json.Unmarshal(resp, &response)
@@ -258,23 +270,20 @@ type OrderSummaryV5 struct {
 	SMsg        string `json:"sMsg"`
 }
 
-func (ok *OKExV5) CreateOrder(param *CreateOrderParam) (*OrderSummaryV5, error) {
-
+func (param *CreateOrderParam) requestBody() map[string]interface{} {
 	reqBody := make(map[string]interface{})
 
 	reqBody["instId"] = param.Symbol
 	reqBody["tdMode"] = param.TradeMode
 	reqBody["side"] = param.Side
 	reqBody["ordType"] = param.OrderType
-	reqBody["sz"] = param.Size
+	reqBody["sz"] = DecimalString(param.Size)
 
 	if param.CCY != "" {
 		reqBody["ccy"] = param.CCY
 	}
 	if param.ClientOrdId != "" {
 		reqBody["clOrdId"] = param.ClientOrdId
-	} else {
-		reqBody["clOrdId"] = param.ClientOrdId
 	}
 	if param.Tag != "" {
 		reqBody["tag"] = param.Tag
@@ -283,12 +292,22 @@ func (ok *OKExV5) CreateOrder(param *CreateOrderParam) (*OrderSummaryV5, error)
 		reqBody["posSide"] = param.PosSide
 	}
 	if param.Price != "" {
-		reqBody["px"] = param.Price
+		reqBody["px"] = DecimalString(param.Price)
 	}
 	if param.ReduceOnly != false {
 		reqBody["reduceOnly"] = param.ReduceOnly
 	}
 
+	return reqBody
+}
+
+func (ok *OKExV5) CreateOrder(param *CreateOrderParam) (*OrderSummaryV5, error) {
+
+	if param.ClientOrdId == "" && ok.customCIDFunc != nil {
+		param.ClientOrdId = ok.customCIDFunc()
+	}
+	reqBody := param.requestBody()
+
 	type OrderResponse struct {
 		Code int              `json:"code,string"`
 		Msg  string           `json:"msg"`
@@ -305,15 +324,7 @@ func (ok *OKExV5) CreateOrder(param *CreateOrderParam) (*OrderSummaryV5, error)
 	}
 
 	if response.Code != 0 {
-		msg := response.Msg
-		if msg == "" {
-			if len(response.Data) > 0 {
-				msg = fmt.Sprintf("code:%d, scode:%s, smsg:%s", response.Code, response.Data[0].SCode, response.Data[0].SMsg)
-			} else {
-				msg = fmt.Sprintf("code:%d", response.Code)
-			}
-		}
-		return nil, fmt.Errorf("CreateOrder error:%s", msg)
+		return nil, adaptOrderError(response.Code, response.Msg, response.Data)
 	}
 	return &response.Data[0], nil
 }
@@ -346,19 +357,99 @@ func (ok *OKExV5) CancelOrderV5(instId, ordId, clOrdId string) (*OrderSummaryV5,
 	}
 
 	if response.Code != 0 {
-		msg := response.Msg
-		if msg == "" {
-			if len(response.Data) > 0 {
-				msg = fmt.Sprintf("code:%d, scode:%s, smsg:%s", response.Code, response.Data[0].SCode, response.Data[0].SMsg)
-			} else {
-				msg = fmt.Sprintf("code:%d", response.Code)
-			}
-		}
-		return nil, fmt.Errorf("CancelOrderV5 error:%s", msg)
+		return nil, adaptOrderError(response.Code, response.Msg, response.Data)
+	}
+	return &response.Data[0], nil
+}
+
+//修改未完成订单的价格或数量，newSz为修改后的总数量(包含已成交部分)，为空表示不修改
+func (ok *OKExV5) AmendOrderV5(instId, ordId, clOrdId, newSz, newPx string) (*OrderSummaryV5, error) {
+
+	reqBody := make(map[string]interface{})
+
+	reqBody["instId"] = instId
+	if ordId != "" {
+		reqBody["ordId"] = ordId
+	}
+	if clOrdId != "" {
+		reqBody["clOrdId"] = clOrdId
+	}
+	if newSz != "" {
+		reqBody["newSz"] = DecimalString(newSz)
+	}
+	if newPx != "" {
+		reqBody["newPx"] = DecimalString(newPx)
+	}
+
+	type OrderResponse struct {
+		Code int              `json:"code,string"`
+		Msg  string           `json:"msg"`
+		Data []OrderSummaryV5 `json:"data"`
+	}
+	var response OrderResponse
+
+	uri := "/api/v5/trade/amend-order"
+
+	jsonStr, _, _ := ok.BuildRequestBody(reqBody)
+	err := ok.DoAuthorRequest(http.MethodPost, uri, jsonStr, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Code != 0 {
+		return nil, adaptOrderError(response.Code, response.Msg, response.Data)
 	}
 	return &response.Data[0], nil
 }
 
+//批量下单，每次最多20个订单，返回结果与params一一对应，单个订单是否成功看sCode
+func (ok *OKExV5) BatchCreateOrders(params []*CreateOrderParam) ([]OrderSummaryV5, error) {
+	reqBody := make([]map[string]interface{}, 0, len(params))
+	for _, param := range params {
+		reqBody = append(reqBody, param.requestBody())
+	}
+	return ok.batchOrderRequest("/api/v5/trade/batch-orders", reqBody)
+}
+
+//批量撤单，每次最多20个订单
+func (ok *OKExV5) BatchCancelOrdersV5(instId string, ordIds []string) ([]OrderSummaryV5, error) {
+	reqBody := make([]map[string]interface{}, 0, len(ordIds))
+	for _, ordId := range ordIds {
+		reqBody = append(reqBody, map[string]interface{}{"instId": instId, "ordId": ordId})
+	}
+	return ok.batchOrderRequest("/api/v5/trade/cancel-batch-orders", reqBody)
+}
+
+func (ok *OKExV5) batchOrderRequest(uri string, reqBody []map[string]interface{}) ([]OrderSummaryV5, error) {
+	type OrderResponse struct {
+		Code int              `json:"code,string"`
+		Msg  string           `json:"msg"`
+		Data []OrderSummaryV5 `json:"data"`
+	}
+	var response OrderResponse
+
+	jsonStr, _, _ := ok.BuildRequestBody(reqBody)
+	err := ok.DoAuthorRequest(http.MethodPost, uri, jsonStr, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	//code为1(全部失败)或2(部分成功)时，每个订单的结果在sCode , sMsg
+	if response.Code != 0 && len(response.Data) != len(reqBody) {
+		return nil, adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
+	}
+	return response.Data, nil
+}
+
+//单个订单的批量下单/撤单结果
+func (summary *OrderSummaryV5) batchResult() BatchOrderResult {
+	r := BatchOrderResult{OrderId: summary.OrdId, Cid: summary.ClientOrdId}
+	if summary.SCode != "" && summary.SCode != "0" {
+		r.Err = adaptErrorCode(summary.SCode, summary.SMsg)
+	}
+	return r
+}
+
 type PendingOrderParam struct {
 	InstType string
 	Uly      string
@@ -389,14 +480,14 @@ type OrderV5 struct {
 	OrdType     string  `json:"ordType"`
 	Pnl         string  `json:"pnl"`
 	PosSide     string  `json:"posSide"`
-	Px          float64 `json:"px,string"`
+	Px          Decimal `json:"px"`
 	Rebate      string  `json:"rebate"`
 	RebateCcy   string  `json:"rebateCcy"`
 	Side        string  `json:"side"`
 	SlOrdPx     string  `json:"slOrdPx"`
 	SlTriggerPx string  `json:"slTriggerPx"`
 	State       string  `json:"state"`
-	Sz          float64 `json:"sz,string"`
+	Sz          Decimal `json:"sz"`
 	Tag         string  `json:"tag"`
 	TdMode      string  `json:"tdMode"`
 	TpOrdPx     string  `json:"tpOrdPx"`
@@ -405,6 +496,10 @@ type OrderV5 struct {
 	UTime       int64   `json:"uTime,string"`
 }
 
+func (o OrderV5) decimals() OrderDecimals {
+	return OrderDecimals{Price: o.Px, Amount: o.Sz, AvgPrice: ToDecimal(o.AvgPx), DealAmount: ToDecimal(o.AccFillSz)}
+}
+
 func (ok *OKExV5) GetPendingOrders(param *PendingOrderParam) ([]OrderV5, error) {
 
 	reqBody := make(map[string]string)
@@ -457,7 +552,7 @@ func (ok *OKExV5) GetPendingOrders(param *PendingOrderParam) ([]OrderV5, error)
 	}
 
 	if response.Code != 0 {
-		return nil, fmt.Errorf("GetPendingOrders error:%s", response.Msg)
+		return nil, adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
 	}
 	return response.Data, nil
 }
@@ -497,7 +592,7 @@ func (ok *OKExV5) GetOrderV5(instId, ordId, clOrdId string) (*OrderV5, error) {
 	}
 
 	if response.Code != 0 {
-		return nil, fmt.Errorf("GetOrderV5 error:%s", response.Msg)
+		return nil, adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
 	}
 	return &response.Data[0], nil
 }
@@ -547,7 +642,7 @@ func (ok *OKExV5) GetOrderHistory(instType, instId, ordType, state, afterID, bef
 	}
 
 	if response.Code != 0 {
-		return nil, fmt.Errorf("GetOrderV5 error:%s", response.Msg)
+		return nil, adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
 	}
 	return response.Data, nil
 }
@@ -585,7 +680,7 @@ func (ok *OKExV5) GetAssetBalances(currency string) ([]AssetSummary, error) {
 	}
 
 	if response.Code != 0 {
-		return nil, fmt.Errorf("GetAssetBalances error:%s", response.Msg)
+		return nil, adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
 	}
 	return response.Data, nil
 }
@@ -646,12 +741,575 @@ func (ok *OKExV5) GetAccountBalances(currency string) (*BalanceV5, error) {
 	}
 
 	if response.Code != 0 {
-		return nil, fmt.Errorf("GetAccountBalances error:%s", response.Msg)
+		return nil, adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
 	}
 	return &response.Data[0], nil
 }
 
-func (ok *OKExV5) adaptKLineBar(period KlinePeriod) string {
+type PositionV5 struct {
+	InstId      string `json:"instId"`
+	InstType    string `json:"instType"`
+	MgnMode     string `json:"mgnMode"` //cross/isolated
+	PosSide     string `json:"posSide"` //long/short/net
+	Pos         string `json:"pos"`     //持仓数量，net模式下为负数表示空仓
+	AvailPos    string `json:"availPos"`
+	AvgPx       string `json:"avgPx"`
+	Upl         string `json:"upl"`
+	UplRatio    string `json:"uplRatio"`
+	RealizedPnl string `json:"realizedPnl"`
+	Lever       string `json:"lever"`
+	LiqPx       string `json:"liqPx"`
+	Margin      string `json:"margin"`
+	MgnRatio    string `json:"mgnRatio"`
+	Ccy         string `json:"ccy"`
+	CTime       int64  `json:"cTime,string"`
+	UTime       int64  `json:"uTime,string"`
+}
+
+//instType: MARGIN/SWAP/FUTURES/OPTION，为空查询全部
+func (ok *OKExV5) GetPositionsV5(instType, instId string) ([]PositionV5, error) {
+	params := url.Values{}
+	if instType != "" {
+		params.Set("instType", instType)
+	}
+	if instId != "" {
+		params.Set("instId", instId)
+	}
+
+	path := "/api/v5/account/positions"
+	if len(params) > 0 {
+		path = fmt.Sprintf("%s?%s", path, params.Encode())
+	}
+
+	type PositionResponse struct {
+		Code int          `json:"code,string"`
+		Msg  string       `json:"msg"`
+		Data []PositionV5 `json:"data"`
+	}
+	var response PositionResponse
+
+	err := ok.DoAuthorRequest(http.MethodGet, path, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Code != 0 {
+		return nil, adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
+	}
+	return response.Data, nil
+}
+
+type LeverageV5 struct {
+	InstId  string `json:"instId"`
+	MgnMode string `json:"mgnMode"`
+	PosSide string `json:"posSide"`
+	Lever   string `json:"lever"`
+}
+
+//posSide仅在逐仓且双向持仓模式下必填(long/short)
+func (ok *OKExV5) SetLeverageV5(instId, lever, mgnMode, posSide string) ([]LeverageV5, error) {
+	reqBody := make(map[string]interface{})
+	reqBody["instId"] = instId
+	reqBody["lever"] = lever
+	reqBody["mgnMode"] = mgnMode
+	if posSide != "" {
+		reqBody["posSide"] = posSide
+	}
+
+	type LeverageResponse struct {
+		Code int          `json:"code,string"`
+		Msg  string       `json:"msg"`
+		Data []LeverageV5 `json:"data"`
+	}
+	var response LeverageResponse
+
+	jsonStr, _, _ := ok.BuildRequestBody(reqBody)
+	err := ok.DoAuthorRequest(http.MethodPost, "/api/v5/account/set-leverage", jsonStr, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Code != 0 {
+		return nil, adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
+	}
+	return response.Data, nil
+}
+
+func (ok *OKExV5) GetLeverageV5(instId, mgnMode string) ([]LeverageV5, error) {
+	path := fmt.Sprintf("/api/v5/account/leverage-info?instId=%s&mgnMode=%s", instId, mgnMode)
+
+	type LeverageResponse struct {
+		Code int          `json:"code,string"`
+		Msg  string       `json:"msg"`
+		Data []LeverageV5 `json:"data"`
+	}
+	var response LeverageResponse
+
+	err := ok.DoAuthorRequest(http.MethodGet, path, "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Code != 0 {
+		return nil, adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
+	}
+	return response.Data, nil
+}
+
+//posMode: long_short_mode 双向持仓 , net_mode 单向持仓
+func (ok *OKExV5) SetPositionModeV5(posMode string) error {
+	jsonStr, _, _ := ok.BuildRequestBody(map[string]string{"posMode": posMode})
+
+	var response struct {
+		Code int    `json:"code,string"`
+		Msg  string `json:"msg"`
+	}
+	err := ok.DoAuthorRequest(http.MethodPost, "/api/v5/account/set-position-mode", jsonStr, &response)
+	if err != nil {
+		return err
+	}
+
+	if response.Code != 0 {
+		return adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
+	}
+	return nil
+}
+
+//调整逐仓保证金，posSide: long/short/net，marginType: add/reduce
+func (ok *OKExV5) ModifyMarginBalanceV5(instId, posSide, marginType, amt string) error {
+	jsonStr, _, _ := ok.BuildRequestBody(map[string]string{
+		"instId":  instId,
+		"posSide": posSide,
+		"type":    marginType,
+		"amt":     amt,
+	})
+
+	var response struct {
+		Code int    `json:"code,string"`
+		Msg  string `json:"msg"`
+	}
+	err := ok.DoAuthorRequest(http.MethodPost, "/api/v5/account/position/margin-balance", jsonStr, &response)
+	if err != nil {
+		return err
+	}
+
+	if response.Code != 0 {
+		return adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
+	}
+	return nil
+}
+
+type AlgoOrderV5 struct {
+	AlgoId        string `json:"algoId"`
+	AlgoClOrdId   string `json:"algoClOrdId"`
+	InstId        string `json:"instId"`
+	OrdType       string `json:"ordType"` //conditional:单向止盈止损 , oco:双向止盈止损 , trigger:计划委托 , move_order_stop:移动止盈止损
+	Side          string `json:"side"`
+	PosSide       string `json:"posSide"`
+	TdMode        string `json:"tdMode"`
+	Sz            string `json:"sz"`
+	ReduceOnly    string `json:"reduceOnly"`
+	CloseFraction string `json:"closeFraction"`
+	TpTriggerPx   string `json:"tpTriggerPx"`
+	TpOrdPx       string `json:"tpOrdPx"` //-1表示市价
+	SlTriggerPx   string `json:"slTriggerPx"`
+	SlOrdPx       string `json:"slOrdPx"` //-1表示市价
+	CallbackRatio string `json:"callbackRatio"`
+	ActivePx      string `json:"activePx"`
+	State         string `json:"state"` //live/pause/effective/canceled/order_failed/partially_failed
+	OrdId         string `json:"ordId"` //触发后生成的订单ID
+	CTime         int64  `json:"cTime,string"`
+}
+
+//策略委托下单，reqBody参考/api/v5/trade/order-algo的请求参数
+func (ok *OKExV5) PlaceAlgoOrderV5(reqBody map[string]interface{}) (algoId string, err error) {
+	var response struct {
+		Code int    `json:"code,string"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			AlgoId string `json:"algoId"`
+			SCode  string `json:"sCode"`
+			SMsg   string `json:"sMsg"`
+		} `json:"data"`
+	}
+
+	jsonStr, _, _ := ok.BuildRequestBody(reqBody)
+	err = ok.DoAuthorRequest(http.MethodPost, "/api/v5/trade/order-algo", jsonStr, &response)
+	if err != nil {
+		return "", err
+	}
+
+	if len(response.Data) > 0 && response.Data[0].SCode != "" && response.Data[0].SCode != "0" {
+		return "", adaptErrorCode(response.Data[0].SCode, response.Data[0].SMsg)
+	}
+	if response.Code != 0 || len(response.Data) == 0 {
+		return "", adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
+	}
+	return response.Data[0].AlgoId, nil
+}
+
+func (ok *OKExV5) CancelAlgoOrdersV5(instId string, algoIds []string) error {
+	reqBody := make([]map[string]string, 0, len(algoIds))
+	for _, algoId := range algoIds {
+		reqBody = append(reqBody, map[string]string{"instId": instId, "algoId": algoId})
+	}
+
+	var response struct {
+		Code int    `json:"code,string"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			AlgoId string `json:"algoId"`
+			SCode  string `json:"sCode"`
+			SMsg   string `json:"sMsg"`
+		} `json:"data"`
+	}
+
+	jsonStr, _, _ := ok.BuildRequestBody(reqBody)
+	err := ok.DoAuthorRequest(http.MethodPost, "/api/v5/trade/cancel-algos", jsonStr, &response)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range response.Data {
+		if d.SCode != "" && d.SCode != "0" {
+			return adaptErrorCode(d.SCode, d.SMsg)
+		}
+	}
+	if response.Code != 0 {
+		return adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
+	}
+	return nil
+}
+
+//未完成的策略委托单，ordType必填
+func (ok *OKExV5) GetAlgoOrdersPendingV5(ordType, instType, instId string) ([]AlgoOrderV5, error) {
+	params := url.Values{}
+	params.Set("ordType", ordType)
+	if instType != "" {
+		params.Set("instType", instType)
+	}
+	if instId != "" {
+		params.Set("instId", instId)
+	}
+
+	var response struct {
+		Code int           `json:"code,string"`
+		Msg  string        `json:"msg"`
+		Data []AlgoOrderV5 `json:"data"`
+	}
+	err := ok.DoAuthorRequest(http.MethodGet, "/api/v5/trade/orders-algo-pending?"+params.Encode(), "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Code != 0 {
+		return nil, adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
+	}
+	return response.Data, nil
+}
+
+type TradeV5 struct {
+	InstId  string  `json:"instId"`
+	TradeId string  `json:"tradeId"`
+	Px      float64 `json:"px,string"`
+	Sz      float64 `json:"sz,string"`
+	Side    string  `json:"side"`
+	Ts      int64   `json:"ts,string"`
+}
+
+//最新成交，limit最大500
+func (ok *OKExV5) GetTradesV5(instId string, limit int) ([]TradeV5, error) {
+	urlPath := fmt.Sprintf("%s/api/v5/market/trades?instId=%s", ok.config.Endpoint, instId)
+	if limit > 0 {
+		urlPath = fmt.Sprintf("%s&limit=%d", urlPath, limit)
+	}
+
+	type TradeResponse struct {
+		Code int       `json:"code,string"`
+		Msg  string    `json:"msg"`
+		Data []TradeV5 `json:"data"`
+	}
+	var response TradeResponse
+	err := HttpGet4(ok.config.HttpClient, urlPath, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Code != 0 {
+		return nil, fmt.Errorf("GetTradesV5 error:%s", response.Msg)
+	}
+	return response.Data, nil
+}
+
+type InstrumentV5 struct {
+	InstId   string  `json:"instId"`
+	InstType string  `json:"instType"`
+	Uly      string  `json:"uly"`
+	BaseCcy  string  `json:"baseCcy"`      //现货
+	QuoteCcy string  `json:"quoteCcy"`     //现货
+	CtVal    float64 `json:"ctVal,string"` //合约面值
+	CtValCcy string  `json:"ctValCcy"`
+	CtType   string  `json:"ctType"` //linear/inverse
+	TickSz   string  `json:"tickSz"`
+	LotSz    string  `json:"lotSz"`
+	MinSz    string  `json:"minSz"`
+	Alias    string  `json:"alias"` //this_week/next_week/quarter/next_quarter
+	ExpTime  string  `json:"expTime"`
+	State    string  `json:"state"`
+}
+
+//instType: SPOT/MARGIN/SWAP/FUTURES/OPTION
+func (ok *OKExV5) GetInstrumentsV5(instType, uly, instId string) ([]InstrumentV5, error) {
+	params := url.Values{}
+	params.Set("instType", instType)
+	if uly != "" {
+		params.Set("uly", uly)
+	}
+	if instId != "" {
+		params.Set("instId", instId)
+	}
+	urlPath := fmt.Sprintf("%s/api/v5/public/instruments?%s", ok.config.Endpoint, params.Encode())
+
+	type InstrumentResponse struct {
+		Code int            `json:"code,string"`
+		Msg  string         `json:"msg"`
+		Data []InstrumentV5 `json:"data"`
+	}
+	var response InstrumentResponse
+	err := HttpGet4(ok.config.HttpClient, urlPath, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Code != 0 {
+		return nil, fmt.Errorf("GetInstrumentsV5 error:%s", response.Msg)
+	}
+	return response.Data, nil
+}
+
+//instType: SPOT/SWAP/FUTURES , 交割合约按alias映射合约类型(this_week/next_week/quarter , next_quarter为bi_quarter)
+func (ok *OKExV5) GetMarketsV5(instType string) ([]Market, error) {
+	instruments, err := ok.GetInstrumentsV5(instType, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	markets := make([]Market, 0, len(instruments))
+	for _, inst := range instruments {
+		m := Market{
+			Symbol:    inst.InstId,
+			TickSize:  ToDecimal(inst.TickSz),
+			LotSize:   ToDecimal(inst.LotSz),
+			MinAmount: ToDecimal(inst.MinSz),
+			Status:    MARKET_SUSPEND,
+		}
+
+		switch instType {
+		case "SPOT":
+			m.Pair = NewCurrencyPair(NewCurrency(inst.BaseCcy, ""), NewCurrency(inst.QuoteCcy, ""))
+		case "SWAP":
+			m.Pair = NewCurrencyPair3(inst.Uly, "-")
+			m.ContractType = SWAP_CONTRACT
+		case "FUTURES":
+			m.Pair = NewCurrencyPair3(inst.Uly, "-")
+			m.ContractType = inst.Alias
+			if inst.Alias == "next_quarter" {
+				m.ContractType = BI_QUARTER_CONTRACT
+			}
+		}
+		if instType != "SPOT" {
+			m.ContractVal = NewDecimalFromFloat(inst.CtVal)
+			m.Inverse = inst.CtType == "inverse"
+		}
+
+		switch inst.State {
+		case "live":
+			m.Status = MARKET_TRADING
+		case "expired":
+			m.Status = MARKET_OFFLINE
+		}
+		markets = append(markets, m)
+	}
+	return markets, nil
+}
+
+//指数行情，instId如BTC-USD , BTC-USDT
+func (ok *OKExV5) GetIndexPriceV5(instId string) (float64, error) {
+	urlPath := fmt.Sprintf("%s/api/v5/market/index-tickers?instId=%s", ok.config.Endpoint, instId)
+
+	type IndexTickerResponse struct {
+		Code int    `json:"code,string"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			IdxPx float64 `json:"idxPx,string"`
+		} `json:"data"`
+	}
+	var response IndexTickerResponse
+	err := HttpGet4(ok.config.HttpClient, urlPath, nil, &response)
+	if err != nil {
+		return 0, err
+	}
+
+	if response.Code != 0 || len(response.Data) == 0 {
+		return 0, fmt.Errorf("GetIndexPriceV5 error:%s", response.Msg)
+	}
+	return response.Data[0].IdxPx, nil
+}
+
+//交割和期权合约的预估交割/行权价格，永续合约没有
+func (ok *OKExV5) GetEstimatedPriceV5(instId string) (float64, error) {
+	urlPath := fmt.Sprintf("%s/api/v5/public/estimated-price?instId=%s", ok.config.Endpoint, instId)
+
+	type EstimatedPriceResponse struct {
+		Code int    `json:"code,string"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			SettlePx float64 `json:"settlePx,string"`
+		} `json:"data"`
+	}
+	var response EstimatedPriceResponse
+	err := HttpGet4(ok.config.HttpClient, urlPath, nil, &response)
+	if err != nil {
+		return 0, err
+	}
+
+	if response.Code != 0 || len(response.Data) == 0 {
+		return 0, fmt.Errorf("GetEstimatedPriceV5 error:%s", response.Msg)
+	}
+	return response.Data[0].SettlePx, nil
+}
+
+//当前账户的交易手续费率，费率为负数表示收取手续费
+func (ok *OKExV5) GetTradeFeeV5(instType, instId string) (maker, taker float64, err error) {
+	path := fmt.Sprintf("/api/v5/account/trade-fee?instType=%s", instType)
+	if instId != "" {
+		path = fmt.Sprintf("%s&instId=%s", path, instId)
+	}
+
+	var response struct {
+		Code int    `json:"code,string"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Maker float64 `json:"maker,string"`
+			Taker float64 `json:"taker,string"`
+		} `json:"data"`
+	}
+	err = ok.DoAuthorRequest(http.MethodGet, path, "", &response)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if response.Code != 0 || len(response.Data) == 0 {
+		return 0, 0, adaptErrorCode(fmt.Sprint(response.Code), response.Msg)
+	}
+	return response.Data[0].Maker, response.Data[0].Taker, nil
+}
+
+type FundingRateV5 struct {
+	InstId          string  `json:"instId"`
+	FundingRate     float64 `json:"fundingRate,string"`
+	NextFundingRate string  `json:"nextFundingRate"` //可能为空
+	FundingTime     int64   `json:"fundingTime,string"`
+	NextFundingTime int64   `json:"nextFundingTime,string"`
+	RealizedRate    string  `json:"realizedRate"` //仅历史资金费率
+}
+
+//当前资金费率，只适用于永续合约
+func (ok *OKExV5) GetFundingRateV5(instId string) (*FundingRateV5, error) {
+	urlPath := fmt.Sprintf("%s/api/v5/public/funding-rate?instId=%s", ok.config.Endpoint, instId)
+
+	type FundingRateResponse struct {
+		Code int             `json:"code,string"`
+		Msg  string          `json:"msg"`
+		Data []FundingRateV5 `json:"data"`
+	}
+	var response FundingRateResponse
+	err := HttpGet4(ok.config.HttpClient, urlPath, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Code != 0 || len(response.Data) == 0 {
+		return nil, fmt.Errorf("GetFundingRateV5 error:%s", response.Msg)
+	}
+	return &response.Data[0], nil
+}
+
+//历史资金费率，按时间降序；params支持before/after(fundingTime分页)和limit(最大100)
+func (ok *OKExV5) GetFundingRateHistoryV5(instId string, params *url.Values) ([]FundingRateV5, error) {
+	urlPath := fmt.Sprintf("%s/api/v5/public/funding-rate-history?instId=%s", ok.config.Endpoint, instId)
+	if params.Encode() != "" {
+		urlPath = fmt.Sprintf("%s&%s", urlPath, params.Encode())
+	}
+
+	type FundingRateResponse struct {
+		Code int             `json:"code,string"`
+		Msg  string          `json:"msg"`
+		Data []FundingRateV5 `json:"data"`
+	}
+	var response FundingRateResponse
+	err := HttpGet4(ok.config.HttpClient, urlPath, nil, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Code != 0 {
+		return nil, fmt.Errorf("GetFundingRateHistoryV5 error:%s", response.Msg)
+	}
+	return response.Data, nil
+}
+
+//标记价格，instType: MARGIN/SWAP/FUTURES/OPTION
+func (ok *OKExV5) GetMarkPriceV5(instType, instId string) (markPx float64, ts int64, err error) {
+	urlPath := fmt.Sprintf("%s/api/v5/public/mark-price?instType=%s&instId=%s", ok.config.Endpoint, instType, instId)
+
+	type MarkPriceResponse struct {
+		Code int    `json:"code,string"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			MarkPx float64 `json:"markPx,string"`
+			Ts     int64   `json:"ts,string"`
+		} `json:"data"`
+	}
+	var response MarkPriceResponse
+	err = HttpGet4(ok.config.HttpClient, urlPath, nil, &response)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if response.Code != 0 || len(response.Data) == 0 {
+		return 0, 0, fmt.Errorf("GetMarkPriceV5 error:%s", response.Msg)
+	}
+	return response.Data[0].MarkPx, response.Data[0].Ts, nil
+}
+
+//持仓总量，oi单位为张，oiCcy单位为币
+func (ok *OKExV5) GetOpenInterestV5(instType, instId string) (oi, oiCcy float64, ts int64, err error) {
+	urlPath := fmt.Sprintf("%s/api/v5/public/open-interest?instType=%s&instId=%s", ok.config.Endpoint, instType, instId)
+
+	type OpenInterestResponse struct {
+		Code int    `json:"code,string"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Oi    float64 `json:"oi,string"`
+			OiCcy float64 `json:"oiCcy,string"`
+			Ts    int64   `json:"ts,string"`
+		} `json:"data"`
+	}
+	var response OpenInterestResponse
+	err = HttpGet4(ok.config.HttpClient, urlPath, nil, &response)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	if response.Code != 0 || len(response.Data) == 0 {
+		return 0, 0, 0, fmt.Errorf("GetOpenInterestV5 error:%s", response.Msg)
+	}
+	d := response.Data[0]
+	return d.Oi, d.OiCcy, d.Ts, nil
+}
+
+func adaptKLineBar(period KlinePeriod) string {
 	bar := "1D"
 	switch period {
 	case KLINE_PERIOD_1MIN:
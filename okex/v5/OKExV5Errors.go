@@ -0,0 +1,61 @@
+package okex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	. "github.com/mrwill84/goex"
+)
+
+//https://www.okex.com/docs-v5/en/#error-code
+var errorCodeTable = ErrorCodeTable{
+	"50001": EX_ERR_MAINTENANCE,          //Service temporarily unavailable
+	"50011": EX_ERR_API_LIMIT,            //Requests too frequent
+	"50061": EX_ERR_API_LIMIT,            //Sub-account rate limit exceeded
+	"50100": EX_ERR_AUTH_FAIL,            //API frozen
+	"50101": EX_ERR_AUTH_FAIL,            //APIKey does not match current environment
+	"50103": EX_ERR_AUTH_FAIL,            //OK-ACCESS-KEY can not be empty
+	"50104": EX_ERR_AUTH_FAIL,            //OK-ACCESS-PASSPHRASE can not be empty
+	"50105": EX_ERR_AUTH_FAIL,            //OK-ACCESS-PASSPHRASE incorrect
+	"50111": EX_ERR_AUTH_FAIL,            //Invalid OK-ACCESS-KEY
+	"50102": EX_ERR_INVALID_TIMESTAMP,    //Timestamp request expired
+	"50112": EX_ERR_INVALID_TIMESTAMP,    //Invalid OK-ACCESS-TIMESTAMP
+	"50113": EX_ERR_SIGN,                 //Invalid signature
+	"51000": EX_ERR_INVALID_ORDER_PARAM,  //Parameter error
+	"51001": EX_ERR_SYMBOL_ERR,           //Instrument ID does not exist
+	"51008": EX_ERR_INSUFFICIENT_BALANCE, //Order placement failed due to insufficient balance
+	"51016": EX_ERR_DUPLICATE_CLIENT_OID, //Duplicated clOrdId
+	"51400": EX_ERR_CANCEL_ORDER_FAIL,    //Cancellation failed
+	"51603": EX_ERR_NOT_FIND_ORDER,       //Order does not exist
+}
+
+func adaptErrorCode(code, msg string) error {
+	return errorCodeTable.Wrap(code, msg)
+}
+
+//http错误响应 {"code":"50011","msg":"..."} 转换为ApiError , 其它错误原样返回
+func adaptError(err error) error {
+	var httpErr *HttpError
+	if !errors.As(err, &httpErr) {
+		return err
+	}
+
+	var resp struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if e := json.Unmarshal([]byte(httpErr.Body), &resp); e != nil || resp.Code == "" {
+		return err
+	}
+
+	return errorCodeTable.WrapHttpError(httpErr, resp.Code, resp.Msg)
+}
+
+//批量/下单接口的code为1时，具体原因在sCode , sMsg
+func adaptOrderError(code int, msg string, data []OrderSummaryV5) error {
+	if len(data) > 0 && data[0].SCode != "" && data[0].SCode != "0" {
+		return adaptErrorCode(data[0].SCode, data[0].SMsg)
+	}
+	return adaptErrorCode(fmt.Sprint(code), msg)
+}
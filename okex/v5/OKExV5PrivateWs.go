@@ -0,0 +1,654 @@
+package okex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/logger"
+)
+
+const (
+	v5WsPrivateUrl = v5WsBaseUrl + "/private"
+	wsLoginTimeout = 10 * time.Second
+	wsOrderTimeout = 10 * time.Second
+)
+
+type wsV5Req struct {
+	Op   string        `json:"op"`
+	Args []interface{} `json:"args"`
+}
+
+type wsV5Resp struct {
+	Id     string            `json:"id"` //ws下单请求的id
+	Op     string            `json:"op"`
+	Event  string            `json:"event"`
+	Code   string            `json:"code"`
+	Msg    string            `json:"msg"`
+	Arg    map[string]string `json:"arg"`
+	Action string            `json:"action"` //books频道: snapshot , update
+	Data   json.RawMessage   `json:"data"`
+}
+
+//订单推送中市价单的px为空字符串，这里覆盖为字符串字段再转换
+type wsOrderV5 struct {
+	OrderV5
+	Px  string `json:"px"`
+	Sz  string `json:"sz"`
+	Fee string `json:"fee"`
+}
+
+//v5 私有频道的ws连接，连接(包括重连)成功后自动登录
+type privateWsV5 struct {
+	*OKExV5
+	connLock  sync.Mutex
+	wsBuilder *WsBuilder
+	c         *WsConn
+	loginCh   chan error
+	isLogin   int32 //登录成功后置1，读goroutine和调用方并发访问
+
+	reqId    int64
+	reqLock  sync.Mutex
+	requests map[string]chan *wsV5Resp //ws下单请求id -> 响应
+
+	dataHandle func(channel string, data json.RawMessage) error
+
+	symbolMapper *SymbolMapper
+}
+
+//设置后使用交易规则把推送的instId映射回交易对
+func (ws *privateWsV5) SetSymbolMapper(mapper *SymbolMapper) {
+	ws.symbolMapper = mapper
+}
+
+func newPrivateWsV5(config *APIConfig, dataHandle func(channel string, data json.RawMessage) error) *privateWsV5 {
+	ws := &privateWsV5{
+		OKExV5:     NewOKExV5(config),
+		loginCh:    make(chan error, 1),
+		requests:   make(map[string]chan *wsV5Resp),
+		dataHandle: dataHandle,
+	}
+	ws.wsBuilder = NewWsBuilder().
+		WsUrl(v5WsPrivateUrl).
+		ProxyUrl(os.Getenv("HTTPS_PROXY")).
+		AutoReconnect().
+		Heartbeat(func() []byte { return []byte("ping") }, 25*time.Second).
+		ConnectSuccessAfterSendMessage(ws.loginMessage).
+		ProtoHandleFunc(ws.handle)
+	return ws
+}
+
+func (ws *privateWsV5) loginMessage() []byte {
+	timestamp := fmt.Sprint(time.Now().Unix())
+	sign := ws.doParamSignWithTimestamp(timestamp, "GET", "/users/self/verify", "")
+	data, _ := json.Marshal(wsV5Req{
+		Op: "login",
+		Args: []interface{}{map[string]string{
+			"apiKey":     ws.config.ApiKey,
+			"passphrase": ws.config.ApiPassphrase,
+			"timestamp":  timestamp,
+			"sign":       sign,
+		}},
+	})
+	return data
+}
+
+//建立连接，连接成功后发送登录消息；连接失败时返回错误，下次Login时重试
+func (ws *privateWsV5) connect() error {
+	ws.connLock.Lock()
+	defer ws.connLock.Unlock()
+	if ws.c != nil {
+		return nil
+	}
+	c, err := ws.wsBuilder.BuildWithContext(context.Background())
+	if err != nil {
+		return err
+	}
+	ws.c = c
+	return nil
+}
+
+func (ws *privateWsV5) Login() error {
+	if ws.config.ApiKey == "" || ws.config.ApiSecretKey == "" {
+		return EX_ERR_NOT_FIND_APIKEY
+	}
+
+	if atomic.LoadInt32(&ws.isLogin) == 1 {
+		return nil
+	}
+
+	if err := ws.connect(); err != nil {
+		return err
+	}
+
+	select {
+	case err := <-ws.loginCh:
+		if err == nil {
+			atomic.StoreInt32(&ws.isLogin, 1)
+		}
+		return err
+	case <-time.After(wsLoginTimeout):
+		return errors.New("[okex] ws login timeout")
+	}
+}
+
+func (ws *privateWsV5) subscribe(args ...map[string]string) error {
+	if ws.c == nil {
+		return errors.New("[okex] please login first")
+	}
+
+	req := wsV5Req{Op: "subscribe"}
+	for _, arg := range args {
+		req.Args = append(req.Args, arg)
+	}
+
+	return ws.c.Subscribe(req)
+}
+
+func (ws *privateWsV5) handle(msg []byte) error {
+	if string(msg) == "pong" {
+		return nil
+	}
+
+	var resp wsV5Resp
+	err := json.Unmarshal(msg, &resp)
+	if err != nil {
+		logger.Errorf("[okex] json unmarshal ws response error [%s] , response data = %s", err, string(msg))
+		return err
+	}
+
+	switch resp.Event {
+	case "login":
+		var err error
+		if resp.Code != "0" {
+			err = fmt.Errorf("[okex] ws login error , code=%s , msg=%s", resp.Code, resp.Msg)
+		}
+		select {
+		case ws.loginCh <- err:
+		default:
+		}
+		return nil
+	case "error":
+		logger.Errorf("[okex] ws error , code=%s , msg=%s", resp.Code, resp.Msg)
+		return nil
+	case "subscribe":
+		logger.Debugf("[okex] subscribe success: %v", resp.Arg)
+		return nil
+	}
+
+	if resp.Id != "" {
+		ws.reqLock.Lock()
+		ch, ok := ws.requests[resp.Id]
+		delete(ws.requests, resp.Id)
+		ws.reqLock.Unlock()
+		if ok {
+			ch <- &resp
+		}
+		return nil
+	}
+
+	if len(resp.Data) == 0 {
+		logger.Warn("[okex] unknown ws response:", string(msg))
+		return nil
+	}
+
+	return ws.dataHandle(resp.Arg["channel"], resp.Data)
+}
+
+//发送交易请求(order , cancel-order , amend-order ...)并等待响应，需先Login
+func (ws *privateWsV5) request(op string, args ...interface{}) (*wsV5Resp, error) {
+	if ws.c == nil || atomic.LoadInt32(&ws.isLogin) != 1 {
+		return nil, errors.New("[okex] please login first")
+	}
+
+	id := fmt.Sprint(atomic.AddInt64(&ws.reqId, 1))
+	ch := make(chan *wsV5Resp, 1)
+	ws.reqLock.Lock()
+	ws.requests[id] = ch
+	ws.reqLock.Unlock()
+
+	err := ws.c.SendJsonMessage(map[string]interface{}{"id": id, "op": op, "args": args})
+	if err != nil {
+		ws.reqLock.Lock()
+		delete(ws.requests, id)
+		ws.reqLock.Unlock()
+		return nil, err
+	}
+
+	select {
+	case resp := <-ch:
+		return resp, nil
+	case <-time.After(wsOrderTimeout):
+		ws.reqLock.Lock()
+		delete(ws.requests, id)
+		ws.reqLock.Unlock()
+		return nil, fmt.Errorf("[okex] ws %s timeout", op)
+	}
+}
+
+func (ws *privateWsV5) orderRequest(op string, reqBody map[string]interface{}) (*OrderSummaryV5, error) {
+	resp, err := ws.request(op, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []OrderSummaryV5
+	if len(resp.Data) > 0 {
+		if err = json.Unmarshal(resp.Data, &data); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.Code != "0" {
+		return nil, adaptOrderError(ToInt(resp.Code), resp.Msg, data)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("[okex] ws %s empty response", op)
+	}
+	return &data[0], nil
+}
+
+//通过ws下单，参数与rest下单一致
+func (ws *privateWsV5) CreateOrderWs(param *CreateOrderParam) (*OrderSummaryV5, error) {
+	if param.ClientOrdId == "" && ws.customCIDFunc != nil {
+		param.ClientOrdId = ws.customCIDFunc()
+	}
+	return ws.orderRequest("order", param.requestBody())
+}
+
+//通过ws撤单，ordId和clOrdId二选一
+func (ws *privateWsV5) CancelOrderWs(instId, ordId, clOrdId string) (*OrderSummaryV5, error) {
+	reqBody := map[string]interface{}{"instId": instId}
+	if ordId != "" {
+		reqBody["ordId"] = ordId
+	}
+	if clOrdId != "" {
+		reqBody["clOrdId"] = clOrdId
+	}
+	return ws.orderRequest("cancel-order", reqBody)
+}
+
+func (ws *privateWsV5) parseOrders(data json.RawMessage) ([]OrderV5, error) {
+	var wsOrders []wsOrderV5
+	err := json.Unmarshal(data, &wsOrders)
+	if err != nil {
+		logger.Errorf("[okex] unmarshal orders error [%s] , data = %s", err, string(data))
+		return nil, err
+	}
+
+	orders := make([]OrderV5, 0, len(wsOrders))
+	for _, o := range wsOrders {
+		o.OrderV5.Px = ToDecimal(o.Px)
+		o.OrderV5.Sz = ToDecimal(o.Sz)
+		o.OrderV5.Fee = ToFloat64(o.Fee)
+		orders = append(orders, o.OrderV5)
+	}
+
+	return orders, nil
+}
+
+func adaptOrderStateV5(state string) TradeStatus {
+	switch state {
+	case "canceled":
+		return ORDER_CANCEL
+	case "partially_filled":
+		return ORDER_PART_FINISH
+	case "filled":
+		return ORDER_FINISH
+	default:
+		return ORDER_UNFINISH
+	}
+}
+
+//现货私有频道: 订单 / 账户
+type OKExV5SpotPrivateWs struct {
+	*privateWsV5
+
+	orderCallFn   func(order *Order)
+	accountCallFn func(account *Account)
+}
+
+var (
+	_ SpotPrivateWsApi  = (*OKExV5SpotPrivateWs)(nil)
+	_ SymbolMapperAware = (*OKExV5SpotPrivateWs)(nil)
+)
+
+func NewOKExV5SpotPrivateWs(config *APIConfig) *OKExV5SpotPrivateWs {
+	ws := &OKExV5SpotPrivateWs{}
+	ws.privateWsV5 = newPrivateWsV5(config, ws.handleData)
+	return ws
+}
+
+func (ws *OKExV5SpotPrivateWs) OrderCallback(f func(order *Order)) {
+	ws.orderCallFn = f
+}
+
+func (ws *OKExV5SpotPrivateWs) AccountCallback(f func(account *Account)) {
+	ws.accountCallFn = f
+}
+
+func (ws *OKExV5SpotPrivateWs) SubscribeOrder(pair CurrencyPair) error {
+	if ws.orderCallFn == nil {
+		return errors.New("please set order callback func")
+	}
+	return ws.subscribe(map[string]string{
+		"channel":  "orders",
+		"instType": "SPOT",
+		"instId":   pair.ToSymbol("-"),
+	})
+}
+
+//订阅交易对两个币种的余额推送
+func (ws *OKExV5SpotPrivateWs) SubscribeAccount(pair CurrencyPair) error {
+	if ws.accountCallFn == nil {
+		return errors.New("please set account callback func")
+	}
+	return ws.subscribe(
+		map[string]string{"channel": "account", "ccy": pair.CurrencyA.Symbol},
+		map[string]string{"channel": "account", "ccy": pair.CurrencyB.Symbol})
+}
+
+func (ws *OKExV5SpotPrivateWs) handleData(channel string, data json.RawMessage) error {
+	switch channel {
+	case "orders":
+		orders, err := ws.parseOrders(data)
+		if err != nil {
+			return err
+		}
+		for _, o := range orders {
+			side := BUY
+			if o.Side == "sell" {
+				side = SELL
+			}
+			ws.orderCallFn(&Order{
+				Price:        o.Px.Float64(),
+				Amount:       o.Sz.Float64(),
+				Decimals:     o.decimals(),
+				AvgPrice:     ToFloat64(o.AvgPx),
+				DealAmount:   ToFloat64(o.AccFillSz),
+				Fee:          o.Fee,
+				Cid:          o.ClOrdID,
+				OrderID2:     o.OrdID,
+				Status:       adaptOrderStateV5(o.State),
+				Currency:     ws.symbolMapper.PairOf(o.InstID, adaptSpotInstId),
+				Side:         side,
+				Type:         o.OrdType,
+				OrderTime:    o.CTime,
+				FinishedTime: o.UTime,
+			})
+		}
+	case "account":
+		var balances []BalanceV5
+		err := json.Unmarshal(data, &balances)
+		if err != nil {
+			return err
+		}
+		for _, b := range balances {
+			account := &Account{
+				Exchange:    OKEX,
+				SubAccounts: make(map[Currency]SubAccount, 2)}
+			for _, itm := range b.Details {
+				currency := NewCurrency(itm.Currency, "")
+				account.SubAccounts[currency] = SubAccount{
+					Currency:     currency,
+					ForzenAmount: ToFloat64(itm.Frozen),
+					Amount:       math.Max(ToFloat64(itm.Available), ToFloat64(itm.AvailEq)),
+				}
+			}
+			ws.accountCallFn(account)
+		}
+	default:
+		logger.Warnf("[okex] unknown channel %s , data = %s", channel, string(data))
+	}
+	return nil
+}
+
+//永续合约私有频道: 订单 / 持仓 / 账户
+type OKExV5SwapPrivateWs struct {
+	*privateWsV5
+
+	orderCallFn    func(order *FutureOrder)
+	positionCallFn func(position *FuturePosition)
+	accountCallFn  func(account *FutureAccount)
+}
+
+var (
+	_ FuturesPrivateWsApi = (*OKExV5SwapPrivateWs)(nil)
+	_ SymbolMapperAware   = (*OKExV5SwapPrivateWs)(nil)
+)
+
+func NewOKExV5SwapPrivateWs(config *APIConfig) *OKExV5SwapPrivateWs {
+	ws := &OKExV5SwapPrivateWs{}
+	ws.privateWsV5 = newPrivateWsV5(config, ws.handleData)
+	return ws
+}
+
+func (ws *OKExV5SwapPrivateWs) OrderCallback(f func(order *FutureOrder)) {
+	ws.orderCallFn = f
+}
+
+func (ws *OKExV5SwapPrivateWs) PositionCallback(f func(position *FuturePosition)) {
+	ws.positionCallFn = f
+}
+
+func (ws *OKExV5SwapPrivateWs) AccountCallback(f func(account *FutureAccount)) {
+	ws.accountCallFn = f
+}
+
+func (ws *OKExV5SwapPrivateWs) SubscribeOrder(pair CurrencyPair, contractType string) error {
+	if ws.orderCallFn == nil {
+		return errors.New("please set order callback func")
+	}
+	return ws.subscribe(map[string]string{
+		"channel":  "orders",
+		"instType": "SWAP",
+		"instId":   fmt.Sprintf("%s-SWAP", pair.ToSymbol("-")),
+	})
+}
+
+func (ws *OKExV5SwapPrivateWs) SubscribePosition(pair CurrencyPair, contractType string) error {
+	if ws.positionCallFn == nil {
+		return errors.New("please set position callback func")
+	}
+	return ws.subscribe(map[string]string{
+		"channel":  "positions",
+		"instType": "SWAP",
+		"instId":   fmt.Sprintf("%s-SWAP", pair.ToSymbol("-")),
+	})
+}
+
+//usdt本位订阅usdt余额，币本位订阅币的余额
+func (ws *OKExV5SwapPrivateWs) SubscribeAccount(pair CurrencyPair) error {
+	if ws.accountCallFn == nil {
+		return errors.New("please set account callback func")
+	}
+
+	ccy := pair.CurrencyA.Symbol
+	if pair.CurrencyB == USDT {
+		ccy = pair.CurrencyB.Symbol
+	}
+
+	return ws.subscribe(map[string]string{"channel": "account", "ccy": ccy})
+}
+
+//账户余额和持仓同时推送，需要设置账户和持仓回调
+func (ws *OKExV5SwapPrivateWs) SubscribeBalanceAndPosition() error {
+	if ws.accountCallFn == nil || ws.positionCallFn == nil {
+		return errors.New("please set account and position callback func")
+	}
+	return ws.subscribe(map[string]string{"channel": "balance_and_position"})
+}
+
+func (ws *OKExV5SwapPrivateWs) handleData(channel string, data json.RawMessage) error {
+	switch channel {
+	case "balance_and_position":
+		var events []struct {
+			EventType string `json:"eventType"`
+			BalData   []struct {
+				Ccy     string `json:"ccy"`
+				CashBal string `json:"cashBal"`
+			} `json:"balData"`
+			PosData []PositionV5 `json:"posData"`
+		}
+		err := json.Unmarshal(data, &events)
+		if err != nil {
+			return err
+		}
+		for _, e := range events {
+			if len(e.BalData) > 0 {
+				account := &FutureAccount{FutureSubAccounts: make(map[Currency]FutureSubAccount, len(e.BalData))}
+				for _, itm := range e.BalData {
+					currency := NewCurrency(itm.Ccy, "")
+					account.FutureSubAccounts[currency] = FutureSubAccount{
+						Currency:      currency,
+						AccountRights: ToFloat64(itm.CashBal),
+					}
+				}
+				ws.accountCallFn(account)
+			}
+			for _, p := range e.PosData {
+				if p.InstType != "SWAP" {
+					continue
+				}
+				ws.positionCallFn(ws.adaptPosition(p))
+			}
+		}
+	case "orders":
+		orders, err := ws.parseOrders(data)
+		if err != nil {
+			return err
+		}
+		for _, o := range orders {
+			ord := adaptFutureOrderV5(o)
+			ord.Currency = ws.symbolMapper.PairOf(o.InstID, adaptSwapInstId)
+			ws.orderCallFn(ord)
+		}
+	case "positions":
+		var positions []PositionV5
+		err := json.Unmarshal(data, &positions)
+		if err != nil {
+			return err
+		}
+		for _, p := range positions {
+			ws.positionCallFn(ws.adaptPosition(p))
+		}
+	case "account":
+		var balances []BalanceV5
+		err := json.Unmarshal(data, &balances)
+		if err != nil {
+			return err
+		}
+		for _, b := range balances {
+			account := &FutureAccount{FutureSubAccounts: make(map[Currency]FutureSubAccount, 2)}
+			for _, itm := range b.Details {
+				currency := NewCurrency(itm.Currency, "")
+				account.FutureSubAccounts[currency] = FutureSubAccount{
+					Currency:      currency,
+					AccountRights: ToFloat64(itm.Eq),
+					KeepDeposit:   ToFloat64(itm.Frozen),
+					ProfitUnreal:  ToFloat64(itm.Upl),
+					RiskRate:      ToFloat64(itm.MgnRatio),
+				}
+			}
+			ws.accountCallFn(account)
+		}
+	default:
+		logger.Warnf("[okex] unknown channel %s , data = %s", channel, string(data))
+	}
+	return nil
+}
+
+func (ws *OKExV5SwapPrivateWs) adaptPosition(p PositionV5) *FuturePosition {
+	pos := adaptFuturePositionV5(p)
+	pos.Symbol = ws.symbolMapper.PairOf(p.InstId, adaptSwapInstId)
+	return pos
+}
+
+//BTC-USDT -> BTC_USDT
+func adaptSpotInstId(instId string) CurrencyPair {
+	return NewCurrencyPair3(instId, "-")
+}
+
+//BTC-USDT-SWAP -> BTC_USDT
+func adaptSwapInstId(instId string) CurrencyPair {
+	return NewCurrencyPair3(strings.TrimSuffix(instId, "-SWAP"), "-")
+}
+
+func adaptFutureOrderV5(o OrderV5) *FutureOrder {
+	oType := OPEN_BUY
+	switch {
+	case o.Side == "buy" && o.PosSide == "short":
+		oType = CLOSE_SELL
+	case o.Side == "sell" && o.PosSide == "long":
+		oType = CLOSE_BUY
+	case o.Side == "sell":
+		oType = OPEN_SELL
+	}
+
+	orderType := ORDER_FEATURE_ORDINARY
+	switch o.OrdType {
+	case "post_only":
+		orderType = ORDER_FEATURE_POST_ONLY
+	case "fok":
+		orderType = ORDER_FEATURE_FOK
+	case "ioc":
+		orderType = ORDER_FEATURE_IOC
+	}
+
+	return &FutureOrder{
+		ClientOid:    o.ClOrdID,
+		OrderID2:     o.OrdID,
+		Price:        o.Px.Float64(),
+		Amount:       o.Sz.Float64(),
+		Decimals:     o.decimals(),
+		AvgPrice:     ToFloat64(o.AvgPx),
+		DealAmount:   ToFloat64(o.AccFillSz),
+		OrderTime:    int64(o.CTime),
+		Status:       adaptOrderStateV5(o.State),
+		Currency:     adaptSwapInstId(o.InstID),
+		OrderType:    orderType,
+		OType:        oType,
+		LeverRate:    ToFloat64(o.Lever),
+		Fee:          o.Fee,
+		Profit:       ToFloat64(o.Pnl),
+		ContractName: o.InstID,
+		FinishedTime: o.UTime,
+	}
+}
+
+func adaptFuturePositionV5(p PositionV5) *FuturePosition {
+	pos := &FuturePosition{
+		Symbol:         adaptSwapInstId(p.InstId),
+		ContractType:   SWAP_CONTRACT,
+		CreateDate:     p.CTime,
+		LeverRate:      ToFloat64(p.Lever),
+		ForceLiquPrice: ToFloat64(p.LiqPx),
+	}
+
+	amount := ToFloat64(p.Pos)
+	if p.PosSide == "long" || (p.PosSide == "net" && amount > 0) {
+		pos.BuyAmount = amount
+		pos.BuyAvailable = ToFloat64(p.AvailPos)
+		pos.BuyPriceAvg = ToFloat64(p.AvgPx)
+		pos.BuyProfit = ToFloat64(p.Upl)
+		pos.BuyProfitReal = ToFloat64(p.RealizedPnl)
+		pos.LongPnlRatio = ToFloat64(p.UplRatio)
+	} else {
+		pos.SellAmount = math.Abs(amount)
+		pos.SellAvailable = math.Abs(ToFloat64(p.AvailPos))
+		pos.SellPriceAvg = ToFloat64(p.AvgPx)
+		pos.SellProfit = ToFloat64(p.Upl)
+		pos.SellProfitReal = ToFloat64(p.RealizedPnl)
+		pos.ShortPnlRatio = ToFloat64(p.UplRatio)
+	}
+
+	return pos
+}
@@ -0,0 +1,29 @@
+package okex
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/mrwill84/goex"
+)
+
+const v5RestHost = "www.okex.com"
+
+//okex v5按接口限频(ip或userId) , https://www.okex.com/docs-v5/en/#rest-api-rate-limit
+//规则按Host匹配，与其它交易所的规则合并使用时不会互相影响
+var RateLimitRules = []RateLimitRule{
+	{Host: v5RestHost, Path: "/api/v5/trade/order", Method: http.MethodPost, Limit: 60, Interval: 2 * time.Second},
+	{Host: v5RestHost, Path: "/api/v5/trade/order", Method: http.MethodGet, Limit: 60, Interval: 2 * time.Second},
+	{Host: v5RestHost, Path: "/api/v5/trade/cancel-order", Limit: 60, Interval: 2 * time.Second},
+	{Host: v5RestHost, Path: "/api/v5/trade/amend-order", Limit: 60, Interval: 2 * time.Second},
+	{Host: v5RestHost, Path: "/api/v5/trade/batch-orders", Limit: 300, Interval: 2 * time.Second},
+	{Host: v5RestHost, Path: "/api/v5/trade/orders-pending", Limit: 60, Interval: 2 * time.Second},
+	{Host: v5RestHost, Path: "/api/v5/trade/orders-history", Limit: 40, Interval: 2 * time.Second},
+	{Host: v5RestHost, Path: "/api/v5/account/balance", Limit: 10, Interval: 2 * time.Second},
+	{Host: v5RestHost, Path: "/api/v5/account/positions", Limit: 10, Interval: 2 * time.Second},
+	{Host: v5RestHost, Path: "/api/v5/asset/balances", Limit: 6, Interval: time.Second},
+	{Host: v5RestHost, Path: "/api/v5/market/ticker", Limit: 20, Interval: 2 * time.Second},
+	{Host: v5RestHost, Path: "/api/v5/market/books", Limit: 40, Interval: 2 * time.Second},
+	{Host: v5RestHost, Path: "/api/v5/market/candles", Limit: 40, Interval: 2 * time.Second},
+	{Host: v5RestHost, Path: "/api/v5/public/instruments", Limit: 20, Interval: 2 * time.Second},
+}
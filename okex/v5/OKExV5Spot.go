@@ -1,6 +1,7 @@
 package okex
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"net/url"
@@ -13,6 +14,15 @@ type OKExV5Spot struct {
 	*OKExV5
 }
 
+var (
+	_ API              = (*OKExV5Spot)(nil)
+	_ ContextBinder    = (*OKExV5Spot)(nil)
+	_ BatchOrderAPI    = (*OKExV5Spot)(nil)
+	_ AmendOrderAPI    = (*OKExV5Spot)(nil)
+	_ ClientOrderIdAPI = (*OKExV5Spot)(nil)
+	_ MarketInfo       = (*OKExV5Spot)(nil)
+)
+
 func NewOKExV5Spot(config *APIConfig) *OKExV5Spot {
 	if config.Endpoint == "" {
 		config.Endpoint = v5RestBaseUrl
@@ -21,65 +31,79 @@ func NewOKExV5Spot(config *APIConfig) *OKExV5Spot {
 	return okex
 }
 
+func (ok *OKExV5Spot) WithContext(ctx context.Context) API {
+	return &OKExV5Spot{OKExV5: ok.withContext(ctx)}
+}
+
 // private API
-func (ok *OKExV5Spot) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+func (ok *OKExV5Spot) limitOrder(side, amount, price string, currency CurrencyPair, opt []LimitOrderOptionalParameter) (*Order, error) {
 	ty := "limit"
-	if len(opt) > 0 {
-		ty = opt[0].String()
+	if tif, isOk := GetTimeInForce(opt...); isOk {
+		ty = tif.String()
 	}
 
 	response, err := ok.CreateOrder(&CreateOrderParam{
-		Symbol:    currency.ToSymbol("-"),
-		TradeMode: "cash",
-		Side:      "buy",
-		OrderType: ty,
-		Size:      amount,
-		Price:     price,
+		Symbol:      currency.ToSymbol("-"),
+		TradeMode:   "cash",
+		Side:        side,
+		OrderType:   ty,
+		Size:        amount,
+		Price:       price,
+		ClientOrdId: GetClientOrderId(opt...),
 	})
 	if err != nil {
 		return nil, err
 	}
+
+	tradeSide := BUY
+	if side == "sell" {
+		tradeSide = SELL
+	}
 	return &Order{
 		Currency: currency,
 		Price:    ToFloat64(price),
 		Amount:   ToFloat64(amount),
 		Cid:      response.ClientOrdId,
 		OrderID2: response.OrdId,
+		Side:     tradeSide,
+		Decimals: OrderDecimals{Price: ToDecimal(price), Amount: ToDecimal(amount)},
 	}, nil
+}
 
+func (ok *OKExV5Spot) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	return ok.limitOrder("buy", amount, price, currency, opt)
 }
+
 func (ok *OKExV5Spot) LimitSell(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
-	ty := "limit"
-	if len(opt) > 0 {
-		ty = opt[0].String()
-	}
+	return ok.limitOrder("sell", amount, price, currency, opt)
+}
+
+func (ok *OKExV5Spot) MarketBuy(amount, price string, currency CurrencyPair) (*Order, error) {
 
 	response, err := ok.CreateOrder(&CreateOrderParam{
 		Symbol:    currency.ToSymbol("-"),
 		TradeMode: "cash",
-		Side:      "sell",
-		OrderType: ty,
+		Side:      "buy",
+		OrderType: "market",
 		Size:      amount,
-		Price:     price,
 	})
 	if err != nil {
 		return nil, err
 	}
 	return &Order{
 		Currency: currency,
-		Price:    ToFloat64(price),
 		Amount:   ToFloat64(amount),
 		Cid:      response.ClientOrdId,
 		OrderID2: response.OrdId,
 	}, nil
 
 }
-func (ok *OKExV5Spot) MarketBuy(amount, price string, currency CurrencyPair) (*Order, error) {
+func (ok *OKExV5Spot) MarketSell(amount, price string, currency CurrencyPair) (*Order, error) {
 
 	response, err := ok.CreateOrder(&CreateOrderParam{
 		Symbol:    currency.ToSymbol("-"),
 		TradeMode: "cash",
-		Side:      "buy",
+		Side:      "sell",
 		OrderType: "market",
 		Size:      amount,
 	})
@@ -94,36 +118,155 @@ func (ok *OKExV5Spot) MarketBuy(amount, price string, currency CurrencyPair) (*O
 	}, nil
 
 }
-func (ok *OKExV5Spot) MarketSell(amount, price string, currency CurrencyPair) (*Order, error) {
+func (ok *OKExV5Spot) CancelOrder(orderId string, currency CurrencyPair) (bool, error) {
+	_, err := ok.CancelOrderV5(currency.ToSymbol("-"), orderId, "")
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 
-	response, err := ok.CreateOrder(&CreateOrderParam{
-		Symbol:    currency.ToSymbol("-"),
-		TradeMode: "cash",
-		Side:      "sell",
-		OrderType: "market",
-		Size:      amount,
-	})
+}
+
+//修改订单是异步的，返回的订单只包含订单ID和修改后的参数
+func (ok *OKExV5Spot) AmendOrder(orderId string, currency CurrencyPair, newPrice, newAmount string) (*Order, error) {
+	response, err := ok.AmendOrderV5(currency.ToSymbol("-"), orderId, "", newAmount, newPrice)
 	if err != nil {
 		return nil, err
 	}
 	return &Order{
 		Currency: currency,
-		Amount:   ToFloat64(amount),
+		Price:    ToFloat64(newPrice),
+		Amount:   ToFloat64(newAmount),
 		Cid:      response.ClientOrdId,
 		OrderID2: response.OrdId,
+		Status:   ORDER_UNFINISH,
 	}, nil
+}
+
+const batchOrderSize = 20 //批量下单/撤单每次最多20个订单
+
+func v5OrderType(orderType int) string {
+	switch orderType {
+	case ORDER_FEATURE_POST_ONLY:
+		return "post_only"
+	case ORDER_FEATURE_FOK:
+		return "fok"
+	case ORDER_FEATURE_IOC:
+		return "ioc"
+	default:
+		return "limit"
+	}
+}
+
+//批量限价单，超过20个订单时分批请求
+func (ok *OKExV5Spot) BatchLimitOrders(orders []Order) ([]BatchOrderResult, error) {
+	results := make([]BatchOrderResult, 0, len(orders))
+
+	for start := 0; start < len(orders); start += batchOrderSize {
+		end := start + batchOrderSize
+		if end > len(orders) {
+			end = len(orders)
+		}
+
+		params := make([]*CreateOrderParam, 0, end-start)
+		for _, ord := range orders[start:end] {
+			side := "buy"
+			if ord.Side == SELL {
+				side = "sell"
+			}
+			params = append(params, &CreateOrderParam{
+				Symbol:      ord.Currency.ToSymbol("-"),
+				TradeMode:   "cash",
+				Side:        side,
+				OrderType:   v5OrderType(ord.OrderType),
+				Size:        NewDecimalFromFloat(ord.Amount).String(),
+				Price:       NewDecimalFromFloat(ord.Price).String(),
+				ClientOrdId: ord.Cid,
+			})
+		}
+
+		response, err := ok.BatchCreateOrders(params)
+		if err != nil {
+			for _, ord := range orders[start:] {
+				results = append(results, BatchOrderResult{Cid: ord.Cid, Err: err})
+			}
+			return results, err
+		}
+		for i := range response {
+			results = append(results, response[i].batchResult())
+		}
+	}
 
+	return results, nil
 }
-func (ok *OKExV5Spot) CancelOrder(orderId string, currency CurrencyPair) (bool, error) {
-	_, err := ok.CancelOrderV5(currency.ToSymbol("-"), orderId, "")
+
+func (ok *OKExV5Spot) BatchCancelOrders(currency CurrencyPair, orderIds []string) ([]BatchOrderResult, error) {
+	results := make([]BatchOrderResult, 0, len(orderIds))
+
+	for start := 0; start < len(orderIds); start += batchOrderSize {
+		end := start + batchOrderSize
+		if end > len(orderIds) {
+			end = len(orderIds)
+		}
+
+		response, err := ok.BatchCancelOrdersV5(currency.ToSymbol("-"), orderIds[start:end])
+		if err != nil {
+			for _, id := range orderIds[start:] {
+				results = append(results, BatchOrderResult{OrderId: id, Err: err})
+			}
+			return results, err
+		}
+		for i := range response {
+			results = append(results, response[i].batchResult())
+		}
+	}
+
+	return results, nil
+}
+
+//v5没有撤销全部订单的接口，查询未完成订单后批量撤销
+func (ok *OKExV5Spot) CancelAllOrders(currency CurrencyPair) (bool, error) {
+	orders, err := ok.GetUnfinishOrders(currency)
 	if err != nil {
 		return false, err
 	}
-	return true, nil
 
+	orderIds := make([]string, 0, len(orders))
+	for _, ord := range orders {
+		orderIds = append(orderIds, ord.OrderID2)
+	}
+
+	results, err := ok.BatchCancelOrders(currency, orderIds)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			return false, r.Err
+		}
+	}
+
+	return true, nil
 }
+
 func (ok *OKExV5Spot) GetOneOrder(orderId string, currency CurrencyPair) (*Order, error) {
-	response, err := ok.GetOrderV5(currency.ToSymbol("-"), orderId, "")
+	return ok.getOrder(orderId, "", currency)
+}
+
+func (ok *OKExV5Spot) GetOrderByClientId(cid string, currency CurrencyPair) (*Order, error) {
+	return ok.getOrder("", cid, currency)
+}
+
+func (ok *OKExV5Spot) CancelOrderByClientId(cid string, currency CurrencyPair) (bool, error) {
+	_, err := ok.CancelOrderV5(currency.ToSymbol("-"), "", cid)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (ok *OKExV5Spot) getOrder(orderId, cid string, currency CurrencyPair) (*Order, error) {
+	response, err := ok.GetOrderV5(currency.ToSymbol("-"), orderId, cid)
 	if err != nil {
 		return nil, err
 	}
@@ -146,8 +289,9 @@ func (ok *OKExV5Spot) GetOneOrder(orderId string, currency CurrencyPair) (*Order
 		side = SELL
 	}
 	return &Order{
-		Price:        response.Px,
-		Amount:       response.Sz,
+		Price:        response.Px.Float64(),
+		Amount:       response.Sz.Float64(),
+		Decimals:     response.decimals(),
 		AvgPrice:     ToFloat64(response.AvgPx),
 		DealAmount:   ToFloat64(response.AccFillSz),
 		Fee:          response.Fee,
@@ -191,8 +335,9 @@ func (ok *OKExV5Spot) GetUnfinishOrders(currency CurrencyPair) ([]Order, error)
 			side = SELL
 		}
 		orders = append(orders, Order{
-			Price:        v.Px,
-			Amount:       v.Sz,
+			Price:        v.Px.Float64(),
+			Amount:       v.Sz.Float64(),
+			Decimals:     v.decimals(),
 			AvgPrice:     ToFloat64(v.AvgPx),
 			DealAmount:   ToFloat64(v.AccFillSz),
 			Fee:          v.Fee,
@@ -240,8 +385,9 @@ func (ok *OKExV5Spot) GetOrderHistorys(currency CurrencyPair, opt ...OptionalPar
 		}
 
 		orders = append(orders, Order{
-			Price:        v.Px,
-			Amount:       v.Sz,
+			Price:        v.Px.Float64(),
+			Amount:       v.Sz.Float64(),
+			Decimals:     v.decimals(),
 			AvgPrice:     ToFloat64(v.AvgPx),
 			DealAmount:   ToFloat64(v.AccFillSz),
 			Fee:          v.Fee,
@@ -352,3 +498,7 @@ func (ok *OKExV5Spot) GetTrades(currencyPair CurrencyPair, since int64) ([]Trade
 func (ok *OKExV5Spot) GetExchangeName() string {
 	return ok.ExchangeName()
 }
+
+func (ok *OKExV5Spot) GetMarkets() ([]Market, error) {
+	return ok.GetMarketsV5("SPOT")
+}
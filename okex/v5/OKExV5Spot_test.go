@@ -1,9 +1,12 @@
 package okex
 
 import (
-	log "github.com/mrwill84/goex/internal/logger"
+	"errors"
 	"testing"
 
+	log "github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/internal/replay"
+
 	"github.com/mrwill84/goex"
 )
 
@@ -25,47 +28,79 @@ func newOKExV5SpotClient() *OKExV5Spot {
 	})
 }
 
+//回放testdata中的fixture , GOEX_RECORD=1时请求真实的交易所并更新fixture
+func newOKExV5SpotReplayClient(t *testing.T, fixture string) *OKExV5Spot {
+	srv := replay.NewServer(t, "testdata/"+fixture, v5RestBaseUrl)
+	return NewOKExV5Spot(&goex.APIConfig{
+		HttpClient:    srv.Client(),
+		Endpoint:      srv.URL,
+		ApiKey:        "key",
+		ApiSecretKey:  "secret",
+		ApiPassphrase: "passphrase",
+	})
+}
+
 func init() {
 	log.SetLevel(log.DEBUG)
 }
 
 func TestOKExV5Spot_GetTicker(t *testing.T) {
-	c := newOKExV5SpotClient()
-	t.Log(c.GetTicker(goex.BTC_USDT))
+	c := newOKExV5SpotReplayClient(t, "spot_market.json")
+	ticker, err := c.GetTicker(goex.BTC_USDT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/spot_ticker.golden.json", ticker)
 }
 
 func TestOKExV5Spot_GetDepth(t *testing.T) {
-	c := newOKExV5SpotClient()
-	t.Log(c.GetDepth(5, goex.BTC_USDT))
+	c := newOKExV5SpotReplayClient(t, "spot_market.json")
+	depth, err := c.GetDepth(3, goex.BTC_USDT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/spot_depth.golden.json", depth)
 }
 
 func TestOKExV5SpotGetKlineRecords(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	c := newOKExV5SpotClient()
 	t.Log(c.GetKlineRecords(goex.BTC_USDT, goex.KLINE_PERIOD_1MIN, 10))
 }
 
 func TestOKExV5Spot_LimitBuy(t *testing.T) {
-	c := newOKExV5SpotClient()
-	t.Log(c.LimitBuy("1", "1.0", goex.XRP_USDT))
-	//{"code":"0","data":[{"clOrdId":"0bf60374efe445BC258eddf46df044c3","ordId":"305267682086109184","sCode":"0","sMsg":"","tag":""}],"msg":""}}
+	c := newOKExV5SpotReplayClient(t, "spot_limit_buy.json")
+	ord, err := c.LimitBuy("1", "1.0", goex.XRP_USDT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/spot_limit_buy.golden.json", ord)
+
+	if _, err = c.LimitBuy("1", "1.0", goex.XRP_USDT); !errors.Is(err, goex.EX_ERR_INSUFFICIENT_BALANCE) {
+		t.Fatal(err)
+	}
 }
 
 func TestOKExV5Spot_CancelOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	c := newOKExV5SpotClient()
 	t.Log(c.CancelOrder("305267682086109184", goex.XRP_USDT))
 }
 
 func TestOKExV5Spot_GetUnfinishOrders(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	c := newOKExV5SpotClient()
 	t.Log(c.GetUnfinishOrders(goex.XRP_USDT))
 }
 
 func TestOKExV5Spot_GetOneOrder(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	c := newOKExV5SpotClient()
 	t.Log(c.GetOneOrder("305267682086109184", goex.XRP_USDT))
 }
 
 func TestOKExV5Spot_GetAccount(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	c := newOKExV5SpotClient()
 	t.Log(c.GetAccount())
 }
@@ -1,19 +1,56 @@
 package okex
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"sort"
+	"sync"
 
 	. "github.com/mrwill84/goex"
 )
 
 type OKExV5Swap struct {
 	*OKExV5
+	marginModes *marginModes //按合约设置的保证金模式，WithContext返回的实例共用
+	netMode     bool         //单向持仓模式下不传posSide，平仓单设置reduceOnly
 }
 
+//instId -> cross 全仓 , isolated 逐仓 ; 未设置的合约使用全仓
+type marginModes struct {
+	lock  sync.RWMutex
+	modes map[string]string
+}
+
+func (m *marginModes) get(instId string) string {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if mode, ok := m.modes[instId]; ok {
+		return mode
+	}
+	return string(CROSS_MARGIN)
+}
+
+func (m *marginModes) set(instId string, mode MarginMode) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.modes[instId] = string(mode)
+}
+
+var (
+	_ FutureRestAPI          = (*OKExV5Swap)(nil)
+	_ FutureContextBinder    = (*OKExV5Swap)(nil)
+	_ FutureClientOrderIdAPI = (*OKExV5Swap)(nil)
+	_ PerpetualDataAPI       = (*OKExV5Swap)(nil)
+	_ FutureLeverageAPI      = (*OKExV5Swap)(nil)
+	_ FutureTriggerOrderAPI  = (*OKExV5Swap)(nil)
+	_ MarketInfo             = (*OKExV5Swap)(nil)
+)
+
 func NewOKExV5Swap(config *APIConfig) *OKExV5Swap {
 	v5 := new(OKExV5Swap)
 	v5.OKExV5 = NewOKExV5(config)
+	v5.marginModes = &marginModes{modes: make(map[string]string, 2)}
 	return v5
 }
 
@@ -21,12 +58,91 @@ func (O *OKExV5Swap) GetExchangeName() string {
 	return OKEX_SWAP
 }
 
+func (O *OKExV5Swap) WithContext(ctx context.Context) FutureRestAPI {
+	return &OKExV5Swap{OKExV5: O.withContext(ctx), marginModes: O.marginModes, netMode: O.netMode}
+}
+
+//okex的保证金模式由每笔订单的tdMode决定，这里只修改该合约之后下单使用的模式，默认全仓
+func (O *OKExV5Swap) SetMarginMode(currencyPair CurrencyPair, contractType string, mode MarginMode) error {
+	switch mode {
+	case CROSS_MARGIN, ISOLATED_MARGIN:
+		O.marginModes.set(O.adaptInstId(currencyPair), mode)
+		return nil
+	default:
+		return fmt.Errorf("unknown margin mode %s", mode)
+	}
+}
+
+//设置账户持仓模式，hedge=true为双向持仓(默认)，false为单向持仓
+func (O *OKExV5Swap) SetPositionMode(hedge bool) error {
+	posMode := "long_short_mode"
+	if !hedge {
+		posMode = "net_mode"
+	}
+	if err := O.SetPositionModeV5(posMode); err != nil {
+		return err
+	}
+	O.netMode = !hedge
+	return nil
+}
+
+//设置杠杆倍数，逐仓双向持仓模式下多空两个方向都会设置
+func (O *OKExV5Swap) SetLeverage(currencyPair CurrencyPair, contractType string, lever int) error {
+	instId := O.adaptInstId(currencyPair)
+	marginMode := O.marginModes.get(instId)
+	if marginMode == string(ISOLATED_MARGIN) && !O.netMode {
+		for _, posSide := range []string{"long", "short"} {
+			if _, err := O.SetLeverageV5(instId, fmt.Sprint(lever), marginMode, posSide); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	_, err := O.SetLeverageV5(instId, fmt.Sprint(lever), marginMode, "")
+	return err
+}
+
+func (O *OKExV5Swap) GetLeverage(currencyPair CurrencyPair, contractType string) (float64, error) {
+	instId := O.adaptInstId(currencyPair)
+	levers, err := O.GetLeverageV5(instId, O.marginModes.get(instId))
+	if err != nil {
+		return 0, err
+	}
+	if len(levers) == 0 {
+		return 0, EX_ERR_SYMBOL_ERR
+	}
+	return ToFloat64(levers[0].Lever), nil
+}
+
+func (O *OKExV5Swap) ModifyIsolatedMargin(currencyPair CurrencyPair, contractType string, positionSide int, amount float64) error {
+	posSide := "net"
+	if !O.netMode {
+		posSide = "long"
+		if positionSide == OPEN_SELL {
+			posSide = "short"
+		}
+	}
+
+	marginType := "add"
+	if amount < 0 {
+		marginType = "reduce"
+		amount = -amount
+	}
+	return O.ModifyMarginBalanceV5(O.adaptInstId(currencyPair), posSide, marginType, FloatToString(amount, 8))
+}
+
+//BTC_USDT -> BTC-USDT-SWAP
+func (O *OKExV5Swap) adaptInstId(currencyPair CurrencyPair) string {
+	return fmt.Sprintf("%s-SWAP", currencyPair.ToSymbol("-"))
+}
+
+//永续合约没有预估交割价格，返回指数价格
 func (O *OKExV5Swap) GetFutureEstimatedPrice(currencyPair CurrencyPair) (float64, error) {
-	panic("implement me")
+	return O.GetFutureIndex(currencyPair)
 }
 
 func (O *OKExV5Swap) GetFutureTicker(currencyPair CurrencyPair, contractType string) (*Ticker, error) {
-	t, err := O.OKExV5.GetTickerV5(fmt.Sprintf("%s-SWAP", currencyPair.ToSymbol("-")))
+	t, err := O.OKExV5.GetTickerV5(O.adaptInstId(currencyPair))
 
 	if err != nil {
 		return nil, err
@@ -45,8 +161,7 @@ func (O *OKExV5Swap) GetFutureTicker(currencyPair CurrencyPair, contractType str
 }
 
 func (O *OKExV5Swap) GetFutureDepth(currencyPair CurrencyPair, contractType string, size int) (*Depth, error) {
-	instId := fmt.Sprintf("%s-SWAP", currencyPair.ToSymbol("-"))
-	dep, err := O.OKExV5.GetDepthV5(instId, size)
+	dep, err := O.OKExV5.GetDepthV5(O.adaptInstId(currencyPair), size)
 
 	if err != nil {
 		return nil, err
@@ -71,65 +186,577 @@ func (O *OKExV5Swap) GetFutureDepth(currencyPair CurrencyPair, contractType stri
 }
 
 func (O *OKExV5Swap) GetFutureIndex(currencyPair CurrencyPair) (float64, error) {
-	panic("implement me")
+	return O.GetIndexPriceV5(currencyPair.ToSymbol("-"))
 }
 
+//只返回交易对涉及币种的账户，不传交易对返回全部
 func (O *OKExV5Swap) GetFutureUserinfo(currencyPair ...CurrencyPair) (*FutureAccount, error) {
-	panic("implement me")
+	balance, err := O.GetAccountBalances("")
+	if err != nil {
+		return nil, err
+	}
+
+	currencies := make(map[string]bool, 2*len(currencyPair))
+	for _, pair := range currencyPair {
+		currencies[pair.CurrencyA.Symbol] = true
+		currencies[pair.CurrencyB.Symbol] = true
+	}
+
+	account := &FutureAccount{FutureSubAccounts: make(map[Currency]FutureSubAccount, 2)}
+	for _, itm := range balance.Details {
+		if len(currencies) > 0 && !currencies[itm.Currency] {
+			continue
+		}
+		currency := NewCurrency(itm.Currency, "")
+		account.FutureSubAccounts[currency] = FutureSubAccount{
+			Currency:      currency,
+			AccountRights: ToFloat64(itm.Eq),
+			KeepDeposit:   ToFloat64(itm.Frozen),
+			ProfitUnreal:  ToFloat64(itm.Upl),
+			RiskRate:      ToFloat64(itm.MgnRatio),
+		}
+	}
+
+	return account, nil
+}
+
+//openType转换为side , posSide
+func (O *OKExV5Swap) adaptOpenType(openType int) (side, posSide string, reduceOnly bool, err error) {
+	switch openType {
+	case OPEN_BUY:
+		side, posSide = "buy", "long"
+	case OPEN_SELL:
+		side, posSide = "sell", "short"
+	case CLOSE_BUY:
+		side, posSide, reduceOnly = "sell", "long", true
+	case CLOSE_SELL:
+		side, posSide, reduceOnly = "buy", "short", true
+	default:
+		return "", "", false, EX_ERR_INVALID_ORDER_PARAM
+	}
+
+	if O.netMode {
+		return side, "", reduceOnly, nil
+	}
+	//双向持仓模式下posSide已经决定了开平方向，不需要reduceOnly
+	return side, posSide, false, nil
+}
+
+func (O *OKExV5Swap) placeOrder(currencyPair CurrencyPair, ordType, price, amount string, openType int, cid string) (*FutureOrder, error) {
+	side, posSide, reduceOnly, err := O.adaptOpenType(openType)
+	if err != nil {
+		return nil, err
+	}
+
+	instId := O.adaptInstId(currencyPair)
+	response, err := O.CreateOrder(&CreateOrderParam{
+		Symbol:      instId,
+		TradeMode:   O.marginModes.get(instId),
+		Side:        side,
+		PosSide:     posSide,
+		OrderType:   ordType,
+		Size:        amount,
+		Price:       price,
+		ClientOrdId: cid,
+		ReduceOnly:  reduceOnly,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	orderType := ORDER_FEATURE_ORDINARY
+	switch ordType {
+	case "post_only":
+		orderType = ORDER_FEATURE_POST_ONLY
+	case "fok":
+		orderType = ORDER_FEATURE_FOK
+	case "ioc":
+		orderType = ORDER_FEATURE_IOC
+	}
+
+	return &FutureOrder{
+		ClientOid:    response.ClientOrdId,
+		OrderID2:     response.OrdId,
+		Price:        ToFloat64(price),
+		Amount:       ToFloat64(amount),
+		Status:       ORDER_UNFINISH,
+		Currency:     currencyPair,
+		OrderType:    orderType,
+		OType:        openType,
+		ContractName: O.adaptInstId(currencyPair),
+		Decimals:     OrderDecimals{Price: ToDecimal(price), Amount: ToDecimal(amount)},
+	}, nil
 }
 
+//杠杆倍数通过SetLeverage设置，这里忽略leverRate
 func (O *OKExV5Swap) PlaceFutureOrder(currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice int, leverRate float64) (string, error) {
-	panic("implement me")
+	var (
+		ord *FutureOrder
+		err error
+	)
+	if matchPrice == 1 {
+		ord, err = O.MarketFuturesOrder(currencyPair, contractType, amount, openType)
+	} else {
+		ord, err = O.LimitFuturesOrder(currencyPair, contractType, price, amount, openType)
+	}
+	if err != nil {
+		return "", err
+	}
+	return ord.OrderID2, nil
 }
 
 func (O *OKExV5Swap) LimitFuturesOrder(currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
-	panic("implement me")
+	ordType := "limit"
+	if tif, has := GetTimeInForce(opt...); has {
+		ordType = tif.String()
+	}
+	return O.placeOrder(currencyPair, ordType, price, amount, openType, GetClientOrderId(opt...))
 }
 
 func (O *OKExV5Swap) MarketFuturesOrder(currencyPair CurrencyPair, contractType, amount string, openType int) (*FutureOrder, error) {
-	panic("implement me")
+	return O.placeOrder(currencyPair, "market", "", amount, openType, "")
 }
 
 func (O *OKExV5Swap) FutureCancelOrder(currencyPair CurrencyPair, contractType, orderId string) (bool, error) {
-	panic("implement me")
+	_, err := O.CancelOrderV5(O.adaptInstId(currencyPair), orderId, "")
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
+func (O *OKExV5Swap) FutureCancelOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (bool, error) {
+	_, err := O.CancelOrderV5(O.adaptInstId(currencyPair), "", cid)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+//双向持仓模式下多空合并为一条记录
 func (O *OKExV5Swap) GetFuturePosition(currencyPair CurrencyPair, contractType string) ([]FuturePosition, error) {
-	panic("implement me")
+	positions, err := O.GetPositionsV5("SWAP", O.adaptInstId(currencyPair))
+	if err != nil {
+		return nil, err
+	}
+
+	pos := FuturePosition{Symbol: currencyPair, ContractType: contractType}
+	for _, p := range positions {
+		if ToFloat64(p.Pos) == 0 {
+			continue
+		}
+		ps := adaptFuturePositionV5(p)
+		pos.CreateDate = ps.CreateDate
+		pos.LeverRate = ps.LeverRate
+		pos.ForceLiquPrice = ps.ForceLiquPrice
+		if ps.BuyAmount > 0 {
+			pos.BuyAmount = ps.BuyAmount
+			pos.BuyAvailable = ps.BuyAvailable
+			pos.BuyPriceAvg = ps.BuyPriceAvg
+			pos.BuyPriceCost = ps.BuyPriceAvg
+			pos.BuyProfit = ps.BuyProfit
+			pos.BuyProfitReal = ps.BuyProfitReal
+			pos.LongPnlRatio = ps.LongPnlRatio
+		} else {
+			pos.SellAmount = ps.SellAmount
+			pos.SellAvailable = ps.SellAvailable
+			pos.SellPriceAvg = ps.SellPriceAvg
+			pos.SellPriceCost = ps.SellPriceAvg
+			pos.SellProfit = ps.SellProfit
+			pos.SellProfitReal = ps.SellProfitReal
+			pos.ShortPnlRatio = ps.ShortPnlRatio
+		}
+	}
+
+	return []FuturePosition{pos}, nil
 }
 
 func (O *OKExV5Swap) GetFutureOrders(orderIds []string, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
-	panic("implement me")
+	orders := make([]FutureOrder, 0, len(orderIds))
+	for _, id := range orderIds {
+		ord, err := O.GetFutureOrder(id, currencyPair, contractType)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *ord)
+	}
+	return orders, nil
 }
 
 func (O *OKExV5Swap) GetFutureOrder(orderId string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
-	panic("implement me")
+	return O.getOrder(orderId, "", currencyPair)
+}
+
+func (O *OKExV5Swap) GetFutureOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
+	return O.getOrder("", cid, currencyPair)
+}
+
+func (O *OKExV5Swap) getOrder(orderId, cid string, currencyPair CurrencyPair) (*FutureOrder, error) {
+	response, err := O.GetOrderV5(O.adaptInstId(currencyPair), orderId, cid)
+	if err != nil {
+		return nil, err
+	}
+	ord := adaptFutureOrderV5(*response)
+	ord.Currency = currencyPair
+	return ord, nil
 }
 
 func (O *OKExV5Swap) GetUnfinishFutureOrders(currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
-	panic("implement me")
+	response, err := O.GetPendingOrders(&PendingOrderParam{
+		InstType: "SWAP",
+		InstId:   O.adaptInstId(currencyPair),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return O.adaptOrders(response, currencyPair), nil
 }
 
+//optional支持ordType , state , after , before(订单ID分页)
 func (O *OKExV5Swap) GetFutureOrderHistory(pair CurrencyPair, contractType string, optional ...OptionalParameter) ([]FutureOrder, error) {
-	panic("implement me")
+	param := OptionalParameter{}
+	for _, opt := range optional {
+		for k, v := range opt {
+			param[k] = v
+		}
+	}
+	get := func(name string) string {
+		if _, has := param[name]; !has {
+			return ""
+		}
+		return param.GetString(name)
+	}
+
+	response, err := O.GetOrderHistory("SWAP", O.adaptInstId(pair),
+		get("ordType"), get("state"), get("after"), get("before"))
+	if err != nil {
+		return nil, err
+	}
+	return O.adaptOrders(response, pair), nil
 }
 
+func (O *OKExV5Swap) adaptOrders(response []OrderV5, currencyPair CurrencyPair) []FutureOrder {
+	orders := make([]FutureOrder, 0, len(response))
+	for _, v := range response {
+		ord := adaptFutureOrderV5(v)
+		ord.Currency = currencyPair
+		orders = append(orders, *ord)
+	}
+	return orders
+}
+
+//v5返回的费率为负数表示扣除手续费，这里返回taker费率的正数
 func (O *OKExV5Swap) GetFee() (float64, error) {
-	panic("implement me")
+	_, taker, err := O.GetTradeFeeV5("SWAP", "")
+	if err != nil {
+		return 0, err
+	}
+	return -taker, nil
 }
 
+//合约面值，币本位单位为USD , U本位单位为币
 func (O *OKExV5Swap) GetContractValue(currencyPair CurrencyPair) (float64, error) {
-	panic("implement me")
+	instruments, err := O.GetInstrumentsV5("SWAP", "", O.adaptInstId(currencyPair))
+	if err != nil {
+		return 0, err
+	}
+	if len(instruments) == 0 {
+		return 0, EX_ERR_SYMBOL_ERR
+	}
+	return instruments[0].CtVal, nil
 }
 
+//永续合约没有交割时间
 func (O OKExV5Swap) GetDeliveryTime() (int, int, int, int) {
-	panic("implement me")
+	return 0, 0, 0, 0
 }
 
 func (O *OKExV5Swap) GetKlineRecords(contractType string, currency CurrencyPair, period KlinePeriod, size int, optional ...OptionalParameter) ([]FutureKline, error) {
-	panic("implement me")
+	param := &url.Values{}
+	param.Set("limit", fmt.Sprint(size))
+	MergeOptionalParameter(param, optional...)
+
+	kl, err := O.GetKlineRecordsV5(O.adaptInstId(currency), period, param)
+	if err != nil {
+		return nil, err
+	}
+
+	klines := make([]FutureKline, 0, len(kl))
+	for _, k := range kl {
+		klines = append(klines, FutureKline{
+			Kline: &Kline{
+				Pair:      currency,
+				Timestamp: ToInt64(k[0]),
+				Open:      ToFloat64(k[1]),
+				High:      ToFloat64(k[2]),
+				Low:       ToFloat64(k[3]),
+				Close:     ToFloat64(k[4]),
+				Vol:       ToFloat64(k[5]),
+			},
+			Vol2: ToFloat64(k[6]),
+		})
+	}
+
+	return klines, nil
 }
 
+//非个人，整个交易所最近的成交记录，只返回成交时间大于since(毫秒)的记录
 func (O *OKExV5Swap) GetTrades(contractType string, currencyPair CurrencyPair, since int64) ([]Trade, error) {
-	panic("implement me")
+	response, err := O.GetTradesV5(O.adaptInstId(currencyPair), 500)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]Trade, 0, len(response))
+	for _, t := range response {
+		if t.Ts <= since {
+			continue
+		}
+		side := BUY
+		if t.Side == "sell" {
+			side = SELL
+		}
+		trades = append(trades, Trade{
+			ContractType: contractType,
+			Tid:          ToInt64(t.TradeId),
+			Exchange:     OKEX_SWAP,
+			Type:         side,
+			Amount:       t.Sz,
+			Price:        t.Px,
+			Date:         t.Ts,
+			Pair:         currencyPair,
+		})
+	}
+	return trades, nil
+}
+
+func (O *OKExV5Swap) GetFundingRate(currencyPair CurrencyPair, contractType string) (*FundingRate, error) {
+	response, err := O.GetFundingRateV5(O.adaptInstId(currencyPair))
+	if err != nil {
+		return nil, err
+	}
+	return &FundingRate{
+		Pair:            currencyPair,
+		ContractType:    contractType,
+		Rate:            response.FundingRate,
+		NextRate:        ToFloat64(response.NextFundingRate),
+		FundingTime:     response.FundingTime,
+		NextFundingTime: response.NextFundingTime,
+	}, nil
+}
+
+//okex单次最多返回100条，使用实际收取的realizedRate
+func (O *OKExV5Swap) GetFundingRateHistory(currencyPair CurrencyPair, contractType string, start, end int64, size int) ([]FundingRate, error) {
+	params := url.Values{}
+	if start > 0 {
+		params.Set("before", fmt.Sprint(start-1))
+	}
+	if end > 0 {
+		params.Set("after", fmt.Sprint(end+1))
+	}
+	if size > 0 {
+		params.Set("limit", fmt.Sprint(size))
+	}
+
+	response, err := O.GetFundingRateHistoryV5(O.adaptInstId(currencyPair), &params)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]FundingRate, 0, len(response))
+	for _, r := range response {
+		rate := r.FundingRate
+		if r.RealizedRate != "" {
+			rate = ToFloat64(r.RealizedRate)
+		}
+		rates = append(rates, FundingRate{
+			Pair:         currencyPair,
+			ContractType: contractType,
+			Rate:         rate,
+			FundingTime:  r.FundingTime,
+		})
+	}
+	return rates, nil
+}
+
+func (O *OKExV5Swap) GetMarkPrice(currencyPair CurrencyPair, contractType string) (*MarkPrice, error) {
+	markPx, ts, err := O.GetMarkPriceV5("SWAP", O.adaptInstId(currencyPair))
+	if err != nil {
+		return nil, err
+	}
+	indexPx, err := O.GetIndexPriceV5(currencyPair.ToSymbol("-"))
+	if err != nil {
+		return nil, err
+	}
+	return &MarkPrice{
+		Pair:         currencyPair,
+		ContractType: contractType,
+		MarkPrice:    markPx,
+		IndexPrice:   indexPx,
+		Timestamp:    ts,
+	}, nil
+}
+
+func (O *OKExV5Swap) GetOpenInterest(currencyPair CurrencyPair, contractType string) (*OpenInterest, error) {
+	oi, oiCcy, ts, err := O.GetOpenInterestV5("SWAP", O.adaptInstId(currencyPair))
+	if err != nil {
+		return nil, err
+	}
+	return &OpenInterest{
+		Pair:         currencyPair,
+		ContractType: contractType,
+		Amount:       oi,
+		Value:        oiCcy,
+		Timestamp:    ts,
+	}, nil
+}
+
+//止损/止盈使用单向止盈止损(conditional)，跟踪止损使用移动止盈止损(move_order_stop)，后者不支持ClosePosition
+func (O *OKExV5Swap) PlaceTriggerOrder(ord *TriggerOrder) (*TriggerOrder, error) {
+	side, posSide, reduceOnly, err := O.adaptOpenType(ord.OType)
+	if err != nil {
+		return nil, err
+	}
+
+	reqBody := make(map[string]interface{})
+	instId := O.adaptInstId(ord.Currency)
+	reqBody["instId"] = instId
+	reqBody["tdMode"] = O.marginModes.get(instId)
+	reqBody["side"] = side
+	if posSide != "" {
+		reqBody["posSide"] = posSide
+	}
+	if O.netMode && (reduceOnly || ord.ReduceOnly) {
+		reqBody["reduceOnly"] = true
+	}
+	if ord.ClientOid != "" {
+		reqBody["algoClOrdId"] = ord.ClientOid
+	}
+
+	ordPx := "-1"
+	if ord.Type == TRIGGER_STOP_LIMIT || ord.Type == TRIGGER_TAKE_PROFIT_LIMIT {
+		ordPx = FloatToString(ord.Price, 8)
+	}
+
+	switch ord.Type {
+	case TRIGGER_STOP_MARKET, TRIGGER_STOP_LIMIT:
+		reqBody["ordType"] = "conditional"
+		reqBody["slTriggerPx"] = FloatToString(ord.TriggerPrice, 8)
+		reqBody["slOrdPx"] = ordPx
+	case TRIGGER_TAKE_PROFIT_MARKET, TRIGGER_TAKE_PROFIT_LIMIT:
+		reqBody["ordType"] = "conditional"
+		reqBody["tpTriggerPx"] = FloatToString(ord.TriggerPrice, 8)
+		reqBody["tpOrdPx"] = ordPx
+	case TRIGGER_TRAILING_STOP:
+		if ord.ClosePosition {
+			return nil, EX_ERR_NOT_SUPPORTED.Wrap("", "close position with trailing stop")
+		}
+		reqBody["ordType"] = "move_order_stop"
+		reqBody["callbackRatio"] = FloatToString(ord.CallbackRate, 4)
+		if ord.TriggerPrice > 0 {
+			reqBody["activePx"] = FloatToString(ord.TriggerPrice, 8)
+		}
+	default:
+		return nil, fmt.Errorf("unknown trigger order type %s", ord.Type)
+	}
+
+	if ord.ClosePosition {
+		reqBody["closeFraction"] = "1"
+	} else {
+		reqBody["sz"] = FloatToString(ord.Amount, 8)
+	}
+
+	algoId, err := O.PlaceAlgoOrderV5(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	placed := *ord
+	placed.OrderId = algoId
+	placed.Status = ORDER_UNFINISH
+	return &placed, nil
+}
+
+func (O *OKExV5Swap) CancelTriggerOrder(currencyPair CurrencyPair, contractType, orderId string) (bool, error) {
+	if err := O.CancelAlgoOrdersV5(O.adaptInstId(currencyPair), []string{orderId}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (O *OKExV5Swap) GetUnfinishTriggerOrders(currencyPair CurrencyPair, contractType string) ([]TriggerOrder, error) {
+	var orders []TriggerOrder
+	for _, ordType := range []string{"conditional", "move_order_stop"} {
+		response, err := O.GetAlgoOrdersPendingV5(ordType, "SWAP", O.adaptInstId(currencyPair))
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range response {
+			orders = append(orders, O.adaptTriggerOrder(o, currencyPair, contractType))
+		}
+	}
+	return orders, nil
+}
+
+func (O *OKExV5Swap) adaptTriggerOrder(o AlgoOrderV5, currencyPair CurrencyPair, contractType string) TriggerOrder {
+	ord := TriggerOrder{
+		OrderId:       o.AlgoId,
+		ClientOid:     o.AlgoClOrdId,
+		Currency:      currencyPair,
+		ContractType:  contractType,
+		Amount:        ToFloat64(o.Sz),
+		ReduceOnly:    o.ReduceOnly == "true",
+		ClosePosition: o.CloseFraction == "1",
+		Status:        ORDER_UNFINISH,
+		OrderTime:     o.CTime,
+		TriggeredId:   o.OrdId,
+	}
+
+	switch {
+	case o.Side == "buy" && (o.PosSide == "short" || o.PosSide == "net" && ord.ReduceOnly):
+		ord.OType = CLOSE_SELL
+	case o.Side == "sell" && (o.PosSide == "long" || o.PosSide == "net" && ord.ReduceOnly):
+		ord.OType = CLOSE_BUY
+	case o.Side == "sell":
+		ord.OType = OPEN_SELL
+	default:
+		ord.OType = OPEN_BUY
+	}
+
+	switch {
+	case o.OrdType == "move_order_stop":
+		ord.Type = TRIGGER_TRAILING_STOP
+		ord.TriggerPrice = ToFloat64(o.ActivePx)
+		ord.CallbackRate = ToFloat64(o.CallbackRatio)
+	case o.SlTriggerPx != "":
+		ord.Type = TRIGGER_STOP_MARKET
+		ord.TriggerPrice = ToFloat64(o.SlTriggerPx)
+		if o.SlOrdPx != "-1" {
+			ord.Type = TRIGGER_STOP_LIMIT
+			ord.Price = ToFloat64(o.SlOrdPx)
+		}
+	default:
+		ord.Type = TRIGGER_TAKE_PROFIT_MARKET
+		ord.TriggerPrice = ToFloat64(o.TpTriggerPx)
+		if o.TpOrdPx != "-1" {
+			ord.Type = TRIGGER_TAKE_PROFIT_LIMIT
+			ord.Price = ToFloat64(o.TpOrdPx)
+		}
+	}
+	return ord
+}
+
+//永续合约和交割合约
+func (O *OKExV5Swap) GetMarkets() ([]Market, error) {
+	markets, err := O.GetMarketsV5("SWAP")
+	if err != nil {
+		return nil, err
+	}
+
+	futures, err := O.GetMarketsV5("FUTURES")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(markets, futures...), nil
 }
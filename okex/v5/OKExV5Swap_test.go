@@ -1,10 +1,14 @@
 package okex
 
 import (
-	"github.com/mrwill84/goex"
-	log "github.com/mrwill84/goex/internal/logger"
+	"context"
+	"errors"
 	"net/http"
 	"testing"
+
+	"github.com/mrwill84/goex"
+	log "github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 func init() {
@@ -13,6 +17,7 @@ func init() {
 }
 
 func TestOKExV5Swap_GetFutureTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	swap := NewOKExV5Swap(&goex.APIConfig{
 		HttpClient:    http.DefaultClient,
 		ApiKey:        "",
@@ -24,6 +29,7 @@ func TestOKExV5Swap_GetFutureTicker(t *testing.T) {
 }
 
 func TestOKExV5Swap_GetFutureDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	swap := NewOKExV5Swap(&goex.APIConfig{
 		HttpClient: http.DefaultClient,
 	})
@@ -37,3 +43,144 @@ func TestOKExV5Swap_GetFutureDepth(t *testing.T) {
 	t.Log(dep.AskList)
 	t.Log(dep.BidList)
 }
+
+func newOKExV5SwapReplayClient(t *testing.T, fixture string) *OKExV5Swap {
+	srv := replay.NewServer(t, "testdata/"+fixture, v5RestBaseUrl)
+	return NewOKExV5Swap(&goex.APIConfig{
+		HttpClient:    srv.Client(),
+		Endpoint:      srv.URL,
+		ApiKey:        "key",
+		ApiSecretKey:  "secret",
+		ApiPassphrase: "passphrase",
+	})
+}
+
+func TestOKExV5Swap_Trade(t *testing.T) {
+	swap := newOKExV5SwapReplayClient(t, "swap_trade.json")
+
+	ord, err := swap.LimitFuturesOrder(goex.BTC_USDT, goex.SWAP_CONTRACT, "60000", "2", goex.OPEN_SELL,
+		goex.PostOnly, goex.ClientOrderId("goexswap0001"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_limit_order.golden.json", ord)
+
+	ord, err = swap.GetFutureOrder(ord.OrderID2, goex.BTC_USDT, goex.SWAP_CONTRACT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_order.golden.json", ord)
+
+	positions, err := swap.GetFuturePosition(goex.BTC_USDT, goex.SWAP_CONTRACT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_position.golden.json", positions)
+
+	if _, err = swap.MarketFuturesOrder(goex.BTC_USDT, goex.SWAP_CONTRACT, "1", goex.OPEN_BUY); !errors.Is(err, goex.EX_ERR_INSUFFICIENT_BALANCE) {
+		t.Fatal(err)
+	}
+
+	if _, err = swap.MarketFuturesOrder(goex.BTC_USDT, goex.SWAP_CONTRACT, "1", 0); !errors.Is(err, goex.EX_ERR_INVALID_ORDER_PARAM) {
+		t.Fatal(err)
+	}
+}
+
+func TestOKExV5Swap_PerpetualData(t *testing.T) {
+	swap := newOKExV5SwapReplayClient(t, "swap_perpetual.json")
+
+	rate, err := swap.GetFundingRate(goex.BTC_USDT, goex.SWAP_CONTRACT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_funding_rate.golden.json", rate)
+
+	rates, err := swap.GetFundingRateHistory(goex.BTC_USDT, goex.SWAP_CONTRACT, 1634169600000, 1634256000000, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_funding_rate_history.golden.json", rates)
+
+	markPrice, err := swap.GetMarkPrice(goex.BTC_USDT, goex.SWAP_CONTRACT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_mark_price.golden.json", markPrice)
+
+	oi, err := swap.GetOpenInterest(goex.BTC_USDT, goex.SWAP_CONTRACT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_open_interest.golden.json", oi)
+}
+
+func TestOKExV5Swap_TriggerOrders(t *testing.T) {
+	swap := newOKExV5SwapReplayClient(t, "swap_trigger.json")
+
+	ord, err := swap.PlaceTriggerOrder(&goex.TriggerOrder{
+		ClientOid:    "goexstop0001",
+		Currency:     goex.BTC_USDT,
+		ContractType: goex.SWAP_CONTRACT,
+		Type:         goex.TRIGGER_STOP_MARKET,
+		OType:        goex.CLOSE_BUY,
+		Amount:       2,
+		TriggerPrice: 58000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ord.OrderId != "372254871658201088" {
+		t.Fatal(ord)
+	}
+
+	orders, err := swap.GetUnfinishTriggerOrders(goex.BTC_USDT, goex.SWAP_CONTRACT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.AssertGolden(t, "testdata/swap_trigger_orders.golden.json", orders)
+
+	ok, err := swap.CancelTriggerOrder(goex.BTC_USDT, goex.SWAP_CONTRACT, ord.OrderId)
+	if err != nil || !ok {
+		t.Fatal(ok, err)
+	}
+}
+
+func TestOKExV5Swap_SymbolMapper(t *testing.T) {
+	swap := newOKExV5SwapReplayClient(t, "swap_instruments.json")
+	mapper := goex.NewSymbolMapper(swap, 0)
+
+	symbol, err := mapper.ToSymbol(goex.BTC_USD, goex.BI_QUARTER_CONTRACT)
+	if err != nil || symbol != "BTC-USD-220325" {
+		t.Fatal(symbol, err)
+	}
+
+	pair, contractType, err := mapper.ToCurrencyPair("BTC-USDT-SWAP")
+	if err != nil || pair.ToSymbol("_") != "BTC_USDT" || contractType != goex.SWAP_USDT_CONTRACT {
+		t.Fatal(pair, contractType, err)
+	}
+
+	pair, contractType, err = mapper.ToCurrencyPair("BTC-USD-211231")
+	if err != nil || pair.ToSymbol("_") != "BTC_USD" || contractType != goex.QUARTER_CONTRACT {
+		t.Fatal(pair, contractType, err)
+	}
+}
+
+func TestOKExV5Swap_SetMarginMode(t *testing.T) {
+	swap := NewOKExV5Swap(&goex.APIConfig{})
+	if err := swap.SetMarginMode(goex.BTC_USDT, goex.SWAP_CONTRACT, goex.ISOLATED_MARGIN); err != nil {
+		t.Fatal(err)
+	}
+
+	//只影响设置的合约，WithContext返回的实例共用设置
+	ctxSwap := swap.WithContext(context.Background()).(*OKExV5Swap)
+	if mode := ctxSwap.marginModes.get("BTC-USDT-SWAP"); mode != string(goex.ISOLATED_MARGIN) {
+		t.Fatal(mode)
+	}
+	if mode := swap.marginModes.get("ETH-USDT-SWAP"); mode != string(goex.CROSS_MARGIN) {
+		t.Fatal(mode)
+	}
+
+	if err := swap.SetMarginMode(goex.BTC_USDT, goex.SWAP_CONTRACT, "unknown"); err == nil {
+		t.Fatal("expect error")
+	}
+}
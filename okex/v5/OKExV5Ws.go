@@ -0,0 +1,648 @@
+package okex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/orderbook"
+)
+
+const (
+	v5WsPublicUrl = v5WsBaseUrl + "/public"
+
+	//本地订单簿推送的深度档数
+	wsDepthSize = 20
+
+	//每个连接每小时最多480次订阅/取消订阅/登录请求，每个连接的订阅数限制为一半，保证重连后可以重新订阅
+	wsSubscribeLimit = 480
+	wsMaxSubsPerConn = wsSubscribeLimit / 2
+)
+
+//v5 公共频道的ws连接，订阅时按需建立连接
+type publicWsV5 struct {
+	wsBuilder *WsBuilder
+	c         *WsPool
+
+	lock       sync.Mutex
+	orderBooks map[string]*orderbook.OrderBook //instId -> 本地订单簿
+	klineBars  map[string]KlinePeriod          //candle频道 -> k线周期
+
+	dataHandle func(resp *wsV5Resp) error
+
+	symbolMapper *SymbolMapper
+}
+
+func newPublicWsV5(dataHandle func(resp *wsV5Resp) error) *publicWsV5 {
+	ws := &publicWsV5{
+		orderBooks: make(map[string]*orderbook.OrderBook),
+		klineBars:  make(map[string]KlinePeriod),
+		dataHandle: dataHandle,
+	}
+	ws.wsBuilder = NewWsBuilder().
+		WsUrl(v5WsPublicUrl).
+		ProxyUrl(os.Getenv("HTTPS_PROXY")).
+		AutoReconnect().
+		Heartbeat(func() []byte { return []byte("ping") }, 25*time.Second).
+		SubscribeRateLimit(wsSubscribeLimit, time.Hour).
+		ProtoHandleFunc(ws.handle)
+	ws.c = NewWsPool(ws.wsBuilder, wsMaxSubsPerConn)
+	return ws
+}
+
+//设置后使用交易规则把推送的instId映射回交易对，现货和合约的instId不会重名
+func (ws *publicWsV5) SetSymbolMapper(mapper *SymbolMapper) {
+	ws.symbolMapper = mapper
+}
+
+//频道+instId作为订阅的Key，订阅回应中的arg相同，同时用于关联回应
+func wsV5SubscriptionKey(arg map[string]string) string {
+	return arg["channel"] + ":" + arg["instId"]
+}
+
+func (ws *publicWsV5) subscribe(arg map[string]string) error {
+	key := wsV5SubscriptionKey(arg)
+	return ws.c.SubscribeWithId(key, key, wsV5Req{Op: "subscribe", Args: []interface{}{arg}})
+}
+
+func (ws *publicWsV5) unsubscribe(arg map[string]string) error {
+	return ws.c.Unsubscribe(wsV5SubscriptionKey(arg), wsV5Req{Op: "unsubscribe", Args: []interface{}{arg}})
+}
+
+func (ws *publicWsV5) unsubscribeBooks(instId string) error {
+	ws.lock.Lock()
+	if ob, ok := ws.orderBooks[instId]; ok {
+		ob.Close()
+		delete(ws.orderBooks, instId)
+	}
+	ws.lock.Unlock()
+
+	return ws.unsubscribe(map[string]string{"channel": "books", "instId": instId})
+}
+
+func (ws *publicWsV5) subscribeKline(instId string, period KlinePeriod) error {
+	channel := "candle" + adaptKLineBar(period)
+
+	ws.lock.Lock()
+	ws.klineBars[channel] = period
+	ws.lock.Unlock()
+
+	return ws.subscribe(map[string]string{"channel": channel, "instId": instId})
+}
+
+func (ws *publicWsV5) handle(msg []byte) error {
+	if string(msg) == "pong" {
+		return nil
+	}
+
+	var resp wsV5Resp
+	err := json.Unmarshal(msg, &resp)
+	if err != nil {
+		logger.Errorf("[okex] json unmarshal ws response error [%s] , response data = %s", err, string(msg))
+		return err
+	}
+
+	switch resp.Event {
+	case "error":
+		logger.Errorf("[okex] ws error , code=%s , msg=%s", resp.Code, resp.Msg)
+		if resp.Arg != nil {
+			ws.c.AckSubscription(wsV5SubscriptionKey(resp.Arg), adaptErrorCode(resp.Code, resp.Msg))
+		}
+		return nil
+	case "subscribe":
+		logger.Debugf("[okex] %s success: %v", resp.Event, resp.Arg)
+		ws.c.AckSubscription(wsV5SubscriptionKey(resp.Arg), nil)
+		return nil
+	case "unsubscribe":
+		logger.Debugf("[okex] %s success: %v", resp.Event, resp.Arg)
+		return nil
+	}
+
+	if len(resp.Data) == 0 {
+		logger.Warn("[okex] unknown ws response:", string(msg))
+		return nil
+	}
+
+	return ws.dataHandle(&resp)
+}
+
+//instId对应的本地订单簿，校验失败时重新订阅books频道获取全量快照
+func (ws *publicWsV5) getOrderBook(instId string) *orderbook.OrderBook {
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+
+	if ob, ok := ws.orderBooks[instId]; ok {
+		return ob
+	}
+
+	ob := orderbook.NewOrderBook(func() (*orderbook.Snapshot, error) {
+		arg := map[string]string{"channel": "books", "instId": instId}
+		c := ws.c.Conn(wsV5SubscriptionKey(arg))
+		if c == nil {
+			return nil, fmt.Errorf("[okex] %s books not subscribed", instId)
+		}
+		args := []interface{}{arg}
+		err := c.SendJsonMessage(wsV5Req{Op: "unsubscribe", Args: args})
+		if err != nil {
+			return nil, err
+		}
+		return nil, c.SendJsonMessage(wsV5Req{Op: "subscribe", Args: args})
+	})
+	ws.orderBooks[instId] = ob
+
+	return ob
+}
+
+type wsBooksV5 struct {
+	Asks      [][4]string `json:"asks"`
+	Bids      [][4]string `json:"bids"`
+	Timestamp int64       `json:"ts,string"`
+	Checksum  int64       `json:"checksum"`
+	SeqId     int64       `json:"seqId"`
+	PrevSeqId int64       `json:"prevSeqId"`
+}
+
+func adaptDepthLevels(items [][4]string) []orderbook.Level {
+	levels := make([]orderbook.Level, 0, len(items))
+	for _, itm := range items {
+		levels = append(levels, orderbook.Level{
+			Price:  itm[0],
+			Amount: itm[1],
+			Slots:  ToInt64(itm[3]),
+		})
+	}
+	return levels
+}
+
+//books频道: 快照+增量合并到本地订单簿并校验checksum，订单簿未就绪时返回nil
+func (ws *publicWsV5) handleBooks(resp *wsV5Resp) (*Depth, error) {
+	var books []wsBooksV5
+	err := json.Unmarshal(resp.Data, &books)
+	if err != nil {
+		return nil, err
+	}
+	if len(books) == 0 {
+		return nil, nil
+	}
+
+	instId := resp.Arg["instId"]
+	ob := ws.getOrderBook(instId)
+	if resp.Action == "snapshot" {
+		err = ob.Snapshot(&orderbook.Snapshot{
+			Asks:     adaptDepthLevels(books[0].Asks),
+			Bids:     adaptDepthLevels(books[0].Bids),
+			UpdateId: books[0].SeqId,
+			Checksum: books[0].Checksum,
+		})
+		if err != nil {
+			err = ob.Resync()
+		}
+	} else {
+		err = ob.Apply(&orderbook.Diff{
+			Asks:     adaptDepthLevels(books[0].Asks),
+			Bids:     adaptDepthLevels(books[0].Bids),
+			LastId:   books[0].SeqId,
+			PrevId:   books[0].PrevSeqId,
+			Checksum: books[0].Checksum,
+		})
+	}
+	if err != nil {
+		logger.Errorf("[okex] %s sync order book error: %s", instId, err)
+		return nil, err
+	}
+
+	if !ob.IsReady() {
+		return nil, nil
+	}
+
+	dep := ob.Depth(wsDepthSize)
+	dep.ContractId = instId
+	dep.Exchange = OKEX
+	dep.Action = resp.Action
+	dep.Timestamp = books[0].Timestamp
+	return dep, nil
+}
+
+func (ws *publicWsV5) parseKline(resp *wsV5Resp, pair CurrencyPair) ([]*FutureKline, KlinePeriod, error) {
+	var candles [][]string
+	err := json.Unmarshal(resp.Data, &candles)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ws.lock.Lock()
+	period := ws.klineBars[resp.Arg["channel"]]
+	ws.lock.Unlock()
+
+	klines := make([]*FutureKline, 0, len(candles))
+	for _, k := range candles {
+		if len(k) < 7 {
+			continue
+		}
+		klines = append(klines, &FutureKline{
+			Kline: &Kline{
+				Pair:      pair,
+				Timestamp: ToInt64(k[0]),
+				Open:      ToFloat64(k[1]),
+				High:      ToFloat64(k[2]),
+				Low:       ToFloat64(k[3]),
+				Close:     ToFloat64(k[4]),
+				Vol:       ToFloat64(k[5]),
+			},
+			Vol2: ToFloat64(k[6]),
+		})
+	}
+	return klines, period, nil
+}
+
+func adaptTradeV5(t TradeV5, pair CurrencyPair) *Trade {
+	side := BUY
+	if t.Side == "sell" {
+		side = SELL
+	}
+	return &Trade{
+		ContractId: t.InstId,
+		Tid:        ToInt64(t.TradeId),
+		Exchange:   OKEX,
+		Type:       side,
+		Amount:     t.Sz,
+		Price:      t.Px,
+		Date:       t.Ts,
+		Pair:       pair,
+	}
+}
+
+func adaptTickerV5(t TickerV5, pair CurrencyPair) *Ticker {
+	return &Ticker{
+		Pair: pair,
+		Last: t.Last,
+		Buy:  t.BuyPrice,
+		Sell: t.SellPrice,
+		High: t.High,
+		Low:  t.Low,
+		Vol:  t.Vol,
+		Date: t.Timestamp,
+	}
+}
+
+//现货公共频道: 行情 / 深度 / 成交 / k线
+type OKExV5SpotWs struct {
+	*publicWsV5
+
+	tickerCallback func(*Ticker)
+	depthCallback  func(*Depth)
+	tradeCallback  func(*Trade)
+	klineCallback  func(*Kline, KlinePeriod)
+}
+
+var (
+	_ SpotWsApi         = (*OKExV5SpotWs)(nil)
+	_ SymbolMapperAware = (*OKExV5SpotWs)(nil)
+)
+
+func NewOKExV5SpotWs() *OKExV5SpotWs {
+	ws := &OKExV5SpotWs{}
+	ws.publicWsV5 = newPublicWsV5(ws.handleData)
+	return ws
+}
+
+func (ws *OKExV5SpotWs) TickerCallback(f func(ticker *Ticker)) {
+	ws.tickerCallback = f
+}
+
+func (ws *OKExV5SpotWs) DepthCallback(f func(depth *Depth)) {
+	ws.depthCallback = f
+}
+
+func (ws *OKExV5SpotWs) TradeCallback(f func(trade *Trade)) {
+	ws.tradeCallback = f
+}
+
+func (ws *OKExV5SpotWs) KlineCallback(f func(kline *Kline, period KlinePeriod)) {
+	ws.klineCallback = f
+}
+
+func (ws *OKExV5SpotWs) SubscribeTicker(pair CurrencyPair) error {
+	if ws.tickerCallback == nil {
+		return errors.New("please set ticker callback func")
+	}
+	return ws.subscribe(map[string]string{"channel": "tickers", "instId": pair.ToSymbol("-")})
+}
+
+//400档深度，本地合并增量并校验checksum后推送前20档
+func (ws *OKExV5SpotWs) SubscribeDepth(pair CurrencyPair) error {
+	if ws.depthCallback == nil {
+		return errors.New("please set depth callback func")
+	}
+	return ws.subscribe(map[string]string{"channel": "books", "instId": pair.ToSymbol("-")})
+}
+
+func (ws *OKExV5SpotWs) SubscribeTrade(pair CurrencyPair) error {
+	if ws.tradeCallback == nil {
+		return errors.New("please set trade callback func")
+	}
+	return ws.subscribe(map[string]string{"channel": "trades", "instId": pair.ToSymbol("-")})
+}
+
+func (ws *OKExV5SpotWs) UnsubscribeTicker(pair CurrencyPair) error {
+	return ws.unsubscribe(map[string]string{"channel": "tickers", "instId": pair.ToSymbol("-")})
+}
+
+func (ws *OKExV5SpotWs) UnsubscribeDepth(pair CurrencyPair) error {
+	return ws.unsubscribeBooks(pair.ToSymbol("-"))
+}
+
+func (ws *OKExV5SpotWs) UnsubscribeTrade(pair CurrencyPair) error {
+	return ws.unsubscribe(map[string]string{"channel": "trades", "instId": pair.ToSymbol("-")})
+}
+
+func (ws *OKExV5SpotWs) SubscribeKline(pair CurrencyPair, period KlinePeriod) error {
+	if ws.klineCallback == nil {
+		return errors.New("please set kline callback func")
+	}
+	return ws.subscribeKline(pair.ToSymbol("-"), period)
+}
+
+func (ws *OKExV5SpotWs) handleData(resp *wsV5Resp) error {
+	channel := resp.Arg["channel"]
+	pair := ws.symbolMapper.PairOf(resp.Arg["instId"], adaptSpotInstId)
+
+	switch channel {
+	case "tickers":
+		var tickers []TickerV5
+		err := json.Unmarshal(resp.Data, &tickers)
+		if err != nil {
+			return err
+		}
+		for _, t := range tickers {
+			ws.tickerCallback(adaptTickerV5(t, pair))
+		}
+	case "books":
+		dep, err := ws.handleBooks(resp)
+		if err != nil || dep == nil {
+			return err
+		}
+		dep.Pair = pair
+		ws.depthCallback(dep)
+	case "trades":
+		var trades []TradeV5
+		err := json.Unmarshal(resp.Data, &trades)
+		if err != nil {
+			return err
+		}
+		for _, t := range trades {
+			ws.tradeCallback(adaptTradeV5(t, pair))
+		}
+	default:
+		if strings.HasPrefix(channel, "candle") {
+			klines, period, err := ws.parseKline(resp, pair)
+			if err != nil {
+				return err
+			}
+			for _, k := range klines {
+				ws.klineCallback(k.Kline, period)
+			}
+			return nil
+		}
+		logger.Warnf("[okex] unknown channel %s , data = %s", channel, string(resp.Data))
+	}
+	return nil
+}
+
+//永续合约公共频道: 行情 / 深度 / 成交 / k线 / 资金费率 / 标记价格
+type OKExV5SwapWs struct {
+	*publicWsV5
+
+	tickerCallback      func(*FutureTicker)
+	depthCallback       func(*Depth)
+	tradeCallback       func(*Trade, string)
+	klineCallback       func(*FutureKline, KlinePeriod)
+	fundingRateCallback func(*FundingRate)
+	markPriceCallback   func(*MarkPrice)
+}
+
+var (
+	_ FuturesWsApi      = (*OKExV5SwapWs)(nil)
+	_ SymbolMapperAware = (*OKExV5SwapWs)(nil)
+)
+
+func NewOKExV5SwapWs() *OKExV5SwapWs {
+	ws := &OKExV5SwapWs{}
+	ws.publicWsV5 = newPublicWsV5(ws.handleData)
+	return ws
+}
+
+func (ws *OKExV5SwapWs) TickerCallback(f func(ticker *FutureTicker)) {
+	ws.tickerCallback = f
+}
+
+func (ws *OKExV5SwapWs) DepthCallback(f func(depth *Depth)) {
+	ws.depthCallback = f
+}
+
+func (ws *OKExV5SwapWs) TradeCallback(f func(trade *Trade, contract string)) {
+	ws.tradeCallback = f
+}
+
+func (ws *OKExV5SwapWs) KlineCallback(f func(kline *FutureKline, period KlinePeriod)) {
+	ws.klineCallback = f
+}
+
+func (ws *OKExV5SwapWs) FundingRateCallback(f func(rate *FundingRate)) {
+	ws.fundingRateCallback = f
+}
+
+func (ws *OKExV5SwapWs) MarkPriceCallback(f func(price *MarkPrice)) {
+	ws.markPriceCallback = f
+}
+
+//只支持永续合约，contractType为SWAP_CONTRACT或SWAP_USDT_CONTRACT
+func (ws *OKExV5SwapWs) adaptInstId(pair CurrencyPair, contractType string) (string, error) {
+	switch contractType {
+	case SWAP_CONTRACT, SWAP_USDT_CONTRACT:
+		return fmt.Sprintf("%s-SWAP", pair.ToSymbol("-")), nil
+	}
+	return "", fmt.Errorf("[okex] unsupported contract type %s", contractType)
+}
+
+func (ws *OKExV5SwapWs) subscribeChannel(channel string, pair CurrencyPair, contractType string) error {
+	instId, err := ws.adaptInstId(pair, contractType)
+	if err != nil {
+		return err
+	}
+	return ws.subscribe(map[string]string{"channel": channel, "instId": instId})
+}
+
+func (ws *OKExV5SwapWs) unsubscribeChannel(channel string, pair CurrencyPair, contractType string) error {
+	instId, err := ws.adaptInstId(pair, contractType)
+	if err != nil {
+		return err
+	}
+	return ws.unsubscribe(map[string]string{"channel": channel, "instId": instId})
+}
+
+func (ws *OKExV5SwapWs) SubscribeTicker(pair CurrencyPair, contractType string) error {
+	if ws.tickerCallback == nil {
+		return errors.New("please set ticker callback func")
+	}
+	return ws.subscribeChannel("tickers", pair, contractType)
+}
+
+//400档深度，本地合并增量并校验checksum后推送前20档
+func (ws *OKExV5SwapWs) SubscribeDepth(pair CurrencyPair, contractType string) error {
+	if ws.depthCallback == nil {
+		return errors.New("please set depth callback func")
+	}
+	return ws.subscribeChannel("books", pair, contractType)
+}
+
+func (ws *OKExV5SwapWs) SubscribeTrade(pair CurrencyPair, contractType string) error {
+	if ws.tradeCallback == nil {
+		return errors.New("please set trade callback func")
+	}
+	return ws.subscribeChannel("trades", pair, contractType)
+}
+
+func (ws *OKExV5SwapWs) UnsubscribeTicker(pair CurrencyPair, contractType string) error {
+	return ws.unsubscribeChannel("tickers", pair, contractType)
+}
+
+func (ws *OKExV5SwapWs) UnsubscribeDepth(pair CurrencyPair, contractType string) error {
+	instId, err := ws.adaptInstId(pair, contractType)
+	if err != nil {
+		return err
+	}
+	return ws.unsubscribeBooks(instId)
+}
+
+func (ws *OKExV5SwapWs) UnsubscribeTrade(pair CurrencyPair, contractType string) error {
+	return ws.unsubscribeChannel("trades", pair, contractType)
+}
+
+func (ws *OKExV5SwapWs) SubscribeKline(pair CurrencyPair, contractType string, period KlinePeriod) error {
+	if ws.klineCallback == nil {
+		return errors.New("please set kline callback func")
+	}
+	instId, err := ws.adaptInstId(pair, contractType)
+	if err != nil {
+		return err
+	}
+	return ws.subscribeKline(instId, period)
+}
+
+func (ws *OKExV5SwapWs) SubscribeFundingRate(pair CurrencyPair, contractType string) error {
+	if ws.fundingRateCallback == nil {
+		return errors.New("please set funding rate callback func")
+	}
+	return ws.subscribeChannel("funding-rate", pair, contractType)
+}
+
+func (ws *OKExV5SwapWs) SubscribeMarkPrice(pair CurrencyPair, contractType string) error {
+	if ws.markPriceCallback == nil {
+		return errors.New("please set mark price callback func")
+	}
+	return ws.subscribeChannel("mark-price", pair, contractType)
+}
+
+func (ws *OKExV5SwapWs) handleData(resp *wsV5Resp) error {
+	channel := resp.Arg["channel"]
+	instId := resp.Arg["instId"]
+	pair := ws.symbolMapper.PairOf(instId, adaptSwapInstId)
+
+	switch channel {
+	case "tickers":
+		var tickers []TickerV5
+		err := json.Unmarshal(resp.Data, &tickers)
+		if err != nil {
+			return err
+		}
+		for _, t := range tickers {
+			ws.tickerCallback(&FutureTicker{
+				Ticker:       adaptTickerV5(t, pair),
+				ContractType: SWAP_CONTRACT,
+				ContractId:   t.InstId,
+				Exchange:     OKEX_SWAP,
+			})
+		}
+	case "books":
+		dep, err := ws.handleBooks(resp)
+		if err != nil || dep == nil {
+			return err
+		}
+		dep.Pair = pair
+		dep.ContractType = SWAP_CONTRACT
+		dep.Exchange = OKEX_SWAP
+		ws.depthCallback(dep)
+	case "trades":
+		var trades []TradeV5
+		err := json.Unmarshal(resp.Data, &trades)
+		if err != nil {
+			return err
+		}
+		for _, t := range trades {
+			trade := adaptTradeV5(t, pair)
+			trade.ContractType = SWAP_CONTRACT
+			trade.Exchange = OKEX_SWAP
+			ws.tradeCallback(trade, SWAP_CONTRACT)
+		}
+	case "funding-rate":
+		var rates []struct {
+			InstId          string  `json:"instId"`
+			FundingRate     float64 `json:"fundingRate,string"`
+			NextFundingRate string  `json:"nextFundingRate"`
+			FundingTime     int64   `json:"fundingTime,string"`
+			NextFundingTime string  `json:"nextFundingTime"`
+		}
+		err := json.Unmarshal(resp.Data, &rates)
+		if err != nil {
+			return err
+		}
+		for _, r := range rates {
+			ws.fundingRateCallback(&FundingRate{
+				Pair:            pair,
+				ContractType:    SWAP_CONTRACT,
+				Rate:            r.FundingRate,
+				NextRate:        ToFloat64(r.NextFundingRate),
+				FundingTime:     r.FundingTime,
+				NextFundingTime: ToInt64(r.NextFundingTime),
+			})
+		}
+	case "mark-price":
+		var prices []struct {
+			InstId string  `json:"instId"`
+			MarkPx float64 `json:"markPx,string"`
+			Ts     int64   `json:"ts,string"`
+		}
+		err := json.Unmarshal(resp.Data, &prices)
+		if err != nil {
+			return err
+		}
+		for _, p := range prices {
+			ws.markPriceCallback(&MarkPrice{
+				Pair:         pair,
+				ContractType: SWAP_CONTRACT,
+				MarkPrice:    p.MarkPx,
+				Timestamp:    p.Ts,
+			})
+		}
+	default:
+		if strings.HasPrefix(channel, "candle") {
+			klines, period, err := ws.parseKline(resp, pair)
+			if err != nil {
+				return err
+			}
+			for _, k := range klines {
+				ws.klineCallback(k, period)
+			}
+			return nil
+		}
+		logger.Warnf("[okex] unknown channel %s , data = %s", channel, string(resp.Data))
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package okex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
+)
+
+//回放testdata/swap_ws.json中的推送，books频道快照+增量并校验checksum
+func TestOKExV5SwapWs(t *testing.T) {
+	wsSrv := replay.NewWsServer(t, "testdata/swap_ws.json", v5WsPublicUrl)
+	wsSrv.SendAfter = 3
+
+	ws := NewOKExV5SwapWs()
+	ws.wsBuilder.WsUrl(wsSrv.URL)
+
+	tickers := make(chan *goex.FutureTicker, 1)
+	depths := make(chan *goex.Depth, 2)
+	rates := make(chan *goex.FundingRate, 1)
+	ws.TickerCallback(func(ticker *goex.FutureTicker) {
+		tickers <- ticker
+	})
+	ws.DepthCallback(func(depth *goex.Depth) {
+		depths <- depth
+	})
+	ws.FundingRateCallback(func(rate *goex.FundingRate) {
+		rates <- rate
+	})
+
+	if err := ws.SubscribeTicker(goex.BTC_USDT, goex.SWAP_USDT_CONTRACT); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SubscribeDepth(goex.BTC_USDT, goex.SWAP_USDT_CONTRACT); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SubscribeFundingRate(goex.BTC_USDT, goex.SWAP_USDT_CONTRACT); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.SubscribeTicker(goex.BTC_USDT, goex.QUARTER_CONTRACT); err == nil {
+		t.Fatal("expect unsupported contract type error")
+	}
+
+	select {
+	case ticker := <-tickers:
+		replay.AssertGolden(t, "testdata/swap_ws_ticker.golden.json", ticker)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ticker timeout")
+	}
+
+	var depth *goex.Depth
+	for i := 0; i < 2; i++ {
+		select {
+		case depth = <-depths:
+		case <-time.After(5 * time.Second):
+			t.Fatal("depth timeout")
+		}
+	}
+	replay.AssertGolden(t, "testdata/swap_ws_depth.golden.json", depth)
+
+	select {
+	case rate := <-rates:
+		replay.AssertGolden(t, "testdata/swap_ws_funding_rate.golden.json", rate)
+	case <-time.After(5 * time.Second):
+		t.Fatal("funding rate timeout")
+	}
+
+	//订阅回应按频道+instId关联到订阅
+	for _, sub := range ws.c.Subscriptions() {
+		if !sub.Acked {
+			t.Fatalf("%+v", sub)
+		}
+	}
+
+	//重复订阅不再发送，取消订阅后从订阅列表移除
+	if err := ws.SubscribeTicker(goex.BTC_USDT, goex.SWAP_USDT_CONTRACT); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.UnsubscribeDepth(goex.BTC_USDT, goex.SWAP_USDT_CONTRACT); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.UnsubscribeTrade(goex.BTC_USDT, goex.SWAP_USDT_CONTRACT); err != nil {
+		t.Fatal(err)
+	}
+	if subs := ws.c.Subscriptions(); len(subs) != 2 || subs[0].Key != "tickers:BTC-USDT-SWAP" || subs[1].Key != "funding-rate:BTC-USDT-SWAP" {
+		t.Fatalf("%+v", subs)
+	}
+
+	for i := 0; len(wsSrv.Received()) < 4; i++ {
+		if i > 100 {
+			t.Fatal("unsubscribe timeout")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	received := wsSrv.Received()
+	if unsub := string(received[3]); len(received) != 4 || unsub != `{"op":"unsubscribe","args":[{"channel":"books","instId":"BTC-USDT-SWAP"}]}` {
+		t.Fatal(unsub)
+	}
+}
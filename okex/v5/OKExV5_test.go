@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 func newOKExV5Client() *OKExV5 {
@@ -27,16 +28,19 @@ func newOKExV5Client() *OKExV5 {
 }
 
 func TestOKExV5_GetTicker(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	o := newOKExV5Client()
 	fmt.Println(o.GetTickerV5("BTC-USD-SWAP"))
 }
 
 func TestOKExV5_GetDepth(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	o := newOKExV5Client()
 	fmt.Println(o.GetDepthV5("BTC-USD-SWAP", 0))
 }
 
 func TestOKExV5_GetKlineRecordsV5(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	o := newOKExV5Client()
 	fmt.Println(o.GetKlineRecordsV5("BTC-USD-SWAP", goex.KLINE_PERIOD_1H, &url.Values{}))
 
@@ -0,0 +1,417 @@
+package orderbook
+
+import (
+	"errors"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/logger"
+)
+
+var (
+	ErrNotReady    = errors.New("orderbook: snapshot not ready")
+	ErrSequenceGap = errors.New("orderbook: update id gap")
+	ErrChecksum    = errors.New("orderbook: checksum mismatch")
+)
+
+//checksum计算的档位数(okex)
+const checksumDepth = 25
+
+const (
+	maxPendingDiffs    = 1000 //等待快照期间缓存的增量数，超出后丢弃最早的
+	minSnapshotBackoff = 500 * time.Millisecond
+	maxSnapshotBackoff = 30 * time.Second
+)
+
+//价格档位，价格/数量保留交易所推送的原始字符串，用于checksum计算
+type Level struct {
+	Price  string
+	Amount string
+	Slots  int64 //订单数，okex
+}
+
+type level struct {
+	price  float64
+	amount float64
+	Level
+}
+
+//全量快照
+type Snapshot struct {
+	Asks     []Level
+	Bids     []Level
+	UpdateId int64 //binance lastUpdateId , okex seqId
+	Checksum int64 //0表示不校验
+}
+
+//增量更新
+//binance: FirstId=U , LastId=u , PrevId=pu(仅合约)
+//okex: LastId=seqId , PrevId=prevSeqId
+type Diff struct {
+	Asks     []Level
+	Bids     []Level
+	FirstId  int64
+	LastId   int64
+	PrevId   int64
+	Checksum int64 //0表示不校验
+}
+
+//获取全量快照，返回nil表示快照会通过推送(如重新订阅)到达
+type SnapshotFunc func() (*Snapshot, error)
+
+//本地订单簿，按价格数值排序，支持快照+增量更新，序号不连续或checksum校验失败时自动重新同步
+//快照在后台goroutine中获取，失败时指数退避重试，期间的增量缓存下来，快照到达后再应用
+type OrderBook struct {
+	lock         sync.RWMutex
+	asks         []level //价格升序
+	bids         []level //价格降序
+	lastUpdateId int64
+	ready        bool
+	synced       bool //快照后是否已应用过增量
+	waiting      bool //等待通过推送到达的快照
+	fetching     bool //后台正在获取快照
+	pending      []*Diff
+	closed       chan struct{}
+	closeOnce    sync.Once
+
+	snapshotFn SnapshotFunc
+	readyFn    func()
+}
+
+func NewOrderBook(snapshotFn SnapshotFunc) *OrderBook {
+	return &OrderBook{snapshotFn: snapshotFn, closed: make(chan struct{})}
+}
+
+//后台同步快照(包括缓存的增量)完成后回调，在后台goroutine中调用
+func (ob *OrderBook) ReadyCallback(f func()) {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+	ob.readyFn = f
+}
+
+//停止后台的快照获取，订单簿不再使用时调用
+func (ob *OrderBook) Close() {
+	ob.closeOnce.Do(func() {
+		close(ob.closed)
+	})
+}
+
+func (ob *OrderBook) IsReady() bool {
+	ob.lock.RLock()
+	defer ob.lock.RUnlock()
+	return ob.ready
+}
+
+func (ob *OrderBook) LastUpdateId() int64 {
+	ob.lock.RLock()
+	defer ob.lock.RUnlock()
+	return ob.lastUpdateId
+}
+
+//清空订单簿，等待新的快照
+func (ob *OrderBook) Reset() {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+	ob.reset()
+}
+
+func (ob *OrderBook) reset() {
+	ob.asks = ob.asks[:0]
+	ob.bids = ob.bids[:0]
+	ob.lastUpdateId = 0
+	ob.ready = false
+	ob.synced = false
+	ob.waiting = false
+	ob.pending = nil
+}
+
+//应用全量快照
+func (ob *OrderBook) Snapshot(s *Snapshot) error {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+	return ob.snapshot(s)
+}
+
+func (ob *OrderBook) snapshot(s *Snapshot) error {
+	ob.reset()
+	for _, l := range s.Asks {
+		ob.upsert(true, l)
+	}
+	for _, l := range s.Bids {
+		ob.upsert(false, l)
+	}
+	ob.lastUpdateId = s.UpdateId
+
+	if s.Checksum != 0 && int64(ob.checksum()) != s.Checksum {
+		ob.reset()
+		return ErrChecksum
+	}
+
+	ob.ready = true
+
+	return nil
+}
+
+//应用增量更新，amount为0表示删除该档位；返回ErrSequenceGap/ErrChecksum时订单簿已被清空
+func (ob *OrderBook) Update(d *Diff) error {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+	return ob.update(d)
+}
+
+func (ob *OrderBook) update(d *Diff) error {
+	if !ob.ready {
+		return ErrNotReady
+	}
+
+	if ob.lastUpdateId > 0 {
+		//过期的增量
+		if d.LastId > 0 && d.LastId <= ob.lastUpdateId {
+			return nil
+		}
+
+		gap := false
+		if !ob.synced && d.FirstId > 0 {
+			gap = d.FirstId > ob.lastUpdateId+1
+		} else if d.PrevId > 0 {
+			gap = d.PrevId != ob.lastUpdateId
+		} else if d.FirstId > 0 {
+			gap = d.FirstId != ob.lastUpdateId+1
+		}
+
+		if gap {
+			logger.Warnf("[orderbook] update id gap , last update id=%d , first id=%d , prev id=%d", ob.lastUpdateId, d.FirstId, d.PrevId)
+			ob.reset()
+			return ErrSequenceGap
+		}
+	}
+
+	for _, l := range d.Asks {
+		ob.upsert(true, l)
+	}
+	for _, l := range d.Bids {
+		ob.upsert(false, l)
+	}
+
+	if d.LastId > 0 {
+		ob.lastUpdateId = d.LastId
+	}
+	ob.synced = true
+
+	if d.Checksum != 0 && int64(ob.checksum()) != d.Checksum {
+		logger.Warnf("[orderbook] checksum mismatch , expect=%d , local=%d", d.Checksum, ob.checksum())
+		ob.reset()
+		return ErrChecksum
+	}
+
+	return nil
+}
+
+//应用增量更新，订单簿未就绪、序号不连续或checksum校验失败时在后台重新同步快照，
+//同步期间的增量被缓存，快照到达后按顺序应用；不会阻塞调用方(ws读goroutine)
+func (ob *OrderBook) Apply(d *Diff) error {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	err := ob.update(d)
+	switch err {
+	case ErrNotReady, ErrSequenceGap, ErrChecksum:
+		if ob.snapshotFn == nil || ob.waiting {
+			return nil
+		}
+	default:
+		return err
+	}
+
+	ob.pending = append(ob.pending, d)
+	if len(ob.pending) > maxPendingDiffs {
+		ob.pending = ob.pending[len(ob.pending)-maxPendingDiffs:]
+	}
+	ob.fetchSnapshot()
+
+	return nil
+}
+
+//清空订单簿并在后台重新获取全量快照
+func (ob *OrderBook) Resync() error {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	ob.reset()
+	if ob.snapshotFn != nil {
+		ob.fetchSnapshot()
+	}
+
+	return nil
+}
+
+//需持有锁，同一时间只有一个获取快照的goroutine
+func (ob *OrderBook) fetchSnapshot() {
+	if ob.fetching {
+		return
+	}
+	ob.fetching = true
+	go ob.syncSnapshot()
+}
+
+func (ob *OrderBook) syncSnapshot() {
+	backoff := minSnapshotBackoff
+	for {
+		s, err := ob.snapshotFn()
+		if err == nil && ob.applySnapshot(s) {
+			ob.lock.RLock()
+			readyFn, ready := ob.readyFn, ob.ready
+			ob.lock.RUnlock()
+			if readyFn != nil && ready {
+				readyFn()
+			}
+			return
+		}
+
+		if err != nil {
+			logger.Errorf("[orderbook] resync snapshot error: %s , retry after %s", err.Error(), backoff)
+		}
+
+		select {
+		case <-ob.closed:
+			ob.lock.Lock()
+			ob.fetching = false
+			ob.lock.Unlock()
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxSnapshotBackoff {
+			backoff = maxSnapshotBackoff
+		}
+	}
+}
+
+//应用获取到的快照和缓存的增量，返回false表示需要重新获取
+func (ob *OrderBook) applySnapshot(s *Snapshot) bool {
+	ob.lock.Lock()
+	defer ob.lock.Unlock()
+
+	if s == nil {
+		ob.fetching = false
+		if !ob.ready { //推送的快照可能已经先到达
+			ob.pending = nil
+			ob.waiting = true //快照通过推送到达前忽略增量
+		}
+		return true
+	}
+
+	pending := ob.pending
+	if err := ob.snapshot(s); err != nil {
+		logger.Errorf("[orderbook] apply snapshot error: %s", err.Error())
+		ob.pending = pending
+		return false
+	}
+
+	//快照之后的增量继续应用，过期的增量会被忽略
+	for _, d := range pending {
+		if err := ob.update(d); err != nil {
+			return false
+		}
+	}
+	ob.fetching = false
+
+	return true
+}
+
+//买卖各n档深度，n<=0时返回全部档位；AskList/BidList均按价格降序
+func (ob *OrderBook) Depth(n int) *goex.Depth {
+	ob.lock.RLock()
+	defer ob.lock.RUnlock()
+
+	asks, bids := len(ob.asks), len(ob.bids)
+	if n > 0 && asks > n {
+		asks = n
+	}
+	if n > 0 && bids > n {
+		bids = n
+	}
+
+	dep := &goex.Depth{
+		AskList: make(goex.DepthRecords, 0, asks),
+		BidList: make(goex.DepthRecords, 0, bids),
+	}
+	for i := asks - 1; i >= 0; i-- {
+		dep.AskList = append(dep.AskList, ob.asks[i].record())
+	}
+	for i := 0; i < bids; i++ {
+		dep.BidList = append(dep.BidList, ob.bids[i].record())
+	}
+
+	return dep
+}
+
+//okex crc32校验和: 买卖前25档 bid1Px:bid1Sz:ask1Px:ask1Sz:bid2Px...
+func (ob *OrderBook) Checksum() int32 {
+	ob.lock.RLock()
+	defer ob.lock.RUnlock()
+	return ob.checksum()
+}
+
+func (ob *OrderBook) checksum() int32 {
+	fields := make([]string, 0, checksumDepth*4)
+	for i := 0; i < checksumDepth; i++ {
+		if i < len(ob.bids) {
+			fields = append(fields, ob.bids[i].Price, ob.bids[i].Amount)
+		}
+		if i < len(ob.asks) {
+			fields = append(fields, ob.asks[i].Price, ob.asks[i].Amount)
+		}
+	}
+	return int32(crc32.ChecksumIEEE([]byte(strings.Join(fields, ":"))))
+}
+
+func (ob *OrderBook) upsert(isAsk bool, l Level) {
+	price, err := strconv.ParseFloat(l.Price, 64)
+	if err != nil {
+		logger.Errorf("[orderbook] parse price error: %s", l.Price)
+		return
+	}
+	amount, _ := strconv.ParseFloat(l.Amount, 64)
+
+	book := ob.bids
+	if isAsk {
+		book = ob.asks
+	}
+
+	i := sort.Search(len(book), func(i int) bool {
+		if isAsk {
+			return book[i].price >= price
+		}
+		return book[i].price <= price
+	})
+
+	found := i < len(book) && book[i].price == price
+	switch {
+	case amount == 0 && found:
+		book = append(book[:i], book[i+1:]...)
+	case amount == 0:
+	case found:
+		book[i] = level{price: price, amount: amount, Level: l}
+	default:
+		book = append(book, level{})
+		copy(book[i+1:], book[i:])
+		book[i] = level{price: price, amount: amount, Level: l}
+	}
+
+	if isAsk {
+		ob.asks = book
+	} else {
+		ob.bids = book
+	}
+}
+
+func (l level) record() goex.DepthRecord {
+	return goex.DepthRecord{Price: l.price, Amount: l.amount, Slots: l.Slots}
+}
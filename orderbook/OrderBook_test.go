@@ -0,0 +1,127 @@
+package orderbook
+
+import (
+	"errors"
+	"hash/crc32"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitReady(t *testing.T, ob *OrderBook) {
+	for i := 0; i < 200 && !ob.IsReady(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !ob.IsReady() {
+		t.Fatal("order book not ready")
+	}
+}
+
+func TestOrderBook_Apply(t *testing.T) {
+	var snapshots int32
+	ob := NewOrderBook(func() (*Snapshot, error) {
+		n := atomic.AddInt32(&snapshots, 1)
+		return &Snapshot{
+			Asks:     []Level{{Price: "10000", Amount: "1"}, {Price: "9000", Amount: "2"}},
+			Bids:     []Level{{Price: "8000", Amount: "3"}, {Price: "800", Amount: "4"}},
+			UpdateId: int64(100 * n),
+		}, nil
+	})
+	defer ob.Close()
+
+	//未就绪时在后台获取快照，过期的增量被忽略
+	if err := ob.Apply(&Diff{FirstId: 90, LastId: 95}); err != nil {
+		t.Fatal(err)
+	}
+	waitReady(t, ob)
+	if atomic.LoadInt32(&snapshots) != 1 {
+		t.Fatal(snapshots)
+	}
+
+	err := ob.Apply(&Diff{
+		FirstId: 96,
+		LastId:  102,
+		Asks:    []Level{{Price: "9000", Amount: "0"}, {Price: "9500", Amount: "5"}},
+		Bids:    []Level{{Price: "8500", Amount: "1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dep := ob.Depth(2)
+	if len(dep.AskList) != 2 || dep.AskList[0].Price != 10000 || dep.AskList[1].Price != 9500 {
+		t.Fatalf("unexpected asks: %v", dep.AskList)
+	}
+	if len(dep.BidList) != 2 || dep.BidList[0].Price != 8500 || dep.BidList[1].Price != 8000 {
+		t.Fatalf("unexpected bids: %v", dep.BidList)
+	}
+
+	//序号不连续，重新同步后继续应用缓存的增量
+	if err := ob.Apply(&Diff{FirstId: 105, LastId: 201, Bids: []Level{{Price: "8600", Amount: "1"}}}); err != nil {
+		t.Fatal(err)
+	}
+	waitReady(t, ob)
+	if atomic.LoadInt32(&snapshots) != 2 {
+		t.Fatal(snapshots)
+	}
+	if ob.LastUpdateId() != 201 || ob.Depth(1).BidList[0].Price != 8600 {
+		t.Fatal(ob.LastUpdateId(), ob.Depth(1))
+	}
+}
+
+func TestOrderBook_Checksum(t *testing.T) {
+	ob := NewOrderBook(nil)
+	err := ob.Snapshot(&Snapshot{
+		Asks:     []Level{{Price: "3366.8", Amount: "9"}, {Price: "3368", Amount: "8"}},
+		Bids:     []Level{{Price: "3366.1", Amount: "7"}, {Price: "3366", Amount: "6"}},
+		UpdateId: 10,
+		Checksum: int64(int32(crc32.ChecksumIEEE([]byte("3366.1:7:3366.8:9:3366:6:3368:8")))),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ob.Apply(&Diff{PrevId: 10, LastId: 11, Checksum: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if ob.IsReady() {
+		t.Fatal("checksum mismatch should reset the book")
+	}
+
+	//等待推送的快照，忽略增量
+	if err := ob.Apply(&Diff{PrevId: 11, LastId: 12}); err != nil || ob.IsReady() {
+		t.Fatal(err)
+	}
+}
+
+func TestOrderBook_SnapshotBackoff(t *testing.T) {
+	var calls int32
+	ob := NewOrderBook(func() (*Snapshot, error) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return nil, errors.New("rest unavailable")
+		}
+		return &Snapshot{Bids: []Level{{Price: "100", Amount: "1"}}, UpdateId: 10}, nil
+	})
+	defer ob.Close()
+
+	//快照失败期间增量只缓存不触发新的请求，也不阻塞调用方
+	start := time.Now()
+	for i := int64(0); i < 50; i++ {
+		if err := ob.Apply(&Diff{FirstId: 9 + i, LastId: 9 + i, Bids: []Level{{Price: "101", Amount: "1"}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if time.Since(start) > 100*time.Millisecond {
+		t.Fatal("apply blocked on snapshot")
+	}
+
+	for i := 0; i < 300 && !ob.IsReady(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !ob.IsReady() || atomic.LoadInt32(&calls) != 3 {
+		t.Fatal(ob.IsReady(), calls)
+	}
+	if ob.LastUpdateId() != 58 || ob.Depth(1).BidList[0].Price != 101 {
+		t.Fatal(ob.LastUpdateId(), ob.Depth(1))
+	}
+}
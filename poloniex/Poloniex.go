@@ -30,6 +30,10 @@ type Poloniex struct {
 	client *http.Client
 }
 
+var (
+	_ API = (*Poloniex)(nil)
+)
+
 func New(client *http.Client, accessKey, secretKey string) *Poloniex {
 	return &Poloniex{accessKey, secretKey, client}
 }
@@ -0,0 +1,142 @@
+package simexchange
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	. "github.com/mrwill84/goex"
+)
+
+//行情记录文件的一行
+type feedEvent struct {
+	Type   string  `json:"type"` //depth , ticker , trade
+	Depth  *Depth  `json:"depth,omitempty"`
+	Ticker *Ticker `json:"ticker,omitempty"`
+	Trade  *Trade  `json:"trade,omitempty"`
+}
+
+//将SpotWsApi推送的行情按行写入文件，供FileFeed回放:
+//ws.DepthCallback(recorder.Depth) ; ws.TradeCallback(recorder.Trade)
+type FeedRecorder struct {
+	lock sync.Mutex
+	enc  *json.Encoder
+}
+
+func NewFeedRecorder(w io.Writer) *FeedRecorder {
+	return &FeedRecorder{enc: json.NewEncoder(w)}
+}
+
+func (r *FeedRecorder) write(e *feedEvent) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.enc.Encode(e)
+}
+
+func (r *FeedRecorder) Depth(depth *Depth) {
+	r.write(&feedEvent{Type: "depth", Depth: depth})
+}
+
+func (r *FeedRecorder) Ticker(ticker *Ticker) {
+	r.write(&feedEvent{Type: "ticker", Ticker: ticker})
+}
+
+func (r *FeedRecorder) Trade(trade *Trade) {
+	r.write(&feedEvent{Type: "trade", Trade: trade})
+}
+
+//回放FeedRecorder记录的行情，实现goex.SpotWsApi , 订阅后调用Replay按顺序同步推送
+type FileFeed struct {
+	filename string
+
+	depthCallback  func(depth *Depth)
+	tickerCallback func(ticker *Ticker)
+	tradeCallback  func(trade *Trade)
+
+	depthPairs  map[string]bool
+	tickerPairs map[string]bool
+	tradePairs  map[string]bool
+}
+
+func NewFileFeed(filename string) *FileFeed {
+	return &FileFeed{
+		filename:    filename,
+		depthPairs:  make(map[string]bool),
+		tickerPairs: make(map[string]bool),
+		tradePairs:  make(map[string]bool),
+	}
+}
+
+func (f *FileFeed) DepthCallback(call func(depth *Depth)) {
+	f.depthCallback = call
+}
+
+func (f *FileFeed) TickerCallback(call func(ticker *Ticker)) {
+	f.tickerCallback = call
+}
+
+func (f *FileFeed) TradeCallback(call func(trade *Trade)) {
+	f.tradeCallback = call
+}
+
+func (f *FileFeed) SubscribeDepth(pair CurrencyPair) error {
+	f.depthPairs[pairKey(pair)] = true
+	return nil
+}
+
+func (f *FileFeed) SubscribeTicker(pair CurrencyPair) error {
+	f.tickerPairs[pairKey(pair)] = true
+	return nil
+}
+
+func (f *FileFeed) SubscribeTrade(pair CurrencyPair) error {
+	f.tradePairs[pairKey(pair)] = true
+	return nil
+}
+
+func (f *FileFeed) UnsubscribeDepth(pair CurrencyPair) error {
+	delete(f.depthPairs, pairKey(pair))
+	return nil
+}
+
+func (f *FileFeed) UnsubscribeTicker(pair CurrencyPair) error {
+	delete(f.tickerPairs, pairKey(pair))
+	return nil
+}
+
+func (f *FileFeed) UnsubscribeTrade(pair CurrencyPair) error {
+	delete(f.tradePairs, pairKey(pair))
+	return nil
+}
+
+//读取整个文件并推送已订阅交易对的行情
+func (f *FileFeed) Replay() error {
+	file, err := os.Open(f.filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		var e feedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return err
+		}
+
+		switch {
+		case e.Depth != nil && f.depthCallback != nil && f.depthPairs[pairKey(e.Depth.Pair)]:
+			f.depthCallback(e.Depth)
+		case e.Ticker != nil && f.tickerCallback != nil && f.tickerPairs[pairKey(e.Ticker.Pair)]:
+			f.tickerCallback(e.Ticker)
+		case e.Trade != nil && f.tradeCallback != nil && f.tradePairs[pairKey(e.Trade.Pair)]:
+			f.tradeCallback(e.Trade)
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,697 @@
+package simexchange
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/mrwill84/goex"
+	"github.com/mrwill84/goex/internal/logger"
+)
+
+//模拟交易所配置
+type Config struct {
+	Name        string               //GetExchangeName返回值，默认simexchange
+	Balances    map[Currency]float64 //初始余额
+	TakerFee    float64              //吃单手续费率，如0.001
+	MakerFee    float64              //挂单手续费率
+	ContractVal float64              //合约面值(币)，默认1 , 合约为U本位线性合约，保证金为计价币
+	Lever       float64              //默认杠杆倍数，默认1
+	DepthOnly   bool                 //Attach时不订阅成交，挂单只按深度撮合
+}
+
+//内存撮合的模拟交易所，实现goex.API , Future()返回goex.FutureRestAPI的实现:
+//行情由UpdateDepth/UpdateTicker/UpdateTrade推送(可以通过Attach对接任意SpotWsApi或FileFeed)，
+//吃单按当前深度成交并消耗深度，挂单在深度或成交价穿过委托价时按委托价成交;
+//被模拟订单消耗的深度在之后的快照中扣除，直到该档位数量减少或消失，同一档位的流动性不会被重复成交;
+//现货和合约共用一个资金账户，合约不计算资金费率和强平
+type SimExchange struct {
+	config *Config
+
+	lock    sync.Mutex
+	seq     int64
+	now     int64 //最新行情时间(ms)
+	wallet  map[string]*balance
+	markets map[string]*market
+	orders  map[string]*simOrder
+	cids    map[string]*simOrder
+
+	positions map[string]*position
+}
+
+type balance struct {
+	available float64
+	frozen    float64
+}
+
+type market struct {
+	pair   CurrencyPair
+	asks   DepthRecords //价格升序，已扣除被模拟订单消耗的数量
+	bids   DepthRecords //价格降序
+	ticker *Ticker
+	trades []Trade
+	open   []*simOrder //未完成的订单
+
+	askConsumed map[float64]*consumed //价格 -> 被消耗的卖盘数量
+	bidConsumed map[float64]*consumed
+}
+
+//深度档位上被模拟订单消耗的数量，交易所的快照不包含模拟成交
+type consumed struct {
+	raw   float64 //最近一次快照中该档位的数量
+	taken float64
+}
+
+type simOrder struct {
+	id           string
+	cid          string
+	pair         CurrencyPair
+	contractType string //现货为空
+	side         TradeSide
+	oType        int //合约 OPEN_BUY/OPEN_SELL/CLOSE_BUY/CLOSE_SELL
+	orderType    int //0:普通 1:post only 2:fok 3:ioc
+	market       bool
+	price        float64
+	amount       float64
+	dealAmount   float64
+	avgPrice     float64
+	fee          float64
+	profit       float64
+	leverRate    float64
+	frozen       float64 //剩余冻结的资金(现货买/合约开仓)或币(现货卖)
+	status       TradeStatus
+	createTime   int64
+	finishedTime int64
+}
+
+const maxTrades = 200
+
+func New(config *Config) *SimExchange {
+	if config.Name == "" {
+		config.Name = "simexchange"
+	}
+	if config.ContractVal <= 0 {
+		config.ContractVal = 1
+	}
+	if config.Lever <= 0 {
+		config.Lever = 1
+	}
+
+	sim := &SimExchange{
+		config:    config,
+		wallet:    make(map[string]*balance),
+		markets:   make(map[string]*market),
+		orders:    make(map[string]*simOrder),
+		cids:      make(map[string]*simOrder),
+		positions: make(map[string]*position),
+	}
+	for currency, amount := range config.Balances {
+		sim.balance(currency).available = amount
+	}
+
+	return sim
+}
+
+//对接行情源，订阅pairs的深度、ticker和成交；会覆盖feed原有的回调
+//行情源不支持成交推送(订阅返回错误)时只按深度撮合
+func (sim *SimExchange) Attach(feed SpotWsApi, pairs ...CurrencyPair) error {
+	feed.DepthCallback(sim.UpdateDepth)
+	feed.TickerCallback(sim.UpdateTicker)
+	if !sim.config.DepthOnly {
+		feed.TradeCallback(sim.UpdateTrade)
+	}
+
+	for _, pair := range pairs {
+		if err := feed.SubscribeDepth(pair); err != nil {
+			return err
+		}
+		if err := feed.SubscribeTicker(pair); err != nil {
+			return err
+		}
+		if sim.config.DepthOnly {
+			continue
+		}
+		if err := feed.SubscribeTrade(pair); err != nil {
+			logger.Warnf("[%s] subscribe %s trade error: %s , match orders by depth only", sim.config.Name, pair, err.Error())
+		}
+	}
+
+	return nil
+}
+
+//更新深度快照，并撮合价格穿过深度的挂单
+func (sim *SimExchange) UpdateDepth(depth *Depth) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+
+	sim.setNow(depth.Timestamp)
+
+	m := sim.market(depth.Pair)
+	asks := append(DepthRecords{}, depth.AskList...)
+	bids := append(DepthRecords{}, depth.BidList...)
+	sort.Sort(asks)
+	sort.Sort(sort.Reverse(bids))
+	m.asks = deductConsumed(asks, m.askConsumed)
+	m.bids = deductConsumed(bids, m.bidConsumed)
+
+	for _, o := range append([]*simOrder{}, m.open...) {
+		if o.side == BUY {
+			sim.takeLevels(m, o, &m.asks, o.remain(), true)
+		} else {
+			sim.takeLevels(m, o, &m.bids, o.remain(), true)
+		}
+		sim.finishIfDone(m, o)
+	}
+}
+
+func (sim *SimExchange) UpdateTicker(ticker *Ticker) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+
+	sim.setNow(int64(ticker.Date))
+	t := *ticker
+	sim.market(ticker.Pair).ticker = &t
+}
+
+//记录成交，并撮合成交价穿过委托价的挂单(成交量不超过该笔成交的数量)
+func (sim *SimExchange) UpdateTrade(trade *Trade) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+
+	sim.setNow(trade.Date)
+
+	m := sim.market(trade.Pair)
+	m.trades = append(m.trades, *trade)
+	if len(m.trades) > maxTrades {
+		m.trades = m.trades[len(m.trades)-maxTrades:]
+	}
+
+	remain := trade.Amount
+	for _, o := range append([]*simOrder{}, m.open...) {
+		if remain <= 0 {
+			break
+		}
+		if (o.side == BUY && trade.Price > o.price) || (o.side == SELL && trade.Price < o.price) {
+			continue
+		}
+		qty := math.Min(remain, o.remain())
+		sim.fill(o, o.price, qty, true)
+		remain -= qty
+		sim.finishIfDone(m, o)
+	}
+}
+
+//从快照中扣除已被消耗的数量:档位数量比上一次快照减少时，减少的部分视为包含被消耗的流动性，相应释放；
+//快照中已没有的档位清除消耗记录
+func deductConsumed(levels DepthRecords, consumedLevels map[float64]*consumed) DepthRecords {
+	book := make(DepthRecords, 0, len(levels))
+	for _, level := range levels {
+		if c, ok := consumedLevels[level.Price]; ok {
+			if level.Amount < c.raw {
+				c.taken -= c.raw - level.Amount
+			}
+			c.taken = math.Min(c.taken, level.Amount)
+			c.raw = level.Amount
+			level.Amount -= math.Max(c.taken, 0)
+		}
+		if level.Amount > 0 {
+			book = append(book, level)
+		}
+	}
+
+	for price, c := range consumedLevels {
+		if c.taken <= 0 || !containsPrice(levels, price) {
+			delete(consumedLevels, price)
+		}
+	}
+
+	return book
+}
+
+func containsPrice(levels DepthRecords, price float64) bool {
+	for _, level := range levels {
+		if level.Price == price {
+			return true
+		}
+	}
+	return false
+}
+
+func (sim *SimExchange) setNow(ts int64) {
+	if ts > sim.now {
+		sim.now = ts
+	}
+}
+
+func (sim *SimExchange) timestamp() int64 {
+	if sim.now > 0 {
+		return sim.now
+	}
+	return time.Now().UnixNano() / int64(time.Millisecond)
+}
+
+func pairKey(pair CurrencyPair) string {
+	return strings.ToUpper(pair.ToSymbol("_"))
+}
+
+func (sim *SimExchange) market(pair CurrencyPair) *market {
+	key := pairKey(pair)
+	m, ok := sim.markets[key]
+	if !ok {
+		m = &market{pair: pair, askConsumed: make(map[float64]*consumed), bidConsumed: make(map[float64]*consumed)}
+		sim.markets[key] = m
+	}
+	return m
+}
+
+func (sim *SimExchange) balance(currency Currency) *balance {
+	key := strings.ToUpper(currency.Symbol)
+	b, ok := sim.wallet[key]
+	if !ok {
+		b = &balance{}
+		sim.wallet[key] = b
+	}
+	return b
+}
+
+func (m *market) lastPrice() float64 {
+	if len(m.trades) > 0 {
+		return m.trades[len(m.trades)-1].Price
+	}
+	if m.ticker != nil && m.ticker.Last > 0 {
+		return m.ticker.Last
+	}
+	return m.midPrice()
+}
+
+func (m *market) midPrice() float64 {
+	if len(m.asks) > 0 && len(m.bids) > 0 {
+		return (m.asks[0].Price + m.bids[0].Price) / 2
+	}
+	if m.ticker != nil {
+		return (m.ticker.Buy + m.ticker.Sell) / 2
+	}
+	return 0
+}
+
+func (o *simOrder) remain() float64 {
+	return o.amount - o.dealAmount
+}
+
+func (o *simOrder) isFuture() bool {
+	return o.contractType != ""
+}
+
+//下单: 校验资金并冻结，按深度吃单，剩余部分按orderType挂单或撤销
+func (sim *SimExchange) placeOrder(o *simOrder) (simOrder, error) {
+	if o.amount <= 0 || (!o.market && o.price <= 0) {
+		return simOrder{}, EX_ERR_INVALID_ORDER_PARAM.OriginErr(fmt.Sprintf("invalid price %f or amount %f", o.price, o.amount))
+	}
+
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+
+	if o.cid != "" {
+		if _, ok := sim.cids[o.cid]; ok {
+			return simOrder{}, EX_ERR_DUPLICATE_CLIENT_OID.OriginErr("duplicate client order id " + o.cid)
+		}
+	}
+
+	m := sim.market(o.pair)
+	book := &m.asks
+	if o.side == SELL {
+		book = &m.bids
+	}
+
+	if o.market && len(*book) == 0 {
+		return simOrder{}, EX_ERR_PLACE_ORDER_FAIL.OriginErr("no depth for market order " + o.pair.String())
+	}
+
+	crossed := len(*book) > 0 && (o.market || crosses(o.side, o.price, (*book)[0].Price))
+	if crossed && o.orderType == 1 {
+		return simOrder{}, EX_ERR_PLACE_ORDER_FAIL.OriginErr("post only order would immediately match")
+	}
+	if o.orderType == 2 && fillable(o, *book) < o.amount {
+		o.status = ORDER_CANCEL
+		sim.save(o)
+		o.finishedTime = o.createTime
+		return *o, nil
+	}
+
+	if err := sim.freeze(o); err != nil {
+		return simOrder{}, err
+	}
+
+	o.status = ORDER_UNFINISH
+	sim.save(o)
+
+	if crossed {
+		sim.takeLevels(m, o, book, o.remain(), false)
+	}
+
+	if o.remain() > 0 && !o.market && o.orderType == 0 {
+		m.open = append(m.open, o)
+	} else {
+		sim.finish(m, o)
+	}
+
+	return *o, nil
+}
+
+func (sim *SimExchange) save(o *simOrder) {
+	if o.id == "" {
+		sim.seq++
+		o.id = strconv.FormatInt(sim.seq, 10)
+		o.createTime = sim.timestamp()
+	}
+	sim.orders[o.id] = o
+	if o.cid != "" {
+		sim.cids[o.cid] = o
+	}
+}
+
+func crosses(side TradeSide, price, bookPrice float64) bool {
+	if side == BUY {
+		return price >= bookPrice
+	}
+	return price <= bookPrice
+}
+
+func fillable(o *simOrder, book DepthRecords) float64 {
+	qty := 0.0
+	for _, level := range book {
+		if !o.market && !crosses(o.side, o.price, level.Price) {
+			break
+		}
+		qty += level.Amount
+	}
+	return qty
+}
+
+//按深度成交qty，maker为true时按委托价成交(挂单被穿过)，否则按深度价成交；成交的数量记录为已消耗
+func (sim *SimExchange) takeLevels(m *market, o *simOrder, book *DepthRecords, qty float64, maker bool) {
+	consumedLevels := m.askConsumed
+	if o.side == SELL {
+		consumedLevels = m.bidConsumed
+	}
+
+	levels := *book
+	for len(levels) > 0 && qty > 0 {
+		level := &levels[0]
+		if !o.market && !crosses(o.side, o.price, level.Price) {
+			break
+		}
+
+		price := level.Price
+		if maker {
+			price = o.price
+		}
+
+		n := sim.fill(o, price, math.Min(qty, level.Amount), maker)
+		if n <= 0 {
+			break //资金不足
+		}
+
+		c, ok := consumedLevels[level.Price]
+		if !ok {
+			c = &consumed{raw: level.Amount}
+			consumedLevels[level.Price] = c
+		}
+		c.taken += n
+
+		qty -= n
+		level.Amount -= n
+		if level.Amount <= 0 {
+			levels = levels[1:]
+		}
+	}
+	*book = levels
+}
+
+func (sim *SimExchange) finishIfDone(m *market, o *simOrder) {
+	if o.remain() <= 0 {
+		sim.finish(m, o)
+	}
+}
+
+//订单结束: 解冻剩余资金，从挂单列表移除
+func (sim *SimExchange) finish(m *market, o *simOrder) {
+	sim.unfreeze(o)
+
+	switch {
+	case o.remain() <= 0:
+		o.status = ORDER_FINISH
+	default:
+		o.status = ORDER_CANCEL
+	}
+	o.finishedTime = sim.timestamp()
+
+	for i, open := range m.open {
+		if open == o {
+			m.open = append(m.open[:i], m.open[i+1:]...)
+			break
+		}
+	}
+}
+
+func (sim *SimExchange) fee(maker bool) float64 {
+	if maker {
+		return sim.config.MakerFee
+	}
+	return sim.config.TakerFee
+}
+
+func (sim *SimExchange) freeze(o *simOrder) error {
+	if o.isFuture() {
+		return sim.freezeFuture(o)
+	}
+
+	if o.side == BUY {
+		if o.market {
+			return nil //市价买单成交时直接扣减可用余额
+		}
+		return sim.freezeBalance(o, o.pair.CurrencyB, o.price*o.amount*(1+sim.config.TakerFee))
+	}
+
+	return sim.freezeBalance(o, o.pair.CurrencyA, o.amount)
+}
+
+func (sim *SimExchange) freezeBalance(o *simOrder, currency Currency, amount float64) error {
+	b := sim.balance(currency)
+	if b.available < amount {
+		return EX_ERR_INSUFFICIENT_BALANCE.OriginErr(fmt.Sprintf("%s available %f , need %f", currency.Symbol, b.available, amount))
+	}
+	b.available -= amount
+	b.frozen += amount
+	o.frozen = amount
+	return nil
+}
+
+func (sim *SimExchange) unfreeze(o *simOrder) {
+	if o.isFuture() {
+		sim.unfreezeFuture(o)
+		return
+	}
+
+	if o.frozen <= 0 {
+		return
+	}
+
+	currency := o.pair.CurrencyB
+	if o.side == SELL {
+		currency = o.pair.CurrencyA
+	}
+	b := sim.balance(currency)
+	b.available += o.frozen
+	b.frozen -= o.frozen
+	o.frozen = 0
+}
+
+//成交qty，返回实际成交数量(市价买单资金不足时会减少)
+func (sim *SimExchange) fill(o *simOrder, price, qty float64, maker bool) float64 {
+	if o.isFuture() {
+		qty = sim.fillFuture(o, price, qty, maker)
+	} else {
+		qty = sim.fillSpot(o, price, qty, maker)
+	}
+
+	if qty <= 0 {
+		return 0
+	}
+
+	o.avgPrice = (o.avgPrice*o.dealAmount + price*qty) / (o.dealAmount + qty)
+	o.dealAmount += qty
+	if o.remain() > 0 {
+		o.status = ORDER_PART_FINISH
+	}
+
+	return qty
+}
+
+func (sim *SimExchange) fillSpot(o *simOrder, price, qty float64, maker bool) float64 {
+	rate := sim.fee(maker)
+	base, quote := sim.balance(o.pair.CurrencyA), sim.balance(o.pair.CurrencyB)
+
+	if o.side == SELL {
+		base.frozen -= qty
+		o.frozen -= qty
+		quote.available += price * qty * (1 - rate)
+		o.fee += price * qty * rate
+		return qty
+	}
+
+	if o.market {
+		if affordable := quote.available / (price * (1 + rate)); qty > affordable {
+			qty = affordable
+		}
+		if qty <= 0 {
+			return 0
+		}
+	} else {
+		reserved := math.Min(o.frozen, o.price*qty*(1+sim.config.TakerFee))
+		o.frozen -= reserved
+		quote.frozen -= reserved
+		quote.available += reserved
+	}
+
+	quote.available -= price * qty * (1 + rate)
+	base.available += qty
+	o.fee += price * qty * rate
+
+	return qty
+}
+
+func (sim *SimExchange) cancelOrder(orderId string) error {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+
+	o, ok := sim.orders[orderId]
+	if !ok {
+		o, ok = sim.cids[orderId]
+	}
+	if !ok {
+		return EX_ERR_NOT_FIND_ORDER.OriginErr("order " + orderId + " not found")
+	}
+
+	if o.status != ORDER_UNFINISH && o.status != ORDER_PART_FINISH {
+		return EX_ERR_CANCEL_ORDER_FAIL.OriginErr(fmt.Sprintf("order %s status %s", orderId, o.status))
+	}
+
+	sim.finish(sim.market(o.pair), o)
+	return nil
+}
+
+func (sim *SimExchange) getOrder(orderId string) (*simOrder, error) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+
+	o, ok := sim.orders[orderId]
+	if !ok {
+		o, ok = sim.cids[orderId]
+	}
+	if !ok {
+		return nil, EX_ERR_NOT_FIND_ORDER.OriginErr("order " + orderId + " not found")
+	}
+
+	cp := *o
+	return &cp, nil
+}
+
+//查询订单，unfinished为true时只返回未完成的订单，否则只返回已完成的订单
+func (sim *SimExchange) findOrders(pair CurrencyPair, contractType string, unfinished bool) []simOrder {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+
+	var orders []simOrder
+	for _, o := range sim.orders {
+		if pairKey(o.pair) != pairKey(pair) || o.contractType != contractType {
+			continue
+		}
+		open := o.status == ORDER_UNFINISH || o.status == ORDER_PART_FINISH
+		if open == unfinished {
+			orders = append(orders, *o)
+		}
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		a, _ := strconv.ParseInt(orders[i].id, 10, 64)
+		b, _ := strconv.ParseInt(orders[j].id, 10, 64)
+		return a > b
+	})
+
+	return orders
+}
+
+func (sim *SimExchange) ticker(pair CurrencyPair) (*Ticker, error) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+
+	m, ok := sim.markets[pairKey(pair)]
+	if !ok {
+		return nil, errors.New("no market data for " + pair.String())
+	}
+
+	ticker := &Ticker{Pair: pair, Date: uint64(sim.timestamp())}
+	if m.ticker != nil {
+		*ticker = *m.ticker
+	}
+	ticker.Last = m.lastPrice()
+	if len(m.bids) > 0 {
+		ticker.Buy = m.bids[0].Price
+	}
+	if len(m.asks) > 0 {
+		ticker.Sell = m.asks[0].Price
+	}
+
+	return ticker, nil
+}
+
+func (sim *SimExchange) depth(size int, pair CurrencyPair) (*Depth, error) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+
+	m, ok := sim.markets[pairKey(pair)]
+	if !ok {
+		return nil, errors.New("no market data for " + pair.String())
+	}
+
+	depth := &Depth{Exchange: sim.config.Name, Pair: pair, Timestamp: sim.timestamp()}
+	for i := 0; i < size && i < len(m.asks); i++ {
+		depth.AskList = append(depth.AskList, m.asks[i])
+	}
+	for i := 0; i < size && i < len(m.bids); i++ {
+		depth.BidList = append(depth.BidList, m.bids[i])
+	}
+	sort.Sort(sort.Reverse(depth.AskList))
+
+	return depth, nil
+}
+
+func (sim *SimExchange) trades(pair CurrencyPair, since int64) []Trade {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+
+	var trades []Trade
+	if m, ok := sim.markets[pairKey(pair)]; ok {
+		for _, t := range m.trades {
+			if t.Date >= since {
+				trades = append(trades, t)
+			}
+		}
+	}
+	return trades
+}
+
+//合约接口，与现货共用资金账户
+func (sim *SimExchange) Future() *SimFuture {
+	return &SimFuture{sim}
+}
+
+func (sim *SimExchange) GetExchangeName() string {
+	return sim.config.Name
+}
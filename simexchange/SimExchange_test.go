@@ -0,0 +1,281 @@
+package simexchange
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+
+	. "github.com/mrwill84/goex"
+)
+
+var (
+	_ API           = (*SimExchange)(nil)
+	_ FutureRestAPI = (*SimFuture)(nil)
+	_ SpotWsApi     = (*FileFeed)(nil)
+)
+
+func newSim() *SimExchange {
+	sim := New(&Config{
+		Balances: map[Currency]float64{USDT: 10000, BTC: 1},
+		TakerFee: 0.001,
+		MakerFee: 0.0005,
+		Lever:    10,
+	})
+	sim.UpdateDepth(&Depth{
+		Pair:    BTC_USDT,
+		AskList: DepthRecords{{Price: 102, Amount: 2}, {Price: 101, Amount: 1}},
+		BidList: DepthRecords{{Price: 99, Amount: 1}, {Price: 98, Amount: 2}},
+	})
+	return sim
+}
+
+func equal(a, b float64) bool {
+	return math.Abs(a-b) < 1e-8
+}
+
+func balanceOf(t *testing.T, sim *SimExchange, currency Currency) SubAccount {
+	acc, err := sim.GetAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return acc.SubAccounts[currency]
+}
+
+func TestSimExchange_LimitBuy(t *testing.T) {
+	sim := newSim()
+
+	//吃掉101的1个和102的2个，剩余1个挂单
+	ord, err := sim.LimitBuy("4", "102", BTC_USDT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ord.Status != ORDER_PART_FINISH || !equal(ord.DealAmount, 3) || !equal(ord.AvgPrice, 305.0/3) {
+		t.Fatal(ord)
+	}
+
+	usdt := balanceOf(t, sim, USDT)
+	if !equal(usdt.ForzenAmount, 102*1.001) || !equal(usdt.Amount+usdt.ForzenAmount, 10000-305*1.001) {
+		t.Fatal(usdt)
+	}
+
+	//成交价穿过委托价，挂单按委托价成交
+	sim.UpdateTrade(&Trade{Pair: BTC_USDT, Price: 101.5, Amount: 3, Type: SELL})
+	ord, _ = sim.GetOneOrder(ord.OrderID2, BTC_USDT)
+	if ord.Status != ORDER_FINISH || !equal(ord.DealAmount, 4) {
+		t.Fatal(ord)
+	}
+
+	usdt, btc := balanceOf(t, sim, USDT), balanceOf(t, sim, BTC)
+	if !equal(usdt.ForzenAmount, 0) || !equal(usdt.Amount, 10000-305*1.001-102*1.0005) || !equal(btc.Amount, 5) {
+		t.Fatal(usdt, btc)
+	}
+}
+
+//同一档位的深度在后续快照中不会被重复成交，档位数量增加的部分才可以继续成交
+func TestSimExchange_UpdateDepthConsumed(t *testing.T) {
+	sim := newSim()
+	update := func(askAmount float64) {
+		sim.UpdateDepth(&Depth{
+			Pair:    BTC_USDT,
+			AskList: DepthRecords{{Price: 102, Amount: 2}, {Price: 101, Amount: askAmount}},
+			BidList: DepthRecords{{Price: 99, Amount: 1}, {Price: 98, Amount: 2}},
+		})
+	}
+
+	//吃掉101的1个，相同的快照中101已被消耗
+	if ord, _ := sim.LimitBuy("1", "101", BTC_USDT); ord.Status != ORDER_FINISH {
+		t.Fatal(ord)
+	}
+	update(1)
+	if depth, _ := sim.GetDepth(5, BTC_USDT); len(depth.AskList) != 1 || depth.AskList[0].Price != 102 {
+		t.Fatal(depth.AskList)
+	}
+
+	ord, err := sim.LimitBuy("5", "100", BTC_USDT)
+	if err != nil || ord.DealAmount != 0 {
+		t.Fatal(ord, err)
+	}
+
+	//卖盘降到100，穿过挂单的委托价，按档位数量成交
+	sim.UpdateDepth(&Depth{
+		Pair:    BTC_USDT,
+		AskList: DepthRecords{{Price: 100, Amount: 2}},
+		BidList: DepthRecords{{Price: 99, Amount: 1}},
+	})
+	ord, _ = sim.GetOneOrder(ord.OrderID2, BTC_USDT)
+	if !equal(ord.DealAmount, 2) {
+		t.Fatal(ord)
+	}
+
+	//重复的快照不再成交
+	for i := 0; i < 3; i++ {
+		sim.UpdateDepth(&Depth{
+			Pair:    BTC_USDT,
+			AskList: DepthRecords{{Price: 100, Amount: 2}},
+			BidList: DepthRecords{{Price: 99, Amount: 1}},
+		})
+	}
+	if ord, _ = sim.GetOneOrder(ord.OrderID2, BTC_USDT); !equal(ord.DealAmount, 2) {
+		t.Fatal(ord)
+	}
+
+	//档位数量减少时释放消耗，之后增加的部分可以成交
+	sim.UpdateDepth(&Depth{Pair: BTC_USDT, AskList: DepthRecords{{Price: 100, Amount: 0.5}}})
+	if ord, _ = sim.GetOneOrder(ord.OrderID2, BTC_USDT); !equal(ord.DealAmount, 2) {
+		t.Fatal(ord)
+	}
+	sim.UpdateDepth(&Depth{Pair: BTC_USDT, AskList: DepthRecords{{Price: 100, Amount: 2}}})
+	if ord, _ = sim.GetOneOrder(ord.OrderID2, BTC_USDT); !equal(ord.DealAmount, 3.5) {
+		t.Fatal(ord)
+	}
+}
+
+func TestSimExchange_OrderOptions(t *testing.T) {
+	sim := newSim()
+
+	if _, err := sim.LimitBuy("1", "101", BTC_USDT, PostOnly); err == nil {
+		t.Fatal("post only order should be rejected")
+	}
+
+	ord, _ := sim.LimitBuy("5", "102", BTC_USDT, Fok)
+	if ord.Status != ORDER_CANCEL || ord.DealAmount != 0 {
+		t.Fatal(ord)
+	}
+
+	ord, _ = sim.LimitBuy("5", "101", BTC_USDT, Ioc)
+	if ord.Status != ORDER_CANCEL || !equal(ord.DealAmount, 1) {
+		t.Fatal(ord)
+	}
+
+	if orders, _ := sim.GetUnfinishOrders(BTC_USDT); len(orders) != 0 {
+		t.Fatal(orders)
+	}
+	if usdt, btc := balanceOf(t, sim, USDT), balanceOf(t, sim, BTC); !equal(usdt.ForzenAmount, 0) || !equal(btc.Amount, 2) {
+		t.Fatal(usdt, btc)
+	}
+
+	if _, err := sim.LimitSell("3", "200", BTC_USDT); err == nil {
+		t.Fatal("insufficient balance")
+	}
+
+	ord, _ = sim.LimitBuy("1", "90", BTC_USDT, ClientOrderId("sim1"))
+	if _, err := sim.LimitBuy("1", "90", BTC_USDT, ClientOrderId("sim1")); err == nil {
+		t.Fatal("duplicate client order id")
+	}
+	if ok, err := sim.CancelOrder("sim1", BTC_USDT); !ok || err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSimFuture_Position(t *testing.T) {
+	sim := newSim()
+	future := sim.Future()
+
+	ord, err := future.MarketFuturesOrder(BTC_USDT, SWAP_CONTRACT, "2", OPEN_BUY)
+	if err != nil || ord.Status != ORDER_FINISH {
+		t.Fatal(ord, err)
+	}
+
+	positions, _ := future.GetFuturePosition(BTC_USDT, SWAP_CONTRACT)
+	if len(positions) != 1 || !equal(positions[0].BuyAmount, 2) || !equal(positions[0].BuyPriceAvg, 101.5) {
+		t.Fatal(positions)
+	}
+
+	sim.UpdateDepth(&Depth{
+		Pair:    BTC_USDT,
+		AskList: DepthRecords{{Price: 112, Amount: 5}},
+		BidList: DepthRecords{{Price: 110, Amount: 5}},
+	})
+
+	ord, err = future.LimitFuturesOrder(BTC_USDT, SWAP_CONTRACT, "110", "2", CLOSE_BUY)
+	if err != nil || ord.Status != ORDER_FINISH || !equal(ord.Profit, 17) {
+		t.Fatal(ord, err)
+	}
+
+	if positions, _ = future.GetFuturePosition(BTC_USDT, SWAP_CONTRACT); len(positions) != 0 {
+		t.Fatal(positions)
+	}
+
+	acc, _ := future.GetFutureUserinfo(BTC_USDT)
+	usdt := acc.FutureSubAccounts[USDT]
+	if !equal(usdt.AccountRights, 10000+17-203*0.001-220*0.001) || !equal(usdt.ProfitReal, 17) {
+		t.Fatal(usdt)
+	}
+
+	if _, err = future.LimitFuturesOrder(BTC_USDT, SWAP_CONTRACT, "110", "1", CLOSE_SELL); err == nil {
+		t.Fatal("no short position to close")
+	}
+}
+
+func TestFileFeed(t *testing.T) {
+	buf := &bytes.Buffer{}
+	recorder := NewFeedRecorder(buf)
+	recorder.Depth(&Depth{Pair: BTC_USDT, AskList: DepthRecords{{Price: 101, Amount: 1}}, BidList: DepthRecords{{Price: 99, Amount: 1}}})
+	recorder.Trade(&Trade{Pair: ETH_USDT, Price: 10, Amount: 1})
+	recorder.Trade(&Trade{Pair: BTC_USDT, Price: 100, Amount: 1})
+
+	file, _ := ioutil.TempFile("", "simfeed")
+	defer os.Remove(file.Name())
+	file.Write(buf.Bytes())
+	file.Close()
+
+	sim := New(&Config{})
+	feed := NewFileFeed(file.Name())
+	if err := sim.Attach(feed, BTC_USDT); err != nil {
+		t.Fatal(err)
+	}
+	if err := feed.Replay(); err != nil {
+		t.Fatal(err)
+	}
+
+	ticker, err := sim.GetTicker(BTC_USDT)
+	if err != nil || ticker.Last != 100 || ticker.Buy != 99 || ticker.Sell != 101 {
+		t.Fatal(ticker, err)
+	}
+	if _, err = sim.GetTicker(ETH_USDT); err == nil {
+		t.Fatal("ETH_USDT not subscribed")
+	}
+}
+
+type noTradeFeed struct {
+	*FileFeed
+}
+
+func (f noTradeFeed) SubscribeTrade(pair CurrencyPair) error {
+	return EX_ERR_NOT_SUPPORTED
+}
+
+//行情源不支持成交推送或DepthOnly时只按深度撮合
+func TestSimExchange_AttachDepthOnly(t *testing.T) {
+	buf := &bytes.Buffer{}
+	recorder := NewFeedRecorder(buf)
+	recorder.Depth(&Depth{Pair: BTC_USDT, AskList: DepthRecords{{Price: 101, Amount: 1}}, BidList: DepthRecords{{Price: 99, Amount: 1}}})
+	recorder.Trade(&Trade{Pair: BTC_USDT, Price: 100, Amount: 1})
+
+	file, _ := ioutil.TempFile("", "simfeed")
+	defer os.Remove(file.Name())
+	file.Write(buf.Bytes())
+	file.Close()
+
+	for _, sim := range []*SimExchange{New(&Config{}), New(&Config{DepthOnly: true})} {
+		var feed SpotWsApi = NewFileFeed(file.Name())
+		if !sim.config.DepthOnly {
+			feed = noTradeFeed{feed.(*FileFeed)}
+		}
+		if err := sim.Attach(feed, BTC_USDT); err != nil {
+			t.Fatal(err)
+		}
+		if err := feed.(interface{ Replay() error }).Replay(); err != nil {
+			t.Fatal(err)
+		}
+
+		if trades, _ := sim.GetTrades(BTC_USDT, 0); len(trades) != 0 {
+			t.Fatal("trades should not be subscribed", trades)
+		}
+		if depth, err := sim.GetDepth(1, BTC_USDT); err != nil || depth.AskList[0].Price != 101 {
+			t.Fatal(depth, err)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+package simexchange
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	. "github.com/mrwill84/goex"
+)
+
+//模拟交易所的合约接口(goex.FutureRestAPI)，与SimExchange共用行情、订单和资金
+type SimFuture struct {
+	*SimExchange
+}
+
+type position struct {
+	pair         CurrencyPair
+	contractType string
+	leverRate    float64
+	createTime   int64
+	long         positionSide
+	short        positionSide
+}
+
+type positionSide struct {
+	amount     float64
+	available  float64 //未被平仓单冻结的张数
+	avgPrice   float64
+	margin     float64
+	profitReal float64
+}
+
+func (sim *SimExchange) position(pair CurrencyPair, contractType string) *position {
+	key := pairKey(pair) + "@" + contractType
+	pos, ok := sim.positions[key]
+	if !ok {
+		pos = &position{pair: pair, contractType: contractType, leverRate: sim.config.Lever, createTime: sim.timestamp()}
+		sim.positions[key] = pos
+	}
+	return pos
+}
+
+//平多/开空为卖单，开多/平空为买单
+func futureSide(openType int) TradeSide {
+	if openType == OPEN_SELL || openType == CLOSE_BUY {
+		return SELL
+	}
+	return BUY
+}
+
+func isClose(openType int) bool {
+	return openType == CLOSE_BUY || openType == CLOSE_SELL
+}
+
+//订单对应的持仓方向
+func (sim *SimExchange) positionSide(o *simOrder) *positionSide {
+	pos := sim.position(o.pair, o.contractType)
+	if o.oType == OPEN_BUY || o.oType == CLOSE_BUY {
+		return &pos.long
+	}
+	return &pos.short
+}
+
+func (sim *SimExchange) freezeFuture(o *simOrder) error {
+	if o.leverRate <= 0 {
+		o.leverRate = sim.config.Lever
+	}
+
+	side := sim.positionSide(o)
+	if isClose(o.oType) {
+		if side.available < o.amount {
+			return EX_ERR_INVALID_ORDER_PARAM.OriginErr(fmt.Sprintf("close amount %f greater than available position %f", o.amount, side.available))
+		}
+		side.available -= o.amount
+		o.frozen = o.amount
+		return nil
+	}
+
+	sim.position(o.pair, o.contractType).leverRate = o.leverRate
+	if o.market {
+		return nil //市价开仓成交时直接扣减可用余额
+	}
+	return sim.freezeBalance(o, o.pair.CurrencyB, o.price*o.amount*sim.config.ContractVal*(1/o.leverRate+sim.config.TakerFee))
+}
+
+func (sim *SimExchange) unfreezeFuture(o *simOrder) {
+	if o.frozen <= 0 {
+		return
+	}
+
+	if isClose(o.oType) {
+		sim.positionSide(o).available += o.frozen
+	} else {
+		b := sim.balance(o.pair.CurrencyB)
+		b.available += o.frozen
+		b.frozen -= o.frozen
+	}
+	o.frozen = 0
+}
+
+func (sim *SimExchange) fillFuture(o *simOrder, price, qty float64, maker bool) float64 {
+	rate, cv := sim.fee(maker), sim.config.ContractVal
+	quote := sim.balance(o.pair.CurrencyB)
+	side := sim.positionSide(o)
+
+	if isClose(o.oType) {
+		pnl := (price - side.avgPrice) * qty * cv
+		if o.oType == CLOSE_SELL {
+			pnl = -pnl
+		}
+		release := side.margin * qty / side.amount
+		fee := price * qty * cv * rate
+
+		side.amount -= qty
+		side.margin -= release
+		side.profitReal += pnl
+		if side.amount <= 0 {
+			side.amount, side.margin, side.avgPrice = 0, 0, 0
+		}
+		o.frozen -= qty
+		o.profit += pnl
+		o.fee += fee
+		quote.available += release + pnl - fee
+
+		return qty
+	}
+
+	if o.market {
+		if affordable := quote.available / (price * cv * (1/o.leverRate + rate)); qty > affordable {
+			qty = affordable
+		}
+		if qty <= 0 {
+			return 0
+		}
+	} else {
+		reserved := math.Min(o.frozen, o.price*qty*cv*(1/o.leverRate+sim.config.TakerFee))
+		o.frozen -= reserved
+		quote.frozen -= reserved
+		quote.available += reserved
+	}
+
+	margin, fee := price*qty*cv/o.leverRate, price*qty*cv*rate
+	quote.available -= margin + fee
+	side.avgPrice = (side.avgPrice*side.amount + price*qty) / (side.amount + qty)
+	side.amount += qty
+	side.available += qty
+	side.margin += margin
+	o.fee += fee
+
+	return qty
+}
+
+func (o *simOrder) toFutureOrder() *FutureOrder {
+	return &FutureOrder{
+		ClientOid:    o.cid,
+		OrderID2:     o.id,
+		Price:        o.price,
+		Amount:       o.amount,
+		AvgPrice:     o.avgPrice,
+		DealAmount:   o.dealAmount,
+		OrderID:      ToInt64(o.id),
+		OrderTime:    o.createTime,
+		Status:       o.status,
+		Currency:     o.pair,
+		OrderType:    o.orderType,
+		OType:        o.oType,
+		LeverRate:    o.leverRate,
+		Fee:          o.fee,
+		Profit:       o.profit,
+		ContractName: o.contractType,
+		FinishedTime: o.finishedTime,
+	}
+}
+
+func (sim *SimExchange) placeFutureOrder(o *simOrder) (*FutureOrder, error) {
+	if o.contractType == "" {
+		o.contractType = SWAP_CONTRACT
+	}
+	if o.oType < OPEN_BUY || o.oType > CLOSE_SELL {
+		return nil, EX_ERR_INVALID_ORDER_PARAM.OriginErr(fmt.Sprintf("invalid open type %d", o.oType))
+	}
+	o.side = futureSide(o.oType)
+
+	ord, err := sim.placeOrder(o)
+	if err != nil {
+		return nil, err
+	}
+	return ord.toFutureOrder(), nil
+}
+
+func (sim *SimFuture) GetFutureEstimatedPrice(currencyPair CurrencyPair) (float64, error) {
+	ticker, err := sim.ticker(currencyPair)
+	if err != nil {
+		return 0, err
+	}
+	return ticker.Last, nil
+}
+
+//合约和现货使用同一份行情
+func (sim *SimFuture) GetFutureTicker(currencyPair CurrencyPair, contractType string) (*Ticker, error) {
+	return sim.ticker(currencyPair)
+}
+
+func (sim *SimFuture) GetFutureDepth(currencyPair CurrencyPair, contractType string, size int) (*Depth, error) {
+	depth, err := sim.depth(size, currencyPair)
+	if err != nil {
+		return nil, err
+	}
+	depth.ContractType = contractType
+	return depth, nil
+}
+
+func (sim *SimFuture) GetFutureIndex(currencyPair CurrencyPair) (float64, error) {
+	ticker, err := sim.ticker(currencyPair)
+	if err != nil {
+		return 0, err
+	}
+	return (ticker.Buy + ticker.Sell) / 2, nil
+}
+
+//按保证金币种(计价币)汇总，不传currencyPair时返回所有币种
+func (sim *SimFuture) GetFutureUserinfo(currencyPair ...CurrencyPair) (*FutureAccount, error) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+
+	acc := &FutureAccount{FutureSubAccounts: make(map[Currency]FutureSubAccount)}
+
+	symbols := make(map[string]bool)
+	for _, pair := range currencyPair {
+		symbols[strings.ToUpper(pair.CurrencyB.Symbol)] = true
+	}
+	if len(symbols) == 0 {
+		for symbol := range sim.wallet {
+			symbols[symbol] = true
+		}
+	}
+
+	for symbol := range symbols {
+		currency := NewCurrency(symbol, "")
+		b := sim.balance(currency)
+		sub := FutureSubAccount{Currency: currency, AccountRights: b.available + b.frozen}
+
+		for _, pos := range sim.positions {
+			if strings.ToUpper(pos.pair.CurrencyB.Symbol) != symbol {
+				continue
+			}
+			last := sim.market(pos.pair).lastPrice()
+			unreal := (last-pos.long.avgPrice)*pos.long.amount*sim.config.ContractVal +
+				(pos.short.avgPrice-last)*pos.short.amount*sim.config.ContractVal
+			sub.KeepDeposit += pos.long.margin + pos.short.margin
+			sub.ProfitReal += pos.long.profitReal + pos.short.profitReal
+			sub.ProfitUnreal += unreal
+		}
+
+		sub.AccountRights += sub.KeepDeposit + sub.ProfitUnreal
+		if sub.KeepDeposit > 0 {
+			sub.RiskRate = sub.AccountRights / sub.KeepDeposit
+		}
+		acc.FutureSubAccounts[currency] = sub
+	}
+
+	return acc, nil
+}
+
+//matchPrice为1时下市价单
+func (sim *SimFuture) PlaceFutureOrder(currencyPair CurrencyPair, contractType, price, amount string, openType, matchPrice int, leverRate float64) (string, error) {
+	ord, err := sim.placeFutureOrder(&simOrder{
+		pair:         currencyPair,
+		contractType: contractType,
+		oType:        openType,
+		market:       matchPrice == 1,
+		price:        ToFloat64(price),
+		amount:       ToFloat64(amount),
+		leverRate:    leverRate,
+	})
+	if err != nil {
+		return "", err
+	}
+	return ord.OrderID2, nil
+}
+
+func (sim *SimFuture) LimitFuturesOrder(currencyPair CurrencyPair, contractType, price, amount string, openType int, opt ...LimitOrderOptionalParameter) (*FutureOrder, error) {
+	return sim.placeFutureOrder(&simOrder{
+		cid:          GetClientOrderId(opt...),
+		pair:         currencyPair,
+		contractType: contractType,
+		oType:        openType,
+		orderType:    orderType(opt),
+		price:        ToFloat64(price),
+		amount:       ToFloat64(amount),
+	})
+}
+
+func (sim *SimFuture) MarketFuturesOrder(currencyPair CurrencyPair, contractType, amount string, openType int) (*FutureOrder, error) {
+	return sim.placeFutureOrder(&simOrder{
+		pair:         currencyPair,
+		contractType: contractType,
+		oType:        openType,
+		market:       true,
+		amount:       ToFloat64(amount),
+	})
+}
+
+func (sim *SimFuture) FutureCancelOrder(currencyPair CurrencyPair, contractType, orderId string) (bool, error) {
+	if err := sim.cancelOrder(orderId); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (sim *SimFuture) GetFuturePosition(currencyPair CurrencyPair, contractType string) ([]FuturePosition, error) {
+	if contractType == "" {
+		contractType = SWAP_CONTRACT
+	}
+
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+
+	pos, ok := sim.positions[pairKey(currencyPair)+"@"+contractType]
+	if !ok || (pos.long.amount <= 0 && pos.short.amount <= 0) {
+		return nil, nil
+	}
+
+	cv := sim.config.ContractVal
+	last := sim.market(currencyPair).lastPrice()
+	p := FuturePosition{
+		BuyAmount:      pos.long.amount,
+		BuyAvailable:   pos.long.available,
+		BuyPriceAvg:    pos.long.avgPrice,
+		BuyPriceCost:   pos.long.avgPrice,
+		BuyProfitReal:  pos.long.profitReal,
+		BuyProfit:      (last - pos.long.avgPrice) * pos.long.amount * cv,
+		CreateDate:     pos.createTime,
+		LeverRate:      pos.leverRate,
+		SellAmount:     pos.short.amount,
+		SellAvailable:  pos.short.available,
+		SellPriceAvg:   pos.short.avgPrice,
+		SellPriceCost:  pos.short.avgPrice,
+		SellProfitReal: pos.short.profitReal,
+		SellProfit:     (pos.short.avgPrice - last) * pos.short.amount * cv,
+		Symbol:         currencyPair,
+		ContractType:   contractType,
+	}
+	if pos.long.margin > 0 {
+		p.LongPnlRatio = p.BuyProfit / pos.long.margin
+	}
+	if pos.short.margin > 0 {
+		p.ShortPnlRatio = p.SellProfit / pos.short.margin
+	}
+
+	return []FuturePosition{p}, nil
+}
+
+func (sim *SimFuture) GetFutureOrders(orderIds []string, currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
+	var orders []FutureOrder
+	for _, id := range orderIds {
+		o, err := sim.getOrder(id)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *o.toFutureOrder())
+	}
+	return orders, nil
+}
+
+func (sim *SimFuture) GetFutureOrder(orderId string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
+	o, err := sim.getOrder(orderId)
+	if err != nil {
+		return nil, err
+	}
+	return o.toFutureOrder(), nil
+}
+
+func (sim *SimFuture) GetFutureOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (*FutureOrder, error) {
+	return sim.GetFutureOrder(cid, currencyPair, contractType)
+}
+
+func (sim *SimFuture) FutureCancelOrderByClientId(cid string, currencyPair CurrencyPair, contractType string) (bool, error) {
+	return sim.FutureCancelOrder(currencyPair, contractType, cid)
+}
+
+func (sim *SimFuture) GetUnfinishFutureOrders(currencyPair CurrencyPair, contractType string) ([]FutureOrder, error) {
+	if contractType == "" {
+		contractType = SWAP_CONTRACT
+	}
+	var orders []FutureOrder
+	for _, o := range sim.findOrders(currencyPair, contractType, true) {
+		orders = append(orders, *o.toFutureOrder())
+	}
+	return orders, nil
+}
+
+func (sim *SimFuture) GetFutureOrderHistory(pair CurrencyPair, contractType string, optional ...OptionalParameter) ([]FutureOrder, error) {
+	if contractType == "" {
+		contractType = SWAP_CONTRACT
+	}
+	var orders []FutureOrder
+	for _, o := range sim.findOrders(pair, contractType, false) {
+		orders = append(orders, *o.toFutureOrder())
+	}
+	return orders, nil
+}
+
+func (sim *SimFuture) GetFee() (float64, error) {
+	return sim.config.TakerFee, nil
+}
+
+func (sim *SimFuture) GetContractValue(currencyPair CurrencyPair) (float64, error) {
+	return sim.config.ContractVal, nil
+}
+
+//模拟交易所的合约不交割
+func (sim *SimFuture) GetDeliveryTime() (int, int, int, int) {
+	return 0, 0, 0, 0
+}
+
+func (sim *SimFuture) GetKlineRecords(contractType string, currency CurrencyPair, period KlinePeriod, size int, optional ...OptionalParameter) ([]FutureKline, error) {
+	return nil, errors.New("not implement")
+}
+
+func (sim *SimFuture) GetTrades(contractType string, currencyPair CurrencyPair, since int64) ([]Trade, error) {
+	return sim.trades(currencyPair, since), nil
+}
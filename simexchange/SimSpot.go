@@ -0,0 +1,153 @@
+package simexchange
+
+import (
+	"errors"
+
+	. "github.com/mrwill84/goex"
+)
+
+func orderType(opt []LimitOrderOptionalParameter) int {
+	for _, o := range opt {
+		switch o {
+		case PostOnly:
+			return 1
+		case Fok:
+			return 2
+		case Ioc:
+			return 3
+		}
+	}
+	return 0
+}
+
+func (o *simOrder) toOrder() *Order {
+	ord := &Order{
+		Price:        o.price,
+		Amount:       o.amount,
+		AvgPrice:     o.avgPrice,
+		DealAmount:   o.dealAmount,
+		Fee:          o.fee,
+		Cid:          o.cid,
+		OrderID2:     o.id,
+		OrderID:      ToInt(o.id),
+		Status:       o.status,
+		Currency:     o.pair,
+		Side:         o.side,
+		Type:         "limit",
+		OrderType:    o.orderType,
+		OrderTime:    int(o.createTime),
+		FinishedTime: o.finishedTime,
+	}
+	if o.market {
+		ord.Type = "market"
+		ord.Side = BUY_MARKET
+		if o.side == SELL {
+			ord.Side = SELL_MARKET
+		}
+	}
+	return ord
+}
+
+func (sim *SimExchange) placeSpotOrder(side TradeSide, market bool, amount, price string, currency CurrencyPair, opt []LimitOrderOptionalParameter) (*Order, error) {
+	o, err := sim.placeOrder(&simOrder{
+		cid:       GetClientOrderId(opt...),
+		pair:      currency,
+		side:      side,
+		orderType: orderType(opt),
+		market:    market,
+		price:     ToFloat64(price),
+		amount:    ToFloat64(amount),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return o.toOrder(), nil
+}
+
+func (sim *SimExchange) LimitBuy(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	return sim.placeSpotOrder(BUY, false, amount, price, currency, opt)
+}
+
+func (sim *SimExchange) LimitSell(amount, price string, currency CurrencyPair, opt ...LimitOrderOptionalParameter) (*Order, error) {
+	return sim.placeSpotOrder(SELL, false, amount, price, currency, opt)
+}
+
+//amount为币的数量
+func (sim *SimExchange) MarketBuy(amount, price string, currency CurrencyPair) (*Order, error) {
+	return sim.placeSpotOrder(BUY, true, amount, "0", currency, nil)
+}
+
+func (sim *SimExchange) MarketSell(amount, price string, currency CurrencyPair) (*Order, error) {
+	return sim.placeSpotOrder(SELL, true, amount, "0", currency, nil)
+}
+
+//orderId可以是订单ID或者客户端订单ID
+func (sim *SimExchange) CancelOrder(orderId string, currency CurrencyPair) (bool, error) {
+	if err := sim.cancelOrder(orderId); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (sim *SimExchange) GetOneOrder(orderId string, currency CurrencyPair) (*Order, error) {
+	o, err := sim.getOrder(orderId)
+	if err != nil {
+		return nil, err
+	}
+	return o.toOrder(), nil
+}
+
+//订单ID和客户端订单ID共用查找逻辑
+func (sim *SimExchange) GetOrderByClientId(cid string, currency CurrencyPair) (*Order, error) {
+	return sim.GetOneOrder(cid, currency)
+}
+
+func (sim *SimExchange) CancelOrderByClientId(cid string, currency CurrencyPair) (bool, error) {
+	return sim.CancelOrder(cid, currency)
+}
+
+func (sim *SimExchange) GetUnfinishOrders(currency CurrencyPair) ([]Order, error) {
+	var orders []Order
+	for _, o := range sim.findOrders(currency, "", true) {
+		orders = append(orders, *o.toOrder())
+	}
+	return orders, nil
+}
+
+func (sim *SimExchange) GetOrderHistorys(currency CurrencyPair, opt ...OptionalParameter) ([]Order, error) {
+	var orders []Order
+	for _, o := range sim.findOrders(currency, "", false) {
+		orders = append(orders, *o.toOrder())
+	}
+	return orders, nil
+}
+
+func (sim *SimExchange) GetAccount() (*Account, error) {
+	sim.lock.Lock()
+	defer sim.lock.Unlock()
+
+	acc := &Account{Exchange: sim.config.Name, SubAccounts: make(map[Currency]SubAccount, len(sim.wallet))}
+	for symbol, b := range sim.wallet {
+		currency := NewCurrency(symbol, "")
+		acc.SubAccounts[currency] = SubAccount{Currency: currency, Amount: b.available, ForzenAmount: b.frozen}
+	}
+
+	return acc, nil
+}
+
+func (sim *SimExchange) GetTicker(currency CurrencyPair) (*Ticker, error) {
+	return sim.ticker(currency)
+}
+
+func (sim *SimExchange) GetDepth(size int, currency CurrencyPair) (*Depth, error) {
+	return sim.depth(size, currency)
+}
+
+func (sim *SimExchange) GetKlineRecords(currency CurrencyPair, period KlinePeriod, size int, optional ...OptionalParameter) ([]Kline, error) {
+	return nil, errors.New("not implement")
+}
+
+//返回最近的成交记录，since为毫秒时间戳
+func (sim *SimExchange) GetTrades(currencyPair CurrencyPair, since int64) ([]Trade, error) {
+	return sim.trades(currencyPair, since), nil
+}
@@ -1,6 +1,7 @@
 package goex
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -22,15 +24,95 @@ type WsConfig struct {
 	HeartbeatData                  func() []byte       //心跳数据2
 	IsAutoReconnect                bool
 	ProtoHandleFunc                func([]byte) error           //协议处理函数
+	ConnProtoHandleFunc            func(*WsConn, []byte) error  //需要区分连接时使用(如回应心跳)，设置后不再调用ProtoHandleFunc
 	DecompressFunc                 func([]byte) ([]byte, error) //解压函数
 	ErrorHandleFunc                func(err error)
-	ConnectSuccessAfterSendMessage func() []byte //for reconnect
+	EventHandleFunc                func(event WsEvent) //连接生命周期事件，在ws的读/监控goroutine中同步调用，不要阻塞
+	ConnectSuccessAfterSendMessage func() []byte       //for reconnect
 	IsDump                         bool
 	DisableEnableCompression       bool
+	StaleTimeout                   time.Duration //超过该时间没有收到消息触发WS_EVENT_STALE , 0不检测
+	SubscribeLimit                 int           //SubscribeInterval内最多发送的订阅/取消订阅消息数(包括重连后的重新订阅) , 0不限制
+	SubscribeInterval              time.Duration
 	readDeadLineTime               time.Duration
 	reconnectInterval              time.Duration
 }
 
+type WsEventType int
+
+const (
+	WS_EVENT_CONNECTED        WsEventType = iota + 1 //连接成功(包括重连成功)
+	WS_EVENT_DISCONNECTED                            //连接断开，Err为断开原因
+	WS_EVENT_RECONNECTING                            //开始第Attempt次重连
+	WS_EVENT_RESUBSCRIBED                            //重连后已重新发送登录和订阅消息
+	WS_EVENT_STALE                                   //超过StaleTimeout没有收到消息
+	WS_EVENT_RESUMED                                 //STALE之后重新收到消息
+	WS_EVENT_SUBSCRIBED                              //交易所回应订阅成功，Subscription为订阅的Key
+	WS_EVENT_SUBSCRIBE_FAILED                        //交易所回应订阅失败，Err为失败原因
+)
+
+func (t WsEventType) String() string {
+	switch t {
+	case WS_EVENT_CONNECTED:
+		return "CONNECTED"
+	case WS_EVENT_DISCONNECTED:
+		return "DISCONNECTED"
+	case WS_EVENT_RECONNECTING:
+		return "RECONNECTING"
+	case WS_EVENT_RESUBSCRIBED:
+		return "RESUBSCRIBED"
+	case WS_EVENT_STALE:
+		return "STALE"
+	case WS_EVENT_RESUMED:
+		return "RESUMED"
+	case WS_EVENT_SUBSCRIBED:
+		return "SUBSCRIBED"
+	case WS_EVENT_SUBSCRIBE_FAILED:
+		return "SUBSCRIBE_FAILED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+type WsEvent struct {
+	Type         WsEventType
+	WsUrl        string
+	Attempt      int    //重连次数，从1开始
+	Err          error  //断开、重连或订阅失败的原因
+	Subscription string //订阅事件对应的订阅Key
+	Time         time.Time
+}
+
+//WsConn的运行状态
+type WsStats struct {
+	Connected       bool
+	Stale           bool
+	Messages        int64     //收到的消息总数
+	MessagesPerSec  float64   //最近一秒左右的消息速率
+	LastMessageTime time.Time //没有收到过消息时为零值
+	ReconnectCount  int64     //重连成功的次数
+	WriteQueueDepth int       //等待发送的消息数
+}
+
+//64位字段放在最前面，保证32位平台上atomic操作的对齐
+type wsCounters struct {
+	messages       int64
+	lastMessage    int64 //unix nano
+	reconnectCount int64
+	connectedAt    int64 //unix nano
+	connected      int32
+	stale          int32
+
+	rateLock   sync.Mutex
+	rate       float64
+	rateAt     time.Time
+	rateSample int64
+}
+
+var ErrWsClosed = errors.New("websocket closed")
+
+const wsCloseTimeout = time.Second //关闭时等待服务端回应close帧的时间
+
 var dialer = &websocket.Dialer{
 	Proxy:             http.ProxyFromEnvironment,
 	HandshakeTimeout:  30 * time.Second,
@@ -44,9 +126,19 @@ type WsConn struct {
 	pingMessageBufferChan  chan []byte
 	pongMessageBufferChan  chan []byte
 	closeMessageBufferChan chan []byte
-	subs                   [][]byte
-	close                  chan bool
+	subscriptions          *wsSubscriptions
 	reConnectLock          *sync.Mutex
+	counters               *wsCounters
+	subscribeBucket        *tokenBucket
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	connLock  sync.RWMutex //保护c , 重连时会替换c
+	wg        sync.WaitGroup
+	writeDone chan struct{} //写goroutine已发送完缓冲的消息和close帧
+	done      chan struct{} //所有goroutine已退出且连接已关闭
+	err       error         //导致连接退出的错误 , 主动关闭时为nil
+	closeErr  error
 }
 
 type WsBuilder struct {
@@ -116,36 +208,89 @@ func (b *WsBuilder) ErrorHandleFunc(f func(err error)) *WsBuilder {
 	return b
 }
 
+func (b *WsBuilder) EventHandleFunc(f func(event WsEvent)) *WsBuilder {
+	b.wsConfig.EventHandleFunc = f
+	return b
+}
+
+func (b *WsBuilder) StaleTimeout(t time.Duration) *WsBuilder {
+	b.wsConfig.StaleTimeout = t
+	return b
+}
+
+func (b *WsBuilder) ConnProtoHandleFunc(f func(c *WsConn, data []byte) error) *WsBuilder {
+	b.wsConfig.ConnProtoHandleFunc = f
+	return b
+}
+
+//交易所对单个连接的订阅消息限频，如binance每秒5条
+func (b *WsBuilder) SubscribeRateLimit(limit int, interval time.Duration) *WsBuilder {
+	b.wsConfig.SubscribeLimit = limit
+	b.wsConfig.SubscribeInterval = interval
+	return b
+}
+
 func (b *WsBuilder) ConnectSuccessAfterSendMessage(msg func() []byte) *WsBuilder {
 	b.wsConfig.ConnectSuccessAfterSendMessage = msg
 	return b
 }
 
+//连接失败时panic
+//Deprecated: 使用BuildWithContext，连接失败时返回错误
 func (b *WsBuilder) Build() *WsConn {
 	wsConn := &WsConn{WsConfig: *b.wsConfig}
 	return wsConn.NewWs()
 }
 
+//建立连接，失败时返回错误；ctx取消或调用Close后停止重连，发送完缓冲的消息和close帧后关闭连接
+func (b *WsBuilder) BuildWithContext(ctx context.Context) (*WsConn, error) {
+	wsConn := &WsConn{WsConfig: *b.wsConfig}
+	if err := wsConn.start(ctx); err != nil {
+		return nil, err
+	}
+	return wsConn, nil
+}
+
+//连接失败时panic
+//Deprecated: 使用WsBuilder.BuildWithContext，连接失败时返回错误
 func (ws *WsConn) NewWs() *WsConn {
+	if err := ws.start(context.Background()); err != nil {
+		Log.Panic(fmt.Errorf("[%s] %s", ws.WsUrl, err.Error()))
+	}
+	return ws
+}
+
+func (ws *WsConn) start(ctx context.Context) error {
 	if ws.HeartbeatIntervalTime == 0 {
 		ws.readDeadLineTime = time.Minute
 	} else {
 		ws.readDeadLineTime = ws.HeartbeatIntervalTime * 2
 	}
 
+	ws.counters = &wsCounters{rateAt: time.Now()}
+	ws.subscriptions = newWsSubscriptions()
+	if ws.SubscribeLimit > 0 && ws.SubscribeInterval > 0 {
+		ws.subscribeBucket = newTokenBucket(ws.SubscribeLimit, ws.SubscribeInterval)
+	}
+	ws.ctx, ws.cancel = context.WithCancel(ctx)
 	if err := ws.connect(); err != nil {
-		Log.Panic(fmt.Errorf("[%s] %s", ws.WsUrl, err.Error()))
+		ws.cancel()
+		return err
 	}
 
-	ws.close = make(chan bool, 1)
 	ws.pingMessageBufferChan = make(chan []byte, 10)
 	ws.pongMessageBufferChan = make(chan []byte, 10)
 	ws.closeMessageBufferChan = make(chan []byte, 10)
 	ws.writeBufferChan = make(chan []byte, 10)
 	ws.reConnectLock = new(sync.Mutex)
+	ws.writeDone = make(chan struct{})
+	ws.done = make(chan struct{})
 
+	ws.wg.Add(3)
 	go ws.writeRequest()
 	go ws.receiveMessage()
+	go ws.monitor()
+	go ws.waitClose()
 
 	if ws.ConnectSuccessAfterSendMessage != nil {
 		msg := ws.ConnectSuccessAfterSendMessage()
@@ -153,25 +298,26 @@ func (ws *WsConn) NewWs() *WsConn {
 		Log.Infof("[ws] [%s] execute the connect success after send message=%s", ws.WsUrl, string(msg))
 	}
 
-	return ws
+	return nil
 }
 
 func (ws *WsConn) connect() error {
+	d := *dialer
 	if ws.ProxyUrl != "" {
 		proxy, err := url.Parse(ws.ProxyUrl)
 		if err == nil {
 			Log.Infof("[ws][%s] proxy url:%s", ws.WsUrl, proxy)
-			dialer.Proxy = http.ProxyURL(proxy)
+			d.Proxy = http.ProxyURL(proxy)
 		} else {
 			Log.Errorf("[ws][%s]parse proxy url [%s] err %s  ", ws.WsUrl, ws.ProxyUrl, err.Error())
 		}
 	}
 
 	if ws.DisableEnableCompression {
-		dialer.EnableCompression = false
+		d.EnableCompression = false
 	}
 
-	wsConn, resp, err := dialer.Dial(ws.WsUrl, http.Header(ws.ReqHeaders))
+	wsConn, resp, err := d.DialContext(ws.ctx, ws.WsUrl, http.Header(ws.ReqHeaders))
 	if err != nil {
 		Log.Errorf("[ws][%s] %s", ws.WsUrl, err.Error())
 		if ws.IsDump && resp != nil {
@@ -182,55 +328,122 @@ func (ws *WsConn) connect() error {
 	}
 
 	wsConn.SetReadDeadline(time.Now().Add(ws.readDeadLineTime))
+	ws.setHandlers(wsConn)
 
 	if ws.IsDump {
 		dumpData, _ := httputil.DumpResponse(resp, true)
 		Log.Debugf("[ws][%s] %s", ws.WsUrl, string(dumpData))
 	}
 	Log.Infof("[ws][%s] connected", ws.WsUrl)
+
+	ws.connLock.Lock()
 	ws.c = wsConn
+	ws.connLock.Unlock()
+
+	atomic.StoreInt64(&ws.counters.connectedAt, time.Now().UnixNano())
+	atomic.StoreInt32(&ws.counters.connected, 1)
+	ws.emit(WsEvent{Type: WS_EVENT_CONNECTED})
 	return nil
 }
 
-func (ws *WsConn) reconnect() {
+//每个新连接都要设置，ping的回应通过写goroutine发送
+func (ws *WsConn) setHandlers(c *websocket.Conn) {
+	c.SetCloseHandler(func(code int, text string) error {
+		Log.Warnf("[ws][%s] websocket exiting [code=%d , text=%s]", ws.WsUrl, code, text)
+		return nil
+	})
+
+	c.SetPongHandler(func(pong string) error {
+		Log.Debugf("[%s] received [pong] %s", ws.WsUrl, pong)
+		c.SetReadDeadline(time.Now().Add(ws.readDeadLineTime))
+		return nil
+	})
+
+	c.SetPingHandler(func(ping string) error {
+		Log.Debugf("[%s] received [ping] %s", ws.WsUrl, ping)
+		ws.SendPongMessage([]byte(ping))
+		c.SetReadDeadline(time.Now().Add(ws.readDeadLineTime))
+		return nil
+	})
+}
+
+func (ws *WsConn) conn() *websocket.Conn {
+	ws.connLock.RLock()
+	defer ws.connLock.RUnlock()
+	return ws.c
+}
+
+func (ws *WsConn) emit(event WsEvent) {
+	if ws.EventHandleFunc == nil {
+		return
+	}
+	event.WsUrl = ws.WsUrl
+	event.Time = time.Now()
+	ws.EventHandleFunc(event)
+}
+
+//在读goroutine中调用，关闭时提前返回ctx的错误
+func (ws *WsConn) reconnect() error {
 	ws.reConnectLock.Lock()
 	defer ws.reConnectLock.Unlock()
 
-	ws.c.Close() //主动关闭一次
+	ws.conn().Close() //主动关闭一次
 	var err error
 	for retry := 1; retry <= 100; retry++ {
+		ws.emit(WsEvent{Type: WS_EVENT_RECONNECTING, Attempt: retry})
 		err = ws.connect()
-		if err != nil {
-			Log.Errorf("[ws] [%s] websocket reconnect fail , %s", ws.WsUrl, err.Error())
-		} else {
+		if err == nil {
 			break
 		}
-		time.Sleep(ws.WsConfig.reconnectInterval * time.Duration(retry))
+		Log.Errorf("[ws] [%s] websocket reconnect fail , %s", ws.WsUrl, err.Error())
+
+		select {
+		case <-ws.ctx.Done():
+			return ws.ctx.Err()
+		case <-time.After(ws.WsConfig.reconnectInterval * time.Duration(retry)):
+		}
 	}
 
 	if err != nil {
 		Log.Errorf("[ws] [%s] retry connect 100 count fail , begin exiting. ", ws.WsUrl)
-		ws.CloseWs()
-		if ws.ErrorHandleFunc != nil {
-			ws.ErrorHandleFunc(errors.New("retry reconnect fail"))
-		}
-	} else {
-		//re subscribe
-		if ws.ConnectSuccessAfterSendMessage != nil {
-			msg := ws.ConnectSuccessAfterSendMessage()
-			ws.SendMessage(msg)
-			Log.Infof("[ws] [%s] execute the connect success after send message=%s", ws.WsUrl, string(msg))
-			time.Sleep(time.Second) //wait response
+		return errors.New("retry reconnect fail")
+	}
+
+	atomic.AddInt64(&ws.counters.reconnectCount, 1)
+
+	//re subscribe
+	if ws.ConnectSuccessAfterSendMessage != nil {
+		msg := ws.ConnectSuccessAfterSendMessage()
+		ws.SendMessage(msg)
+		Log.Infof("[ws] [%s] execute the connect success after send message=%s", ws.WsUrl, string(msg))
+
+		select { //wait response
+		case <-ws.ctx.Done():
+			return ws.ctx.Err()
+		case <-time.After(time.Second):
 		}
+	}
 
-		for _, sub := range ws.subs {
-			Log.Info("[ws] re subscribe: ", string(sub))
-			ws.SendMessage(sub)
+	//只重新订阅没有取消和没有失败的
+	for _, sub := range ws.subscriptions.active() {
+		if err := ws.waitSubscribe(); err != nil {
+			return err
 		}
+		Log.Info("[ws] re subscribe: ", string(sub))
+		ws.SendMessage(sub)
 	}
+	ws.emit(WsEvent{Type: WS_EVENT_RESUBSCRIBED})
+	return nil
+}
+
+func (ws *WsConn) write(messageType int, data []byte) error {
+	return ws.conn().WriteMessage(messageType, data)
 }
 
 func (ws *WsConn) writeRequest() {
+	defer ws.wg.Done()
+	defer close(ws.writeDone)
+
 	var (
 		heartTimer *time.Timer
 		err        error
@@ -241,23 +454,25 @@ func (ws *WsConn) writeRequest() {
 	} else {
 		heartTimer = time.NewTimer(ws.HeartbeatIntervalTime)
 	}
+	defer heartTimer.Stop()
 
 	for {
 		select {
-		case <-ws.close:
+		case <-ws.ctx.Done():
+			ws.drainWrites()
 			Log.Infof("[ws][%s] close websocket , exiting write message goroutine.", ws.WsUrl)
 			return
 		case d := <-ws.writeBufferChan:
-			err = ws.c.WriteMessage(websocket.TextMessage, d)
+			err = ws.write(websocket.TextMessage, d)
 		case d := <-ws.pingMessageBufferChan:
-			err = ws.c.WriteMessage(websocket.PingMessage, d)
+			err = ws.write(websocket.PingMessage, d)
 		case d := <-ws.pongMessageBufferChan:
-			err = ws.c.WriteMessage(websocket.PongMessage, d)
+			err = ws.write(websocket.PongMessage, d)
 		case d := <-ws.closeMessageBufferChan:
-			err = ws.c.WriteMessage(websocket.CloseMessage, d)
+			err = ws.write(websocket.CloseMessage, d)
 		case <-heartTimer.C:
 			if ws.HeartbeatIntervalTime > 0 {
-				err = ws.c.WriteMessage(websocket.TextMessage, ws.HeartbeatData())
+				err = ws.write(websocket.TextMessage, ws.HeartbeatData())
 				heartTimer.Reset(ws.HeartbeatIntervalTime)
 			}
 		}
@@ -269,33 +484,54 @@ func (ws *WsConn) writeRequest() {
 	}
 }
 
-func (ws *WsConn) Subscribe(subEvent interface{}) error {
-	data, err := json.Marshal(subEvent)
-	if err != nil {
-		Log.Errorf("[ws][%s] json encode error , %s", ws.WsUrl, err)
-		return err
+//关闭前发送缓冲中的消息，最后发送close帧
+func (ws *WsConn) drainWrites() {
+	for {
+		select {
+		case d := <-ws.writeBufferChan:
+			if err := ws.write(websocket.TextMessage, d); err != nil {
+				Log.Errorf("[ws][%s] write message %s", ws.WsUrl, err.Error())
+			}
+		default:
+			err := ws.write(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			if err != nil {
+				Log.Warnf("[ws][%s] write close message %s", ws.WsUrl, err.Error())
+			}
+			return
+		}
+	}
+}
+
+//关闭后不再发送，返回ErrWsClosed
+func (ws *WsConn) send(ch chan []byte, msg []byte) error {
+	if ws.ctx.Err() != nil {
+		return ErrWsClosed
+	}
+
+	select {
+	case ch <- msg:
+		return nil
+	case <-ws.ctx.Done():
+		return ErrWsClosed
 	}
-	Log.Debug(string(data))
-	//fmt.Println("ws", ws)
-	ws.writeBufferChan <- data
-	ws.subs = append(ws.subs, data)
-	return nil
 }
 
 func (ws *WsConn) SendMessage(msg []byte) {
-	ws.writeBufferChan <- msg
+	if err := ws.send(ws.writeBufferChan, msg); err != nil {
+		Log.Warnf("[ws][%s] drop message %s , %s", ws.WsUrl, string(msg), err.Error())
+	}
 }
 
 func (ws *WsConn) SendPingMessage(msg []byte) {
-	ws.pingMessageBufferChan <- msg
+	ws.send(ws.pingMessageBufferChan, msg)
 }
 
 func (ws *WsConn) SendPongMessage(msg []byte) {
-	ws.pongMessageBufferChan <- msg
+	ws.send(ws.pongMessageBufferChan, msg)
 }
 
 func (ws *WsConn) SendCloseMessage(msg []byte) {
-	ws.closeMessageBufferChan <- msg
+	ws.send(ws.closeMessageBufferChan, msg)
 }
 
 func (ws *WsConn) SendJsonMessage(m interface{}) error {
@@ -303,97 +539,192 @@ func (ws *WsConn) SendJsonMessage(m interface{}) error {
 	if err != nil {
 		return err
 	}
-	ws.writeBufferChan <- data
-	return nil
+	return ws.send(ws.writeBufferChan, data)
 }
 
 func (ws *WsConn) receiveMessage() {
-	//exit
-	ws.c.SetCloseHandler(func(code int, text string) error {
-		Log.Warnf("[ws][%s] websocket exiting [code=%d , text=%s]", ws.WsUrl, code, text)
-		//ws.CloseWs()
-		return nil
-	})
+	defer ws.wg.Done()
 
-	ws.c.SetPongHandler(func(pong string) error {
-		Log.Debugf("[%s] received [pong] %s", ws.WsUrl, pong)
-		ws.c.SetReadDeadline(time.Now().Add(ws.readDeadLineTime))
-		return nil
-	})
+	for {
+		t, msg, err := ws.conn().ReadMessage()
+		if err != nil {
+			if ws.ctx.Err() != nil {
+				Log.Infof("[ws][%s] close websocket , exiting receive message goroutine.", ws.WsUrl)
+				return
+			}
 
-	ws.c.SetPingHandler(func(ping string) error {
-		Log.Debugf("[%s] received [ping] %s", ws.WsUrl, ping)
-		ws.SendPongMessage([]byte(ping))
-		ws.c.SetReadDeadline(time.Now().Add(ws.readDeadLineTime))
-		return nil
-	})
+			Log.Errorf("[ws][%s] %s", ws.WsUrl, err.Error())
+			atomic.StoreInt32(&ws.counters.connected, 0)
+			ws.emit(WsEvent{Type: WS_EVENT_DISCONNECTED, Err: err})
 
-	for {
-		select {
-		case <-ws.close:
-			Log.Infof("[ws][%s] close websocket , exiting receive message goroutine.", ws.WsUrl)
-			return
-		default:
-			t, msg, err := ws.c.ReadMessage()
-			if err != nil {
-				Log.Errorf("[ws][%s] %s", ws.WsUrl, err.Error())
-				if ws.IsAutoReconnect {
-					Log.Infof("[ws][%s] Unexpected Closed , Begin Retry Connect.", ws.WsUrl)
-					ws.reconnect()
+			if ws.IsAutoReconnect {
+				Log.Infof("[ws][%s] Unexpected Closed , Begin Retry Connect.", ws.WsUrl)
+				if err = ws.reconnect(); err == nil {
 					continue
 				}
-
-				if ws.ErrorHandleFunc != nil {
-					ws.ErrorHandleFunc(err)
+				if ws.ctx.Err() != nil {
+					return
 				}
-
-				return
 			}
-			//			Log.Debug(string(msg))
-			ws.c.SetReadDeadline(time.Now().Add(ws.readDeadLineTime))
-			switch t {
-			case websocket.TextMessage:
-				ws.ProtoHandleFunc(msg)
-			case websocket.BinaryMessage:
-				if ws.DecompressFunc == nil {
-					ws.ProtoHandleFunc(msg)
+
+			//ErrorHandleFunc在连接关闭后调用，可以在其中调用Close
+			ws.err = err
+			ws.cancel()
+			return
+		}
+		//			Log.Debug(string(msg))
+		if ws.ctx.Err() == nil {
+			ws.conn().SetReadDeadline(time.Now().Add(ws.readDeadLineTime))
+		}
+		ws.received()
+		switch t {
+		case websocket.TextMessage:
+			ws.handle(msg)
+		case websocket.BinaryMessage:
+			if ws.DecompressFunc == nil {
+				ws.handle(msg)
+			} else {
+				msg2, err := ws.DecompressFunc(msg)
+				if err != nil {
+					Log.Errorf("[ws][%s] decompress error %s", ws.WsUrl, err.Error())
 				} else {
-					msg2, err := ws.DecompressFunc(msg)
-					if err != nil {
-						Log.Errorf("[ws][%s] decompress error %s", ws.WsUrl, err.Error())
-					} else {
-						ws.ProtoHandleFunc(msg2)
-					}
+					ws.handle(msg2)
 				}
-				//	case websocket.CloseMessage:
-				//	ws.CloseWs()
-			default:
-				Log.Errorf("[ws][%s] error websocket message type , content is :\n %s \n", ws.WsUrl, string(msg))
 			}
+		default:
+			Log.Errorf("[ws][%s] error websocket message type , content is :\n %s \n", ws.WsUrl, string(msg))
 		}
 	}
 }
 
-func (ws *WsConn) CloseWs() {
-	//ws.close <- true
-	close(ws.close)
-	close(ws.writeBufferChan)
-	close(ws.closeMessageBufferChan)
-	close(ws.pingMessageBufferChan)
-	close(ws.pongMessageBufferChan)
-
-	err := ws.c.Close()
-	if err != nil {
-		Log.Error("[ws][", ws.WsUrl, "] close websocket error ,", err)
+func (ws *WsConn) handle(msg []byte) {
+	if ws.ConnProtoHandleFunc != nil {
+		ws.ConnProtoHandleFunc(ws, msg)
+		return
+	}
+	ws.ProtoHandleFunc(msg)
+}
+
+//按SubscribeRateLimit等待，关闭时返回ErrWsClosed
+func (ws *WsConn) waitSubscribe() error {
+	if ws.subscribeBucket == nil {
+		return nil
+	}
+	if err := sleepWithContext(ws.ctx, ws.subscribeBucket.reserve(1)); err != nil {
+		return ErrWsClosed
+	}
+	return nil
+}
+
+//ctx取消后：等待写goroutine发送完缓冲的消息和close帧，等待服务端回应close帧(最多wsCloseTimeout)，关闭连接
+func (ws *WsConn) waitClose() {
+	<-ws.ctx.Done()
+	<-ws.writeDone
+
+	ws.conn().SetReadDeadline(time.Now().Add(wsCloseTimeout))
+	ws.wg.Wait()
+
+	ws.closeErr = ws.conn().Close()
+	atomic.StoreInt32(&ws.counters.connected, 0)
+	close(ws.done)
+
+	if ws.err != nil && ws.ErrorHandleFunc != nil {
+		ws.ErrorHandleFunc(ws.err)
 	}
 }
 
-func (ws *WsConn) clearChannel(c chan struct{}) {
+func (ws *WsConn) received() {
+	atomic.AddInt64(&ws.counters.messages, 1)
+	atomic.StoreInt64(&ws.counters.lastMessage, time.Now().UnixNano())
+	if atomic.CompareAndSwapInt32(&ws.counters.stale, 1, 0) {
+		ws.emit(WsEvent{Type: WS_EVENT_RESUMED})
+	}
+}
+
+//每秒计算消息速率，并按StaleTimeout检测数据是否停止推送
+func (ws *WsConn) monitor() {
+	defer ws.wg.Done()
+
+	interval := time.Second
+	if ws.StaleTimeout > 0 && ws.StaleTimeout/4 < interval {
+		interval = ws.StaleTimeout / 4
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
 	for {
-		if len(c) > 0 {
-			<-c
-		} else {
-			break
+		select {
+		case <-ws.ctx.Done():
+			return
+		case now := <-ticker.C:
+			ws.counters.sampleRate(now)
+			if ws.StaleTimeout > 0 && now.Sub(ws.lastActiveTime()) > ws.StaleTimeout &&
+				atomic.CompareAndSwapInt32(&ws.counters.stale, 0, 1) {
+				Log.Warnf("[ws][%s] no message received in %s", ws.WsUrl, ws.StaleTimeout)
+				ws.emit(WsEvent{Type: WS_EVENT_STALE})
+			}
 		}
 	}
 }
+
+//最后一次收到消息的时间，连接后还没有收到消息时为连接时间
+func (ws *WsConn) lastActiveTime() time.Time {
+	last := atomic.LoadInt64(&ws.counters.lastMessage)
+	if connectedAt := atomic.LoadInt64(&ws.counters.connectedAt); connectedAt > last {
+		last = connectedAt
+	}
+	return time.Unix(0, last)
+}
+
+func (c *wsCounters) sampleRate(now time.Time) {
+	c.rateLock.Lock()
+	defer c.rateLock.Unlock()
+
+	elapsed := now.Sub(c.rateAt)
+	if elapsed < time.Second {
+		return
+	}
+	messages := atomic.LoadInt64(&c.messages)
+	c.rate = float64(messages-c.rateSample) / elapsed.Seconds()
+	c.rateSample = messages
+	c.rateAt = now
+}
+
+func (ws *WsConn) Stats() WsStats {
+	c := ws.counters
+	stats := WsStats{
+		Connected:       atomic.LoadInt32(&c.connected) == 1,
+		Stale:           atomic.LoadInt32(&c.stale) == 1,
+		Messages:        atomic.LoadInt64(&c.messages),
+		ReconnectCount:  atomic.LoadInt64(&c.reconnectCount),
+		WriteQueueDepth: len(ws.writeBufferChan),
+	}
+	if last := atomic.LoadInt64(&c.lastMessage); last > 0 {
+		stats.LastMessageTime = time.Unix(0, last)
+	}
+
+	c.rateLock.Lock()
+	stats.MessagesPerSec = c.rate
+	c.rateLock.Unlock()
+
+	return stats
+}
+
+//停止重连，发送完缓冲中的消息和close帧后关闭连接，等待所有goroutine退出，可以重复调用
+func (ws *WsConn) Close() error {
+	ws.cancel()
+	<-ws.done
+	return ws.closeErr
+}
+
+//连接关闭(调用Close , ctx取消或重连失败)后关闭
+func (ws *WsConn) Done() <-chan struct{} {
+	return ws.done
+}
+
+//同Close
+func (ws *WsConn) CloseWs() {
+	if err := ws.Close(); err != nil {
+		Log.Error("[ws][", ws.WsUrl, "] close websocket error ,", err)
+	}
+}
@@ -1,10 +1,20 @@
 package goex
 
 import (
+	"context"
 	"encoding/json"
-	. "github.com/mrwill84/goex/internal/logger"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/gorilla/websocket"
+	. "github.com/mrwill84/goex/internal/logger"
+	"github.com/mrwill84/goex/internal/replay"
 )
 
 func Test_time(t *testing.T) {
@@ -17,6 +27,7 @@ func ProtoHandle(data []byte) error {
 }
 
 func TestNewWsConn(t *testing.T) {
+	replay.SkipUnlessLive(t)
 	Log.SetLevel(DEBUG)
 
 	clientId := "a"
@@ -49,3 +60,294 @@ func TestNewWsConn(t *testing.T) {
 	ws.c.Close()
 	time.Sleep(time.Second * 120)
 }
+
+func waitWsEvent(t *testing.T, events chan WsEvent, typ WsEventType) WsEvent {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-events:
+			if e.Type == typ {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("wait ws event %s timeout", typ)
+		}
+	}
+}
+
+func TestWsConn_Events(t *testing.T) {
+	var (
+		conns    int32
+		upgrader = websocket.Upgrader{}
+		resume   = make(chan struct{})
+		drop     = make(chan struct{})
+		done     = make(chan struct{})
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.ReadMessage() //订阅或重新订阅
+		switch atomic.AddInt32(&conns, 1) {
+		case 1:
+			conn.WriteMessage(websocket.TextMessage, []byte("m1"))
+			<-resume
+			conn.WriteMessage(websocket.TextMessage, []byte("m2"))
+			<-drop
+		default:
+			conn.WriteMessage(websocket.TextMessage, []byte("m3"))
+			<-done
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	events := make(chan WsEvent, 32)
+	ws := NewWsBuilder().WsUrl("ws"+strings.TrimPrefix(server.URL, "http")).
+		AutoReconnect().ReconnectInterval(10*time.Millisecond).StaleTimeout(100*time.Millisecond).
+		ProtoHandleFunc(func(data []byte) error { return nil }).
+		EventHandleFunc(func(event WsEvent) { events <- event }).Build()
+	defer ws.Close()
+
+	waitWsEvent(t, events, WS_EVENT_CONNECTED)
+	ws.Subscribe(map[string]string{"op": "subscribe"})
+
+	waitWsEvent(t, events, WS_EVENT_STALE)
+	if stats := ws.Stats(); !stats.Stale || !stats.Connected || stats.Messages != 1 || stats.LastMessageTime.IsZero() {
+		t.Fatalf("%+v", stats)
+	}
+
+	close(resume)
+	waitWsEvent(t, events, WS_EVENT_RESUMED)
+
+	close(drop)
+	if e := waitWsEvent(t, events, WS_EVENT_DISCONNECTED); e.Err == nil {
+		t.Fatal(e)
+	}
+	if e := waitWsEvent(t, events, WS_EVENT_RECONNECTING); e.Attempt != 1 {
+		t.Fatal(e)
+	}
+	waitWsEvent(t, events, WS_EVENT_CONNECTED)
+	waitWsEvent(t, events, WS_EVENT_RESUBSCRIBED)
+
+	for i := 0; ws.Stats().Messages < 3; i++ {
+		if i > 100 {
+			t.Fatalf("%+v", ws.Stats())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if stats := ws.Stats(); stats.ReconnectCount != 1 || !stats.Connected {
+		t.Fatalf("%+v", stats)
+	}
+}
+
+func TestWsConn_Close(t *testing.T) {
+	_, err := NewWsBuilder().WsUrl("ws://127.0.0.1:1/ws").ProtoHandleFunc(ProtoHandle).BuildWithContext(context.Background())
+	if err == nil {
+		t.Fatal("dial should fail")
+	}
+
+	var (
+		upgrader = websocket.Upgrader{}
+		received = make(chan string, 1000)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			_, msg, err := conn.ReadMessage() //收到close帧时默认回应close帧
+			if err != nil {
+				if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+					received <- "close"
+				}
+				return
+			}
+			received <- string(msg)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ws, err := NewWsBuilder().WsUrl("ws"+strings.TrimPrefix(server.URL, "http")).AutoReconnect().
+		ProtoHandleFunc(ProtoHandle).BuildWithContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		ws.SendMessage([]byte("msg"))
+	}
+
+	//并发发送不会panic
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		for i := 0; i < 50; i++ {
+			ws.SendMessage([]byte("concurrent"))
+		}
+	}()
+
+	if err = ws.Close(); err != nil {
+		t.Fatal(err)
+	}
+	<-sent
+	ws.Close() //重复调用
+
+	select {
+	case <-ws.Done():
+	default:
+		t.Fatal("done should be closed")
+	}
+	if err = ws.Subscribe(map[string]string{"op": "subscribe"}); err != ErrWsClosed {
+		t.Fatal(err)
+	}
+
+	//close帧在缓冲的消息之后
+	msgs := 0
+	for closed := false; !closed; {
+		select {
+		case msg := <-received:
+			closed = msg == "close"
+			if msg == "msg" {
+				msgs++
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("close message not received")
+		}
+	}
+	if msgs != 5 {
+		t.Fatal(msgs)
+	}
+
+	//取消ctx关闭连接
+	ws, err = NewWsBuilder().WsUrl("ws"+strings.TrimPrefix(server.URL, "http")).AutoReconnect().
+		ProtoHandleFunc(ProtoHandle).BuildWithContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	select {
+	case <-ws.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("close timeout")
+	}
+	if ws.Stats().Connected {
+		t.Fatal("should be disconnected")
+	}
+}
+
+func TestWsConn_Subscriptions(t *testing.T) {
+	var (
+		conns    int32
+		upgrader = websocket.Upgrader{}
+		received = make(chan string, 100)
+		drop     = make(chan struct{})
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		n := atomic.AddInt32(&conns, 1)
+		if n == 1 {
+			go func() {
+				<-drop
+				conn.Close()
+			}()
+		}
+		for {
+			_, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			received <- fmt.Sprintf("%d:%s", n, msg)
+		}
+	}))
+	defer server.Close()
+
+	expect := func(msgs ...string) {
+		for _, want := range msgs {
+			select {
+			case msg := <-received:
+				if msg != want {
+					t.Fatalf("want %s , got %s", want, msg)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("wait %s timeout", want)
+			}
+		}
+	}
+
+	events := make(chan WsEvent, 32)
+	ws := NewWsBuilder().WsUrl("ws"+strings.TrimPrefix(server.URL, "http")).
+		AutoReconnect().ReconnectInterval(10*time.Millisecond).
+		ProtoHandleFunc(func(data []byte) error { return nil }).
+		EventHandleFunc(func(event WsEvent) { events <- event }).Build()
+	defer ws.Close()
+
+	ws.SubscribeWithId("a", "1", map[string]string{"sub": "a"})
+	ws.SubscribeWithId("a", "9", map[string]string{"sub": "a"}) //重复订阅
+	ws.SubscribeWithId("b", "2", map[string]string{"sub": "b"})
+	ws.SubscribeWithId("c", "3", map[string]string{"sub": "c"})
+	ws.Subscribe(map[string]string{"sub": "d"})
+	ws.Subscribe(map[string]string{"sub": "d"})
+	expect(`1:{"sub":"a"}`, `1:{"sub":"b"}`, `1:{"sub":"c"}`, `1:{"sub":"d"}`)
+
+	if !ws.AckSubscription("1", nil) || ws.AckSubscription("4", nil) {
+		t.Fatal("ack by id")
+	}
+	if e := waitWsEvent(t, events, WS_EVENT_SUBSCRIBED); e.Subscription != "a" {
+		t.Fatal(e)
+	}
+	ws.AckSubscription("2", errors.New("invalid channel"))
+	if e := waitWsEvent(t, events, WS_EVENT_SUBSCRIBE_FAILED); e.Subscription != "b" || e.Err == nil {
+		t.Fatal(e)
+	}
+
+	if err := ws.Unsubscribe("c", map[string]string{"unsub": "c"}); err != nil {
+		t.Fatal(err)
+	}
+	ws.Unsubscribe("c", map[string]string{"unsub": "c"}) //已取消不再发送
+	ws.Unsubscribe("e", map[string]string{"unsub": "e"})
+	ws.SendMessage([]byte("end"))
+	expect(`1:{"unsub":"c"}`, "1:end")
+
+	subs := ws.Subscriptions()
+	if len(subs) != 3 || subs[0].Key != "a" || !subs[0].Acked || subs[1].Key != "b" || subs[1].Err == nil ||
+		subs[2].Key != `{"sub":"d"}` || subs[2].Acked {
+		t.Fatalf("%+v", subs)
+	}
+
+	//重连后只重新订阅没有取消和没有失败的
+	close(drop)
+	waitWsEvent(t, events, WS_EVENT_RESUBSCRIBED)
+	ws.SendMessage([]byte("end"))
+	expect(`2:{"sub":"a"}`, `2:{"sub":"d"}`, "2:end")
+
+	if subs = ws.Subscriptions(); subs[0].Acked {
+		t.Fatalf("ack should be reset after reconnect %+v", subs)
+	}
+
+	//失败的订阅可以重新订阅，保留原来的顺序
+	if err := ws.SubscribeWithId("b", "5", map[string]string{"sub": "b"}); err != nil {
+		t.Fatal(err)
+	}
+	ws.SubscribeWithId("a", "6", map[string]string{"sub": "a"})
+	ws.SendMessage([]byte("end"))
+	expect(`2:{"sub":"b"}`, "2:end")
+	if subs = ws.Subscriptions(); len(subs) != 3 || subs[1].Key != "b" || subs[1].Id != "5" || subs[1].Err != nil {
+		t.Fatalf("%+v", subs)
+	}
+}
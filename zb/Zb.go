@@ -34,6 +34,10 @@ type Zb struct {
 	secretKey string
 }
 
+var (
+	_ API = (*Zb)(nil)
+)
+
 func New(httpClient *http.Client, accessKey, secretKey string) *Zb {
 	return &Zb{httpClient, accessKey, secretKey}
 }